@@ -0,0 +1,276 @@
+package agentkit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Priority orders provider calls competing for an AdmissionQueue. Lower
+// values are admitted first.
+type Priority int
+
+// Priorities, highest first.
+const (
+	PriorityInteractive Priority = iota
+	PriorityBackground
+	PriorityBatch
+)
+
+var priorities = []Priority{PriorityInteractive, PriorityBackground, PriorityBatch}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBackground:
+		return "background"
+	case PriorityBatch:
+		return "batch"
+	default:
+		return "unknown"
+	}
+}
+
+const priorityKey contextKey = "agentkit_priority"
+
+// WithPriority sets the admission priority for runs using ctx, overriding
+// Config.Priority.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey, p)
+}
+
+// GetPriority retrieves the admission priority from the context.
+func GetPriority(ctx context.Context) (Priority, bool) {
+	p, ok := ctx.Value(priorityKey).(Priority)
+	return p, ok
+}
+
+// AdmissionConfig sizes an AdmissionQueue.
+type AdmissionConfig struct {
+	// MaxConcurrent is the total number of provider calls allowed in flight.
+	MaxConcurrent int
+	// Slots optionally caps in-flight calls per priority, so batch work can
+	// never take every slot. Priorities without an entry share MaxConcurrent.
+	Slots map[Priority]int
+	// OnAdmit is called whenever a call is admitted, with the time it waited.
+	OnAdmit func(p Priority, wait time.Duration)
+}
+
+// AdmissionStats are the counters for one priority.
+type AdmissionStats struct {
+	InFlight int
+	Waiting  int
+	Admitted int64
+	// Abandoned counts calls whose context ended while queued.
+	Abandoned int64
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// AverageWait returns the mean queueing time of admitted calls.
+func (s AdmissionStats) AverageWait() time.Duration {
+	if s.Admitted == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Admitted)
+}
+
+// AdmissionQueue limits concurrent provider calls and admits waiting calls
+// in priority order (interactive before background before batch, FIFO within
+// a priority). Share one queue between agents to share capacity. It is safe
+// for concurrent use.
+type AdmissionQueue struct {
+	mu       sync.Mutex
+	cfg      AdmissionConfig
+	inFlight int
+	waiting  map[Priority][]*admissionWaiter
+	stats    map[Priority]*AdmissionStats
+}
+
+type admissionWaiter struct {
+	ready    chan struct{}
+	enqueued time.Time
+	admitted bool
+}
+
+// NewAdmissionQueue creates an admission queue. MaxConcurrent defaults to 1.
+func NewAdmissionQueue(cfg AdmissionConfig) *AdmissionQueue {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 1
+	}
+	q := &AdmissionQueue{
+		cfg:     cfg,
+		waiting: make(map[Priority][]*admissionWaiter),
+		stats:   make(map[Priority]*AdmissionStats),
+	}
+	for _, p := range priorities {
+		q.stats[p] = &AdmissionStats{}
+	}
+	return q
+}
+
+// Acquire waits for a slot at priority p. The returned release function must
+// be called exactly once when the call completes.
+func (q *AdmissionQueue) Acquire(ctx context.Context, p Priority) (func(), error) {
+	p = min(max(p, PriorityInteractive), PriorityBatch)
+
+	q.mu.Lock()
+	w := &admissionWaiter{ready: make(chan struct{}), enqueued: time.Now()}
+	q.waiting[p] = append(q.waiting[p], w)
+	stats := q.stats[p]
+	stats.Waiting++
+	q.dispatch()
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return q.releaseFunc(p), nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		if w.admitted {
+			// Admitted while the context ended; hand the slot on.
+			q.release(p)
+		} else {
+			q.remove(p, w)
+			stats.Waiting--
+			stats.Abandoned++
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the counters for every priority.
+func (q *AdmissionQueue) Stats() map[Priority]AdmissionStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	snapshot := make(map[Priority]AdmissionStats, len(q.stats))
+	for p, s := range q.stats {
+		snapshot[p] = *s
+	}
+	return snapshot
+}
+
+func (q *AdmissionQueue) releaseFunc(p Priority) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.release(p)
+		})
+	}
+}
+
+func (q *AdmissionQueue) canAdmit(p Priority) bool {
+	if q.inFlight >= q.cfg.MaxConcurrent {
+		return false
+	}
+	limit, ok := q.cfg.Slots[p]
+	return !ok || q.stats[p].InFlight < limit
+}
+
+func (q *AdmissionQueue) admit(p Priority, wait time.Duration) {
+	q.inFlight++
+	s := q.stats[p]
+	s.InFlight++
+	s.Admitted++
+	s.TotalWait += wait
+	if wait > s.MaxWait {
+		s.MaxWait = wait
+	}
+	if q.cfg.OnAdmit != nil {
+		q.cfg.OnAdmit(p, wait)
+	}
+}
+
+// release frees a slot and admits waiters in priority order.
+func (q *AdmissionQueue) release(p Priority) {
+	q.inFlight--
+	q.stats[p].InFlight--
+	q.dispatch()
+}
+
+func (q *AdmissionQueue) dispatch() {
+	for _, p := range priorities {
+		for len(q.waiting[p]) > 0 && q.canAdmit(p) {
+			w := q.waiting[p][0]
+			q.waiting[p] = q.waiting[p][1:]
+			q.stats[p].Waiting--
+			q.admit(p, time.Since(w.enqueued))
+			w.admitted = true
+			close(w.ready)
+		}
+	}
+}
+
+func (q *AdmissionQueue) remove(p Priority, w *admissionWaiter) {
+	waiters := q.waiting[p]
+	for i, candidate := range waiters {
+		if candidate == w {
+			q.waiting[p] = append(waiters[:i:i], waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// admissionProvider gates provider calls through an AdmissionQueue.
+type admissionProvider struct {
+	providers.Provider
+	queue    *AdmissionQueue
+	priority Priority
+}
+
+// unwrapProvider returns the provider an admission queue gates, so checks
+// for optional provider interfaces see the real provider.
+func unwrapProvider(provider providers.Provider) providers.Provider {
+	if admission, ok := provider.(*admissionProvider); ok {
+		return admission.Provider
+	}
+	return provider
+}
+
+func (p *admissionProvider) acquire(ctx context.Context) (func(), error) {
+	priority, ok := GetPriority(ctx)
+	if !ok {
+		priority = p.priority
+	}
+	return p.queue.Acquire(ctx, priority)
+}
+
+// Complete implements providers.Provider.
+func (p *admissionProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	release, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return p.Provider.Complete(ctx, req)
+}
+
+// Stream implements providers.Provider. The slot is held until the stream is closed.
+func (p *admissionProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	release, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := p.Provider.Stream(ctx, req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &admissionStream{StreamReader: stream, release: release}, nil
+}
+
+type admissionStream struct {
+	providers.StreamReader
+	release func()
+}
+
+func (s *admissionStream) Close() error {
+	defer s.release()
+	return s.StreamReader.Close()
+}
@@ -0,0 +1,113 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestAdmissionQueue_AdmitsByPriority(t *testing.T) {
+	q := NewAdmissionQueue(AdmissionConfig{MaxConcurrent: 1})
+	release, err := q.Acquire(context.Background(), PriorityInteractive)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var order []Priority
+	var wg sync.WaitGroup
+	enqueue := func(p Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := q.Acquire(context.Background(), p)
+			if err != nil {
+				t.Errorf("Acquire(%s) error = %v", p, err)
+				return
+			}
+			mu.Lock()
+			order = append(order, p)
+			mu.Unlock()
+			r()
+		}()
+		waitFor(t, func() bool { return q.Stats()[p].Waiting > 0 })
+	}
+	enqueue(PriorityBatch)
+	enqueue(PriorityBackground)
+	enqueue(PriorityInteractive)
+
+	release()
+	wg.Wait()
+
+	want := []Priority{PriorityInteractive, PriorityBackground, PriorityBatch}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("admission order = %v, want %v", order, want)
+		}
+	}
+	stats := q.Stats()
+	if stats[PriorityBatch].Admitted != 1 || stats[PriorityBatch].MaxWait <= 0 || stats[PriorityInteractive].Admitted != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAdmissionQueue_PerPrioritySlots(t *testing.T) {
+	q := NewAdmissionQueue(AdmissionConfig{MaxConcurrent: 3, Slots: map[Priority]int{PriorityBatch: 1}})
+	releaseBatch, _ := q.Acquire(context.Background(), PriorityBatch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Acquire(ctx, PriorityBatch); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second batch Acquire() error = %v, want deadline exceeded", err)
+	}
+	if _, err := q.Acquire(context.Background(), PriorityInteractive); err != nil {
+		t.Errorf("interactive Acquire() error = %v", err)
+	}
+	releaseBatch()
+
+	if stats := q.Stats()[PriorityBatch]; stats.Abandoned != 1 || stats.Waiting != 0 || stats.InFlight != 0 {
+		t.Errorf("batch stats = %+v", stats)
+	}
+}
+
+func TestAdmissionQueue_GatesAgentProviderCalls(t *testing.T) {
+	var admitted []Priority
+	q := NewAdmissionQueue(AdmissionConfig{
+		MaxConcurrent: 2,
+		OnAdmit:       func(p Priority, _ time.Duration) { admitted = append(admitted, p) },
+	})
+	agent, err := New(Config{
+		Provider:  mockprovider.New().WithResponse("a", nil).WithResponse("b", nil),
+		Model:     "test-model",
+		Admission: q,
+		Priority:  PriorityBackground,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.Run(context.Background(), "one"), 2*time.Second)
+	collectEvents(agent.Run(WithPriority(context.Background(), PriorityBatch), "two"), 2*time.Second)
+
+	if len(admitted) != 2 || admitted[0] != PriorityBackground || admitted[1] != PriorityBatch {
+		t.Errorf("admitted = %v", admitted)
+	}
+	if q.Stats()[PriorityBackground].InFlight != 0 {
+		t.Error("slot not released after the call")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
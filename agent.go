@@ -7,7 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,21 +20,27 @@ import (
 	"github.com/darkostanimirovic/agentkit/internal/parallel"
 	"github.com/darkostanimirovic/agentkit/internal/retry"
 	"github.com/darkostanimirovic/agentkit/internal/timeout"
+	"github.com/darkostanimirovic/agentkit/jsonrepair"
+	"github.com/darkostanimirovic/agentkit/memory"
 	"github.com/darkostanimirovic/agentkit/middleware"
 	"github.com/darkostanimirovic/agentkit/providers"
+	"github.com/darkostanimirovic/agentkit/providers/anthropic"
 	"github.com/darkostanimirovic/agentkit/providers/openai"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Type aliases for internal package types
 type (
-	ConversationStore = conversation.ConversationStore
-	Conversation      = conversation.Conversation
-	ConversationTurn  = conversation.ConversationTurn
-	RetryConfig       = retry.RetryConfig
-	TimeoutConfig     = timeout.TimeoutConfig
-	LoggingConfig     = logging.LoggingConfig
-	ParallelConfig    = parallel.ParallelConfig
-	Middleware        = middleware.Middleware
+	ConversationStore      = conversation.ConversationStore
+	Conversation           = conversation.Conversation
+	ConversationTurn       = conversation.ConversationTurn
+	ConversationToolCall   = conversation.ConversationToolCall
+	ConversationToolResult = conversation.ConversationToolResult
+	RetryConfig            = retry.RetryConfig
+	TimeoutConfig          = timeout.TimeoutConfig
+	LoggingConfig          = logging.LoggingConfig
+	ParallelConfig         = parallel.ParallelConfig
+	Middleware             = middleware.Middleware
 )
 
 // Function re-exports for convenience
@@ -53,7 +63,11 @@ type Agent struct {
 	provider          providers.Provider
 	model             string
 	systemPrompt      SystemPromptFunc
+	promptVariants    map[string]SystemPromptFunc
+	promptBuilder     *PromptBuilder
 	tools             map[string]Tool
+	toolsMu           *sync.RWMutex // Guards tools and toolsets; nil on agents not built by New
+	toolsets          map[string]*Toolset
 	maxIterations     int
 	temperature       float32
 	reasoningEffort   providers.ReasoningEffort
@@ -69,11 +83,40 @@ type Agent struct {
 	approvalConfig    ApprovalConfig
 	loggingConfig     LoggingConfig
 	logger            *slog.Logger
+	redaction         redactors
 	middlewares       []Middleware
 	eventBuffer       int
+	stopped           bool // Set by Runtime.Shutdown, guarded by registry.mu
 	parallelConfig    ParallelConfig
 	tracer            Tracer
 	agentName         string
+	streamShaping     StreamShapingConfig
+	costMeter         *costMeter
+	graphMemory       *GraphMemoryConfig
+	memory            *MemoryConfig
+	stateStore        AgentStateStore
+	flags             *FlagConfig
+	eventSinks        []EventSink
+	quota             *QuotaMonitor
+	costMonitor       *CostMonitor
+	contextManager    ContextManager
+	contextPolicy     *ContextPolicy
+	lessons           *lessonBook
+	responseChaining  *ResponseChainingConfig
+	toolDescriptionLimit int
+	thinkingTrace     *ThinkingTraceConfig
+	streamIdleTimeout time.Duration
+	hostedTools       []HostedTool
+	devReload         *devReloader
+	outputSchemaConfig *OutputSchemaConfig
+	deterministic     bool
+	seed              int64
+	lengthConfig      *OutputLengthConfig
+	terminology       *TerminologyConfig
+	moderation        *ModerationConfig
+	toolArgValidation ToolArgValidation
+	warmUp            *warmUpState
+	fallbacks         []ModelSpec
 }
 
 // Config holds agent configuration.
@@ -81,6 +124,7 @@ type Config struct {
 	APIKey                string
 	Model                 string
 	SystemPrompt          SystemPromptFunc
+	SystemPromptVariants  map[string]SystemPromptFunc // Keyed by model family, e.g. "gpt-5" or "o3"; longest model-name prefix wins
 	MaxIterations         int
 	Temperature           float32
 	ReasoningEffort       providers.ReasoningEffort
@@ -89,6 +133,7 @@ type Config struct {
 	TextFormat            string
 	Store                 bool
 	StreamResponses       bool
+	StreamIdleTimeout     time.Duration // Abort a stream that sends no chunk for this long and retry the call without streaming (0: no watchdog)
 	ToolChoice           string
 	Retry                 *RetryConfig
 	Timeout               *TimeoutConfig
@@ -101,26 +146,65 @@ type Config struct {
 	ParallelToolExecution *ParallelConfig
 	Tracer                Tracer
 	AgentName             string
+	StreamShaping         *StreamShapingConfig
+	GraphMemory           *GraphMemoryConfig
+	Memory                *MemoryConfig
+	StateStore            AgentStateStore // Agent-owned state keyed by AgentName, loaded at Run start and saved at completion
+	Flags                 *FlagConfig
+	Admission             *AdmissionQueue     // Shared queue gating provider calls by priority
+	Priority              Priority            // Default admission priority for this agent's runs
+	EventSinks            []EventSink         // Receive every emitted event, e.g. BrokerSink for Kafka/NATS
+	Quota                 *QuotaMonitor       // Tracks provider rate-limit headers and warns before quotas run out
+	CostMonitor           *CostMonitor        // Alerts when a run or conversation spends far more than usual or crosses a limit
+	AllowUnknownModel     bool                // Skip the known-model check for models newer than this version
+	ContextManager        ContextManager      // Compacts history when the provider reports context_length_exceeded (default TruncateOldest)
+	ContextPolicy         *ContextPolicy      // Compacts history before each model call when it exceeds a token budget
+	Lessons               *LessonsConfig      // Mines thumbs-down runs (Agent.Score) into a reviewable lessons prompt section
+	ResponseChaining      *ResponseChainingConfig // Continue from the previous stored response instead of resending history
+	ToolDescriptionLimit  int                     // Cut tool and parameter descriptions sent to the model to this many characters (0: no limit)
+	ThinkingTrace         *ThinkingTraceConfig    // Record only the first/last/sampled streamed reasoning chunks in traces
+	Deterministic         bool                // Reproducible requests: temperature 0 (overrides Temperature) and a pinned Seed
+	Seed                  int64               // Sampling seed sent in Deterministic mode by providers that support one
+	OutputLength          *OutputLengthConfig // Soft word target, hard token cap and enforcement for the final answer
+	Terminology           *TerminologyConfig  // Checks the final answer against a glossary of banned and preferred terms
+	Moderation            *ModerationConfig   // Checks user input and the final answer with a moderation API, optionally stopping the run
+	BaseURL               string              // OpenAI-compatible API base URL, e.g. https://openrouter.ai/api/v1 (APIKey becomes optional)
+	Headers               map[string]string   // Extra headers sent with every request to the built-in OpenAI-compatible provider
+	HTTPClient            *http.Client        // Client for the built-in providers (default: the pooled providers.DefaultHTTPClient)
+	FallbackModels        []ModelSpec         // Tried in order when the model is rate limited, fails with a 5xx or times out
+	HostedTools           []HostedTool        // Tools the provider runs itself: WebSearchTool(), FileSearchTool(ids...), CodeInterpreterTool()
+	DevReload             *DevReloadConfig    // Development only: reload the system prompt and settings from files when they change
+	OutputSchema          *OutputSchemaConfig // Final answer is JSON matching a schema, validated and repaired; see RunStructured
+	ToolArgValidation     ToolArgValidation   // Invalid tool arguments are reported (default ToolArgsLenient) or also sent back to the model instead of running the tool (ToolArgsStrict)
+	Redaction             *RedactionConfig    // Per-destination redaction of logs, traces, events and event sinks
+	PromptBuilder         *PromptBuilder      // Composes the system prompt from ordered, cached sections; use instead of SystemPrompt
 }
 
 // Common validation errors.
 var (
-	ErrMissingAPIKey          = errors.New("agentkit: APIKey is required")
-	ErrInvalidIterations      = errors.New("agentkit: MaxIterations must be between 1 and 100")
-	ErrInvalidTemperature     = errors.New("agentkit: Temperature must be between 0.0 and 2.0")
-	ErrInvalidReasoningEffort = errors.New("agentkit: ReasoningEffort must be valid")
+	ErrMissingAPIKey              = errors.New("agentkit: APIKey is required")
+	ErrInvalidIterations          = errors.New("agentkit: MaxIterations must be between 1 and 100")
+	ErrInvalidTemperature         = errors.New("agentkit: Temperature must be between 0.0 and 2.0")
+	ErrInvalidReasoningEffort     = errors.New("agentkit: ReasoningEffort must be valid")
+	ErrReasoningEffortUnsupported = errors.New("agentkit: ReasoningEffort requires a reasoning model")
+	ErrTemperatureUnsupported     = errors.New("agentkit: Temperature is not supported by reasoning models")
+	ErrUnknownModel               = errors.New("agentkit: unknown model (set AllowUnknownModel or RegisterModelInfo)")
+	ErrInvalidFallbackModel       = errors.New("agentkit: FallbackModels entries need a Model")
+	ErrConflictingPrompts         = errors.New("agentkit: set SystemPrompt or PromptBuilder, not both")
 )
 
-// Validate checks if the configuration is valid.
+// Validate checks if the configuration is valid. It reports every problem
+// found, joined with errors.Join; use errors.Is to test for a specific one.
 func (c Config) Validate() error {
-	if c.APIKey == "" && c.Provider == nil && c.LLMProvider == nil {
-		return ErrMissingAPIKey
+	var errs []error
+	if c.APIKey == "" && c.BaseURL == "" && c.usesBuiltinProvider() {
+		errs = append(errs, ErrMissingAPIKey)
 	}
 	if c.MaxIterations < 0 || c.MaxIterations > 100 {
-		return ErrInvalidIterations
+		errs = append(errs, ErrInvalidIterations)
 	}
 	if c.Temperature < 0.0 || c.Temperature > 2.0 {
-		return ErrInvalidTemperature
+		errs = append(errs, ErrInvalidTemperature)
 	}
 	if c.ReasoningEffort != "" {
 		if c.ReasoningEffort != providers.ReasoningEffortNone &&
@@ -129,10 +213,69 @@ func (c Config) Validate() error {
 			c.ReasoningEffort != providers.ReasoningEffortMedium &&
 			c.ReasoningEffort != providers.ReasoningEffortHigh &&
 			c.ReasoningEffort != providers.ReasoningEffortXHigh {
-			return ErrInvalidReasoningEffort
+			errs = append(errs, ErrInvalidReasoningEffort)
 		}
 	}
-	return nil
+
+	if c.SystemPrompt != nil && c.PromptBuilder != nil {
+		errs = append(errs, ErrConflictingPrompts)
+	}
+
+	for _, spec := range c.FallbackModels {
+		if spec.Model == "" {
+			errs = append(errs, ErrInvalidFallbackModel)
+			break
+		}
+	}
+
+	if c.Model != "" {
+		if info, ok := LookupModelInfo(c.Model); ok {
+			if !info.Reasoning && c.ReasoningEffort != "" && c.ReasoningEffort != providers.ReasoningEffortNone {
+				errs = append(errs, fmt.Errorf("%w: %s", ErrReasoningEffortUnsupported, c.Model))
+			}
+			if !info.Temperature && c.Temperature > 0 && !c.Deterministic {
+				errs = append(errs, fmt.Errorf("%w: %s", ErrTemperatureUnsupported, c.Model))
+			}
+		} else if c.usesBuiltinProvider() && c.BaseURL == "" && !c.AllowUnknownModel {
+			// Model metadata only covers the built-in providers' own APIs.
+			errs = append(errs, fmt.Errorf("%w: %s", ErrUnknownModel, c.Model))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Warnings returns non-fatal configuration problems. New logs them.
+func (c Config) Warnings() []string {
+	var warnings []string
+	if c.LLMProvider != nil && c.Provider == nil {
+		warnings = append(warnings, "LLMProvider is deprecated; use Provider")
+	}
+	if c.Model != "" && c.AllowUnknownModel && c.usesBuiltinProvider() {
+		if _, ok := LookupModelInfo(c.Model); !ok {
+			warnings = append(warnings, fmt.Sprintf("model %q is unknown; ReasoningEffort and Temperature are not checked", c.Model))
+		}
+	}
+	if c.MaxIterations > 50 {
+		warnings = append(warnings, fmt.Sprintf("MaxIterations is %d; runaway tool loops will be expensive", c.MaxIterations))
+	}
+	if c.Approval != nil && c.Approval.Handler == nil && (c.Approval.AllTools || len(c.Approval.Tools) > 0) {
+		warnings = append(warnings, "Approval has no Handler; tools requiring approval will always be denied")
+	}
+	return warnings
+}
+
+func (c Config) usesBuiltinProvider() bool {
+	return c.Provider == nil && c.LLMProvider == nil
+}
+
+// builtinProvider picks the built-in provider for cfg.Model: Anthropic for
+// "claude-" models, OpenAI otherwise. A BaseURL always selects the
+// OpenAI-compatible provider.
+func builtinProvider(cfg Config, logger *slog.Logger) providers.Provider {
+	if cfg.BaseURL == "" && strings.HasPrefix(cfg.Model, "claude") {
+		return anthropic.New(cfg.APIKey, logger).WithHTTPClient(cfg.HTTPClient)
+	}
+	return openai.New(cfg.APIKey, logger).WithBaseURL(cfg.BaseURL).WithHeaders(cfg.Headers).WithHTTPClient(cfg.HTTPClient)
 }
 
 // DefaultConfig returns sensible defaults.
@@ -145,8 +288,45 @@ func DefaultConfig() Config {
 	}
 }
 
-// New creates a new agent with the given configuration.
-func New(cfg Config) (*Agent, error) {
+// New creates a new agent from a Config and/or functional options, applied
+// in order:
+//
+//	agent, err := agentkit.New(
+//		agentkit.WithModel("gpt-4o"),
+//		agentkit.WithTool(lookupOrder),
+//	)
+//
+// Config is itself an Option, so New(cfg) keeps working, and
+// New(cfg, agentkit.WithTool(t)) starts from cfg.
+func New(opts ...Option) (*Agent, error) {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt.applyOption(&o)
+		}
+	}
+	seen := make(map[string]bool, len(o.tools))
+	for _, tool := range o.tools {
+		if seen[tool.Name()] {
+			return nil, fmt.Errorf("invalid agent config: %w: %s", ErrDuplicateTool, tool.Name())
+		}
+		seen[tool.Name()] = true
+	}
+
+	agent, err := newAgent(o.cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range o.tools {
+		agent.AddTool(tool)
+	}
+	for _, m := range o.middlewares {
+		agent.Use(m)
+	}
+	return agent, nil
+}
+
+func newAgent(cfg Config) (*Agent, error) {
 	if cfg.Model == "" {
 		cfg.Model = "gpt-4o-mini"
 	}
@@ -163,6 +343,13 @@ func New(cfg Config) (*Agent, error) {
 		loggingConfig = *cfg.Logging
 	}
 	logger := logging.ResolveLogger(loggingConfig)
+	redaction := newRedactors(cfg.Redaction, loggingConfig)
+	if redaction.logs != nil {
+		logger = slog.New(redaction.logs.LogHandler(logger.Handler()))
+	}
+	for _, warning := range cfg.Warnings() {
+		logger.Warn("agent config: " + warning)
+	}
 
 	retryConfig := DefaultRetryConfig()
 	if cfg.Retry != nil {
@@ -193,9 +380,13 @@ func New(cfg Config) (*Agent, error) {
 			// Wrap legacy LLMProvider into Provider interface
 			provider = &llmProviderWrapper{llm: cfg.LLMProvider}
 		} else {
-			provider = openai.New(cfg.APIKey, logger)
+			provider = builtinProvider(cfg, logger)
 		}
 	}
+	if cfg.Admission != nil {
+		provider = &admissionProvider{Provider: provider, queue: cfg.Admission, priority: cfg.Priority}
+	}
+	fallbacks := resolveFallbacks(cfg, provider)
 
 	agentName := cfg.AgentName
 	if agentName == "" {
@@ -211,12 +402,65 @@ func New(cfg Config) (*Agent, error) {
 	if tracer == nil {
 		tracer = &NoOpTracer{}
 	}
+	if redaction.traces != nil && !isNoOpTracer(tracer) {
+		tracer = redaction.traces.Tracer(tracer)
+	}
+
+	var streamShaping StreamShapingConfig
+	if cfg.StreamShaping != nil {
+		streamShaping = *cfg.StreamShaping
+	}
+
+	var graphMemory *GraphMemoryConfig
+	if cfg.GraphMemory != nil && cfg.GraphMemory.Graph != nil {
+		graphMemoryCopy := *cfg.GraphMemory
+		graphMemory = &graphMemoryCopy
+	}
+
+	var flagConfig *FlagConfig
+	if cfg.Flags != nil && cfg.Flags.Provider != nil {
+		flagCopy := *cfg.Flags
+		flagConfig = &flagCopy
+	}
+
+	var memoryConfig *MemoryConfig
+	if cfg.Memory != nil && (cfg.Memory.Memory != nil || cfg.Memory.Store != nil) {
+		memoryCopy := *cfg.Memory
+		if memoryCopy.Memory == nil {
+			memoryCopy.Memory = memory.FromStore(memoryCopy.Store)
+		}
+		if len(memoryCopy.ReadScopes) == 0 {
+			memoryCopy.ReadScopes = memory.AllScopes
+		}
+		if memoryCopy.WriteScope == "" {
+			memoryCopy.WriteScope = memory.ScopeUser
+		}
+		if memoryCopy.RecallLimit <= 0 {
+			memoryCopy.RecallLimit = DefaultMemoryRecallLimit
+		}
+		memoryConfig = &memoryCopy
+	}
 
-	return &Agent{
+	var outputLengthConfig *OutputLengthConfig
+	if cfg.OutputLength != nil {
+		outputLengthCopy := *cfg.OutputLength
+		outputLengthConfig = &outputLengthCopy
+	}
+
+	var terminologyConfig *TerminologyConfig
+	if cfg.Terminology != nil {
+		terminologyCopy := *cfg.Terminology
+		terminologyConfig = &terminologyCopy
+	}
+
+	agent := &Agent{
 		provider:          provider,
 		model:             cfg.Model,
 		systemPrompt:      cfg.SystemPrompt,
+		promptVariants:    cfg.SystemPromptVariants,
+		promptBuilder:     cfg.PromptBuilder,
 		tools:             make(map[string]Tool),
+		toolsMu:           new(sync.RWMutex),
 		maxIterations:     cfg.MaxIterations,
 		temperature:       cfg.Temperature,
 		reasoningEffort:   cfg.ReasoningEffort,
@@ -232,18 +476,108 @@ func New(cfg Config) (*Agent, error) {
 		approvalConfig:    approvalConfig,
 		loggingConfig:     loggingConfig,
 		logger:            logger,
+		redaction:         redaction,
 		eventBuffer:       eventBuffer,
 		parallelConfig:    parallelConfig,
 		tracer:            tracer,
 		agentName:         agentName,
-	}, nil
+		streamShaping:     streamShaping,
+		costMeter:         newCostMeter(),
+		graphMemory:       graphMemory,
+		memory:            memoryConfig,
+		stateStore:        cfg.StateStore,
+		flags:             flagConfig,
+		eventSinks:        cfg.EventSinks,
+		quota:             cfg.Quota,
+		costMonitor:       cfg.CostMonitor,
+		contextManager:    cfg.ContextManager,
+		deterministic:     cfg.Deterministic,
+		seed:              cfg.Seed,
+		lengthConfig:      outputLengthConfig,
+		terminology:       terminologyConfig,
+		toolArgValidation: cfg.ToolArgValidation,
+		fallbacks:         fallbacks,
+		warmUp:            &warmUpState{},
+	}
+	if agent.contextManager == nil {
+		agent.contextManager = TruncateOldest{}
+	}
+	if cfg.ContextPolicy != nil {
+		agent.contextPolicy = cfg.ContextPolicy.withDefaults(provider, cfg.Model)
+	}
+	if cfg.Lessons != nil {
+		agent.lessons = newLessonBook(*cfg.Lessons, provider, cfg.Model)
+	}
+	if cfg.Moderation != nil {
+		moderation, err := cfg.Moderation.withDefaults(provider)
+		if err != nil {
+			return nil, err
+		}
+		agent.moderation = moderation
+	}
+	agent.toolDescriptionLimit = cfg.ToolDescriptionLimit
+	agent.thinkingTrace = cfg.ThinkingTrace
+	agent.streamIdleTimeout = cfg.StreamIdleTimeout
+	agent.hostedTools = slices.Clone(cfg.HostedTools)
+	if cfg.DevReload != nil {
+		reloader, err := newDevReloader(*cfg.DevReload, logger)
+		if err != nil {
+			return nil, err
+		}
+		agent.devReload = reloader
+	}
+	if cfg.OutputSchema != nil {
+		if _, err := schemaInstruction(cfg.OutputSchema.Schema); err != nil {
+			return nil, err
+		}
+		outputSchema := *cfg.OutputSchema
+		agent.outputSchemaConfig = &outputSchema
+	}
+	if cfg.ResponseChaining != nil {
+		chaining := *cfg.ResponseChaining
+		if chaining.TTL <= 0 {
+			chaining.TTL = DefaultResponseTTL
+		}
+		agent.responseChaining = &chaining
+	}
+
+	if graphMemory != nil && !graphMemory.DisableTools {
+		for _, tool := range NewGraphTools(graphMemory.Graph) {
+			agent.AddTool(tool)
+		}
+	}
+
+	if memoryConfig != nil && memoryConfig.RememberTool {
+		agent.AddTool(NewRememberTool(agent))
+	}
+
+	return agent, nil
 }
 
 // AddTool registers a tool with the agent.
 func (a *Agent) AddTool(tool Tool) {
+	defer a.lockTools()()
 	a.tools[tool.Name()] = tool
 }
 
+// clone returns a copy of the agent that shares its provider, stores and
+// meters but has its own tool map, prompt variants, middlewares and event
+// sinks, so the copy can be adjusted without affecting the original.
+func (a *Agent) clone() *Agent {
+	copied := *a
+	unlock := a.readTools()
+	copied.tools = maps.Clone(a.tools)
+	copied.toolsets = maps.Clone(a.toolsets)
+	unlock()
+	copied.toolsMu = new(sync.RWMutex)
+	copied.promptVariants = maps.Clone(a.promptVariants)
+	copied.middlewares = slices.Clone(a.middlewares)
+	copied.eventSinks = slices.Clone(a.eventSinks)
+	copied.fallbacks = slices.Clone(a.fallbacks)
+	copied.hostedTools = slices.Clone(a.hostedTools)
+	return &copied
+}
+
 // AsTool converts the agent into a tool that can be used by other agents.
 func (a *Agent) AsTool(name, description string) Tool {
 	return NewTool(name).
@@ -336,6 +670,16 @@ func (a *Agent) emit(ctx context.Context, events chan<- Event, event Event) {
 			event.Data["agent_name"] = name
 		}
 	}
+	if source, ok := GetEventSource(ctx); ok {
+		if event.Data == nil {
+			event.Data = map[string]any{}
+		}
+		if _, exists := event.Data["agent_path"]; !exists {
+			for key, value := range source.data() {
+				event.Data[key] = value
+			}
+		}
+	}
 	if iteration, ok := GetIteration(ctx); ok {
 		if event.Data == nil {
 			event.Data = map[string]any{}
@@ -344,7 +688,20 @@ func (a *Agent) emit(ctx context.Context, events chan<- Event, event Event) {
 			event.Data["iteration"] = iteration
 		}
 	}
-	events <- event
+	// Shadow runs stay out of the sinks, which feed production systems.
+	if len(a.eventSinks) > 0 && !IsShadowRun(ctx) {
+		published := event
+		if a.redaction.sinks != nil {
+			published.Data = a.redaction.sinks.redactData(event.Data)
+		}
+		for _, sink := range a.eventSinks {
+			sink.Publish(ctx, published)
+		}
+	}
+	if a.redaction.events != nil {
+		event.Data = a.redaction.events.redactData(event.Data)
+	}
+	sendEvent(ctx, events, event)
 }
 
 // Conversation management methods
@@ -373,7 +730,13 @@ func (a *Agent) DeleteConversation(ctx context.Context, conversationID string) e
 	if a.conversationStore == nil {
 		return errors.New("agentkit: conversation store not configured")
 	}
-	return a.conversationStore.Delete(ctx, conversationID)
+	if err := a.conversationStore.Delete(ctx, conversationID); err != nil {
+		return err
+	}
+	if a.costMeter != nil {
+		a.costMeter.forget(conversationID)
+	}
+	return nil
 }
 
 func (a *Agent) AddContext(ctx context.Context, conversationID string, content string) error {
@@ -401,6 +764,9 @@ func (a *Agent) ClearConversation(ctx context.Context, conversationID string) er
 	if err := a.conversationStore.Delete(ctx, conversationID); err != nil {
 		return err
 	}
+	if a.costMeter != nil {
+		a.costMeter.forget(conversationID)
+	}
 
 	conv.Turns = nil
 	conv.CreatedAt = time.Now()
@@ -439,21 +805,54 @@ func (a *Agent) ForkConversation(ctx context.Context, originalID, newID, userMes
 	return a.conversationStore.Save(ctx, forked)
 }
 
-// Run executes the agent with streaming events.
-func (a *Agent) Run(ctx context.Context, userMessage string) <-chan Event {
-	events := make(chan Event, a.eventBuffer)
+// ErrNoMessages is reported by RunMessages when called without messages.
+var ErrNoMessages = errors.New("agentkit: RunMessages requires at least one message")
+
+// Run executes the agent with streaming events. RunOptions adjust this run
+// only, e.g. WithRunTemperature or WithRunTools.
+func (a *Agent) Run(ctx context.Context, userMessage string, opts ...RunOption) <-chan Event {
+	options := newRunOptions(opts)
+	if options != nil && options.conversationID != "" && a.conversationStore != nil {
+		return a.runWithConversation(ctx, options.conversationID, userMessage, options)
+	}
+	return a.runMessages(ctx, []providers.Message{{Role: providers.RoleUser, Content: userMessage}}, options)
+}
+
+// RunMessages executes the agent starting from several messages instead of a
+// single user string: system notes, a prior assistant turn, or multiple user
+// parts with images. The messages are sent after the system prompt, in
+// order. Middleware, memory and traces see the text of the user messages.
+func (a *Agent) RunMessages(ctx context.Context, messages []providers.Message, opts ...RunOption) <-chan Event {
+	return a.runMessages(ctx, messages, newRunOptions(opts))
+}
+
+func (a *Agent) runMessages(ctx context.Context, messages []providers.Message, options *runOptions) <-chan Event {
+	ctx = options.apply(ctx)
+	messages = slices.Clone(messages)
+	userMessage := userText(messages)
 	startTime := time.Now()
+	run, err := a.startRun(ctx, startTime)
+	if err != nil {
+		return errorRun(err)
+	}
+	run.options = options
+	events := make(chan Event, a.eventBuffer)
 
 	go func() {
+		ctx := context.WithValue(ctx, activeRunKey, run)
 		traceCtx, endTrace := a.tracer.StartTrace(ctx, "agent.run",
 			WithTraceInput(userMessage),
 			WithTraceStartTime(startTime),
 		)
 		defer endTrace()
-		ctx = traceCtx
+		ctx, usageTotals, rootTrace := withTraceUsage(traceCtx)
 
 		ctx = WithTracer(ctx, a.tracer)
+		ctx = WithEventSource(ctx, nestedEventSource(ctx, a.agentName))
 		ctx = WithAgentName(ctx, a.agentName)
+		ctx = a.evaluateFlags(ctx)
+		a.recordRunMetadata(ctx)
+		ctx = a.applyDevReload(ctx)
 
 		parentPub, hasParent := GetEventPublisher(ctx)
 		var runLoopChan chan<- Event
@@ -470,7 +869,7 @@ func (a *Agent) Run(ctx context.Context, userMessage string) <-chan Event {
 					if e.Type != EventTypeError {
 						parentPub(e)
 					}
-					events <- e
+					run.send(events, e)
 				}
 			}()
 		} else {
@@ -478,85 +877,154 @@ func (a *Agent) Run(ctx context.Context, userMessage string) <-chan Event {
 		}
 
 		childPub := func(e Event) {
-			runLoopChan <- e
+			run.send(runLoopChan, e)
 		}
 		execCtx := WithEventPublisher(ctx, childPub)
+		execCtx = context.WithValue(execCtx, eventEmitterKey, eventEmitter(func(ctx context.Context, e Event) {
+			a.emit(ctx, runLoopChan, e)
+		}))
 
 		execCtx, cancel := a.withExecutionTimeout(execCtx)
 		if cancel != nil {
 			defer cancel()
 		}
 
+		execCtx = a.loadState(execCtx)
 		execCtx = a.applyAgentStart(execCtx, userMessage)
 
 		agentName := a.agentName
 		a.emit(execCtx, runLoopChan, AgentStart(agentName))
 
-		finalOutput, usage, iterations, runErr := a.runLoop(execCtx, userMessage, runLoopChan)
-		a.applyAgentComplete(execCtx, finalOutput, runErr)
+		outcome, runErr := a.runLoop(execCtx, messages, runLoopChan)
+		a.endCostRun(run)
+		if rootTrace {
+			if err := a.tracer.SetTraceAttributes(ctx, usageTotals.attributes()); err != nil {
+				a.logger.Debug("failed to record trace usage", "error", err)
+			}
+		}
+		a.applyAgentComplete(execCtx, outcome.output, runErr)
+		a.saveState(execCtx)
+		if runErr == nil {
+			a.learnGraphMemory(execCtx, userMessage, outcome.output)
+			a.writeMemories(execCtx, userMessage, outcome.output)
+		}
 
 		// Always emit final output event (even if empty)
 		// Empty output is still a valid completion state that clients need to know about
-		a.emit(execCtx, runLoopChan, FinalOutput("", finalOutput))
+		a.emit(execCtx, runLoopChan, FinalOutputWithAnnotations("", outcome.output, outcome.annotations))
 
 		duration := time.Since(startTime).Milliseconds()
-		a.emit(execCtx, runLoopChan, AgentCompleteWithUsage(agentName, finalOutput, usage, iterations, duration))
+		a.emit(execCtx, runLoopChan, AgentCompleteWithUsage(agentName, outcome.output, outcome.usage, outcome.iterations, duration))
 
 		if hasParent {
 			close(internalChan)
 			wg.Wait()
 		}
+		registry.remove(run)
 		close(events)
 	}()
 
-	return events
+	return shapeEvents(events, a.streamShaping, a.forwarder(ctx).send)
 }
 
-// runLoop orchestrates the multi-turn conversation.
-func (a *Agent) runLoop(ctx context.Context, userMessage string, events chan<- Event) (string, providers.TokenUsage, int, error) {
-	conversationHistory := []providers.Message{
-		{
-			Role:    providers.RoleUser,
-			Content: userMessage,
-		},
-	}
+// runOutcome captures the result of a completed run loop.
+type runOutcome struct {
+	output      string
+	usage       providers.TokenUsage
+	cost        float64
+	iterations  int
+	annotations []providers.Annotation
+}
 
-	var finalOutput string
-	var totalUsage providers.TokenUsage
-	iterationsUsed := 0
+// userText joins the text of the user messages, for middleware, memory and
+// traces.
+func userText(messages []providers.Message) string {
+	parts := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == providers.RoleUser && msg.Content != "" {
+			parts = append(parts, msg.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
 
-	for iteration := 0; iteration < a.maxIterations; iteration++ {
+// runLoop orchestrates the multi-turn conversation.
+func (a *Agent) runLoop(ctx context.Context, messages []providers.Message, events chan<- Event) (runOutcome, error) {
+	var outcome runOutcome
+	if len(messages) == 0 {
+		a.emit(ctx, events, Error(ErrNoMessages))
+		return outcome, ErrNoMessages
+	}
+	conversationHistory := messages
+	userMessage := userText(messages)
+	if err := a.moderate(ctx, ModerationStageInput, userMessage, events); err != nil {
+		return outcome, err
+	}
+
+	ctx = a.recallGraphMemory(ctx, userMessage)
+	ctx = a.recallMemories(ctx, userMessage)
+	ctx = a.recallLessons(ctx)
+	chain := a.startResponseChain(ctx, len(conversationHistory), events)
+	maxIterations := a.runMaxIterations(ctx)
+	outputSchema := a.outputSchema(ctx)
+	document := runDocument(ctx)
+	repairs := 0
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
 		if err := ctx.Err(); err != nil {
 			runErr := fmt.Errorf("agent execution timeout: %w", err)
 			a.emit(ctx, events, Error(runErr))
-			return finalOutput, totalUsage, iterationsUsed, runErr
+			return outcome, runErr
 		}
 
-		a.logger.Debug("agent iteration", "iteration", iteration, "max", a.maxIterations)
+		a.logger.Debug("agent iteration", "iteration", iteration, "max", maxIterations)
 
 		iterCtx := WithIteration(ctx, iteration+1)
-		req := a.buildCompletionRequest(conversationHistory)
+		if policed, compacted := a.applyContextPolicy(iterCtx, conversationHistory, events); compacted {
+			// The stored response holds the uncompacted history.
+			chain.reset()
+			conversationHistory = policed
+		}
+		req := a.buildCompletionRequest(iterCtx, conversationHistory)
+		callCtx := a.chainRequest(iterCtx, chain, &req, conversationHistory)
+		a.emit(iterCtx, events, PromptBudgetReport(req.Model, a.promptBudget(req)))
 
 		var resp *providers.CompletionResponse
+		var model string
 		var err error
 
-		if a.streamResponses {
-			resp, err = a.runStreamingIteration(iterCtx, req, events)
-		} else {
-			resp, err = a.runNonStreamingIteration(iterCtx, req, events)
+		resp, model, err = a.runWithFallback(context.WithValue(callCtx, compactionKey, true), req, events)
+		if err != nil && req.PreviousResponseID != "" && providers.IsPreviousResponseNotFound(err) {
+			a.logger.Warn("previous response not found; resending history", "previous_response_id", req.PreviousResponseID)
+			a.emit(iterCtx, events, ServerStateLost(req.PreviousResponseID, "not_found", len(conversationHistory)))
+			chain.reset()
+			req = a.buildCompletionRequest(iterCtx, conversationHistory)
+			callCtx = a.chainRequest(iterCtx, chain, &req, conversationHistory)
+			resp, model, err = a.runWithFallback(context.WithValue(callCtx, compactionKey, true), req, events)
+		}
+		if err != nil && providers.IsContextLengthExceeded(err) {
+			compacted, compactErr := a.compactHistory(iterCtx, conversationHistory, events)
+			if compactErr != nil {
+				a.logger.Warn("context compaction failed", "error", compactErr)
+				a.emit(iterCtx, events, Error(err))
+				return outcome, err
+			}
+			conversationHistory = compacted
+			chain.reset()
+			req = a.buildCompletionRequest(iterCtx, conversationHistory)
+			callCtx = a.chainRequest(iterCtx, chain, &req, conversationHistory)
+			resp, model, err = a.runWithFallback(callCtx, req, events)
 		}
 
 		if err != nil {
-			return finalOutput, totalUsage, iterationsUsed, err
+			return outcome, err
 		}
 
 		resp.ToolCalls = ensureToolCallIDs(filterCompleteToolCalls(resp.ToolCalls))
-		iterationsUsed = iteration + 1
+		outcome.iterations = iteration + 1
 
-		totalUsage.PromptTokens += resp.Usage.PromptTokens
-		totalUsage.CompletionTokens += resp.Usage.CompletionTokens
-		totalUsage.ReasoningTokens += resp.Usage.ReasoningTokens
-		totalUsage.TotalTokens += resp.Usage.TotalTokens
+		a.recordUsage(iterCtx, events, model, resp.Usage, &outcome)
+		outcome.annotations = append(outcome.annotations, resp.Annotations...)
 
 		assistantMsg := providers.Message{
 			Role:      providers.RoleAssistant,
@@ -564,10 +1032,38 @@ func (a *Agent) runLoop(ctx context.Context, userMessage string, events chan<- E
 			ToolCalls: resp.ToolCalls,
 		}
 		conversationHistory = append(conversationHistory, assistantMsg)
+		chain.record(resp.ID, model == req.Model, len(conversationHistory))
 
+		if len(resp.ToolCalls) == 0 && document != nil {
+			patch, repair, err := a.checkDocumentPatch(iterCtx, document, model, resp.Content, &repairs, events)
+			if err != nil {
+				return outcome, err
+			}
+			if repair != nil {
+				conversationHistory = append(conversationHistory, *repair)
+				continue
+			}
+			outcome.output = patch
+			a.logger.Info("agent completed", "iterations", iteration+1, "document_version", document.Version)
+			break
+		}
+		if len(resp.ToolCalls) == 0 && outputSchema != nil {
+			output, repair, err := a.checkOutputSchema(iterCtx, outputSchema, model, resp.Content, &repairs, events)
+			if err != nil {
+				return outcome, err
+			}
+			if repair != nil {
+				conversationHistory = append(conversationHistory, *repair)
+				continue
+			}
+			outcome.output = output
+			a.logger.Info("agent completed", "iterations", iteration+1, "output_length", len(outcome.output))
+			break
+		}
 		if len(resp.ToolCalls) == 0 {
-			finalOutput = resp.Content
-			a.logger.Info("agent completed", "iterations", iteration+1, "output_length", len(finalOutput))
+			outcome.output = a.enforceOutputLength(iterCtx, conversationHistory, resp.Content, events, &outcome)
+			outcome.output = a.applyTerminology(iterCtx, outcome.output, events, &outcome)
+			a.logger.Info("agent completed", "iterations", iteration+1, "output_length", len(outcome.output))
 			break
 		}
 
@@ -577,19 +1073,58 @@ func (a *Agent) runLoop(ctx context.Context, userMessage string, events chan<- E
 		a.logger.Debug("continuing iteration", "tool_calls_executed", len(toolMessages))
 	}
 
-	if finalOutput == "" {
-		return "", totalUsage, iterationsUsed, fmt.Errorf("max iterations reached without completion")
+	if outcome.output == "" {
+		return outcome, fmt.Errorf("max iterations reached without completion")
+	}
+	if err := a.moderate(ctx, ModerationStageOutput, outcome.output, events); err != nil {
+		outcome.output = ""
+		return outcome, err
 	}
 
-	return finalOutput, totalUsage, iterationsUsed, nil
+	return outcome, nil
 }
 
 // Helper methods
-func (a *Agent) buildSystemPrompt(ctx context.Context) string {
-	if a.systemPrompt == nil {
-		return ""
+func (a *Agent) buildSystemPrompt(ctx context.Context, model string) string {
+	prompt := a.systemPrompt
+	if a.promptBuilder != nil {
+		prompt = a.promptBuilder.Build
+	}
+	if variant := selectPromptVariant(a.promptVariants, model); variant != nil {
+		prompt = variant
 	}
-	return a.systemPrompt(ctx)
+	if devPrompt := a.devPrompt(ctx); devPrompt != nil {
+		prompt = devPrompt
+	}
+	if rf := getRunFlags(ctx); rf != nil && rf.prompt != nil {
+		prompt = rf.prompt
+	}
+	if prompt == nil {
+		return appendPromptSections(ctx, "")
+	}
+	// A PromptBuilder with a MemorySection places memory itself.
+	var placed bool
+	text := prompt(context.WithValue(ctx, memoryPlacedKey, &placed))
+	if placed {
+		return text
+	}
+	return appendPromptSections(ctx, text)
+}
+
+// selectPromptVariant returns the variant whose key is the longest prefix of model.
+func selectPromptVariant(variants map[string]SystemPromptFunc, model string) SystemPromptFunc {
+	var selected SystemPromptFunc
+	matched := -1
+	for family, variant := range variants {
+		if variant == nil || !strings.HasPrefix(model, family) {
+			continue
+		}
+		if len(family) > matched {
+			selected = variant
+			matched = len(family)
+		}
+	}
+	return selected
 }
 
 func (a *Agent) withExecutionTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
@@ -615,10 +1150,34 @@ func (a *Agent) withToolTimeout(ctx context.Context) (context.Context, context.C
 
 func (a *Agent) handleIterationError(ctx context.Context, events chan<- Event, err error, msg string, keyvals ...any) error {
 	a.logger.Error(msg, append(keyvals, "error", err)...)
+	// A context length error on the first attempt is retried after
+	// compaction; runLoop reports it if compaction fails.
+	if retry, _ := ctx.Value(compactionKey).(bool); retry && providers.IsContextLengthExceeded(err) {
+		return err
+	}
+	// A lost previous response is retried with the full history.
+	if chained, _ := ctx.Value(previousResponseKey).(bool); chained && providers.IsPreviousResponseNotFound(err) {
+		return err
+	}
+	// Errors a fallback model can take over are reported by runWithFallback
+	// once the chain is exhausted.
+	if fallback, _ := ctx.Value(fallbackKey).(bool); fallback {
+		if _, ok := fallbackReason(err); ok {
+			return err
+		}
+	}
 	a.emit(ctx, events, Error(err))
 	return err
 }
 
+// runIteration executes a single streaming or non-streaming iteration.
+func (a *Agent) runIteration(ctx context.Context, provider providers.Provider, req providers.CompletionRequest, events chan<- Event) (*providers.CompletionResponse, error) {
+	if a.streamResponses {
+		return a.runStreamingIteration(ctx, provider, req, events)
+	}
+	return a.runNonStreamingIteration(ctx, provider, req, events)
+}
+
 // Helper methods for tracing integration
 
 // llmCallTiming holds timing information for an LLM call
@@ -685,6 +1244,9 @@ func (a *Agent) logLLMGeneration(ctx context.Context, req providers.CompletionRe
 		"text_format":         req.TextFormat,
 		"store":               req.Store,
 	}
+	if req.Seed != nil {
+		modelParams["seed"] = *req.Seed
+	}
 
 	input := map[string]any{
 		"system_prompt": req.SystemPrompt,
@@ -724,6 +1286,7 @@ func (a *Agent) logLLMGeneration(ctx context.Context, req providers.CompletionRe
 		EndTime:             timing.endTime,
 		CompletionStartTime: timing.completionStartTime,
 		Metadata: map[string]any{
+			"prompt_budget":    a.promptBudget(req).attributes(),
 			"tool_definitions": req.Tools,
 			"tool_calls": func() []providers.ToolCall {
 				if resp != nil {
@@ -748,11 +1311,29 @@ type ApprovalHandler func(ctx context.Context, request ApprovalRequest) (bool, e
 
 // ApprovalRequest contains information about a tool call that requires approval
 type ApprovalRequest struct {
-	ToolName       string         `json:"tool_name"`
-	Arguments      map[string]any `json:"arguments"`
-	Description    string         `json:"description"`     // Human-friendly description
-	ConversationID string         `json:"conversation_id"` // If available
-	CallID         string         `json:"call_id"`         // Unique call identifier
+	ToolName       string           `json:"tool_name"`
+	Arguments      map[string]any   `json:"arguments"`
+	Description    string           `json:"description"`       // Human-friendly description
+	ConversationID string           `json:"conversation_id"`   // If available
+	CallID         string           `json:"call_id"`           // Unique call identifier
+	Preview        *ApprovalPreview `json:"preview,omitempty"` // Set when the tool defines an approval preview
+}
+
+// RiskLevel hints how consequential a tool call is
+type RiskLevel string
+
+const (
+	RiskLevelLow      RiskLevel = "low"
+	RiskLevelMedium   RiskLevel = "medium"
+	RiskLevelHigh     RiskLevel = "high"
+	RiskLevelCritical RiskLevel = "critical"
+)
+
+// ApprovalPreview is a human-readable rendering of a tool call awaiting approval
+type ApprovalPreview struct {
+	Summary string    `json:"summary,omitempty"` // Short description of what will happen
+	Diff    string    `json:"diff,omitempty"`    // Optional detail such as a unified diff or SQL statement
+	Risk    RiskLevel `json:"risk,omitempty"`
 }
 
 // ApprovalConfig configures which tools require approval
@@ -799,6 +1380,13 @@ const (
 	tracerKey         contextKey = "agentkit_tracer"
 	agentNameKey      contextKey = "agentkit_agent_name"
 	iterationKey      contextKey = "agentkit_iteration"
+	eventEmitterKey   contextKey = "agentkit_event_emitter"
+	toolReporterKey   contextKey = "agentkit_tool_reporter"
+	eventSourceKey    contextKey = "agentkit_event_source"
+	traceUsageKey     contextKey = "agentkit_trace_usage"
+	compactionKey     contextKey = "agentkit_compaction_retry"
+	fallbackKey       contextKey = "agentkit_model_fallback"
+	developerKey      contextKey = "agentkit_developer_instructions"
 )
 
 // EventPublisher is a function that publishes events
@@ -860,10 +1448,15 @@ func WithTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, traceIDKey, traceID)
 }
 
-// GetTraceID retrieves the trace ID from the context.
+// GetTraceID retrieves the trace ID from the context: the one set with
+// WithTraceID, else the one reported by the context's tracer if it
+// implements TraceIDProvider, else the active OpenTelemetry span's.
 func GetTraceID(ctx context.Context) (string, bool) {
-	id, ok := ctx.Value(traceIDKey).(string)
-	return id, ok
+	if id, ok := ctx.Value(traceIDKey).(string); ok {
+		return id, true
+	}
+	traceID, _ := activeTraceIDs(ctx)
+	return traceID, traceID != ""
 }
 
 // WithSpanID adds a span ID to the context for request correlation.
@@ -871,10 +1464,26 @@ func WithSpanID(ctx context.Context, spanID string) context.Context {
 	return context.WithValue(ctx, spanIDKey, spanID)
 }
 
-// GetSpanID retrieves the span ID from the context.
+// GetSpanID retrieves the span ID from the context, resolved like
+// GetTraceID.
 func GetSpanID(ctx context.Context) (string, bool) {
-	id, ok := ctx.Value(spanIDKey).(string)
-	return id, ok
+	if id, ok := ctx.Value(spanIDKey).(string); ok {
+		return id, true
+	}
+	_, spanID := activeTraceIDs(ctx)
+	return spanID, spanID != ""
+}
+
+func activeTraceIDs(ctx context.Context) (traceID, spanID string) {
+	if provider, ok := GetTracer(ctx).(TraceIDProvider); ok {
+		if traceID, spanID = provider.TraceIDs(ctx); traceID != "" {
+			return traceID, spanID
+		}
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.TraceID().String(), sc.SpanID().String()
+	}
+	return "", ""
 }
 
 // WithAgentName adds the agent name to the context.
@@ -891,6 +1500,35 @@ func GetAgentName(ctx context.Context) (string, bool) {
 	return name, ok
 }
 
+// WithEventSource sets the source stamped on events emitted with ctx.
+func WithEventSource(ctx context.Context, source EventSource) context.Context {
+	return context.WithValue(ctx, eventSourceKey, source)
+}
+
+// GetEventSource retrieves the event source from the context.
+func GetEventSource(ctx context.Context) (EventSource, bool) {
+	source, ok := ctx.Value(eventSourceKey).(EventSource)
+	return source, ok
+}
+
+// nestedEventSource returns the source of an agent run started with ctx: a
+// child of the source already in ctx, if any.
+func nestedEventSource(ctx context.Context, agentName string) EventSource {
+	parent, ok := GetEventSource(ctx)
+	if !ok {
+		return EventSource{Agent: agentName, Path: agentName}
+	}
+	source := EventSource{
+		Agent: agentName,
+		Path:  parent.Path + "/" + agentName,
+		Depth: parent.Depth + 1,
+	}
+	if reporter := GetToolReporter(ctx); reporter != nil {
+		source.ParentCallID = reporter.toolID
+	}
+	return source
+}
+
 // WithIteration adds the iteration index to the context.
 func WithIteration(ctx context.Context, iteration int) context.Context {
 	if iteration <= 0 {
@@ -918,9 +1556,11 @@ func GetTracer(ctx context.Context) Tracer {
 }
 
 // buildCompletionRequest creates a provider-agnostic completion request from current conversation state.
-func (a *Agent) buildCompletionRequest(conversationHistory []providers.Message) providers.CompletionRequest {
+func (a *Agent) buildCompletionRequest(ctx context.Context, conversationHistory []providers.Message) providers.CompletionRequest {
 	// Build tool definitions
+	unlockTools := a.readTools()
 	tools := make([]providers.ToolDefinition, 0, len(a.tools))
+	tagged := false
 	if len(a.tools) > 0 {
 		names := make([]string, 0, len(a.tools))
 		for name := range a.tools {
@@ -929,21 +1569,38 @@ func (a *Agent) buildCompletionRequest(conversationHistory []providers.Message)
 		sort.Strings(names)
 		for _, name := range names {
 			tool := a.tools[name]
-			tools = append(tools, tool.ToToolDefinition())
+			if !toolEnabled(ctx, &tool) {
+				continue
+			}
+			def := tool.ToToolDefinition()
+			if description, ok := devToolDescription(ctx, name); ok {
+				def.Description = description
+			}
+			if a.toolDescriptionLimit > 0 {
+				def = compactToolDefinition(def, a.toolDescriptionLimit)
+			}
+			if tool.tags != (ToolTags{}) {
+				def.Description = tool.tags.describe(def.Description)
+				tagged = true
+			}
+			tools = append(tools, def)
 		}
 	}
+	unlockTools()
 
 	toolChoice := a.toolChoice
 	if toolChoice == "" {
 		toolChoice = "auto"
 	}
 
+	model := a.runModel(ctx)
 	req := providers.CompletionRequest{
-		Model:             a.model,
-		SystemPrompt:      a.buildSystemPrompt(context.Background()),
-		Messages:          conversationHistory,
+		Model:             model,
+		SystemPrompt:      a.buildSystemPrompt(ctx, model),
+		Messages:          withDeveloperMessages(ctx, conversationHistory),
 		Tools:             tools,
-		Temperature:       a.temperature,
+		HostedTools:       a.hostedTools,
+		Temperature:       a.runTemperature(ctx),
 		MaxTokens:         0, // Let provider use default
 		TopP:              0, // Let provider use default
 		ToolChoice:        toolChoice,
@@ -953,13 +1610,35 @@ func (a *Agent) buildCompletionRequest(conversationHistory []providers.Message)
 		TextVerbosity:     a.textVerbosity,
 		TextFormat:        a.textFormat,
 		Store:             a.store,
+		Audio:             audioOutput(ctx),
+	}
+	if tagged {
+		req.SystemPrompt = strings.TrimSpace(req.SystemPrompt + "\n\n" + toolTagsGuidance)
+	}
+	applyDevSettings(ctx, &req)
+	a.applyOutputSchema(ctx, &req)
+	applyDocument(ctx, &req)
+	if length := a.outputLength(ctx); length != nil {
+		req.MaxTokens = length.MaxTokens
+		if instruction := length.instruction(); instruction != "" {
+			req.SystemPrompt = strings.TrimSpace(req.SystemPrompt + "\n\n" + instruction)
+		}
+	}
+	if a.deterministic {
+		seed := a.seed
+		req.Temperature = 0
+		req.Seed = &seed
+		// Reasoning models reject any temperature, including zero.
+		if info, ok := LookupModelInfo(model); !ok || info.Temperature {
+			req.Deterministic = true
+		}
 	}
 
 	return req
 }
 
 // runNonStreamingIteration executes a single non-streaming iteration.
-func (a *Agent) runNonStreamingIteration(ctx context.Context, req providers.CompletionRequest, events chan<- Event) (*providers.CompletionResponse, error) {
+func (a *Agent) runNonStreamingIteration(ctx context.Context, provider providers.Provider, req providers.CompletionRequest, events chan<- Event) (*providers.CompletionResponse, error) {
 	callCtx := a.applyLLMCall(ctx, req)
 	callCtx, cancel := a.withLLMTimeout(callCtx)
 	if cancel != nil {
@@ -968,15 +1647,22 @@ func (a *Agent) runNonStreamingIteration(ctx context.Context, req providers.Comp
 
 	// Start timing for tracing
 	callCtx = startLLMCallTiming(callCtx)
+	callCtx = a.observeQuota(callCtx, req.Model, events)
 
-	resp, err := a.provider.Complete(callCtx, req)
+	resp, err := provider.Complete(callCtx, req)
 	if err != nil {
 		iterationErr := fmt.Errorf("provider completion error: %w", err)
 		a.applyLLMResponse(callCtx, nil, iterationErr)
 		a.logLLMGeneration(callCtx, req, nil, iterationErr)
-		return nil, a.handleIterationError(callCtx, events, iterationErr, "completion failed", "model", a.model)
+		return nil, a.handleIterationError(callCtx, events, iterationErr, "completion failed", "model", req.Model)
 	}
 
+	for _, call := range resp.HostedToolCalls {
+		a.emitHostedToolCall(ctx, events, call)
+	}
+	if len(resp.Audio) > 0 && req.Audio != nil {
+		a.emit(ctx, events, AudioDelta(resp.Audio, req.Audio.Format))
+	}
 	a.applyLLMResponse(callCtx, resp, nil)
 	a.logLLMGeneration(callCtx, req, resp, nil)
 
@@ -991,7 +1677,7 @@ func (a *Agent) runNonStreamingIteration(ctx context.Context, req providers.Comp
 }
 
 // runStreamingIteration executes a single streaming iteration.
-func (a *Agent) runStreamingIteration(ctx context.Context, req providers.CompletionRequest, events chan<- Event) (*providers.CompletionResponse, error) {
+func (a *Agent) runStreamingIteration(ctx context.Context, provider providers.Provider, req providers.CompletionRequest, events chan<- Event) (*providers.CompletionResponse, error) {
 	callCtx := a.applyLLMCall(ctx, req)
 	callCtx, cancel := a.withLLMTimeout(callCtx)
 	if cancel != nil {
@@ -1000,37 +1686,59 @@ func (a *Agent) runStreamingIteration(ctx context.Context, req providers.Complet
 
 	// Start timing for tracing
 	callCtx = startLLMCallTiming(callCtx)
+	callCtx = a.observeQuota(callCtx, req.Model, events)
 
-	stream, err := a.provider.Stream(callCtx, req)
+	streamCtx, abortStream := context.WithCancel(callCtx)
+	defer abortStream()
+	stream, err := provider.Stream(streamCtx, req)
 	if err != nil {
 		iterationErr := fmt.Errorf("provider stream error: %w", err)
 		a.applyLLMResponse(callCtx, nil, iterationErr)
-		return nil, a.handleIterationError(callCtx, events, iterationErr, "streaming failed", "model", a.model)
+		return nil, a.handleIterationError(callCtx, events, iterationErr, "streaming failed", "model", req.Model)
 	}
 	defer stream.Close()
+	watchdog := a.watchStream(stream, abortStream)
+	defer watchdog.stop()
+	chunks := 0
 
 	// Accumulate streaming response
 	var content string
 	var reasoningSummary string
+	var reasoningChunks []string
+	var annotations []providers.Annotation
+	var hostedCalls []providers.HostedToolCall
 	var toolCalls []providers.ToolCall
 	var usage *providers.TokenUsage
 	var finishReason providers.FinishReason
+	var responseID string
 
 	// Track tool calls being built
 	activeToolCalls := make(map[string]*providers.ToolCall)
+	var toolCallOrder []string // Arrival order, so history is identical across runs
 	toolArgsRaw := make(map[string]string)
+	toolArgsPreview := make(map[string]string)
 
 	for {
 		chunk, err := stream.Next()
 		if err != nil {
+			if watchdog.fired() {
+				a.applyLLMResponse(callCtx, nil, ErrStreamStalled)
+				return a.retryStalledStream(ctx, provider, req, events, chunks)
+			}
 			if err.Error() == "EOF" || err.Error() == "io: EOF" {
 				break
 			}
-			return nil, fmt.Errorf("stream read error: %w", err)
+			readErr := fmt.Errorf("stream read error: %w", err)
+			if content != "" || reasoningSummary != "" || len(toolArgsPreview) > 0 {
+				return nil, interruptedStreamError{readErr}
+			}
+			return nil, readErr
 		}
+		watchdog.reset()
+		chunks++
 
 		if timing := getLLMCallTiming(callCtx); timing != nil && timing.completionStartTime == nil {
-			if chunk.Content != "" || chunk.ReasoningSummary != "" || chunk.ToolCallID != "" || chunk.ToolArgs != "" {
+			if chunk.Content != "" || chunk.ReasoningSummary != "" || chunk.ToolCallID != "" || chunk.ToolArgs != "" || chunk.ToolArgsDelta != "" || len(chunk.Audio) > 0 {
 				start := time.Now()
 				timing.completionStartTime = &start
 			}
@@ -1044,9 +1752,25 @@ func (a *Agent) runStreamingIteration(ctx context.Context, req providers.Complet
 
 		if chunk.ReasoningSummary != "" {
 			reasoningSummary += chunk.ReasoningSummary
+			if a.thinkingTrace != nil {
+				reasoningChunks = append(reasoningChunks, chunk.ReasoningSummary)
+			}
 			a.emit(ctx, events, ReasoningChunk(chunk.ReasoningSummary))
 		}
 
+		if len(chunk.Audio) > 0 && req.Audio != nil {
+			a.emit(ctx, events, AudioDelta(chunk.Audio, req.Audio.Format))
+		}
+
+		if len(chunk.Annotations) > 0 {
+			annotations = append(annotations, chunk.Annotations...)
+		}
+
+		if call := chunk.HostedToolCall; call != nil {
+			hostedCalls = recordHostedToolCall(hostedCalls, *call)
+			a.emitHostedToolCall(ctx, events, *call)
+		}
+
 		// Handle tool call chunks
 		if chunk.ToolCallID != "" {
 			if activeToolCalls[chunk.ToolCallID] == nil {
@@ -1054,16 +1778,31 @@ func (a *Agent) runStreamingIteration(ctx context.Context, req providers.Complet
 					ID:        chunk.ToolCallID,
 					Arguments: make(map[string]any),
 				}
+				toolCallOrder = append(toolCallOrder, chunk.ToolCallID)
 			}
 			tc := activeToolCalls[chunk.ToolCallID]
-			if chunk.ToolName != "" {
+			if chunk.ToolArgsDelta != "" {
+				toolArgsPreview[chunk.ToolCallID] += chunk.ToolArgsDelta
+				name := chunk.ToolName
+				if name == "" {
+					name = tc.Name
+				}
+				a.emit(ctx, events, ToolArgsDelta(name, chunk.ToolCallID, chunk.ToolArgsDelta, toolArgsPreview[chunk.ToolCallID]))
+			} else if chunk.ToolName != "" {
 				tc.Name = chunk.ToolName
 			}
 			if chunk.ToolArgs != "" {
 				toolArgsRaw[chunk.ToolCallID] = chunk.ToolArgs
 				var args map[string]any
-				if err := json.Unmarshal([]byte(chunk.ToolArgs), &args); err == nil {
+				repaired, err := jsonrepair.Unmarshal(req.Model, []byte(chunk.ToolArgs), &args)
+				if repaired {
+					a.logger.Warn("malformed tool arguments", "tool", tc.Name, "model", req.Model, "repaired", err == nil)
+				}
+				if err == nil {
 					tc.Arguments = args
+					tc.RawArguments = ""
+				} else {
+					tc.RawArguments = chunk.ToolArgs
 				}
 			}
 		}
@@ -1071,12 +1810,16 @@ func (a *Agent) runStreamingIteration(ctx context.Context, req providers.Complet
 		// Handle completion
 		if chunk.IsComplete {
 			finishReason = chunk.FinishReason
+			if chunk.ResponseID != "" {
+				responseID = chunk.ResponseID
+			}
 			if chunk.Usage != nil {
 				usage = chunk.Usage
 			}
 
 			// Collect completed tool calls
-			for _, tc := range activeToolCalls {
+			for _, id := range toolCallOrder {
+				tc := activeToolCalls[id]
 				if len(tc.Arguments) == 0 {
 					if raw, ok := toolArgsRaw[tc.ID]; ok {
 						var args map[string]any
@@ -1097,20 +1840,31 @@ func (a *Agent) runStreamingIteration(ctx context.Context, req providers.Complet
 		}
 	}
 
+	if responseID == "" {
+		responseID = fmt.Sprintf("stream-%d", len(content)) // Generate ID
+	}
 	resp := &providers.CompletionResponse{
-		ID:               fmt.Sprintf("stream-%d", len(content)), // Generate ID
+		ID:               responseID,
 		Content:          content,
 		ToolCalls:        ensureToolCallIDs(toolCalls),
 		FinishReason:     finishReason,
-		Model:            a.model,
+		Model:            req.Model,
 		ReasoningSummary: reasoningSummary,
+		Annotations:      annotations,
+		HostedToolCalls:  hostedCalls,
 	}
 	if usage != nil {
 		resp.Usage = *usage
 	}
 
 	a.applyLLMResponse(callCtx, resp, nil)
-	a.logLLMGeneration(callCtx, req, resp, nil)
+	traced := resp
+	if a.thinkingTrace != nil && len(reasoningChunks) > 0 {
+		sampled := *resp
+		sampled.ReasoningSummary = a.thinkingTrace.sample(reasoningChunks)
+		traced = &sampled
+	}
+	a.logLLMGeneration(callCtx, req, traced, nil)
 
 	return resp, nil
 }
@@ -1223,27 +1977,31 @@ func ensureToolCallIDs(toolCalls []providers.ToolCall) []providers.ToolCall {
 }
 
 func (a *Agent) executeToolCall(ctx context.Context, toolCall providers.ToolCall, events chan<- Event) providers.Message {
-	tool, exists := a.tools[toolCall.Name]
+	tool, exists := a.lookupTool(toolCall.Name)
 
 	// Check if tool exists
-	if !exists {
-		a.logger.Warn("tool not found", "tool", toolCall.Name)
-		a.emit(ctx, events, ToolError(toolCall.Name, fmt.Errorf("tool not found")))
-		return providers.Message{
-			Role:       providers.RoleTool,
-			Content:    fmt.Sprintf("Error: Tool '%s' not found", toolCall.Name),
-			ToolCallID: toolCall.ID,
-		}
+	if !exists || !toolEnabled(ctx, &tool) {
+		return a.unknownToolCall(ctx, toolCall, events)
+	}
+
+	if rejected := a.validateToolArgs(ctx, &tool, toolCall, events); rejected != nil {
+		return *rejected
 	}
 
 	args := toolCall.Arguments
 	if args == nil {
 		args = map[string]any{}
 	}
-	a.emit(ctx, events, ActionDetected(tool.FormatPending(args), toolCall.ID))
+	detected := ActionDetected(tool.FormatPending(args), toolCall.ID)
+	detected.Data["tool_name"] = toolCall.Name
+	detected.Data["arguments"] = args
+	if tool.tags != (ToolTags{}) {
+		detected.Data["tool_tags"] = tool.tags.attributes()
+	}
+	a.emit(ctx, events, detected)
 
 	// Check approval if required
-	if a.approvalConfig.requiresApproval(toolCall.Name) {
+	if tool.requiresApproval || a.approvalConfig.requiresApproval(toolCall.Name) {
 		approved, rejectMsg := a.requestToolApproval(ctx, toolCall, tool, events)
 		if !approved {
 			return *rejectMsg
@@ -1256,6 +2014,13 @@ func (a *Agent) executeToolCall(ctx context.Context, toolCall providers.ToolCall
 	if cancel != nil {
 		defer cancel()
 	}
+	toolCtx = context.WithValue(toolCtx, toolReporterKey, &ToolReporter{
+		toolName: toolCall.Name,
+		toolID:   toolCall.ID,
+		agent:    a,
+		ctx:      ctx,
+		events:   events,
+	})
 
 	// Execute tool with retry
 	var result any
@@ -1276,6 +2041,9 @@ func (a *Agent) executeToolCall(ctx context.Context, toolCall providers.ToolCall
 	result, err = retry.WithRetry(toolCtx, a.retryConfig, func() (any, error) {
 		return tool.Execute(toolCtx, string(argsJSON))
 	})
+	if err == nil {
+		err = tool.checkResult(result)
+	}
 
 	// Complete tool execution
 	a.applyToolComplete(toolCtx, toolCall.Name, result, err)
@@ -1308,6 +2076,15 @@ func (a *Agent) requestToolApproval(ctx context.Context, toolCall providers.Tool
 		CallID:      toolCall.ID,
 	}
 
+	if tool.approvalPreview != nil {
+		preview, err := tool.approvalPreview(ctx, toolCall.Arguments)
+		if err != nil {
+			a.logger.Warn("approval preview failed", "tool", toolCall.Name, "error", err)
+		} else {
+			approvalReq.Preview = &preview
+		}
+	}
+
 	// Emit approval request
 	a.emit(ctx, events, ApprovalNeeded(approvalReq))
 
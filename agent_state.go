@@ -0,0 +1,259 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxStateSaveAttempts bounds how often a run re-applies its state changes
+// after losing an optimistic-concurrency race.
+const maxStateSaveAttempts = 3
+
+// Agent state errors.
+var (
+	ErrAgentStateNotFound = errors.New("agentkit: agent state not found")
+	ErrStateConflict      = errors.New("agentkit: agent state was modified concurrently")
+)
+
+// AgentState is evolving state owned by an agent rather than a conversation:
+// counters, learned preferences, calibration data. It outlives conversations
+// and deployments.
+type AgentState struct {
+	AgentID   string         `json:"agent_id"`
+	Version   int64          `json:"version"`
+	Data      map[string]any `json:"data"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// AgentStateStore persists AgentState with optimistic concurrency.
+type AgentStateStore interface {
+	// Load returns the state for agentID or ErrAgentStateNotFound.
+	Load(ctx context.Context, agentID string) (AgentState, error)
+	// Save stores state if the stored version still equals state.Version
+	// (0 for state that has never been saved) and returns it with the new
+	// version. A stale version returns ErrStateConflict.
+	Save(ctx context.Context, state AgentState) (AgentState, error)
+}
+
+// MemoryAgentStateStore provides an in-memory implementation of AgentStateStore.
+// Useful for testing and development. Not suitable for production.
+type MemoryAgentStateStore struct {
+	mu     sync.Mutex
+	states map[string]AgentState
+}
+
+// NewMemoryAgentStateStore creates a new in-memory agent state store.
+func NewMemoryAgentStateStore() *MemoryAgentStateStore {
+	return &MemoryAgentStateStore{states: make(map[string]AgentState)}
+}
+
+// Load implements AgentStateStore.
+func (s *MemoryAgentStateStore) Load(ctx context.Context, agentID string) (AgentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[agentID]
+	if !ok {
+		return AgentState{}, ErrAgentStateNotFound
+	}
+	state.Data = cloneStateData(state.Data)
+	return state, nil
+}
+
+// Save implements AgentStateStore.
+func (s *MemoryAgentStateStore) Save(ctx context.Context, state AgentState) (AgentState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current := s.states[state.AgentID]; current.Version != state.Version {
+		return AgentState{}, fmt.Errorf("%w: have version %d, stored %d", ErrStateConflict, state.Version, current.Version)
+	}
+	state.Version++
+	state.UpdatedAt = time.Now()
+	state.Data = cloneStateData(state.Data)
+	s.states[state.AgentID] = state
+
+	state.Data = cloneStateData(state.Data)
+	return state, nil
+}
+
+// StateHandle gives tools and middleware access to the agent state during a
+// run. Changes are recorded and saved when the run completes; if another run
+// saved first, they are re-applied on top of the newer state.
+type StateHandle struct {
+	mu    sync.Mutex
+	state AgentState
+	ops   []func(map[string]any)
+}
+
+func newStateHandle(state AgentState) *StateHandle {
+	if state.Data == nil {
+		state.Data = make(map[string]any)
+	}
+	return &StateHandle{state: state}
+}
+
+// Get returns the value stored under key.
+func (h *StateHandle) Get(key string) (any, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v, ok := h.state.Data[key]
+	return v, ok
+}
+
+// Set stores value under key.
+func (h *StateHandle) Set(key string, value any) {
+	h.apply(func(data map[string]any) { data[key] = value })
+}
+
+// Delete removes key.
+func (h *StateHandle) Delete(key string) {
+	h.apply(func(data map[string]any) { delete(data, key) })
+}
+
+// Increment adds delta to the number stored under key and returns the result.
+// Increments from concurrent runs add up rather than overwrite each other.
+func (h *StateHandle) Increment(key string, delta float64) float64 {
+	h.apply(func(data map[string]any) { data[key] = toFloat(data[key]) + delta })
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return toFloat(h.state.Data[key])
+}
+
+// Snapshot returns a copy of the current state data.
+func (h *StateHandle) Snapshot() map[string]any {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return cloneStateData(h.state.Data)
+}
+
+// Version returns the stored version the run started from.
+func (h *StateHandle) Version() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.state.Version
+}
+
+func (h *StateHandle) apply(op func(map[string]any)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	op(h.state.Data)
+	h.ops = append(h.ops, op)
+}
+
+// rebase replays the recorded changes on top of a newer stored state.
+func (h *StateHandle) rebase(latest AgentState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	latest.Data = cloneStateData(latest.Data)
+	if latest.Data == nil {
+		latest.Data = make(map[string]any)
+	}
+	for _, op := range h.ops {
+		op(latest.Data)
+	}
+	h.state = latest
+}
+
+func (h *StateHandle) pending() (AgentState, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state := h.state
+	state.Data = cloneStateData(h.state.Data)
+	return state, len(h.ops) > 0
+}
+
+const agentStateKey contextKey = "agentkit_agent_state"
+
+// GetAgentState retrieves the state handle of the running agent from the context.
+func GetAgentState(ctx context.Context) (*StateHandle, bool) {
+	h, ok := ctx.Value(agentStateKey).(*StateHandle)
+	return h, ok
+}
+
+// State loads the agent's persisted state.
+func (a *Agent) State(ctx context.Context) (AgentState, error) {
+	if a.stateStore == nil {
+		return AgentState{}, errors.New("agentkit: no agent state store configured")
+	}
+	state, err := a.stateStore.Load(ctx, a.agentName)
+	if errors.Is(err, ErrAgentStateNotFound) {
+		return AgentState{AgentID: a.agentName, Data: map[string]any{}}, nil
+	}
+	return state, err
+}
+
+// loadState attaches the agent state to ctx at the start of a run.
+func (a *Agent) loadState(ctx context.Context) context.Context {
+	if a.stateStore == nil {
+		return ctx
+	}
+	state, err := a.State(ctx)
+	if err != nil {
+		a.logger.Warn("agent state load failed", "agent", a.agentName, "error", err)
+		return ctx
+	}
+	return context.WithValue(ctx, agentStateKey, newStateHandle(state))
+}
+
+// saveState persists the state changes made during a run, re-applying them
+// on top of newer state when another run saved first. Shadow runs save
+// nothing.
+func (a *Agent) saveState(ctx context.Context) {
+	h, ok := GetAgentState(ctx)
+	if !ok || a.stateStore == nil || IsShadowRun(ctx) {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for attempt := 1; ; attempt++ {
+		state, dirty := h.pending()
+		if !dirty {
+			return
+		}
+		_, err := a.stateStore.Save(ctx, state)
+		if err == nil {
+			return
+		}
+		if !errors.Is(err, ErrStateConflict) || attempt == maxStateSaveAttempts {
+			a.logger.Warn("agent state save failed", "agent", a.agentName, "attempts", attempt, "error", err)
+			return
+		}
+		latest, err := a.State(ctx)
+		if err != nil {
+			a.logger.Warn("agent state reload failed", "agent", a.agentName, "error", err)
+			return
+		}
+		h.rebase(latest)
+	}
+}
+
+func cloneStateData(data map[string]any) map[string]any {
+	if data == nil {
+		return nil
+	}
+	clone := make(map[string]any, len(data))
+	for k, v := range data {
+		clone[k] = v
+	}
+	return clone
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case float32:
+		return float64(n)
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case int32:
+		return float64(n)
+	default:
+		return 0
+	}
+}
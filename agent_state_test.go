@@ -0,0 +1,99 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestMemoryAgentStateStore_OptimisticConcurrency(t *testing.T) {
+	store := NewMemoryAgentStateStore()
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "triage"); !errors.Is(err, ErrAgentStateNotFound) {
+		t.Fatalf("Load() error = %v, want ErrAgentStateNotFound", err)
+	}
+	saved, err := store.Save(ctx, AgentState{AgentID: "triage", Data: map[string]any{"threshold": 0.5}})
+	if err != nil || saved.Version != 1 {
+		t.Fatalf("Save() = %+v, %v", saved, err)
+	}
+
+	stale := AgentState{AgentID: "triage", Data: map[string]any{"threshold": 0.9}}
+	if _, err := store.Save(ctx, stale); !errors.Is(err, ErrStateConflict) {
+		t.Errorf("stale Save() error = %v, want ErrStateConflict", err)
+	}
+
+	saved.Data["threshold"] = 0.7
+	if saved, err = store.Save(ctx, saved); err != nil || saved.Version != 2 {
+		t.Errorf("Save() = %+v, %v", saved, err)
+	}
+}
+
+func stateTestAgent(t *testing.T, store AgentStateStore, onCall func(ctx context.Context)) *Agent {
+	t.Helper()
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "count", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)
+	agent, err := New(Config{
+		Provider:   provider,
+		Model:      "test-model",
+		AgentName:  "triage",
+		StateStore: store,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("count").
+		WithDescription("Count calls").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			state, ok := GetAgentState(ctx)
+			if !ok {
+				return nil, errors.New("no agent state")
+			}
+			onCall(ctx)
+			return state.Increment("calls", 1), nil
+		}).
+		Build())
+	return agent
+}
+
+func TestAgentState_LoadedAndSavedAroundRun(t *testing.T) {
+	store := NewMemoryAgentStateStore()
+	agent := stateTestAgent(t, store, func(context.Context) {})
+
+	collectEvents(agent.Run(context.Background(), "count"), 2*time.Second)
+
+	state, err := agent.State(context.Background())
+	if err != nil {
+		t.Fatalf("State() error = %v", err)
+	}
+	if state.Version != 1 || state.Data["calls"] != 1.0 {
+		t.Errorf("state = %+v", state)
+	}
+}
+
+func TestAgentState_ReappliesChangesAfterConflict(t *testing.T) {
+	store := NewMemoryAgentStateStore()
+	_, _ = store.Save(context.Background(), AgentState{AgentID: "triage", Data: map[string]any{"calls": 5.0}})
+
+	// Another deployment saves while this run is in flight.
+	agent := stateTestAgent(t, store, func(ctx context.Context) {
+		latest, _ := store.Load(ctx, "triage")
+		latest.Data["calls"] = 10.0
+		latest.Data["other"] = "kept"
+		if _, err := store.Save(ctx, latest); err != nil {
+			t.Errorf("concurrent Save() error = %v", err)
+		}
+	})
+
+	collectEvents(agent.Run(context.Background(), "count"), 2*time.Second)
+
+	state, _ := agent.State(context.Background())
+	if state.Version != 3 || state.Data["calls"] != 11.0 || state.Data["other"] != "kept" {
+		t.Errorf("state = %+v, want increment applied on top of the concurrent write", state)
+	}
+}
@@ -3,11 +3,16 @@ package agentkit
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/darkostanimirovic/agentkit/jsonrepair"
 	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
 )
 
 func TestNew(t *testing.T) {
@@ -546,3 +551,190 @@ func TestConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestAgent_SystemPromptVariants(t *testing.T) {
+	prompt := func(text string) SystemPromptFunc {
+		return func(ctx context.Context) string { return text }
+	}
+
+	agent, err := New(Config{
+		APIKey:       "test-key",
+		Model:        "gpt-5-mini",
+		SystemPrompt: prompt("default"),
+		SystemPromptVariants: map[string]SystemPromptFunc{
+			"gpt":   prompt("gpt"),
+			"gpt-5": prompt("gpt-5"),
+			"o3":    prompt("o3"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "gpt-5-mini", want: "gpt-5"},
+		{model: "gpt-4o", want: "gpt"},
+		{model: "o3-mini", want: "o3"},
+		{model: "claude-sonnet", want: "default"},
+	}
+	for _, tt := range tests {
+		if got := agent.buildSystemPrompt(context.Background(), tt.model); got != tt.want {
+			t.Errorf("buildSystemPrompt(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+
+	req := agent.buildCompletionRequest(context.Background(), nil)
+	if req.SystemPrompt != "gpt-5" {
+		t.Errorf("expected request to use gpt-5 variant, got %q", req.SystemPrompt)
+	}
+}
+
+func TestAgent_DeterministicRequests(t *testing.T) {
+	newAgent := func() *Agent {
+		agent, err := New(Config{
+			APIKey:        "test-key",
+			Model:         "gpt-4o",
+			Temperature:   0.7,
+			Deterministic: true,
+			Seed:          42,
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		for _, name := range []string{"zeta", "alpha", "mid"} {
+			agent.AddTool(NewTool(name).
+				WithParameter("filter", Object().
+					WithProperty("b", String().Required()).
+					WithProperty("a", String().Required()).
+					WithProperty("c", Integer().Optional())).
+				Build())
+		}
+		return agent
+	}
+
+	first, err := json.Marshal(newAgent().buildCompletionRequest(context.Background(), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 10 {
+		again, _ := json.Marshal(newAgent().buildCompletionRequest(context.Background(), nil))
+		if string(again) != string(first) {
+			t.Fatalf("requests differ:\n%s\n%s", first, again)
+		}
+	}
+
+	req := newAgent().buildCompletionRequest(context.Background(), nil)
+	if req.Temperature != 0 || !req.Deterministic || req.Seed == nil || *req.Seed != 42 {
+		t.Errorf("request = temperature %v, deterministic %v, seed %v", req.Temperature, req.Deterministic, req.Seed)
+	}
+	if req.Tools[0].Name != "alpha" || req.Tools[2].Name != "zeta" {
+		t.Errorf("tools not sorted: %v, %v, %v", req.Tools[0].Name, req.Tools[1].Name, req.Tools[2].Name)
+	}
+
+	// Reasoning models reject a temperature, so only the seed is pinned.
+	reasoning, err := New(Config{APIKey: "test-key", Model: "o3-mini", Temperature: 0.7, Deterministic: true})
+	if err != nil {
+		t.Fatalf("New(o3-mini) error = %v", err)
+	}
+	if req := reasoning.buildCompletionRequest(context.Background(), nil); req.Deterministic || req.Seed == nil {
+		t.Errorf("reasoning request = deterministic %v, seed %v", req.Deterministic, req.Seed)
+	}
+}
+
+func TestAgent_StreamedToolCallsKeepArrivalOrder(t *testing.T) {
+	names := []string{"delta", "alpha", "charlie", "bravo", "echo"}
+	var chunks []providers.StreamChunk
+	for i, name := range names {
+		chunks = append(chunks, providers.StreamChunk{ToolCallID: fmt.Sprintf("call-%d", i), ToolName: name, ToolArgs: "{}"})
+	}
+	chunks = append(chunks, providers.StreamChunk{IsComplete: true, FinishReason: providers.FinishReasonToolCalls})
+
+	for range 5 {
+		mock := mockprovider.New().
+			WithStream(chunks).
+			WithStream([]providers.StreamChunk{{Content: "done"}, {IsComplete: true, FinishReason: providers.FinishReasonStop}})
+		agent, err := New(Config{Provider: mock, Model: "test-model", StreamResponses: true})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		for _, name := range names {
+			agent.AddTool(echoTool(name))
+		}
+
+		var order []string
+		for _, e := range collectEvents(agent.Run(context.Background(), "go"), time.Second) {
+			if e.Type == EventTypeActionDetected {
+				order = append(order, e.Data["tool_id"].(string))
+			}
+		}
+		if strings.Join(order, ",") != "call-0,call-1,call-2,call-3,call-4" {
+			t.Fatalf("tool call order = %v, want arrival order", order)
+		}
+	}
+}
+
+func TestAgent_RepairsMalformedStreamedToolArgs(t *testing.T) {
+	jsonrepair.ResetStats()
+	defer jsonrepair.ResetStats()
+
+	mock := mockprovider.New().
+		WithStream([]providers.StreamChunk{
+			{ToolCallID: "call-1", ToolName: "lookup", ToolArgs: `{order_id: "A-7", include_items: True,}`},
+			{IsComplete: true, FinishReason: providers.FinishReasonToolCalls},
+		}).
+		WithStream([]providers.StreamChunk{{Content: "done"}, {IsComplete: true, FinishReason: providers.FinishReasonStop}})
+	agent, err := New(Config{Provider: mock, Model: "test-model", StreamResponses: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var got map[string]any
+	agent.AddTool(NewTool("lookup").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			got = args
+			return "ok", nil
+		}).
+		Build())
+
+	collectEvents(agent.Run(context.Background(), "go"), time.Second)
+	if got["order_id"] != "A-7" || got["include_items"] != true {
+		t.Errorf("tool args = %v, want repaired arguments", got)
+	}
+	if stats := jsonrepair.Stats()["test-model"]; stats.Repaired != 1 {
+		t.Errorf("repair stats = %+v", stats)
+	}
+}
+
+func TestNew_PicksBuiltinProviderByModel(t *testing.T) {
+	tests := map[string]string{
+		"claude-sonnet-4-5": "anthropic",
+		"gpt-4o":            "openai",
+	}
+	for model, want := range tests {
+		agent, err := New(Config{APIKey: "test-key", Model: model})
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", model, err)
+		}
+		if got := agent.provider.Name(); got != want {
+			t.Errorf("New(%s) provider = %s, want %s", model, got, want)
+		}
+	}
+}
+
+func TestNew_BaseURLUsesOpenAICompatibleProvider(t *testing.T) {
+	for _, model := range []string{"claude-sonnet-4-5", "meta-llama/llama-3.1-70b-instruct"} {
+		cfg := Config{Model: model, BaseURL: "http://localhost:8000/v1"}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate(%s) error = %v; want no API key or known model required", model, err)
+		}
+		agent, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", model, err)
+		}
+		if got := agent.provider.Name(); got != "openai" {
+			t.Errorf("New(%s) provider = %s, want openai", model, got)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package agentkittest provides helpers for regression-testing agent
+// behavior in CI. A Fixture holds recorded inputs, and optionally the runs
+// recorded for them; AssertBehavior replays the inputs through an agent
+// (live or backed by a mock provider) and checks invariants such as "must
+// call tool X" or "at most N iterations", so prompt edits that change
+// behavior fail the build.
+package agentkittest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// DefaultTimeout bounds each replayed run when Fixture.Timeout is zero.
+const DefaultTimeout = 2 * time.Minute
+
+// Fixture is a set of inputs to replay through an agent.
+type Fixture struct {
+	Name    string        `json:"name,omitempty"`
+	Cases   []Case        `json:"cases"`
+	Timeout time.Duration `json:"timeout,omitempty"` // Per run; zero uses DefaultTimeout
+}
+
+// Case is one recorded input. Events is the run recorded for it, if any;
+// MatchesRecording compares new runs against it.
+type Case struct {
+	Name   string           `json:"name"`
+	Input  string           `json:"input"`
+	Events []agentkit.Event `json:"events,omitempty"`
+}
+
+// LoadFixture reads a fixture from a JSON file.
+func LoadFixture(path string) (Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return Fixture{}, fmt.Errorf("agentkittest: parse fixture %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Save writes the fixture to a JSON file.
+func (f Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Record runs every case through agent and stores the events as the case's
+// recording, e.g. to refresh a fixture after an intended behavior change.
+func Record(ctx context.Context, fixture Fixture, agent *agentkit.Agent) Fixture {
+	recorded := fixture
+	recorded.Cases = make([]Case, len(fixture.Cases))
+	for i, c := range fixture.Cases {
+		c.Events = run(ctx, agent, c.Input, fixture.timeout())
+		recorded.Cases[i] = c
+	}
+	return recorded
+}
+
+func (f Fixture) timeout() time.Duration {
+	if f.Timeout > 0 {
+		return f.Timeout
+	}
+	return DefaultTimeout
+}
+
+// Run is a replayed case, passed to assertions.
+type Run struct {
+	Case   Case
+	Events []agentkit.Event
+	// Summary and ToolCalls describe the root agent's run.
+	Summary   agentkit.ShadowRun
+	ToolCalls []agentkit.RecordedToolCall
+	// Diff compares the run with the case's recording. It is nil when the
+	// case has none.
+	Diff *agentkit.RunDiff
+}
+
+// Assertion checks one invariant of a run and returns an error describing
+// the violation.
+type Assertion func(run *Run) error
+
+// AssertBehavior replays every case of fixture through agent, each as a
+// subtest, and reports every assertion that fails.
+func AssertBehavior(t *testing.T, fixture Fixture, agent *agentkit.Agent, assertions ...Assertion) {
+	t.Helper()
+	if len(fixture.Cases) == 0 {
+		t.Fatalf("fixture %q has no cases", fixture.Name)
+	}
+	for i, c := range fixture.Cases {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("case_%d", i)
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			r := Replay(context.Background(), c, agent, fixture.timeout())
+			for _, assert := range assertions {
+				if err := assert(r); err != nil {
+					t.Errorf("input %q: %v", c.Input, err)
+				}
+			}
+		})
+	}
+}
+
+// Replay runs one case through agent and summarizes the run.
+func Replay(ctx context.Context, c Case, agent *agentkit.Agent, timeout time.Duration) *Run {
+	r := &Run{Case: c, Events: run(ctx, agent, c.Input, timeout)}
+	diff := agentkit.CompareRuns(c.Events, r.Events)
+	r.Summary, r.ToolCalls = diff.After, diff.AfterCalls
+	if len(c.Events) > 0 {
+		r.Diff = &diff
+	}
+	return r
+}
+
+// run collects the events of one run, canceling it after timeout.
+func run(ctx context.Context, agent *agentkit.Agent, input string, timeout time.Duration) []agentkit.Event {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var events []agentkit.Event
+	for event := range agent.Run(ctx, input) {
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,108 @@
+package agentkittest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func newAgent(t *testing.T, provider *mockprovider.Provider) *agentkit.Agent {
+	t.Helper()
+	agent, err := agentkit.New(
+		agentkit.WithProvider(provider),
+		agentkit.WithModel("test-model"),
+		agentkit.WithStreamResponses(false),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, name := range []string{"lookup", "refund"} {
+		agent.AddTool(agentkit.NewTool(name).
+			WithHandler(func(context.Context, map[string]any) (any, error) { return "ok", nil }).
+			Build())
+	}
+	return agent
+}
+
+func refundProvider(answer string) *mockprovider.Provider {
+	return mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "c1", Name: "lookup", Arguments: map[string]any{"id": "7"}}}).
+		WithResponse("", []providers.ToolCall{{ID: "c2", Name: "refund", Arguments: map[string]any{"id": "7"}}}).
+		WithResponse(answer, nil)
+}
+
+var refundFixture = Fixture{Cases: []Case{{Name: "refund", Input: "refund order 7"}}}
+
+func TestAssertBehavior(t *testing.T) {
+	AssertBehavior(t, refundFixture, newAgent(t, refundProvider(`{"status": "refunded"}`)),
+		CallsToolsInOrder("lookup", "refund"),
+		NeverCallsTool("delete_order"),
+		MaxIterations(3),
+		MaxTokens(90),
+		NoErrors(),
+		OutputMatchesSchema(map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"status": map[string]any{"type": "string", "enum": []any{"refunded", "denied"}}},
+			"required":   []any{"status"},
+		}),
+	)
+}
+
+func TestAssertions_ReportViolations(t *testing.T) {
+	r := Replay(context.Background(), refundFixture.Cases[0], newAgent(t, refundProvider("Refunded.")), time.Second)
+	tests := []struct {
+		name      string
+		assertion Assertion
+		want      string
+	}{
+		{"calls", CallsTool("escalate"), `tool "escalate" was not called (called: lookup, refund)`},
+		{"never", NeverCallsTool("refund"), `tool "refund" was called 1 times`},
+		{"order", CallsToolsInOrder("refund", "lookup"), "not called in order"},
+		{"max calls", MaxToolCalls(1), "2 tool calls, want at most 1"},
+		{"iterations", MaxIterations(2), "3 iterations, want at most 2"},
+		{"tokens", MaxTokens(50), "90 tokens, want at most 50"},
+		{"contains", OutputContains("denied"), `does not contain "denied"`},
+		{"matches", OutputMatches(`^\{`), "does not match"},
+		{"schema", OutputMatchesSchema(map[string]any{"type": "object"}), "output is not JSON"},
+		{"recording", MatchesRecording(), "no recorded run"},
+		{"check", Check("short", func(r *Run) bool { return len(r.Summary.Output) < 5 }), `check "short" failed`},
+	}
+	for _, tt := range tests {
+		err := tt.assertion(r)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRecordSaveLoad_MatchesRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "refund.json")
+	recorded := Record(context.Background(), refundFixture, newAgent(t, refundProvider("Refunded.")))
+	if err := recorded.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	if len(fixture.Cases) != 1 || len(fixture.Cases[0].Events) == 0 {
+		t.Fatalf("loaded fixture = %+v", fixture)
+	}
+
+	AssertBehavior(t, fixture, newAgent(t, refundProvider("Refund issued.")), MatchesRecording())
+
+	// Skipping the lookup diverges from the recording.
+	changed := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "c1", Name: "refund", Arguments: map[string]any{"id": "7"}}}).
+		WithResponse("Refunded.", nil)
+	r := Replay(context.Background(), fixture.Cases[0], newAgent(t, changed), time.Second)
+	if err := MatchesRecording()(r); err == nil || !strings.Contains(err.Error(), "removed `lookup`") {
+		t.Errorf("MatchesRecording() = %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package agentkittest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// CallsTool requires the run to call the named tool at least once.
+func CallsTool(name string) Assertion {
+	return func(r *Run) error {
+		if countCalls(r, name) == 0 {
+			return fmt.Errorf("tool %q was not called (called: %s)", name, calledTools(r))
+		}
+		return nil
+	}
+}
+
+// NeverCallsTool requires the run not to call the named tool.
+func NeverCallsTool(name string) Assertion {
+	return func(r *Run) error {
+		if n := countCalls(r, name); n > 0 {
+			return fmt.Errorf("tool %q was called %d times", name, n)
+		}
+		return nil
+	}
+}
+
+// CallsToolsInOrder requires the named tools to be called in this order,
+// possibly with other calls in between.
+func CallsToolsInOrder(names ...string) Assertion {
+	return func(r *Run) error {
+		next := 0
+		for _, call := range r.ToolCalls {
+			if next < len(names) && call.Tool == names[next] {
+				next++
+			}
+		}
+		if next < len(names) {
+			return fmt.Errorf("tools %s were not called in order (called: %s)", strings.Join(names, ", "), calledTools(r))
+		}
+		return nil
+	}
+}
+
+// MaxToolCalls requires the run to make at most n tool calls.
+func MaxToolCalls(n int) Assertion {
+	return func(r *Run) error {
+		if len(r.ToolCalls) > n {
+			return fmt.Errorf("%d tool calls, want at most %d", len(r.ToolCalls), n)
+		}
+		return nil
+	}
+}
+
+// MaxIterations requires the run to finish within n iterations.
+func MaxIterations(n int) Assertion {
+	return func(r *Run) error {
+		if r.Summary.Iterations > n {
+			return fmt.Errorf("%d iterations, want at most %d", r.Summary.Iterations, n)
+		}
+		return nil
+	}
+}
+
+// MaxTokens requires the run to use at most n tokens.
+func MaxTokens(n int) Assertion {
+	return func(r *Run) error {
+		if r.Summary.TotalTokens > n {
+			return fmt.Errorf("%d tokens, want at most %d", r.Summary.TotalTokens, n)
+		}
+		return nil
+	}
+}
+
+// NoErrors requires the run to emit no error events.
+func NoErrors() Assertion {
+	return func(r *Run) error {
+		if r.Summary.Error != "" {
+			return fmt.Errorf("run failed: %s", r.Summary.Error)
+		}
+		return nil
+	}
+}
+
+// OutputContains requires the final output to contain substr.
+func OutputContains(substr string) Assertion {
+	return func(r *Run) error {
+		if !strings.Contains(r.Summary.Output, substr) {
+			return fmt.Errorf("output %q does not contain %q", r.Summary.Output, substr)
+		}
+		return nil
+	}
+}
+
+// OutputMatches requires the final output to match the regular expression.
+func OutputMatches(pattern string) Assertion {
+	re := regexp.MustCompile(pattern)
+	return func(r *Run) error {
+		if !re.MatchString(r.Summary.Output) {
+			return fmt.Errorf("output %q does not match %s", r.Summary.Output, pattern)
+		}
+		return nil
+	}
+}
+
+// OutputMatchesSchema requires the final output to be JSON that matches
+// the JSON schema; see agentkit.ValidateSchema.
+func OutputMatchesSchema(schema map[string]any) Assertion {
+	return func(r *Run) error {
+		var value any
+		if err := json.Unmarshal([]byte(r.Summary.Output), &value); err != nil {
+			return fmt.Errorf("output is not JSON: %w", err)
+		}
+		violations := agentkit.ValidateSchema(schema, value)
+		if len(violations) == 0 {
+			return nil
+		}
+		problems := make([]string, len(violations))
+		for i, v := range violations {
+			problems[i] = v.String()
+		}
+		return fmt.Errorf("output does not match the schema: %s", strings.Join(problems, "; "))
+	}
+}
+
+// MatchesRecording requires the run to make the same tool calls as the
+// case's recording. Cases without a recording fail, so a fixture that has
+// not been recorded yet is noticed.
+func MatchesRecording() Assertion {
+	return func(r *Run) error {
+		if r.Diff == nil {
+			return errors.New("case has no recorded run")
+		}
+		if len(r.Diff.ToolCalls) > 0 {
+			return fmt.Errorf("tool calls differ from the recording:\n%s", r.Diff.Markdown())
+		}
+		return nil
+	}
+}
+
+// Check adapts a custom check to an Assertion.
+func Check(name string, ok func(r *Run) bool) Assertion {
+	return func(r *Run) error {
+		if !ok(r) {
+			return fmt.Errorf("check %q failed", name)
+		}
+		return nil
+	}
+}
+
+func countCalls(r *Run, name string) int {
+	n := 0
+	for _, call := range r.ToolCalls {
+		if call.Tool == name {
+			n++
+		}
+	}
+	return n
+}
+
+func calledTools(r *Run) string {
+	if len(r.ToolCalls) == 0 {
+		return "none"
+	}
+	names := make([]string, len(r.ToolCalls))
+	for i, call := range r.ToolCalls {
+		names[i] = call.Tool
+	}
+	return strings.Join(names, ", ")
+}
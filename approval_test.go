@@ -251,3 +251,66 @@ func TestApprovalHandler_ErrorHandling(t *testing.T) {
 		t.Error("expected approval to be denied on error")
 	}
 }
+
+func TestApprovalPreview_IncludedInRequestAndEvent(t *testing.T) {
+	mock := NewMockLLM().
+		WithResponse("", []ToolCall{
+			{
+				ID:        "call_1",
+				Name:      "run_sql",
+				Arguments: map[string]any{"query": "DELETE FROM users"},
+			},
+		}).
+		WithFinalResponse("Done.")
+
+	var handled ApprovalRequest
+	agent, err := New(Config{
+		Model:       "gpt-4o",
+		LLMProvider: mock,
+		Approval: &ApprovalConfig{
+			Tools: []string{"run_sql"},
+			Handler: func(ctx context.Context, req ApprovalRequest) (bool, error) {
+				handled = req
+				return false, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	agent.AddTool(NewTool("run_sql").
+		WithParameter("query", String().Required()).
+		WithApprovalPreview(func(ctx context.Context, args map[string]any) (ApprovalPreview, error) {
+			return ApprovalPreview{
+				Summary: "Run SQL against production",
+				Diff:    args["query"].(string),
+				Risk:    RiskLevelHigh,
+			}, nil
+		}).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return "ok", nil
+		}).
+		Build())
+
+	var eventPreview any
+	for event := range agent.Run(context.Background(), "Clean up users") {
+		if event.Type == EventTypeApprovalRequired {
+			eventPreview = event.Data["preview"]
+		}
+	}
+
+	if handled.Preview == nil {
+		t.Fatal("expected preview on approval request")
+	}
+	if handled.Preview.Diff != "DELETE FROM users" || handled.Preview.Risk != RiskLevelHigh {
+		t.Errorf("unexpected preview: %+v", handled.Preview)
+	}
+	preview, ok := eventPreview.(ApprovalPreview)
+	if !ok {
+		t.Fatalf("expected preview in approval_required event, got %T", eventPreview)
+	}
+	if preview.Summary != "Run SQL against production" {
+		t.Errorf("expected preview summary in event, got %q", preview.Summary)
+	}
+}
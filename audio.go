@@ -0,0 +1,80 @@
+package agentkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrInvalidAudio is reported by RunAudio for audio without data or in a
+// format that cannot be detected.
+var ErrInvalidAudio = errors.New("agentkit: invalid audio input")
+
+// AudioInput is a spoken user turn for RunAudio.
+type AudioInput struct {
+	// Data is the recorded audio.
+	Data []byte
+	// Format of Data: "wav" or "mp3" for the OpenAI provider, "pcm16" for
+	// realtime sessions. Detected from Data when empty.
+	Format string
+	// Text is sent along with the audio, e.g. "Answer in French".
+	Text string
+	// Voice of the spoken reply (default "alloy").
+	Voice string
+	// OutputFormat of the spoken reply (default "pcm16", the only format
+	// providers stream).
+	OutputFormat string
+}
+
+// audioOutput returns the spoken reply settings of a RunAudio run. They
+// belong to the run, so agents it calls as tools answer in text.
+func audioOutput(ctx context.Context) *providers.AudioOutput {
+	if o := currentRunOptions(ctx); o != nil {
+		return o.audioOutput
+	}
+	return nil
+}
+
+// RunAudio executes the agent with a spoken user turn and asks the model for
+// a spoken reply. Fragments of the reply arrive as audio.delta events while
+// its transcript streams as response chunks and ends up in final_output, so
+// memory, traces and tools work as in a text run. The model must support
+// audio, e.g. "gpt-4o-audio-preview" or a realtime provider. Invalid audio
+// is reported as an error event before the run starts.
+func (a *Agent) RunAudio(ctx context.Context, input AudioInput) <-chan Event {
+	if len(input.Data) == 0 {
+		return errorRun(fmt.Errorf("%w: no data", ErrInvalidAudio))
+	}
+	format := input.Format
+	if format == "" {
+		if format = detectAudioFormat(input.Data); format == "" {
+			return errorRun(fmt.Errorf("%w: unknown format; set AudioInput.Format", ErrInvalidAudio))
+		}
+	}
+	output := &providers.AudioOutput{Voice: input.Voice, Format: input.OutputFormat}
+	if output.Voice == "" {
+		output.Voice = "alloy"
+	}
+	if output.Format == "" {
+		output.Format = "pcm16"
+	}
+	return a.runMessages(ctx, []providers.Message{{
+		Role:    providers.RoleUser,
+		Content: input.Text,
+		Audio:   []providers.Audio{{Data: input.Data, Format: format}},
+	}}, &runOptions{audioOutput: output})
+}
+
+// detectAudioFormat recognizes WAV and MP3 data.
+func detectAudioFormat(data []byte) string {
+	switch {
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WAVE")):
+		return "wav"
+	case bytes.HasPrefix(data, []byte("ID3")), len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0:
+		return "mp3"
+	}
+	return ""
+}
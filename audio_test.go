@@ -0,0 +1,129 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// speakingProvider streams a spoken reply and records the requests.
+type speakingProvider struct {
+	*mockprovider.Provider
+	requests []providers.CompletionRequest
+}
+
+func (p *speakingProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	p.requests = append(p.requests, req)
+	return &chunkStream{chunks: []*providers.StreamChunk{
+		{Content: "Hi", Audio: []byte{1, 2}},
+		{Content: " there", Audio: []byte{3}},
+		{IsComplete: true, FinishReason: providers.FinishReasonStop},
+	}}, nil
+}
+
+type chunkStream struct {
+	chunks []*providers.StreamChunk
+}
+
+func (s *chunkStream) Next() (*providers.StreamChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func (s *chunkStream) Close() error { return nil }
+
+func TestRunAudio_StreamsSpokenReply(t *testing.T) {
+	provider := &speakingProvider{Provider: mockprovider.New()}
+	agent, err := New(Config{Provider: provider, Model: "gpt-4o-audio-preview", StreamResponses: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	wav := append([]byte("RIFF\x00\x00\x00\x00WAVE"), 0, 0)
+
+	events := collectEvents(agent.RunAudio(context.Background(), AudioInput{Data: wav, Text: "Reply briefly.", Voice: "verse"}), 2*time.Second)
+
+	var audio []byte
+	var final string
+	for _, event := range events {
+		switch event.Type {
+		case EventTypeAudioDelta:
+			data, _ := base64.StdEncoding.DecodeString(event.Data["audio"].(string))
+			audio = append(audio, data...)
+			if event.Data["format"] != "pcm16" {
+				t.Errorf("format = %v", event.Data["format"])
+			}
+		case EventTypeFinalOutput:
+			final, _ = event.Data["response"].(string)
+		case EventTypeError:
+			t.Errorf("unexpected error event: %v", event.Data)
+		}
+	}
+	if string(audio) != "\x01\x02\x03" || final != "Hi there" {
+		t.Errorf("audio = %v, final output = %q", audio, final)
+	}
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("requests = %d", len(provider.requests))
+	}
+	req := provider.requests[0]
+	if req.Audio == nil || req.Audio.Voice != "verse" || req.Audio.Format != "pcm16" {
+		t.Errorf("audio output = %+v", req.Audio)
+	}
+	msg := req.Messages[len(req.Messages)-1]
+	if msg.Content != "Reply briefly." || len(msg.Audio) != 1 || msg.Audio[0].Format != "wav" {
+		t.Errorf("user message = %+v", msg)
+	}
+}
+
+func TestRunAudio_NestedAgentsAnswerInText(t *testing.T) {
+	subProvider := &recordingProvider{Provider: mockprovider.New().WithResponse("found", nil)}
+	sub, err := New(Config{Provider: subProvider, Model: "gpt-4o-audio-preview", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "research", Arguments: map[string]any{"input": "look it up"}}}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "gpt-4o-audio-preview", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(sub.AsTool("research", "Research a topic"))
+
+	wav := append([]byte("RIFF\x00\x00\x00\x00WAVE"), 0, 0)
+	collectEvents(agent.RunAudio(context.Background(), AudioInput{Data: wav}), 2*time.Second)
+
+	if provider.requests[0].Audio == nil {
+		t.Error("the audio run asked for no spoken reply")
+	}
+	if len(subProvider.requests) != 1 || subProvider.requests[0].Audio != nil {
+		t.Errorf("nested agent requests = %+v, want one text request", subProvider.requests)
+	}
+}
+
+func TestRunAudio_InvalidInput(t *testing.T) {
+	agent := newMockAgent(t, mockprovider.New().WithResponse("unused", nil))
+	for name, input := range map[string]AudioInput{
+		"empty":   {},
+		"unknown": {Data: []byte("not audio")},
+	} {
+		events := collectEvents(agent.RunAudio(context.Background(), input), time.Second)
+		if len(events) != 1 || events[0].Type != EventTypeError {
+			t.Errorf("%s: events = %+v", name, events)
+			continue
+		}
+		if detail, ok := events[0].ErrorDetail(); !ok || !errors.Is(detail, ErrInvalidAudio) {
+			t.Errorf("%s: error = %v, want ErrInvalidAudio", name, detail)
+		}
+	}
+}
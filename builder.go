@@ -0,0 +1,377 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrDuplicateTool is returned by AgentBuilder.Build when two tools share a name.
+var ErrDuplicateTool = errors.New("agentkit: duplicate tool name")
+
+// AgentBuilder assembles an agent step by step as an alternative to filling
+// in Config:
+//
+//	agent, err := agentkit.NewBuilder().
+//		Model("gpt-4o").
+//		Instructions("You are a support agent.").
+//		WithTool(lookupOrder).
+//		WithTracer(tracer).
+//		WithMemory(agentkit.MemoryConfig{Store: store}).
+//		Build()
+//
+// Setters only record values; everything is validated together in Build.
+type AgentBuilder struct {
+	cfg            Config
+	temperatureSet bool
+	tools          []Tool
+	middlewares    []Middleware
+}
+
+// NewBuilder starts a builder from DefaultConfig. The default temperature is
+// dropped for models that do not accept one.
+func NewBuilder() *AgentBuilder {
+	return &AgentBuilder{cfg: DefaultConfig()}
+}
+
+// Model sets the model name.
+func (b *AgentBuilder) Model(model string) *AgentBuilder {
+	b.cfg.Model = model
+	return b
+}
+
+// APIKey sets the OpenAI API key used by the built-in provider.
+func (b *AgentBuilder) APIKey(key string) *AgentBuilder {
+	b.cfg.APIKey = key
+	return b
+}
+
+// Provider sets the LLM provider.
+func (b *AgentBuilder) Provider(provider providers.Provider) *AgentBuilder {
+	b.cfg.Provider = provider
+	return b
+}
+
+// Name sets the agent name used in events and traces.
+func (b *AgentBuilder) Name(name string) *AgentBuilder {
+	b.cfg.AgentName = name
+	return b
+}
+
+// Instructions sets a static system prompt.
+func (b *AgentBuilder) Instructions(prompt string) *AgentBuilder {
+	b.cfg.SystemPrompt = func(context.Context) string { return prompt }
+	return b
+}
+
+// SystemPrompt sets a system prompt built from the run context.
+func (b *AgentBuilder) SystemPrompt(fn SystemPromptFunc) *AgentBuilder {
+	b.cfg.SystemPrompt = fn
+	return b
+}
+
+// Prompt composes the system prompt from the sections of pb.
+func (b *AgentBuilder) Prompt(pb *PromptBuilder) *AgentBuilder {
+	b.cfg.PromptBuilder = pb
+	return b
+}
+
+// MaxIterations sets the maximum number of model calls per run.
+func (b *AgentBuilder) MaxIterations(n int) *AgentBuilder {
+	b.cfg.MaxIterations = n
+	return b
+}
+
+// Temperature sets the sampling temperature.
+func (b *AgentBuilder) Temperature(t float32) *AgentBuilder {
+	b.cfg.Temperature = t
+	b.temperatureSet = true
+	return b
+}
+
+// ReasoningEffort sets the reasoning effort for reasoning models.
+func (b *AgentBuilder) ReasoningEffort(effort providers.ReasoningEffort) *AgentBuilder {
+	b.cfg.ReasoningEffort = effort
+	return b
+}
+
+// Streaming sets whether responses are streamed.
+func (b *AgentBuilder) Streaming(stream bool) *AgentBuilder {
+	b.cfg.StreamResponses = stream
+	return b
+}
+
+// Deterministic makes requests reproducible: temperature 0 and seed.
+func (b *AgentBuilder) Deterministic(seed int64) *AgentBuilder {
+	b.cfg.Deterministic = true
+	b.cfg.Seed = seed
+	return b
+}
+
+// WithOutputLength sets the answer length target, token cap and enforcement.
+func (b *AgentBuilder) WithOutputLength(cfg OutputLengthConfig) *AgentBuilder {
+	b.cfg.OutputLength = &cfg
+	return b
+}
+
+// WithTerminology checks the final answer against a glossary of banned and
+// preferred terms.
+func (b *AgentBuilder) WithTerminology(cfg TerminologyConfig) *AgentBuilder {
+	b.cfg.Terminology = &cfg
+	return b
+}
+
+// WithModeration checks user input and the final answer with a moderation
+// API.
+func (b *AgentBuilder) WithModeration(cfg ModerationConfig) *AgentBuilder {
+	b.cfg.Moderation = &cfg
+	return b
+}
+
+// AllowUnknownModel skips the known-model check in validation.
+func (b *AgentBuilder) AllowUnknownModel() *AgentBuilder {
+	b.cfg.AllowUnknownModel = true
+	return b
+}
+
+// WithTool adds a tool.
+func (b *AgentBuilder) WithTool(tool Tool) *AgentBuilder {
+	b.tools = append(b.tools, tool)
+	return b
+}
+
+// WithTools adds several tools.
+func (b *AgentBuilder) WithTools(tools ...Tool) *AgentBuilder {
+	b.tools = append(b.tools, tools...)
+	return b
+}
+
+// WithMiddleware adds execution middleware.
+func (b *AgentBuilder) WithMiddleware(m Middleware) *AgentBuilder {
+	if m != nil {
+		b.middlewares = append(b.middlewares, m)
+	}
+	return b
+}
+
+// WithTracer sets the tracer.
+func (b *AgentBuilder) WithTracer(tracer Tracer) *AgentBuilder {
+	b.cfg.Tracer = tracer
+	return b
+}
+
+// WithLogger logs through logger.
+func (b *AgentBuilder) WithLogger(logger *slog.Logger) *AgentBuilder {
+	logging := DefaultLoggingConfig()
+	if b.cfg.Logging != nil {
+		logging = *b.cfg.Logging
+	}
+	logging.Logger = logger
+	b.cfg.Logging = &logging
+	return b
+}
+
+// WithLogging sets the logging configuration.
+func (b *AgentBuilder) WithLogging(cfg LoggingConfig) *AgentBuilder {
+	b.cfg.Logging = &cfg
+	return b
+}
+
+// WithRetry sets the retry policy.
+func (b *AgentBuilder) WithRetry(cfg RetryConfig) *AgentBuilder {
+	b.cfg.Retry = &cfg
+	return b
+}
+
+// WithTimeout sets the timeouts.
+func (b *AgentBuilder) WithTimeout(cfg TimeoutConfig) *AgentBuilder {
+	b.cfg.Timeout = &cfg
+	return b
+}
+
+// WithApproval requires approval for tool calls.
+func (b *AgentBuilder) WithApproval(cfg ApprovalConfig) *AgentBuilder {
+	b.cfg.Approval = &cfg
+	return b
+}
+
+// WithConversationStore persists conversations.
+func (b *AgentBuilder) WithConversationStore(store ConversationStore) *AgentBuilder {
+	b.cfg.ConversationStore = store
+	return b
+}
+
+// WithMemory enables scoped long-term memory.
+func (b *AgentBuilder) WithMemory(cfg MemoryConfig) *AgentBuilder {
+	b.cfg.Memory = &cfg
+	return b
+}
+
+// WithGraphMemory enables knowledge-graph memory.
+func (b *AgentBuilder) WithGraphMemory(cfg GraphMemoryConfig) *AgentBuilder {
+	b.cfg.GraphMemory = &cfg
+	return b
+}
+
+// WithStateStore persists agent-owned state.
+func (b *AgentBuilder) WithStateStore(store AgentStateStore) *AgentBuilder {
+	b.cfg.StateStore = store
+	return b
+}
+
+// WithFlags enables feature-flagged models, prompts and tools.
+func (b *AgentBuilder) WithFlags(cfg FlagConfig) *AgentBuilder {
+	b.cfg.Flags = &cfg
+	return b
+}
+
+// WithAdmission gates provider calls through queue at priority.
+func (b *AgentBuilder) WithAdmission(queue *AdmissionQueue, priority Priority) *AgentBuilder {
+	b.cfg.Admission = queue
+	b.cfg.Priority = priority
+	return b
+}
+
+// WithEventSink adds an event sink.
+func (b *AgentBuilder) WithEventSink(sink EventSink) *AgentBuilder {
+	b.cfg.EventSinks = append(b.cfg.EventSinks, sink)
+	return b
+}
+
+// WithContextPolicy keeps history within a token budget before each model call.
+func (b *AgentBuilder) WithContextPolicy(policy ContextPolicy) *AgentBuilder {
+	b.cfg.ContextPolicy = &policy
+	return b
+}
+
+// WithLessons enables the feedback-driven lessons loop (see LessonsConfig).
+func (b *AgentBuilder) WithLessons(cfg LessonsConfig) *AgentBuilder {
+	b.cfg.Lessons = &cfg
+	return b
+}
+
+// WithResponseChaining continues each model call from the previous stored
+// response (see ResponseChainingConfig).
+func (b *AgentBuilder) WithResponseChaining(cfg ResponseChainingConfig) *AgentBuilder {
+	b.cfg.ResponseChaining = &cfg
+	return b
+}
+
+// WithToolDescriptionLimit cuts tool and parameter descriptions sent to the
+// model to limit characters, to keep requests with many tools small.
+func (b *AgentBuilder) WithToolDescriptionLimit(limit int) *AgentBuilder {
+	b.cfg.ToolDescriptionLimit = limit
+	return b
+}
+
+// WithThinkingTrace records only a sample of streamed reasoning chunks in
+// traces (see ThinkingTraceConfig).
+func (b *AgentBuilder) WithThinkingTrace(cfg ThinkingTraceConfig) *AgentBuilder {
+	b.cfg.ThinkingTrace = &cfg
+	return b
+}
+
+// WithStreamIdleTimeout aborts streams that go quiet for idle and retries
+// the call without streaming.
+func (b *AgentBuilder) WithStreamIdleTimeout(idle time.Duration) *AgentBuilder {
+	b.cfg.StreamIdleTimeout = idle
+	return b
+}
+
+// WithHostedTools makes tools run by the provider, such as WebSearchTool(),
+// available to the model.
+func (b *AgentBuilder) WithHostedTools(tools ...HostedTool) *AgentBuilder {
+	b.cfg.HostedTools = append(b.cfg.HostedTools, tools...)
+	return b
+}
+
+// WithDevReload reloads the system prompt and settings from files as they
+// change. Intended for development.
+func (b *AgentBuilder) WithDevReload(cfg DevReloadConfig) *AgentBuilder {
+	b.cfg.DevReload = &cfg
+	return b
+}
+
+// WithOutputSchema makes the final answer a JSON value matching schema.
+func (b *AgentBuilder) WithOutputSchema(cfg OutputSchemaConfig) *AgentBuilder {
+	b.cfg.OutputSchema = &cfg
+	return b
+}
+
+// WithFallbackModels adds models to fail over to when the model is rate
+// limited, erroring or timing out.
+func (b *AgentBuilder) WithFallbackModels(specs ...ModelSpec) *AgentBuilder {
+	b.cfg.FallbackModels = append(b.cfg.FallbackModels, specs...)
+	return b
+}
+
+// WithQuota tracks provider rate limits.
+func (b *AgentBuilder) WithQuota(monitor *QuotaMonitor) *AgentBuilder {
+	b.cfg.Quota = monitor
+	return b
+}
+
+// WithParallelTools configures parallel tool execution.
+func (b *AgentBuilder) WithParallelTools(cfg ParallelConfig) *AgentBuilder {
+	b.cfg.ParallelToolExecution = &cfg
+	return b
+}
+
+// WithStreamShaping coalesces thinking chunks.
+func (b *AgentBuilder) WithStreamShaping(cfg StreamShapingConfig) *AgentBuilder {
+	b.cfg.StreamShaping = &cfg
+	return b
+}
+
+// WithEventBuffer sets the event channel buffer size.
+func (b *AgentBuilder) WithEventBuffer(size int) *AgentBuilder {
+	b.cfg.EventBuffer = size
+	return b
+}
+
+// Config returns the configuration built so far, without tools or middleware.
+func (b *AgentBuilder) Config() Config {
+	cfg := b.cfg
+	if !b.temperatureSet {
+		if info, ok := LookupModelInfo(cfg.Model); ok && !info.Temperature {
+			cfg.Temperature = 0
+		}
+	}
+	return cfg
+}
+
+// Build validates the configuration and tools and creates the agent. All
+// problems are reported together, joined with errors.Join.
+func (b *AgentBuilder) Build() (*Agent, error) {
+	cfg := b.Config()
+	var errs []error
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	seen := make(map[string]bool, len(b.tools))
+	for _, tool := range b.tools {
+		if seen[tool.Name()] {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrDuplicateTool, tool.Name()))
+		}
+		seen[tool.Name()] = true
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid agent config: %w", errors.Join(errs...))
+	}
+
+	agent, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range b.tools {
+		agent.AddTool(tool)
+	}
+	for _, m := range b.middlewares {
+		agent.Use(m)
+	}
+	return agent, nil
+}
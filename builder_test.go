@@ -0,0 +1,89 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/middleware"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+type startRecorder struct {
+	middleware.BaseMiddleware
+	inputs []string
+}
+
+func (m *startRecorder) OnAgentStart(ctx context.Context, input string) context.Context {
+	m.inputs = append(m.inputs, input)
+	return ctx
+}
+
+func echoTool(name string) Tool {
+	return NewTool(name).
+		WithDescription("Echo").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) { return "ok", nil }).
+		Build()
+}
+
+func TestAgentBuilder_Build(t *testing.T) {
+	recorder := &startRecorder{}
+	agent, err := NewBuilder().
+		Provider(mockprovider.New().WithResponse("hello", nil)).
+		Model("test-model").
+		Name("support").
+		Instructions("Be brief.").
+		MaxIterations(3).
+		Streaming(false).
+		WithTool(echoTool("echo")).
+		WithMiddleware(recorder).
+		WithMiddleware(nil).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, ok := agent.tools["echo"]; !ok {
+		t.Error("tool not registered")
+	}
+	if agent.maxIterations != 3 || agent.agentName != "support" || agent.streamResponses {
+		t.Errorf("agent = maxIterations %d, name %q, stream %v", agent.maxIterations, agent.agentName, agent.streamResponses)
+	}
+	if got := agent.systemPrompt(context.Background()); got != "Be brief." {
+		t.Errorf("system prompt = %q", got)
+	}
+
+	collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second)
+	if len(recorder.inputs) != 1 || recorder.inputs[0] != "hi" {
+		t.Errorf("middleware inputs = %v", recorder.inputs)
+	}
+}
+
+func TestAgentBuilder_DropsDefaultTemperatureForReasoningModels(t *testing.T) {
+	cfg := NewBuilder().APIKey("k").Model("o3").ReasoningEffort(providers.ReasoningEffortHigh).Config()
+	if cfg.Temperature != 0 {
+		t.Fatalf("Temperature = %v, want 0", cfg.Temperature)
+	}
+	if _, err := NewBuilder().APIKey("k").Model("o3").Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	_, err := NewBuilder().APIKey("k").Model("o3").Temperature(0.5).Build()
+	if !errors.Is(err, ErrTemperatureUnsupported) {
+		t.Fatalf("Build() error = %v, want ErrTemperatureUnsupported", err)
+	}
+}
+
+func TestAgentBuilder_ReportsAllErrors(t *testing.T) {
+	_, err := NewBuilder().
+		Model("gpt-4o").
+		MaxIterations(500).
+		WithTools(echoTool("echo"), echoTool("echo")).
+		Build()
+	for _, want := range []error{ErrMissingAPIKey, ErrInvalidIterations, ErrDuplicateTool} {
+		if !errors.Is(err, want) {
+			t.Errorf("Build() error = %v, want %v", err, want)
+		}
+	}
+}
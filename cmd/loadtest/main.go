@@ -0,0 +1,129 @@
+// Command loadtest drives an agent against a fake model and reports latency
+// percentiles, allocation rates and goroutine leaks. It exits with status 1
+// when a leak is found.
+//
+//	go run ./cmd/loadtest -concurrency 64 -duration 1m -stream -cancel-rate 0.1
+//	go run ./cmd/loadtest -server -stream -soak 4h -tools lookup:3:20ms,search:1:200ms:0.05
+//
+// Tools are name[:weight[:latency[:error rate]]]. With -server the model is
+// served over HTTP through the built-in OpenAI provider. -soak runs for the
+// given duration, printing a sample every -sample interval.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/loadtest"
+)
+
+func main() {
+	var (
+		cfg       loadtest.Config
+		tools     string
+		soak      time.Duration
+		useServer bool
+		asJSON    bool
+	)
+	flag.IntVar(&cfg.Concurrency, "concurrency", 8, "runs in flight")
+	flag.DurationVar(&cfg.Duration, "duration", 0, "how long to start new runs")
+	flag.IntVar(&cfg.Runs, "runs", 0, "stop after this many runs (default 1000 without -duration)")
+	flag.DurationVar(&soak, "soak", 0, "soak test: run this long, sampling every -sample")
+	flag.DurationVar(&cfg.SampleInterval, "sample", time.Minute, "sample interval")
+	flag.BoolVar(&cfg.Stream, "stream", false, "stream model responses")
+	flag.Float64Var(&cfg.CancelRate, "cancel-rate", 0, "fraction of runs cancelled part-way")
+	flag.BoolVar(&cfg.AgentPerRun, "agent-per-run", false, "build an agent per run")
+	flag.IntVar(&cfg.Script.ToolCalls, "tool-calls", 2, "tool calls per run")
+	flag.IntVar(&cfg.Script.AnswerChunks, "chunks", 20, "chunks in each answer")
+	flag.DurationVar(&cfg.Script.Latency, "latency", 0, "model time before each response")
+	flag.DurationVar(&cfg.Script.ChunkInterval, "chunk-interval", 0, "time between streamed chunks")
+	flag.StringVar(&tools, "tools", "lookup", "comma-separated tool mix, name[:weight[:latency[:error rate]]]")
+	flag.BoolVar(&useServer, "server", false, "serve the model over HTTP")
+	flag.DurationVar(&cfg.StallTimeout, "stall-timeout", 30*time.Second, "abandon runs silent for this long")
+	flag.DurationVar(&cfg.Settle, "settle", 5*time.Second, "time for goroutines to exit after the load")
+	flag.IntVar(&cfg.LeakTolerance, "leak-tolerance", 0, "surviving goroutines not reported")
+	flag.BoolVar(&asJSON, "json", false, "print the report as JSON")
+	flag.Parse()
+
+	profiles, err := parseTools(tools)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		os.Exit(2)
+	}
+	cfg.Script.Tools = profiles
+	if cfg.Script.ToolCalls == 0 {
+		cfg.Script.ToolCalls = -1
+	}
+	if soak > 0 {
+		cfg.Duration = soak
+		cfg.OnSample = func(s loadtest.Sample) {
+			fmt.Fprintf(os.Stderr, "%s  runs %d  active %d  goroutines %d  heap %.1f MB\n",
+				s.Elapsed.Round(time.Second), s.Runs, s.ActiveRuns, s.Goroutines, float64(s.HeapInuse)/(1024*1024))
+		}
+	}
+	if useServer {
+		server := loadtest.NewFakeServer(cfg.Script)
+		defer server.Close()
+		cfg.Provider = server.Provider()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := loadtest.Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		fmt.Print(report.Summary())
+	}
+	if err := report.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parseTools parses a comma-separated list of
+// name[:weight[:latency[:error rate]]].
+func parseTools(list string) ([]loadtest.ToolProfile, error) {
+	var profiles []loadtest.ToolProfile
+	for _, spec := range strings.Split(list, ",") {
+		fields := strings.Split(strings.TrimSpace(spec), ":")
+		if fields[0] == "" {
+			continue
+		}
+		profile := loadtest.ToolProfile{Name: fields[0]}
+		var err error
+		if len(fields) > 1 {
+			if profile.Weight, err = strconv.Atoi(fields[1]); err != nil {
+				return nil, fmt.Errorf("tool %s: weight: %w", spec, err)
+			}
+		}
+		if len(fields) > 2 {
+			if profile.Latency, err = time.ParseDuration(fields[2]); err != nil {
+				return nil, fmt.Errorf("tool %s: latency: %w", spec, err)
+			}
+		}
+		if len(fields) > 3 {
+			if profile.ErrorRate, err = strconv.ParseFloat(fields[3], 64); err != nil {
+				return nil, fmt.Errorf("tool %s: error rate: %w", spec, err)
+			}
+		}
+		if len(fields) > 4 {
+			return nil, fmt.Errorf("tool %s: too many fields", spec)
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
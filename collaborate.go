@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"text/template"
 	"time"
 )
 
@@ -12,17 +16,29 @@ import (
 // Unlike handoffs, collaborations are not hierarchical - all agents are peers
 // who contribute to a shared conversation. Think of this as a breakout room
 // where everyone hashes out ideas together.
+//
+// A session is safe for concurrent use: Discuss may run many discussions at
+// once, and each contribution runs on its own copy of the agent. Use a
+// CollaborationPool to bound how many run at a time.
 type CollaborationSession struct {
 	facilitator *Agent   // The agent who runs the conversation flow
 	peers       []*Agent // Other agents participating as equals
+	expertise   map[*Agent]PeerExpertise // Declared per-peer expertise (SetExpertise)
 	options     collaborationOptions
 	mu          sync.RWMutex
 }
 
 type collaborationOptions struct {
-	maxRounds      int           // Maximum number of discussion rounds
-	roundTimeout   time.Duration // Timeout for each round
-	captureHistory bool          // Whether to capture full conversation history
+	maxRounds      int                  // Maximum number of discussion rounds
+	roundTimeout   time.Duration        // Timeout for each round
+	captureHistory bool                 // Whether to capture full conversation history
+	rotate         bool                 // Peers take turns synthesizing instead of the facilitator
+	reducer        CollaborationReducer // Merges contributions without a model call
+
+	routeByExpertise bool                     // Skip peers whose expertise does not match the round's sub-question
+	expertise        map[*Agent]PeerExpertise // The session's declared expertise, snapshotted by Discuss
+
+	decision *decisionSpec // Structured decision record built after the final answer
 }
 
 // CollaborationOption configures a collaboration session.
@@ -50,6 +66,62 @@ func WithCaptureHistory(capture bool) CollaborationOption {
 	}
 }
 
+// WithRotatingSynthesis has the peers take turns synthesizing: the first
+// peer synthesizes round 1, the second round 2, and so on, and the peer
+// after the last synthesizer writes the final answer. The facilitator is not
+// used, so the session may be created without one.
+func WithRotatingSynthesis() CollaborationOption {
+	return func(o *collaborationOptions) {
+		o.rotate = true
+	}
+}
+
+// WithReducer merges contributions with reducer instead of a model call.
+// Rounds have no synthesis, every round runs (nobody can conclude early),
+// and the final answer is reducer's output for all rounds. The facilitator
+// is not used, so the session may be created without one. See
+// TemplateReducer.
+func WithReducer(reducer CollaborationReducer) CollaborationOption {
+	return func(o *collaborationOptions) {
+		o.reducer = reducer
+	}
+}
+
+// CollaborationReducer deterministically merges a discussion's rounds into
+// its final answer.
+type CollaborationReducer func(topic string, rounds []CollaborationRound) string
+
+// DefaultReducerTemplate lists every contribution under its round.
+const DefaultReducerTemplate = `Topic: {{.Topic}}
+{{range .Rounds}}
+Round {{.Number}}:
+{{range .Contributions}}- {{.Agent}}: {{.Content}}
+{{end}}{{end}}`
+
+// TemplateReducer returns a reducer that renders a text/template. The
+// template sees .Topic and .Rounds ([]CollaborationRound); an empty text
+// uses DefaultReducerTemplate.
+func TemplateReducer(text string) (CollaborationReducer, error) {
+	if text == "" {
+		text = DefaultReducerTemplate
+	}
+	tmpl, err := template.New("reducer").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("agentkit: parse reducer template: %w", err)
+	}
+	return func(topic string, rounds []CollaborationRound) string {
+		var b strings.Builder
+		data := struct {
+			Topic  string
+			Rounds []CollaborationRound
+		}{topic, rounds}
+		if err := tmpl.Execute(&b, data); err != nil {
+			return fmt.Sprintf("reducer template failed: %v", err)
+		}
+		return strings.TrimSpace(b.String())
+	}, nil
+}
+
 // CollaborationResult contains the outcome of a collaborative discussion.
 type CollaborationResult struct {
 	FinalResponse string                       // The synthesized final answer
@@ -57,6 +129,7 @@ type CollaborationResult struct {
 	Summary       string                       // Summary of the collaboration
 	Participants  []string                     // Names/IDs of participating agents
 	Metadata      map[string]any               // Additional metadata
+	Decision      any                          // Structured decision record (WithDecisionSchema); see DecisionAs
 }
 
 // CollaborationRound represents one round of discussion.
@@ -64,6 +137,9 @@ type CollaborationRound struct {
 	Number        int                           // Round number (1-indexed)
 	Contributions []CollaborationContribution   // Each agent's contribution
 	Synthesis     string                        // How the facilitator synthesized this round
+	Focus         string                        // The question this round addressed: the topic, or a sub-question under WithExpertiseRouting
+	NextFocus     string                        // Sub-question the synthesizer chose for the next round (WithExpertiseRouting)
+	Skipped       []string                      // Peers skipped because their expertise did not match Focus
 }
 
 // CollaborationContribution represents one agent's input in a round.
@@ -71,10 +147,13 @@ type CollaborationContribution struct {
 	Agent   string    // Agent identifier
 	Content string    // What the agent said
 	Time    time.Time // When they contributed
+
+	Expertise []string // The agent's declared expertise tags
+	Weight    float64  // Declared weight, doubled when the expertise matches the round's question
 }
 
 var (
-	ErrCollaborationNoFacilitator = errors.New("agentkit: collaboration requires a facilitator agent")
+	ErrCollaborationNoFacilitator = errors.New("agentkit: collaboration requires a facilitator agent, WithRotatingSynthesis or WithReducer")
 	ErrCollaborationNoPeers       = errors.New("agentkit: collaboration requires at least one peer agent")
 	ErrCollaborationTopicEmpty    = errors.New("agentkit: collaboration topic cannot be empty")
 	ErrCollaborationFailed        = errors.New("agentkit: collaboration failed")
@@ -94,7 +173,7 @@ var (
 func NewCollaborationSession(facilitator *Agent, peers ...*Agent) *CollaborationSession {
 	return &CollaborationSession{
 		facilitator: facilitator,
-		peers:       peers,
+		peers:       slices.Clone(peers),
 		options: collaborationOptions{
 			maxRounds:      3,                // Default: 3 rounds of discussion
 			roundTimeout:   2 * time.Minute,  // Default: 2 minutes per round
@@ -124,7 +203,16 @@ func (cs *CollaborationSession) Configure(opts ...CollaborationOption) *Collabor
 //	    WithMaxRounds(5),
 //	)
 func (cs *CollaborationSession) Discuss(ctx context.Context, topic string, opts ...CollaborationOption) (*CollaborationResult, error) {
-	if cs.facilitator == nil {
+	// Apply any runtime options
+	cs.mu.RLock()
+	options := cs.options
+	options.expertise = maps.Clone(cs.expertise)
+	cs.mu.RUnlock()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if cs.facilitator == nil && !options.facilitatorless() {
 		return nil, ErrCollaborationNoFacilitator
 	}
 	if len(cs.peers) == 0 {
@@ -133,17 +221,14 @@ func (cs *CollaborationSession) Discuss(ctx context.Context, topic string, opts
 	if topic == "" {
 		return nil, ErrCollaborationTopicEmpty
 	}
-
-	// Apply any runtime options
-	options := cs.options
-	for _, opt := range opts {
-		opt(&options)
+	if options.decision != nil && options.decision.err != nil {
+		return nil, fmt.Errorf("agentkit: decision schema: %w", options.decision.err)
 	}
 
 	// Get tracer for this collaboration
 	tracer := GetTracer(ctx)
 	if tracer == nil {
-		tracer = cs.facilitator.tracer
+		tracer = cs.lead().tracer
 	}
 
 	// Create a span for the entire collaboration
@@ -155,7 +240,7 @@ func (cs *CollaborationSession) Discuss(ctx context.Context, topic string, opts
 
 		tracer.SetSpanAttributes(spanCtx, map[string]any{
 			"topic":           topic,
-			"participant_count": len(cs.peers) + 1, // +1 for facilitator
+			"participant_count": len(cs.getParticipantNames()),
 			"max_rounds":      options.maxRounds,
 			"round_timeout":   options.roundTimeout.String(),
 			"capture_history": options.captureHistory,
@@ -172,7 +257,7 @@ func (cs *CollaborationSession) Discuss(ctx context.Context, topic string, opts
 				"error": err.Error(),
 			})
 		}
-		return nil, fmt.Errorf("%w: %v", ErrCollaborationFailed, err)
+		return nil, fmt.Errorf("%w: %w", ErrCollaborationFailed, err)
 	}
 
 	// Record success metrics
@@ -196,11 +281,16 @@ func (cs *CollaborationSession) executeCollaboration(
 	result := &CollaborationResult{
 		Rounds:       make([]CollaborationRound, 0, opts.maxRounds),
 		Participants: cs.getParticipantNames(),
-		Metadata:     make(map[string]any),
+		Metadata:     map[string]any{"topic": topic},
+	}
+	if opts.facilitatorless() && cs.facilitator != nil {
+		// The facilitator takes no part in this discussion.
+		result.Participants = result.Participants[1:]
 	}
 
 	// Shared conversation context that grows with each round
 	conversationHistory := []string{fmt.Sprintf("Topic: %s", topic)}
+	focus := topic
 
 	// Run discussion rounds
 	for roundNum := 1; roundNum <= opts.maxRounds; roundNum++ {
@@ -226,7 +316,7 @@ func (cs *CollaborationSession) executeCollaboration(
 		}
 
 		// Execute the round
-		round, shouldContinue, err := cs.executeRound(roundCtx, roundNum, conversationHistory, tracer)
+		round, shouldContinue, err := cs.executeRound(roundCtx, roundNum, focus, topic, conversationHistory, opts, tracer)
 		if err != nil {
 			// Don't fail the entire collaboration if one round fails
 			// Just record the error and stop
@@ -239,6 +329,9 @@ func (cs *CollaborationSession) executeCollaboration(
 		}
 
 		result.Rounds = append(result.Rounds, round)
+		if round.NextFocus != "" {
+			focus = round.NextFocus
+		}
 
 		// Update conversation history for next round
 		if opts.captureHistory {
@@ -259,14 +352,28 @@ func (cs *CollaborationSession) executeCollaboration(
 	}
 
 	// Have facilitator create final synthesis
-	finalResponse, err := cs.generateFinalSynthesis(ctx, topic, result.Rounds, tracer)
-	if err != nil {
-		return nil, err
+	var finalResponse string
+	if opts.reducer != nil {
+		finalResponse = opts.reducer(topic, result.Rounds)
+	} else {
+		var err error
+		finalResponse, err = cs.generateFinalSynthesis(ctx, cs.synthesizer(len(result.Rounds)+1, opts), topic, result.Rounds, len(opts.expertise) > 0, tracer)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	result.FinalResponse = finalResponse
 	result.Summary = cs.generateSummary(result)
 
+	if opts.decision != nil {
+		decision, err := cs.recordDecision(ctx, cs.synthesizer(len(result.Rounds)+1, opts), result, opts.decision, tracer)
+		if err != nil {
+			return nil, err
+		}
+		result.Decision = decision
+	}
+
 	return result, nil
 }
 
@@ -275,21 +382,34 @@ func (cs *CollaborationSession) executeCollaboration(
 func (cs *CollaborationSession) executeRound(
 	ctx context.Context,
 	roundNum int,
+	focus string,
+	topic string,
 	history []string,
+	opts collaborationOptions,
 	tracer Tracer,
 ) (CollaborationRound, bool, error) {
 	round := CollaborationRound{
 		Number:        roundNum,
 		Contributions: make([]CollaborationContribution, 0, len(cs.peers)),
+		Focus:         focus,
 	}
+	active := routedPeers(cs.peers, opts.expertise, focus, opts.routeByExpertise)
 
 	// Get parent event publisher to forward events in real-time
 	parentPub, hasParent := GetEventPublisher(ctx)
 
 	// Each peer contributes
 	for i, peer := range cs.peers {
+		if !active[i] {
+			round.Skipped = append(round.Skipped, cs.getPeerName(i))
+			continue
+		}
+
 		// Create context for this peer's contribution
 		peerPrompt := cs.buildPeerPrompt(roundNum, history)
+		if focus != topic {
+			peerPrompt += fmt.Sprintf("\n\nThis round focuses on: %s", focus)
+		}
 		
 		// Create span for peer contribution
 		var peerCtx context.Context
@@ -302,12 +422,7 @@ func (cs *CollaborationSession) executeRound(
 		}
 
 		// Get peer's contribution
-		peerWithTracer := *peer
-		if tracer != nil && !isNoOpTracer(tracer) {
-			peerWithTracer.tracer = tracer
-		}
-
-		events := peerWithTracer.Run(peerCtx, peerPrompt)
+		events := participant(peer, tracer).Run(peerCtx, peerPrompt)
 		
 		var response string
 		for event := range events {
@@ -329,10 +444,13 @@ func (cs *CollaborationSession) executeRound(
 			continue
 		}
 
+		expertise := opts.expertise[peer]
 		contribution := CollaborationContribution{
-			Agent:   cs.getPeerName(i),
-			Content: response,
-			Time:    time.Now(),
+			Agent:     cs.getPeerName(i),
+			Content:   response,
+			Time:      time.Now(),
+			Expertise: expertise.Tags,
+			Weight:    expertise.weightFor(focus),
 		}
 		round.Contributions = append(round.Contributions, contribution)
 
@@ -345,12 +463,20 @@ func (cs *CollaborationSession) executeRound(
 		history = append(history, fmt.Sprintf("%s: %s", contribution.Agent, contribution.Content))
 	}
 
+	// The reducer only merges once, for the final answer
+	if opts.reducer != nil {
+		return round, true, nil
+	}
+
 	// Facilitator synthesizes this round
-	synthesis, shouldContinue, err := cs.facilitatorSynthesis(ctx, roundNum, round.Contributions, history, tracer)
+	synthesis, shouldContinue, err := cs.facilitatorSynthesis(ctx, cs.synthesizer(roundNum, opts), roundNum, round.Contributions, history, opts, tracer)
 	if err != nil {
 		return round, false, err
 	}
 
+	if opts.routeByExpertise {
+		synthesis, round.NextFocus = splitNextFocus(synthesis)
+	}
 	round.Synthesis = synthesis
 	return round, shouldContinue, nil
 }
@@ -375,19 +501,29 @@ func (cs *CollaborationSession) buildPeerPrompt(roundNum int, history []string)
 // Events from the facilitator are forwarded to the parent event publisher in real-time.
 func (cs *CollaborationSession) facilitatorSynthesis(
 	ctx context.Context,
+	synthesizer *Agent,
 	roundNum int,
 	contributions []CollaborationContribution,
 	history []string,
+	opts collaborationOptions,
 	tracer Tracer,
 ) (string, bool, error) {
+	weighted := len(opts.expertise) > 0
+
 	// Build synthesis prompt
 	prompt := fmt.Sprintf("You are facilitating a collaborative discussion (Round %d).\n\n", roundNum)
 	prompt += "Contributions this round:\n"
 	for _, contrib := range contributions {
-		prompt += fmt.Sprintf("- %s: %s\n", contrib.Agent, contrib.Content)
+		prompt += contributionLine(contrib, weighted)
 	}
 	prompt += "\nSynthesize the key insights and decide if we need another round. "
+	if weighted {
+		prompt += weightingInstruction
+	}
 	prompt += "If the discussion has converged or the topic is well-explored, say 'CONCLUDE' at the start of your response."
+	if opts.routeByExpertise {
+		prompt += fmt.Sprintf(" Otherwise end with a line '%s <sub-question>' naming what the next round should focus on.", nextFocusMarker)
+	}
 
 	// Create span for synthesis
 	var synthCtx context.Context
@@ -402,14 +538,10 @@ func (cs *CollaborationSession) facilitatorSynthesis(
 	// Get parent event publisher to forward events in real-time
 	parentPub, hasParent := GetEventPublisher(ctx)
 
-	facilitatorWithTracer := *cs.facilitator
-	if tracer != nil && !isNoOpTracer(tracer) {
-		facilitatorWithTracer.tracer = tracer
-	}
-
-	events := facilitatorWithTracer.Run(synthCtx, prompt)
+	events := participant(synthesizer, tracer).Run(synthCtx, prompt)
 	
 	var synthesis string
+	var runErr error
 	for event := range events {
 		// ALWAYS forward events to parent if available (real-time streaming)
 		if hasParent {
@@ -422,9 +554,15 @@ func (cs *CollaborationSession) facilitatorSynthesis(
 				synthesis = resp
 			}
 		}
+		if detail, ok := event.ErrorDetail(); ok {
+			runErr = detail
+		}
 	}
 	
 	if synthesis == "" {
+		if runErr != nil {
+			return "", false, fmt.Errorf("facilitator failed to synthesize round %d: %w", roundNum, runErr)
+		}
 		return "", false, fmt.Errorf("facilitator failed to synthesize round %d", roundNum)
 	}
 
@@ -445,8 +583,10 @@ func (cs *CollaborationSession) facilitatorSynthesis(
 // Events from the facilitator are forwarded to the parent event publisher in real-time.
 func (cs *CollaborationSession) generateFinalSynthesis(
 	ctx context.Context,
+	synthesizer *Agent,
 	topic string,
 	rounds []CollaborationRound,
+	weighted bool,
 	tracer Tracer,
 ) (string, error) {
 	prompt := fmt.Sprintf("Based on the following collaborative discussion about '%s', provide a final synthesized answer.\n\n", topic)
@@ -454,7 +594,7 @@ func (cs *CollaborationSession) generateFinalSynthesis(
 	for _, round := range rounds {
 		prompt += fmt.Sprintf("Round %d:\n", round.Number)
 		for _, contrib := range round.Contributions {
-			prompt += fmt.Sprintf("- %s: %s\n", contrib.Agent, contrib.Content)
+			prompt += contributionLine(contrib, weighted)
 		}
 		if round.Synthesis != "" {
 			prompt += fmt.Sprintf("Synthesis: %s\n", round.Synthesis)
@@ -463,6 +603,9 @@ func (cs *CollaborationSession) generateFinalSynthesis(
 	}
 	
 	prompt += "Provide a clear, comprehensive final answer that incorporates the best insights from all participants."
+	if weighted {
+		prompt += " " + strings.TrimSpace(weightingInstruction)
+	}
 
 	// Create span for final synthesis
 	var finalCtx context.Context
@@ -477,14 +620,10 @@ func (cs *CollaborationSession) generateFinalSynthesis(
 	// Get parent event publisher to forward events in real-time
 	parentPub, hasParent := GetEventPublisher(ctx)
 
-	facilitatorWithTracer := *cs.facilitator
-	if tracer != nil && !isNoOpTracer(tracer) {
-		facilitatorWithTracer.tracer = tracer
-	}
-
-	events := facilitatorWithTracer.Run(finalCtx, prompt)
+	events := participant(synthesizer, tracer).Run(finalCtx, prompt)
 	
 	var finalResponse string
+	var runErr error
 	for event := range events {
 		// ALWAYS forward events to parent if available (real-time streaming)
 		if hasParent {
@@ -497,15 +636,55 @@ func (cs *CollaborationSession) generateFinalSynthesis(
 				finalResponse = resp
 			}
 		}
+		if detail, ok := event.ErrorDetail(); ok {
+			runErr = detail
+		}
 	}
 	
 	if finalResponse == "" {
+		if runErr != nil {
+			return "", fmt.Errorf("facilitator failed to generate final synthesis: %w", runErr)
+		}
 		return "", fmt.Errorf("facilitator failed to generate final synthesis")
 	}
 	
 	return finalResponse, nil
 }
 
+// facilitatorless reports whether the options synthesize without the
+// facilitator.
+func (o collaborationOptions) facilitatorless() bool {
+	return o.rotate || o.reducer != nil
+}
+
+// synthesizer returns the agent that synthesizes round roundNum; the round
+// after the last one is the final synthesis.
+func (cs *CollaborationSession) synthesizer(roundNum int, opts collaborationOptions) *Agent {
+	if opts.rotate || cs.facilitator == nil {
+		return cs.peers[(roundNum-1)%len(cs.peers)]
+	}
+	return cs.facilitator
+}
+
+// lead returns the facilitator, or the first peer without one.
+func (cs *CollaborationSession) lead() *Agent {
+	if cs.facilitator != nil {
+		return cs.facilitator
+	}
+	return cs.peers[0]
+}
+
+// participant returns the copy of agent that makes one contribution,
+// reporting to the collaboration's tracer. Discussions running at the same
+// time never share a copy.
+func participant(agent *Agent, tracer Tracer) *Agent {
+	copied := agent.clone()
+	if tracer != nil && !isNoOpTracer(tracer) {
+		copied.tracer = tracer
+	}
+	return copied
+}
+
 // generateSummary creates a summary of the collaboration.
 func (cs *CollaborationSession) generateSummary(result *CollaborationResult) string {
 	totalContributions := 0
@@ -520,7 +699,9 @@ func (cs *CollaborationSession) generateSummary(result *CollaborationResult) str
 // getParticipantNames returns names of all participants.
 func (cs *CollaborationSession) getParticipantNames() []string {
 	names := make([]string, 0, len(cs.peers)+1)
-	names = append(names, cs.facilitator.getAgentName())
+	if cs.facilitator != nil {
+		names = append(names, cs.facilitator.getAgentName())
+	}
 	for i := range cs.peers {
 		names = append(names, cs.getPeerName(i))
 	}
@@ -553,6 +734,14 @@ func (cs *CollaborationSession) getPeerName(index int) string {
 //	coordinatorAgent.Run(ctx, "We need to decide on the authentication approach...")
 //	// LLM calls: design_collaboration(topic: "How should we design the authentication API?")
 func (cs *CollaborationSession) AsTool(name, description string, opts ...CollaborationOption) Tool {
+	return collaborationTool(name, description, func(ctx context.Context, topic string) (*CollaborationResult, error) {
+		return cs.Discuss(ctx, topic, opts...)
+	})
+}
+
+// collaborationTool builds the tool behind CollaborationSession.AsTool and
+// CollaborationPool.AsTool.
+func collaborationTool(name, description string, discuss func(ctx context.Context, topic string) (*CollaborationResult, error)) Tool {
 	return NewTool(name).
 		WithDescription(description).
 		WithParameter("topic", String().Required().WithDescription("The topic or question for the collaborative discussion")).
@@ -563,18 +752,22 @@ func (cs *CollaborationSession) AsTool(name, description string, opts ...Collabo
 			}
 
 			// Execute the collaboration with provided options
-			result, err := cs.Discuss(ctx, topic, opts...)
+			result, err := discuss(ctx, topic)
 			if err != nil {
 				return nil, err
 			}
 
 			// Return structured result
-			return map[string]any{
+			output := map[string]any{
 				"final_response": result.FinalResponse,
 				"summary":        result.Summary,
 				"rounds":         len(result.Rounds),
 				"participants":   result.Participants,
-			}, nil
+			}
+			if result.Decision != nil {
+				output["decision"] = result.Decision
+			}
+			return output, nil
 		}).
 		Build()
 }
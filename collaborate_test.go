@@ -0,0 +1,173 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func namedAgent(t *testing.T, name, content string) *Agent {
+	t.Helper()
+	agent, err := New(Config{
+		Provider:        &repeatingProvider{content: content},
+		Model:           "test-model",
+		AgentName:       name,
+		StreamResponses: false,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return agent
+}
+
+func TestCollaboration_RotatingSynthesis(t *testing.T) {
+	session := NewCollaborationSession(nil, namedAgent(t, "a", "from a"), namedAgent(t, "b", "from b"))
+
+	result, err := session.Discuss(context.Background(), "naming", WithMaxRounds(2), WithRotatingSynthesis())
+	if err != nil {
+		t.Fatalf("Discuss() error = %v", err)
+	}
+	if len(result.Rounds) != 2 || result.Rounds[0].Synthesis != "from a" || result.Rounds[1].Synthesis != "from b" {
+		t.Errorf("rounds = %+v, want peers taking turns", result.Rounds)
+	}
+	if result.FinalResponse != "from a" {
+		t.Errorf("final response = %q, want the next peer in turn", result.FinalResponse)
+	}
+	if len(result.Participants) != 2 {
+		t.Errorf("participants = %v", result.Participants)
+	}
+}
+
+func TestCollaboration_Reducer(t *testing.T) {
+	// The facilitator has no responses; calling it would fail the discussion.
+	facilitator, err := New(Config{Provider: mockprovider.New(), Model: "test-model", AgentName: "lead", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	reducer, err := TemplateReducer("")
+	if err != nil {
+		t.Fatalf("TemplateReducer() error = %v", err)
+	}
+	session := NewCollaborationSession(facilitator, namedAgent(t, "a", "from a"), namedAgent(t, "b", "from b"))
+
+	result, err := session.Discuss(context.Background(), "naming", WithMaxRounds(2), WithReducer(reducer))
+	if err != nil {
+		t.Fatalf("Discuss() error = %v", err)
+	}
+	want := "Topic: naming\n\nRound 1:\n- a: from a\n- b: from b\n\nRound 2:\n- a: from a\n- b: from b"
+	if result.FinalResponse != want {
+		t.Errorf("final response = %q, want %q", result.FinalResponse, want)
+	}
+	if len(result.Rounds) != 2 || result.Rounds[0].Synthesis != "" {
+		t.Errorf("rounds = %+v", result.Rounds)
+	}
+	if len(result.Participants) != 2 || result.Participants[0] != "a" {
+		t.Errorf("participants = %v, want peers only", result.Participants)
+	}
+}
+
+func TestTemplateReducer(t *testing.T) {
+	reducer, err := TemplateReducer(`{{.Topic}}:{{range .Rounds}}{{range .Contributions}} {{.Content}};{{end}}{{end}}`)
+	if err != nil {
+		t.Fatalf("TemplateReducer() error = %v", err)
+	}
+	got := reducer("ideas", []CollaborationRound{{Number: 1, Contributions: []CollaborationContribution{{Agent: "a", Content: "x"}, {Agent: "b", Content: "y"}}}})
+	if got != "ideas: x; y;" {
+		t.Errorf("reducer = %q", got)
+	}
+	if _, err := TemplateReducer("{{.Topic"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestCollaboration_NoFacilitatorWithoutSynthesisOption(t *testing.T) {
+	session := NewCollaborationSession(nil, namedAgent(t, "a", "from a"))
+	if _, err := session.Discuss(context.Background(), "naming"); !errors.Is(err, ErrCollaborationNoFacilitator) {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestCollaboration_ExpertiseRouting(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("Search is slow in the database.\nNEXT: Postgres indexing", nil).
+		WithResponse("CONCLUDE Add a GIN index.", nil).
+		WithResponse("Add a GIN index on the search column.", nil)}
+	facilitator, err := New(Config{Provider: provider, Model: "test-model", AgentName: "lead", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	db, ui, generalist := namedAgent(t, "db", "from db"), namedAgent(t, "ui", "from ui"), namedAgent(t, "gen", "from gen")
+	session := NewCollaborationSession(facilitator, db, ui, generalist).
+		SetExpertise(db, PeerExpertise{Tags: []string{"postgres"}, Weight: 1.5}).
+		SetExpertise(ui, PeerExpertise{Tags: []string{"frontend"}})
+
+	result, err := session.Discuss(context.Background(), "Speed up the search page", WithExpertiseRouting())
+	if err != nil {
+		t.Fatalf("Discuss() error = %v", err)
+	}
+	if len(result.Rounds) != 2 {
+		t.Fatalf("rounds = %+v", result.Rounds)
+	}
+	first, second := result.Rounds[0], result.Rounds[1]
+	if len(first.Contributions) != 3 || len(first.Skipped) != 0 {
+		t.Errorf("round 1 = %+v, want everyone when no expert matches", first)
+	}
+	if first.Synthesis != "Search is slow in the database." || first.NextFocus != "Postgres indexing" {
+		t.Errorf("round 1 synthesis = %q, next = %q", first.Synthesis, first.NextFocus)
+	}
+	if second.Focus != "Postgres indexing" || len(second.Skipped) != 1 || second.Skipped[0] != "ui" {
+		t.Errorf("round 2 = %+v, want ui skipped", second)
+	}
+	if second.Contributions[0].Agent != "db" || second.Contributions[0].Weight != 3 || second.Contributions[1].Weight != 1 {
+		t.Errorf("round 2 contributions = %+v", second.Contributions)
+	}
+
+	prompt := provider.requests[1].Messages[0].Content
+	if !strings.Contains(prompt, "- db (expertise: postgres; weight 3.0): from db") || !strings.Contains(prompt, "Weigh each contribution") {
+		t.Errorf("synthesis prompt = %q", prompt)
+	}
+}
+
+func TestCollaboration_DecisionSchema(t *testing.T) {
+	record := `{"options_considered": [{"option": "NATS", "proposed_by": "a", "pros": ["simple"], "cons": []}, {"option": "Kafka", "proposed_by": null, "pros": [], "cons": ["ops cost"]}],
+		"decision": "NATS", "rationale": "Lower operational cost.", "action_items": [{"description": "Prototype NATS", "owner": "a"}]}`
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("CONCLUDE NATS is enough.", nil).
+		WithResponse("Adopt NATS.", nil).
+		WithResponse(record, nil)}
+	facilitator, err := New(Config{Provider: provider, Model: "test-model", AgentName: "lead", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	session := NewCollaborationSession(facilitator, namedAgent(t, "a", "NATS"), namedAgent(t, "b", "Kafka is costly"))
+
+	result, err := session.Discuss(context.Background(), "Which queue?", WithMaxRounds(1), WithDecisionSchema[DecisionRecord]())
+	if err != nil {
+		t.Fatalf("Discuss() error = %v", err)
+	}
+	if result.FinalResponse != "Adopt NATS." {
+		t.Errorf("final response = %q", result.FinalResponse)
+	}
+	decision, ok := DecisionAs[DecisionRecord](result)
+	if !ok {
+		t.Fatalf("decision = %#v, want a DecisionRecord", result.Decision)
+	}
+	if decision.Decision != "NATS" || len(decision.OptionsConsidered) != 2 || decision.ActionItems[0].Owner != "a" {
+		t.Errorf("decision = %+v", decision)
+	}
+	prompt := provider.requests[2].Messages[0].Content
+	if !strings.Contains(prompt, "## Decision\n\nAdopt NATS.") {
+		t.Errorf("decision prompt = %q, want the transcript", prompt)
+	}
+}
+
+func TestCollaboration_DecisionSchemaRequiresStruct(t *testing.T) {
+	session := NewCollaborationSession(nil, namedAgent(t, "a", "from a"))
+	_, err := session.Discuss(context.Background(), "naming", WithRotatingSynthesis(), WithDecisionSchema[string]())
+	if !errors.Is(err, ErrInvalidStructSchema) {
+		t.Errorf("error = %v, want ErrInvalidStructSchema", err)
+	}
+}
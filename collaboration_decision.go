@@ -0,0 +1,109 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DecisionRecord is a ready-made decision schema: the options the
+// participants considered, what was decided and why, and the next steps.
+// Use it as WithDecisionSchema[DecisionRecord]() or define your own type.
+type DecisionRecord struct {
+	OptionsConsidered []DecisionOption `json:"options_considered" desc:"Every option the participants discussed"`
+	Decision          string           `json:"decision" desc:"The option chosen, or what was decided"`
+	Rationale         string           `json:"rationale" desc:"Why this was chosen over the alternatives"`
+	ActionItems       []ActionItem     `json:"action_items" desc:"Concrete next steps"`
+}
+
+// DecisionOption is one option considered in a DecisionRecord.
+type DecisionOption struct {
+	Option     string   `json:"option"`
+	ProposedBy string   `json:"proposed_by,omitempty" desc:"Participant who proposed it"`
+	Pros       []string `json:"pros"`
+	Cons       []string `json:"cons"`
+}
+
+// ActionItem is a next step in a DecisionRecord.
+type ActionItem struct {
+	Description string `json:"description"`
+	Owner       string `json:"owner,omitempty" desc:"Participant responsible, if one was named"`
+}
+
+// decisionSpec is the schema and decoder behind WithDecisionSchema.
+type decisionSpec struct {
+	schema map[string]any
+	err    error
+	decode func(raw json.RawMessage) (any, error)
+}
+
+// WithDecisionSchema has the discussion end with a structured decision
+// record of type T, built from the rounds and the final answer and checked
+// against T's schema (see SchemaFromStruct). The record is returned in
+// CollaborationResult.Decision; read it with DecisionAs[T]. T must be a
+// struct; Discuss returns ErrInvalidStructSchema otherwise.
+//
+// Example:
+//
+//	result, err := session.Discuss(ctx, "Which queue should we adopt?",
+//	    agentkit.WithDecisionSchema[agentkit.DecisionRecord](),
+//	)
+//	record, _ := agentkit.DecisionAs[agentkit.DecisionRecord](result)
+func WithDecisionSchema[T any]() CollaborationOption {
+	var zero T
+	schema, err := SchemaFromStruct(zero)
+	spec := &decisionSpec{
+		schema: schema,
+		err:    err,
+		decode: func(raw json.RawMessage) (any, error) {
+			var value T
+			err := json.Unmarshal(raw, &value)
+			return value, err
+		},
+	}
+	return func(o *collaborationOptions) {
+		o.decision = spec
+	}
+}
+
+// DecisionAs returns the decision record of a discussion run with
+// WithDecisionSchema[T].
+func DecisionAs[T any](result *CollaborationResult) (T, bool) {
+	value, ok := result.Decision.(T)
+	return value, ok
+}
+
+// recordDecision has recorder turn the finished discussion into a decision
+// record matching spec's schema.
+func (cs *CollaborationSession) recordDecision(
+	ctx context.Context,
+	recorder *Agent,
+	result *CollaborationResult,
+	spec *decisionSpec,
+	tracer Tracer,
+) (any, error) {
+	if tracer != nil && !isNoOpTracer(tracer) {
+		var endSpan func()
+		ctx, endSpan = tracer.StartSpan(ctx, "decision_record")
+		defer endSpan()
+	}
+
+	output, err := CompleteJSON(ctx, recorder.provider, providers.CompletionRequest{
+		Model:        recorder.model,
+		SystemPrompt: "You record the outcome of team discussions as structured decision records. Use only what the participants said; do not invent options, owners or steps.",
+		Messages: []providers.Message{{
+			Role:    providers.RoleUser,
+			Content: "Record the decision reached in this discussion.\n\n" + result.Markdown(),
+		}},
+	}, spec.schema, DefaultJSONRepairs)
+	if err != nil {
+		return nil, fmt.Errorf("record decision: %w", err)
+	}
+	decision, err := spec.decode(output.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode decision: %w", err)
+	}
+	return decision, nil
+}
@@ -0,0 +1,121 @@
+package agentkit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PeerExpertise declares what a collaboration peer knows about. The
+// facilitator's round and final syntheses see each contribution's weight,
+// and WithExpertiseRouting skips peers whose tags do not match the current
+// sub-question.
+type PeerExpertise struct {
+	Tags   []string // Topics the peer is expert in, e.g. "security", "postgres"
+	Weight float64  // Relative weight of the peer's contributions; zero means 1
+}
+
+// relevantTo reports whether any tag appears in question, ignoring case.
+func (e PeerExpertise) relevantTo(question string) bool {
+	question = strings.ToLower(question)
+	for _, tag := range e.Tags {
+		if tag != "" && strings.Contains(question, strings.ToLower(tag)) {
+			return true
+		}
+	}
+	return false
+}
+
+// weightFor returns the weight of a contribution to question: the declared
+// weight, doubled when the peer's expertise is relevant.
+func (e PeerExpertise) weightFor(question string) float64 {
+	weight := e.Weight
+	if weight <= 0 {
+		weight = 1
+	}
+	if e.relevantTo(question) {
+		weight *= 2
+	}
+	return weight
+}
+
+// SetExpertise declares peer's expertise for this session's discussions.
+// Peers without declared expertise are treated as generalists: weight 1,
+// never skipped.
+//
+// Example:
+//
+//	session.SetExpertise(securityAgent, agentkit.PeerExpertise{Tags: []string{"auth", "security"}, Weight: 1.5})
+//	result, err := session.Discuss(ctx, "How should we store session tokens?", agentkit.WithExpertiseRouting())
+func (cs *CollaborationSession) SetExpertise(peer *Agent, expertise PeerExpertise) *CollaborationSession {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.expertise == nil {
+		cs.expertise = make(map[*Agent]PeerExpertise)
+	}
+	cs.expertise[peer] = expertise
+	return cs
+}
+
+// WithExpertiseRouting has each round address a sub-question and skips
+// peers whose declared expertise does not match it. The first round
+// addresses the topic; the synthesizer names the sub-question for the next
+// round. Generalists (no tags) always contribute, and when no expert matches
+// everyone does. See SetExpertise.
+func WithExpertiseRouting() CollaborationOption {
+	return func(o *collaborationOptions) {
+		o.routeByExpertise = true
+	}
+}
+
+// routedPeers returns whether each peer contributes to a round on question.
+func routedPeers(peers []*Agent, expertise map[*Agent]PeerExpertise, question string, route bool) []bool {
+	active := make([]bool, len(peers))
+	anyExpert := false
+	for i, peer := range peers {
+		exp := expertise[peer]
+		active[i] = len(exp.Tags) == 0 || !route || exp.relevantTo(question)
+		anyExpert = anyExpert || (len(exp.Tags) > 0 && exp.relevantTo(question))
+	}
+	if !anyExpert {
+		for i := range active {
+			active[i] = true
+		}
+	}
+	return active
+}
+
+// nextFocusMarker starts the synthesis line that names the next round's
+// sub-question under WithExpertiseRouting.
+const nextFocusMarker = "NEXT:"
+
+// splitNextFocus removes the last NEXT: line from synthesis and returns the
+// sub-question it names.
+func splitNextFocus(synthesis string) (string, string) {
+	lines := strings.Split(synthesis, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if len(line) >= len(nextFocusMarker) && strings.EqualFold(line[:len(nextFocusMarker)], nextFocusMarker) {
+			focus := strings.TrimSpace(line[len(nextFocusMarker):])
+			rest := append(lines[:i:i], lines[i+1:]...)
+			return strings.TrimSpace(strings.Join(rest, "\n")), focus
+		}
+	}
+	return synthesis, ""
+}
+
+// contributionLine formats a contribution for a synthesis prompt, with its
+// expertise and weight when the session declares any.
+func contributionLine(contrib CollaborationContribution, weighted bool) string {
+	if !weighted {
+		return fmt.Sprintf("- %s: %s\n", contrib.Agent, contrib.Content)
+	}
+	label := fmt.Sprintf("weight %.1f", contrib.Weight)
+	if len(contrib.Expertise) > 0 {
+		label = "expertise: " + strings.Join(contrib.Expertise, ", ") + "; " + label
+	}
+	return fmt.Sprintf("- %s (%s): %s\n", contrib.Agent, label, contrib.Content)
+}
+
+// weightingInstruction tells the synthesizer how to use contribution weights.
+const weightingInstruction = "Weigh each contribution by its weight: prefer the views of relevant experts where participants disagree. "
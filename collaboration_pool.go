@@ -0,0 +1,75 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCollaborationPoolSize is returned by NewCollaborationPool for a size
+// below one.
+var ErrCollaborationPoolSize = errors.New("agentkit: collaboration pool size must be at least 1")
+
+// CollaborationPool serves many simultaneous discussions from a bounded set
+// of sessions. At most size discussions run at once; callers beyond that
+// wait for a session to free up or for their context to end. Sessions are
+// created on first use by newSession and reused afterwards, so agents that
+// hold per-session resources (HTTP clients, memory, state) are not shared
+// between concurrent discussions.
+type CollaborationPool struct {
+	newSession func() *CollaborationSession
+	slots      chan struct{}
+	idle       chan *CollaborationSession
+}
+
+// NewCollaborationPool creates a pool of at most size sessions.
+//
+// Example:
+//
+//	pool, err := agentkit.NewCollaborationPool(8, func() *agentkit.CollaborationSession {
+//	    return agentkit.NewCollaborationSession(newFacilitator(), newEngineer(), newDesigner())
+//	})
+//	result, err := pool.Discuss(ctx, "How should we version the public API?")
+func NewCollaborationPool(size int, newSession func() *CollaborationSession) (*CollaborationPool, error) {
+	if size < 1 {
+		return nil, ErrCollaborationPoolSize
+	}
+	return &CollaborationPool{
+		newSession: newSession,
+		slots:      make(chan struct{}, size),
+		idle:       make(chan *CollaborationSession, size),
+	}, nil
+}
+
+// Discuss runs a discussion on a free session, waiting for one when all are
+// busy. It returns ctx's error if ctx ends while waiting.
+func (p *CollaborationPool) Discuss(ctx context.Context, topic string, opts ...CollaborationOption) (*CollaborationResult, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	var session *CollaborationSession
+	select {
+	case session = <-p.idle:
+	default:
+		session = p.newSession()
+	}
+	defer func() { p.idle <- session }()
+
+	return session.Discuss(ctx, topic, opts...)
+}
+
+// InUse returns the number of discussions currently running.
+func (p *CollaborationPool) InUse() int {
+	return len(p.slots)
+}
+
+// AsTool converts the pool into a Tool, like CollaborationSession.AsTool,
+// for agents that may start several discussions at once.
+func (p *CollaborationPool) AsTool(name, description string, opts ...CollaborationOption) Tool {
+	return collaborationTool(name, description, func(ctx context.Context, topic string) (*CollaborationResult, error) {
+		return p.Discuss(ctx, topic, opts...)
+	})
+}
@@ -0,0 +1,118 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// repeatingProvider answers every request with the same content.
+type repeatingProvider struct {
+	*mockprovider.Provider
+	content string
+	gate    chan struct{} // When set, each call waits for a value
+}
+
+func (p *repeatingProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	if p.gate != nil {
+		select {
+		case <-p.gate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return &providers.CompletionResponse{Content: p.content, FinishReason: providers.FinishReasonStop}, nil
+}
+
+func newDiscussionSession(t *testing.T, gate chan struct{}) *CollaborationSession {
+	t.Helper()
+	agent := func(content string) *Agent {
+		a, err := New(Config{Provider: &repeatingProvider{content: content, gate: gate}, Model: "test-model", StreamResponses: false})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return a
+	}
+	return NewCollaborationSession(agent("CONCLUDE Agreed."), agent("Use semver."))
+}
+
+func TestCollaborationSession_ConcurrentDiscuss(t *testing.T) {
+	session := newDiscussionSession(t, nil)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			session.Configure(WithRoundTimeout(time.Minute))
+			result, err := session.Discuss(context.Background(), "versioning", WithMaxRounds(2))
+			if err != nil {
+				t.Errorf("Discuss() error = %v", err)
+				return
+			}
+			if len(result.Rounds) != 1 || result.Rounds[0].Contributions[0].Content != "Use semver." {
+				t.Errorf("rounds = %+v", result.Rounds)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCollaborationPool_BoundsConcurrentDiscussions(t *testing.T) {
+	gate := make(chan struct{})
+	var created atomic.Int32
+	pool, err := NewCollaborationPool(2, func() *CollaborationSession {
+		created.Add(1)
+		return newDiscussionSession(t, gate)
+	})
+	if err != nil {
+		t.Fatalf("NewCollaborationPool() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pool.Discuss(context.Background(), "versioning", WithMaxRounds(1)); err != nil {
+				t.Errorf("Discuss() error = %v", err)
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for pool.InUse() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if pool.InUse() != 2 {
+		t.Fatalf("in use = %d, want 2", pool.InUse())
+	}
+
+	// A caller that gives up while waiting gets its context's error.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Discuss(ctx, "versioning"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiting Discuss() error = %v", err)
+	}
+
+	close(gate)
+	wg.Wait()
+	if n := created.Load(); n != 2 {
+		t.Errorf("sessions created = %d, want 2 reused", n)
+	}
+	if pool.InUse() != 0 {
+		t.Errorf("in use = %d after all discussions finished", pool.InUse())
+	}
+}
+
+func TestNewCollaborationPool_InvalidSize(t *testing.T) {
+	if _, err := NewCollaborationPool(0, nil); !errors.Is(err, ErrCollaborationPoolSize) {
+		t.Errorf("error = %v", err)
+	}
+}
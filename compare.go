@@ -0,0 +1,249 @@
+package agentkit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RecordedToolCall is a tool call made during a recorded run.
+type RecordedToolCall struct {
+	Tool        string         `json:"tool"`
+	Arguments   map[string]any `json:"arguments,omitempty"`
+	Description string         `json:"description,omitempty"`
+}
+
+// ToolCallChange classifies a ToolCallDiff.
+type ToolCallChange string
+
+const (
+	ToolCallAdded   ToolCallChange = "added"
+	ToolCallRemoved ToolCallChange = "removed"
+	ToolCallChanged ToolCallChange = "changed" // Same tool, different arguments
+)
+
+// ToolCallDiff is a tool call that differs between two runs. Before is nil
+// for added calls and After is nil for removed calls.
+type ToolCallDiff struct {
+	Change ToolCallChange    `json:"change"`
+	Before *RecordedToolCall `json:"before,omitempty"`
+	After  *RecordedToolCall `json:"after,omitempty"`
+}
+
+// RunDiff is the difference between two recorded runs of the same input,
+// e.g. before and after a prompt change.
+type RunDiff struct {
+	Before ShadowRun `json:"before"`
+	After  ShadowRun `json:"after"`
+	// BeforeCalls and AfterCalls are the tool calls of each run in order.
+	BeforeCalls []RecordedToolCall `json:"before_calls,omitempty"`
+	AfterCalls  []RecordedToolCall `json:"after_calls,omitempty"`
+	// Divergence is the index of the first step where the runs differ,
+	// counting each tool call as a step and the final output as the step
+	// after the last call. It is -1 when the runs are the same.
+	Divergence int            `json:"divergence"`
+	ToolCalls  []ToolCallDiff `json:"tool_calls,omitempty"`
+	TokenDelta int            `json:"token_delta"`
+	CostDelta  float64        `json:"cost_delta"`
+}
+
+// Identical reports whether both runs made the same tool calls and
+// produced the same output and error.
+func (d RunDiff) Identical() bool {
+	return d.Divergence < 0
+}
+
+// CompareRuns diffs two recorded runs, as returned by EventRecorder.Events
+// or decoded from JSON. Only the root agent's events are compared; events
+// from agents started by handoffs or collaborations are ignored. Tool calls
+// are matched by tool name in order, so a call whose arguments changed is
+// reported as changed rather than as a removal and an addition.
+func CompareRuns(before, after []Event) RunDiff {
+	diff := RunDiff{Divergence: -1}
+	diff.Before, diff.BeforeCalls = summarizeRecorded(before)
+	diff.After, diff.AfterCalls = summarizeRecorded(after)
+	diff.TokenDelta = diff.After.TotalTokens - diff.Before.TotalTokens
+	diff.CostDelta = diff.After.Cost - diff.Before.Cost
+	diff.ToolCalls = diffToolCalls(diff.BeforeCalls, diff.AfterCalls)
+
+	steps := min(len(diff.BeforeCalls), len(diff.AfterCalls))
+	for i := 0; i < steps; i++ {
+		if !sameToolCall(diff.BeforeCalls[i], diff.AfterCalls[i]) {
+			diff.Divergence = i
+			return diff
+		}
+	}
+	if len(diff.BeforeCalls) != len(diff.AfterCalls) ||
+		diff.Before.Output != diff.After.Output || diff.Before.Error != diff.After.Error {
+		diff.Divergence = steps
+	}
+	return diff
+}
+
+// summarizeRecorded summarizes a recorded run and collects its tool calls.
+func summarizeRecorded(events []Event) (ShadowRun, []RecordedToolCall) {
+	var run ShadowRun
+	var calls []RecordedToolCall
+	var first, last time.Time
+	for _, event := range events {
+		if source, ok := event.Source(); ok && source.Depth > 0 {
+			continue
+		}
+		if first.IsZero() {
+			first = event.Timestamp
+		}
+		last = event.Timestamp
+		run.observe(event)
+		switch event.Type {
+		case EventTypeActionDetected:
+			call := RecordedToolCall{}
+			call.Tool, _ = event.Data["tool_name"].(string)
+			call.Arguments, _ = event.Data["arguments"].(map[string]any)
+			call.Description, _ = event.Data["description"].(string)
+			calls = append(calls, call)
+		case EventTypeAgentComplete:
+			if ms, ok := event.Data["duration_ms"]; ok {
+				run.Duration = time.Duration(toFloat(ms)) * time.Millisecond
+			}
+		}
+	}
+	if run.Duration == 0 && !first.IsZero() {
+		run.Duration = last.Sub(first)
+	}
+	return run, calls
+}
+
+// sameToolCall compares tool calls by name and arguments. Arguments are
+// compared as JSON so recorded runs decoded from JSON match live ones.
+func sameToolCall(a, b RecordedToolCall) bool {
+	if a.Tool != b.Tool {
+		return false
+	}
+	if a.Tool == "" {
+		// Recorded before action_detected carried the tool name.
+		return a.Description == b.Description
+	}
+	return argumentsJSON(a.Arguments) == argumentsJSON(b.Arguments)
+}
+
+func argumentsJSON(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Sprint(args)
+	}
+	return string(data)
+}
+
+// diffToolCalls aligns the two call sequences on their longest common
+// subsequence of tool names and reports everything outside it, plus aligned
+// calls whose arguments differ.
+func diffToolCalls(before, after []RecordedToolCall) []ToolCallDiff {
+	// lcs[i][j] is the LCS length of before[i:] and after[j:].
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i].Tool == after[j].Tool {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diffs []ToolCallDiff
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i].Tool == after[j].Tool:
+			if !sameToolCall(before[i], after[j]) {
+				diffs = append(diffs, ToolCallDiff{Change: ToolCallChanged, Before: &before[i], After: &after[j]})
+			}
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diffs = append(diffs, ToolCallDiff{Change: ToolCallRemoved, Before: &before[i]})
+			i++
+		default:
+			diffs = append(diffs, ToolCallDiff{Change: ToolCallAdded, After: &after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		diffs = append(diffs, ToolCallDiff{Change: ToolCallRemoved, Before: &before[i]})
+	}
+	for ; j < len(after); j++ {
+		diffs = append(diffs, ToolCallDiff{Change: ToolCallAdded, After: &after[j]})
+	}
+	return diffs
+}
+
+// Markdown renders the diff for a pull request description or review
+// comment: a metrics table, the tool call changes and the outputs side by
+// side.
+func (d RunDiff) Markdown() string {
+	var b strings.Builder
+	b.WriteString("### Run comparison\n\n")
+	if d.Identical() {
+		b.WriteString("The runs made the same tool calls and produced the same output.\n\n")
+	} else if d.Divergence < len(d.BeforeCalls) || d.Divergence < len(d.AfterCalls) {
+		fmt.Fprintf(&b, "The runs diverge at tool call %d.\n\n", d.Divergence+1)
+	} else {
+		b.WriteString("The runs made the same tool calls but their outputs differ.\n\n")
+	}
+
+	b.WriteString("| | Before | After | Delta |\n|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| Tokens | %d | %d | %+d |\n", d.Before.TotalTokens, d.After.TotalTokens, d.TokenDelta)
+	fmt.Fprintf(&b, "| Cost | $%.6f | $%.6f | %+.6f |\n", d.Before.Cost, d.After.Cost, d.CostDelta)
+	fmt.Fprintf(&b, "| Iterations | %d | %d | %+d |\n", d.Before.Iterations, d.After.Iterations, d.After.Iterations-d.Before.Iterations)
+	fmt.Fprintf(&b, "| Tool calls | %d | %d | %+d |\n", len(d.BeforeCalls), len(d.AfterCalls), len(d.AfterCalls)-len(d.BeforeCalls))
+	fmt.Fprintf(&b, "| Duration | %s | %s | %+.0fms |\n", d.Before.Duration, d.After.Duration, float64(d.After.Duration-d.Before.Duration)/float64(time.Millisecond))
+
+	if len(d.ToolCalls) > 0 {
+		b.WriteString("\n#### Tool calls\n\n")
+		for _, c := range d.ToolCalls {
+			switch c.Change {
+			case ToolCallAdded:
+				fmt.Fprintf(&b, "- added `%s` %s\n", c.After.Tool, argumentsJSON(c.After.Arguments))
+			case ToolCallRemoved:
+				fmt.Fprintf(&b, "- removed `%s` %s\n", c.Before.Tool, argumentsJSON(c.Before.Arguments))
+			case ToolCallChanged:
+				fmt.Fprintf(&b, "- changed `%s` %s → %s\n", c.Before.Tool, argumentsJSON(c.Before.Arguments), argumentsJSON(c.After.Arguments))
+			}
+		}
+	}
+
+	b.WriteString("\n#### Output\n\n| Before | After |\n|---|---|\n")
+	before := outputLines(d.Before)
+	after := outputLines(d.After)
+	for i := 0; i < max(len(before), len(after)); i++ {
+		var left, right string
+		if i < len(before) {
+			left = before[i]
+		}
+		if i < len(after) {
+			right = after[i]
+		}
+		fmt.Fprintf(&b, "| %s | %s |\n", left, right)
+	}
+	return b.String()
+}
+
+// outputLines splits a run's output (or its error) into table-safe lines.
+func outputLines(run ShadowRun) []string {
+	text := run.Output
+	if run.Error != "" {
+		text = strings.TrimSpace(text + "\n**error:** " + run.Error)
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.ReplaceAll(line, "|", `\|`)
+	}
+	return lines
+}
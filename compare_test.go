@@ -0,0 +1,108 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func recordRun(t *testing.T, provider *mockprovider.Provider) []Event {
+	t.Helper()
+	agent := newMockAgent(t, provider)
+	agent.AddTool(echoTool("lookup"))
+	agent.AddTool(echoTool("policy"))
+	return collectEvents(agent.Run(context.Background(), "refund order 7"), 2*time.Second)
+}
+
+func TestCompareRuns(t *testing.T) {
+	before := recordRun(t, mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "c1", Name: "lookup", Arguments: map[string]any{"id": "7"}}}).
+		WithResponse("Refunded.", nil))
+	after := recordRun(t, mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "c1", Name: "lookup", Arguments: map[string]any{"id": 7}}}).
+		WithResponse("", []providers.ToolCall{{ID: "c2", Name: "policy", Arguments: map[string]any{}}}).
+		WithResponse("Refund needs approval.", nil))
+
+	diff := CompareRuns(before, after)
+	if diff.Identical() || diff.Divergence != 0 {
+		t.Errorf("divergence = %d, want 0", diff.Divergence)
+	}
+	if len(diff.ToolCalls) != 2 ||
+		diff.ToolCalls[0].Change != ToolCallChanged || diff.ToolCalls[0].Before.Arguments["id"] != "7" ||
+		diff.ToolCalls[1].Change != ToolCallAdded || diff.ToolCalls[1].After.Tool != "policy" {
+		t.Errorf("tool calls = %+v", diff.ToolCalls)
+	}
+	if diff.TokenDelta != 30 || diff.Before.Output != "Refunded." || diff.After.Output != "Refund needs approval." {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	md := diff.Markdown()
+	for _, want := range []string{
+		"diverge at tool call 1",
+		"| Tokens | 60 | 90 | +30 |",
+		"- changed `lookup` {\"id\":\"7\"} → {\"id\":7}",
+		"- added `policy` {}",
+		"| Refunded. | Refund needs approval. |",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestCompareRuns_OutputOnlyAndJSONRoundTrip(t *testing.T) {
+	run := func(answer string) []Event {
+		return recordRun(t, mockprovider.New().
+			WithResponse("", []providers.ToolCall{{ID: "c1", Name: "lookup", Arguments: map[string]any{"id": "7"}}}).
+			WithResponse(answer, nil))
+	}
+	before := run("Refunded.")
+
+	// Runs saved as JSON compare equal to live ones.
+	data, err := json.Marshal(before)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded []Event
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if diff := CompareRuns(before, decoded); !diff.Identical() || len(diff.ToolCalls) != 0 || diff.After.TotalTokens != 60 {
+		t.Errorf("decoded run diff = %+v", diff)
+	}
+
+	diff := CompareRuns(before, run("Refunded | no fee."))
+	if diff.Divergence != 1 || len(diff.ToolCalls) != 0 {
+		t.Errorf("divergence = %d, tool calls = %+v", diff.Divergence, diff.ToolCalls)
+	}
+	if md := diff.Markdown(); !strings.Contains(md, "outputs differ") || !strings.Contains(md, `| Refunded. | Refunded \| no fee. |`) {
+		t.Errorf("markdown:\n%s", md)
+	}
+}
+
+func TestDiffToolCalls_RemovedAndReordered(t *testing.T) {
+	calls := func(names ...string) []RecordedToolCall {
+		var out []RecordedToolCall
+		for _, name := range names {
+			out = append(out, RecordedToolCall{Tool: name})
+		}
+		return out
+	}
+	diffs := diffToolCalls(calls("search", "lookup", "refund"), calls("lookup", "refund", "notify"))
+	var got []string
+	for _, d := range diffs {
+		call := d.Before
+		if call == nil {
+			call = d.After
+		}
+		got = append(got, string(d.Change)+" "+call.Tool)
+	}
+	if strings.Join(got, ", ") != "removed search, added notify" {
+		t.Errorf("diffs = %v", got)
+	}
+}
@@ -0,0 +1,211 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	"github.com/darkostanimirovic/agentkit/retrieval"
+)
+
+// ErrNothingToCompact is returned by a ContextManager when history cannot
+// be made any shorter.
+var ErrNothingToCompact = errors.New("agentkit: conversation history cannot be compacted further")
+
+// ContextManager shrinks conversation history that no longer fits the
+// model's context window. When a provider reports context_length_exceeded,
+// the agent compacts the run's history with Config.ContextManager (default
+// TruncateOldest) and retries the iteration once, emitting a
+// context.compacted event.
+type ContextManager interface {
+	Compact(ctx context.Context, history []providers.Message) ([]providers.Message, error)
+}
+
+// TruncateOldest drops the oldest messages, keeping the first user message
+// (the task) and the KeepLast most recent ones (default: half the history).
+type TruncateOldest struct {
+	KeepLast int
+}
+
+// Compact implements ContextManager.
+func (t TruncateOldest) Compact(ctx context.Context, history []providers.Message) ([]providers.Message, error) {
+	head, dropped, tail, err := splitHistory(history, t.KeepLast)
+	if err != nil {
+		return nil, err
+	}
+	compacted := append([]providers.Message{}, head...)
+	compacted = append(compacted, providers.Message{
+		Role:    providers.RoleUser,
+		Content: fmt.Sprintf("[%d earlier messages were removed to fit the context window.]", len(dropped)),
+	})
+	return append(compacted, tail...), nil
+}
+
+// Summarize replaces the oldest messages with a summary written by the
+// model, keeping the first user message and the KeepLast most recent ones
+// (default: half the history).
+type Summarize struct {
+	Provider providers.Provider
+	Model    string
+	KeepLast int
+	// MaxSummaryChars truncates each dropped message in the summarization
+	// prompt (default 2000), so the prompt itself fits.
+	MaxSummaryChars int
+}
+
+// Compact implements ContextManager.
+func (s Summarize) Compact(ctx context.Context, history []providers.Message) ([]providers.Message, error) {
+	if s.Provider == nil {
+		return nil, errors.New("agentkit: Summarize requires a Provider")
+	}
+	head, dropped, tail, err := splitHistory(history, s.KeepLast)
+	if err != nil {
+		return nil, err
+	}
+	limit := s.MaxSummaryChars
+	if limit <= 0 {
+		limit = 2000
+	}
+
+	var transcript strings.Builder
+	for _, msg := range dropped {
+		content := msg.Content
+		if len(content) > limit {
+			content = content[:limit] + "..."
+		}
+		for _, call := range msg.ToolCalls {
+			content += fmt.Sprintf(" [called %s]", call.Name)
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, content)
+	}
+
+	resp, err := s.Provider.Complete(ctx, providers.CompletionRequest{
+		Model:        s.Model,
+		SystemPrompt: "Summarize the conversation excerpt below. Keep facts, decisions, tool results and open questions needed to continue the task. Be concise.",
+		Messages:     []providers.Message{{Role: providers.RoleUser, Content: transcript.String()}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("agentkit: summarize history: %w", err)
+	}
+
+	compacted := append([]providers.Message{}, head...)
+	compacted = append(compacted, providers.Message{
+		Role:    providers.RoleUser,
+		Content: "Summary of the earlier conversation:\n" + resp.Content,
+	})
+	return append(compacted, tail...), nil
+}
+
+// Hybrid keeps a running summary of older messages and a sliding window of
+// the KeepLast most recent ones, like Summarize, but drops the oldest
+// messages instead when summarization fails, so an unavailable summary model
+// does not stop the run.
+type Hybrid struct {
+	Provider        providers.Provider
+	Model           string
+	KeepLast        int
+	MaxSummaryChars int
+}
+
+// Compact implements ContextManager.
+func (h Hybrid) Compact(ctx context.Context, history []providers.Message) ([]providers.Message, error) {
+	compacted, err := Summarize(h).Compact(ctx, history)
+	if err == nil || errors.Is(err, ErrNothingToCompact) {
+		return compacted, err
+	}
+	return TruncateOldest{KeepLast: h.KeepLast}.Compact(ctx, history)
+}
+
+// CompressToolResults compresses long tool results (typically retrieved
+// documents) in place, using the first user message as the query, before
+// dropping anything. When no tool result can be shortened any further it
+// falls back to Next (default TruncateOldest).
+type CompressToolResults struct {
+	// Compressor shortens each tool result (default
+	// retrieval.SentenceCompressor{}).
+	Compressor retrieval.Compressor
+	// MinChars leaves tool results shorter than this untouched (default 1000).
+	MinChars int
+	Next     ContextManager
+}
+
+// Compact implements ContextManager.
+func (c CompressToolResults) Compact(ctx context.Context, history []providers.Message) ([]providers.Message, error) {
+	compressor := c.Compressor
+	if compressor == nil {
+		compressor = retrieval.SentenceCompressor{}
+	}
+	minChars := c.MinChars
+	if minChars <= 0 {
+		minChars = 1000
+	}
+	var query string
+	for _, msg := range history {
+		if msg.Role == providers.RoleUser {
+			query = msg.Content
+			break
+		}
+	}
+
+	compacted := make([]providers.Message, len(history))
+	copy(compacted, history)
+	saved := 0
+	for i, msg := range compacted {
+		if msg.Role != providers.RoleTool || len(msg.Content) < minChars {
+			continue
+		}
+		content, err := compressor.Compress(ctx, query, msg.Content)
+		if err != nil {
+			return nil, fmt.Errorf("agentkit: compress tool result: %w", err)
+		}
+		if len(content) < len(msg.Content) {
+			saved += len(msg.Content) - len(content)
+			compacted[i].Content = content
+		}
+	}
+	if saved > 0 {
+		return compacted, nil
+	}
+
+	next := c.Next
+	if next == nil {
+		next = TruncateOldest{}
+	}
+	return next.Compact(ctx, history)
+}
+
+// splitHistory splits history into the first user message, the messages to
+// drop and the most recent keepLast messages. The kept tail never starts
+// with tool results, whose tool calls would be dropped.
+func splitHistory(history []providers.Message, keepLast int) (head, dropped, tail []providers.Message, err error) {
+	if len(history) > 0 && history[0].Role == providers.RoleUser {
+		head, history = history[:1], history[1:]
+	}
+	if keepLast <= 0 {
+		keepLast = len(history) / 2
+	}
+	cut := max(len(history)-keepLast, 0)
+	for cut < len(history) && history[cut].Role == providers.RoleTool {
+		cut++
+	}
+	if cut == 0 {
+		return nil, nil, nil, ErrNothingToCompact
+	}
+	return head, history[:cut], history[cut:], nil
+}
+
+// compactHistory compacts history after a context_length_exceeded error and
+// emits a context.compacted event.
+func (a *Agent) compactHistory(ctx context.Context, history []providers.Message, events chan<- Event) ([]providers.Message, error) {
+	compacted, err := a.contextManager.Compact(ctx, history)
+	if err != nil {
+		return nil, err
+	}
+	a.logger.Warn("context length exceeded, compacted history",
+		"messages_before", len(history),
+		"messages_after", len(compacted))
+	a.emit(ctx, events, ContextCompacted("context_length_exceeded", len(history), len(compacted)))
+	return compacted, nil
+}
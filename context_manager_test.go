@@ -0,0 +1,171 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+	"github.com/darkostanimirovic/agentkit/retrieval"
+)
+
+// contextLimitProvider rejects requests with more than limit messages.
+type contextLimitProvider struct {
+	*mockprovider.Provider
+	limit int
+
+	mu       sync.Mutex
+	requests [][]providers.Message
+}
+
+func (p *contextLimitProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	p.mu.Lock()
+	p.requests = append(p.requests, req.Messages)
+	p.mu.Unlock()
+	if len(req.Messages) > p.limit {
+		return nil, fmt.Errorf("%w: %d messages", providers.ErrContextLengthExceeded, len(req.Messages))
+	}
+	return p.Provider.Complete(ctx, req)
+}
+
+func TestContextLengthExceeded_CompactsAndRetries(t *testing.T) {
+	provider := &contextLimitProvider{
+		Provider: mockprovider.New().
+			WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "echo", Arguments: map[string]any{}}}).
+			WithResponse("done", nil),
+		limit: 2,
+	}
+	agent, err := New(
+		WithProvider(provider),
+		WithModel("test-model"),
+		WithStreamResponses(false),
+		WithTool(echoTool("echo")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var compacted, errorsSeen []Event
+	var output string
+	for _, e := range collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second) {
+		switch e.Type {
+		case EventTypeContextCompacted:
+			compacted = append(compacted, e)
+		case EventTypeError:
+			errorsSeen = append(errorsSeen, e)
+		case EventTypeFinalOutput:
+			output, _ = e.Data["response"].(string)
+		}
+	}
+
+	if len(errorsSeen) != 0 {
+		t.Fatalf("error events = %+v", errorsSeen)
+	}
+	if len(compacted) != 1 || compacted[0].Data["messages_before"] != 3 || compacted[0].Data["messages_after"] != 2 {
+		t.Fatalf("context.compacted events = %+v", compacted)
+	}
+	if output != "done" {
+		t.Errorf("output = %q", output)
+	}
+	retried := provider.requests[len(provider.requests)-1]
+	if retried[0].Content != "hi" || !strings.Contains(retried[1].Content, "removed") {
+		t.Errorf("retried history = %+v", retried)
+	}
+}
+
+func TestContextLengthExceeded_FailsWhenNothingToCompact(t *testing.T) {
+	provider := &contextLimitProvider{Provider: mockprovider.New().WithResponse("done", nil), limit: 0}
+	agent, err := New(WithProvider(provider), WithModel("test-model"), WithStreamResponses(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errorsSeen []Event
+	for _, e := range collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second) {
+		if e.Type == EventTypeError {
+			errorsSeen = append(errorsSeen, e)
+		}
+	}
+	if len(errorsSeen) != 1 {
+		t.Fatalf("error events = %+v", errorsSeen)
+	}
+	if len(provider.requests) != 1 {
+		t.Errorf("provider calls = %d, want 1", len(provider.requests))
+	}
+}
+
+func TestTruncateOldest_KeepsToolCallPairs(t *testing.T) {
+	history := []providers.Message{
+		{Role: providers.RoleUser, Content: "task"},
+		{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "1", Name: "a"}}},
+		{Role: providers.RoleTool, ToolCallID: "1", Content: "r1"},
+		{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "2", Name: "b"}}},
+		{Role: providers.RoleTool, ToolCallID: "2", Content: "r2"},
+	}
+	got, err := TruncateOldest{KeepLast: 1}.Compact(context.Background(), history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keeping only the last tool result would orphan it, so it is dropped too.
+	if len(got) != 2 || got[0].Content != "task" || got[1].Role != providers.RoleUser {
+		t.Fatalf("compacted = %+v", got)
+	}
+
+	got, err = TruncateOldest{KeepLast: 2}.Compact(context.Background(), history)
+	if err != nil || len(got) != 4 || got[2].ToolCalls[0].ID != "2" {
+		t.Fatalf("compacted = %+v, %v", got, err)
+	}
+
+	if _, err := (TruncateOldest{}).Compact(context.Background(), history[:1]); !errors.Is(err, ErrNothingToCompact) {
+		t.Fatalf("Compact(task only) error = %v", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	summarizer := mockprovider.New().WithResponse("user asked about a; tool returned r1", nil)
+	history := []providers.Message{
+		{Role: providers.RoleUser, Content: "task"},
+		{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "1", Name: "a"}}},
+		{Role: providers.RoleTool, ToolCallID: "1", Content: "r1"},
+		{Role: providers.RoleAssistant, Content: "next"},
+	}
+	got, err := Summarize{Provider: summarizer, KeepLast: 1}.Compact(context.Background(), history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !strings.Contains(got[1].Content, "tool returned r1") || got[2].Content != "next" {
+		t.Fatalf("compacted = %+v", got)
+	}
+}
+
+func TestCompressToolResults(t *testing.T) {
+	document := strings.Repeat("Unrelated filler about the weather. ", 40) + "The refund window is 30 days."
+	history := []providers.Message{
+		{Role: providers.RoleUser, Content: "what is the refund window?"},
+		{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "1", Name: "search"}}},
+		{Role: providers.RoleTool, ToolCallID: "1", Content: document},
+	}
+	manager := CompressToolResults{Compressor: retrieval.SentenceCompressor{Ratio: 0.1}}
+
+	got, err := manager.Compact(context.Background(), history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || !strings.Contains(got[2].Content, "refund window is 30 days") || len(got[2].Content) >= len(document) {
+		t.Fatalf("compacted = %+v", got)
+	}
+	if history[2].Content != document {
+		t.Error("Compact modified the input history")
+	}
+
+	// Nothing left to compress: fall back to truncation.
+	got, err = manager.Compact(context.Background(), got)
+	if err != nil || len(got) != 2 || !strings.Contains(got[1].Content, "earlier messages were removed") {
+		t.Fatalf("fallback compacted = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,123 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ContextPolicy keeps conversation history within a token budget. Before
+// each model call the agent estimates the history's tokens and, when they
+// exceed MaxTokens, compacts it with Strategy until it fits, emitting a
+// context.compacted event with reason "token_budget". The compacted history
+// replaces the original for the rest of the run, so Summarize and Hybrid
+// maintain a running summary.
+//
+// The budget covers the messages only; leave room for the system prompt,
+// tool definitions and the response.
+type ContextPolicy struct {
+	MaxTokens int            // History budget in estimated tokens; zero disables the policy
+	Strategy  ContextManager // TruncateOldest (default), Summarize or Hybrid; a missing Provider/Model uses the agent's
+	// Estimate counts a history's tokens (default EstimateTokens).
+	Estimate func(messages []providers.Message) int
+}
+
+// maxContextPolicyPasses bounds how often one call compacts history.
+const maxContextPolicyPasses = 4
+
+// EstimateTokens approximates the tokens of messages at four characters per
+// token (counting inline text files), plus a small per-message and
+// per-image overhead. It needs no tokenizer and errs on the high side for
+// English text.
+func EstimateTokens(messages []providers.Message) int {
+	chars := 0
+	tokens := 0
+	for _, msg := range messages {
+		tokens += 4
+		chars += len(msg.Content)
+		for _, call := range msg.ToolCalls {
+			chars += len(call.Name)
+			if args, err := json.Marshal(call.Arguments); err == nil {
+				chars += len(args)
+			}
+		}
+		tokens += 85 * len(msg.Images)
+		for _, file := range msg.Files {
+			if providers.IsTextMediaType(file.MediaType) {
+				chars += len(file.Data)
+			}
+		}
+	}
+	return tokens + (chars+3)/4
+}
+
+// withDefaults fills a summarizing strategy's missing provider and model
+// with the agent's.
+func (p ContextPolicy) withDefaults(provider providers.Provider, model string) *ContextPolicy {
+	switch strategy := p.Strategy.(type) {
+	case Summarize:
+		if strategy.Provider == nil {
+			strategy.Provider = provider
+		}
+		if strategy.Model == "" {
+			strategy.Model = model
+		}
+		p.Strategy = strategy
+	case Hybrid:
+		if strategy.Provider == nil {
+			strategy.Provider = provider
+		}
+		if strategy.Model == "" {
+			strategy.Model = model
+		}
+		p.Strategy = strategy
+	case nil:
+		p.Strategy = TruncateOldest{}
+	}
+	if p.Estimate == nil {
+		p.Estimate = EstimateTokens
+	}
+	return &p
+}
+
+// applyContextPolicy compacts history that exceeds the policy's budget and
+// reports whether it did. It returns history unchanged when there is no
+// policy, the history fits, or compaction fails.
+func (a *Agent) applyContextPolicy(ctx context.Context, history []providers.Message, events chan<- Event) ([]providers.Message, bool) {
+	policy := a.contextPolicy
+	if policy == nil || policy.MaxTokens <= 0 {
+		return history, false
+	}
+	before := policy.Estimate(history)
+	if before <= policy.MaxTokens {
+		return history, false
+	}
+
+	compacted, tokens := history, before
+	for range maxContextPolicyPasses {
+		next, err := policy.Strategy.Compact(ctx, compacted)
+		if err != nil {
+			a.logger.Warn("context policy compaction failed", "error", err)
+			break
+		}
+		nextTokens := policy.Estimate(next)
+		if nextTokens >= tokens {
+			break
+		}
+		compacted, tokens = next, nextTokens
+		if tokens <= policy.MaxTokens {
+			break
+		}
+	}
+	if tokens == before {
+		return history, false
+	}
+
+	a.logger.Debug("compacted history to fit the token budget",
+		"tokens_before", before,
+		"tokens_after", tokens,
+		"max_tokens", policy.MaxTokens)
+	a.emit(ctx, events, ContextCompactedWithTokens("token_budget", len(history), len(compacted), before, tokens))
+	return compacted, true
+}
@@ -0,0 +1,147 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// longHistory returns n alternating user/assistant messages of about 100
+// estimated tokens each, ending with a user message.
+func longHistory(n int) []providers.Message {
+	messages := make([]providers.Message, n)
+	for i := range messages {
+		role := providers.RoleUser
+		if (n-1-i)%2 == 1 {
+			role = providers.RoleAssistant
+		}
+		messages[i] = providers.Message{Role: role, Content: strings.Repeat("word ", 80)}
+	}
+	return messages
+}
+
+func TestContextPolicy_TruncatesBeforeCall(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("done", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		ContextPolicy:   &ContextPolicy{MaxTokens: 500},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := collectEvents(agent.RunMessages(context.Background(), longHistory(11)), time.Second)
+
+	sent := provider.requests[0].Messages
+	if got := EstimateTokens(sent); got > 500 {
+		t.Errorf("sent %d estimated tokens, want at most 500", got)
+	}
+	if sent[0].Content != longHistory(1)[0].Content || !strings.Contains(sent[1].Content, "earlier messages were removed") {
+		t.Errorf("sent messages = %+v, want the task and a removal notice first", sent[:2])
+	}
+	var compacted *Event
+	for i := range events {
+		if events[i].Type == EventTypeContextCompacted {
+			compacted = &events[i]
+		}
+	}
+	if compacted == nil || compacted.Data["reason"] != "token_budget" || compacted.Data["tokens_after"].(int) > 500 {
+		t.Errorf("context.compacted event = %+v", compacted)
+	}
+}
+
+func TestContextPolicy_SummarizesWithAgentModel(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("The user asked about invoices.", nil).
+		WithResponse("done", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		ContextPolicy:   &ContextPolicy{MaxTokens: 800, Strategy: Summarize{KeepLast: 3}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.RunMessages(context.Background(), longHistory(11)), time.Second)
+
+	if len(provider.requests) != 2 || provider.requests[0].Model != "test-model" {
+		t.Fatalf("requests = %+v, want a summary call on the agent's model first", provider.requests)
+	}
+	sent := provider.requests[1].Messages
+	if len(sent) != 5 || sent[1].Content != "Summary of the earlier conversation:\nThe user asked about invoices." {
+		t.Errorf("sent messages = %+v", sent)
+	}
+}
+
+// shortenMessages keeps every message but cuts its content to limit bytes.
+type shortenMessages struct{ limit int }
+
+func (s shortenMessages) Compact(ctx context.Context, history []providers.Message) ([]providers.Message, error) {
+	compacted := make([]providers.Message, len(history))
+	for i, msg := range history {
+		msg.Content = msg.Content[:min(len(msg.Content), s.limit)]
+		compacted[i] = msg
+	}
+	return compacted, nil
+}
+
+func TestContextPolicy_UsesCompactionThatKeepsMessageCount(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("done", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		ContextPolicy:   &ContextPolicy{MaxTokens: 100, Strategy: shortenMessages{limit: 20}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.RunMessages(context.Background(), longHistory(3)), time.Second)
+
+	sent := provider.requests[0].Messages
+	if len(sent) != 3 || EstimateTokens(sent) > 100 {
+		t.Errorf("sent %d messages of %d estimated tokens, want the 3 compacted messages", len(sent), EstimateTokens(sent))
+	}
+}
+
+func TestContextPolicy_UnderBudgetUnchanged(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("done", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		ContextPolicy:   &ContextPolicy{MaxTokens: 10_000},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	collectEvents(agent.RunMessages(context.Background(), longHistory(5)), time.Second)
+	if len(provider.requests[0].Messages) != 5 {
+		t.Errorf("sent %d messages, want all 5", len(provider.requests[0].Messages))
+	}
+}
+
+func TestHybrid_FallsBackToTruncation(t *testing.T) {
+	// The provider has no responses, so summarizing fails.
+	compacted, err := Hybrid{Provider: mockprovider.New(), Model: "test-model", KeepLast: 2}.Compact(context.Background(), longHistory(7))
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if len(compacted) != 4 || !strings.Contains(compacted[1].Content, "4 earlier messages were removed") {
+		t.Errorf("compacted = %+v", compacted)
+	}
+
+	if _, err := (Hybrid{Provider: mockprovider.New()}).Compact(context.Background(), longHistory(1)); !errors.Is(err, ErrNothingToCompact) {
+		t.Errorf("error = %v, want ErrNothingToCompact", err)
+	}
+}
@@ -0,0 +1,132 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// RunWithConversation runs the agent with the stored history of
+// conversationID. Prior turns are sent before userMessage; the user turn is
+// appended to the store before the run starts and the final answer after it
+// completes. A missing conversation is created. Runs that end without output
+// (errors, cancellation) store only the user turn.
+//
+// The conversation ID is also set on the context (see WithConversation), so
+// cost tracking, flags and session memory are scoped to it.
+func (a *Agent) RunWithConversation(ctx context.Context, conversationID, userMessage string, opts ...RunOption) <-chan Event {
+	return a.runWithConversation(ctx, conversationID, userMessage, newRunOptions(opts))
+}
+
+func (a *Agent) runWithConversation(ctx context.Context, conversationID, userMessage string, options *runOptions) <-chan Event {
+	ctx = WithConversation(ctx, conversationID)
+	conv, err := a.startConversationTurn(ctx, conversationID, userMessage)
+	if err != nil {
+		events := make(chan Event, 1)
+		events <- Error(err)
+		close(events)
+		return events
+	}
+
+	messages := append(conversationMessages(conv.Turns), providers.Message{Role: providers.RoleUser, Content: userMessage})
+	var chain *responseChain
+	if a.responseChaining != nil {
+		chain = loadResponseChain(conv)
+		ctx = withResponseChain(ctx, chain)
+	}
+	out := make(chan Event, a.eventBuffer)
+	forward := a.forwarder(ctx)
+	go func() {
+		defer close(out)
+		var output string
+		for event := range a.runMessages(ctx, messages, options) {
+			if event.Type == EventTypeFinalOutput {
+				output, _ = event.Data["response"].(string)
+			}
+			forward.send(out, event)
+		}
+		if output == "" {
+			return
+		}
+		storeCtx := context.WithoutCancel(ctx)
+		turn := ConversationTurn{Role: string(providers.RoleAssistant), Content: output, Timestamp: time.Now()}
+		if err := a.conversationStore.Append(storeCtx, conversationID, turn); err != nil {
+			forward.send(out, Error(fmt.Errorf("agentkit: store assistant turn of %s: %w", conversationID, err)))
+			return
+		}
+		if chain != nil {
+			if err := a.saveResponseChain(storeCtx, conversationID, chain); err != nil {
+				a.logger.Warn("failed to store response chain", "conversation_id", conversationID, "error", err)
+			}
+		}
+	}()
+	return out
+}
+
+// startConversationTurn loads conversationID, creating it when it does not
+// exist, and appends the user turn. The returned conversation holds the
+// turns before it.
+func (a *Agent) startConversationTurn(ctx context.Context, conversationID, userMessage string) (Conversation, error) {
+	if a.conversationStore == nil {
+		return Conversation{}, errors.New("agentkit: conversation store not configured")
+	}
+	conv, err := a.conversationStore.Load(ctx, conversationID)
+	if errors.Is(err, ErrConversationNotFound) {
+		conv = Conversation{ID: conversationID, AgentID: a.agentName, Metadata: map[string]any{}}
+		err = a.conversationStore.Save(ctx, conv)
+	}
+	if err != nil {
+		return Conversation{}, fmt.Errorf("agentkit: load conversation %s: %w", conversationID, err)
+	}
+
+	turn := ConversationTurn{Role: string(providers.RoleUser), Content: userMessage, Timestamp: time.Now()}
+	if err := a.conversationStore.Append(ctx, conversationID, turn); err != nil {
+		return Conversation{}, fmt.Errorf("agentkit: store user turn of %s: %w", conversationID, err)
+	}
+	return conv, nil
+}
+
+// conversationMessages converts stored turns into provider messages. Tool
+// turns become one tool message per result.
+func conversationMessages(turns []ConversationTurn) []providers.Message {
+	messages := make([]providers.Message, 0, len(turns))
+	for _, turn := range turns {
+		switch providers.MessageRole(turn.Role) {
+		case providers.RoleTool:
+			for _, result := range turn.ToolResults {
+				messages = append(messages, providers.Message{
+					Role:       providers.RoleTool,
+					ToolCallID: result.CallID,
+					Content:    toolResultContent(result),
+				})
+			}
+		case providers.RoleAssistant:
+			msg := providers.Message{Role: providers.RoleAssistant, Content: turn.Content}
+			for _, call := range turn.ToolCalls {
+				msg.ToolCalls = append(msg.ToolCalls, providers.ToolCall{ID: call.ID, Name: call.Name, Arguments: call.Arguments})
+			}
+			messages = append(messages, msg)
+		default:
+			messages = append(messages, providers.Message{Role: providers.MessageRole(turn.Role), Content: turn.Content})
+		}
+	}
+	return messages
+}
+
+func toolResultContent(result ConversationToolResult) string {
+	if result.Error != "" {
+		return "Error: " + result.Error
+	}
+	if s, ok := result.Result.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(result.Result)
+	if err != nil {
+		return fmt.Sprint(result.Result)
+	}
+	return string(data)
+}
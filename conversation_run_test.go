@@ -0,0 +1,63 @@
+package agentkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestRunWithConversation_InjectsAndStoresHistory(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("Hi Ana.", nil).
+		WithResponse("Your name is Ana.", nil)}
+	store := NewMemoryConversationStore()
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false, ConversationStore: store})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.RunWithConversation(context.Background(), "conv-1", "I'm Ana."), time.Second)
+	collectEvents(agent.RunWithConversation(context.Background(), "conv-1", "What's my name?"), time.Second)
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(provider.requests))
+	}
+	sent := provider.requests[1].Messages
+	if len(sent) != 3 || sent[0].Content != "I'm Ana." || sent[1].Role != providers.RoleAssistant || sent[1].Content != "Hi Ana." || sent[2].Content != "What's my name?" {
+		t.Errorf("second run messages = %+v", sent)
+	}
+
+	conv, err := store.Load(context.Background(), "conv-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(conv.Turns) != 4 || conv.Turns[3].Role != "assistant" || conv.Turns[3].Content != "Your name is Ana." {
+		t.Errorf("stored turns = %+v", conv.Turns)
+	}
+}
+
+func TestRunWithConversation_ConvertsToolTurns(t *testing.T) {
+	turns := []ConversationTurn{
+		{Role: "user", Content: "weather?"},
+		{Role: "assistant", ToolCalls: []ConversationToolCall{{ID: "c1", Name: "weather", Arguments: map[string]any{"city": "Oslo"}}}},
+		{Role: "tool", ToolResults: []ConversationToolResult{{CallID: "c1", Result: map[string]any{"temp": 3}}}},
+	}
+	messages := conversationMessages(turns)
+	if len(messages) != 3 || messages[1].ToolCalls[0].Name != "weather" || messages[2].ToolCallID != "c1" || messages[2].Content != `{"temp":3}` {
+		t.Errorf("messages = %+v", messages)
+	}
+}
+
+func TestRunWithConversation_NoStore(t *testing.T) {
+	agent, err := New(Config{Provider: mockprovider.New(), Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	events := collectEvents(agent.RunWithConversation(context.Background(), "conv-1", "hi"), time.Second)
+	if len(events) != 1 || events[0].Type != EventTypeError {
+		t.Fatalf("events = %+v, want one error", events)
+	}
+}
@@ -0,0 +1,160 @@
+package agentkit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// CostUpdate describes the token usage and estimated cost after a single generation,
+// along with running totals for the current run and conversation.
+type CostUpdate struct {
+	Model string
+
+	// Usage and Cost describe the generation that just completed.
+	// Cost is nil when pricing for the model is unknown.
+	Usage providers.TokenUsage
+	Cost  *CostInfo
+
+	// RunUsage and RunCost are cumulative for the current Run call.
+	RunUsage providers.TokenUsage
+	RunCost  float64
+
+	// ConversationID is set when the run is associated with a conversation
+	// (see WithConversation). ConversationUsage and ConversationCost are cumulative
+	// across all runs of this agent within that conversation. Totals are
+	// forgotten when the conversation is deleted through the agent or has
+	// been idle for a day, and then start again from zero.
+	ConversationID    string
+	ConversationUsage providers.TokenUsage
+	ConversationCost  float64
+}
+
+// The cost meter keeps the totals of at most maxMeteredConversations
+// conversations, forgetting those idle for meteredConversationTTL and then
+// the least recently updated, so long-running servers don't grow without
+// bound.
+var (
+	maxMeteredConversations = 10000
+	meteredConversationTTL  = 24 * time.Hour
+)
+
+// costMeter accumulates per-conversation usage for cost.update events.
+// recent orders the totals from the most to the least recently updated, so
+// eviction only looks at its back.
+type costMeter struct {
+	mu            sync.Mutex
+	conversations map[string]*list.Element // Of *conversationCost
+	recent        *list.List
+}
+
+type conversationCost struct {
+	id      string
+	usage   providers.TokenUsage
+	cost    float64
+	updated time.Time
+}
+
+func newCostMeter() *costMeter {
+	return &costMeter{conversations: make(map[string]*list.Element), recent: list.New()}
+}
+
+// add records usage for a conversation and returns the updated totals.
+func (m *costMeter) add(conversationID string, usage providers.TokenUsage, cost float64) (providers.TokenUsage, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	elem, ok := m.conversations[conversationID]
+	if ok {
+		m.recent.MoveToFront(elem)
+	} else {
+		m.evict(now)
+		elem = m.recent.PushFront(&conversationCost{id: conversationID})
+		m.conversations[conversationID] = elem
+	}
+	totals := elem.Value.(*conversationCost)
+	totals.usage = addUsage(totals.usage, usage)
+	totals.cost += cost
+	totals.updated = now
+	return totals.usage, totals.cost
+}
+
+// forget drops the totals of a conversation.
+func (m *costMeter) forget(conversationID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.conversations[conversationID]; ok {
+		m.recent.Remove(elem)
+		delete(m.conversations, conversationID)
+	}
+}
+
+// evict forgets idle conversations and makes room for one more. Callers
+// hold m.mu.
+func (m *costMeter) evict(now time.Time) {
+	for elem := m.recent.Back(); elem != nil; elem = m.recent.Back() {
+		totals := elem.Value.(*conversationCost)
+		if m.recent.Len() < maxMeteredConversations && now.Sub(totals.updated) <= meteredConversationTTL {
+			return
+		}
+		m.recent.Remove(elem)
+		delete(m.conversations, totals.id)
+	}
+}
+
+func addUsage(a, b providers.TokenUsage) providers.TokenUsage {
+	return providers.TokenUsage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		ReasoningTokens:  a.ReasoningTokens + b.ReasoningTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+}
+
+// recordUsage adds a generation's usage to the run and trace totals and
+// emits a cost.update event, pricing it at model.
+func (a *Agent) recordUsage(ctx context.Context, events chan<- Event, model string, usage providers.TokenUsage, outcome *runOutcome) {
+	outcome.usage = addUsage(outcome.usage, usage)
+	addTraceUsage(ctx, model, usage)
+	a.emitCostUpdate(ctx, events, model, usage, outcome)
+}
+
+// emitCostUpdate emits a cost.update event for the generation that just completed.
+// Generations that report no token usage are skipped.
+func (a *Agent) emitCostUpdate(ctx context.Context, events chan<- Event, model string, usage providers.TokenUsage, outcome *runOutcome) {
+	if usage.PromptTokens == 0 && usage.CompletionTokens == 0 && usage.TotalTokens == 0 {
+		return
+	}
+
+	update := CostUpdate{
+		Model:    model,
+		Usage:    usage,
+		Cost:     CalculateCost(model, usage.PromptTokens, usage.CompletionTokens),
+		RunUsage: outcome.usage,
+	}
+	if update.Cost != nil {
+		outcome.cost += update.Cost.TotalCost
+	}
+	update.RunCost = outcome.cost
+
+	if conversationID, ok := GetConversationID(ctx); ok && conversationID != "" && a.costMeter != nil {
+		var cost float64
+		if update.Cost != nil {
+			cost = update.Cost.TotalCost
+		}
+		update.ConversationID = conversationID
+		update.ConversationUsage, update.ConversationCost = a.costMeter.add(conversationID, usage, cost)
+	}
+
+	a.emit(ctx, events, CostUpdated(update))
+
+	var cost float64
+	if update.Cost != nil {
+		cost = update.Cost.TotalCost
+	}
+	a.observeCost(ctx, events, cost, max(usage.TotalTokens, usage.PromptTokens+usage.CompletionTokens))
+}
@@ -0,0 +1,71 @@
+package agentkit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestCostMeter_EvictsIdleAndOldestConversations(t *testing.T) {
+	defer func(max int, ttl time.Duration) {
+		maxMeteredConversations, meteredConversationTTL = max, ttl
+	}(maxMeteredConversations, meteredConversationTTL)
+	maxMeteredConversations = 3
+	usage := providers.TokenUsage{TotalTokens: 10}
+
+	meter := newCostMeter()
+	for i := range 5 {
+		meter.add(fmt.Sprintf("conv-%d", i), usage, 0.01)
+	}
+	if len(meter.conversations) != 3 {
+		t.Fatalf("kept %d conversations, want 3", len(meter.conversations))
+	}
+	if _, ok := meter.conversations["conv-0"]; ok {
+		t.Error("the least recently updated conversation was kept")
+	}
+	if tokens, _ := meter.add("conv-2", usage, 0.01); tokens.TotalTokens != 20 {
+		t.Errorf("conv-2 tokens = %d, want 20", tokens.TotalTokens)
+	}
+	meter.add("conv-5", usage, 0.01)
+	if _, ok := meter.conversations["conv-2"]; !ok {
+		t.Error("a recently updated conversation was evicted")
+	}
+	if _, ok := meter.conversations["conv-3"]; ok {
+		t.Error("the least recently updated conversation was kept")
+	}
+
+	meteredConversationTTL = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+	meter.add("conv-6", usage, 0.01)
+	if len(meter.conversations) != 1 {
+		t.Errorf("kept %d conversations after they went idle, want 1", len(meter.conversations))
+	}
+}
+
+func TestDeleteAndClearConversation_ForgetCostTotals(t *testing.T) {
+	agent, err := New(Config{Provider: mockprovider.New(), Model: "test-model", ConversationStore: NewMemoryConversationStore()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	for name, reset := range map[string]func(context.Context, string) error{
+		"DeleteConversation": agent.DeleteConversation,
+		"ClearConversation":  agent.ClearConversation,
+	} {
+		agent.costMeter.add("conv-1", providers.TokenUsage{TotalTokens: 10}, 0.01)
+		if err := agent.SaveConversation(ctx, Conversation{ID: "conv-1"}); err != nil {
+			t.Fatalf("SaveConversation() error = %v", err)
+		}
+		if err := reset(ctx, "conv-1"); err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		if _, ok := agent.costMeter.conversations["conv-1"]; ok || agent.costMeter.recent.Len() != 0 {
+			t.Errorf("%s kept the cost totals", name)
+		}
+	}
+}
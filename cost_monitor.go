@@ -0,0 +1,412 @@
+package agentkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// CostScope names what a CostAlert measured.
+type CostScope string
+
+const (
+	CostScopeRun          CostScope = "run"
+	CostScopeConversation CostScope = "conversation"
+	CostScopeAgent        CostScope = "agent" // Spend over CostMonitorConfig.BudgetWindow
+)
+
+// Defaults for CostMonitorConfig.
+const (
+	DefaultCostRatio        = 10.0
+	DefaultCostMinSamples   = 10
+	DefaultCostWindow       = 200
+	DefaultCostBudgetWindow = time.Hour
+)
+
+// CostMonitorConfig configures a CostMonitor. Ratio alerts compare a run or
+// conversation with the median of the agent's recent ones; limit alerts fire
+// at fixed amounts in USD.
+type CostMonitorConfig struct {
+	// Ratio alerts when a run or conversation spends this many times the
+	// median (default DefaultCostRatio).
+	Ratio float64
+	// MinSamples is how many completed runs, or other conversations, an
+	// agent needs before ratio alerts start (default DefaultCostMinSamples).
+	MinSamples int
+	// Window is how many recent runs and conversations are remembered per
+	// agent (default DefaultCostWindow).
+	Window int
+
+	MaxRunCost          float64 // Alert when a run spends more than this (0: no limit)
+	MaxConversationCost float64 // Alert when a conversation spends more than this (0: no limit)
+	// AgentBudget alerts when an agent spends more than this within
+	// BudgetWindow (default DefaultCostBudgetWindow). The alert re-arms once
+	// spend in the window falls back below the budget.
+	AgentBudget  float64
+	BudgetWindow time.Duration
+
+	// OnAlert is called for each alert, in addition to the cost.anomaly
+	// event emitted to the run.
+	OnAlert func(alert CostAlert)
+	// WebhookURL receives each alert as a JSON POST, sent in the background.
+	WebhookURL string
+	HTTPClient *http.Client // Client for WebhookURL (default: providers.DefaultHTTPClient)
+	// OnError is called when a webhook delivery fails.
+	OnError func(err error)
+}
+
+// CostAlert reports spend beyond a CostMonitor threshold.
+type CostAlert struct {
+	Agent  string    `json:"agent"`
+	Scope  CostScope `json:"scope"`
+	ID     string    `json:"id,omitempty"` // Run or conversation ID; empty for CostScopeAgent
+	Cost   float64   `json:"cost"`         // USD spent by the run, conversation or agent
+	Tokens int       `json:"tokens"`
+	// Median and Ratio are set by ratio alerts. They compare USD, or tokens
+	// (Unit "tokens") when the model's pricing is unknown.
+	Median float64 `json:"median,omitempty"`
+	Ratio  float64 `json:"ratio,omitempty"`
+	Unit   string  `json:"unit,omitempty"`
+	// Limit is the USD limit crossed, set by limit alerts.
+	Limit float64 `json:"limit,omitempty"`
+}
+
+// CostSample is the spend of one generation, as observed by CostMonitor.
+type CostSample struct {
+	Agent          string
+	RunID          string
+	ConversationID string
+	Cost           float64 // USD; 0 when the model's pricing is unknown
+	Tokens         int
+	Time           time.Time // Default now
+}
+
+// CostMonitor tracks rolling spend per agent, run and conversation, and
+// alerts when a run or conversation spends far more than usual or crosses a
+// limit, catching runaway tool loops while they run. Each run and
+// conversation alerts at most once per kind. Share one monitor between
+// agents; it keeps separate statistics per agent name. It is safe for
+// concurrent use.
+type CostMonitor struct {
+	cfg     CostMonitorConfig
+	mu      sync.Mutex
+	agents  map[string]*agentSpend
+	pending sync.WaitGroup // Webhook deliveries
+}
+
+// spend is the running total of a run or conversation.
+type spend struct {
+	cost    float64
+	tokens  int
+	updated time.Time
+	alerted map[string]bool // Alerts fired, by kind
+}
+
+func (s *spend) add(sample CostSample) {
+	s.cost += sample.Cost
+	s.tokens += sample.Tokens
+	s.updated = sample.Time
+}
+
+// value is what ratio alerts compare: cost when priced, else tokens.
+func (s spend) value(unit string) float64 {
+	if unit == "tokens" {
+		return float64(s.tokens)
+	}
+	return s.cost
+}
+
+// once reports whether the alert kind hasn't fired for s yet, marking it.
+func (s *spend) once(kind string) bool {
+	if s.alerted[kind] {
+		return false
+	}
+	if s.alerted == nil {
+		s.alerted = map[string]bool{}
+	}
+	s.alerted[kind] = true
+	return true
+}
+
+type timedSpend struct {
+	at     time.Time
+	cost   float64
+	tokens int
+}
+
+type agentSpend struct {
+	runs          map[string]*spend // In progress
+	finished      []spend           // Last Window completed runs
+	conversations map[string]*spend
+	recent        []timedSpend // Within BudgetWindow
+	overBudget    bool
+}
+
+// NewCostMonitor creates a cost monitor.
+func NewCostMonitor(cfg CostMonitorConfig) *CostMonitor {
+	if cfg.Ratio <= 0 {
+		cfg.Ratio = DefaultCostRatio
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = DefaultCostMinSamples
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultCostWindow
+	}
+	if cfg.BudgetWindow <= 0 {
+		cfg.BudgetWindow = DefaultCostBudgetWindow
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = providers.DefaultHTTPClient()
+	}
+	return &CostMonitor{cfg: cfg, agents: make(map[string]*agentSpend)}
+}
+
+// Observe records a generation's spend and returns the alerts it triggers.
+func (m *CostMonitor) Observe(sample CostSample) []CostAlert {
+	if sample.Time.IsZero() {
+		sample.Time = time.Now()
+	}
+
+	m.mu.Lock()
+	agent := m.agent(sample.Agent)
+	var alerts []CostAlert
+	if sample.RunID != "" {
+		run := agent.runs[sample.RunID]
+		if run == nil {
+			run = &spend{}
+			agent.runs[sample.RunID] = run
+		}
+		run.add(sample)
+		alerts = m.check(alerts, sample.Agent, CostScopeRun, sample.RunID, run, agent.finished, m.cfg.MaxRunCost)
+	}
+	if sample.ConversationID != "" {
+		conversation := agent.conversations[sample.ConversationID]
+		if conversation == nil {
+			conversation = &spend{}
+			agent.conversations[sample.ConversationID] = conversation
+			m.evictConversations(agent)
+		}
+		conversation.add(sample)
+		peers := make([]spend, 0, len(agent.conversations))
+		for id, other := range agent.conversations {
+			if id != sample.ConversationID {
+				peers = append(peers, *other)
+			}
+		}
+		alerts = m.check(alerts, sample.Agent, CostScopeConversation, sample.ConversationID, conversation, peers, m.cfg.MaxConversationCost)
+	}
+	if alert, ok := m.checkBudget(sample, agent); ok {
+		alerts = append(alerts, alert)
+	}
+	m.mu.Unlock()
+
+	m.deliver(alerts)
+	return alerts
+}
+
+// EndRun marks a run finished, adding its total to the agent's baseline.
+func (m *CostMonitor) EndRun(agent, runID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	spending := m.agent(agent)
+	run, ok := spending.runs[runID]
+	if !ok {
+		return
+	}
+	delete(spending.runs, runID)
+	spending.finished = append(spending.finished, *run)
+	if extra := len(spending.finished) - m.cfg.Window; extra > 0 {
+		spending.finished = slices.Delete(spending.finished, 0, extra)
+	}
+}
+
+// Close waits for webhook deliveries in flight, or until ctx is done.
+func (m *CostMonitor) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// agent returns the statistics of the named agent. Callers hold m.mu.
+func (m *CostMonitor) agent(name string) *agentSpend {
+	agent, ok := m.agents[name]
+	if !ok {
+		agent = &agentSpend{runs: map[string]*spend{}, conversations: map[string]*spend{}}
+		m.agents[name] = agent
+	}
+	return agent
+}
+
+// evictConversations forgets the least recently updated conversations
+// beyond Window. Callers hold m.mu.
+func (m *CostMonitor) evictConversations(agent *agentSpend) {
+	for len(agent.conversations) > m.cfg.Window {
+		var oldest string
+		for id, conversation := range agent.conversations {
+			if oldest == "" || conversation.updated.Before(agent.conversations[oldest].updated) {
+				oldest = id
+			}
+		}
+		delete(agent.conversations, oldest)
+	}
+}
+
+// check appends the ratio and limit alerts current triggers against its
+// peers. Callers hold m.mu.
+func (m *CostMonitor) check(alerts []CostAlert, agent string, scope CostScope, id string, current *spend, peers []spend, limit float64) []CostAlert {
+	alert := CostAlert{Agent: agent, Scope: scope, ID: id, Cost: current.cost, Tokens: current.tokens}
+	if limit > 0 && current.cost > limit && current.once("limit") {
+		limitAlert := alert
+		limitAlert.Limit = limit
+		alerts = append(alerts, limitAlert)
+	}
+	if len(peers) < m.cfg.MinSamples {
+		return alerts
+	}
+	unit := "usd"
+	if current.cost == 0 || median(peers, unit) == 0 {
+		unit = "tokens"
+	}
+	baseline := median(peers, unit)
+	if baseline <= 0 || current.value(unit) <= m.cfg.Ratio*baseline || !current.once("ratio") {
+		return alerts
+	}
+	alert.Median = baseline
+	alert.Ratio = current.value(unit) / baseline
+	alert.Unit = unit
+	return append(alerts, alert)
+}
+
+func median(samples []spend, unit string) float64 {
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.value(unit)
+	}
+	slices.Sort(values)
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// checkBudget adds sample to the agent's rolling window and reports an
+// alert when it crosses AgentBudget. Callers hold m.mu.
+func (m *CostMonitor) checkBudget(sample CostSample, agent *agentSpend) (CostAlert, bool) {
+	if m.cfg.AgentBudget <= 0 {
+		return CostAlert{}, false
+	}
+	agent.recent = append(agent.recent, timedSpend{at: sample.Time, cost: sample.Cost, tokens: sample.Tokens})
+	cutoff := sample.Time.Add(-m.cfg.BudgetWindow)
+	expired := 0
+	for expired < len(agent.recent) && agent.recent[expired].at.Before(cutoff) {
+		expired++
+	}
+	agent.recent = slices.Delete(agent.recent, 0, expired)
+
+	alert := CostAlert{Agent: sample.Agent, Scope: CostScopeAgent, Limit: m.cfg.AgentBudget}
+	for _, s := range agent.recent {
+		alert.Cost += s.cost
+		alert.Tokens += s.tokens
+	}
+	if alert.Cost <= m.cfg.AgentBudget {
+		agent.overBudget = false
+		return CostAlert{}, false
+	}
+	if agent.overBudget {
+		return CostAlert{}, false
+	}
+	agent.overBudget = true
+	return alert, true
+}
+
+// deliver hands alerts to OnAlert and the webhook.
+func (m *CostMonitor) deliver(alerts []CostAlert) {
+	for _, alert := range alerts {
+		if m.cfg.OnAlert != nil {
+			m.cfg.OnAlert(alert)
+		}
+		if m.cfg.WebhookURL != "" {
+			m.pending.Add(1)
+			go func() {
+				defer m.pending.Done()
+				if err := m.post(alert); err != nil && m.cfg.OnError != nil {
+					m.cfg.OnError(err)
+				}
+			}()
+		}
+	}
+}
+
+func (m *CostMonitor) post(alert CostAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("agentkit: cost alert webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("agentkit: cost alert webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+// observeCost routes a generation's spend to the agent's cost monitor,
+// emitting cost.anomaly events for new alerts.
+func (a *Agent) observeCost(ctx context.Context, events chan<- Event, cost float64, tokens int) {
+	if a.costMonitor == nil {
+		return
+	}
+	sample := CostSample{Agent: a.agentName, Cost: cost, Tokens: tokens}
+	if run, ok := ctx.Value(activeRunKey).(*activeRun); ok {
+		sample.RunID = strconv.FormatUint(run.id, 10)
+	}
+	sample.ConversationID, _ = GetConversationID(ctx)
+	for _, alert := range a.costMonitor.Observe(sample) {
+		a.logger.Warn("cost anomaly",
+			"scope", alert.Scope,
+			"id", alert.ID,
+			"cost", alert.Cost,
+			"tokens", alert.Tokens,
+			"ratio", alert.Ratio,
+			"limit", alert.Limit)
+		a.emit(ctx, events, CostAnomaly(alert))
+	}
+}
+
+// endCostRun adds a finished run to the cost monitor's baseline.
+func (a *Agent) endCostRun(run *activeRun) {
+	if a.costMonitor != nil {
+		a.costMonitor.EndRun(a.agentName, strconv.FormatUint(run.id, 10))
+	}
+}
@@ -0,0 +1,145 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestCostMonitor_RunRatio(t *testing.T) {
+	monitor := NewCostMonitor(CostMonitorConfig{MinSamples: 3})
+	for i, run := range []string{"a", "b", "c"} {
+		if alerts := monitor.Observe(CostSample{Agent: "triage", RunID: run, Cost: 0.01 * float64(i+1)}); len(alerts) != 0 {
+			t.Fatalf("unexpected alerts while building the baseline: %+v", alerts)
+		}
+		monitor.EndRun("triage", run)
+	}
+
+	var alerts []CostAlert
+	for range 25 { // A runaway loop: 25 generations at the median cost
+		alerts = append(alerts, monitor.Observe(CostSample{Agent: "triage", RunID: "d", Cost: 0.02})...)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected one alert, got %+v", alerts)
+	}
+	alert := alerts[0]
+	if alert.Scope != CostScopeRun || alert.ID != "d" || alert.Median != 0.02 || alert.Unit != "usd" || alert.Ratio <= DefaultCostRatio {
+		t.Errorf("unexpected alert: %+v", alert)
+	}
+
+	// Other agents keep their own baseline.
+	if alerts := monitor.Observe(CostSample{Agent: "writer", RunID: "e", Cost: 1}); len(alerts) != 0 {
+		t.Errorf("unexpected alerts for a new agent: %+v", alerts)
+	}
+}
+
+func TestCostMonitor_ConversationsAndLimits(t *testing.T) {
+	var delivered []CostAlert
+	monitor := NewCostMonitor(CostMonitorConfig{
+		MinSamples:          2,
+		MaxConversationCost: 1,
+		OnAlert:             func(alert CostAlert) { delivered = append(delivered, alert) },
+	})
+	monitor.Observe(CostSample{ConversationID: "c1", Tokens: 100})
+	monitor.Observe(CostSample{ConversationID: "c2", Tokens: 120})
+
+	alerts := monitor.Observe(CostSample{ConversationID: "c3", Tokens: 2000})
+	if len(alerts) != 1 || alerts[0].Unit != "tokens" || alerts[0].Median != 110 || alerts[0].Scope != CostScopeConversation {
+		t.Fatalf("expected a token ratio alert, got %+v", alerts)
+	}
+	alerts = monitor.Observe(CostSample{ConversationID: "c3", Cost: 1.5, Tokens: 10})
+	if len(alerts) != 1 || alerts[0].Limit != 1 || alerts[0].Cost != 1.5 {
+		t.Fatalf("expected a limit alert, got %+v", alerts)
+	}
+	if alerts := monitor.Observe(CostSample{ConversationID: "c3", Cost: 5}); len(alerts) != 0 {
+		t.Errorf("conversation alerted twice: %+v", alerts)
+	}
+	if len(delivered) != 2 {
+		t.Errorf("OnAlert got %d alerts, want 2", len(delivered))
+	}
+}
+
+func TestCostMonitor_AgentBudget(t *testing.T) {
+	monitor := NewCostMonitor(CostMonitorConfig{AgentBudget: 1, BudgetWindow: time.Minute})
+	start := time.Now()
+	observe := func(offset time.Duration, cost float64) []CostAlert {
+		return monitor.Observe(CostSample{Agent: "triage", Cost: cost, Time: start.Add(offset)})
+	}
+
+	if alerts := observe(0, 0.6); len(alerts) != 0 {
+		t.Fatalf("unexpected alerts: %+v", alerts)
+	}
+	alerts := observe(10*time.Second, 0.6)
+	if len(alerts) != 1 || alerts[0].Scope != CostScopeAgent || alerts[0].Cost != 1.2 {
+		t.Fatalf("expected a budget alert, got %+v", alerts)
+	}
+	if alerts := observe(20*time.Second, 0.1); len(alerts) != 0 {
+		t.Errorf("budget alerted twice: %+v", alerts)
+	}
+	// The first sample leaves the window, then spend rises again.
+	if alerts := observe(65*time.Second, 0); len(alerts) != 0 {
+		t.Errorf("unexpected alerts: %+v", alerts)
+	}
+	if alerts := observe(70*time.Second, 0.5); len(alerts) != 1 {
+		t.Errorf("expected the budget alert to re-arm, got %+v", alerts)
+	}
+}
+
+func TestCostMonitor_Webhook(t *testing.T) {
+	received := make(chan CostAlert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert CostAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("decode webhook body: %v", err)
+		}
+		received <- alert
+	}))
+	defer server.Close()
+
+	monitor := NewCostMonitor(CostMonitorConfig{MaxRunCost: 0.5, WebhookURL: server.URL, HTTPClient: server.Client()})
+	monitor.Observe(CostSample{Agent: "triage", RunID: "r1", Cost: 0.75})
+	if err := monitor.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case alert := <-received:
+		if alert.Agent != "triage" || alert.Scope != CostScopeRun || alert.Limit != 0.5 {
+			t.Errorf("unexpected webhook alert: %+v", alert)
+		}
+	default:
+		t.Fatal("webhook not delivered before Close returned")
+	}
+}
+
+func TestCostMonitor_EmitsAnomalyEvents(t *testing.T) {
+	monitor := NewCostMonitor(CostMonitorConfig{MinSamples: 1, Ratio: 1.5})
+	provider := mockprovider.New().
+		WithResponse("first", nil).
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "noop", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)
+	agent, err := New(Config{Provider: provider, Model: "test-model", AgentName: "triage", CostMonitor: monitor})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("noop").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) { return "ok", nil }).
+		Build())
+
+	if event := findEvent(collectEvents(agent.Run(context.Background(), "one"), time.Second), EventTypeCostAnomaly); event != nil {
+		t.Fatalf("unexpected anomaly in the baseline run: %+v", event)
+	}
+	event := findEvent(collectEvents(agent.Run(context.Background(), "two"), time.Second), EventTypeCostAnomaly)
+	if event == nil {
+		t.Fatal("expected a cost.anomaly event")
+	}
+	if event.Data["scope"] != "run" || event.Data["unit"] != "tokens" || event.Data["tokens"] != 60 || event.Data["median"] != 30.0 {
+		t.Errorf("unexpected event data: %+v", event.Data)
+	}
+}
@@ -0,0 +1,131 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidEventType      = errors.New("agentkit: custom event types must be namespaced lowercase names, e.g. \"ticket.created\"")
+	ErrReservedEventType     = errors.New("agentkit: event type uses a namespace reserved for agentkit")
+	ErrUnregisteredEventType = errors.New("agentkit: event type not registered")
+	ErrNoRun                 = errors.New("agentkit: no agent run in context")
+)
+
+// EventTypeInfo describes a custom event type.
+type EventTypeInfo struct {
+	Description string
+}
+
+// eventTypePattern is "<namespace>.<name>" with optional further segments.
+var eventTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*(\.[a-z][a-z0-9_-]*)+$`)
+
+// reservedEventNamespaces are the namespaces of built-in dotted event types,
+// plus a few kept for agentkit's own future events.
+var reservedEventNamespaces = map[string]bool{
+	"agentkit":      true,
+	"agent":         true,
+	"approval":      true,
+	"audio":         true,
+	"collaboration": true,
+	"context":       true,
+	"cost":          true,
+	"document":      true,
+	"error":         true,
+	"guard":         true,
+	"model":         true,
+	"moderation":    true,
+	"handoff":       true,
+	"quota":         true,
+	"replay":        true,
+	"run":           true,
+	"stream":        true,
+	"thinking":      true,
+	"tool":          true,
+}
+
+var (
+	customEventTypesMu sync.RWMutex
+	customEventTypes   = map[EventType]EventTypeInfo{}
+)
+
+// ValidateEventType checks that t is a valid custom event type: at least two
+// dot-separated lowercase segments whose first segment is not reserved.
+func ValidateEventType(t EventType) error {
+	if !eventTypePattern.MatchString(string(t)) {
+		return fmt.Errorf("%w: %q", ErrInvalidEventType, t)
+	}
+	namespace, _, _ := strings.Cut(string(t), ".")
+	if reservedEventNamespaces[namespace] {
+		return fmt.Errorf("%w: %q", ErrReservedEventType, t)
+	}
+	return nil
+}
+
+// RegisterEventType declares a custom event type so tool handlers can emit
+// it with EmitEvent. Registering a type again replaces its info.
+func RegisterEventType(t EventType, info EventTypeInfo) error {
+	if err := ValidateEventType(t); err != nil {
+		return err
+	}
+	customEventTypesMu.Lock()
+	defer customEventTypesMu.Unlock()
+	customEventTypes[t] = info
+	return nil
+}
+
+// LookupEventType returns the info of a registered custom event type.
+func LookupEventType(t EventType) (EventTypeInfo, bool) {
+	customEventTypesMu.RLock()
+	defer customEventTypesMu.RUnlock()
+	info, ok := customEventTypes[t]
+	return info, ok
+}
+
+// CustomEventTypes returns the registered custom event types, sorted.
+func CustomEventTypes() []EventType {
+	customEventTypesMu.RLock()
+	defer customEventTypesMu.RUnlock()
+	types := make([]EventType, 0, len(customEventTypes))
+	for t := range customEventTypes {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+type eventEmitter func(ctx context.Context, event Event)
+
+// EmitEvent emits a custom event from within an agent run, typically from a
+// tool handler. The event goes through the same pipeline as built-in events:
+// it gets the run's trace and span IDs and agent name, reaches event sinks
+// and the Run channel (and so SSE and other transports), bubbles up to parent
+// agents, and is logged to the tracer.
+//
+// The type must have been registered with RegisterEventType.
+func EmitEvent(ctx context.Context, event Event) error {
+	if _, ok := LookupEventType(event.Type); !ok {
+		if err := ValidateEventType(event.Type); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %q", ErrUnregisteredEventType, event.Type)
+	}
+	emit, ok := ctx.Value(eventEmitterKey).(eventEmitter)
+	if !ok {
+		return ErrNoRun
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if tracer := GetTracer(ctx); tracer != nil {
+		_ = tracer.LogEvent(ctx, string(event.Type), event.Data)
+	}
+	emit(ctx, event)
+	return nil
+}
@@ -0,0 +1,106 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestValidateEventType(t *testing.T) {
+	tests := []struct {
+		eventType EventType
+		want      error
+	}{
+		{"ticket.created", nil},
+		{"billing.invoice.paid", nil},
+		{"ticket", ErrInvalidEventType},
+		{"Ticket.Created", ErrInvalidEventType},
+		{"ticket.", ErrInvalidEventType},
+		{"tool.custom", ErrReservedEventType},
+		{"agent.paused", ErrReservedEventType},
+		{"audio.delta", ErrReservedEventType},
+		{"moderation.flagged", ErrReservedEventType},
+		{"document.patched", ErrReservedEventType},
+		{"stream.open", ErrReservedEventType},
+		{"replay.gap", ErrReservedEventType},
+	}
+	for _, tt := range tests {
+		if err := ValidateEventType(tt.eventType); !errors.Is(err, tt.want) {
+			t.Errorf("ValidateEventType(%q) = %v, want %v", tt.eventType, err, tt.want)
+		}
+	}
+}
+
+func TestEmitEvent_FromToolHandler(t *testing.T) {
+	const ticketCreated EventType = "ticket.created"
+	if err := RegisterEventType(ticketCreated, EventTypeInfo{Description: "A support ticket was opened"}); err != nil {
+		t.Fatalf("RegisterEventType() error = %v", err)
+	}
+	if !slices.Contains(CustomEventTypes(), ticketCreated) {
+		t.Errorf("CustomEventTypes() = %v", CustomEventTypes())
+	}
+
+	var mu sync.Mutex
+	var sunk []Event
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "open_ticket", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)
+	agent, err := New(
+		WithProvider(provider),
+		WithModel("test-model"),
+		WithName("support"),
+		WithEventSinks(EventSinkFunc(func(ctx context.Context, e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			sunk = append(sunk, e)
+		})),
+		WithTool(NewTool("open_ticket").
+			WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+				if err := EmitEvent(ctx, NewEvent("ticket.unknown", nil)); !errors.Is(err, ErrUnregisteredEventType) {
+					t.Errorf("EmitEvent(unregistered) = %v", err)
+				}
+				return "T-1", EmitEvent(ctx, Event{Type: ticketCreated, Data: map[string]any{"id": "T-1"}})
+			}).
+			Build()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var custom *Event
+	for _, e := range collectEvents(agent.Run(context.Background(), "my printer is broken"), 2*time.Second) {
+		if e.Type == ticketCreated {
+			custom = &e
+		}
+	}
+	if custom == nil {
+		t.Fatal("custom event not emitted")
+	}
+	if custom.Data["id"] != "T-1" || custom.Data["agent_name"] != "support" || custom.Timestamp.IsZero() {
+		t.Errorf("custom event = %+v", custom)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.ContainsFunc(sunk, func(e Event) bool { return e.Type == ticketCreated }) {
+		t.Error("custom event did not reach the event sink")
+	}
+}
+
+func TestEmitEvent_OutsideRun(t *testing.T) {
+	if err := RegisterEventType("ticket.closed", EventTypeInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := EmitEvent(context.Background(), NewEvent("ticket.closed", nil)); !errors.Is(err, ErrNoRun) {
+		t.Fatalf("EmitEvent() = %v, want ErrNoRun", err)
+	}
+	if err := RegisterEventType("handoff.custom", EventTypeInfo{}); !errors.Is(err, ErrReservedEventType) {
+		t.Fatalf("RegisterEventType(reserved) = %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package agentkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultDevReloadInterval is how often DevReloadConfig files are checked.
+const DefaultDevReloadInterval = time.Second
+
+// DevReloadConfig reloads the system prompt and selected settings from files
+// while they are edited, for a faster prompt-iteration loop in development.
+// Changes apply to runs started after the files are saved, without
+// restarting the server.
+//
+// Files are checked at the start of a run, at most once per Interval. A file
+// that fails to parse is logged and the previous settings are kept. Settings
+// chosen by feature flags still take precedence.
+type DevReloadConfig struct {
+	// PromptFile holds the system prompt as a text/template executed with
+	// PromptData. It replaces Config.SystemPrompt and its variants.
+	PromptFile string
+	// ConfigFile is a JSON object of overrides. Every key is optional:
+	//
+	//	{
+	//	  "model": "gpt-4o-mini",
+	//	  "temperature": 0.2,
+	//	  "max_iterations": 8,
+	//	  "reasoning_effort": "low",
+	//	  "text_verbosity": "low",
+	//	  "tool_descriptions": {"search": "Search the knowledge base"}
+	//	}
+	ConfigFile string
+	// Interval between checks for changes (default DefaultDevReloadInterval).
+	Interval time.Duration
+}
+
+// PromptData is the data a DevReloadConfig prompt template is executed with.
+type PromptData struct {
+	Model     string
+	AgentName string
+	Date      string // Current date, YYYY-MM-DD
+}
+
+// devSettings is the ConfigFile format.
+type devSettings struct {
+	Model            string            `json:"model"`
+	Temperature      *float32          `json:"temperature"`
+	MaxIterations    int               `json:"max_iterations"`
+	ReasoningEffort  string            `json:"reasoning_effort"`
+	TextVerbosity    string            `json:"text_verbosity"`
+	ToolDescriptions map[string]string `json:"tool_descriptions"`
+}
+
+func (s devSettings) validate() error {
+	if s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > 2) {
+		return ErrInvalidTemperature
+	}
+	if s.MaxIterations < 0 || s.MaxIterations > 100 {
+		return ErrInvalidIterations
+	}
+	switch providers.ReasoningEffort(s.ReasoningEffort) {
+	case providers.ReasoningEffortNone, providers.ReasoningEffortMinimal, providers.ReasoningEffortLow,
+		providers.ReasoningEffortMedium, providers.ReasoningEffortHigh, providers.ReasoningEffortXHigh:
+		return nil
+	}
+	return ErrInvalidReasoningEffort
+}
+
+// devOverrides is one loaded snapshot of the files, shared read-only by the
+// runs that started while it was current.
+type devOverrides struct {
+	prompt   *template.Template
+	settings devSettings
+}
+
+// fileStamp identifies a version of a file.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// devReloader loads DevReloadConfig files and reloads them when they change.
+type devReloader struct {
+	cfg     DevReloadConfig
+	logger  *slog.Logger
+	mu      sync.Mutex
+	checked time.Time
+	stamps  map[string]fileStamp
+	current *devOverrides
+}
+
+// newDevReloader loads the files once, failing if they cannot be read.
+func newDevReloader(cfg DevReloadConfig, logger *slog.Logger) (*devReloader, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultDevReloadInterval
+	}
+	r := &devReloader{cfg: cfg, logger: logger}
+	r.stamps = r.stat()
+	overrides, err := r.load()
+	if err != nil {
+		return nil, fmt.Errorf("agentkit: dev reload: %w", err)
+	}
+	r.current = overrides
+	r.checked = time.Now()
+	return r, nil
+}
+
+// files returns the configured file paths.
+func (r *devReloader) files() []string {
+	var files []string
+	for _, path := range []string{r.cfg.PromptFile, r.cfg.ConfigFile} {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+func (r *devReloader) stat() map[string]fileStamp {
+	stamps := make(map[string]fileStamp, 2)
+	for _, path := range r.files() {
+		if info, err := os.Stat(path); err == nil {
+			stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stamps
+}
+
+func (r *devReloader) load() (*devOverrides, error) {
+	overrides := &devOverrides{}
+	if r.cfg.PromptFile != "" {
+		data, err := os.ReadFile(r.cfg.PromptFile)
+		if err != nil {
+			return nil, err
+		}
+		overrides.prompt, err = template.New(r.cfg.PromptFile).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.cfg.ConfigFile != "" {
+		data, err := os.ReadFile(r.cfg.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&overrides.settings); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.cfg.ConfigFile, err)
+		}
+		if err := overrides.settings.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.cfg.ConfigFile, err)
+		}
+	}
+	return overrides, nil
+}
+
+// overrides returns the current snapshot, reloading the files first when
+// they changed since the last check.
+func (r *devReloader) overrides() *devOverrides {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checked) < r.cfg.Interval {
+		return r.current
+	}
+	r.checked = time.Now()
+	stamps := r.stat()
+	var changed []string
+	for _, path := range r.files() {
+		if stamps[path] != r.stamps[path] {
+			changed = append(changed, path)
+		}
+	}
+	if len(changed) == 0 {
+		return r.current
+	}
+	// Remember the new versions even if they fail to load, so a broken file
+	// is reported once rather than on every run.
+	r.stamps = stamps
+	overrides, err := r.load()
+	if err != nil {
+		r.logger.Warn("dev reload failed; keeping previous settings", "files", strings.Join(changed, ", "), "error", err)
+		return r.current
+	}
+	r.current = overrides
+	r.logger.Info("dev reload applied", "files", strings.Join(changed, ", "))
+	return r.current
+}
+
+const devOverridesKey contextKey = "agentkit_dev_overrides"
+
+// applyDevReload stores the current dev overrides in ctx for the run.
+func (a *Agent) applyDevReload(ctx context.Context) context.Context {
+	if a.devReload == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, devOverridesKey, a.devReload.overrides())
+}
+
+func getDevOverrides(ctx context.Context) *devOverrides {
+	overrides, _ := ctx.Value(devOverridesKey).(*devOverrides)
+	return overrides
+}
+
+// devPrompt returns the run's reloaded system prompt, if any.
+func (a *Agent) devPrompt(ctx context.Context) SystemPromptFunc {
+	overrides := getDevOverrides(ctx)
+	if overrides == nil || overrides.prompt == nil {
+		return nil
+	}
+	return func(ctx context.Context) string {
+		var b strings.Builder
+		data := PromptData{Model: a.runModel(ctx), AgentName: a.agentName, Date: time.Now().Format(time.DateOnly)}
+		if err := overrides.prompt.Execute(&b, data); err != nil {
+			a.logger.Warn("dev prompt template failed", "error", err)
+		}
+		return b.String()
+	}
+}
+
+// runMaxIterations returns the iteration limit for this run.
+func (a *Agent) runMaxIterations(ctx context.Context) int {
+	if o := currentRunOptions(ctx); o != nil && o.maxIterations > 0 {
+		return o.maxIterations
+	}
+	if overrides := getDevOverrides(ctx); overrides != nil && overrides.settings.MaxIterations > 0 {
+		return overrides.settings.MaxIterations
+	}
+	return a.maxIterations
+}
+
+// applyDevSettings applies the run's reloaded settings to req.
+func applyDevSettings(ctx context.Context, req *providers.CompletionRequest) {
+	overrides := getDevOverrides(ctx)
+	if overrides == nil {
+		return
+	}
+	settings := overrides.settings
+	if settings.Temperature != nil {
+		req.Temperature = *settings.Temperature
+	}
+	if settings.ReasoningEffort != "" {
+		req.ReasoningEffort = providers.ReasoningEffort(settings.ReasoningEffort)
+	}
+	if settings.TextVerbosity != "" {
+		req.TextVerbosity = settings.TextVerbosity
+	}
+}
+
+// devToolDescription returns the run's reloaded description for a tool.
+func devToolDescription(ctx context.Context, name string) (string, bool) {
+	overrides := getDevOverrides(ctx)
+	if overrides == nil {
+		return "", false
+	}
+	description, ok := overrides.settings.ToolDescriptions[name]
+	return description, ok
+}
@@ -0,0 +1,102 @@
+package agentkit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// writeDevFile writes content and moves its mtime forward so the change is
+// seen even on filesystems with coarse timestamps.
+func writeDevFile(t *testing.T, path, content string, age time.Duration) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevReload_AppliesChangesToLaterRuns(t *testing.T) {
+	dir := t.TempDir()
+	promptFile := filepath.Join(dir, "prompt.tmpl")
+	configFile := filepath.Join(dir, "agent.json")
+	writeDevFile(t, promptFile, "You are {{.AgentName}} on {{.Model}}.", time.Hour)
+	writeDevFile(t, configFile, `{"temperature": 0.3}`, time.Hour)
+
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("one", nil).
+		WithResponse("two", nil)}
+	agent, err := New(Config{
+		Provider:  provider,
+		Model:     "test-model",
+		AgentName: "helper",
+		DevReload: &DevReloadConfig{PromptFile: promptFile, ConfigFile: configFile, Interval: time.Nanosecond},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("search").WithDescription("Search").WithHandler(func(context.Context, map[string]any) (any, error) {
+		return "ok", nil
+	}).Build())
+
+	collectEvents(agent.Run(context.Background(), "hi"), time.Second)
+	first := provider.requests[0]
+	if first.SystemPrompt != "You are helper on test-model." || first.Temperature != 0.3 {
+		t.Fatalf("first request prompt %q, temperature %v", first.SystemPrompt, first.Temperature)
+	}
+
+	writeDevFile(t, promptFile, "Be brief.", 0)
+	writeDevFile(t, configFile, `{"model": "other-model", "tool_descriptions": {"search": "Search the docs"}}`, 0)
+
+	collectEvents(agent.Run(context.Background(), "hi"), time.Second)
+	second := provider.requests[1]
+	if second.SystemPrompt != "Be brief." || second.Model != "other-model" || second.Temperature != 0 {
+		t.Errorf("second request prompt %q, model %q, temperature %v", second.SystemPrompt, second.Model, second.Temperature)
+	}
+	if len(second.Tools) != 1 || second.Tools[0].Description != "Search the docs" {
+		t.Errorf("tools = %+v", second.Tools)
+	}
+}
+
+func TestDevReload_KeepsPreviousSettingsOnError(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "agent.json")
+	writeDevFile(t, configFile, `{"max_iterations": 3}`, time.Hour)
+
+	reloader, err := newDevReloader(DevReloadConfig{ConfigFile: configFile, Interval: time.Nanosecond}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("newDevReloader() error = %v", err)
+	}
+
+	writeDevFile(t, configFile, `{"max_iteration": 5}`, 0)
+	if got := reloader.overrides().settings.MaxIterations; got != 3 {
+		t.Errorf("max iterations after bad edit = %d, want 3", got)
+	}
+
+	writeDevFile(t, configFile, `{"max_iterations": 5}`, time.Minute)
+	if got := reloader.overrides().settings.MaxIterations; got != 5 {
+		t.Errorf("max iterations after fix = %d, want 5", got)
+	}
+}
+
+func TestDevReload_InvalidFileFailsNew(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "agent.json")
+	writeDevFile(t, configFile, `{"reasoning_effort": "extreme"}`, time.Hour)
+
+	_, err := New(Config{
+		Provider:  mockprovider.New(),
+		Model:     "test-model",
+		DevReload: &DevReloadConfig{ConfigFile: configFile},
+	})
+	if err == nil {
+		t.Fatal("New() error = nil, want invalid reasoning_effort")
+	}
+}
@@ -0,0 +1,44 @@
+package agentkit
+
+import (
+	"context"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// WithDeveloperInstructions adds per-run instructions, such as business rules
+// that change per request, without rebuilding the system prompt. They are
+// sent as developer messages ahead of the conversation on every model call,
+// so history compaction never drops them; providers without a developer role
+// fold them into the system prompt. Instructions accumulate across calls and
+// apply to agents the run delegates to.
+func WithDeveloperInstructions(ctx context.Context, instructions ...string) context.Context {
+	existing := GetDeveloperInstructions(ctx)
+	combined := make([]string, 0, len(existing)+len(instructions))
+	combined = append(combined, existing...)
+	for _, instruction := range instructions {
+		if instruction != "" {
+			combined = append(combined, instruction)
+		}
+	}
+	return context.WithValue(ctx, developerKey, combined)
+}
+
+// GetDeveloperInstructions returns the developer instructions in ctx.
+func GetDeveloperInstructions(ctx context.Context) []string {
+	instructions, _ := ctx.Value(developerKey).([]string)
+	return instructions
+}
+
+// withDeveloperMessages prepends the developer instructions in ctx to history.
+func withDeveloperMessages(ctx context.Context, history []providers.Message) []providers.Message {
+	instructions := GetDeveloperInstructions(ctx)
+	if len(instructions) == 0 {
+		return history
+	}
+	messages := make([]providers.Message, 0, len(instructions)+len(history))
+	for _, instruction := range instructions {
+		messages = append(messages, providers.Message{Role: providers.RoleDeveloper, Content: instruction})
+	}
+	return append(messages, history...)
+}
@@ -0,0 +1,48 @@
+package agentkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestWithDeveloperInstructions(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("done", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		SystemPrompt:    func(context.Context) string { return "You are a support agent." },
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := WithDeveloperInstructions(context.Background(), "Refunds above $500 need approval.")
+	ctx = WithDeveloperInstructions(ctx, "", "Reply in German.")
+	collectEvents(agent.Run(ctx, "refund my order"), time.Second)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(provider.requests))
+	}
+	req := provider.requests[0]
+	if req.SystemPrompt != "You are a support agent." {
+		t.Errorf("system prompt = %q, want it unchanged", req.SystemPrompt)
+	}
+	want := []providers.Message{
+		{Role: providers.RoleDeveloper, Content: "Refunds above $500 need approval."},
+		{Role: providers.RoleDeveloper, Content: "Reply in German."},
+		{Role: providers.RoleUser, Content: "refund my order"},
+	}
+	if len(req.Messages) != len(want) {
+		t.Fatalf("messages = %+v", req.Messages)
+	}
+	for i, msg := range want {
+		if req.Messages[i].Role != msg.Role || req.Messages[i].Content != msg.Content {
+			t.Errorf("message %d = %+v, want %+v", i, req.Messages[i], msg)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// PatchFormat is how a model edits a Document.
+type PatchFormat string
+
+const (
+	// PatchUnifiedDiff edits text with a unified diff (the default).
+	PatchUnifiedDiff PatchFormat = "unified_diff"
+	// PatchJSON edits a JSON document with a JSON Patch (RFC 6902).
+	PatchJSON PatchFormat = "json_patch"
+)
+
+// Document is a working artifact an agent revises over many runs. Instead
+// of re-sending the whole document, the model answers with a patch against
+// it, which is applied and validated; a patch that fails is sent back to
+// the model with the error. The final output and the document.patched event
+// carry the patch, so clients holding the previous version apply it too,
+// and the conversation history keeps patches rather than full copies.
+//
+// A run started with ContextWithDocument updates Content and Version in place
+// when it completes; don't read them while the run is in progress.
+type Document struct {
+	Name    string // Shown to the model, e.g. "README.md"
+	Content string
+	Version int // Incremented by every applied patch
+	Format  PatchFormat
+	// Validate checks the patched content, e.g. that it still parses. Its
+	// error is sent back to the model like a patch that doesn't apply.
+	Validate func(content string) error
+	// MaxRepairs limits the corrections requested for failed patches
+	// (0 uses DefaultJSONRepairs; negative disables them).
+	MaxRepairs int
+}
+
+func (d *Document) format() PatchFormat {
+	if d.Format == "" {
+		return PatchUnifiedDiff
+	}
+	return d.Format
+}
+
+func (d *Document) maxRepairs() int {
+	switch {
+	case d.MaxRepairs < 0:
+		return 0
+	case d.MaxRepairs == 0:
+		return DefaultJSONRepairs
+	}
+	return d.MaxRepairs
+}
+
+// Apply applies patch in the document's format and validates the result,
+// without changing the document.
+func (d *Document) Apply(patch string) (string, error) {
+	var content string
+	var err error
+	if d.format() == PatchJSON {
+		content, err = ApplyJSONPatch(d.Content, patch)
+	} else {
+		content, err = ApplyUnifiedDiff(d.Content, patch)
+	}
+	if err != nil {
+		return "", err
+	}
+	if d.Validate != nil {
+		if err := d.Validate(content); err != nil {
+			return "", fmt.Errorf("agentkit: patched document is invalid: %w", err)
+		}
+	}
+	return content, nil
+}
+
+// instruction shows the model the document and asks for a patch.
+func (d *Document) instruction() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are editing the document %q (version %d) below. Do not repeat the document. ", d.Name, d.Version)
+	if d.format() == PatchJSON {
+		b.WriteString("Reply only with a JSON Patch (RFC 6902) array of operations against it, " +
+			`e.g. [{"op":"replace","path":"/title","value":"New title"}]. Reply with [] if nothing needs to change.`)
+	} else {
+		b.WriteString("Reply only with a unified diff against it: hunks starting with \"@@ -start,count +start,count @@\", " +
+			"unchanged context lines prefixed with a space, removed lines with \"-\" and added lines with \"+\". " +
+			"Include two or three lines of context around each change, copied exactly. Reply with " + noDocumentChanges + " if nothing needs to change.")
+	}
+	fmt.Fprintf(&b, "\n\n<document name=%q>\n%s\n</document>", d.Name, d.Content)
+	return b.String()
+}
+
+// noDocumentChanges is the reply for an edit that leaves a text document
+// as it is.
+const noDocumentChanges = "NO CHANGES"
+
+type documentKey struct{}
+
+// ContextWithDocument makes runs started with ctx edit doc: the model sees it
+// and answers with a patch, applied to doc when the run completes.
+func ContextWithDocument(ctx context.Context, doc *Document) context.Context {
+	return context.WithValue(ctx, documentKey{}, doc)
+}
+
+func runDocument(ctx context.Context) *Document {
+	doc, _ := ctx.Value(documentKey{}).(*Document)
+	return doc
+}
+
+// applyDocument adds the run's document and patch instructions to the
+// system prompt.
+func applyDocument(ctx context.Context, req *providers.CompletionRequest) {
+	if doc := runDocument(ctx); doc != nil {
+		req.SystemPrompt = strings.TrimSpace(req.SystemPrompt + "\n\n" + doc.instruction())
+	}
+}
+
+// checkDocumentPatch applies a final answer to the run's document. It
+// returns the patch, or the message asking the model to correct it while
+// repairs remain. Once they run out it reports the patch error.
+func (a *Agent) checkDocumentPatch(ctx context.Context, doc *Document, model, content string, repairs *int, events chan<- Event) (string, *providers.Message, error) {
+	patch := strings.TrimSpace(content)
+	if patch == "" || patch == noDocumentChanges {
+		return noDocumentChanges, nil, nil
+	}
+	if patch == "[]" {
+		return patch, nil, nil
+	}
+	patched, err := doc.Apply(patch)
+	if err == nil {
+		doc.Content = patched
+		doc.Version++
+		a.emit(ctx, events, DocumentPatched(doc.Name, doc.Version, doc.format(), patch))
+		return patch, nil, nil
+	}
+	if *repairs >= doc.maxRepairs() {
+		err = fmt.Errorf("agentkit: document %q not updated after %d repairs: %w", doc.Name, *repairs, err)
+		a.emit(ctx, events, Error(err))
+		return "", nil, err
+	}
+	*repairs++
+	a.logger.Info("document patch rejected", "document", doc.Name, "repair", *repairs, "error", err)
+	a.emit(ctx, events, OutputInvalid(model, []string{err.Error()}, *repairs))
+	return "", &providers.Message{
+		Role:    providers.RoleUser,
+		Content: "Your patch was not applied: " + err.Error() + "\nReply with a corrected patch against the same document version.",
+	}, nil
+}
+
+// RunEdit runs the agent with instruction against doc and returns the
+// applied patch, empty when the model made no change. doc is updated in
+// place.
+func (a *Agent) RunEdit(ctx context.Context, doc *Document, instruction string) (string, error) {
+	if doc == nil {
+		return "", errors.New("agentkit: RunEdit requires a document")
+	}
+	patch, err := a.runFinalOutput(ContextWithDocument(ctx, doc), instruction)
+	if err != nil {
+		return "", err
+	}
+	if patch == noDocumentChanges || patch == "[]" {
+		return "", nil
+	}
+	return patch, nil
+}
@@ -0,0 +1,91 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestRunEdit_AppliesPatches(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("@@ -2 +2 @@\n-two\n+deux\n", nil).
+		WithResponse("NO CHANGES", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	doc := &Document{Name: "numbers.txt", Content: "one\ntwo\nthree\n"}
+
+	patch, err := agent.RunEdit(context.Background(), doc, "Translate the second line to French")
+	if err != nil {
+		t.Fatalf("RunEdit() error = %v", err)
+	}
+	if doc.Content != "one\ndeux\nthree\n" || doc.Version != 1 || !strings.Contains(patch, "+deux") {
+		t.Errorf("unexpected edit: %q, %+v", patch, doc)
+	}
+	if prompt := provider.requests[0].SystemPrompt; !strings.Contains(prompt, "unified diff") || !strings.Contains(prompt, "one\ntwo\nthree") {
+		t.Errorf("system prompt doesn't show the document: %q", prompt)
+	}
+
+	patch, err = agent.RunEdit(context.Background(), doc, "Anything else?")
+	if err != nil || patch != "" || doc.Version != 1 {
+		t.Errorf("no-change edit = %q, %v, version %d", patch, err, doc.Version)
+	}
+	if !strings.Contains(provider.requests[1].SystemPrompt, "one\ndeux\nthree") {
+		t.Error("second run didn't see the patched document")
+	}
+}
+
+func TestRunEdit_RepairsRejectedPatches(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse(`[{"op":"replace","path":"/titel","value":"Final"}]`, nil).
+		WithResponse(`[{"op":"replace","path":"/title","value":""}]`, nil).
+		WithResponse(`[{"op":"replace","path":"/title","value":"Final"}]`, nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	doc := &Document{
+		Name:    "post.json",
+		Content: `{"title":"Draft"}`,
+		Format:  PatchJSON,
+		Validate: func(content string) error {
+			var post struct{ Title string }
+			json.Unmarshal([]byte(content), &post)
+			if post.Title == "" {
+				return errors.New("title is empty")
+			}
+			return nil
+		},
+	}
+
+	events := collectEvents(agent.Run(ContextWithDocument(context.Background(), doc), "Finalize the title"), time.Second)
+
+	if doc.Content != `{"title":"Final"}` || doc.Version != 1 {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+	var rejected []string
+	for _, event := range events {
+		if event.Type == EventTypeOutputInvalid {
+			rejected = append(rejected, event.Data["problems"].([]string)[0])
+		}
+	}
+	if len(rejected) != 2 || !strings.Contains(rejected[0], `"titel" not found`) || !strings.Contains(rejected[1], "title is empty") {
+		t.Errorf("unexpected rejections: %q", rejected)
+	}
+	patched := findEvent(events, EventTypeDocumentPatched)
+	if patched == nil || patched.Data["version"] != 1 || patched.Data["format"] != "json_patch" || !strings.Contains(patched.Data["patch"].(string), `"Final"`) {
+		t.Errorf("unexpected document.patched event: %+v", patched)
+	}
+	if final := findEvent(events, EventTypeFinalOutput); final == nil || strings.Contains(final.Data["response"].(string), "Draft") {
+		t.Errorf("final output should carry the patch, got %+v", final)
+	}
+	if last := provider.requests[2].Messages; !strings.Contains(last[len(last)-1].Content, "title is empty") {
+		t.Errorf("repair request doesn't report the validation error: %+v", last[len(last)-1])
+	}
+}
@@ -711,3 +711,140 @@ func TestE2E_EmptyResponseHandling(t *testing.T) {
 
 	t.Log("Empty response properly handled with complete event lifecycle")
 }
+
+// TestE2E_StreamingAnnotationsInFinalOutput verifies citations reach the final output event
+func TestE2E_StreamingAnnotationsInFinalOutput(t *testing.T) {
+	citation := providers.Annotation{
+		Type:       providers.AnnotationTypeURLCitation,
+		StartIndex: 0,
+		EndIndex:   5,
+		URL:        "https://example.com/paris",
+		Title:      "Paris",
+	}
+	mock := NewMockLLM().WithStream([]providers.StreamChunk{
+		{Content: "Paris is the capital."},
+		{Annotations: []providers.Annotation{citation}},
+		{IsComplete: true},
+	})
+
+	agent, err := New(Config{
+		Model:           "gpt-4o",
+		LLMProvider:     mock,
+		StreamResponses: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	var annotations []providers.Annotation
+	for event := range agent.Run(context.Background(), "What is the capital of France?") {
+		if event.Type == EventTypeFinalOutput {
+			annotations, _ = event.Data["annotations"].([]providers.Annotation)
+		}
+	}
+
+	if len(annotations) != 1 {
+		t.Fatalf("Expected 1 annotation on final output, got %d", len(annotations))
+	}
+	if annotations[0].URL != citation.URL {
+		t.Errorf("Expected citation URL %s, got %s", citation.URL, annotations[0].URL)
+	}
+}
+
+func TestE2E_CostUpdateEvents(t *testing.T) {
+	stream := []providers.StreamChunk{
+		{Content: "Hello!"},
+		{IsComplete: true, Usage: &providers.TokenUsage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500}},
+	}
+	mock := NewMockLLM().WithStream(stream).WithStream(stream)
+
+	agent, err := New(Config{
+		Model:           "gpt-4o",
+		LLMProvider:     mock,
+		StreamResponses: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	ctx := WithConversation(context.Background(), "conv-cost")
+	var updates []Event
+	for i := 0; i < 2; i++ {
+		for event := range agent.Run(ctx, "Hi") {
+			if event.Type == EventTypeCostUpdate {
+				updates = append(updates, event)
+			}
+		}
+	}
+
+	if len(updates) != 2 {
+		t.Fatalf("Expected 2 cost.update events, got %d", len(updates))
+	}
+
+	first, second := updates[0].Data, updates[1].Data
+	if first["total_tokens"] != 1500 || first["run_total_tokens"] != 1500 {
+		t.Errorf("Unexpected first update tokens: %v", first)
+	}
+	if first["conversation_id"] != "conv-cost" {
+		t.Errorf("Expected conversation_id=conv-cost, got %v", first["conversation_id"])
+	}
+	if second["run_total_tokens"] != 1500 {
+		t.Errorf("Expected run totals to reset per run, got %v", second["run_total_tokens"])
+	}
+	if second["conversation_total_tokens"] != 3000 {
+		t.Errorf("Expected conversation_total_tokens=3000, got %v", second["conversation_total_tokens"])
+	}
+	if cost, ok := second["cost"].(float64); ok {
+		conversationCost, _ := second["conversation_cost"].(float64)
+		if conversationCost <= cost {
+			t.Errorf("Expected conversation cost to accumulate, got %v (incremental %v)", conversationCost, cost)
+		}
+	}
+}
+
+func TestE2E_StreamingToolArgsDeltaEvents(t *testing.T) {
+	mock := NewMockLLM().
+		WithStream([]providers.StreamChunk{
+			{ToolCallID: "call_1", ToolName: "search", ToolArgsDelta: `{"query":`},
+			{ToolCallID: "call_1", ToolName: "search", ToolArgsDelta: `"golang"}`},
+			{ToolCallID: "call_1", ToolName: "search", ToolArgs: `{"query":"golang"}`},
+			{IsComplete: true},
+		}).
+		WithStream([]providers.StreamChunk{
+			{Content: "Found it."},
+			{IsComplete: true},
+		})
+
+	agent, err := New(Config{
+		Model:           "gpt-4o",
+		LLMProvider:     mock,
+		StreamResponses: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create agent: %v", err)
+	}
+
+	agent.AddTool(NewTool("search").
+		WithParameter("query", String().Required()).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return "result", nil
+		}).
+		Build())
+
+	var deltas []Event
+	for event := range agent.Run(context.Background(), "Search for golang") {
+		if event.Type == EventTypeToolArgsDelta {
+			deltas = append(deltas, event)
+		}
+	}
+
+	if len(deltas) != 2 {
+		t.Fatalf("Expected 2 tool.args.delta events, got %d", len(deltas))
+	}
+	if deltas[0].Data["tool_name"] != "search" || deltas[0].Data["call_id"] != "call_1" {
+		t.Errorf("Unexpected delta metadata: %v", deltas[0].Data)
+	}
+	if deltas[1].Data["arguments"] != `{"query":"golang"}` {
+		t.Errorf("Expected accumulated arguments, got %v", deltas[1].Data["arguments"])
+	}
+}
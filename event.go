@@ -1,6 +1,7 @@
 package agentkit
 
 import (
+	"encoding/base64"
 	"sync"
 	"time"
 
@@ -16,6 +17,8 @@ const (
 	EventTypeReasoningChunk EventType = "reasoning_chunk"
 	EventTypeResponseChunk  EventType = "response_chunk"
 	EventTypeFinalOutput   EventType = "final_output"
+	EventTypeThinkingSegment EventType = "thinking.segment"
+	EventTypeAudioDelta      EventType = "audio.delta"
 
 	// Agent lifecycle events
 	EventTypeAgentStart    EventType = "agent.start"
@@ -24,6 +27,13 @@ const (
 	// Tool execution events
 	EventTypeActionDetected EventType = "action_detected"
 	EventTypeActionResult   EventType = "action_result"
+	EventTypeToolArgsDelta  EventType = "tool.args.delta"
+	EventTypeToolLog        EventType = "tool.log"
+	EventTypeToolProgress   EventType = "tool.progress"
+	EventTypeToolArtifact   EventType = "tool.artifact"
+	EventTypeToolUnknown    EventType = "tool.unknown"
+	EventTypeToolArgsInvalid EventType = "tool.args.invalid"
+	EventTypeToolHosted     EventType = "tool.hosted"
 
 	// Multi-agent coordination events
 	EventTypeHandoffStart                EventType = "handoff.start"
@@ -39,6 +49,28 @@ const (
 	EventTypeProgress EventType = "progress"
 	EventTypeDecision EventType = "decision"
 
+	// Usage events
+	EventTypeCostUpdate   EventType = "cost.update"
+	EventTypeQuotaWarning EventType = "quota.warning"
+	EventTypeCostAnomaly  EventType = "cost.anomaly"
+
+	// Context management events
+	EventTypeContextCompacted EventType = "context.compacted"
+	EventTypeServerStateLost  EventType = "context.server_state_lost"
+	EventTypePromptBudget     EventType = "context.prompt_budget"
+
+	// Guard events
+	EventTypeGuardViolation    EventType = "guard.violation"
+	EventTypeModerationFlagged EventType = "moderation.flagged"
+
+	// Model fallback events
+	EventTypeModelFallback EventType = "model.fallback"
+	EventTypeStreamStalled EventType = "model.stream_stalled"
+	EventTypeOutputInvalid EventType = "model.output_invalid"
+
+	// Document editing events
+	EventTypeDocumentPatched EventType = "document.patched"
+
 	// Error events
 	EventTypeError EventType = "error"
 )
@@ -50,6 +82,58 @@ type Event struct {
 	Timestamp time.Time      `json:"timestamp"`
 	TraceID   string         `json:"trace_id,omitempty"`
 	SpanID    string         `json:"span_id,omitempty"`
+
+	err *ErrorDetail // in-process payload of error events; see ErrorDetail
+}
+
+// EventSource identifies the agent that produced an event. Events from
+// agents run by handoffs, collaborations or agent tools bubble up to the
+// parent's Run channel, so consumers use the source to attribute and nest
+// them. Agents emit it in Data as agent_name, agent_path, agent_depth and
+// parent_call_id.
+type EventSource struct {
+	Agent string `json:"agent"`
+	// Path is the slash-separated agent names from the root agent, e.g.
+	// "triage/billing".
+	Path string `json:"path"`
+	// Depth is 0 for the root agent and 1 for agents it starts.
+	Depth int `json:"depth"`
+	// ParentCallID is the parent's tool call that started the agent, when
+	// started from a tool such as a handoff tool.
+	ParentCallID string `json:"parent_call_id,omitempty"`
+}
+
+func (s EventSource) data() map[string]any {
+	data := map[string]any{
+		"agent_name":  s.Agent,
+		"agent_path":  s.Path,
+		"agent_depth": s.Depth,
+	}
+	if s.ParentCallID != "" {
+		data["parent_call_id"] = s.ParentCallID
+	}
+	return data
+}
+
+// Source returns the agent that produced the event, also after JSON
+// decoding. It reports false for events emitted outside an agent run.
+func (e Event) Source() (EventSource, bool) {
+	path, ok := e.Data["agent_path"].(string)
+	if !ok {
+		return EventSource{}, false
+	}
+	source := EventSource{Path: path}
+	source.Agent, _ = e.Data["agent_name"].(string)
+	source.ParentCallID, _ = e.Data["parent_call_id"].(string)
+	switch depth := e.Data["agent_depth"].(type) {
+	case int:
+		source.Depth = depth
+	case float64:
+		source.Depth = int(depth)
+	case int64:
+		source.Depth = int(depth)
+	}
+	return source, true
 }
 
 // NewEvent creates a new event with the current timestamp
@@ -80,6 +164,15 @@ func ReasoningChunk(chunk string) Event {
 	})
 }
 
+// ThinkingSegment creates an event holding coalesced thinking chunks
+func ThinkingSegment(content string, source EventType, chunks int) Event {
+	return NewEvent(EventTypeThinkingSegment, map[string]any{
+		"content": content,
+		"source":  string(source),
+		"chunks":  chunks,
+	})
+}
+
 // ResponseChunk creates a response text chunk event
 func ResponseChunk(chunk string) Event {
 	return NewEvent(EventTypeResponseChunk, map[string]any{
@@ -87,6 +180,15 @@ func ResponseChunk(chunk string) Event {
 	})
 }
 
+// AudioDelta creates an event holding a fragment of a spoken reply,
+// base64-encoded, in format (e.g. "pcm16")
+func AudioDelta(data []byte, format string) Event {
+	return NewEvent(EventTypeAudioDelta, map[string]any{
+		"audio":  base64.StdEncoding.EncodeToString(data),
+		"format": format,
+	})
+}
+
 // ActionDetected creates an action detected event
 func ActionDetected(description, toolID string) Event {
 	return NewEvent(EventTypeActionDetected, map[string]any{
@@ -103,6 +205,17 @@ func ActionResult(description string, result any) Event {
 	})
 }
 
+// ToolArgsDelta creates a tool.args.delta event while a tool call's arguments stream in.
+// arguments holds the raw (possibly incomplete) JSON accumulated so far.
+func ToolArgsDelta(toolName, callID, delta, arguments string) Event {
+	return NewEvent(EventTypeToolArgsDelta, map[string]any{
+		"tool_name": toolName,
+		"call_id":   callID,
+		"delta":     delta,
+		"arguments": arguments,
+	})
+}
+
 // ToolResult creates a tool result event (alias for ActionResult)
 func ToolResult(toolName string, result any) Event {
 	return ActionResult(toolName, result)
@@ -116,18 +229,218 @@ func FinalOutput(summary, response string) Event {
 	})
 }
 
-// Error creates an error event
+// FinalOutputWithAnnotations creates a final output event that also carries
+// citations (web search, file search, code interpreter) attached to the response.
+func FinalOutputWithAnnotations(summary, response string, annotations []providers.Annotation) Event {
+	event := FinalOutput(summary, response)
+	if len(annotations) > 0 {
+		event.Data["annotations"] = annotations
+	}
+	return event
+}
+
+// Error creates an error event carrying a structured ErrorDetail
 func Error(err error) Event {
-	return NewEvent(EventTypeError, map[string]any{
-		"error": err.Error(),
-	})
+	return errorEvent(NewErrorDetail(err))
 }
 
 // ToolError creates a tool execution error event
 func ToolError(toolName string, err error) Event {
-	return NewEvent(EventTypeError, map[string]any{
+	detail := NewErrorDetail(err)
+	if detail.Code == ErrorCodeUnknown {
+		detail.Code = ErrorCodeTool
+	}
+	detail.Tool = toolName
+	return errorEvent(detail)
+}
+
+func errorEvent(detail *ErrorDetail) Event {
+	data := map[string]any{
+		"error":     detail.Message,
+		"code":      string(detail.Code),
+		"retryable": detail.Retryable,
+	}
+	if detail.Tool != "" {
+		data["tool_name"] = detail.Tool
+	}
+	event := NewEvent(EventTypeError, data)
+	event.err = detail
+	return event
+}
+
+// HostedToolCall creates an event reporting a hosted tool's progress, such
+// as a web search moving to "searching" and then "completed". Details holds
+// what the provider reported about the call, e.g. the query or the code run.
+func HostedToolCall(toolType, toolID, status string, details map[string]any) Event {
+	return NewEvent(EventTypeToolHosted, map[string]any{
+		"tool_type": toolType,
+		"tool_id":   toolID,
+		"status":    status,
+		"details":   details,
+	})
+}
+
+// ToolUnknown creates an event reporting that the model called a tool that
+// doesn't exist or is disabled for the run, with the tools it could have
+// called. Frequent occurrences point at prompt or schema drift.
+func ToolUnknown(toolName, toolID string, available []string) Event {
+	return NewEvent(EventTypeToolUnknown, map[string]any{
+		"tool_name":       toolName,
+		"tool_id":         toolID,
+		"available_tools": available,
+	})
+}
+
+// ToolArgsInvalid creates an event reporting tool-call arguments that are not
+// valid JSON or don't match the tool's parameters, and whether the call was
+// rejected
+func ToolArgsInvalid(toolName, toolID string, violations []string, rejected bool) Event {
+	return NewEvent(EventTypeToolArgsInvalid, map[string]any{
+		"tool_name":  toolName,
+		"tool_id":    toolID,
+		"violations": violations,
+		"rejected":   rejected,
+	})
+}
+
+// ToolLog creates a log event reported by a tool handler
+func ToolLog(toolName, toolID, level, message string, attributes map[string]any) Event {
+	return NewEvent(EventTypeToolLog, map[string]any{
+		"tool_name":  toolName,
+		"tool_id":    toolID,
+		"level":      level,
+		"message":    message,
+		"attributes": attributes,
+	})
+}
+
+// ToolProgress creates a progress event reported by a tool handler
+func ToolProgress(toolName, toolID string, percent float64, message string) Event {
+	return NewEvent(EventTypeToolProgress, map[string]any{
 		"tool_name": toolName,
-		"error":     err.Error(),
+		"tool_id":   toolID,
+		"percent":   percent,
+		"message":   message,
+	})
+}
+
+// ToolArtifactEvent creates an event for an artifact produced by a tool handler
+func ToolArtifactEvent(toolName, toolID string, artifact ToolArtifact) Event {
+	return NewEvent(EventTypeToolArtifact, map[string]any{
+		"tool_name": toolName,
+		"tool_id":   toolID,
+		"artifact":  artifact,
+	})
+}
+
+// ContextCompacted creates an event reporting that conversation history was
+// compacted to fit the model's context window
+func ContextCompacted(reason string, messagesBefore, messagesAfter int) Event {
+	return NewEvent(EventTypeContextCompacted, map[string]any{
+		"reason":          reason,
+		"messages_before": messagesBefore,
+		"messages_after":  messagesAfter,
+	})
+}
+
+// ContextCompactedWithTokens creates a context compacted event that also
+// reports the estimated history tokens before and after compaction
+func ContextCompactedWithTokens(reason string, messagesBefore, messagesAfter, tokensBefore, tokensAfter int) Event {
+	return NewEvent(EventTypeContextCompacted, map[string]any{
+		"reason":          reason,
+		"messages_before": messagesBefore,
+		"messages_after":  messagesAfter,
+		"tokens_before":   tokensBefore,
+		"tokens_after":    tokensAfter,
+	})
+}
+
+// ServerStateLost creates an event reporting that a stored response could
+// not be continued (reason "expired" or "not_found") and the full history
+// of the given number of messages was sent instead
+func ServerStateLost(previousResponseID, reason string, messages int) Event {
+	return NewEvent(EventTypeServerStateLost, map[string]any{
+		"previous_response_id": previousResponseID,
+		"reason":               reason,
+		"messages":             messages,
+	})
+}
+
+// PromptBudgetReport creates an event breaking down the estimated prompt
+// tokens of the next model call by instructions, tools, history and input
+func PromptBudgetReport(model string, budget PromptBudget) Event {
+	return NewEvent(EventTypePromptBudget, map[string]any{
+		"model":               model,
+		"instructions_tokens": budget.Instructions,
+		"tools_tokens":        budget.Tools,
+		"history_tokens":      budget.History,
+		"input_tokens":        budget.Input,
+		"total_tokens":        budget.Total(),
+	})
+}
+
+// GuardViolation creates an event reporting that a guard found violations in
+// the final answer and what it did about them
+func GuardViolation(guard, action string, violations any, unresolved int) Event {
+	return NewEvent(EventTypeGuardViolation, map[string]any{
+		"guard":      guard,
+		"action":     action,
+		"violations": violations,
+		"unresolved": unresolved,
+	})
+}
+
+// ModerationFlagged creates an event reporting that the moderator flagged
+// the user input or the final answer ("input" or "output" stage), and
+// whether the run was stopped
+func ModerationFlagged(stage string, categories []string, blocked bool) Event {
+	return NewEvent(EventTypeModerationFlagged, map[string]any{
+		"stage":      stage,
+		"categories": categories,
+		"blocked":    blocked,
+	})
+}
+
+// ModelFallback creates an event reporting that a request moved from one
+// model to the next in the fallback chain, and why
+func ModelFallback(fromModel, toModel string, reason ErrorCode, err error) Event {
+	return NewEvent(EventTypeModelFallback, map[string]any{
+		"from_model": fromModel,
+		"to_model":   toModel,
+		"reason":     string(reason),
+		"error":      err.Error(),
+	})
+}
+
+// StreamStalled creates an event reporting that a stream sent no chunk for
+// idle and was aborted after the given number of chunks. The call is
+// retried without streaming, so clients should discard its partial output.
+func StreamStalled(model string, idle time.Duration, chunks int) Event {
+	return NewEvent(EventTypeStreamStalled, map[string]any{
+		"model":           model,
+		"idle_ms":         idle.Milliseconds(),
+		"chunks_received": chunks,
+	})
+}
+
+// OutputInvalid creates an event reporting that a final answer did not match
+// the output schema and a corrected one was requested.
+func OutputInvalid(model string, problems []string, repair int) Event {
+	return NewEvent(EventTypeOutputInvalid, map[string]any{
+		"model":    model,
+		"problems": problems,
+		"repair":   repair,
+	})
+}
+
+// DocumentPatched creates an event for a patch applied to a Document,
+// bringing it to version
+func DocumentPatched(name string, version int, format PatchFormat, patch string) Event {
+	return NewEvent(EventTypeDocumentPatched, map[string]any{
+		"name":    name,
+		"version": version,
+		"format":  string(format),
+		"patch":   patch,
 	})
 }
 
@@ -151,13 +464,17 @@ func Decision(action string, confidence float64, reasoning string) Event {
 
 // ApprovalRequired creates an approval required event
 func ApprovalRequired(request ApprovalRequest) Event {
-	return NewEvent(EventTypeApprovalRequired, map[string]any{
+	data := map[string]any{
 		"tool_name":       request.ToolName,
 		"arguments":       request.Arguments,
 		"description":     request.Description,
 		"conversation_id": request.ConversationID,
 		"call_id":         request.CallID,
-	})
+	}
+	if request.Preview != nil {
+		data["preview"] = *request.Preview
+	}
+	return NewEvent(EventTypeApprovalRequired, data)
 }
 
 // ApprovalNeeded is an alias for ApprovalRequired
@@ -226,6 +543,69 @@ func AgentCompleteWithUsage(agentName, output string, usage providers.TokenUsage
 	return NewEvent(EventTypeAgentComplete, data)
 }
 
+// CostUpdated creates a cost.update event with incremental and cumulative usage.
+// Cost fields are omitted when pricing for the model is unknown.
+func CostUpdated(update CostUpdate) Event {
+	data := map[string]any{
+		"model":                 update.Model,
+		"prompt_tokens":         update.Usage.PromptTokens,
+		"completion_tokens":     update.Usage.CompletionTokens,
+		"total_tokens":          update.Usage.TotalTokens,
+		"run_prompt_tokens":     update.RunUsage.PromptTokens,
+		"run_completion_tokens": update.RunUsage.CompletionTokens,
+		"run_total_tokens":      update.RunUsage.TotalTokens,
+	}
+	if update.Usage.ReasoningTokens > 0 {
+		data["reasoning_tokens"] = update.Usage.ReasoningTokens
+	}
+	if update.Cost != nil {
+		data["cost"] = update.Cost.TotalCost
+		data["run_cost"] = update.RunCost
+	}
+	if update.ConversationID != "" {
+		data["conversation_id"] = update.ConversationID
+		data["conversation_total_tokens"] = update.ConversationUsage.TotalTokens
+		if update.Cost != nil || update.ConversationCost > 0 {
+			data["conversation_cost"] = update.ConversationCost
+		}
+	}
+	return NewEvent(EventTypeCostUpdate, data)
+}
+
+// QuotaWarning creates a quota warning event for a crossed QuotaMonitor threshold
+func QuotaWarning(alert QuotaAlert) Event {
+	return NewEvent(EventTypeQuotaWarning, map[string]any{
+		"provider":  alert.Provider,
+		"model":     alert.Model,
+		"resource":  string(alert.Resource),
+		"remaining": alert.Remaining,
+		"limit":     alert.Limit,
+		"threshold": alert.Threshold,
+		"reset_ms":  alert.ResetIn.Milliseconds(),
+	})
+}
+
+// CostAnomaly creates a cost anomaly event for a CostMonitor alert
+func CostAnomaly(alert CostAlert) Event {
+	data := map[string]any{
+		"scope":  string(alert.Scope),
+		"cost":   alert.Cost,
+		"tokens": alert.Tokens,
+	}
+	if alert.ID != "" {
+		data["id"] = alert.ID
+	}
+	if alert.Ratio > 0 {
+		data["median"] = alert.Median
+		data["ratio"] = alert.Ratio
+		data["unit"] = alert.Unit
+	}
+	if alert.Limit > 0 {
+		data["limit"] = alert.Limit
+	}
+	return NewEvent(EventTypeCostAnomaly, data)
+}
+
 // HandoffStart creates a handoff start event
 func HandoffStart(fromAgent, toAgent, task, reason string) Event {
 	return NewEvent(EventTypeHandoffStart, map[string]any{
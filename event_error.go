@@ -0,0 +1,83 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/darkostanimirovic/agentkit/internal/retry"
+)
+
+// ErrorCode classifies the error carried by an error event.
+type ErrorCode string
+
+const (
+	ErrorCodeUnknown       ErrorCode = "unknown"
+	ErrorCodeCanceled      ErrorCode = "canceled"
+	ErrorCodeTimeout       ErrorCode = "timeout"
+	ErrorCodeRateLimited   ErrorCode = "rate_limited"
+	ErrorCodeServer        ErrorCode = "server_error"
+	ErrorCodeTool          ErrorCode = "tool_error"
+	ErrorCodeHandoff       ErrorCode = "handoff_failed"
+	ErrorCodeCollaboration ErrorCode = "collaboration_failed"
+)
+
+// ErrorDetail is the structured payload of an error event. Code, Message,
+// Retryable and Tool travel in Event.Data ("code", "error", "retryable",
+// "tool_name"); Err, the original error, is only available in-process.
+//
+// ErrorDetail wraps Err, so errors.Is and errors.As see through it to the
+// original error after it crosses a handoff or collaboration.
+type ErrorDetail struct {
+	Code      ErrorCode
+	Message   string
+	Retryable bool
+	Tool      string
+	Err       error
+}
+
+func (d *ErrorDetail) Error() string { return d.Message }
+
+func (d *ErrorDetail) Unwrap() error { return d.Err }
+
+// NewErrorDetail classifies err. Details already present in err's chain,
+// e.g. from a delegated agent, keep their code and retryability.
+func NewErrorDetail(err error) *ErrorDetail {
+	detail := &ErrorDetail{Code: ErrorCodeUnknown, Message: err.Error(), Err: err}
+	var inner *ErrorDetail
+	switch {
+	case errors.As(err, &inner):
+		detail.Code, detail.Retryable, detail.Tool = inner.Code, inner.Retryable, inner.Tool
+	case errors.Is(err, context.Canceled):
+		detail.Code = ErrorCodeCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, retry.ErrTimeout):
+		detail.Code, detail.Retryable = ErrorCodeTimeout, true
+	case errors.Is(err, retry.ErrRateLimited):
+		detail.Code, detail.Retryable = ErrorCodeRateLimited, true
+	case errors.Is(err, retry.ErrServerError):
+		detail.Code, detail.Retryable = ErrorCodeServer, true
+	case errors.Is(err, ErrHandoffExecutionFail):
+		detail.Code = ErrorCodeHandoff
+	case errors.Is(err, ErrCollaborationFailed):
+		detail.Code = ErrorCodeCollaboration
+	}
+	return detail
+}
+
+// ErrorDetail returns the payload of an error event. It also works for
+// events decoded from JSON, in which case Err is nil.
+func (e Event) ErrorDetail() (*ErrorDetail, bool) {
+	if e.Type != EventTypeError {
+		return nil, false
+	}
+	if e.err != nil {
+		return e.err, true
+	}
+	detail := &ErrorDetail{Code: ErrorCodeUnknown}
+	detail.Message, _ = e.Data["error"].(string)
+	if code, ok := e.Data["code"].(string); ok && code != "" {
+		detail.Code = ErrorCode(code)
+	}
+	detail.Retryable, _ = e.Data["retryable"].(bool)
+	detail.Tool, _ = e.Data["tool_name"].(string)
+	return detail, true
+}
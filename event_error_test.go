@@ -0,0 +1,106 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/internal/retry"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// failingProvider fails every call with err.
+type failingProvider struct {
+	*mockprovider.Provider
+	err error
+}
+
+func (p *failingProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	return nil, p.err
+}
+
+func (p *failingProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	return nil, p.err
+}
+
+type billingError struct{ account string }
+
+func (e *billingError) Error() string { return "billing disabled for " + e.account }
+
+func TestError_Detail(t *testing.T) {
+	tests := []struct {
+		err       error
+		code      ErrorCode
+		retryable bool
+	}{
+		{errors.New("boom"), ErrorCodeUnknown, false},
+		{fmt.Errorf("call: %w", retry.ErrRateLimited), ErrorCodeRateLimited, true},
+		{context.DeadlineExceeded, ErrorCodeTimeout, true},
+		{context.Canceled, ErrorCodeCanceled, false},
+		{fmt.Errorf("%w: x", ErrHandoffExecutionFail), ErrorCodeHandoff, false},
+	}
+	for _, tt := range tests {
+		event := Error(tt.err)
+		detail, ok := event.ErrorDetail()
+		if !ok {
+			t.Fatalf("ErrorDetail() not ok for %v", tt.err)
+		}
+		if detail.Code != tt.code || detail.Retryable != tt.retryable || detail.Message != tt.err.Error() {
+			t.Errorf("detail for %v = %+v", tt.err, detail)
+		}
+		if !errors.Is(detail, tt.err) {
+			t.Errorf("detail does not wrap %v", tt.err)
+		}
+		if event.Data["code"] != string(tt.code) || event.Data["retryable"] != tt.retryable || event.Data["error"] != tt.err.Error() {
+			t.Errorf("data for %v = %v", tt.err, event.Data)
+		}
+	}
+
+	if _, ok := Thinking("x").ErrorDetail(); ok {
+		t.Error("ErrorDetail() ok for a non-error event")
+	}
+}
+
+func TestError_DetailAfterJSON(t *testing.T) {
+	raw, err := json.Marshal(ToolError("search", fmt.Errorf("upstream: %w", retry.ErrServerError)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event Event
+	if err := json.Unmarshal(raw, &event); err != nil {
+		t.Fatal(err)
+	}
+	detail, ok := event.ErrorDetail()
+	if !ok || detail.Code != ErrorCodeServer || !detail.Retryable || detail.Tool != "search" || detail.Err != nil {
+		t.Fatalf("detail = %+v", detail)
+	}
+
+	if detail, _ := ToolError("search", errors.New("bad input")).ErrorDetail(); detail.Code != ErrorCodeTool {
+		t.Errorf("tool error code = %s", detail.Code)
+	}
+}
+
+func TestHandoff_PreservesTypedErrors(t *testing.T) {
+	cause := &billingError{account: "acme"}
+	from := newMockAgent(t, mockprovider.New())
+	to, err := New(Config{Provider: &failingProvider{Provider: mockprovider.New(), err: cause}, Model: "test-model"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = from.Handoff(context.Background(), to, "charge the customer")
+	if !errors.Is(err, ErrHandoffExecutionFail) {
+		t.Fatalf("Handoff() error = %v, want ErrHandoffExecutionFail", err)
+	}
+	var billing *billingError
+	if !errors.As(err, &billing) || billing.account != "acme" {
+		t.Fatalf("Handoff() error = %v, want to wrap *billingError", err)
+	}
+	var detail *ErrorDetail
+	if !errors.As(err, &detail) || detail.Code != ErrorCodeUnknown {
+		t.Fatalf("Handoff() error = %v, want to wrap *ErrorDetail", err)
+	}
+}
@@ -0,0 +1,178 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventSchema identifies the envelope format published by BrokerSink.
+// Consumers should check it before decoding Data.
+const EventSchema = "agentkit.event.v1"
+
+const defaultSinkBuffer = 1024
+
+// EventSink receives every event an agent emits. Publish is called on the
+// run's goroutine, so implementations must return quickly.
+type EventSink interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// EventSinkFunc adapts a function to EventSink.
+type EventSinkFunc func(ctx context.Context, event Event)
+
+// Publish implements EventSink.
+func (f EventSinkFunc) Publish(ctx context.Context, event Event) {
+	f(ctx, event)
+}
+
+// EventEnvelope is the versioned payload written to message brokers.
+type EventEnvelope struct {
+	Schema         string         `json:"schema"`
+	Type           EventType      `json:"type"`
+	Timestamp      time.Time      `json:"timestamp"`
+	ConversationID string         `json:"conversation_id,omitempty"`
+	AgentName      string         `json:"agent_name,omitempty"`
+	TraceID        string         `json:"trace_id,omitempty"`
+	SpanID         string         `json:"span_id,omitempty"`
+	Data           map[string]any `json:"data"`
+}
+
+// NewEventEnvelope wraps event with the routing fields found in ctx.
+func NewEventEnvelope(ctx context.Context, event Event) EventEnvelope {
+	envelope := EventEnvelope{
+		Schema:    EventSchema,
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		TraceID:   event.TraceID,
+		SpanID:    event.SpanID,
+		Data:      event.Data,
+	}
+	envelope.ConversationID, _ = GetConversationID(ctx)
+	if name, ok := event.Data["agent_name"].(string); ok {
+		envelope.AgentName = name
+	} else {
+		envelope.AgentName, _ = GetAgentName(ctx)
+	}
+	return envelope
+}
+
+// PartitionKey returns the key messages are partitioned by: the conversation
+// ID, so one conversation's events stay ordered, falling back to the trace ID.
+func (e EventEnvelope) PartitionKey() string {
+	if e.ConversationID != "" {
+		return e.ConversationID
+	}
+	return e.TraceID
+}
+
+// BrokerPublisher writes one message to a broker topic. Kafka clients map key
+// to the message key (and so the partition); NATS clients map it to a subject
+// token.
+type BrokerPublisher interface {
+	Publish(ctx context.Context, topic string, key, value []byte) error
+}
+
+// BrokerSinkConfig configures a BrokerSink.
+type BrokerSinkConfig struct {
+	Topic string
+	// Types limits publishing to these event types (default: all).
+	Types []EventType
+	// Buffer is how many events may wait for the publisher (default 1024).
+	// Events arriving while the buffer is full are dropped and counted.
+	Buffer int
+	// OnError is called when publishing fails.
+	OnError func(err error)
+}
+
+// BrokerSink is an EventSink that publishes EventEnvelopes to a message
+// broker from a background goroutine, so a slow broker never blocks a run.
+type BrokerSink struct {
+	publisher BrokerPublisher
+	cfg       BrokerSinkConfig
+	types     map[EventType]bool
+	mu        sync.RWMutex
+	closed    bool
+	queue     chan brokerMessage
+	dropped   atomic.Int64
+	done      chan struct{}
+}
+
+type brokerMessage struct {
+	ctx      context.Context
+	envelope EventEnvelope
+}
+
+// NewBrokerSink starts a sink publishing through publisher. Call Close to
+// flush buffered events.
+func NewBrokerSink(publisher BrokerPublisher, cfg BrokerSinkConfig) *BrokerSink {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = defaultSinkBuffer
+	}
+	s := &BrokerSink{
+		publisher: publisher,
+		cfg:       cfg,
+		queue:     make(chan brokerMessage, cfg.Buffer),
+		done:      make(chan struct{}),
+	}
+	if len(cfg.Types) > 0 {
+		s.types = make(map[EventType]bool, len(cfg.Types))
+		for _, t := range cfg.Types {
+			s.types[t] = true
+		}
+	}
+	go s.loop()
+	return s
+}
+
+// Publish implements EventSink.
+func (s *BrokerSink) Publish(ctx context.Context, event Event) {
+	if s.types != nil && !s.types[event.Type] {
+		return
+	}
+	envelope := NewEventEnvelope(ctx, event)
+	envelope.Data = maps.Clone(envelope.Data)
+	msg := brokerMessage{ctx: context.WithoutCancel(ctx), envelope: envelope}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- msg:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Dropped returns how many events were discarded because the buffer was full.
+func (s *BrokerSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops accepting events and waits until buffered events are published.
+func (s *BrokerSink) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+	<-s.done
+}
+
+func (s *BrokerSink) loop() {
+	defer close(s.done)
+	for msg := range s.queue {
+		value, err := json.Marshal(msg.envelope)
+		if err == nil {
+			err = s.publisher.Publish(msg.ctx, s.cfg.Topic, []byte(msg.envelope.PartitionKey()), value)
+		}
+		if err != nil && s.cfg.OnError != nil {
+			s.cfg.OnError(err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// fakeBroker records published messages.
+type fakeBroker struct {
+	mu       sync.Mutex
+	topics   []string
+	keys     []string
+	payloads []EventEnvelope
+}
+
+func (b *fakeBroker) Publish(ctx context.Context, topic string, key, value []byte) error {
+	var envelope EventEnvelope
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.topics = append(b.topics, topic)
+	b.keys = append(b.keys, string(key))
+	b.payloads = append(b.payloads, envelope)
+	return nil
+}
+
+func TestBrokerSink_PublishesRunEvents(t *testing.T) {
+	broker := &fakeBroker{}
+	sink := NewBrokerSink(broker, BrokerSinkConfig{
+		Topic: "agentkit.events",
+		Types: []EventType{EventTypeAgentStart, EventTypeFinalOutput},
+	})
+	agent, err := New(Config{
+		Provider:   mockprovider.New().WithResponse("hello back", nil),
+		Model:      "test-model",
+		AgentName:  "greeter",
+		EventSinks: []EventSink{sink},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.Run(WithConversation(context.Background(), "conv-9"), "hello"), 2*time.Second)
+	sink.Close()
+
+	if len(broker.payloads) != 2 {
+		t.Fatalf("published %d events, want 2 (filtered)", len(broker.payloads))
+	}
+	for i, envelope := range broker.payloads {
+		if envelope.Schema != EventSchema || envelope.ConversationID != "conv-9" || envelope.AgentName != "greeter" {
+			t.Errorf("envelope %d = %+v", i, envelope)
+		}
+		if broker.topics[i] != "agentkit.events" || broker.keys[i] != "conv-9" {
+			t.Errorf("message %d topic=%q key=%q", i, broker.topics[i], broker.keys[i])
+		}
+	}
+	if broker.payloads[1].Type != EventTypeFinalOutput || broker.payloads[1].Data["response"] != "hello back" {
+		t.Errorf("final output envelope = %+v", broker.payloads[1])
+	}
+}
+
+func TestBrokerSink_DropsWhenBufferFull(t *testing.T) {
+	block := make(chan struct{})
+	sink := NewBrokerSink(brokerFunc(func() { <-block }), BrokerSinkConfig{Topic: "t", Buffer: 1})
+
+	for i := 0; i < 5; i++ {
+		sink.Publish(context.Background(), NewEvent(EventTypeProgress, map[string]any{}))
+	}
+	close(block)
+	sink.Close()
+
+	if dropped := sink.Dropped(); dropped < 3 {
+		t.Errorf("Dropped() = %d, want at least 3", dropped)
+	}
+	sink.Publish(context.Background(), NewEvent(EventTypeProgress, nil)) // must not panic after Close
+}
+
+type brokerFunc func()
+
+func (f brokerFunc) Publish(context.Context, string, []byte, []byte) error {
+	f()
+	return nil
+}
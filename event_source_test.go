@@ -0,0 +1,69 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestEventSource_NestedHandoff(t *testing.T) {
+	billing, err := New(WithProvider(mockprovider.New().WithResponse("refunded", nil)), WithModel("test-model"), WithName("billing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	triage, err := New(
+		WithProvider(mockprovider.New().
+			WithResponse("", []providers.ToolCall{{ID: "call-7", Name: "billing", Arguments: map[string]any{"task": "refund order 42"}}}).
+			WithResponse("done", nil)),
+		WithModel("test-model"),
+		WithName("triage"),
+		WithTool(billing.AsHandoffTool("billing", "Handles refunds")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := map[string]EventSource{}
+	for _, e := range collectEvents(triage.Run(context.Background(), "I want a refund"), 2*time.Second) {
+		if e.Type != EventTypeAgentStart {
+			continue
+		}
+		source, ok := e.Source()
+		if !ok {
+			t.Fatalf("agent.start without source: %+v", e)
+		}
+		sources[source.Agent] = source
+	}
+
+	if got := sources["triage"]; got != (EventSource{Agent: "triage", Path: "triage"}) {
+		t.Errorf("triage source = %+v", got)
+	}
+	want := EventSource{Agent: "billing", Path: "triage/billing", Depth: 1, ParentCallID: "call-7"}
+	if got := sources["billing"]; got != want {
+		t.Errorf("billing source = %+v, want %+v", got, want)
+	}
+}
+
+func TestEventSource_AfterJSON(t *testing.T) {
+	event := ActionResult("done", nil)
+	event.Data = EventSource{Agent: "billing", Path: "triage/billing", Depth: 1, ParentCallID: "call-7"}.data()
+	raw, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Event
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	source, ok := decoded.Source()
+	if !ok || source.Depth != 1 || source.Path != "triage/billing" || source.ParentCallID != "call-7" {
+		t.Fatalf("Source() = %+v, %v", source, ok)
+	}
+	if _, ok := Thinking("x").Source(); ok {
+		t.Error("Source() ok for an event without source")
+	}
+}
@@ -23,13 +23,12 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// Add a simple tool
+	// Add a simple tool; its parameters come from weatherArgs
 	agent.AddTool(
-		agentkit.NewTool("get_weather").
-			WithDescription("Get weather information for a location").
-			WithParameter("location", agentkit.String().Required().WithDescription("City name")).
-			WithHandler(weatherHandler).
-			Build(),
+		agentkit.WithTypedHandler(
+			agentkit.NewTool("get_weather").WithDescription("Get weather information for a location"),
+			weatherHandler,
+		).Build(),
 	)
 
 	// Run the agent
@@ -56,13 +55,23 @@ func buildSystemPrompt(ctx context.Context) string {
 	return "You are a helpful weather assistant. Use the get_weather tool to fetch weather information."
 }
 
-func weatherHandler(ctx context.Context, args map[string]any) (any, error) {
-	location := args["location"].(string)
+type weatherArgs struct {
+	Location string `json:"location" required:"true" desc:"City name"`
+}
+
+type weather struct {
+	Location    string `json:"location"`
+	Temperature string `json:"temperature"`
+	Conditions  string `json:"conditions"`
+	Humidity    string `json:"humidity"`
+}
+
+func weatherHandler(ctx context.Context, args weatherArgs) (weather, error) {
 	// In a real app, you'd call a weather API here
-	return map[string]any{
-		"location":    location,
-		"temperature": "72°F",
-		"conditions":  "Sunny",
-		"humidity":    "45%",
+	return weather{
+		Location:    args.Location,
+		Temperature: "72°F",
+		Conditions:  "Sunny",
+		Humidity:    "45%",
 	}, nil
 }
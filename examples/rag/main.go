@@ -5,40 +5,53 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"strings"
 
 	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/providers/openai"
+	"github.com/darkostanimirovic/agentkit/retrieval"
 )
 
-// Simulated vector database
-var knowledgeBase = map[string]string{
-	"go_concurrency":    "Go uses goroutines and channels for concurrency. Goroutines are lightweight threads managed by the Go runtime.",
-	"go_interfaces":     "Interfaces in Go provide a way to specify the behavior of an object. They are implicit and satisfied automatically.",
-	"go_error_handling": "Go uses explicit error handling with the error type. Functions return errors as values to be checked by the caller.",
+var documents = []retrieval.Record{
+	{ID: "go_concurrency", Content: "Go uses goroutines and channels for concurrency. Goroutines are lightweight threads managed by the Go runtime."},
+	{ID: "go_interfaces", Content: "Interfaces in Go provide a way to specify the behavior of an object. They are implicit and satisfied automatically."},
+	{ID: "go_error_handling", Content: "Go uses explicit error handling with the error type. Functions return errors as values to be checked by the caller."},
 }
 
 func main() {
+	ctx := context.Background()
+	embedder := openai.NewEmbedder(os.Getenv("OPENAI_API_KEY"), openai.DefaultEmbeddingModel)
+
+	// Swap in retrieval.NewPgVectorStore or retrieval.NewQdrantStore for a
+	// persistent store; the tool works with any retrieval.VectorStore.
+	store := retrieval.NewMemoryStore()
+	texts := make([]string, len(documents))
+	for i, doc := range documents {
+		texts[i] = doc.Content
+	}
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i := range documents {
+		documents[i].Vector = vectors[i]
+	}
+	if err := store.Upsert(ctx, documents...); err != nil {
+		log.Fatal(err)
+	}
+
 	agent, err := agentkit.New(agentkit.Config{
 		APIKey: os.Getenv("OPENAI_API_KEY"),
 		Model:  "gpt-4o-mini",
 		SystemPrompt: func(ctx context.Context) string {
-			return "You are a Go programming expert with access to a knowledge base. Use the retrieve_context tool to get relevant information before answering."
+			return "You are a Go programming expert with access to a knowledge base. Use the search_knowledge_base tool to get relevant information before answering."
 		},
 	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Add RAG retrieval tool
-	agent.AddTool(
-		agentkit.NewTool("retrieve_context").
-			WithDescription("Search the knowledge base for relevant Go programming information").
-			WithParameter("query", agentkit.String().Required().WithDescription("Search query")).
-			WithHandler(retrieveHandler).
-			Build(),
-	)
+	agent.AddTool(agentkit.NewRetrievalTool(store, embedder, agentkit.WithRetrievalTopK(2)))
 
-	ctx := context.Background()
 	events := agent.Run(ctx, "How does error handling work in Go?")
 
 	for event := range events {
@@ -54,28 +67,3 @@ func main() {
 		}
 	}
 }
-
-func retrieveHandler(ctx context.Context, args map[string]any) (any, error) {
-	query := strings.ToLower(args["query"].(string))
-
-	// Simple keyword matching for demo purposes
-	var results []string
-	for key, content := range knowledgeBase {
-		if strings.Contains(key, query) || strings.Contains(strings.ToLower(content), query) {
-			results = append(results, content)
-		}
-	}
-
-	if len(results) == 0 {
-		return map[string]any{
-			"found":   false,
-			"message": "No relevant information found",
-		}, nil
-	}
-
-	return map[string]any{
-		"found":  true,
-		"chunks": results,
-		"count":  len(results),
-	}, nil
-}
@@ -39,13 +39,10 @@ func main() {
 
 	// Register a tool
 	agent.AddTool(
-		agentkit.NewTool("get_weather").
-			WithDescription("Get current weather for a location").
-			WithParameter("location", agentkit.String().
-				Required().
-				WithDescription("City name")).
-			WithHandler(getWeather).
-			Build(),
+		agentkit.WithTypedHandler(
+			agentkit.NewTool("get_weather").WithDescription("Get current weather for a location"),
+			getWeather,
+		).Build(),
 	)
 
 	// Run agent with tracing enabled
@@ -77,11 +74,14 @@ func buildSystemPrompt(ctx context.Context) string {
 	return "You are a helpful weather assistant."
 }
 
-func getWeather(ctx context.Context, args map[string]any) (any, error) {
-	location := args["location"].(string)
+type weatherArgs struct {
+	Location string `json:"location" required:"true" desc:"City name"`
+}
+
+func getWeather(ctx context.Context, args weatherArgs) (map[string]any, error) {
 	// Simulate API call
 	return map[string]any{
-		"location":    location,
+		"location":    args.Location,
 		"temperature": "72°F",
 		"condition":   "Sunny",
 		"humidity":    "45%",
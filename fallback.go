@@ -0,0 +1,105 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ModelSpec names a model and the provider serving it, for FallbackModels.
+type ModelSpec struct {
+	// Provider serves Model. Nil uses the agent's provider.
+	Provider providers.Provider
+	Model    string
+}
+
+// resolveFallbacks fills in the agent's provider for fallback specs without
+// one and puts the others behind the agent's admission queue.
+func resolveFallbacks(cfg Config, provider providers.Provider) []ModelSpec {
+	if len(cfg.FallbackModels) == 0 {
+		return nil
+	}
+	fallbacks := make([]ModelSpec, len(cfg.FallbackModels))
+	for i, spec := range cfg.FallbackModels {
+		switch {
+		case spec.Provider == nil:
+			spec.Provider = provider
+		case cfg.Admission != nil:
+			spec.Provider = &admissionProvider{Provider: spec.Provider, queue: cfg.Admission, priority: cfg.Priority}
+		}
+		fallbacks[i] = spec
+	}
+	return fallbacks
+}
+
+// interruptedStreamError marks a stream that failed after emitting output.
+// A fallback model cannot take it over without repeating that output.
+type interruptedStreamError struct{ error }
+
+func (e interruptedStreamError) Unwrap() error { return e.error }
+
+// fallbackReason reports whether err is a rate limit, server error or
+// timeout that the next model in the fallback chain should retry.
+func fallbackReason(err error) (ErrorCode, bool) {
+	var interrupted interruptedStreamError
+	if errors.As(err, &interrupted) || errors.Is(err, context.Canceled) {
+		return "", false
+	}
+	switch code := NewErrorDetail(err).Code; code {
+	case ErrorCodeTimeout, ErrorCodeRateLimited, ErrorCodeServer:
+		return code, true
+	}
+	status, ok := providers.StatusCode(err)
+	switch {
+	case !ok:
+		return "", false
+	case status == http.StatusTooManyRequests:
+		return ErrorCodeRateLimited, true
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return ErrorCodeTimeout, true
+	case status >= 500:
+		return ErrorCodeServer, true
+	}
+	return "", false
+}
+
+// runWithFallback runs an iteration on req.Model and, when it fails with an
+// error fallbackReason accepts, sends the same request to each of the
+// agent's fallback models in turn. It returns the model that answered.
+func (a *Agent) runWithFallback(ctx context.Context, req providers.CompletionRequest, events chan<- Event) (*providers.CompletionResponse, string, error) {
+	if len(a.fallbacks) == 0 {
+		resp, err := a.runIteration(ctx, a.provider, req, events)
+		return resp, req.Model, err
+	}
+
+	chain := append([]ModelSpec{{Provider: a.provider, Model: req.Model}}, a.fallbacks...)
+	last := len(chain) - 1
+	for i, spec := range chain[:last] {
+		if i > 0 {
+			req = unchainedRequest(ctx, req)
+		}
+		req.Model = spec.Model
+		resp, err := a.runIteration(context.WithValue(ctx, fallbackKey, true), spec.Provider, req, events)
+		if err == nil {
+			return resp, spec.Model, nil
+		}
+		reason, ok := fallbackReason(err)
+		if !ok {
+			return nil, spec.Model, err
+		}
+		if ctx.Err() != nil {
+			// The run itself was canceled or timed out.
+			a.emit(ctx, events, Error(err))
+			return nil, spec.Model, err
+		}
+		next := chain[i+1].Model
+		a.logger.Warn("falling back to next model", "from", spec.Model, "to", next, "reason", reason, "error", err)
+		a.emit(ctx, events, ModelFallback(spec.Model, next, reason, err))
+	}
+	req = unchainedRequest(ctx, req)
+	req.Model = chain[last].Model
+	resp, err := a.runIteration(ctx, chain[last].Provider, req, events)
+	return resp, req.Model, err
+}
@@ -0,0 +1,163 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/internal/retry"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// statusProvider fails every call with an API error carrying status.
+type statusProvider struct {
+	*mockprovider.Provider
+	status int
+	models []string
+}
+
+func (p *statusProvider) Complete(_ context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	p.models = append(p.models, req.Model)
+	return nil, fmt.Errorf("API error (status %d): upstream unavailable", p.status)
+}
+
+func (p *statusProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	_, err := p.Complete(ctx, req)
+	return nil, err
+}
+
+func TestFallbackModels_FailsOverToNextModel(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		primary := &statusProvider{Provider: mockprovider.New(), status: 429}
+		backup := &recordingProvider{Provider: mockprovider.New().WithResponse("from backup", nil).WithStream([]providers.StreamChunk{
+			{Content: "from backup"},
+			{IsComplete: true, FinishReason: providers.FinishReasonStop, Usage: &providers.TokenUsage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30}},
+		})}
+		agent, err := New(
+			WithProvider(primary),
+			WithModel("gpt-4o"),
+			WithStreamResponses(streaming),
+			WithFallbackModels(ModelSpec{Provider: backup, Model: "gpt-4o-mini"}),
+		)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		var fallbacks, errs []Event
+		var output, costModel string
+		for _, e := range collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second) {
+			switch e.Type {
+			case EventTypeModelFallback:
+				fallbacks = append(fallbacks, e)
+			case EventTypeError:
+				errs = append(errs, e)
+			case EventTypeCostUpdate:
+				costModel, _ = e.Data["model"].(string)
+			case EventTypeFinalOutput:
+				output, _ = e.Data["response"].(string)
+			}
+		}
+
+		if output != "from backup" || len(errs) != 0 {
+			t.Errorf("streaming=%v: output = %q, errors = %v", streaming, output, errs)
+		}
+		if len(fallbacks) != 1 || fallbacks[0].Data["from_model"] != "gpt-4o" || fallbacks[0].Data["to_model"] != "gpt-4o-mini" || fallbacks[0].Data["reason"] != "rate_limited" {
+			t.Errorf("streaming=%v: fallback events = %v", streaming, fallbacks)
+		}
+		if costModel != "gpt-4o-mini" {
+			t.Errorf("streaming=%v: usage priced at %q, want the fallback model", streaming, costModel)
+		}
+		if !streaming && (len(backup.requests) != 1 || backup.requests[0].Model != "gpt-4o-mini") {
+			t.Errorf("backup requests = %+v", backup.requests)
+		}
+	}
+}
+
+func TestFallbackModels_ExhaustedChainReportsLastError(t *testing.T) {
+	primary := &statusProvider{Provider: mockprovider.New(), status: 503}
+	agent, err := New(
+		WithProvider(primary),
+		WithModel("test-model"),
+		WithStreamResponses(false),
+		WithFallbackModels(ModelSpec{Model: "backup-1"}, ModelSpec{Model: "backup-2"}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var fallbacks, errs int
+	for _, e := range collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second) {
+		switch e.Type {
+		case EventTypeModelFallback:
+			fallbacks++
+		case EventTypeError:
+			errs++
+		}
+	}
+	if fallbacks != 2 || errs != 1 {
+		t.Errorf("fallback events = %d, error events = %d; want 2 and 1", fallbacks, errs)
+	}
+	if fmt.Sprint(primary.models) != "[test-model backup-1 backup-2]" {
+		t.Errorf("models tried = %v", primary.models)
+	}
+}
+
+func TestFallbackModels_ClientErrorDoesNotFallBack(t *testing.T) {
+	primary := &statusProvider{Provider: mockprovider.New(), status: 400}
+	agent, err := New(
+		WithProvider(primary),
+		WithModel("test-model"),
+		WithStreamResponses(false),
+		WithFallbackModels(ModelSpec{Model: "backup"}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var errs int
+	for _, e := range collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second) {
+		if e.Type == EventTypeModelFallback {
+			t.Errorf("unexpected fallback: %v", e.Data)
+		}
+		if e.Type == EventTypeError {
+			errs++
+		}
+	}
+	if errs != 1 || len(primary.models) != 1 {
+		t.Errorf("error events = %d, models tried = %v", errs, primary.models)
+	}
+}
+
+func TestFallbackReason(t *testing.T) {
+	tests := []struct {
+		err    error
+		reason ErrorCode
+		ok     bool
+	}{
+		{fmt.Errorf("API error (status 429): slow down"), ErrorCodeRateLimited, true},
+		{fmt.Errorf("API error (status 529): overloaded (type: overloaded_error)"), ErrorCodeServer, true},
+		{fmt.Errorf("API error (status 504): gateway timeout"), ErrorCodeTimeout, true},
+		{fmt.Errorf("provider completion error: %w", context.DeadlineExceeded), ErrorCodeTimeout, true},
+		{retry.ErrServerError, ErrorCodeServer, true},
+		{fmt.Errorf("API error (status 401): bad key"), "", false},
+		{context.Canceled, "", false},
+		{interruptedStreamError{fmt.Errorf("stream read error: %w", context.DeadlineExceeded)}, "", false},
+		{errors.New("boom"), "", false},
+	}
+	for _, tt := range tests {
+		reason, ok := fallbackReason(tt.err)
+		if reason != tt.reason || ok != tt.ok {
+			t.Errorf("fallbackReason(%v) = %q, %v; want %q, %v", tt.err, reason, ok, tt.reason, tt.ok)
+		}
+	}
+}
+
+func TestConfigValidate_FallbackModelNeedsModel(t *testing.T) {
+	err := Config{APIKey: "key", Model: "gpt-4o", FallbackModels: []ModelSpec{{}}}.Validate()
+	if !errors.Is(err, ErrInvalidFallbackModel) {
+		t.Errorf("Validate() = %v, want ErrInvalidFallbackModel", err)
+	}
+}
@@ -0,0 +1,117 @@
+package agentkit
+
+import (
+	"context"
+
+	"github.com/darkostanimirovic/agentkit/flags"
+)
+
+// FlagConfig connects an agent to a feature flag provider so prompt, tool and
+// model changes can be rolled out to a share of users. Flags are evaluated
+// once per run for the user from WithUser (or the conversation ID), and the
+// assignments are recorded on the trace as "flag.<name>" attributes so the
+// cohorts can be compared.
+type FlagConfig struct {
+	Provider flags.Provider
+	// ModelFlag names a string flag; a non-empty value replaces Config.Model.
+	ModelFlag string
+	// PromptFlag names a string flag whose value selects a prompt from Prompts.
+	// Unknown or empty values keep the default system prompt.
+	PromptFlag string
+	Prompts    map[string]SystemPromptFunc
+	// ToolFlags maps tool names to boolean flags; a tool is offered to the
+	// model only while its flag is on.
+	ToolFlags map[string]string
+}
+
+// runFlags holds the flag assignments for one run.
+type runFlags struct {
+	model         string
+	prompt        SystemPromptFunc
+	disabledTools map[string]bool
+}
+
+const runFlagsKey contextKey = "agentkit_run_flags"
+
+// flagEvalContext builds the flag evaluation context for a run.
+func (a *Agent) flagEvalContext(ctx context.Context) flags.EvalContext {
+	ec := flags.EvalContext{Attributes: map[string]string{"agent": a.agentName}}
+	if id, ok := GetUserID(ctx); ok {
+		ec.Key = id
+		ec.Attributes["user_id"] = id
+	} else if id, ok := GetConversationID(ctx); ok {
+		ec.Key = id
+	}
+	if id, ok := GetConversationID(ctx); ok {
+		ec.Attributes["conversation_id"] = id
+	}
+	return ec
+}
+
+// evaluateFlags resolves the configured flags and stores the assignments in ctx.
+func (a *Agent) evaluateFlags(ctx context.Context) context.Context {
+	if a.flags == nil || a.flags.Provider == nil {
+		return ctx
+	}
+	cfg := a.flags
+	ec := a.flagEvalContext(ctx)
+	assignments := make(map[string]any)
+	rf := &runFlags{}
+
+	if cfg.ModelFlag != "" {
+		rf.model = cfg.Provider.StringVariation(ctx, cfg.ModelFlag, ec, "")
+		assignments["flag."+cfg.ModelFlag] = rf.model
+	}
+	if cfg.PromptFlag != "" {
+		variant := cfg.Provider.StringVariation(ctx, cfg.PromptFlag, ec, "")
+		rf.prompt = cfg.Prompts[variant]
+		assignments["flag."+cfg.PromptFlag] = variant
+	}
+	for tool, flag := range cfg.ToolFlags {
+		on := cfg.Provider.BoolVariation(ctx, flag, ec, false)
+		if !on {
+			if rf.disabledTools == nil {
+				rf.disabledTools = make(map[string]bool)
+			}
+			rf.disabledTools[tool] = true
+		}
+		assignments["flag."+flag] = on
+	}
+
+	if len(assignments) > 0 {
+		if err := a.tracer.SetTraceAttributes(ctx, assignments); err != nil {
+			a.logger.Debug("failed to record flag assignments", "error", err)
+		}
+		a.logger.Debug("feature flags evaluated", "key", ec.Key, "assignments", assignments)
+	}
+	return context.WithValue(ctx, runFlagsKey, rf)
+}
+
+func getRunFlags(ctx context.Context) *runFlags {
+	rf, _ := ctx.Value(runFlagsKey).(*runFlags)
+	return rf
+}
+
+// runModel returns the model for this run, honoring ModelFlag and DevReload.
+func (a *Agent) runModel(ctx context.Context) string {
+	if rf := getRunFlags(ctx); rf != nil && rf.model != "" {
+		return rf.model
+	}
+	if overrides := getDevOverrides(ctx); overrides != nil && overrides.settings.Model != "" {
+		return overrides.settings.Model
+	}
+	return a.model
+}
+
+// toolEnabled reports whether a tool is offered in this run: its flag is on
+// and the run is granted its required scopes and allowed it by
+// WithEnabledTools and WithRunTools.
+func toolEnabled(ctx context.Context, tool *Tool) bool {
+	if rf := getRunFlags(ctx); rf != nil && rf.disabledTools[tool.name] {
+		return false
+	}
+	if !runAllowsTool(ctx, tool) || !runOptionsAllowTool(ctx, tool) {
+		return false
+	}
+	return hasScopes(ctx, tool.requiredScopes)
+}
@@ -0,0 +1,173 @@
+// Package flags provides feature flags for rolling out agent changes
+// (prompts, tools, models) to a percentage of users.
+//
+// Provider mirrors the variation calls of hosted flag services such as
+// LaunchDarkly, so an adapter to one of them is a few lines. Static is a
+// self-contained implementation backed by a map, a JSON file or environment
+// variables.
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidFlag is returned when a flag definition cannot be parsed.
+var ErrInvalidFlag = errors.New("flags: invalid flag")
+
+// EvalContext identifies who a flag is evaluated for. Key is usually the user
+// ID; it determines the rollout bucket, so the same key always gets the same
+// variation of a flag.
+type EvalContext struct {
+	Key        string
+	Attributes map[string]string
+}
+
+// Provider evaluates feature flags. Implementations return fallback when the
+// flag is unknown or its value has the wrong type.
+type Provider interface {
+	BoolVariation(ctx context.Context, flag string, ec EvalContext, fallback bool) bool
+	StringVariation(ctx context.Context, flag string, ec EvalContext, fallback string) string
+}
+
+// Flag is a single flag definition. Keys listed in Keys and the Rollout
+// percentage of all other keys are served On; everyone else gets Off, or the
+// caller's fallback when Off is unset.
+type Flag struct {
+	On      any      `json:"on"`
+	Off     any      `json:"off,omitempty"`
+	Rollout float64  `json:"rollout"`
+	Keys    []string `json:"keys,omitempty"`
+}
+
+// Variation returns the value served to key.
+func (f Flag) Variation(flag, key string) any {
+	for _, k := range f.Keys {
+		if k == key {
+			return f.On
+		}
+	}
+	if Bucket(flag, key) < f.Rollout {
+		return f.On
+	}
+	return f.Off
+}
+
+// Bucket maps flag and key to a stable percentile in [0, 100). Different
+// flags bucket the same key independently.
+func Bucket(flag, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(flag))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) / 100
+}
+
+// Static is a Provider holding flag definitions in memory. It is safe for
+// concurrent use and can be updated while agents are running.
+type Static struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewStatic creates a provider from flag definitions.
+func NewStatic(flags map[string]Flag) *Static {
+	s := &Static{flags: make(map[string]Flag, len(flags))}
+	for name, f := range flags {
+		s.flags[name] = f
+	}
+	return s
+}
+
+// LoadFile reads flag definitions from a JSON file of the form
+// {"new_prompt": {"on": "v2", "off": "v1", "rollout": 25}}.
+func LoadFile(path string) (*Static, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("flags: read %s: %w", path, err)
+	}
+	var defs map[string]Flag
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidFlag, path, err)
+	}
+	return NewStatic(defs), nil
+}
+
+// FromEnv reads flags from environment variables starting with prefix, e.g.
+// with prefix "AGENTKIT_FLAG_", AGENTKIT_FLAG_NEW_PROMPT=v2@25 serves "v2" to
+// 25% of keys under the flag "new_prompt". Without "@percent" the value is
+// served to everyone.
+func FromEnv(prefix string) *Static {
+	s := NewStatic(nil)
+	for _, kv := range os.Environ() {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+		f, err := ParseFlag(value)
+		if err != nil {
+			continue
+		}
+		s.Set(strings.ToLower(strings.TrimPrefix(name, prefix)), f)
+	}
+	return s
+}
+
+// ParseFlag parses the "value[@percent]" shorthand used by FromEnv.
+func ParseFlag(s string) (Flag, error) {
+	value, percent, hasPercent := strings.Cut(s, "@")
+	f := Flag{On: value, Rollout: 100}
+	if hasPercent {
+		rollout, err := strconv.ParseFloat(strings.TrimSuffix(percent, "%"), 64)
+		if err != nil || rollout < 0 || rollout > 100 {
+			return Flag{}, fmt.Errorf("%w: rollout %q", ErrInvalidFlag, percent)
+		}
+		f.Rollout = rollout
+	}
+	return f, nil
+}
+
+// Set adds or replaces a flag.
+func (s *Static) Set(name string, f Flag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags[name] = f
+}
+
+func (s *Static) variation(flag string, ec EvalContext) any {
+	s.mu.RLock()
+	f, ok := s.flags[flag]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return f.Variation(flag, ec.Key)
+}
+
+// BoolVariation implements Provider. String values such as "true" are parsed.
+func (s *Static) BoolVariation(ctx context.Context, flag string, ec EvalContext, fallback bool) bool {
+	switch v := s.variation(flag, ec).(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
+// StringVariation implements Provider.
+func (s *Static) StringVariation(ctx context.Context, flag string, ec EvalContext, fallback string) string {
+	if v, ok := s.variation(flag, ec).(string); ok {
+		return v
+	}
+	return fallback
+}
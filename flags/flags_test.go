@@ -0,0 +1,75 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFlag_RolloutIsStableAndProportional(t *testing.T) {
+	f := Flag{On: true, Off: false, Rollout: 25}
+	on := 0
+	for i := 0; i < 4000; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		v := f.Variation("new_prompt", key)
+		if v != f.Variation("new_prompt", key) {
+			t.Fatalf("variation for %s is not stable", key)
+		}
+		if v == true {
+			on++
+		}
+	}
+	if share := float64(on) / 4000; share < 0.22 || share > 0.28 {
+		t.Errorf("rollout share = %.3f, want about 0.25", share)
+	}
+
+	allowlisted := Flag{On: "v2", Rollout: 0, Keys: []string{"qa-user"}}
+	if allowlisted.Variation("new_prompt", "qa-user") != "v2" || allowlisted.Variation("new_prompt", "someone") != nil {
+		t.Error("Keys allowlist not honored")
+	}
+}
+
+func TestStatic_Variations(t *testing.T) {
+	ctx := context.Background()
+	ec := EvalContext{Key: "user-1"}
+	s := NewStatic(map[string]Flag{
+		"search_v2": {On: "true", Rollout: 100},
+		"model":     {On: "gpt-5-mini", Off: "gpt-4o", Rollout: 0},
+	})
+
+	if !s.BoolVariation(ctx, "search_v2", ec, false) {
+		t.Error("string \"true\" should parse as a bool variation")
+	}
+	if got := s.StringVariation(ctx, "model", ec, "fallback"); got != "gpt-4o" {
+		t.Errorf("StringVariation() = %q, want off value", got)
+	}
+	if got := s.StringVariation(ctx, "missing", ec, "fallback"); got != "fallback" {
+		t.Errorf("unknown flag = %q, want fallback", got)
+	}
+}
+
+func TestLoadFileAndFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	if err := os.WriteFile(path, []byte(`{"new_prompt": {"on": "v2", "off": "v1", "rollout": 100}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if got := s.StringVariation(context.Background(), "new_prompt", EvalContext{Key: "u"}, ""); got != "v2" {
+		t.Errorf("file flag = %q", got)
+	}
+
+	t.Setenv("TESTFLAG_WEB_SEARCH", "true@100")
+	t.Setenv("TESTFLAG_BROKEN", "x@lots")
+	env := FromEnv("TESTFLAG_")
+	if !env.BoolVariation(context.Background(), "web_search", EvalContext{Key: "u"}, false) {
+		t.Error("env flag web_search should be on")
+	}
+	if env.StringVariation(context.Background(), "broken", EvalContext{Key: "u"}, "fallback") != "fallback" {
+		t.Error("invalid env flag should be ignored")
+	}
+}
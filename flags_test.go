@@ -0,0 +1,74 @@
+package agentkit
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/flags"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// traceAttributeTracer records trace attributes.
+type traceAttributeTracer struct {
+	NoOpTracer
+	mu         sync.Mutex
+	attributes map[string]any
+	calls      int
+}
+
+func (t *traceAttributeTracer) SetTraceAttributes(ctx context.Context, attributes map[string]any) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.attributes == nil {
+		t.attributes = make(map[string]any)
+	}
+	maps.Copy(t.attributes, attributes)
+	t.calls++
+	return nil
+}
+
+func TestFlags_SelectModelPromptAndTools(t *testing.T) {
+	provider := flags.NewStatic(map[string]flags.Flag{
+		"triage_model":  {On: "gpt-5-mini", Rollout: 0, Keys: []string{"beta-user"}},
+		"triage_prompt": {On: "concise", Rollout: 0, Keys: []string{"beta-user"}},
+		"web_search":    {On: true, Off: false, Rollout: 0, Keys: []string{"beta-user"}},
+	})
+	tracer := &traceAttributeTracer{}
+	recorder := &recordingProvider{Provider: mockprovider.New().WithResponse("a", nil).WithResponse("b", nil)}
+	agent, err := New(Config{
+		Provider:     recorder,
+		Model:        "gpt-4o",
+		SystemPrompt: func(context.Context) string { return "default prompt" },
+		Tracer:       tracer,
+		Flags: &FlagConfig{
+			Provider:   provider,
+			ModelFlag:  "triage_model",
+			PromptFlag: "triage_prompt",
+			Prompts:    map[string]SystemPromptFunc{"concise": func(context.Context) string { return "concise prompt" }},
+			ToolFlags:  map[string]string{"web_search": "web_search"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("web_search").WithDescription("Search").Build())
+	agent.AddTool(NewTool("lookup").WithDescription("Lookup").Build())
+
+	collectEvents(agent.Run(WithUser(context.Background(), "regular-user"), "hi"), 2*time.Second)
+	control := recorder.requests[0]
+	if control.Model != "gpt-4o" || control.SystemPrompt != "default prompt" || len(control.Tools) != 1 {
+		t.Errorf("control request: model=%q prompt=%q tools=%d", control.Model, control.SystemPrompt, len(control.Tools))
+	}
+
+	collectEvents(agent.Run(WithUser(context.Background(), "beta-user"), "hi"), 2*time.Second)
+	treatment := recorder.requests[1]
+	if treatment.Model != "gpt-5-mini" || treatment.SystemPrompt != "concise prompt" || len(treatment.Tools) != 2 {
+		t.Errorf("treatment request: model=%q prompt=%q tools=%d", treatment.Model, treatment.SystemPrompt, len(treatment.Tools))
+	}
+	if tracer.attributes["flag.triage_prompt"] != "concise" || tracer.attributes["flag.web_search"] != true {
+		t.Errorf("trace attributes = %v", tracer.attributes)
+	}
+}
@@ -0,0 +1,131 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/memory"
+)
+
+const maxGraphToolDepth = 4
+
+// GraphMemoryConfig enables knowledge-graph memory on an agent.
+type GraphMemoryConfig struct {
+	Graph *memory.GraphMemory
+	// LearnFromRuns extracts entities and relations from every completed
+	// exchange (user message and final answer). Requires Graph.Extractor.
+	LearnFromRuns bool
+	// DisableInjection stops the subgraph relevant to the user message from
+	// being appended to the system prompt.
+	DisableInjection bool
+	// DisableTools stops graph_neighbors and graph_path from being registered.
+	DisableTools bool
+}
+
+// NewGraphTools returns tools that let the model query a knowledge graph:
+// graph_neighbors lists what is connected to an entity and graph_path finds
+// how two entities are related.
+func NewGraphTools(graph *memory.GraphMemory) []Tool {
+	neighbors := NewTool("graph_neighbors").
+		WithDescription("Look up an entity in the knowledge graph and list the entities and relations connected to it.").
+		WithParameter("entity", String().Required().WithDescription("Entity name, e.g. a person, team, project or product")).
+		WithParameter("depth", Integer().Optional().WithDescription(fmt.Sprintf("Number of hops to follow (default 1, max %d)", maxGraphToolDepth))).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			entity, _ := args["entity"].(string)
+			depth := 1
+			if n, ok := args["depth"].(float64); ok && n > 0 {
+				depth = min(int(n), maxGraphToolDepth)
+			}
+			sub, err := graph.Store.Neighbors(ctx, entity, depth)
+			if errors.Is(err, memory.ErrEntityNotFound) {
+				return map[string]any{"entity": entity, "found": false}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"entity": entity, "found": true, "entities": sub.Entities, "relations": sub.Relations}, nil
+		}).
+		Build()
+
+	path := NewTool("graph_path").
+		WithDescription("Find how two entities in the knowledge graph are connected, as a chain of relations.").
+		WithParameter("from", String().Required().WithDescription("First entity name")).
+		WithParameter("to", String().Required().WithDescription("Second entity name")).
+		WithParameter("max_depth", Integer().Optional().WithDescription(fmt.Sprintf("Longest chain to consider (default and max %d)", maxGraphToolDepth))).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			from, _ := args["from"].(string)
+			to, _ := args["to"].(string)
+			maxDepth := maxGraphToolDepth
+			if n, ok := args["max_depth"].(float64); ok && n > 0 {
+				maxDepth = min(int(n), maxGraphToolDepth)
+			}
+			relations, err := graph.Store.Path(ctx, from, to, maxDepth)
+			if errors.Is(err, memory.ErrNoPath) || errors.Is(err, memory.ErrEntityNotFound) {
+				return map[string]any{"from": from, "to": to, "connected": false, "reason": err.Error()}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"from": from, "to": to, "connected": true, "path": relations}, nil
+		}).
+		Build()
+
+	return []Tool{neighbors, path}
+}
+
+// recallGraphMemory adds the subgraph relevant to userMessage to the system prompt.
+func (a *Agent) recallGraphMemory(ctx context.Context, userMessage string) context.Context {
+	if a.graphMemory == nil || a.graphMemory.DisableInjection {
+		return ctx
+	}
+	sub, err := a.graphMemory.Graph.Recall(ctx, userMessage)
+	if err != nil {
+		a.logger.Warn("graph memory recall failed", "error", err)
+		return ctx
+	}
+	if sub.Empty() {
+		return ctx
+	}
+	return withPromptSection(ctx, "## Known facts (knowledge graph)\n"+sub.String())
+}
+
+// learnGraphMemory extracts entities and relations from a completed exchange.
+// Shadow runs learn nothing.
+func (a *Agent) learnGraphMemory(ctx context.Context, userMessage, output string) {
+	if a.graphMemory == nil || !a.graphMemory.LearnFromRuns || output == "" || IsShadowRun(ctx) {
+		return
+	}
+	source := "run"
+	if id, ok := GetConversationID(ctx); ok {
+		source = id
+	}
+	text := "User: " + userMessage + "\nAssistant: " + output
+	if err := a.graphMemory.Graph.Learn(ctx, text, source); err != nil {
+		a.logger.Warn("graph memory learning failed", "error", err)
+	}
+}
+
+const promptSectionsKey contextKey = "agentkit_prompt_sections"
+
+// withPromptSection appends a section to the system prompt of requests built from ctx.
+func withPromptSection(ctx context.Context, section string) context.Context {
+	existing, _ := ctx.Value(promptSectionsKey).([]string)
+	sections := append(append([]string(nil), existing...), section)
+	return context.WithValue(ctx, promptSectionsKey, sections)
+}
+
+// appendPromptSections adds the sections stored in ctx to prompt.
+func appendPromptSections(ctx context.Context, prompt string) string {
+	sections, _ := ctx.Value(promptSectionsKey).([]string)
+	if len(sections) == 0 {
+		return prompt
+	}
+	parts := make([]string, 0, len(sections)+1)
+	if prompt != "" {
+		parts = append(parts, prompt)
+	}
+	parts = append(parts, sections...)
+	return strings.Join(parts, "\n\n")
+}
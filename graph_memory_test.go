@@ -0,0 +1,104 @@
+package agentkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/memory"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// recordingProvider wraps a mock provider and records every request.
+type recordingProvider struct {
+	*mockprovider.Provider
+	requests []providers.CompletionRequest
+}
+
+func (r *recordingProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	r.requests = append(r.requests, req)
+	return r.Provider.Complete(ctx, req)
+}
+
+// staticExtractor returns a fixed extraction.
+type staticExtractor struct {
+	entities  []memory.Entity
+	relations []memory.Relation
+}
+
+func (s staticExtractor) Extract(context.Context, string) ([]memory.Entity, []memory.Relation, error) {
+	return s.entities, s.relations, nil
+}
+
+func TestGraphMemory_InjectsSubgraphAndLearns(t *testing.T) {
+	store := memory.NewInMemoryGraph()
+	_ = store.Upsert(context.Background(), nil, []memory.Relation{{From: "Ana", Type: "manages", To: "Billing"}})
+	graph := memory.NewGraphMemory(store, staticExtractor{
+		relations: []memory.Relation{{From: "Billing", Type: "owns", To: "Invoices"}},
+	})
+
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("Ana runs billing.", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		SystemPrompt:    func(context.Context) string { return "You are helpful." },
+		StreamResponses: false,
+		GraphMemory:     &GraphMemoryConfig{Graph: graph, LearnFromRuns: true},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, ok := agent.tools["graph_neighbors"]; !ok {
+		t.Error("graph_neighbors tool not registered")
+	}
+	if _, ok := agent.tools["graph_path"]; !ok {
+		t.Error("graph_path tool not registered")
+	}
+
+	collectEvents(agent.Run(WithConversation(context.Background(), "conv-7"), "Who handles billing?"), 2*time.Second)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(provider.requests))
+	}
+	prompt := provider.requests[0].SystemPrompt
+	if !strings.HasPrefix(prompt, "You are helpful.\n\n## Known facts") || !strings.Contains(prompt, "- Ana manages Billing") {
+		t.Errorf("system prompt = %q", prompt)
+	}
+
+	path, err := store.Path(context.Background(), "Ana", "Invoices", 3)
+	if err != nil || len(path) != 2 || path[1].Source != "conv-7" {
+		t.Errorf("learned path = %+v, %v", path, err)
+	}
+}
+
+func TestGraphTools(t *testing.T) {
+	store := memory.NewInMemoryGraph()
+	_ = store.Upsert(context.Background(), nil, []memory.Relation{
+		{From: "Ana", Type: "manages", To: "Billing"},
+		{From: "Billing", Type: "owns", To: "Invoices"},
+	})
+	tools := NewGraphTools(memory.NewGraphMemory(store, nil))
+
+	result, err := tools[0].Execute(context.Background(), `{"entity": "billing"}`)
+	if err != nil {
+		t.Fatalf("graph_neighbors error = %v", err)
+	}
+	if relations := result.(map[string]any)["relations"].([]memory.Relation); len(relations) != 2 {
+		t.Errorf("neighbors relations = %+v", relations)
+	}
+
+	result, err = tools[1].Execute(context.Background(), `{"from": "Ana", "to": "Invoices"}`)
+	if err != nil {
+		t.Fatalf("graph_path error = %v", err)
+	}
+	if result.(map[string]any)["connected"] != true {
+		t.Errorf("graph_path = %v", result)
+	}
+
+	result, _ = tools[1].Execute(context.Background(), `{"from": "Ana", "to": "Nobody"}`)
+	if result.(map[string]any)["connected"] != false {
+		t.Errorf("graph_path to unknown entity = %v", result)
+	}
+}
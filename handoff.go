@@ -259,7 +259,7 @@ func (h *HandoffConfiguration) AsTool(name, description string) Tool {
 			result := &HandoffResult{
 				Response: response,
 				Summary:  summary,
-				Metadata: make(map[string]any),
+				Metadata: map[string]any{"from": h.from.getAgentName(), "to": h.to.getAgentName(), "task": task},
 			}
 
 			if opts.fullContext {
@@ -365,7 +365,7 @@ func (a *Agent) Handoff(ctx context.Context, to *Agent, task string, opts ...Han
 				"error": err.Error(),
 			})
 		}
-		return nil, fmt.Errorf("%w: %v", ErrHandoffExecutionFail, err)
+		return nil, fmt.Errorf("%w: %w", ErrHandoffExecutionFail, err)
 	}
 
 	// Record success metrics
@@ -380,7 +380,7 @@ func (a *Agent) Handoff(ctx context.Context, to *Agent, task string, opts ...Han
 	result := &HandoffResult{
 		Response: response,
 		Summary:  summary,
-		Metadata: make(map[string]any),
+		Metadata: map[string]any{"from": a.getAgentName(), "to": to.getAgentName(), "task": task},
 	}
 
 	if options.fullContext {
@@ -465,8 +465,8 @@ func executeHandoff(ctx context.Context, agent *Agent, task string, opts handoff
 				}
 			}
 		case EventTypeError:
-			if errMsg, ok := event.Data["error"].(string); ok {
-				runErr = fmt.Errorf("%s", errMsg)
+			if detail, ok := event.ErrorDetail(); ok {
+				runErr = detail
 			}
 		}
 	}
@@ -655,7 +655,7 @@ func (a *Agent) AsHandoffTool(name, description string, opts ...HandoffOption) T
 			result := &HandoffResult{
 				Response: response,
 				Summary:  summary,
-				Metadata: make(map[string]any),
+				Metadata: map[string]any{"from": fromAgentName, "to": toAgentName, "task": task},
 			}
 
 			if handoffOpts.fullContext {
@@ -0,0 +1,66 @@
+package agentkit
+
+import (
+	"context"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// HostedTool is a tool the provider runs itself, such as OpenAI's web
+// search, file search and code interpreter. The model calls it without a
+// round trip through the agent; its progress surfaces as tool.hosted events
+// and its citations as annotations on the final output. Providers without
+// hosted tools ignore them.
+type HostedTool = providers.HostedTool
+
+// WebSearchTool lets the model search the web (OpenAI Responses API). Set
+// Options such as "search_context_size" or "user_location" on the result to
+// tune it.
+func WebSearchTool() HostedTool {
+	return HostedTool{Type: providers.HostedToolWebSearch}
+}
+
+// FileSearchTool lets the model search the given OpenAI vector stores.
+func FileSearchTool(vectorStoreIDs ...string) HostedTool {
+	return HostedTool{
+		Type:    providers.HostedToolFileSearch,
+		Options: map[string]any{"vector_store_ids": vectorStoreIDs},
+	}
+}
+
+// CodeInterpreterTool lets the model run Python in a sandboxed container
+// that OpenAI creates automatically.
+func CodeInterpreterTool() HostedTool {
+	return HostedTool{
+		Type:    providers.HostedToolCodeInterpreter,
+		Options: map[string]any{"container": map[string]any{"type": "auto"}},
+	}
+}
+
+// AddHostedTool makes a hosted tool available to the model.
+func (a *Agent) AddHostedTool(tool HostedTool) {
+	a.hostedTools = append(a.hostedTools, tool)
+}
+
+// emitHostedToolCall reports a hosted tool's activity.
+func (a *Agent) emitHostedToolCall(ctx context.Context, events chan<- Event, call providers.HostedToolCall) {
+	a.emit(ctx, events, HostedToolCall(call.Type, call.ID, call.Status, call.Details))
+}
+
+// recordHostedToolCall updates a streamed call's latest status, keeping
+// calls in the order they started.
+func recordHostedToolCall(calls []providers.HostedToolCall, call providers.HostedToolCall) []providers.HostedToolCall {
+	for i := range calls {
+		if calls[i].ID == call.ID {
+			if call.Type == "" {
+				call.Type = calls[i].Type
+			}
+			if call.Details == nil {
+				call.Details = calls[i].Details
+			}
+			calls[i] = call
+			return calls
+		}
+	}
+	return append(calls, call)
+}
@@ -0,0 +1,78 @@
+package agentkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// streamRecordingProvider records the requests of streamed calls.
+type streamRecordingProvider struct {
+	*mockprovider.Provider
+	requests []providers.CompletionRequest
+}
+
+func (r *streamRecordingProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	r.requests = append(r.requests, req)
+	return r.Provider.Stream(ctx, req)
+}
+
+func TestHostedTools_SentAndReported(t *testing.T) {
+	provider := &streamRecordingProvider{Provider: mockprovider.New().WithStream([]providers.StreamChunk{
+		{HostedToolCall: &providers.HostedToolCall{ID: "ws_1", Type: "web_search", Status: "in_progress"}},
+		{HostedToolCall: &providers.HostedToolCall{ID: "ws_1", Type: "web_search", Status: "searching"}},
+		{HostedToolCall: &providers.HostedToolCall{ID: "ws_1", Type: "web_search", Status: "completed", Details: map[string]any{"action": map[string]any{"query": "weather"}}}},
+		{Content: "Sunny."},
+		{IsComplete: true, FinishReason: providers.FinishReasonStop},
+	})}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: true,
+		HostedTools:     []HostedTool{WebSearchTool()},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddHostedTool(FileSearchTool("vs_1", "vs_2"))
+
+	events := collectEvents(agent.Run(context.Background(), "Weather?"), time.Second)
+
+	hosted := provider.requests[0].HostedTools
+	if len(hosted) != 2 || hosted[0].Type != "web_search" || hosted[1].Type != "file_search" {
+		t.Fatalf("hosted tools sent = %+v", hosted)
+	}
+	if ids := hosted[1].Options["vector_store_ids"].([]string); len(ids) != 2 {
+		t.Errorf("vector_store_ids = %v", ids)
+	}
+
+	var statuses []any
+	for _, event := range events {
+		if event.Type == EventTypeToolHosted {
+			if event.Data["tool_type"] != "web_search" || event.Data["tool_id"] != "ws_1" {
+				t.Errorf("tool.hosted data = %v", event.Data)
+			}
+			statuses = append(statuses, event.Data["status"])
+		}
+		if event.Type == EventTypeFinalOutput && event.Data["response"] != "Sunny." {
+			t.Errorf("final output = %v", event.Data["response"])
+		}
+	}
+	if len(statuses) != 3 || statuses[2] != "completed" {
+		t.Errorf("tool.hosted statuses = %v", statuses)
+	}
+}
+
+func TestRecordHostedToolCall_KeepsLatestStatus(t *testing.T) {
+	var calls []providers.HostedToolCall
+	calls = recordHostedToolCall(calls, providers.HostedToolCall{ID: "a", Type: "web_search", Status: "in_progress", Details: map[string]any{"action": "search"}})
+	calls = recordHostedToolCall(calls, providers.HostedToolCall{ID: "b", Type: "file_search", Status: "in_progress"})
+	calls = recordHostedToolCall(calls, providers.HostedToolCall{ID: "a", Status: "completed"})
+
+	if len(calls) != 2 || calls[0].ID != "a" || calls[0].Status != "completed" || calls[0].Type != "web_search" || calls[0].Details == nil {
+		t.Errorf("calls = %+v", calls)
+	}
+}
@@ -0,0 +1,209 @@
+// Command options generates options_gen.go, one With<Field> Option per field
+// of agentkit.Config, so every configuration field is also settable as a
+// functional option for New. Run it with go generate from the module root.
+//
+// Fields pointing to a *Config struct take the struct by value and slice
+// fields are variadic and append. Names that would clash with an existing package-level function are
+// taken from renames; any other clash fails generation.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	source = "agent.go"
+	target = "options_gen.go"
+)
+
+// renames maps Config fields whose With<Field> name is taken by a context
+// helper to the option name used instead.
+var renames = map[string]string{
+	"AgentName": "WithName",
+	"Priority":  "WithDefaultPriority",
+	"Tracer":    "WithTracing",
+}
+
+// skipped fields get no option.
+var skipped = map[string]bool{
+	"LLMProvider": true, // deprecated in favor of Provider
+}
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, source, nil, parser.ParseComments)
+	if err != nil {
+		log.Fatalf("gen: parse %s: %v", source, err)
+	}
+	config := findConfig(file)
+	if config == nil {
+		log.Fatalf("gen: Config not found in %s", source)
+	}
+	taken, err := packageFuncs(fset)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+
+	var body bytes.Buffer
+	used := make(map[string]bool)
+	for _, field := range config.Fields.List {
+		for _, ident := range field.Names {
+			if !ident.IsExported() || skipped[ident.Name] {
+				continue
+			}
+			name := "With" + ident.Name
+			if renamed, ok := renames[ident.Name]; ok {
+				name = renamed
+			}
+			if taken[name] {
+				log.Fatalf("gen: %s for Config.%s clashes with an existing function; add it to renames", name, ident.Name)
+			}
+			ast.Inspect(field.Type, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok {
+					if pkg, ok := sel.X.(*ast.Ident); ok {
+						used[pkg.Name] = true
+					}
+				}
+				return true
+			})
+			writeOption(&body, fset, name, ident.Name, field)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by go run ./internal/gen/options; DO NOT EDIT.\n\npackage agentkit\n\n")
+	if len(used) > 0 {
+		var paths []string
+		for name := range used {
+			path, ok := imports[name]
+			if !ok {
+				log.Fatalf("gen: unknown package %s", name)
+			}
+			paths = append(paths, strconv.Quote(path))
+		}
+		slices.Sort(paths)
+		fmt.Fprintf(&out, "import (\n%s\n)\n", strings.Join(paths, "\n"))
+	}
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("gen: format: %v", err)
+	}
+	if err := os.WriteFile(target, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func findConfig(file *ast.File) *ast.StructType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.Name == "Config" {
+				return st
+			}
+		}
+	}
+	return nil
+}
+
+// packageFuncs returns the package-level functions declared outside the
+// generated file and tests.
+func packageFuncs(fset *token.FileSet) (map[string]bool, error) {
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		return nil, err
+	}
+	funcs := make(map[string]bool)
+	for _, path := range paths {
+		if path == target || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("gen: parse %s: %w", path, err)
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				funcs[fn.Name.Name] = true
+			}
+		}
+	}
+	return funcs, nil
+}
+
+func writeOption(w *bytes.Buffer, fset *token.FileSet, name, field string, f *ast.Field) {
+	param := paramName(field)
+	verb, typ, assign := "sets", expr(fset, f.Type), param
+	switch t := f.Type.(type) {
+	case *ast.StarExpr:
+		if elem := expr(fset, t.X); strings.HasSuffix(elem, "Config") {
+			typ, assign = elem, "&"+param
+		}
+	case *ast.ArrayType:
+		verb, typ = "appends to", "..."+expr(fset, t.Elt)
+		assign = fmt.Sprintf("append(o.cfg.%s, %s...)", field, param)
+	}
+
+	fmt.Fprintf(w, "\n// %s %s Config.%s.\n", name, verb, field)
+	if f.Comment != nil {
+		fmt.Fprintf(w, "// %s.\n", strings.TrimSuffix(strings.TrimSpace(f.Comment.Text()), "."))
+	}
+	fmt.Fprintf(w, "func %s(%s %s) Option {\n\treturn optionFunc(func(o *options) { o.cfg.%s = %s })\n}\n",
+		name, param, typ, field, assign)
+}
+
+func expr(fset *token.FileSet, e ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, e); err != nil {
+		log.Fatal(err)
+	}
+	return buf.String()
+}
+
+// paramName lower-cases the leading word of a field name, treating a run of
+// capitals as one word: APIKey becomes apiKey, Model becomes model.
+func paramName(field string) string {
+	runes := []rune(field)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	if n > 1 && n < len(runes) {
+		n-- // the last capital starts the next word
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	name := string(runes)
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
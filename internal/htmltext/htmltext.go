@@ -0,0 +1,64 @@
+// Package htmltext extracts readable text from HTML documents.
+// This is an internal package and not part of the public API.
+package htmltext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Elements whose content is never part of the readable text.
+	noiseElements = regexp.MustCompile(`(?is)<(script|style|noscript|template|svg|iframe|head|nav|header|footer|aside|form|button)\b[^>]*>.*?</(?:script|style|noscript|template|svg|iframe|head|nav|header|footer|aside|form|button)>`)
+	comments      = regexp.MustCompile(`(?s)<!--.*?-->`)
+	titleElement  = regexp.MustCompile(`(?is)<title\b[^>]*>(.*?)</title>`)
+	articleBody   = regexp.MustCompile(`(?is)<article\b[^>]*>(.*)</article>`)
+	mainBody      = regexp.MustCompile(`(?is)<main\b[^>]*>(.*)</main>`)
+	bodyElement   = regexp.MustCompile(`(?is)<body\b[^>]*>(.*)</body>`)
+	blockTags     = regexp.MustCompile(`(?i)</?(p|div|section|article|main|br|hr|h[1-6]|li|ul|ol|tr|table|blockquote|pre|dd|dt|figcaption)\b[^>]*>`)
+	listItems     = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	anyTag        = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaceRuns     = regexp.MustCompile(`[ \t\f\r\v\x{00a0}]+`)
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+)
+
+// Extract returns the page title and the readable main text of an HTML document.
+//
+// It is a lightweight readability pass: boilerplate elements (scripts, styles,
+// navigation, headers, footers, sidebars, forms) are dropped, the <article> or
+// <main> element is preferred over the whole <body>, and block elements become
+// paragraph breaks.
+func Extract(document string) (title, text string) {
+	if m := titleElement.FindStringSubmatch(document); m != nil {
+		title = normalizeInline(html.UnescapeString(anyTag.ReplaceAllString(m[1], "")))
+	}
+
+	content := comments.ReplaceAllString(document, "")
+	content = noiseElements.ReplaceAllString(content, "")
+
+	for _, container := range []*regexp.Regexp{articleBody, mainBody, bodyElement} {
+		if m := container.FindStringSubmatch(content); m != nil && strings.TrimSpace(anyTag.ReplaceAllString(m[1], "")) != "" {
+			content = m[1]
+			break
+		}
+	}
+
+	content = listItems.ReplaceAllString(content, "\n- ")
+	content = blockTags.ReplaceAllString(content, "\n\n")
+	content = anyTag.ReplaceAllString(content, "")
+	content = html.UnescapeString(content)
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = normalizeInline(line)
+	}
+	content = strings.Join(lines, "\n")
+	content = blankLines.ReplaceAllString(content, "\n\n")
+
+	return title, strings.TrimSpace(content)
+}
+
+func normalizeInline(s string) string {
+	return strings.TrimSpace(spaceRuns.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,121 @@
+// Package leakcheck finds goroutines left running, by comparing the
+// goroutine stacks before and after a piece of work.
+// This is an internal package and not part of the public API.
+package leakcheck
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Leak is a group of goroutines with the same stack that were not running
+// before and still are after.
+type Leak struct {
+	Count int    `json:"count"`
+	Stack string `json:"stack"` // State and frames, without goroutine IDs or addresses
+}
+
+func (l Leak) String() string {
+	return fmt.Sprintf("%d goroutine(s):\n%s", l.Count, l.Stack)
+}
+
+var (
+	goroutineHeader = regexp.MustCompile(`^goroutine \d+ \[([^,\]]+)[^\]]*\]:`)
+	frameArgs       = regexp.MustCompile(`\([^()]*\)$`)
+	frameOffset     = regexp.MustCompile(` \+0x[0-9a-f]+$`)
+	creatorID       = regexp.MustCompile(` in goroutine \d+$`)
+)
+
+// Stacks groups the running goroutines by normalized stack, leaving out
+// the calling one.
+func Stacks() map[string]int {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[string]int)
+	// The calling goroutine is always listed first.
+	for _, block := range bytes.Split(buf, []byte("\n\n"))[1:] {
+		if key := normalizeStack(string(block)); key != "" {
+			stacks[key]++
+		}
+	}
+	return stacks
+}
+
+// normalizeStack strips goroutine IDs, wait times, arguments and PC
+// offsets, so goroutines parked at the same place compare equal.
+func normalizeStack(block string) string {
+	lines := strings.Split(strings.TrimSpace(block), "\n")
+	header := goroutineHeader.FindStringSubmatch(lines[0])
+	if header == nil {
+		return ""
+	}
+	out := []string{"[" + header[1] + "]"}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		line = frameArgs.ReplaceAllString(line, "(...)")
+		line = frameOffset.ReplaceAllString(line, "")
+		line = creatorID.ReplaceAllString(line, "")
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Diff returns the stack groups that grew from before to after, largest
+// first.
+func Diff(before, after map[string]int) []Leak {
+	var leaks []Leak
+	for stack, n := range after {
+		if extra := n - before[stack]; extra > 0 {
+			leaks = append(leaks, Leak{Count: extra, Stack: stack})
+		}
+	}
+	sort.Slice(leaks, func(i, j int) bool {
+		if leaks[i].Count != leaks[j].Count {
+			return leaks[i].Count > leaks[j].Count
+		}
+		return leaks[i].Stack < leaks[j].Stack
+	})
+	return leaks
+}
+
+// Settle is how long Check waits for goroutines to exit.
+const Settle = 2 * time.Second
+
+// Check records the running goroutines and returns a function that fails t
+// if any started since are still running Settle later:
+//
+//	defer leakcheck.Check(t)()
+func Check(t testing.TB) func() {
+	t.Helper()
+	before := Stacks()
+	return func() {
+		t.Helper()
+		deadline := time.Now().Add(Settle)
+		for {
+			leaks := Diff(before, Stacks())
+			if len(leaks) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				for _, leak := range leaks {
+					t.Errorf("leaked %s", leak)
+				}
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
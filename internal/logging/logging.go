@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -33,7 +34,8 @@ type LoggingConfig struct {
 	// LogToolCalls enables logging tool call summaries.
 	LogToolCalls bool
 
-	// RedactSensitive enables best-effort redaction of sensitive fields in logs.
+	// RedactSensitive redacts well-known secret fields (api_key, password,
+	// token...) in logs. Config.Redaction.Logs takes precedence.
 	RedactSensitive bool
 
 	// PromptLogPath overrides the prompt log file path.
@@ -126,6 +128,16 @@ var sensitiveKeys = map[string]struct{}{
 	"openai_api_key": {},
 }
 
+// SensitiveKeys returns the field names redacted by RedactSensitive, sorted.
+func SensitiveKeys() []string {
+	keys := make([]string, 0, len(sensitiveKeys))
+	for key := range sensitiveKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func redactSensitiveValue(value any) any {
 	data, err := json.Marshal(value)
 	if err != nil {
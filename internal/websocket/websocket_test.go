@@ -0,0 +1,87 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDial_EchoesMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(msg); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+	url := "ws://" + strings.TrimPrefix(server.URL, "http://")
+
+	if _, err := Dial(context.Background(), nil, url, nil); !errors.Is(err, ErrHandshake) {
+		t.Fatalf("dial without auth: err = %v, want ErrHandshake", err)
+	}
+
+	conn, err := Dial(context.Background(), nil, url, http.Header{"Authorization": {"Bearer key"}})
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	for _, msg := range [][]byte{[]byte(`{"type":"hello"}`), bytes.Repeat([]byte("a"), 300), bytes.Repeat([]byte("b"), 70000)} {
+		if err := conn.WriteMessage(msg); err != nil {
+			t.Fatalf("WriteMessage: %v", err)
+		}
+		got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage: %v", err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("echo of %d bytes returned %d bytes", len(msg), len(got))
+		}
+	}
+}
+
+func TestReadMessage_FragmentsPingsAndClose(t *testing.T) {
+	var stream bytes.Buffer
+	writer := &Conn{rwc: nopCloser{&stream}}
+	_ = writer.writeFrame(opPing, []byte("p"))
+	// A fragmented text message: "hel" + "lo".
+	stream.Write([]byte{opText, 3, 'h', 'e', 'l'})
+	stream.Write([]byte{0x80 | opContinuation, 2, 'l', 'o'})
+	_ = writer.writeFrame(opClose, nil)
+
+	var replies bytes.Buffer
+	conn := &Conn{rwc: nopCloser{&replies}, br: bufio.NewReader(&stream)}
+	msg, err := conn.ReadMessage()
+	if err != nil || string(msg) != "hello" {
+		t.Fatalf("ReadMessage = %q, %v", msg, err)
+	}
+	if !bytes.HasPrefix(replies.Bytes(), []byte{0x80 | opPong, 1, 'p'}) {
+		t.Errorf("ping not answered: %x", replies.Bytes())
+	}
+	if _, err := conn.ReadMessage(); err != io.EOF {
+		t.Errorf("after close: err = %v, want io.EOF", err)
+	}
+}
+
+type nopCloser struct{ io.ReadWriter }
+
+func (nopCloser) Close() error { return nil }
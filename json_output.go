@@ -0,0 +1,115 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/jsonrepair"
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrInvalidJSONOutput is returned by CompleteJSON when the model's output
+// still does not match the schema after all repair attempts.
+var ErrInvalidJSONOutput = errors.New("agentkit: model output does not match the schema")
+
+// DefaultJSONRepairs is the number of repair turns CompleteJSON callers
+// usually allow.
+const DefaultJSONRepairs = 2
+
+// JSONOutput is a schema-valid JSON value returned by CompleteJSON.
+type JSONOutput struct {
+	Raw     json.RawMessage
+	Repairs int                  // Repair turns it took
+	Usage   providers.TokenUsage // Summed over all attempts
+}
+
+// Decode unmarshals the output into v.
+func (o *JSONOutput) Decode(v any) error {
+	return json.Unmarshal(o.Raw, v)
+}
+
+// CompleteJSON gets a JSON value matching schema from providers and models
+// without strict structured outputs (see ModelInfo.StrictSchemas). It
+// requests JSON mode, puts the schema in the system prompt, fixes malformed
+// JSON with jsonrepair, validates the reply locally with ValidateSchema and,
+// when the reply is not valid, sends the problems back and asks for a
+// corrected reply, at most maxRepairs times. Tools are removed from req.
+func CompleteJSON(ctx context.Context, provider providers.Provider, req providers.CompletionRequest, schema map[string]any, maxRepairs int) (*JSONOutput, error) {
+	instruction, err := schemaInstruction(schema)
+	if err != nil {
+		return nil, err
+	}
+	if req.SystemPrompt != "" {
+		instruction = req.SystemPrompt + "\n\n" + instruction
+	}
+	req.SystemPrompt = instruction
+	req.TextFormat = "json_object"
+	req.Tools, req.ToolChoice = nil, ""
+	req.Messages = append([]providers.Message(nil), req.Messages...)
+
+	output := &JSONOutput{}
+	for attempt := 0; ; attempt++ {
+		resp, err := provider.Complete(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		output.Usage = addUsage(output.Usage, resp.Usage)
+
+		raw, problems := jsonOutputProblems(req.Model, resp.Content, schema)
+		if len(problems) == 0 {
+			output.Raw = json.RawMessage(raw)
+			output.Repairs = attempt
+			return output, nil
+		}
+		if attempt >= maxRepairs {
+			return nil, fmt.Errorf("%w after %d repairs: %s", ErrInvalidJSONOutput, attempt, strings.Join(problems, "; "))
+		}
+		req.Messages = append(req.Messages,
+			providers.Message{Role: providers.RoleAssistant, Content: resp.Content},
+			providers.Message{
+				Role: providers.RoleUser,
+				Content: "Your reply does not match the schema:\n- " + strings.Join(problems, "\n- ") +
+					"\nReply with the corrected JSON only.",
+			})
+	}
+}
+
+// jsonOutputProblems parses the JSON in content, repairing it when it is
+// malformed, and validates it against schema. It returns the parsed JSON.
+func jsonOutputProblems(model, content string, schema map[string]any) (string, []string) {
+	raw := extractJSON(content)
+	var value any
+	if _, err := jsonrepair.Unmarshal(model, []byte(raw), &value); err != nil {
+		return raw, []string{"invalid JSON: " + err.Error()}
+	}
+	if !json.Valid([]byte(raw)) {
+		fixed, _ := json.Marshal(value)
+		raw = string(fixed)
+	}
+	var problems []string
+	for _, v := range ValidateSchema(schema, value) {
+		problems = append(problems, v.String())
+	}
+	return raw, problems
+}
+
+// extractJSON strips code fences and prose around the outermost JSON object
+// or array in content.
+func extractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	start := strings.IndexAny(content, "{[")
+	if start < 0 {
+		return content
+	}
+	closing := "}"
+	if content[start] == '[' {
+		closing = "]"
+	}
+	if end := strings.LastIndex(content, closing); end > start {
+		return content[start : end+1]
+	}
+	return content[start:]
+}
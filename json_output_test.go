@@ -0,0 +1,82 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+var verdictSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"approved": map[string]any{"type": "boolean"},
+		"reason":   map[string]any{"type": "string"},
+	},
+	"required":             []string{"approved", "reason"},
+	"additionalProperties": false,
+}
+
+func TestCompleteJSON_RepairsInvalidOutput(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse(`Sure! {"approved": "yes"}`, nil).
+		WithResponse("```json\n{\"approved\": true, \"reason\": \"within policy\"}\n```", nil)}
+
+	output, err := CompleteJSON(context.Background(), provider, providers.CompletionRequest{
+		Model:        "claude-sonnet-4-5",
+		SystemPrompt: "You review refunds.",
+		Messages:     []providers.Message{{Role: providers.RoleUser, Content: "Refund $20?"}},
+		Tools:        []providers.ToolDefinition{{Name: "lookup"}},
+	}, verdictSchema, DefaultJSONRepairs)
+	if err != nil {
+		t.Fatalf("CompleteJSON() error = %v", err)
+	}
+
+	var verdict struct {
+		Approved bool   `json:"approved"`
+		Reason   string `json:"reason"`
+	}
+	if err := output.Decode(&verdict); err != nil || !verdict.Approved || verdict.Reason != "within policy" {
+		t.Errorf("Decode() = %+v, %v", verdict, err)
+	}
+	if output.Repairs != 1 || output.Usage.TotalTokens != 60 {
+		t.Errorf("repairs = %d, usage = %+v", output.Repairs, output.Usage)
+	}
+
+	first := provider.requests[0]
+	if first.TextFormat != "json_object" || len(first.Tools) != 0 ||
+		!strings.HasPrefix(first.SystemPrompt, "You review refunds.\n\n") || !strings.Contains(first.SystemPrompt, `"additionalProperties":false`) {
+		t.Errorf("first request = %+v", first)
+	}
+	repair := provider.requests[1].Messages
+	if len(repair) != 3 || !strings.Contains(repair[2].Content, `$.approved: expected boolean, got string`) ||
+		!strings.Contains(repair[2].Content, `missing required property "reason"`) {
+		t.Errorf("repair messages = %+v", repair)
+	}
+}
+
+func TestCompleteJSON_GivesUpAfterMaxRepairs(t *testing.T) {
+	provider := mockprovider.New().
+		WithResponse("not json", nil).
+		WithResponse(`{"approved": true}`, nil)
+
+	_, err := CompleteJSON(context.Background(), provider, providers.CompletionRequest{Model: "llama3"}, verdictSchema, 1)
+	if !errors.Is(err, ErrInvalidJSONOutput) || !strings.Contains(err.Error(), "after 1 repairs") {
+		t.Errorf("error = %v, want ErrInvalidJSONOutput after 1 repair", err)
+	}
+}
+
+func TestCompleteJSON_FixesMalformedJSONLocally(t *testing.T) {
+	provider := mockprovider.New().WithResponse(`{approved: true, reason: 'within policy',}`, nil)
+
+	output, err := CompleteJSON(context.Background(), provider, providers.CompletionRequest{Model: "llama3"}, verdictSchema, 0)
+	if err != nil {
+		t.Fatalf("CompleteJSON() error = %v", err)
+	}
+	if output.Repairs != 0 || string(output.Raw) != `{"approved":true,"reason":"within policy"}` {
+		t.Errorf("output = %s, repairs = %d", output.Raw, output.Repairs)
+	}
+}
@@ -0,0 +1,255 @@
+// Package jsonrepair fixes the malformed JSON models commonly produce:
+// trailing commas, unquoted keys, single-quoted strings, Python literals,
+// comments, code fences around the value and objects cut off mid-way by a
+// token limit.
+package jsonrepair
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrUnrepairable is returned when the input cannot be turned into valid JSON.
+var ErrUnrepairable = errors.New("jsonrepair: cannot repair JSON")
+
+// Repair returns input as valid JSON. Input that is already valid is
+// returned unchanged.
+func Repair(input string) (string, error) {
+	if json.Valid([]byte(input)) {
+		return input, nil
+	}
+	start := strings.IndexAny(input, "{[")
+	if start < 0 {
+		return "", ErrUnrepairable
+	}
+	r := &repairer{in: []rune(input[start:])}
+	out := r.run()
+	if !json.Valid([]byte(out)) {
+		return "", ErrUnrepairable
+	}
+	return out, nil
+}
+
+// frame is an open object or array.
+type frame struct {
+	close  rune
+	keyPos bool // An object expects a key next
+}
+
+type repairer struct {
+	in    []rune
+	pos   int
+	out   strings.Builder
+	stack []frame
+	// afterKey is set between an object key and its colon.
+	afterKey bool
+}
+
+func (r *repairer) run() string {
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+		switch {
+		case c == '"' || c == '\'':
+			r.str(c)
+		case c == '{' || c == '[':
+			r.pos++
+			r.out.WriteRune(c)
+			if c == '{' {
+				r.stack = append(r.stack, frame{close: '}', keyPos: true})
+			} else {
+				r.stack = append(r.stack, frame{close: ']'})
+			}
+		case c == '}' || c == ']':
+			r.pos++
+			if len(r.stack) == 0 {
+				continue
+			}
+			r.closeFrame()
+			if len(r.stack) == 0 {
+				// The top-level value is complete; ignore trailing prose.
+				return r.out.String()
+			}
+		case c == ',':
+			r.pos++
+			r.trimTrailingComma()
+			r.out.WriteRune(',')
+			if top := r.top(); top != nil && top.close == '}' {
+				top.keyPos = true
+			}
+		case c == ':':
+			r.pos++
+			r.out.WriteRune(':')
+			r.afterKey = false
+			if top := r.top(); top != nil {
+				top.keyPos = false
+			}
+		case c == '/' && r.pos+1 < len(r.in) && (r.in[r.pos+1] == '/' || r.in[r.pos+1] == '*'):
+			r.comment()
+		case c == '-' || c == '.' || unicode.IsDigit(c):
+			r.number()
+		case c == '_' || c == '$' || unicode.IsLetter(c):
+			r.word()
+		default:
+			r.pos++
+			if unicode.IsSpace(c) {
+				r.out.WriteRune(c)
+			}
+		}
+	}
+	return r.finish()
+}
+
+func (r *repairer) top() *frame {
+	if len(r.stack) == 0 {
+		return nil
+	}
+	return &r.stack[len(r.stack)-1]
+}
+
+// str copies a string literal, converting single quotes to double quotes
+// and escaping raw control characters.
+func (r *repairer) str(quote rune) {
+	r.pos++
+	r.out.WriteRune('"')
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+		r.pos++
+		switch {
+		case c == '\\' && r.pos < len(r.in):
+			next := r.in[r.pos]
+			r.pos++
+			if next == '\'' {
+				r.out.WriteRune('\'')
+			} else {
+				r.out.WriteRune('\\')
+				r.out.WriteRune(next)
+			}
+		case c == quote:
+			r.out.WriteRune('"')
+			r.endString()
+			return
+		case c == '"':
+			r.out.WriteString(`\"`)
+		case c == '\n':
+			r.out.WriteString(`\n`)
+		case c == '\r':
+			r.out.WriteString(`\r`)
+		case c == '\t':
+			r.out.WriteString(`\t`)
+		default:
+			r.out.WriteRune(c)
+		}
+	}
+	// Truncated mid-string.
+	r.out.WriteRune('"')
+	r.endString()
+}
+
+func (r *repairer) endString() {
+	if top := r.top(); top != nil && top.close == '}' && top.keyPos {
+		r.afterKey = true
+	}
+}
+
+func (r *repairer) number() {
+	start := r.pos
+	for r.pos < len(r.in) && strings.ContainsRune("+-.eE0123456789", r.in[r.pos]) {
+		r.pos++
+	}
+	// A number cut off mid-way ("1.", "2e") loses its incomplete tail.
+	num := strings.TrimRight(string(r.in[start:r.pos]), "+-.eE")
+	if num == "" {
+		num = "0"
+	}
+	r.out.WriteString(num)
+}
+
+// word handles bare identifiers: unquoted keys, Python and JavaScript
+// literals, and unquoted string values.
+func (r *repairer) word() {
+	start := r.pos
+	for r.pos < len(r.in) {
+		c := r.in[r.pos]
+		if !(c == '_' || c == '$' || c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c)) {
+			break
+		}
+		r.pos++
+	}
+	w := string(r.in[start:r.pos])
+	if top := r.top(); top != nil && top.close == '}' && top.keyPos {
+		r.out.WriteString(`"` + w + `"`)
+		r.afterKey = true
+		return
+	}
+	switch w {
+	case "true", "True":
+		r.out.WriteString("true")
+	case "false", "False":
+		r.out.WriteString("false")
+	case "null", "None", "undefined", "NaN":
+		r.out.WriteString("null")
+	default:
+		if r.pos == len(r.in) {
+			// A literal cut off at the end of the input.
+			for _, literal := range []string{"true", "false", "null"} {
+				if strings.HasPrefix(literal, w) {
+					r.out.WriteString(literal)
+					return
+				}
+			}
+		}
+		r.out.WriteString(`"` + w + `"`)
+	}
+}
+
+func (r *repairer) comment() {
+	if r.in[r.pos+1] == '/' {
+		for r.pos < len(r.in) && r.in[r.pos] != '\n' {
+			r.pos++
+		}
+		return
+	}
+	r.pos += 2
+	for r.pos+1 < len(r.in) && !(r.in[r.pos] == '*' && r.in[r.pos+1] == '/') {
+		r.pos++
+	}
+	r.pos += 2
+}
+
+// trimTrailingComma removes a comma (and whitespace after it) at the end of
+// the output, so "[1,]" and "[1,,2]" become valid.
+func (r *repairer) trimTrailingComma() {
+	out := strings.TrimRightFunc(r.out.String(), unicode.IsSpace)
+	if trimmed, ok := strings.CutSuffix(out, ","); ok {
+		r.out.Reset()
+		r.out.WriteString(trimmed)
+	}
+}
+
+// closeFrame closes the innermost object or array, completing a dangling key
+// or colon with null.
+func (r *repairer) closeFrame() {
+	top := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	out := strings.TrimRightFunc(r.out.String(), unicode.IsSpace)
+	out = strings.TrimSuffix(out, ",")
+	if r.afterKey {
+		out += ":null"
+	} else if strings.HasSuffix(out, ":") {
+		out += "null"
+	}
+	r.afterKey = false
+	r.out.Reset()
+	r.out.WriteString(out)
+	r.out.WriteRune(top.close)
+}
+
+// finish closes everything left open by truncated input.
+func (r *repairer) finish() string {
+	for len(r.stack) > 0 {
+		r.closeFrame()
+	}
+	return r.out.String()
+}
@@ -0,0 +1,75 @@
+package jsonrepair
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "valid", input: `{"a": [1, 2]}`, want: `{"a": [1, 2]}`},
+		{name: "trailing commas", input: `{"a": [1, 2,], "b": 3,}`, want: `{"a": [1, 2], "b": 3}`},
+		{name: "unquoted keys", input: `{query: "refunds", max_results: 5}`, want: `{"query": "refunds", "max_results": 5}`},
+		{name: "single quotes", input: `{'name': 'O\'Brien', 'note': 'say "hi"'}`, want: `{"name": "O'Brien", "note": "say \"hi\""}`},
+		{name: "python literals", input: `{"ok": True, "err": None, "retry": False}`, want: `{"ok": true, "err": null, "retry": false}`},
+		{name: "code fence and prose", input: "Here you go:\n```json\n{\"a\": 1}\n```\nAnything else?", want: `{"a": 1}`},
+		{name: "comments", input: "{\"a\": 1, // first\n /* second */ \"b\": 2}", want: "{\"a\": 1, \n  \"b\": 2}"},
+		{name: "raw newline in string", input: "{\"text\": \"line one\nline two\"}", want: `{"text": "line one\nline two"}`},
+		{name: "truncated string", input: `{"items": [{"id": "a1"}, {"id": "b`, want: `{"items": [{"id": "a1"}, {"id": "b"}]}`},
+		{name: "truncated after key", input: `{"id": 7, "status"`, want: `{"id": 7, "status":null}`},
+		{name: "truncated after colon", input: `{"id": 7, "status": `, want: `{"id": 7, "status":null}`},
+		{name: "truncated after comma", input: `[1, 2, `, want: `[1, 2]`},
+		{name: "truncated number", input: `{"score": 0.`, want: `{"score": 0}`},
+		{name: "truncated literal", input: `{"done": tr`, want: `{"done": true}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Repair(tt.input)
+			if err != nil {
+				t.Fatalf("Repair() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Repair() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepair_Unrepairable(t *testing.T) {
+	for _, input := range []string{"", "no json here", `{"a" "b" "c"}`} {
+		if _, err := Repair(input); !errors.Is(err, ErrUnrepairable) {
+			t.Errorf("Repair(%q) error = %v, want ErrUnrepairable", input, err)
+		}
+	}
+}
+
+func TestUnmarshal_Stats(t *testing.T) {
+	ResetStats()
+	defer ResetStats()
+
+	var args map[string]any
+	if repaired, err := Unmarshal("gpt-4o", []byte(`{"id": 7}`), &args); repaired || err != nil {
+		t.Errorf("valid: repaired = %v, err = %v", repaired, err)
+	}
+	if repaired, err := Unmarshal("llama3", []byte(`{id: 7,}`), &args); !repaired || err != nil || args["id"] != float64(7) {
+		t.Errorf("repairable: repaired = %v, err = %v, args = %v", repaired, err, args)
+	}
+	if _, err := Unmarshal("llama3", []byte(`nope`), &args); err == nil {
+		t.Error("unrepairable: expected error")
+	}
+	if _, err := Unmarshal("llama3", []byte(`[1]`), &args); err == nil {
+		t.Error("wrong shape: expected error")
+	}
+
+	stats := Stats()
+	if got := stats["gpt-4o"]; got != (ModelStats{Valid: 1}) {
+		t.Errorf("gpt-4o stats = %+v", got)
+	}
+	if got := stats["llama3"]; got != (ModelStats{Valid: 1, Repaired: 1, Failed: 1}) || got.RepairRate() < 0.66 {
+		t.Errorf("llama3 stats = %+v", got)
+	}
+}
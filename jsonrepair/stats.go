@@ -0,0 +1,82 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// ModelStats counts how often JSON from a model needed repair.
+type ModelStats struct {
+	Valid    int64 // Parsed as-is
+	Repaired int64 // Parsed after Repair
+	Failed   int64 // Could not be parsed even after Repair
+}
+
+// RepairRate returns the fraction of parse attempts that needed repair,
+// successful or not.
+func (s ModelStats) RepairRate() float64 {
+	total := s.Valid + s.Repaired + s.Failed
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Repaired+s.Failed) / float64(total)
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = map[string]*ModelStats{}
+)
+
+// Unmarshal parses data into v, repairing it first when it is not valid
+// JSON, and counts the outcome for model in Stats. It reports whether
+// repair was needed.
+func Unmarshal(model string, data []byte, v any) (repaired bool, err error) {
+	err = json.Unmarshal(data, v)
+	var syntaxErr *json.SyntaxError
+	if err == nil || !errors.As(err, &syntaxErr) {
+		// Valid JSON, possibly of the wrong shape, which repair cannot fix.
+		record(model, func(s *ModelStats) { s.Valid++ })
+		return false, err
+	}
+
+	fixed, err := Repair(string(data))
+	if err == nil {
+		err = json.Unmarshal([]byte(fixed), v)
+	}
+	if err != nil {
+		record(model, func(s *ModelStats) { s.Failed++ })
+		return true, err
+	}
+	record(model, func(s *ModelStats) { s.Repaired++ })
+	return true, nil
+}
+
+func record(model string, update func(*ModelStats)) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	s, ok := stats[model]
+	if !ok {
+		s = &ModelStats{}
+		stats[model] = s
+	}
+	update(s)
+}
+
+// Stats returns a snapshot of the counters per model.
+func Stats() map[string]ModelStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	snapshot := make(map[string]ModelStats, len(stats))
+	for model, s := range stats {
+		snapshot[model] = *s
+	}
+	return snapshot
+}
+
+// ResetStats clears the counters.
+func ResetStats() {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	stats = map[string]*ModelStats{}
+}
@@ -0,0 +1,355 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/memory"
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Default LessonsConfig values.
+const (
+	DefaultLessonBatchSize   = 3
+	DefaultLessonInjectLimit = 10
+)
+
+var (
+	// ErrLessonsNotConfigured is returned by Score and the lesson methods on
+	// agents without Config.Lessons.
+	ErrLessonsNotConfigured = errors.New("agentkit: lessons are not configured")
+	// ErrLessonNotFound is returned by ReviewLesson for an unknown lesson ID.
+	ErrLessonNotFound = errors.New("agentkit: lesson not found")
+)
+
+// Rating scores a run. Negative ratings are failures and feed the lessons loop.
+type Rating int
+
+const (
+	ThumbsDown Rating = -1
+	ThumbsUp   Rating = 1
+)
+
+// RunFeedback is a score for one finished run.
+type RunFeedback struct {
+	Input   string // The user message of the run
+	Output  string // The agent's final response
+	Rating  Rating
+	Comment string // What went wrong, in the rater's words (optional)
+	At      time.Time
+}
+
+// LessonStatus is where a lesson is in review.
+type LessonStatus string
+
+const (
+	LessonPending  LessonStatus = "pending"
+	LessonApproved LessonStatus = "approved"
+	LessonRejected LessonStatus = "rejected"
+)
+
+// Lesson is a short instruction mined from failed runs. Only approved
+// lessons are added to the system prompt.
+type Lesson struct {
+	ID        string
+	Text      string
+	Status    LessonStatus
+	Evidence  int // Failed runs the lesson was mined from
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// LessonStore persists an agent's lessons, keyed by agent name.
+type LessonStore interface {
+	Load(ctx context.Context, agentName string) ([]Lesson, error)
+	Save(ctx context.Context, agentName string, lessons []Lesson) error
+}
+
+// LessonMiner turns failed runs into lesson texts. existing lists the
+// lessons already known, including rejected ones, so the miner can avoid
+// proposing them again.
+type LessonMiner interface {
+	Mine(ctx context.Context, failures []RunFeedback, existing []Lesson) ([]string, error)
+}
+
+// LessonsConfig enables the feedback loop: runs rated ThumbsDown through
+// Agent.Score are collected, mined in batches for recurring failure
+// patterns, and the resulting lessons are added to the system prompt as a
+// "Lessons learned" section once approved.
+//
+// Mined lessons start pending; review them with Agent.Lessons and
+// Agent.ReviewLesson, or set AutoApprove to inject them right away.
+type LessonsConfig struct {
+	// Store keeps lessons between runs (default: NewMemoryLessonStore()).
+	Store LessonStore
+	// Miner extracts lessons (default: an LLMLessonMiner on the agent's provider and model).
+	Miner LessonMiner
+	// BatchSize is how many failed runs are collected before mining
+	// (default DefaultLessonBatchSize).
+	BatchSize int
+	// InjectLimit caps the lessons added to the system prompt
+	// (default DefaultLessonInjectLimit); the best supported come first.
+	InjectLimit int
+	// AutoApprove skips review: mined lessons are approved immediately.
+	AutoApprove bool
+}
+
+// lessonBook is an agent's lessons state: the config and the failures not
+// yet mined.
+type lessonBook struct {
+	LessonsConfig
+
+	mu       sync.Mutex
+	failures []RunFeedback
+}
+
+func newLessonBook(cfg LessonsConfig, provider providers.Provider, model string) *lessonBook {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryLessonStore()
+	}
+	if cfg.Miner == nil {
+		cfg.Miner = LLMLessonMiner{Provider: provider, Model: model}
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultLessonBatchSize
+	}
+	if cfg.InjectLimit <= 0 {
+		cfg.InjectLimit = DefaultLessonInjectLimit
+	}
+	return &lessonBook{LessonsConfig: cfg}
+}
+
+// Score records feedback for a run. Once BatchSize failed runs have been
+// collected they are mined for lessons; a mining error is returned and the
+// failures are kept for the next attempt.
+func (a *Agent) Score(ctx context.Context, feedback RunFeedback) error {
+	if a.lessons == nil {
+		return ErrLessonsNotConfigured
+	}
+	if feedback.Rating >= 0 {
+		return nil
+	}
+	if feedback.At.IsZero() {
+		feedback.At = time.Now()
+	}
+	book := a.lessons
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	book.failures = append(book.failures, feedback)
+	if len(book.failures) < book.BatchSize {
+		return nil
+	}
+	_, err := a.mineLessonsLocked(ctx)
+	return err
+}
+
+// MineLessons mines the failed runs collected so far without waiting for a
+// full batch and returns the lessons it added.
+func (a *Agent) MineLessons(ctx context.Context) ([]Lesson, error) {
+	if a.lessons == nil {
+		return nil, ErrLessonsNotConfigured
+	}
+	a.lessons.mu.Lock()
+	defer a.lessons.mu.Unlock()
+	return a.mineLessonsLocked(ctx)
+}
+
+func (a *Agent) mineLessonsLocked(ctx context.Context) ([]Lesson, error) {
+	book := a.lessons
+	if len(book.failures) == 0 {
+		return nil, nil
+	}
+	existing, err := book.Store.Load(ctx, a.agentName)
+	if err != nil {
+		return nil, fmt.Errorf("load lessons: %w", err)
+	}
+	texts, err := book.Miner.Mine(ctx, book.failures, existing)
+	if err != nil {
+		return nil, fmt.Errorf("mine lessons: %w", err)
+	}
+
+	now := time.Now()
+	status := LessonPending
+	if book.AutoApprove {
+		status = LessonApproved
+	}
+	var added []Lesson
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		// A lesson mined again is more evidence for the known one.
+		if i := slices.IndexFunc(existing, func(l Lesson) bool { return strings.EqualFold(l.Text, text) }); i >= 0 {
+			existing[i].Evidence += len(book.failures)
+			existing[i].UpdatedAt = now
+			continue
+		}
+		id, err := memory.NewID()
+		if err != nil {
+			return nil, err
+		}
+		lesson := Lesson{ID: id, Text: text, Status: status, Evidence: len(book.failures), CreatedAt: now, UpdatedAt: now}
+		existing = append(existing, lesson)
+		added = append(added, lesson)
+	}
+	if err := book.Store.Save(ctx, a.agentName, existing); err != nil {
+		return nil, fmt.Errorf("save lessons: %w", err)
+	}
+	book.failures = nil
+	return added, nil
+}
+
+// Lessons returns the agent's lessons in every status, for review.
+func (a *Agent) Lessons(ctx context.Context) ([]Lesson, error) {
+	if a.lessons == nil {
+		return nil, ErrLessonsNotConfigured
+	}
+	return a.lessons.Store.Load(ctx, a.agentName)
+}
+
+// ReviewLesson sets a lesson's status, e.g. LessonApproved to start
+// injecting it or LessonRejected to stop. A non-empty text replaces the
+// lesson's wording.
+func (a *Agent) ReviewLesson(ctx context.Context, id string, status LessonStatus, text string) error {
+	if a.lessons == nil {
+		return ErrLessonsNotConfigured
+	}
+	book := a.lessons
+	book.mu.Lock()
+	defer book.mu.Unlock()
+	lessons, err := book.Store.Load(ctx, a.agentName)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(lessons, func(l Lesson) bool { return l.ID == id })
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrLessonNotFound, id)
+	}
+	lessons[i].Status = status
+	if text = strings.TrimSpace(text); text != "" {
+		lessons[i].Text = text
+	}
+	lessons[i].UpdatedAt = time.Now()
+	return book.Store.Save(ctx, a.agentName, lessons)
+}
+
+// recallLessons adds the approved lessons to the system prompt.
+func (a *Agent) recallLessons(ctx context.Context) context.Context {
+	if a.lessons == nil {
+		return ctx
+	}
+	lessons, err := a.lessons.Store.Load(ctx, a.agentName)
+	if err != nil {
+		a.logger.Warn("lessons load failed", "error", err)
+		return ctx
+	}
+	var approved []Lesson
+	for _, lesson := range lessons {
+		if lesson.Status == LessonApproved {
+			approved = append(approved, lesson)
+		}
+	}
+	if len(approved) == 0 {
+		return ctx
+	}
+	slices.SortStableFunc(approved, func(x, y Lesson) int { return y.Evidence - x.Evidence })
+	if len(approved) > a.lessons.InjectLimit {
+		approved = approved[:a.lessons.InjectLimit]
+	}
+	var sb strings.Builder
+	sb.WriteString("## Lessons learned\nPast responses were rated poorly when these were ignored:")
+	for _, lesson := range approved {
+		sb.WriteString("\n- " + lesson.Text)
+	}
+	return withPromptSection(ctx, sb.String())
+}
+
+// LLMLessonMiner asks a model for the failure patterns shared by a batch of
+// poorly rated runs.
+type LLMLessonMiner struct {
+	Provider providers.Provider
+	Model    string
+	// MaxLessons caps the lessons proposed per batch (default 3).
+	MaxLessons int
+}
+
+type minedLessons struct {
+	Lessons []string `json:"lessons" desc:"Short imperative instructions that would have avoided the failures"`
+}
+
+// Mine implements LessonMiner.
+func (m LLMLessonMiner) Mine(ctx context.Context, failures []RunFeedback, existing []Lesson) ([]string, error) {
+	maxLessons := m.MaxLessons
+	if maxLessons <= 0 {
+		maxLessons = 3
+	}
+	var sb strings.Builder
+	for i, failure := range failures {
+		fmt.Fprintf(&sb, "### Run %d\nUser: %s\nAssistant: %s\n", i+1, failure.Input, failure.Output)
+		if failure.Comment != "" {
+			fmt.Fprintf(&sb, "Feedback: %s\n", failure.Comment)
+		}
+		sb.WriteString("\n")
+	}
+	if len(existing) > 0 {
+		sb.WriteString("### Known lessons (do not repeat)\n")
+		for _, lesson := range existing {
+			fmt.Fprintf(&sb, "- %s\n", lesson.Text)
+		}
+	}
+
+	schema, err := SchemaFromStruct(minedLessons{})
+	if err != nil {
+		return nil, err
+	}
+	output, err := CompleteJSON(ctx, m.Provider, providers.CompletionRequest{
+		Model: m.Model,
+		SystemPrompt: fmt.Sprintf("You review assistant responses that users rated poorly. Find what the failures have in common and write at most %d general, reusable instructions that would have prevented them. "+
+			"Skip one-off mistakes and anything already covered by a known lesson. Return an empty list if there is no clear pattern.", maxLessons),
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: sb.String()}},
+	}, schema, DefaultJSONRepairs)
+	if err != nil {
+		return nil, err
+	}
+	var mined minedLessons
+	if err := output.Decode(&mined); err != nil {
+		return nil, err
+	}
+	if len(mined.Lessons) > maxLessons {
+		mined.Lessons = mined.Lessons[:maxLessons]
+	}
+	return mined.Lessons, nil
+}
+
+// MemoryLessonStore provides an in-memory implementation of LessonStore.
+// Useful for testing and development. Not suitable for production.
+type MemoryLessonStore struct {
+	mu      sync.Mutex
+	lessons map[string][]Lesson
+}
+
+// NewMemoryLessonStore creates a new in-memory lesson store.
+func NewMemoryLessonStore() *MemoryLessonStore {
+	return &MemoryLessonStore{lessons: make(map[string][]Lesson)}
+}
+
+// Load implements LessonStore.
+func (s *MemoryLessonStore) Load(ctx context.Context, agentName string) ([]Lesson, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.lessons[agentName]), nil
+}
+
+// Save implements LessonStore.
+func (s *MemoryLessonStore) Save(ctx context.Context, agentName string, lessons []Lesson) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lessons[agentName] = slices.Clone(lessons)
+	return nil
+}
@@ -0,0 +1,85 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestLessons_MinedReviewedAndInjected(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse(`{"lessons": ["Quote prices in the user's currency."]}`, nil).
+		WithResponse("done", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		Lessons:         &LessonsConfig{BatchSize: 2},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := agent.Score(ctx, RunFeedback{Input: "Price?", Output: "$10", Rating: ThumbsUp}); err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	for _, output := range []string{"$10", "$25"} {
+		if err := agent.Score(ctx, RunFeedback{Input: "Price in EUR?", Output: output, Rating: ThumbsDown, Comment: "wrong currency"}); err != nil {
+			t.Fatalf("Score() error = %v", err)
+		}
+	}
+	if len(provider.requests) != 1 || !strings.Contains(provider.requests[0].Messages[0].Content, "Feedback: wrong currency") {
+		t.Fatalf("requests = %+v, want one mining call with both failures", provider.requests)
+	}
+
+	lessons, err := agent.Lessons(ctx)
+	if err != nil || len(lessons) != 1 || lessons[0].Status != LessonPending || lessons[0].Evidence != 2 {
+		t.Fatalf("Lessons() = %+v, %v", lessons, err)
+	}
+	if err := agent.ReviewLesson(ctx, lessons[0].ID, LessonApproved, ""); err != nil {
+		t.Fatalf("ReviewLesson() error = %v", err)
+	}
+
+	collectEvents(agent.Run(ctx, "Price of the pro plan in EUR?"), time.Second)
+	if prompt := provider.requests[1].SystemPrompt; !strings.Contains(prompt, "## Lessons learned") || !strings.Contains(prompt, "- Quote prices in the user's currency.") {
+		t.Errorf("system prompt = %q, want the approved lesson", prompt)
+	}
+}
+
+func TestLessons_PendingNotInjected(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("done", nil)}
+	store := NewMemoryLessonStore()
+	_ = store.Save(context.Background(), "test-model", []Lesson{{ID: "1", Text: "Be brief.", Status: LessonPending}})
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		Lessons:         &LessonsConfig{Store: store},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.Run(context.Background(), "hi"), time.Second)
+	if strings.Contains(provider.requests[0].SystemPrompt, "Be brief.") {
+		t.Errorf("system prompt = %q, pending lessons must not be injected", provider.requests[0].SystemPrompt)
+	}
+	if err := agent.ReviewLesson(context.Background(), "missing", LessonApproved, ""); !errors.Is(err, ErrLessonNotFound) {
+		t.Errorf("error = %v, want ErrLessonNotFound", err)
+	}
+}
+
+func TestLessons_NotConfigured(t *testing.T) {
+	agent, err := New(Config{Provider: mockprovider.New(), Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := agent.Score(context.Background(), RunFeedback{Rating: ThumbsDown}); !errors.Is(err, ErrLessonsNotConfigured) {
+		t.Errorf("error = %v, want ErrLessonsNotConfigured", err)
+	}
+}
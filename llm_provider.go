@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/darkostanimirovic/agentkit/jsonrepair"
 	"github.com/darkostanimirovic/agentkit/providers"
 )
 
@@ -110,8 +111,9 @@ func (a *ProviderAdapter) convertResponse(resp *providers.CompletionResponse, or
 			Role:   "assistant",
 			Content: []ResponseContentItem{
 				{
-					Type: "output_text",
-					Text: resp.Content,
+					Type:        "output_text",
+					Text:        resp.Content,
+					Annotations: toResponseAnnotations(resp.Annotations),
 				},
 			},
 		})
@@ -162,10 +164,17 @@ func (a *ProviderAdapter) convertResponse(resp *providers.CompletionResponse, or
 
 // streamAdapter adapts StreamReader to ResponseStreamClient.
 type streamAdapter struct {
-	stream providers.StreamReader
+	stream  providers.StreamReader
+	pending []*ResponseStreamChunk
 }
 
 func (s *streamAdapter) ReadChunk() (*ResponseStreamChunk, error) {
+	if len(s.pending) > 0 {
+		next := s.pending[0]
+		s.pending = s.pending[1:]
+		return next, nil
+	}
+
 	chunk, err := s.stream.Next()
 	if err != nil {
 		return nil, err
@@ -180,8 +189,14 @@ func (s *streamAdapter) ReadChunk() (*ResponseStreamChunk, error) {
 	} else if chunk.ReasoningSummary != "" {
 		apiChunk.Type = "response.reasoning_summary_text.delta"
 		apiChunk.Delta = chunk.ReasoningSummary
+	} else if chunk.ToolArgsDelta != "" {
+		apiChunk.Type = "response.function_call_arguments.delta"
+		apiChunk.CallID = chunk.ToolCallID
+		apiChunk.Name = chunk.ToolName
+		apiChunk.Delta = chunk.ToolArgsDelta
 	} else if chunk.ToolName != "" {
 		apiChunk.Type = "response.function_call_arguments.done"
+		apiChunk.CallID = chunk.ToolCallID
 		apiChunk.Name = chunk.ToolName
 		apiChunk.Arguments = chunk.ToolArgs
 	} else if chunk.ToolArgs != "" {
@@ -198,6 +213,27 @@ func (s *streamAdapter) ReadChunk() (*ResponseStreamChunk, error) {
 			}
 		}
 	}
+
+	if len(chunk.Annotations) > 0 {
+		// Annotations travel as separate events; they precede response.done
+		// and follow any text delta carried by the same chunk.
+		queue := make([]*ResponseStreamChunk, 0, len(chunk.Annotations)+1)
+		if apiChunk.Type != "" && !chunk.IsComplete {
+			queue = append(queue, apiChunk)
+		}
+		for _, ann := range toResponseAnnotations(chunk.Annotations) {
+			ann := ann
+			queue = append(queue, &ResponseStreamChunk{
+				Type:       "response.output_text.annotation.added",
+				Annotation: &ann,
+			})
+		}
+		if chunk.IsComplete {
+			queue = append(queue, apiChunk)
+		}
+		s.pending = append(s.pending, queue[1:]...)
+		return queue[0], nil
+	}
 	
 	return apiChunk, nil
 }
@@ -265,6 +301,11 @@ func (w *llmProviderWrapper) toResponseRequest(req providers.CompletionRequest)
 			}
 		}
 	}
+	for _, hosted := range req.HostedTools {
+		tool := ResponseTool{Type: hosted.Type, Container: hosted.Options["container"]}
+		tool.VectorStoreIDs, _ = hosted.Options["vector_store_ids"].([]string)
+		apiReq.Tools = append(apiReq.Tools, tool)
+	}
 	
 	if req.ReasoningEffort != "" || req.ReasoningSummary != "" {
 		apiReq.Reasoning = &ResponseReasoning{
@@ -283,6 +324,15 @@ func (w *llmProviderWrapper) toResponseRequest(req providers.CompletionRequest)
 			Verbosity: req.TextVerbosity,
 		}
 	}
+	if schema := req.OutputSchema; schema != nil {
+		if apiReq.Text == nil {
+			apiReq.Text = &ResponseTextConfig{}
+		}
+		apiReq.Text.Format = ResponseTextFormat{
+			Type:       "json_schema",
+			JSONSchema: map[string]any{"name": schema.Name, "schema": schema.Schema, "strict": schema.Strict},
+		}
+	}
 	
 	return apiReq
 }
@@ -307,6 +357,7 @@ func (w *llmProviderWrapper) fromResponseObject(resp *ResponseObject) *providers
 			for _, content := range item.Content {
 				if content.Type == "text" || content.Type == "output_text" {
 					domainResp.Content += content.Text
+					domainResp.Annotations = append(domainResp.Annotations, fromResponseAnnotations(content.Annotations)...)
 				}
 			}
 		case "reasoning":
@@ -321,7 +372,7 @@ func (w *llmProviderWrapper) fromResponseObject(resp *ResponseObject) *providers
 		case "function_call":
 			var args map[string]any
 			if item.Arguments != "" {
-				json.Unmarshal([]byte(item.Arguments), &args)
+				jsonrepair.Unmarshal(resp.Model, []byte(item.Arguments), &args)
 			}
 			domainResp.ToolCalls = append(domainResp.ToolCalls, providers.ToolCall{
 				ID:        item.CallID,
@@ -364,9 +415,16 @@ func (r *responseStreamWrapper) Next() (*providers.StreamChunk, error) {
 		} else {
 			chunk.ReasoningSummary = apiChunk.Delta
 		}
+	case "response.output_text.annotation.added":
+		if apiChunk.Annotation != nil {
+			chunk.Annotations = fromResponseAnnotations([]ResponseAnnotation{*apiChunk.Annotation})
+		}
 	case "response.function_call_arguments.delta":
-		chunk.ToolArgs = apiChunk.Delta
+		chunk.ToolCallID = apiChunk.CallID
+		chunk.ToolName = apiChunk.Name
+		chunk.ToolArgsDelta = apiChunk.Delta
 	case "response.function_call_arguments.done":
+		chunk.ToolCallID = apiChunk.CallID
 		chunk.ToolName = apiChunk.Name
 		chunk.ToolArgs = apiChunk.Arguments
 	case "response.done":
@@ -387,3 +445,45 @@ func (r *responseStreamWrapper) Next() (*providers.StreamChunk, error) {
 func (r *responseStreamWrapper) Close() error {
 	return r.stream.Close()
 }
+
+func toResponseAnnotations(annotations []providers.Annotation) []ResponseAnnotation {
+	if len(annotations) == 0 {
+		return nil
+	}
+	converted := make([]ResponseAnnotation, len(annotations))
+	for i, ann := range annotations {
+		converted[i] = ResponseAnnotation{
+			Type:        string(ann.Type),
+			StartIndex:  ann.StartIndex,
+			EndIndex:    ann.EndIndex,
+			URL:         ann.URL,
+			Title:       ann.Title,
+			FileID:      ann.FileID,
+			Filename:    ann.Filename,
+			ContainerID: ann.ContainerID,
+			Index:       ann.Index,
+		}
+	}
+	return converted
+}
+
+func fromResponseAnnotations(annotations []ResponseAnnotation) []providers.Annotation {
+	if len(annotations) == 0 {
+		return nil
+	}
+	converted := make([]providers.Annotation, len(annotations))
+	for i, ann := range annotations {
+		converted[i] = providers.Annotation{
+			Type:        providers.AnnotationType(ann.Type),
+			StartIndex:  ann.StartIndex,
+			EndIndex:    ann.EndIndex,
+			URL:         ann.URL,
+			Title:       ann.Title,
+			FileID:      ann.FileID,
+			Filename:    ann.Filename,
+			ContainerID: ann.ContainerID,
+			Index:       ann.Index,
+		}
+	}
+	return converted
+}
@@ -0,0 +1,304 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	"github.com/darkostanimirovic/agentkit/providers/openai"
+)
+
+// Script describes the conversations the fake model holds: each run calls
+// ToolCalls tools, one per model turn, then streams an answer.
+type Script struct {
+	Tools         []ToolProfile // Tools the model calls, picked by weight (default one fast "lookup" tool)
+	ToolCalls     int           // Tool calls per run (default 2; negative for none)
+	AnswerChunks  int           // Chunks in the final answer (default 20)
+	Latency       time.Duration // Model time before each response
+	ChunkInterval time.Duration // Time between streamed chunks
+}
+
+func (s Script) withDefaults() Script {
+	if len(s.Tools) == 0 {
+		s.Tools = []ToolProfile{{Name: "lookup"}}
+	}
+	switch {
+	case s.ToolCalls == 0:
+		s.ToolCalls = 2
+	case s.ToolCalls < 0:
+		s.ToolCalls = 0
+	}
+	if s.AnswerChunks <= 0 {
+		s.AnswerChunks = 20
+	}
+	return s
+}
+
+// pickTool returns the name of a tool chosen by weight.
+func (s Script) pickTool() string {
+	total := 0
+	for _, tool := range s.Tools {
+		total += tool.weight()
+	}
+	n := rand.IntN(total)
+	for _, tool := range s.Tools {
+		if n -= tool.weight(); n < 0 {
+			return tool.Name
+		}
+	}
+	return s.Tools[0].Name
+}
+
+// nextToolCall returns the tool call for the model turn after toolResults
+// tool results, or false when the run should end with an answer.
+func (s Script) nextToolCall(toolResults int) (providers.ToolCall, bool) {
+	if toolResults >= s.ToolCalls {
+		return providers.ToolCall{}, false
+	}
+	return providers.ToolCall{
+		ID:        fmt.Sprintf("call_%d_%d", toolResults, rand.Uint32()),
+		Name:      s.pickTool(),
+		Arguments: map[string]any{"id": fmt.Sprint(toolResults)},
+	}, true
+}
+
+// answerChunks splits the final answer into AnswerChunks pieces.
+func (s Script) answerChunks() []string {
+	chunks := make([]string, s.AnswerChunks)
+	for i := range chunks {
+		chunks[i] = fmt.Sprintf("word%d ", i)
+	}
+	return chunks
+}
+
+// sleep waits d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var fakeUsage = providers.TokenUsage{PromptTokens: 100, CompletionTokens: 20, TotalTokens: 120}
+
+// FakeProvider is an in-process providers.Provider that plays a Script
+// for any number of concurrent runs. Unlike providers/mock it never runs
+// out of responses.
+type FakeProvider struct {
+	script Script
+	calls  atomic.Int64
+}
+
+// NewFakeProvider returns a provider playing script.
+func NewFakeProvider(script Script) *FakeProvider {
+	return &FakeProvider{script: script.withDefaults()}
+}
+
+// Name returns the provider name.
+func (p *FakeProvider) Name() string {
+	return "loadtest"
+}
+
+// Calls returns the number of model calls served.
+func (p *FakeProvider) Calls() int64 {
+	return p.calls.Load()
+}
+
+// Complete returns the next turn of the script.
+func (p *FakeProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	p.calls.Add(1)
+	if err := sleep(ctx, p.script.Latency); err != nil {
+		return nil, err
+	}
+	resp := &providers.CompletionResponse{
+		ID:           fmt.Sprintf("resp_%d", rand.Uint32()),
+		Model:        req.Model,
+		FinishReason: providers.FinishReasonStop,
+		Usage:        fakeUsage,
+		Created:      time.Now(),
+	}
+	if call, ok := p.script.nextToolCall(countToolResults(req.Messages)); ok {
+		resp.ToolCalls = []providers.ToolCall{call}
+		resp.FinishReason = providers.FinishReasonToolCalls
+	} else {
+		resp.Content = strings.Join(p.script.answerChunks(), "")
+	}
+	return resp, nil
+}
+
+// Stream streams the next turn of the script.
+func (p *FakeProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	p.calls.Add(1)
+	if err := sleep(ctx, p.script.Latency); err != nil {
+		return nil, err
+	}
+	var chunks []providers.StreamChunk
+	if call, ok := p.script.nextToolCall(countToolResults(req.Messages)); ok {
+		args, _ := json.Marshal(call.Arguments)
+		chunks = append(chunks,
+			providers.StreamChunk{ToolCallID: call.ID, ToolName: call.Name, ToolArgs: string(args)},
+			providers.StreamChunk{IsComplete: true, FinishReason: providers.FinishReasonToolCalls, Usage: &fakeUsage})
+	} else {
+		for _, text := range p.script.answerChunks() {
+			chunks = append(chunks, providers.StreamChunk{Content: text})
+		}
+		chunks = append(chunks, providers.StreamChunk{IsComplete: true, FinishReason: providers.FinishReasonStop, Usage: &fakeUsage})
+	}
+	return &fakeStream{ctx: ctx, chunks: chunks, interval: p.script.ChunkInterval}, nil
+}
+
+func countToolResults(messages []providers.Message) int {
+	n := 0
+	for _, msg := range messages {
+		if msg.Role == providers.RoleTool {
+			n++
+		}
+	}
+	return n
+}
+
+type fakeStream struct {
+	ctx      context.Context
+	chunks   []providers.StreamChunk
+	interval time.Duration
+	next     int
+}
+
+func (s *fakeStream) Next() (*providers.StreamChunk, error) {
+	if s.next >= len(s.chunks) {
+		return nil, io.EOF
+	}
+	if s.next > 0 {
+		if err := sleep(s.ctx, s.interval); err != nil {
+			return nil, err
+		}
+	}
+	chunk := s.chunks[s.next]
+	s.next++
+	return &chunk, nil
+}
+
+func (s *fakeStream) Close() error {
+	return nil
+}
+
+// FakeServer serves a Script over the OpenAI Responses API, streamed or
+// not, so load tests exercise the real HTTP client, SSE parsing and
+// connection pooling. Point the built-in provider at it with Provider, or
+// with Config.BaseURL set to its URL.
+type FakeServer struct {
+	*httptest.Server
+	script Script
+}
+
+// NewFakeServer starts a server playing script. Close it when done.
+func NewFakeServer(script Script) *FakeServer {
+	s := &FakeServer{script: script.withDefaults()}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveResponses))
+	return s
+}
+
+// Provider returns an OpenAI provider sending requests to the server.
+func (s *FakeServer) Provider() providers.Provider {
+	return openai.New("loadtest", slog.New(slog.DiscardHandler)).WithBaseURL(s.URL)
+}
+
+func (s *FakeServer) serveResponses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/responses") {
+		http.NotFound(w, r)
+		return
+	}
+	var req struct {
+		Model  string            `json:"model"`
+		Stream bool              `json:"stream"`
+		Input  []json.RawMessage `json:"input"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	toolResults := 0
+	for _, item := range req.Input {
+		var typed struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(item, &typed) == nil && typed.Type == "function_call_output" {
+			toolResults++
+		}
+	}
+	if sleep(r.Context(), s.script.Latency) != nil {
+		return
+	}
+
+	id := fmt.Sprintf("resp_%d", rand.Uint32())
+	var output []map[string]any
+	call, isCall := s.script.nextToolCall(toolResults)
+	if isCall {
+		args, _ := json.Marshal(call.Arguments)
+		output = append(output, map[string]any{
+			"type": "function_call", "id": "fc_" + call.ID, "call_id": call.ID, "name": call.Name, "arguments": string(args),
+		})
+	}
+	chunks := s.script.answerChunks()
+	if !isCall {
+		output = append(output, map[string]any{
+			"type": "message", "role": "assistant",
+			"content": []map[string]any{{"type": "output_text", "text": strings.Join(chunks, "")}},
+		})
+	}
+	response := map[string]any{
+		"id": id, "object": "response", "status": "completed", "model": req.Model, "output": output,
+		"usage": map[string]int{"input_tokens": fakeUsage.PromptTokens, "output_tokens": fakeUsage.CompletionTokens, "total_tokens": fakeUsage.TotalTokens},
+	}
+
+	if !req.Stream {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	flusher, _ := w.(http.Flusher)
+	send := func(event map[string]any) bool {
+		data, _ := json.Marshal(event)
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event["type"], data); err != nil {
+			return false
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+	if isCall {
+		if !send(map[string]any{"type": "response.output_item.done", "response_id": id, "item": output[0]}) {
+			return
+		}
+	} else {
+		for i, text := range chunks {
+			if i > 0 && sleep(r.Context(), s.script.ChunkInterval) != nil {
+				return
+			}
+			if !send(map[string]any{"type": "response.output_text.delta", "response_id": id, "delta": text}) {
+				return
+			}
+		}
+	}
+	// Output items were streamed above.
+	response["output"] = []map[string]any{}
+	send(map[string]any{"type": "response.completed", "response": response})
+}
@@ -0,0 +1,511 @@
+// Package loadtest drives an agent with many concurrent runs, against a
+// fake model in-process (FakeProvider) or over HTTP (FakeServer), and
+// reports latency percentiles, allocation rates and leaks:
+//
+//	report, err := loadtest.Run(ctx, loadtest.Config{
+//		Script:      loadtest.Script{ToolCalls: 3, Latency: 20 * time.Millisecond},
+//		Concurrency: 64,
+//		Duration:    time.Minute,
+//		Stream:      true,
+//		CancelRate:  0.1,
+//	})
+//	fmt.Print(report.Summary())
+//
+// Goroutines still running after the load, and runs whose event channel
+// never closed, are reported as leaks. Leaks that only show after hours of
+// streaming are caught in soak mode: a long Duration with periodic samples,
+// from which the report fits goroutine and heap growth per 1000 runs. The
+// cmd/loadtest command wraps Run with flags.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/internal/leakcheck"
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrLeak is returned by Report.Err when goroutines or runs leaked.
+var ErrLeak = errors.New("loadtest: leak detected")
+
+// errSimulated is returned by simulated tools on their ErrorRate.
+var errSimulated = errors.New("loadtest: simulated tool failure")
+
+// reservoirSize bounds the latencies kept for percentiles, so soak tests
+// run in constant memory.
+const reservoirSize = 10000
+
+// ToolProfile describes a tool in the mix the fake model calls.
+type ToolProfile struct {
+	Name       string
+	Weight     int                  // Relative call frequency (default 1)
+	Latency    time.Duration        // Simulated handler time
+	ErrorRate  float64              // Fraction of simulated calls that fail
+	ResultSize int                  // Bytes in the simulated result (default 64)
+	Handler    agentkit.ToolHandler // Runs instead of the simulation, to load-test a real tool
+}
+
+func (t ToolProfile) weight() int {
+	return max(t.Weight, 1)
+}
+
+func (t ToolProfile) tool() agentkit.Tool {
+	handler := t.Handler
+	if handler == nil {
+		result := strings.Repeat("x", max(t.ResultSize, 64))
+		if t.ResultSize > 0 {
+			result = result[:t.ResultSize]
+		}
+		handler = func(ctx context.Context, args map[string]any) (any, error) {
+			if err := sleep(ctx, t.Latency); err != nil {
+				return nil, err
+			}
+			if rand.Float64() < t.ErrorRate {
+				return nil, errSimulated
+			}
+			return result, nil
+		}
+	}
+	return agentkit.NewTool(t.Name).
+		WithDescription("Load test tool").
+		WithParameter("id", agentkit.String().Required()).
+		WithHandler(handler).
+		Build()
+}
+
+// Config configures a load test. Zero fields take the defaults noted.
+type Config struct {
+	// Script is what the fake model does in each run. Its Tools are added
+	// to the agent under test; with a FakeServer, pass the server's script.
+	Script
+	Concurrency int           // Runs in flight (default 8)
+	Duration    time.Duration // How long to start new runs; soak tests run for hours
+	Runs        int           // Stop after this many runs (default 1000 when Duration is zero)
+	Stream      bool          // Stream model responses (default agent only)
+	// CancelRate is the fraction of runs cancelled part-way through, as
+	// when clients disconnect; abandoned streams are where leaks hide.
+	CancelRate float64
+	Input      string // User input of every run (default "Look up order 42.")
+
+	// Provider serves the model calls (default NewFakeProvider(Script)).
+	Provider providers.Provider
+	// NewAgent builds the agent under test (default a silent agent with
+	// Provider). The Script's tools are added to it, replacing tools of
+	// the same name.
+	NewAgent    func(providers.Provider) (*agentkit.Agent, error)
+	AgentPerRun bool // Build an agent per run, as servers creating agents per request do
+
+	SampleInterval time.Duration // How often soak samples are taken (default 10s)
+	OnSample       func(Sample)  // Called with each sample, e.g. to print progress
+	// StallTimeout abandons a run that emits no event for this long; it is
+	// counted as stalled, a leak (default 30s).
+	StallTimeout time.Duration
+	// Settle is how long goroutines get to exit after the load before the
+	// survivors are reported as leaked (default 5s).
+	Settle        time.Duration
+	LeakTolerance int // Surviving goroutines not reported as leaked
+}
+
+func (c Config) withDefaults() Config {
+	c.Script = c.Script.withDefaults()
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.Duration <= 0 && c.Runs <= 0 {
+		c.Runs = 1000
+	}
+	if c.Input == "" {
+		c.Input = "Look up order 42."
+	}
+	if c.Provider == nil {
+		c.Provider = NewFakeProvider(c.Script)
+	}
+	if c.NewAgent == nil {
+		c.NewAgent = func(provider providers.Provider) (*agentkit.Agent, error) {
+			return agentkit.New(agentkit.Config{
+				Provider:        provider,
+				Model:           "loadtest",
+				MaxIterations:   min(c.Script.ToolCalls+1, 100),
+				StreamResponses: c.Stream,
+				Logging:         agentkit.LoggingConfig{}.Silent(),
+			})
+		}
+	}
+	if c.SampleInterval <= 0 {
+		c.SampleInterval = 10 * time.Second
+	}
+	if c.StallTimeout <= 0 {
+		c.StallTimeout = 30 * time.Second
+	}
+	if c.Settle <= 0 {
+		c.Settle = 5 * time.Second
+	}
+	return c
+}
+
+// Latency summarizes the durations of completed runs.
+type Latency struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+// Sample is a snapshot taken during the load.
+type Sample struct {
+	Elapsed    time.Duration `json:"elapsed"`
+	Runs       int           `json:"runs"`
+	Goroutines int           `json:"goroutines"`
+	ActiveRuns int           `json:"active_runs"` // agentkit.ActiveRuns, in all agents
+	HeapInuse  uint64        `json:"heap_inuse"`
+}
+
+// GoroutineLeak is a group of goroutines with the same stack that were not
+// running before the load test and still are after it.
+type GoroutineLeak = leakcheck.Leak
+
+// Report is the outcome of a load test.
+type Report struct {
+	Runs          int           `json:"runs"`
+	Errors        int           `json:"errors"`      // Runs that failed, cancelled runs aside
+	ToolErrors    int           `json:"tool_errors"` // Failed tool calls; the runs carry on
+	Cancelled     int           `json:"cancelled"`   // Runs cancelled by CancelRate
+	Stalled       int           `json:"stalled"`     // Runs abandoned after StallTimeout
+	Elapsed       time.Duration `json:"elapsed"`
+	RunsPerSecond float64       `json:"runs_per_second"`
+	Latency       Latency       `json:"latency"` // Of runs that completed without error
+
+	AllocsPerRun     float64 `json:"allocs_per_run"`
+	BytesPerRun      float64 `json:"bytes_per_run"`
+	AllocBytesPerSec float64 `json:"alloc_bytes_per_sec"`
+
+	GoroutinesBefore int             `json:"goroutines_before"`
+	GoroutinesAfter  int             `json:"goroutines_after"` // After Settle
+	LeakedGoroutines []GoroutineLeak `json:"leaked_goroutines,omitempty"`
+	// GoroutineGrowth and HeapGrowth are fitted to the samples, per 1000
+	// runs; steady growth during a soak test is a leak even when the
+	// goroutines exit at the end.
+	GoroutineGrowth float64  `json:"goroutine_growth"`
+	HeapGrowth      float64  `json:"heap_growth"`
+	Samples         []Sample `json:"samples,omitempty"`
+}
+
+// Err returns ErrLeak when goroutines survived the load or runs stalled.
+func (r *Report) Err() error {
+	leaked := 0
+	for _, leak := range r.LeakedGoroutines {
+		leaked += leak.Count
+	}
+	if leaked == 0 && r.Stalled == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %d goroutines, %d stalled runs", ErrLeak, leaked, r.Stalled)
+}
+
+// Summary formats the report for a terminal.
+func (r *Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "runs         %d in %s (%.1f/s): %d errors, %d cancelled, %d stalled; %d tool errors\n",
+		r.Runs, r.Elapsed.Round(time.Millisecond), r.RunsPerSecond, r.Errors, r.Cancelled, r.Stalled, r.ToolErrors)
+	fmt.Fprintf(&b, "latency      mean %s  p50 %s  p90 %s  p99 %s  max %s\n",
+		round(r.Latency.Mean), round(r.Latency.P50), round(r.Latency.P90), round(r.Latency.P99), round(r.Latency.Max))
+	fmt.Fprintf(&b, "allocations  %.0f allocs/run, %.1f KB/run, %.1f MB/s\n",
+		r.AllocsPerRun, r.BytesPerRun/1024, r.AllocBytesPerSec/(1024*1024))
+	fmt.Fprintf(&b, "goroutines   %d before, %d after", r.GoroutinesBefore, r.GoroutinesAfter)
+	if len(r.Samples) >= 3 {
+		fmt.Fprintf(&b, "; growth %+.2f goroutines and %+.1f KB heap per 1000 runs", r.GoroutineGrowth, r.HeapGrowth/1024)
+	}
+	b.WriteString("\n")
+	for _, leak := range r.LeakedGoroutines {
+		fmt.Fprintf(&b, "\nleaked %d goroutine(s):\n  %s\n", leak.Count, strings.ReplaceAll(leak.Stack, "\n", "\n  "))
+	}
+	return b.String()
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// Run runs the load test. It returns an error only when the agent cannot be
+// built; check Report.Err for leaks.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	cfg = cfg.withDefaults()
+	h := &harness{cfg: cfg}
+	if !cfg.AgentPerRun {
+		agent, err := h.newAgent()
+		if err != nil {
+			return nil, err
+		}
+		h.agent = agent
+	}
+
+	runtime.GC()
+	stacksBefore := leakcheck.Stacks()
+	report := &Report{GoroutinesBefore: runtime.NumGoroutine()}
+	var memBefore runtime.MemStats
+	runtime.ReadMemStats(&memBefore)
+
+	start := time.Now()
+	var deadline time.Time
+	if cfg.Duration > 0 {
+		deadline = start.Add(cfg.Duration)
+	}
+	stopSampling := make(chan struct{})
+	samplingDone := make(chan struct{})
+	go func() {
+		defer close(samplingDone)
+		ticker := time.NewTicker(cfg.SampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.sample(start)
+			case <-stopSampling:
+				return
+			}
+		}
+	}()
+
+	var started atomic.Int64
+	var wg sync.WaitGroup
+	for range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil && h.err() == nil {
+				if !deadline.IsZero() && time.Now().After(deadline) {
+					return
+				}
+				if cfg.Runs > 0 && started.Add(1) > int64(cfg.Runs) {
+					return
+				}
+				h.runOnce(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+	close(stopSampling)
+	<-samplingDone
+
+	var memAfter runtime.MemStats
+	runtime.ReadMemStats(&memAfter)
+	h.fill(report, memAfter.Mallocs-memBefore.Mallocs, memAfter.TotalAlloc-memBefore.TotalAlloc)
+
+	// Idle keep-alive connections hold goroutines on both ends; they are
+	// pooled, not leaked.
+	providers.DefaultHTTPClient().CloseIdleConnections()
+	http.DefaultClient.CloseIdleConnections()
+	settleBy := time.Now().Add(cfg.Settle)
+	for runtime.NumGoroutine() > report.GoroutinesBefore+cfg.LeakTolerance && time.Now().Before(settleBy) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	report.GoroutinesAfter = runtime.NumGoroutine()
+	if report.GoroutinesAfter > report.GoroutinesBefore+cfg.LeakTolerance {
+		report.LeakedGoroutines = leakcheck.Diff(stacksBefore, leakcheck.Stacks())
+	}
+	return report, h.err()
+}
+
+// harness holds the state shared by the workers of a load test.
+type harness struct {
+	cfg   Config
+	agent *agentkit.Agent // Shared unless AgentPerRun
+
+	completed  atomic.Int64
+	stalled    atomic.Int64
+	toolErrors atomic.Int64
+
+	mu        sync.Mutex
+	errors    int
+	cancelled int
+	latencies []time.Duration // Reservoir sample
+	seen      int
+	sum       time.Duration
+	max       time.Duration
+	samples   []Sample
+	firstErr  error
+}
+
+func (h *harness) newAgent() (*agentkit.Agent, error) {
+	agent, err := h.cfg.NewAgent(h.cfg.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("loadtest: build agent: %w", err)
+	}
+	for _, tool := range h.cfg.Script.Tools {
+		agent.AddTool(tool.tool())
+	}
+	return agent, nil
+}
+
+func (h *harness) err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.firstErr
+}
+
+// runOnce runs the agent once, draining its events.
+func (h *harness) runOnce(ctx context.Context) {
+	agent := h.agent
+	if agent == nil {
+		var err error
+		if agent, err = h.newAgent(); err != nil {
+			h.mu.Lock()
+			if h.firstErr == nil {
+				h.firstErr = err
+			}
+			h.mu.Unlock()
+			return
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cancelAfter := -1
+	if h.cfg.CancelRate > 0 && rand.Float64() < h.cfg.CancelRate {
+		cancelAfter = 1 + rand.IntN(8)
+	}
+
+	start := time.Now()
+	events := agent.Run(runCtx, h.cfg.Input)
+	stall := time.NewTimer(h.cfg.StallTimeout)
+	defer stall.Stop()
+	received, failed, cancelled := 0, false, false
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				h.record(time.Since(start), failed, cancelled)
+				return
+			}
+			received++
+			if detail, ok := event.ErrorDetail(); ok {
+				if detail.Tool != "" {
+					h.toolErrors.Add(1)
+				} else {
+					failed = true
+				}
+			}
+			if received == cancelAfter {
+				cancel()
+				cancelled = true
+			}
+			stall.Reset(h.cfg.StallTimeout)
+		case <-stall.C:
+			h.stalled.Add(1)
+			go func() {
+				for range events {
+				}
+			}()
+			return
+		}
+	}
+}
+
+func (h *harness) record(d time.Duration, failed, cancelled bool) {
+	h.completed.Add(1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch {
+	case cancelled:
+		h.cancelled++
+		return
+	case failed:
+		h.errors++
+		return
+	}
+	h.seen++
+	h.sum += d
+	h.max = max(h.max, d)
+	if len(h.latencies) < reservoirSize {
+		h.latencies = append(h.latencies, d)
+	} else if i := rand.IntN(h.seen); i < reservoirSize {
+		h.latencies[i] = d
+	}
+}
+
+func (h *harness) sample(start time.Time) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	sample := Sample{
+		Elapsed:    time.Since(start),
+		Runs:       int(h.completed.Load()),
+		Goroutines: runtime.NumGoroutine(),
+		ActiveRuns: agentkit.ActiveRuns(),
+		HeapInuse:  mem.HeapInuse,
+	}
+	h.mu.Lock()
+	h.samples = append(h.samples, sample)
+	h.mu.Unlock()
+	if h.cfg.OnSample != nil {
+		h.cfg.OnSample(sample)
+	}
+}
+
+// fill copies the collected measurements into report.
+func (h *harness) fill(report *Report, mallocs, allocBytes uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	report.Stalled = int(h.stalled.Load())
+	report.Runs = int(h.completed.Load()) + report.Stalled
+	report.Errors = h.errors
+	report.ToolErrors = int(h.toolErrors.Load())
+	report.Cancelled = h.cancelled
+	report.Samples = h.samples
+	if seconds := report.Elapsed.Seconds(); seconds > 0 {
+		report.RunsPerSecond = float64(report.Runs) / seconds
+		report.AllocBytesPerSec = float64(allocBytes) / seconds
+	}
+	if report.Runs > 0 {
+		report.AllocsPerRun = float64(mallocs) / float64(report.Runs)
+		report.BytesPerRun = float64(allocBytes) / float64(report.Runs)
+	}
+	if h.seen > 0 {
+		sorted := slices.Clone(h.latencies)
+		slices.Sort(sorted)
+		at := func(q float64) time.Duration { return sorted[int(q*float64(len(sorted)-1))] }
+		report.Latency = Latency{Mean: h.sum / time.Duration(h.seen), P50: at(0.50), P90: at(0.90), P99: at(0.99), Max: h.max}
+	}
+	report.GoroutineGrowth = growth(h.samples, func(s Sample) float64 { return float64(s.Goroutines) })
+	report.HeapGrowth = growth(h.samples, func(s Sample) float64 { return float64(s.HeapInuse) })
+}
+
+// growth fits a line to metric over completed runs and returns its slope
+// per 1000 runs, or 0 with fewer than 3 samples.
+func growth(samples []Sample, metric func(Sample) float64) float64 {
+	if len(samples) < 3 {
+		return 0
+	}
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range samples {
+		x, y := float64(s.Runs), metric(s)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(samples))
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator * 1000
+}
@@ -0,0 +1,124 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+func TestRun_FakeProvider(t *testing.T) {
+	script := Script{ToolCalls: 2, Tools: []ToolProfile{{Name: "lookup", Weight: 3}, {Name: "search", ErrorRate: 0.5}}}
+	provider := NewFakeProvider(script)
+	report, err := Run(context.Background(), Config{
+		Provider:    provider,
+		Script:      script,
+		Runs:        200,
+		Concurrency: 8,
+		Stream:      true,
+		CancelRate:  0.2,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Runs != 200 || report.Cancelled == 0 || report.Errors != 0 || report.ToolErrors == 0 {
+		t.Errorf("runs = %d, cancelled = %d, errors = %d, tool errors = %d", report.Runs, report.Cancelled, report.Errors, report.ToolErrors)
+	}
+	if report.Latency.P50 <= 0 || report.Latency.P99 < report.Latency.P50 || report.Latency.Max < report.Latency.P99 {
+		t.Errorf("latency = %+v", report.Latency)
+	}
+	if report.AllocsPerRun <= 0 || report.RunsPerSecond <= 0 {
+		t.Errorf("allocs/run = %v, runs/s = %v", report.AllocsPerRun, report.RunsPerSecond)
+	}
+	if calls := provider.Calls(); calls < 200 {
+		t.Errorf("model calls = %d", calls)
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("Err() = %v\n%s", err, report.Summary())
+	}
+}
+
+func TestRun_FakeServer(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		script := Script{ToolCalls: 1, AnswerChunks: 5}
+		server := NewFakeServer(script)
+		report, err := Run(context.Background(), Config{Script: script, Provider: server.Provider(), Runs: 40, Concurrency: 4, Stream: stream})
+		server.Close()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if report.Runs != 40 || report.Errors != 0 {
+			t.Errorf("stream %v: runs = %d, errors = %d", stream, report.Runs, report.Errors)
+		}
+		if err := report.Err(); err != nil {
+			t.Errorf("stream %v: Err() = %v\n%s", stream, err, report.Summary())
+		}
+	}
+}
+
+func TestRun_DetectsLeakedGoroutines(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	leaky := func(ctx context.Context, args map[string]any) (any, error) {
+		go func() { <-block }()
+		return "ok", nil
+	}
+
+	report, err := Run(context.Background(), Config{
+		Script: Script{ToolCalls: 1, Tools: []ToolProfile{{Name: "leaky", Handler: leaky}}},
+		Runs:   10,
+		Settle: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !errors.Is(report.Err(), ErrLeak) {
+		t.Fatalf("Err() = %v, want ErrLeak", report.Err())
+	}
+	if leak := report.LeakedGoroutines[0]; leak.Count != 10 || !strings.Contains(leak.Stack, "TestRun_DetectsLeakedGoroutines") {
+		t.Errorf("leak = %+v", leak)
+	}
+	if summary := report.Summary(); !strings.Contains(summary, "leaked 10 goroutine(s)") {
+		t.Errorf("summary:\n%s", summary)
+	}
+}
+
+// hangingProvider never answers, even when its context is cancelled.
+type hangingProvider struct {
+	*FakeProvider
+	release chan struct{}
+}
+
+func (p hangingProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	<-p.release
+	return nil, context.Canceled
+}
+
+func TestRun_DetectsStalledRuns(t *testing.T) {
+	provider := hangingProvider{FakeProvider: NewFakeProvider(Script{}), release: make(chan struct{})}
+	defer close(provider.release)
+
+	report, err := Run(context.Background(), Config{Provider: provider, Runs: 3, StallTimeout: 50 * time.Millisecond, Settle: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Stalled != 3 || !errors.Is(report.Err(), ErrLeak) {
+		t.Errorf("stalled = %d, Err() = %v", report.Stalled, report.Err())
+	}
+}
+
+func TestGrowth(t *testing.T) {
+	var samples []Sample
+	for i := range 5 {
+		samples = append(samples, Sample{Runs: i * 500, Goroutines: 10 + i})
+	}
+	if got := growth(samples, func(s Sample) float64 { return float64(s.Goroutines) }); got != 2 {
+		t.Errorf("growth = %v, want 2 per 1000 runs", got)
+	}
+	if got := growth(samples[:2], func(s Sample) float64 { return float64(s.Goroutines) }); got != 0 {
+		t.Errorf("growth of 2 samples = %v, want 0", got)
+	}
+}
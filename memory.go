@@ -0,0 +1,252 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/memory"
+)
+
+// DefaultMemoryRecallLimit is how many memories are injected per run when
+// MemoryConfig.RecallLimit is not set.
+const DefaultMemoryRecallLimit = 5
+
+// ErrMemoryNotConfigured is returned by memory methods on agents without Config.Memory.
+var ErrMemoryNotConfigured = errors.New("agentkit: memory is not configured")
+
+// MemoryConfig enables scoped long-term memory on an agent.
+//
+// Session memories are owned by the conversation ID (WithConversation) and
+// user memories by the user ID (WithUser). Scopes whose owner is missing from
+// the context are skipped on read and rejected on write.
+type MemoryConfig struct {
+	// Memory stores and recalls memories, e.g. memory.NewEpisodic or
+	// memory.NewSemantic. It takes precedence over Store.
+	Memory memory.Memory
+	// Store is a plain store used through memory.FromStore when Memory is nil.
+	Store memory.Store
+	// ReadScopes are searched before each run (default: all scopes). Override
+	// per run with WithMemoryScopes.
+	ReadScopes []memory.Scope
+	// WriteScope is used by Remember when the item names no scope (default: user).
+	WriteScope memory.Scope
+	// RecallLimit caps the memories injected into the system prompt.
+	RecallLimit int
+	// DisableInjection stops recalled memories from being added to the system prompt.
+	DisableInjection bool
+	// Policy decides what is persisted after each successful run. Nil writes
+	// nothing automatically; see memory.RulePolicy and memory.LLMPolicy.
+	Policy memory.Policy
+	// RememberTool registers a "remember" tool so the model can store
+	// memories explicitly.
+	RememberTool bool
+	// RememberGlobal lets the remember tool store global memories, which
+	// every user sees. Leave it off unless the model's input is trusted: a
+	// prompt-injected model could otherwise plant memories for all users.
+	RememberGlobal bool
+}
+
+const (
+	userIDKey       contextKey = "agentkit_user_id"
+	memoryScopesKey contextKey = "agentkit_memory_scopes"
+)
+
+// WithUser adds the end-user ID to the context. It owns user-scoped memory.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// GetUserID retrieves the end-user ID from the context.
+func GetUserID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(userIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithMemoryScopes overrides MemoryConfig.ReadScopes for runs using ctx.
+func WithMemoryScopes(ctx context.Context, scopes ...memory.Scope) context.Context {
+	return context.WithValue(ctx, memoryScopesKey, scopes)
+}
+
+// memoryNamespace resolves the namespace for scope from the context.
+func memoryNamespace(ctx context.Context, scope memory.Scope) (memory.Namespace, error) {
+	ns := memory.Namespace{Scope: scope}
+	switch scope {
+	case memory.ScopeSession:
+		ns.Owner, _ = GetConversationID(ctx)
+	case memory.ScopeUser:
+		ns.Owner, _ = GetUserID(ctx)
+	}
+	return ns, ns.Validate()
+}
+
+// Remember stores a memory. An empty item.Namespace.Scope uses
+// MemoryConfig.WriteScope, and an empty owner is taken from the context.
+func (a *Agent) Remember(ctx context.Context, item memory.Item) (memory.Item, error) {
+	if a.memory == nil {
+		return memory.Item{}, ErrMemoryNotConfigured
+	}
+	if item.Namespace.Scope == "" {
+		item.Namespace.Scope = a.memory.WriteScope
+	}
+	if item.Namespace.Owner == "" {
+		ns, err := memoryNamespace(ctx, item.Namespace.Scope)
+		if err != nil {
+			return memory.Item{}, err
+		}
+		item.Namespace = ns
+	}
+	return a.memory.Memory.Store(ctx, item)
+}
+
+// ForgetMemory removes a memory. An empty ns.Owner is taken from the context.
+func (a *Agent) ForgetMemory(ctx context.Context, ns memory.Namespace, id string) error {
+	if a.memory == nil {
+		return ErrMemoryNotConfigured
+	}
+	if ns.Owner == "" {
+		resolved, err := memoryNamespace(ctx, ns.Scope)
+		if err != nil {
+			return err
+		}
+		ns = resolved
+	}
+	return a.memory.Memory.Forget(ctx, ns, id)
+}
+
+// RecallMemories searches the given scopes (default: the configured read
+// scopes) for memories relevant to query.
+func (a *Agent) RecallMemories(ctx context.Context, query string, scopes ...memory.Scope) ([]memory.Item, error) {
+	if a.memory == nil {
+		return nil, ErrMemoryNotConfigured
+	}
+	if len(scopes) == 0 {
+		scopes = a.readScopes(ctx)
+	}
+	var namespaces []memory.Namespace
+	for _, scope := range scopes {
+		ns, err := memoryNamespace(ctx, scope)
+		if errors.Is(err, memory.ErrMissingOwner) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, ns)
+	}
+	if len(namespaces) == 0 {
+		return nil, nil
+	}
+	return a.memory.Memory.Recall(ctx, query, namespaces, a.memory.RecallLimit)
+}
+
+func (a *Agent) readScopes(ctx context.Context) []memory.Scope {
+	if scopes, ok := ctx.Value(memoryScopesKey).([]memory.Scope); ok {
+		return scopes
+	}
+	return a.memory.ReadScopes
+}
+
+// recallMemories adds memories relevant to userMessage to the system prompt.
+func (a *Agent) recallMemories(ctx context.Context, userMessage string) context.Context {
+	if a.memory == nil || a.memory.DisableInjection {
+		return ctx
+	}
+	items, err := a.RecallMemories(ctx, userMessage)
+	if err != nil {
+		a.logger.Warn("memory recall failed", "error", err)
+		return ctx
+	}
+	if len(items) == 0 {
+		return ctx
+	}
+	var sb strings.Builder
+	sb.WriteString("## Memories")
+	for _, item := range items {
+		if item.Metadata["kind"] == "episode" {
+			// Episodes are about when something happened.
+			fmt.Fprintf(&sb, "\n- [%s, %s] %s", item.Namespace.Scope, item.CreatedAt.Format(time.DateOnly), item.Content)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n- [%s] %s", item.Namespace.Scope, item.Content)
+	}
+	return withPromptSection(ctx, sb.String())
+}
+
+// NewRememberTool returns a tool that lets the model store a memory through
+// agent.Remember, in the configured write scope unless it picks another. The
+// model may pick the global scope only with MemoryConfig.RememberGlobal.
+func NewRememberTool(agent *Agent) Tool {
+	global := agent.memory != nil && agent.memory.RememberGlobal
+	scopes := []string{string(memory.ScopeSession), string(memory.ScopeUser)}
+	description := "session: this conversation only; user: this user across conversations"
+	if global {
+		scopes = append(scopes, string(memory.ScopeGlobal))
+		description += "; global: everyone"
+	}
+	return NewTool("remember").
+		WithDescription("Store a durable fact for future conversations, such as a user preference, personal detail or standing instruction. Do not store transient details.").
+		WithParameter("content", String().Required().WithDescription("The fact to remember, written to be understood without this conversation")).
+		WithParameter("scope", String().Optional().WithEnum(scopes...).WithDescription(description)).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			content, _ := args["content"].(string)
+			if strings.TrimSpace(content) == "" {
+				return nil, errors.New("content is required")
+			}
+			scope, _ := args["scope"].(string)
+			if memory.Scope(scope) == memory.ScopeGlobal && !global {
+				return nil, errors.New("global memories are not allowed")
+			}
+			item, err := agent.Remember(ctx, memory.Item{
+				Namespace: memory.Namespace{Scope: memory.Scope(scope)},
+				Content:   strings.TrimSpace(content),
+				Metadata:  map[string]any{"origin": "remember_tool"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"remembered": true, "id": item.ID, "scope": item.Namespace.Scope}, nil
+		}).
+		Build()
+}
+
+// writeMemories applies the memory policy to a completed exchange, skipping
+// items the store already holds. Shadow runs write nothing.
+func (a *Agent) writeMemories(ctx context.Context, userMessage, output string) {
+	if a.memory == nil || a.memory.Policy == nil || IsShadowRun(ctx) {
+		return
+	}
+	items, err := a.memory.Policy.Select(ctx, memory.Exchange{Input: userMessage, Output: output})
+	if err != nil {
+		a.logger.Warn("memory policy failed", "error", err)
+		return
+	}
+	for _, item := range items {
+		if a.hasMemory(ctx, item) {
+			continue
+		}
+		if _, err := a.Remember(ctx, item); err != nil {
+			a.logger.Warn("memory write failed", "error", err, "scope", item.Namespace.Scope)
+		}
+	}
+}
+
+// hasMemory reports whether an item with the same content is already stored in its scope.
+func (a *Agent) hasMemory(ctx context.Context, item memory.Item) bool {
+	scope := item.Namespace.Scope
+	if scope == "" {
+		scope = a.memory.WriteScope
+	}
+	existing, err := a.RecallMemories(ctx, item.Content, scope)
+	if err != nil {
+		return false
+	}
+	for _, e := range existing {
+		if strings.EqualFold(strings.TrimSpace(e.Content), strings.TrimSpace(item.Content)) {
+			return true
+		}
+	}
+	return false
+}
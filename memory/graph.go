@@ -0,0 +1,340 @@
+// Package memory provides long-lived agent memory that outlives a single
+// conversation.
+//
+// A Store holds remembered facts partitioned by Scope: session memories
+// belong to one conversation, user memories follow one end-user, and global
+// memories are shared by everyone. Reads and writes always name the
+// namespaces they touch, so one user's memories never leak into another's.
+//
+// GraphMemory keeps a knowledge graph of entities and the relations between
+// them, extracted from conversations and documents. Graph lookups follow
+// relations across several hops, which recovers connected facts ("who manages
+// the team that owns billing?") that similarity search over text chunks misses.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Common graph errors.
+var (
+	ErrEntityNotFound = errors.New("memory: entity not found")
+	ErrNoPath         = errors.New("memory: no path between entities")
+)
+
+// Entity is a node in the knowledge graph. Names are matched case-insensitively.
+type Entity struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Sources    []string          `json:"sources,omitempty"`
+}
+
+// Relation is a directed, typed edge between two entities.
+type Relation struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Type   string `json:"type"`
+	Source string `json:"source,omitempty"`
+}
+
+// Subgraph is a set of entities and the relations between them.
+type Subgraph struct {
+	Entities  []Entity   `json:"entities"`
+	Relations []Relation `json:"relations"`
+}
+
+// Empty reports whether the subgraph has no relations and no entities.
+func (s Subgraph) Empty() bool {
+	return len(s.Entities) == 0 && len(s.Relations) == 0
+}
+
+// String renders the subgraph as one fact per line, suitable for a prompt.
+func (s Subgraph) String() string {
+	var sb strings.Builder
+	for _, e := range s.Entities {
+		if e.Type == "" && len(e.Attributes) == 0 {
+			continue
+		}
+		sb.WriteString("- ")
+		sb.WriteString(e.Name)
+		if e.Type != "" {
+			fmt.Fprintf(&sb, " (%s)", e.Type)
+		}
+		for _, key := range sortedKeys(e.Attributes) {
+			fmt.Fprintf(&sb, "; %s: %s", key, e.Attributes[key])
+		}
+		sb.WriteByte('\n')
+	}
+	for _, r := range s.Relations {
+		fmt.Fprintf(&sb, "- %s %s %s\n", r.From, r.Type, r.To)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// GraphStore persists a knowledge graph.
+type GraphStore interface {
+	// Upsert merges entities (by name) and adds relations, ignoring duplicates.
+	// Relations referencing unknown entities create them.
+	Upsert(ctx context.Context, entities []Entity, relations []Relation) error
+	// Entity returns the entity with the given name or ErrEntityNotFound.
+	Entity(ctx context.Context, name string) (Entity, error)
+	// Neighbors returns the subgraph within depth hops of name, following relations in both directions.
+	Neighbors(ctx context.Context, name string, depth int) (Subgraph, error)
+	// Path returns the shortest chain of relations linking from and to, or ErrNoPath.
+	Path(ctx context.Context, from, to string, maxDepth int) ([]Relation, error)
+	// Mentions returns the known entities whose names appear in text.
+	Mentions(ctx context.Context, text string) ([]Entity, error)
+}
+
+// InMemoryGraph is a GraphStore held in memory. It is safe for concurrent use.
+type InMemoryGraph struct {
+	mu        sync.RWMutex
+	entities  map[string]*Entity // keyed by normalized name
+	relations []Relation
+	edges     map[string][]int // normalized name -> indexes into relations
+	seen      map[string]bool  // relation dedup keys
+}
+
+// NewInMemoryGraph creates an empty in-memory graph store.
+func NewInMemoryGraph() *InMemoryGraph {
+	return &InMemoryGraph{
+		entities: make(map[string]*Entity),
+		edges:    make(map[string][]int),
+		seen:     make(map[string]bool),
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Upsert implements GraphStore.
+func (g *InMemoryGraph) Upsert(ctx context.Context, entities []Entity, relations []Relation) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, e := range entities {
+		if normalizeName(e.Name) == "" {
+			continue
+		}
+		g.mergeEntity(e)
+	}
+	for _, r := range relations {
+		from, to, typ := normalizeName(r.From), normalizeName(r.To), strings.TrimSpace(r.Type)
+		if from == "" || to == "" || typ == "" || from == to {
+			continue
+		}
+		key := from + "\x00" + strings.ToLower(typ) + "\x00" + to
+		if g.seen[key] {
+			continue
+		}
+		g.seen[key] = true
+		r.From = g.mergeEntity(Entity{Name: r.From}).Name
+		r.To = g.mergeEntity(Entity{Name: r.To}).Name
+		r.Type = typ
+		g.relations = append(g.relations, r)
+		index := len(g.relations) - 1
+		g.edges[from] = append(g.edges[from], index)
+		g.edges[to] = append(g.edges[to], index)
+	}
+	return nil
+}
+
+// mergeEntity adds e or merges it into the existing entity with the same name.
+func (g *InMemoryGraph) mergeEntity(e Entity) *Entity {
+	key := normalizeName(e.Name)
+	existing, ok := g.entities[key]
+	if !ok {
+		existing = &Entity{Name: strings.TrimSpace(e.Name)}
+		g.entities[key] = existing
+	}
+	if e.Type != "" {
+		existing.Type = e.Type
+	}
+	for k, v := range e.Attributes {
+		if existing.Attributes == nil {
+			existing.Attributes = make(map[string]string)
+		}
+		existing.Attributes[k] = v
+	}
+	for _, source := range e.Sources {
+		if !contains(existing.Sources, source) {
+			existing.Sources = append(existing.Sources, source)
+		}
+	}
+	return existing
+}
+
+// Entity implements GraphStore.
+func (g *InMemoryGraph) Entity(ctx context.Context, name string) (Entity, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	e, ok := g.entities[normalizeName(name)]
+	if !ok {
+		return Entity{}, fmt.Errorf("%w: %s", ErrEntityNotFound, name)
+	}
+	return copyEntity(e), nil
+}
+
+// Neighbors implements GraphStore.
+func (g *InMemoryGraph) Neighbors(ctx context.Context, name string, depth int) (Subgraph, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	start := normalizeName(name)
+	if _, ok := g.entities[start]; !ok {
+		return Subgraph{}, fmt.Errorf("%w: %s", ErrEntityNotFound, name)
+	}
+	if depth <= 0 {
+		depth = 1
+	}
+
+	visited := map[string]bool{start: true}
+	order := []string{start}
+	included := make(map[int]bool)
+	frontier := []string{start}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, node := range frontier {
+			for _, index := range g.edges[node] {
+				included[index] = true
+				other := g.otherEnd(index, node)
+				if !visited[other] {
+					visited[other] = true
+					order = append(order, other)
+					next = append(next, other)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	var sub Subgraph
+	for _, key := range order {
+		sub.Entities = append(sub.Entities, copyEntity(g.entities[key]))
+	}
+	indexes := make([]int, 0, len(included))
+	for index := range included {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		sub.Relations = append(sub.Relations, g.relations[index])
+	}
+	return sub, nil
+}
+
+// Path implements GraphStore with a breadth-first search that follows
+// relations in either direction.
+func (g *InMemoryGraph) Path(ctx context.Context, from, to string, maxDepth int) ([]Relation, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	start, goal := normalizeName(from), normalizeName(to)
+	for _, name := range []string{from, to} {
+		if _, ok := g.entities[normalizeName(name)]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrEntityNotFound, name)
+		}
+	}
+	if start == goal {
+		return nil, nil
+	}
+	if maxDepth <= 0 {
+		maxDepth = 4
+	}
+
+	type step struct {
+		prev     string
+		relation int
+	}
+	came := map[string]step{start: {relation: -1}}
+	frontier := []string{start}
+	for hop := 0; hop < maxDepth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, node := range frontier {
+			for _, index := range g.edges[node] {
+				other := g.otherEnd(index, node)
+				if _, seen := came[other]; seen {
+					continue
+				}
+				came[other] = step{prev: node, relation: index}
+				if other == goal {
+					var path []Relation
+					for at := goal; at != start; at = came[at].prev {
+						path = append([]Relation{g.relations[came[at].relation]}, path...)
+					}
+					return path, nil
+				}
+				next = append(next, other)
+			}
+		}
+		frontier = next
+	}
+	return nil, fmt.Errorf("%w: %s and %s", ErrNoPath, from, to)
+}
+
+// Mentions implements GraphStore by matching entity names as whole words.
+func (g *InMemoryGraph) Mentions(ctx context.Context, text string) ([]Entity, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	haystack := " " + strings.Join(strings.FieldsFunc(strings.ToLower(text), isSeparator), " ") + " "
+	var found []Entity
+	for key, e := range g.entities {
+		needle := " " + strings.Join(strings.FieldsFunc(key, isSeparator), " ") + " "
+		if strings.TrimSpace(needle) != "" && strings.Contains(haystack, needle) {
+			found = append(found, copyEntity(e))
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found, nil
+}
+
+func (g *InMemoryGraph) otherEnd(index int, node string) string {
+	r := g.relations[index]
+	if from := normalizeName(r.From); from != node {
+		return from
+	}
+	return normalizeName(r.To)
+}
+
+func isSeparator(r rune) bool {
+	return !(r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r > 127)
+}
+
+func copyEntity(e *Entity) Entity {
+	c := *e
+	if e.Attributes != nil {
+		c.Attributes = make(map[string]string, len(e.Attributes))
+		for k, v := range e.Attributes {
+			c.Attributes[k] = v
+		}
+	}
+	c.Sources = append([]string(nil), e.Sources...)
+	return c
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
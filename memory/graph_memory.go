@@ -0,0 +1,155 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Default GraphMemory limits.
+const (
+	DefaultGraphDepth        = 2
+	DefaultGraphMaxRelations = 30
+)
+
+// ErrNoExtractor is returned when GraphMemory.Learn is called without an Extractor.
+var ErrNoExtractor = errors.New("memory: graph memory has no extractor")
+
+// Extractor pulls entities and relations out of free text.
+type Extractor interface {
+	Extract(ctx context.Context, text string) ([]Entity, []Relation, error)
+}
+
+// GraphMemory combines a GraphStore with an Extractor: Learn grows the graph
+// from text and Recall returns the subgraph relevant to a query.
+type GraphMemory struct {
+	Store     GraphStore
+	Extractor Extractor
+	// Depth is how many hops Recall follows from each mentioned entity.
+	Depth int
+	// MaxRelations caps the relations Recall returns, keeping injected context small.
+	MaxRelations int
+}
+
+// NewGraphMemory creates a graph memory with default limits.
+func NewGraphMemory(store GraphStore, extractor Extractor) *GraphMemory {
+	return &GraphMemory{
+		Store:        store,
+		Extractor:    extractor,
+		Depth:        DefaultGraphDepth,
+		MaxRelations: DefaultGraphMaxRelations,
+	}
+}
+
+// Learn extracts entities and relations from text and merges them into the
+// graph, tagging them with source (a conversation ID, document path, ...).
+func (g *GraphMemory) Learn(ctx context.Context, text, source string) error {
+	if g.Extractor == nil {
+		return ErrNoExtractor
+	}
+	entities, relations, err := g.Extractor.Extract(ctx, text)
+	if err != nil {
+		return fmt.Errorf("memory: extract: %w", err)
+	}
+	if source != "" {
+		for i := range entities {
+			entities[i].Sources = append(entities[i].Sources, source)
+		}
+		for i := range relations {
+			if relations[i].Source == "" {
+				relations[i].Source = source
+			}
+		}
+	}
+	return g.Store.Upsert(ctx, entities, relations)
+}
+
+// Recall returns the neighborhood of every known entity mentioned in text.
+func (g *GraphMemory) Recall(ctx context.Context, text string) (Subgraph, error) {
+	mentioned, err := g.Store.Mentions(ctx, text)
+	if err != nil {
+		return Subgraph{}, err
+	}
+	depth := g.Depth
+	if depth <= 0 {
+		depth = DefaultGraphDepth
+	}
+	maxRelations := g.MaxRelations
+	if maxRelations <= 0 {
+		maxRelations = DefaultGraphMaxRelations
+	}
+
+	var result Subgraph
+	seenEntities := make(map[string]bool)
+	seenRelations := make(map[Relation]bool)
+	for _, e := range mentioned {
+		sub, err := g.Store.Neighbors(ctx, e.Name, depth)
+		if err != nil {
+			if errors.Is(err, ErrEntityNotFound) {
+				continue
+			}
+			return Subgraph{}, err
+		}
+		for _, entity := range sub.Entities {
+			if key := normalizeName(entity.Name); !seenEntities[key] {
+				seenEntities[key] = true
+				result.Entities = append(result.Entities, entity)
+			}
+		}
+		for _, r := range sub.Relations {
+			if len(result.Relations) >= maxRelations {
+				break
+			}
+			if !seenRelations[r] {
+				seenRelations[r] = true
+				result.Relations = append(result.Relations, r)
+			}
+		}
+	}
+	return result, nil
+}
+
+// LLMExtractor extracts entities and relations by prompting a language model for JSON.
+type LLMExtractor struct {
+	Provider providers.Provider
+	Model    string
+}
+
+const extractorPrompt = `Extract a knowledge graph from the text.
+Return JSON only, in the form:
+{"entities": [{"name": "...", "type": "person|organization|project|product|place|concept|...", "attributes": {"key": "value"}}],
+ "relations": [{"from": "entity name", "type": "short_verb_phrase", "to": "entity name"}]}
+Use canonical names, lowercase snake_case relation types (works_at, manages, depends_on), and only facts stated in the text.`
+
+// Extract implements Extractor.
+func (x *LLMExtractor) Extract(ctx context.Context, text string) ([]Entity, []Relation, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil, nil
+	}
+	resp, err := x.Provider.Complete(ctx, providers.CompletionRequest{
+		Model:        x.Model,
+		SystemPrompt: extractorPrompt,
+		Messages:     []providers.Message{{Role: providers.RoleUser, Content: text}},
+		TextFormat:   "json_object",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := resp.Content
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		content = content[start : end+1]
+	}
+	var graph struct {
+		Entities  []Entity   `json:"entities"`
+		Relations []Relation `json:"relations"`
+	}
+	if err := json.Unmarshal([]byte(content), &graph); err != nil {
+		return nil, nil, fmt.Errorf("decode extraction: %w", err)
+	}
+	return graph.Entities, graph.Relations, nil
+}
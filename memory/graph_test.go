@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func seededGraph(t *testing.T) *InMemoryGraph {
+	t.Helper()
+	g := NewInMemoryGraph()
+	err := g.Upsert(context.Background(),
+		[]Entity{
+			{Name: "Ana", Type: "person"},
+			{Name: "Billing Team", Type: "team"},
+			{Name: "Invoice Service", Type: "service", Attributes: map[string]string{"language": "Go"}},
+		},
+		[]Relation{
+			{From: "Ana", Type: "manages", To: "Billing Team"},
+			{From: "billing team", Type: "owns", To: "Invoice Service"},
+			{From: "Invoice Service", Type: "depends_on", To: "Postgres"},
+			{From: "Ana", Type: "manages", To: "Billing Team"}, // duplicate
+		},
+	)
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	return g
+}
+
+func TestInMemoryGraph_Neighbors(t *testing.T) {
+	g := seededGraph(t)
+	ctx := context.Background()
+
+	sub, err := g.Neighbors(ctx, "ana", 1)
+	if err != nil {
+		t.Fatalf("Neighbors() error = %v", err)
+	}
+	if len(sub.Relations) != 1 || len(sub.Entities) != 2 {
+		t.Errorf("depth 1 = %+v", sub)
+	}
+
+	sub, _ = g.Neighbors(ctx, "Ana", 3)
+	if len(sub.Relations) != 3 || len(sub.Entities) != 4 {
+		t.Errorf("depth 3 = %+v", sub)
+	}
+	if !strings.Contains(sub.String(), "- Billing Team owns Invoice Service") ||
+		!strings.Contains(sub.String(), "- Invoice Service (service); language: Go") {
+		t.Errorf("String() = %q", sub.String())
+	}
+
+	if _, err := g.Neighbors(ctx, "Nobody", 1); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("error = %v, want ErrEntityNotFound", err)
+	}
+}
+
+func TestInMemoryGraph_Path(t *testing.T) {
+	g := seededGraph(t)
+	ctx := context.Background()
+
+	path, err := g.Path(ctx, "Postgres", "Ana", 0)
+	if err != nil {
+		t.Fatalf("Path() error = %v", err)
+	}
+	if len(path) != 3 || path[0].Type != "depends_on" || path[2].Type != "manages" {
+		t.Errorf("path = %+v", path)
+	}
+
+	if _, err := g.Path(ctx, "Postgres", "Ana", 2); !errors.Is(err, ErrNoPath) {
+		t.Errorf("error = %v, want ErrNoPath with a short max depth", err)
+	}
+}
+
+func TestInMemoryGraph_Mentions(t *testing.T) {
+	g := seededGraph(t)
+	found, err := g.Mentions(context.Background(), "Who on the billing team can fix invoice service? Anastasia asked.")
+	if err != nil {
+		t.Fatalf("Mentions() error = %v", err)
+	}
+	var names []string
+	for _, e := range found {
+		names = append(names, e.Name)
+	}
+	if strings.Join(names, ",") != "Billing Team,Invoice Service" {
+		t.Errorf("mentions = %v", names)
+	}
+}
+
+func TestGraphMemory_LearnAndRecall(t *testing.T) {
+	provider := mock.New().WithResponse(`{"entities": [{"name": "Marko", "type": "person"}, {"name": "Atlas", "type": "project"}],
+		"relations": [{"from": "Marko", "type": "leads", "to": "Atlas"}, {"from": "Atlas", "type": "uses", "to": "Kafka"}]}`, nil)
+	graph := NewGraphMemory(NewInMemoryGraph(), &LLMExtractor{Provider: provider, Model: "mock-model"})
+	ctx := context.Background()
+
+	if err := graph.Learn(ctx, "Marko leads Atlas, which uses Kafka.", "conv-1"); err != nil {
+		t.Fatalf("Learn() error = %v", err)
+	}
+	entity, err := graph.Store.Entity(ctx, "marko")
+	if err != nil || entity.Type != "person" || entity.Sources[0] != "conv-1" {
+		t.Errorf("Entity() = %+v, %v", entity, err)
+	}
+
+	sub, err := graph.Recall(ctx, "What does Marko's project use?")
+	if err != nil {
+		t.Fatalf("Recall() error = %v", err)
+	}
+	if len(sub.Relations) != 2 {
+		t.Errorf("Recall() relations = %+v, want the two-hop neighborhood", sub.Relations)
+	}
+
+	if err := NewGraphMemory(NewInMemoryGraph(), nil).Learn(ctx, "text", ""); !errors.Is(err, ErrNoExtractor) {
+		t.Errorf("error = %v, want ErrNoExtractor", err)
+	}
+}
@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Memory is cross-conversation knowledge about a user or task: what the
+// agent should still know in the next conversation. It is distinct from a
+// ConversationStore, which keeps one conversation's turns.
+type Memory interface {
+	// Store saves item in item.Namespace, assigning an ID and CreatedAt when unset.
+	Store(ctx context.Context, item Item) (Item, error)
+	// Recall returns up to limit items from namespaces relevant to query.
+	Recall(ctx context.Context, query string, namespaces []Namespace, limit int) ([]Item, error)
+	// Forget removes one item.
+	Forget(ctx context.Context, ns Namespace, id string) error
+}
+
+// FromStore adapts a Store to Memory, ranking recall with the store's search.
+func FromStore(store Store) Memory {
+	return storeMemory{store}
+}
+
+type storeMemory struct {
+	store Store
+}
+
+func (m storeMemory) Store(ctx context.Context, item Item) (Item, error) {
+	return m.store.Put(ctx, item)
+}
+
+func (m storeMemory) Recall(ctx context.Context, query string, namespaces []Namespace, limit int) ([]Item, error) {
+	return m.store.Search(ctx, query, namespaces, limit)
+}
+
+func (m storeMemory) Forget(ctx context.Context, ns Namespace, id string) error {
+	return m.store.Delete(ctx, ns, id)
+}
+
+// DefaultEpisodeHalfLife is how quickly episodes fade when
+// Episodic.HalfLife is not set.
+const DefaultEpisodeHalfLife = 7 * 24 * time.Hour
+
+// Episodic remembers what happened and when: past exchanges, outcomes and
+// events. Recall ranks episodes by relevance to the query weighted by
+// recency, so an episode loses half its weight every HalfLife; an empty
+// query returns the most recent episodes.
+type Episodic struct {
+	// Backend holds the episodes (default: an InMemoryStore).
+	Backend Store
+	// HalfLife is the age at which an episode's score halves (default DefaultEpisodeHalfLife).
+	HalfLife time.Duration
+	// Now returns the current time (default time.Now); tests override it.
+	Now func() time.Time
+}
+
+// NewEpisodic creates an episodic memory on backend; a nil backend keeps
+// episodes in memory.
+func NewEpisodic(backend Store) *Episodic {
+	if backend == nil {
+		backend = NewInMemoryStore()
+	}
+	return &Episodic{Backend: backend, HalfLife: DefaultEpisodeHalfLife}
+}
+
+// Store implements Memory. Items are tagged with kind "episode".
+func (e *Episodic) Store(ctx context.Context, item Item) (Item, error) {
+	item.Metadata = withKind(item.Metadata, "episode")
+	return e.Backend.Put(ctx, item)
+}
+
+// Recall implements Memory.
+func (e *Episodic) Recall(ctx context.Context, query string, namespaces []Namespace, limit int) ([]Item, error) {
+	items, err := e.Backend.Search(ctx, query, namespaces, 0)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if e.Now != nil {
+		now = e.Now()
+	}
+	halfLife := e.HalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultEpisodeHalfLife
+	}
+	for i := range items {
+		relevance := items[i].Score
+		if query == "" {
+			relevance = 1
+		}
+		age := max(now.Sub(items[i].CreatedAt), 0)
+		items[i].Score = relevance * math.Pow(0.5, float64(age)/float64(halfLife))
+	}
+	SortItems(items)
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// Forget implements Memory.
+func (e *Episodic) Forget(ctx context.Context, ns Namespace, id string) error {
+	return e.Backend.Delete(ctx, ns, id)
+}
+
+// Default Semantic thresholds.
+const (
+	DefaultSemanticMinScore  = 0.3
+	DefaultSemanticDuplicate = 0.92
+)
+
+// ErrNoEmbedder is returned by Semantic without an Embedder.
+var ErrNoEmbedder = errors.New("memory: semantic memory has no embedder")
+
+// Semantic remembers facts: preferences, attributes and standing
+// instructions. Recall ranks facts by embedding similarity, so "what units
+// does she use" finds "prefers metric". Storing a fact nearly identical to
+// one already in the namespace replaces it, so restated or corrected facts
+// do not pile up. Facts and their embeddings are held in memory; it is safe
+// for concurrent use.
+type Semantic struct {
+	Embedder providers.Embedder
+	// MinScore drops facts less similar to the query than this (default DefaultSemanticMinScore).
+	MinScore float64
+	// Duplicate is the similarity at which a new fact replaces an existing one
+	// (default DefaultSemanticDuplicate); above 1 never replaces.
+	Duplicate float64
+
+	mu    sync.RWMutex
+	facts map[Namespace]map[string]semanticFact
+}
+
+type semanticFact struct {
+	item   Item
+	vector []float32
+}
+
+// NewSemantic creates a semantic memory that embeds facts with embedder.
+func NewSemantic(embedder providers.Embedder) *Semantic {
+	return &Semantic{Embedder: embedder}
+}
+
+// Store implements Memory. Items are tagged with kind "fact"; a replaced
+// fact keeps its ID.
+func (s *Semantic) Store(ctx context.Context, item Item) (Item, error) {
+	if err := item.Namespace.Validate(); err != nil {
+		return Item{}, err
+	}
+	vector, err := s.embed(ctx, item.Content)
+	if err != nil {
+		return Item{}, err
+	}
+	item.Metadata = withKind(item.Metadata, "fact")
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	item.Score = 0
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.facts == nil {
+		s.facts = make(map[Namespace]map[string]semanticFact)
+	}
+	partition, ok := s.facts[item.Namespace]
+	if !ok {
+		partition = make(map[string]semanticFact)
+		s.facts[item.Namespace] = partition
+	}
+	if item.ID == "" {
+		duplicate := s.Duplicate
+		if duplicate <= 0 {
+			duplicate = DefaultSemanticDuplicate
+		}
+		for id, fact := range partition {
+			if cosine(vector, fact.vector) >= duplicate {
+				item.ID = id
+				break
+			}
+		}
+	}
+	if item.ID == "" {
+		if item.ID, err = NewID(); err != nil {
+			return Item{}, err
+		}
+	}
+	partition[item.ID] = semanticFact{item: item, vector: vector}
+	return item, nil
+}
+
+// Recall implements Memory. An empty query returns the most recent facts.
+func (s *Semantic) Recall(ctx context.Context, query string, namespaces []Namespace, limit int) ([]Item, error) {
+	var vector []float32
+	if query != "" {
+		var err error
+		if vector, err = s.embed(ctx, query); err != nil {
+			return nil, err
+		}
+	}
+	minScore := s.MinScore
+	if minScore <= 0 {
+		minScore = DefaultSemanticMinScore
+	}
+
+	s.mu.RLock()
+	var results []Item
+	for _, ns := range namespaces {
+		for _, fact := range s.facts[ns] {
+			item := fact.item
+			if vector != nil {
+				item.Score = cosine(vector, fact.vector)
+				if item.Score < minScore {
+					continue
+				}
+			}
+			results = append(results, item)
+		}
+	}
+	s.mu.RUnlock()
+
+	SortItems(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Forget implements Memory.
+func (s *Semantic) Forget(ctx context.Context, ns Namespace, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.facts[ns][id]; !ok {
+		return fmt.Errorf("%w: %s in %s", ErrItemNotFound, id, ns)
+	}
+	delete(s.facts[ns], id)
+	return nil
+}
+
+func (s *Semantic) embed(ctx context.Context, text string) ([]float32, error) {
+	if s.Embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+	vectors, err := s.Embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("memory: embed: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("memory: embed: got %d vectors for 1 text", len(vectors))
+	}
+	return vectors[0], nil
+}
+
+// withKind returns metadata with "kind" set, copying it so the caller's map
+// is not modified.
+func withKind(metadata map[string]any, kind string) map[string]any {
+	copied := make(map[string]any, len(metadata)+1)
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	if _, ok := copied["kind"]; !ok {
+		copied["kind"] = kind
+	}
+	return copied
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
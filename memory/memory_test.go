@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wordEmbedder embeds text as counts of a fixed vocabulary.
+type wordEmbedder struct{ vocabulary []string }
+
+func (e wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(e.vocabulary))
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			for j, v := range e.vocabulary {
+				if strings.HasPrefix(word, v) {
+					vectors[i][j]++
+				}
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func TestEpisodic_RecencyWeighting(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	episodic := NewEpisodic(nil)
+	episodic.HalfLife = 24 * time.Hour
+	episodic.Now = func() time.Time { return now }
+	ctx := context.Background()
+	ns := Namespace{Scope: ScopeUser, Owner: "alice"}
+
+	old, _ := episodic.Store(ctx, Item{Namespace: ns, Content: "Refund issued for order 7", CreatedAt: now.Add(-72 * time.Hour)})
+	recent, _ := episodic.Store(ctx, Item{Namespace: ns, Content: "Refund denied for order 9", CreatedAt: now.Add(-time.Hour)})
+
+	items, err := episodic.Recall(ctx, "refund order", []Namespace{ns}, 10)
+	if err != nil {
+		t.Fatalf("Recall() error = %v", err)
+	}
+	if len(items) != 2 || items[0].ID != recent.ID || items[1].ID != old.ID {
+		t.Fatalf("Recall() = %+v, want the recent episode first", items)
+	}
+	if items[1].Score > items[0].Score/4 {
+		t.Errorf("three half-lives old scored %v vs %v", items[1].Score, items[0].Score)
+	}
+	if items[0].Metadata["kind"] != "episode" {
+		t.Errorf("metadata = %v", items[0].Metadata)
+	}
+
+	if err := episodic.Forget(ctx, ns, old.ID); err != nil {
+		t.Fatalf("Forget() error = %v", err)
+	}
+	if items, _ := episodic.Recall(ctx, "", []Namespace{ns}, 10); len(items) != 1 {
+		t.Errorf("after Forget = %+v", items)
+	}
+}
+
+func TestSemantic_RecallAndSupersede(t *testing.T) {
+	semantic := NewSemantic(wordEmbedder{vocabulary: []string{"metric", "unit", "tea", "coffee", "prefer"}})
+	ctx := context.Background()
+	ns := Namespace{Scope: ScopeUser, Owner: "alice"}
+
+	units, err := semantic.Store(ctx, Item{Namespace: ns, Content: "prefers metric units"})
+	if err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if _, err := semantic.Store(ctx, Item{Namespace: ns, Content: "drinks tea"}); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	restated, _ := semantic.Store(ctx, Item{Namespace: ns, Content: "Prefers metric units!"})
+	if restated.ID != units.ID {
+		t.Errorf("restated fact got ID %s, want it to replace %s", restated.ID, units.ID)
+	}
+
+	items, err := semantic.Recall(ctx, "which units", []Namespace{ns}, 10)
+	if err != nil {
+		t.Fatalf("Recall() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Content != "Prefers metric units!" || items[0].Metadata["kind"] != "fact" {
+		t.Errorf("Recall() = %+v", items)
+	}
+	if items, _ := semantic.Recall(ctx, "units", []Namespace{{Scope: ScopeUser, Owner: "bob"}}, 10); len(items) != 0 {
+		t.Errorf("bob recalled %+v", items)
+	}
+
+	if err := semantic.Forget(ctx, ns, "missing"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Forget() error = %v", err)
+	}
+	if _, err := NewSemantic(nil).Store(ctx, Item{Namespace: ns, Content: "x"}); !errors.Is(err, ErrNoEmbedder) {
+		t.Errorf("Store() without embedder error = %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultMinImportance is the LLMPolicy threshold when MinImportance is not set.
+const DefaultMinImportance = 0.6
+
+// Exchange is a completed run offered to a Policy.
+type Exchange struct {
+	Input  string
+	Output string
+}
+
+// Policy decides what from a completed run is worth keeping. Returned items
+// with an empty Namespace are written to the agent's default write scope.
+// Returning nothing is the common case: most exchanges hold nothing durable.
+type Policy interface {
+	Select(ctx context.Context, ex Exchange) ([]Item, error)
+}
+
+// PolicyFunc adapts a function to Policy.
+type PolicyFunc func(ctx context.Context, ex Exchange) ([]Item, error)
+
+// Select implements Policy.
+func (f PolicyFunc) Select(ctx context.Context, ex Exchange) ([]Item, error) {
+	return f(ctx, ex)
+}
+
+// Rule keeps user sentences matching Pattern.
+type Rule struct {
+	Pattern    *regexp.Regexp
+	Importance float64
+	// Scope overrides the default write scope for matches.
+	Scope Scope
+}
+
+// DefaultRules keep explicit requests to remember, personal details and stated preferences.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Pattern: regexp.MustCompile(`(?i)\b(remember|don't forget|do not forget|note) that\b`), Importance: 0.9},
+		{Pattern: regexp.MustCompile(`(?i)\bmy (name|email|role|title|timezone|time zone|team|company|manager) is\b`), Importance: 0.8},
+		{Pattern: regexp.MustCompile(`(?i)\bi (always|never|usually|prefer|like|dislike|hate|love)\b`), Importance: 0.7},
+	}
+}
+
+// RulePolicy persists sentences from the user message that match any rule.
+// It needs no model call, which makes it the cheapest policy.
+type RulePolicy struct {
+	Rules []Rule
+}
+
+// NewRulePolicy creates a rule policy, using DefaultRules when none are given.
+func NewRulePolicy(rules ...Rule) *RulePolicy {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &RulePolicy{Rules: rules}
+}
+
+var (
+	sentenceBoundary = regexp.MustCompile(`[.!?]+\s+|\n+`)
+	rememberPrefix   = regexp.MustCompile(`(?i)^(please\s+)?(remember|don't forget|do not forget|note)\s+that\s+`)
+)
+
+// Select implements Policy.
+func (p *RulePolicy) Select(ctx context.Context, ex Exchange) ([]Item, error) {
+	var items []Item
+	for _, sentence := range sentenceBoundary.Split(ex.Input, -1) {
+		sentence = strings.TrimSpace(sentence)
+		if sentence == "" {
+			continue
+		}
+		for _, rule := range p.Rules {
+			if !rule.Pattern.MatchString(sentence) {
+				continue
+			}
+			content := strings.TrimRight(rememberPrefix.ReplaceAllString(sentence, ""), ".!?")
+			if content == "" {
+				break
+			}
+			first, size := utf8.DecodeRuneInString(content)
+			items = append(items, Item{
+				Namespace:  Namespace{Scope: rule.Scope},
+				Content:    string(unicode.ToUpper(first)) + content[size:],
+				Importance: rule.Importance,
+			})
+			break
+		}
+	}
+	return items, nil
+}
+
+// LLMPolicy asks a model to propose durable facts from the exchange and to
+// score their importance, keeping those at or above MinImportance.
+type LLMPolicy struct {
+	Provider      providers.Provider
+	Model         string
+	MinImportance float64
+}
+
+const policyPrompt = `You decide what an assistant should remember long-term about a user or their organization.
+From the exchange, list durable facts worth recalling in future conversations: preferences, personal details,
+decisions, commitments and standing instructions. Ignore small talk, one-off questions and anything already
+obvious from a single message. Score each fact's importance from 0 to 1.
+Return JSON only: {"memories": [{"content": "self-contained fact", "importance": 0.8}]}. Return an empty list when nothing qualifies.`
+
+// Select implements Policy.
+func (p *LLMPolicy) Select(ctx context.Context, ex Exchange) ([]Item, error) {
+	resp, err := p.Provider.Complete(ctx, providers.CompletionRequest{
+		Model:        p.Model,
+		SystemPrompt: policyPrompt,
+		Messages: []providers.Message{{
+			Role:    providers.RoleUser,
+			Content: "User: " + ex.Input + "\nAssistant: " + ex.Output,
+		}},
+		TextFormat: "json_object",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := resp.Content
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		content = content[start : end+1]
+	}
+	var scored struct {
+		Memories []struct {
+			Content    string  `json:"content"`
+			Importance float64 `json:"importance"`
+		} `json:"memories"`
+	}
+	if err := json.Unmarshal([]byte(content), &scored); err != nil {
+		return nil, fmt.Errorf("decode memory scores: %w", err)
+	}
+
+	threshold := p.MinImportance
+	if threshold <= 0 {
+		threshold = DefaultMinImportance
+	}
+	var items []Item
+	for _, m := range scored.Memories {
+		if strings.TrimSpace(m.Content) == "" || m.Importance < threshold {
+			continue
+		}
+		items = append(items, Item{Content: strings.TrimSpace(m.Content), Importance: m.Importance})
+	}
+	return items, nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestRulePolicy_Select(t *testing.T) {
+	policy := NewRulePolicy()
+	items, err := policy.Select(context.Background(), Exchange{
+		Input: "Hi there! Please remember that invoices go to finance@example.com. I prefer short answers.\nWhat's the weather?",
+	})
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %+v, want 2", items)
+	}
+	if items[0].Content != "Invoices go to finance@example.com" || items[0].Importance != 0.9 {
+		t.Errorf("items[0] = %+v", items[0])
+	}
+	if items[1].Content != "I prefer short answers" || items[1].Importance != 0.7 {
+		t.Errorf("items[1] = %+v", items[1])
+	}
+
+	if items, _ := policy.Select(context.Background(), Exchange{Input: "Thanks, that's all."}); len(items) != 0 {
+		t.Errorf("small talk kept: %+v", items)
+	}
+}
+
+func TestLLMPolicy_Select(t *testing.T) {
+	provider := mock.New().WithResponse(`{"memories": [
+		{"content": "User's team deploys on Thursdays", "importance": 0.8},
+		{"content": "User said hello", "importance": 0.1}]}`, nil)
+	policy := &LLMPolicy{Provider: provider, Model: "mock-model"}
+
+	items, err := policy.Select(context.Background(), Exchange{Input: "Hello, we deploy on Thursdays", Output: "Noted."})
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Content != "User's team deploys on Thursdays" || items[0].Importance != 0.8 {
+		t.Errorf("items = %+v, want only the important fact", items)
+	}
+}
@@ -0,0 +1,229 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope partitions long-term memory by who can see it.
+type Scope string
+
+const (
+	// ScopeSession holds memories for a single conversation. They are meant
+	// to be ephemeral and are discarded with Store.Clear when the session ends.
+	ScopeSession Scope = "session"
+	// ScopeUser holds memories that follow one end-user across conversations.
+	ScopeUser Scope = "user"
+	// ScopeGlobal holds organizational memories shared by every user.
+	ScopeGlobal Scope = "global"
+)
+
+// AllScopes lists every scope, narrowest first.
+var AllScopes = []Scope{ScopeSession, ScopeUser, ScopeGlobal}
+
+// Common store errors.
+var (
+	ErrInvalidScope = errors.New("memory: invalid scope")
+	ErrMissingOwner = errors.New("memory: scope requires an owner")
+	ErrItemNotFound = errors.New("memory: item not found")
+)
+
+// Namespace identifies one isolated partition of a store: a scope plus the
+// session or user that owns it. Global namespaces have no owner.
+type Namespace struct {
+	Scope Scope  `json:"scope"`
+	Owner string `json:"owner,omitempty"`
+}
+
+// Validate reports whether the namespace is well formed.
+func (n Namespace) Validate() error {
+	switch n.Scope {
+	case ScopeSession, ScopeUser:
+		if n.Owner == "" {
+			return fmt.Errorf("%w: %s", ErrMissingOwner, n.Scope)
+		}
+	case ScopeGlobal:
+		if n.Owner != "" {
+			return fmt.Errorf("%w: global memory has no owner", ErrInvalidScope)
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidScope, n.Scope)
+	}
+	return nil
+}
+
+func (n Namespace) String() string {
+	if n.Owner == "" {
+		return string(n.Scope)
+	}
+	return string(n.Scope) + ":" + n.Owner
+}
+
+// Item is a single remembered fact.
+type Item struct {
+	ID        string         `json:"id"`
+	Namespace Namespace      `json:"namespace"`
+	Content   string         `json:"content"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	// Importance is a 0..1 weight; higher values win ties during search.
+	Importance float64   `json:"importance,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Score is set on search results and reflects relevance to the query.
+	Score float64 `json:"score,omitempty"`
+}
+
+// Store persists memory items. Implementations must keep namespaces isolated:
+// a search only ever returns items from the namespaces it names.
+type Store interface {
+	// Put stores item in item.Namespace, assigning an ID and CreatedAt when unset.
+	Put(ctx context.Context, item Item) (Item, error)
+	// Search returns up to limit items from namespaces ranked by relevance to query.
+	// An empty query returns the most recent items.
+	Search(ctx context.Context, query string, namespaces []Namespace, limit int) ([]Item, error)
+	// Delete removes one item.
+	Delete(ctx context.Context, ns Namespace, id string) error
+	// Clear removes every item in a namespace.
+	Clear(ctx context.Context, ns Namespace) error
+}
+
+// InMemoryStore is a Store held in memory, ranking by term overlap. It is
+// safe for concurrent use.
+type InMemoryStore struct {
+	mu    sync.RWMutex
+	items map[Namespace]map[string]Item
+}
+
+// NewInMemoryStore creates an empty in-memory store.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{items: make(map[Namespace]map[string]Item)}
+}
+
+// Put implements Store.
+func (s *InMemoryStore) Put(ctx context.Context, item Item) (Item, error) {
+	if err := item.Namespace.Validate(); err != nil {
+		return Item{}, err
+	}
+	if item.ID == "" {
+		id, err := NewID()
+		if err != nil {
+			return Item{}, err
+		}
+		item.ID = id
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	item.Score = 0
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	partition, ok := s.items[item.Namespace]
+	if !ok {
+		partition = make(map[string]Item)
+		s.items[item.Namespace] = partition
+	}
+	partition[item.ID] = item
+	return item, nil
+}
+
+// Search implements Store.
+func (s *InMemoryStore) Search(ctx context.Context, query string, namespaces []Namespace, limit int) ([]Item, error) {
+	terms := uniqueTerms(query)
+
+	s.mu.RLock()
+	var results []Item
+	for _, ns := range namespaces {
+		for _, item := range s.items[ns] {
+			if len(terms) > 0 {
+				item.Score = termOverlap(terms, item.Content)
+				if item.Score == 0 {
+					continue
+				}
+			}
+			results = append(results, item)
+		}
+	}
+	s.mu.RUnlock()
+
+	SortItems(results)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Delete implements Store.
+func (s *InMemoryStore) Delete(ctx context.Context, ns Namespace, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[ns][id]; !ok {
+		return fmt.Errorf("%w: %s in %s", ErrItemNotFound, id, ns)
+	}
+	delete(s.items[ns], id)
+	return nil
+}
+
+// Clear implements Store.
+func (s *InMemoryStore) Clear(ctx context.Context, ns Namespace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, ns)
+	return nil
+}
+
+// SortItems orders items by score, then importance, then recency.
+func SortItems(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		if items[i].Importance != items[j].Importance {
+			return items[i].Importance > items[j].Importance
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+}
+
+// NewID returns a random item ID.
+func NewID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("memory: generate id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+func uniqueTerms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if len(term) < 3 || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// termOverlap returns the fraction of terms that occur in content.
+func termOverlap(terms []string, content string) float64 {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(content), isSeparator) {
+		words[w] = true
+	}
+	matched := 0
+	for _, term := range terms {
+		if words[term] {
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(terms))
+}
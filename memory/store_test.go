@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestInMemoryStore_NamespaceIsolation(t *testing.T) {
+	store := NewInMemoryStore()
+	ctx := context.Background()
+	alice := Namespace{Scope: ScopeUser, Owner: "alice"}
+	bob := Namespace{Scope: ScopeUser, Owner: "bob"}
+	global := Namespace{Scope: ScopeGlobal}
+
+	for _, item := range []Item{
+		{Namespace: alice, Content: "Alice prefers metric units"},
+		{Namespace: bob, Content: "Bob prefers imperial units"},
+		{Namespace: global, Content: "Office closes on public holidays"},
+	} {
+		if _, err := store.Put(ctx, item); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+
+	items, err := store.Search(ctx, "which units does she prefer", []Namespace{alice, global}, 10)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(items) != 1 || items[0].Content != "Alice prefers metric units" || items[0].ID == "" {
+		t.Errorf("Search() = %+v, want only Alice's memory", items)
+	}
+
+	recent, _ := store.Search(ctx, "", []Namespace{bob, global}, 10)
+	if len(recent) != 2 {
+		t.Errorf("empty query = %+v, want bob and global items", recent)
+	}
+
+	if err := store.Clear(ctx, alice); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if items, _ := store.Search(ctx, "", []Namespace{alice}, 10); len(items) != 0 {
+		t.Errorf("after Clear = %+v", items)
+	}
+	if err := store.Delete(ctx, bob, "missing"); !errors.Is(err, ErrItemNotFound) {
+		t.Errorf("Delete() error = %v, want ErrItemNotFound", err)
+	}
+}
+
+func TestInMemoryStore_RejectsInvalidNamespace(t *testing.T) {
+	store := NewInMemoryStore()
+	tests := []struct {
+		ns   Namespace
+		want error
+	}{
+		{Namespace{Scope: ScopeSession}, ErrMissingOwner},
+		{Namespace{Scope: ScopeUser}, ErrMissingOwner},
+		{Namespace{Scope: ScopeGlobal, Owner: "alice"}, ErrInvalidScope},
+		{Namespace{Scope: "team", Owner: "x"}, ErrInvalidScope},
+	}
+	for _, tt := range tests {
+		if _, err := store.Put(context.Background(), Item{Namespace: tt.ns, Content: "x"}); !errors.Is(err, tt.want) {
+			t.Errorf("Put(%v) error = %v, want %v", tt.ns, err, tt.want)
+		}
+	}
+}
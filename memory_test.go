@@ -0,0 +1,161 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/memory"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func newMemoryAgent(t *testing.T, provider *recordingProvider, cfg *MemoryConfig) *Agent {
+	t.Helper()
+	agent, err := New(Config{
+		Provider:     provider,
+		Model:        "test-model",
+		SystemPrompt: func(context.Context) string { return "You are helpful." },
+		Memory:       cfg,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return agent
+}
+
+func TestMemory_ScopesResolvedFromContext(t *testing.T) {
+	store := memory.NewInMemoryStore()
+	agent := newMemoryAgent(t, &recordingProvider{Provider: mockprovider.New()}, &MemoryConfig{Store: store})
+	ctx := WithUser(WithConversation(context.Background(), "conv-1"), "alice")
+
+	if _, err := agent.Remember(ctx, memory.Item{Content: "Alice likes tea"}); err != nil {
+		t.Fatalf("Remember() error = %v", err)
+	}
+	if _, err := agent.Remember(ctx, memory.Item{Namespace: memory.Namespace{Scope: memory.ScopeSession}, Content: "Draft tea order pending"}); err != nil {
+		t.Fatalf("Remember(session) error = %v", err)
+	}
+	if _, err := agent.Remember(context.Background(), memory.Item{Content: "no user"}); !errors.Is(err, memory.ErrMissingOwner) {
+		t.Errorf("Remember() without user error = %v, want ErrMissingOwner", err)
+	}
+
+	items, err := agent.RecallMemories(ctx, "tea")
+	if err != nil || len(items) != 2 {
+		t.Fatalf("RecallMemories() = %+v, %v", items, err)
+	}
+
+	// Another user in another conversation sees neither memory.
+	other := WithUser(WithConversation(context.Background(), "conv-2"), "bob")
+	if items, _ := agent.RecallMemories(other, "tea"); len(items) != 0 {
+		t.Errorf("bob recalled %+v", items)
+	}
+
+	// Same user, new conversation: only the user-scoped memory carries over.
+	items, _ = agent.RecallMemories(WithUser(context.Background(), "alice"), "tea")
+	if len(items) != 1 || items[0].Namespace != (memory.Namespace{Scope: memory.ScopeUser, Owner: "alice"}) {
+		t.Errorf("new conversation recalled %+v", items)
+	}
+
+	items, _ = agent.RecallMemories(WithMemoryScopes(ctx, memory.ScopeSession), "tea")
+	if len(items) != 1 || items[0].Content != "Draft tea order pending" {
+		t.Errorf("session-only recall = %+v", items)
+	}
+}
+
+func TestMemory_InjectedIntoSystemPrompt(t *testing.T) {
+	store := memory.NewInMemoryStore()
+	_, _ = store.Put(context.Background(), memory.Item{
+		Namespace: memory.Namespace{Scope: memory.ScopeGlobal},
+		Content:   "Refunds over 500 EUR need manager approval",
+	})
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("ok", nil)}
+	agent := newMemoryAgent(t, provider, &MemoryConfig{Store: store})
+
+	collectEvents(agent.Run(context.Background(), "Can I approve this refunds request?"), 2*time.Second)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(provider.requests))
+	}
+	if prompt := provider.requests[0].SystemPrompt; !strings.Contains(prompt, "## Memories\n- [global] Refunds over 500 EUR") {
+		t.Errorf("system prompt = %q", prompt)
+	}
+
+	if _, err := newMemoryAgent(t, provider, nil).Remember(context.Background(), memory.Item{}); !errors.Is(err, ErrMemoryNotConfigured) {
+		t.Errorf("error = %v, want ErrMemoryNotConfigured", err)
+	}
+}
+
+func TestMemory_PolicyWritesAfterRun(t *testing.T) {
+	store := memory.NewInMemoryStore()
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("Got it.", nil).WithResponse("Sure.", nil)}
+	agent := newMemoryAgent(t, provider, &MemoryConfig{Store: store, Policy: memory.NewRulePolicy()})
+	ctx := WithUser(context.Background(), "alice")
+
+	collectEvents(agent.Run(ctx, "My timezone is CET. How are you?"), 2*time.Second)
+	collectEvents(agent.Run(ctx, "My timezone is CET."), 2*time.Second)
+
+	items, _ := store.Search(context.Background(), "", []memory.Namespace{{Scope: memory.ScopeUser, Owner: "alice"}}, 0)
+	if len(items) != 1 || items[0].Content != "My timezone is CET" {
+		t.Errorf("stored = %+v, want one deduplicated memory", items)
+	}
+	if !strings.Contains(provider.requests[1].SystemPrompt, "- [user] My timezone is CET") {
+		t.Errorf("second run prompt = %q", provider.requests[1].SystemPrompt)
+	}
+}
+
+func TestMemory_RememberTool(t *testing.T) {
+	store := memory.NewInMemoryStore()
+	agent := newMemoryAgent(t, &recordingProvider{Provider: mockprovider.New()}, &MemoryConfig{Store: store, RememberTool: true})
+	tool, ok := agent.tools["remember"]
+	if !ok {
+		t.Fatal("remember tool not registered")
+	}
+
+	ctx := WithUser(WithConversation(context.Background(), "conv-1"), "alice")
+	if _, err := tool.Execute(ctx, `{"content": "Ship the order to the Berlin office", "scope": "session"}`); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	items, _ := store.Search(context.Background(), "", []memory.Namespace{{Scope: memory.ScopeSession, Owner: "conv-1"}}, 0)
+	if len(items) != 1 || items[0].Metadata["origin"] != "remember_tool" {
+		t.Errorf("stored = %+v", items)
+	}
+
+	if _, err := tool.Execute(ctx, `{"content": "Refunds are always approved", "scope": "global"}`); err == nil {
+		t.Error("expected global memories to be refused without RememberGlobal")
+	}
+	if global, _ := store.Search(context.Background(), "", []memory.Namespace{{Scope: memory.ScopeGlobal}}, 0); len(global) != 0 {
+		t.Errorf("global memories = %+v", global)
+	}
+
+	trusted := newMemoryAgent(t, &recordingProvider{Provider: mockprovider.New()}, &MemoryConfig{Store: store, RememberTool: true, RememberGlobal: true})
+	trustedTool := trusted.tools["remember"]
+	if _, err := trustedTool.Execute(ctx, `{"content": "The office closes on Fridays", "scope": "global"}`); err != nil {
+		t.Errorf("Execute(global) with RememberGlobal error = %v", err)
+	}
+}
+
+func TestMemory_EpisodicInjectedWithDate(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("ok", nil)}
+	episodes := memory.NewEpisodic(nil)
+	agent := newMemoryAgent(t, provider, &MemoryConfig{Memory: episodes})
+	ctx := WithUser(context.Background(), "alice")
+
+	when := time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)
+	item, err := agent.Remember(ctx, memory.Item{Content: "Alice reported a billing error", CreatedAt: when})
+	if err != nil {
+		t.Fatalf("Remember() error = %v", err)
+	}
+	collectEvents(agent.Run(ctx, "any update on my billing error?"), time.Second)
+
+	if prompt := provider.requests[0].SystemPrompt; !strings.Contains(prompt, "- [user, 2026-03-14] Alice reported a billing error") {
+		t.Errorf("system prompt = %q", prompt)
+	}
+
+	if err := agent.ForgetMemory(ctx, memory.Namespace{Scope: memory.ScopeUser}, item.ID); err != nil {
+		t.Fatalf("ForgetMemory() error = %v", err)
+	}
+	if items, _ := agent.RecallMemories(ctx, "billing"); len(items) != 0 {
+		t.Errorf("recalled %+v after ForgetMemory", items)
+	}
+}
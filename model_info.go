@@ -0,0 +1,72 @@
+package agentkit
+
+import (
+	"strings"
+	"sync"
+)
+
+// ModelInfo describes what a model family accepts, for config validation.
+type ModelInfo struct {
+	// Reasoning models accept ReasoningEffort.
+	Reasoning bool
+	// Temperature reports whether the model accepts a sampling temperature.
+	Temperature bool
+	// StrictSchemas reports whether the provider enforces a strict JSON
+	// schema on the model's output. Without it, use CompleteJSON.
+	StrictSchemas bool
+}
+
+var (
+	modelInfoMu sync.RWMutex
+	// modelInfo is keyed by model family; the longest family that prefixes a
+	// model name applies, as with Config.SystemPromptVariants.
+	modelInfo = map[string]ModelInfo{
+		"gpt-3.5":    {Temperature: true},
+		"gpt-4":      {Temperature: true},
+		"gpt-4o":     {Temperature: true, StrictSchemas: true},
+		"gpt-4.1":    {Temperature: true, StrictSchemas: true},
+		"chatgpt-4o": {Temperature: true},
+		"gpt-5":      {Reasoning: true, StrictSchemas: true},
+		"gpt-5-chat": {Temperature: true, StrictSchemas: true},
+		"o1":         {Reasoning: true, StrictSchemas: true},
+		"o3":         {Reasoning: true, StrictSchemas: true},
+		"o4":         {Reasoning: true, StrictSchemas: true},
+		"codex-mini": {Reasoning: true, StrictSchemas: true},
+		"claude":     {Temperature: true},
+	}
+)
+
+// RegisterModelInfo adds or replaces the capabilities of a model family, so
+// Validate recognizes models released after this version.
+func RegisterModelInfo(family string, info ModelInfo) {
+	modelInfoMu.Lock()
+	defer modelInfoMu.Unlock()
+	modelInfo[family] = info
+}
+
+// LookupModelInfo returns the capabilities of model's family. OpenAI
+// fine-tuned models ("ft:gpt-4o-mini:org::id") take their base model's.
+func LookupModelInfo(model string) (ModelInfo, bool) {
+	model = baseModel(model)
+	modelInfoMu.RLock()
+	defer modelInfoMu.RUnlock()
+	var best string
+	var info ModelInfo
+	found := false
+	for family, candidate := range modelInfo {
+		if strings.HasPrefix(model, family) && len(family) > len(best) {
+			best, info, found = family, candidate, true
+		}
+	}
+	return info, found
+}
+
+// baseModel returns the base model of an OpenAI fine-tuned model ID, or
+// model itself.
+func baseModel(model string) string {
+	if tuned, ok := strings.CutPrefix(model, "ft:"); ok {
+		base, _, _ := strings.Cut(tuned, ":")
+		return base
+	}
+	return model
+}
@@ -0,0 +1,132 @@
+package agentkit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestLookupModelInfo_LongestFamilyWins(t *testing.T) {
+	tests := map[string]ModelInfo{
+		"gpt-4o-mini":                            {Temperature: true, StrictSchemas: true},
+		"gpt-5-mini":                             {Reasoning: true, StrictSchemas: true},
+		"gpt-5-chat-latest":                      {Temperature: true, StrictSchemas: true},
+		"gpt-5.1-codex-max":                      {Reasoning: true, StrictSchemas: true},
+		"o3-mini":                                {Reasoning: true, StrictSchemas: true},
+		"claude-sonnet-4-5":                      {Temperature: true},
+		"ft:gpt-4o-mini-2024-07-18:acme::9abc":   {Temperature: true, StrictSchemas: true},
+		"ft:o4-mini-2025-04-16:acme:triage:9abc": {Reasoning: true, StrictSchemas: true},
+	}
+	for model, want := range tests {
+		if got, ok := LookupModelInfo(model); !ok || got != want {
+			t.Errorf("LookupModelInfo(%q) = %+v, %v, want %+v", model, got, ok, want)
+		}
+	}
+	if _, ok := LookupModelInfo("llama-3"); ok {
+		t.Error("LookupModelInfo(llama-3) found a family")
+	}
+}
+
+func TestConfigValidation_ModelCompatibility(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr []error
+	}{
+		{
+			name:    "reasoning effort on non-reasoning model",
+			config:  Config{APIKey: "k", Model: "gpt-4o", ReasoningEffort: providers.ReasoningEffortHigh},
+			wantErr: []error{ErrReasoningEffortUnsupported},
+		},
+		{
+			name:   "reasoning effort none on non-reasoning model",
+			config: Config{APIKey: "k", Model: "gpt-4o", ReasoningEffort: providers.ReasoningEffortNone},
+		},
+		{
+			name:    "temperature on o-series",
+			config:  Config{APIKey: "k", Model: "o3", Temperature: 0.7},
+			wantErr: []error{ErrTemperatureUnsupported},
+		},
+		{
+			name:    "unknown model on built-in provider",
+			config:  Config{APIKey: "k", Model: "gpt-6-turbo-preview"},
+			wantErr: []error{ErrUnknownModel},
+		},
+		{
+			name:   "fine-tuned model on built-in provider",
+			config: Config{APIKey: "k", Model: "ft:gpt-4o-mini:org::id", Temperature: 0.2},
+		},
+		{
+			name:    "fine-tuned model of an unknown base",
+			config:  Config{APIKey: "k", Model: "ft:gpt-6-turbo:org::id"},
+			wantErr: []error{ErrUnknownModel},
+		},
+		{
+			name:   "unknown model allowed",
+			config: Config{APIKey: "k", Model: "gpt-6-turbo-preview", AllowUnknownModel: true},
+		},
+		{
+			name:   "unknown model with custom provider",
+			config: Config{Provider: mockprovider.New(), Model: "llama-3", Temperature: 0.2},
+		},
+		{
+			name:    "every problem is reported",
+			config:  Config{Model: "o1", Temperature: 3, MaxIterations: 500, ReasoningEffort: "bogus"},
+			wantErr: []error{ErrMissingAPIKey, ErrInvalidIterations, ErrInvalidTemperature, ErrInvalidReasoningEffort, ErrTemperatureUnsupported},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Errorf("Validate() error = %v, want %v", err, want)
+				}
+			}
+			if got := len(strings.Split(err.Error(), "\n")); got != len(tt.wantErr) {
+				t.Errorf("Validate() reported %d errors, want %d: %v", got, len(tt.wantErr), err)
+			}
+		})
+	}
+}
+
+func TestRegisterModelInfo(t *testing.T) {
+	RegisterModelInfo("acme-reasoner", ModelInfo{Reasoning: true})
+	t.Cleanup(func() {
+		modelInfoMu.Lock()
+		delete(modelInfo, "acme-reasoner")
+		modelInfoMu.Unlock()
+	})
+
+	cfg := Config{APIKey: "k", Model: "acme-reasoner-2", ReasoningEffort: providers.ReasoningEffortLow}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+}
+
+func TestConfigWarnings(t *testing.T) {
+	cfg := Config{
+		APIKey:            "k",
+		Model:             "gpt-6",
+		AllowUnknownModel: true,
+		MaxIterations:     80,
+		Approval:          &ApprovalConfig{AllTools: true},
+	}
+	warnings := cfg.Warnings()
+	if len(warnings) != 3 {
+		t.Fatalf("Warnings() = %q, want 3", warnings)
+	}
+	if warnings := (Config{APIKey: "k", Model: "gpt-4o"}).Warnings(); len(warnings) != 0 {
+		t.Fatalf("Warnings() = %q, want none", warnings)
+	}
+}
@@ -0,0 +1,97 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Moderation errors.
+var (
+	// ErrContentFlagged is reported when moderation stops a run.
+	ErrContentFlagged = errors.New("agentkit: content flagged by moderation")
+	// ErrNoModerator is returned by New when Moderation has no Moderator and
+	// the provider has no moderation API.
+	ErrNoModerator = errors.New("agentkit: Moderation needs a Moderator for this provider")
+)
+
+// Moderation stages reported in moderation.flagged events.
+const (
+	ModerationStageInput  = "input"
+	ModerationStageOutput = "output"
+)
+
+// ModerationConfig configures content moderation of the user input, before
+// the model is called, and of the final answer.
+type ModerationConfig struct {
+	// Moderator classifies text. It defaults to the agent's provider when
+	// that implements providers.Moderator, as the OpenAI provider does.
+	Moderator  providers.Moderator
+	SkipInput  bool // Don't moderate user input
+	SkipOutput bool // Don't moderate the final answer
+	// Block stops the run on flagged content: flagged input ends it before
+	// the model is called and a flagged answer is withheld from
+	// final_output. Both report ErrContentFlagged. Without Block, flagged
+	// content is only reported with a moderation.flagged event. Streamed
+	// chunks reach the client before the answer is moderated; disable
+	// StreamResponses to withhold them too.
+	Block bool
+	// FailClosed stops the run with ErrContentFlagged when the moderator
+	// fails. By default the failure is logged and the run continues.
+	FailClosed bool
+}
+
+// withDefaults fills in the moderator from provider.
+func (c ModerationConfig) withDefaults(provider providers.Provider) (*ModerationConfig, error) {
+	if c.Moderator == nil {
+		provider = unwrapProvider(provider)
+		moderator, ok := provider.(providers.Moderator)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNoModerator, provider.Name())
+		}
+		c.Moderator = moderator
+	}
+	return &c, nil
+}
+
+// moderate checks text at stage, emitting moderation.flagged when the
+// moderator flags it. It returns an error, already reported, when the run
+// must stop.
+func (a *Agent) moderate(ctx context.Context, stage, text string, events chan<- Event) error {
+	config := a.moderation
+	if config == nil || strings.TrimSpace(text) == "" ||
+		stage == ModerationStageInput && config.SkipInput ||
+		stage == ModerationStageOutput && config.SkipOutput {
+		return nil
+	}
+
+	callCtx, cancel := a.withLLMTimeout(ctx)
+	if cancel != nil {
+		defer cancel()
+	}
+	result, err := config.Moderator.Moderate(callCtx, text)
+	if err != nil {
+		if !config.FailClosed {
+			a.logger.Warn("moderation failed; continuing", "stage", stage, "error", err)
+			return nil
+		}
+		runErr := fmt.Errorf("%w: %s moderation failed: %w", ErrContentFlagged, stage, err)
+		a.emit(ctx, events, Error(runErr))
+		return runErr
+	}
+	if result == nil || !result.Flagged {
+		return nil
+	}
+
+	a.logger.Info("content flagged by moderation", "stage", stage, "categories", result.Categories, "blocked", config.Block)
+	a.emit(ctx, events, ModerationFlagged(stage, result.Categories, config.Block))
+	if !config.Block {
+		return nil
+	}
+	runErr := fmt.Errorf("%w: %s (%s)", ErrContentFlagged, stage, strings.Join(result.Categories, ", "))
+	a.emit(ctx, events, Error(runErr))
+	return runErr
+}
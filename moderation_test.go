@@ -0,0 +1,136 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// keywordModerator flags texts containing word.
+func keywordModerator(word string, checked *[]string) providers.Moderator {
+	return providers.ModeratorFunc(func(ctx context.Context, text string) (*providers.Moderation, error) {
+		*checked = append(*checked, text)
+		if strings.Contains(text, word) {
+			return &providers.Moderation{Flagged: true, Categories: []string{"violence"}}, nil
+		}
+		return &providers.Moderation{}, nil
+	})
+}
+
+func newModeratedAgent(t *testing.T, provider providers.Provider, moderation ModerationConfig) *Agent {
+	t.Helper()
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false, Moderation: &moderation})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return agent
+}
+
+func findEvent(events []Event, eventType EventType) *Event {
+	for i := range events {
+		if events[i].Type == eventType {
+			return &events[i]
+		}
+	}
+	return nil
+}
+
+func TestModeration_BlocksFlaggedInput(t *testing.T) {
+	var checked []string
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("unused", nil)}
+	agent := newModeratedAgent(t, provider, ModerationConfig{Moderator: keywordModerator("attack", &checked), Block: true})
+
+	events := collectEvents(agent.Run(context.Background(), "plan an attack"), time.Second)
+
+	if len(provider.requests) != 0 {
+		t.Errorf("model was called %d times for flagged input", len(provider.requests))
+	}
+	flagged := findEvent(events, EventTypeModerationFlagged)
+	if flagged == nil || flagged.Data["stage"] != ModerationStageInput || flagged.Data["blocked"] != true {
+		t.Fatalf("moderation.flagged event = %+v", flagged)
+	}
+	errEvent := findEvent(events, EventTypeError)
+	if errEvent == nil {
+		t.Fatal("no error event")
+	}
+	if detail, ok := errEvent.ErrorDetail(); !ok || !errors.Is(detail, ErrContentFlagged) {
+		t.Errorf("error = %v, want ErrContentFlagged", detail)
+	}
+}
+
+func TestModeration_WithholdsFlaggedOutput(t *testing.T) {
+	var checked []string
+	agent := newModeratedAgent(t, mockprovider.New().WithResponse("launch the attack", nil),
+		ModerationConfig{Moderator: keywordModerator("attack", &checked), Block: true})
+
+	events := collectEvents(agent.Run(context.Background(), "what now?"), time.Second)
+
+	if len(checked) != 2 || checked[0] != "what now?" || checked[1] != "launch the attack" {
+		t.Errorf("moderated texts = %q", checked)
+	}
+	flagged := findEvent(events, EventTypeModerationFlagged)
+	if flagged == nil || flagged.Data["stage"] != ModerationStageOutput {
+		t.Fatalf("moderation.flagged event = %+v", flagged)
+	}
+	if final := findEvent(events, EventTypeFinalOutput); final == nil || final.Data["response"] != "" {
+		t.Errorf("final output = %+v, want the answer withheld", final)
+	}
+}
+
+func TestModeration_FlagOnly(t *testing.T) {
+	var checked []string
+	agent := newModeratedAgent(t, mockprovider.New().WithResponse("launch the attack", nil),
+		ModerationConfig{Moderator: keywordModerator("attack", &checked), SkipInput: true})
+
+	events := collectEvents(agent.Run(context.Background(), "what now?"), time.Second)
+
+	if len(checked) != 1 {
+		t.Errorf("moderated texts = %q, want only the output", checked)
+	}
+	if flagged := findEvent(events, EventTypeModerationFlagged); flagged == nil || flagged.Data["blocked"] != false {
+		t.Errorf("moderation.flagged event = %+v", flagged)
+	}
+	if errEvent := findEvent(events, EventTypeError); errEvent != nil {
+		t.Errorf("unexpected error event: %v", errEvent.Data)
+	}
+	if final := findEvent(events, EventTypeFinalOutput); final == nil || final.Data["response"] != "launch the attack" {
+		t.Errorf("final output = %+v", final)
+	}
+}
+
+func TestModeration_ModeratorFailure(t *testing.T) {
+	failing := providers.ModeratorFunc(func(context.Context, string) (*providers.Moderation, error) {
+		return nil, errors.New("moderation unavailable")
+	})
+
+	open := newModeratedAgent(t, mockprovider.New().WithResponse("fine", nil), ModerationConfig{Moderator: failing})
+	events := collectEvents(open.Run(context.Background(), "hi"), time.Second)
+	if final := findEvent(events, EventTypeFinalOutput); final == nil || final.Data["response"] != "fine" {
+		t.Errorf("fail-open final output = %+v", final)
+	}
+
+	closed := newModeratedAgent(t, mockprovider.New().WithResponse("fine", nil), ModerationConfig{Moderator: failing, FailClosed: true})
+	events = collectEvents(closed.Run(context.Background(), "hi"), time.Second)
+	errEvent := findEvent(events, EventTypeError)
+	if errEvent == nil {
+		t.Fatal("fail-closed run did not fail")
+	}
+	if detail, ok := errEvent.ErrorDetail(); !ok || !errors.Is(detail, ErrContentFlagged) {
+		t.Errorf("error = %v, want ErrContentFlagged", detail)
+	}
+}
+
+func TestModeration_RequiresModerator(t *testing.T) {
+	_, err := New(Config{Provider: mockprovider.New(), Model: "test-model", Moderation: &ModerationConfig{}})
+	if !errors.Is(err, ErrNoModerator) {
+		t.Errorf("New() error = %v, want ErrNoModerator", err)
+	}
+	if _, err := New(Config{APIKey: "key", Model: "gpt-4o", Moderation: &ModerationConfig{}}); err != nil {
+		t.Errorf("OpenAI provider not used as moderator: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrInvalidImage is reported by RunMultimodal for an image that is neither
+// an http(s) or data URL nor image bytes.
+var ErrInvalidImage = errors.New("agentkit: invalid image input")
+
+// ErrInvalidFile is reported by RunMultimodal for a file attachment without
+// data or a file ID.
+var ErrInvalidFile = errors.New("agentkit: invalid file input")
+
+// fileCleanupTimeout bounds deleting a run's uploaded files.
+const fileCleanupTimeout = 30 * time.Second
+
+// Input is a user turn for RunMultimodal: text and the images and files it
+// refers to.
+type Input struct {
+	Text   string
+	Images []ImageSource
+	Files  []FileSource
+}
+
+// ImageSource is an image input, given either as a URL or as raw bytes.
+type ImageSource struct {
+	// URL is an http(s) URL the provider fetches, or a base64 data URL
+	// ("data:image/png;base64,...").
+	URL string
+	// Data is the encoded image (PNG, JPEG, GIF or WebP), sent inline as a
+	// data URL. Used when URL is empty.
+	Data []byte
+	// MediaType of Data, e.g. "image/png"; detected from Data when empty.
+	MediaType string
+	// Detail is "low", "high" or "auto"; empty uses the provider default.
+	Detail string
+}
+
+// ImageURL returns an image input the provider fetches from url.
+func ImageURL(url string) ImageSource {
+	return ImageSource{URL: url}
+}
+
+// ImageBytes returns an image input sent inline, e.g. a screenshot.
+func ImageBytes(data []byte) ImageSource {
+	return ImageSource{Data: data}
+}
+
+// FileSource is a document attachment such as a PDF or CSV.
+type FileSource struct {
+	// Name is the file name shown to the model, e.g. "invoice.pdf".
+	Name string
+	Data []byte
+	// MediaType of Data, e.g. "application/pdf"; detected from Name's
+	// extension or from Data when empty.
+	MediaType string
+	// Upload sends the file once through the provider's files API (see
+	// providers.FileUploader) instead of inlining it as base64 in every
+	// request of the run, and deletes it when the run ends. Providers
+	// without a files API get the file inline.
+	Upload bool
+	// ID references a file already uploaded to the provider. It is sent
+	// instead of Data and not deleted.
+	ID string
+}
+
+// LoadFile reads a file attachment from disk.
+func LoadFile(path string) (FileSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileSource{}, err
+	}
+	return FileSource{Name: filepath.Base(path), Data: data}, nil
+}
+
+// file converts the source to a provider file.
+func (s FileSource) file() (providers.File, error) {
+	if s.ID != "" {
+		return providers.File{ID: s.ID, Name: s.Name, MediaType: s.MediaType}, nil
+	}
+	if len(s.Data) == 0 {
+		return providers.File{}, fmt.Errorf("%w: %q has no data or ID", ErrInvalidFile, s.Name)
+	}
+	mediaType := s.MediaType
+	if mediaType == "" {
+		mediaType = mime.TypeByExtension(filepath.Ext(s.Name))
+	}
+	if mediaType == "" {
+		mediaType = http.DetectContentType(s.Data)
+	}
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	name := s.Name
+	if name == "" {
+		name = "file"
+	}
+	return providers.File{Name: name, MediaType: mediaType, Data: s.Data}, nil
+}
+
+// image converts the source to a provider image.
+func (s ImageSource) image() (providers.Image, error) {
+	if s.URL != "" {
+		lower := strings.ToLower(s.URL)
+		switch {
+		case strings.HasPrefix(lower, "https://"), strings.HasPrefix(lower, "http://"):
+		case strings.HasPrefix(lower, "data:image/") && strings.Contains(lower, ";base64,"):
+		default:
+			return providers.Image{}, fmt.Errorf("%w: %q is not an http(s) or base64 data URL", ErrInvalidImage, truncateDescription(s.URL, 40))
+		}
+		return providers.Image{URL: s.URL, Detail: s.Detail}, nil
+	}
+	if len(s.Data) == 0 {
+		return providers.Image{}, fmt.Errorf("%w: no URL or data", ErrInvalidImage)
+	}
+	mediaType := s.MediaType
+	if mediaType == "" {
+		mediaType, _, _ = strings.Cut(http.DetectContentType(s.Data), ";")
+	}
+	if !strings.HasPrefix(mediaType, "image/") {
+		return providers.Image{}, fmt.Errorf("%w: data is %s", ErrInvalidImage, mediaType)
+	}
+	return providers.Image{
+		URL:    "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(s.Data),
+		Detail: s.Detail,
+	}, nil
+}
+
+// RunMultimodal executes the agent with a user turn of text, images and
+// files, for vision and document models such as screenshot or invoice
+// analysis agents. Images are sent as URLs or inline base64 data; files
+// inline or, with FileSource.Upload, through the provider's files API, and
+// uploaded files are deleted when the run ends. Middleware, memory and
+// traces see the text. An invalid attachment or failed upload is reported
+// as an error event before the run starts.
+func (a *Agent) RunMultimodal(ctx context.Context, input Input) <-chan Event {
+	msg := providers.Message{Role: providers.RoleUser, Content: input.Text}
+	for i, source := range input.Images {
+		image, err := source.image()
+		if err != nil {
+			return errorRun(fmt.Errorf("image %d: %w", i, err))
+		}
+		msg.Images = append(msg.Images, image)
+	}
+	upload := make([]bool, 0, len(input.Files))
+	for i, source := range input.Files {
+		file, err := source.file()
+		if err != nil {
+			return errorRun(fmt.Errorf("file %d: %w", i, err))
+		}
+		msg.Files = append(msg.Files, file)
+		upload = append(upload, source.Upload && source.ID == "")
+	}
+	uploader, _ := fileUploader(a.provider)
+	if uploader == nil || !slices.Contains(upload, true) {
+		return a.RunMessages(ctx, []providers.Message{msg})
+	}
+
+	out := make(chan Event, a.eventBuffer)
+	forward := a.forwarder(ctx)
+	go func() {
+		defer close(out)
+		var uploaded []string
+		defer func() { a.deleteFiles(ctx, uploader, uploaded) }()
+		for i, file := range msg.Files {
+			if !upload[i] {
+				continue
+			}
+			id, err := uploader.UploadFile(ctx, file.Name, file.MediaType, file.Data)
+			if err != nil {
+				forward.send(out, Error(fmt.Errorf("upload %s: %w", file.Name, err)))
+				return
+			}
+			uploaded = append(uploaded, id)
+			msg.Files[i] = providers.File{ID: id, Name: file.Name, MediaType: file.MediaType}
+		}
+		for event := range a.RunMessages(ctx, []providers.Message{msg}) {
+			forward.send(out, event)
+		}
+	}()
+	return out
+}
+
+// deleteFiles removes a run's uploaded files, also when the run was
+// cancelled.
+func (a *Agent) deleteFiles(ctx context.Context, uploader providers.FileUploader, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fileCleanupTimeout)
+	defer cancel()
+	for _, id := range ids {
+		if err := uploader.DeleteFile(ctx, id); err != nil {
+			a.logger.Warn("failed to delete uploaded file", "file_id", id, "error", err)
+		}
+	}
+}
+
+// fileUploader returns the files API of the agent's provider, if any.
+func fileUploader(provider providers.Provider) (providers.FileUploader, bool) {
+	uploader, ok := unwrapProvider(provider).(providers.FileUploader)
+	return uploader, ok
+}
+
+// errorRun returns a finished run reporting err.
+func errorRun(err error) <-chan Event {
+	events := make(chan Event, 1)
+	events <- Error(err)
+	close(events)
+	return events
+}
@@ -0,0 +1,128 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestRunMultimodal_SendsImages(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("A login form with an error banner.", nil)}
+	agent, err := New(Config{Provider: provider, Model: "gpt-4o", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	collectEvents(agent.RunMultimodal(context.Background(), Input{
+		Text: "What is wrong on this screen?",
+		Images: []ImageSource{
+			ImageBytes(png),
+			{URL: "https://example.com/before.png", Detail: "low"},
+		},
+	}), time.Second)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(provider.requests))
+	}
+	sent := provider.requests[0].Messages[0]
+	if sent.Content != "What is wrong on this screen?" || len(sent.Images) != 2 {
+		t.Fatalf("sent message = %+v", sent)
+	}
+	if !strings.HasPrefix(sent.Images[0].URL, "data:image/png;base64,iVBORw0KGgo") {
+		t.Errorf("inline image URL = %q", sent.Images[0].URL)
+	}
+	if sent.Images[1].URL != "https://example.com/before.png" || sent.Images[1].Detail != "low" {
+		t.Errorf("remote image = %+v", sent.Images[1])
+	}
+}
+
+func TestRunMultimodal_InvalidImage(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("unused", nil)}
+	agent, err := New(Config{Provider: provider, Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for name, source := range map[string]ImageSource{
+		"empty":     {},
+		"file path": ImageURL("/tmp/screenshot.png"),
+		"not image": ImageBytes([]byte("plain text, not an image")),
+	} {
+		events := collectEvents(agent.RunMultimodal(context.Background(), Input{Text: "hi", Images: []ImageSource{source}}), time.Second)
+		if len(events) != 1 {
+			t.Errorf("%s: events = %v, want one error", name, events)
+			continue
+		}
+		if detail, ok := events[0].ErrorDetail(); !ok || !errors.Is(detail, ErrInvalidImage) {
+			t.Errorf("%s: error = %v, want ErrInvalidImage", name, detail)
+		}
+	}
+	if len(provider.requests) != 0 {
+		t.Errorf("provider called %d times for invalid images", len(provider.requests))
+	}
+}
+
+// uploadingProvider records files uploaded and deleted through its files API.
+type uploadingProvider struct {
+	*recordingProvider
+	uploaded []string
+	deleted  []string
+}
+
+func (p *uploadingProvider) UploadFile(_ context.Context, name, mediaType string, data []byte) (string, error) {
+	p.uploaded = append(p.uploaded, name+" "+mediaType)
+	return "file-" + name, nil
+}
+
+func (p *uploadingProvider) DeleteFile(_ context.Context, id string) error {
+	p.deleted = append(p.deleted, id)
+	return nil
+}
+
+func TestRunMultimodal_Files(t *testing.T) {
+	provider := &uploadingProvider{recordingProvider: &recordingProvider{Provider: mockprovider.New().
+		WithResponse("Revenue grew 12%.", nil)}}
+	agent, err := New(Config{Provider: provider, Model: "gpt-4o", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.RunMultimodal(context.Background(), Input{
+		Text: "Summarize the quarter.",
+		Files: []FileSource{
+			{Name: "q3.pdf", Data: []byte("%PDF-1.7"), Upload: true},
+			{Name: "q3.csv", Data: []byte("month,revenue\njul,10")},
+			{ID: "file-existing", Name: "notes.pdf"},
+		},
+	}), time.Second)
+
+	files := provider.requests[0].Messages[0].Files
+	if len(files) != 3 {
+		t.Fatalf("files = %+v", files)
+	}
+	if files[0].ID != "file-q3.pdf" || files[0].Data != nil {
+		t.Errorf("uploaded file sent as %+v", files[0])
+	}
+	if files[1].ID != "" || files[1].MediaType != "text/csv" || string(files[1].Data) != "month,revenue\njul,10" {
+		t.Errorf("inline file = %+v", files[1])
+	}
+	if files[2].ID != "file-existing" {
+		t.Errorf("existing file = %+v", files[2])
+	}
+	if len(provider.uploaded) != 1 || provider.uploaded[0] != "q3.pdf application/pdf" {
+		t.Errorf("uploaded = %v", provider.uploaded)
+	}
+	if len(provider.deleted) != 1 || provider.deleted[0] != "file-q3.pdf" {
+		t.Errorf("deleted = %v, want only the file uploaded for the run", provider.deleted)
+	}
+
+	events := collectEvents(agent.RunMultimodal(context.Background(), Input{Files: []FileSource{{Name: "empty.pdf"}}}), time.Second)
+	if detail, ok := events[0].ErrorDetail(); !ok || !errors.Is(detail, ErrInvalidFile) {
+		t.Errorf("empty file: events = %v", events)
+	}
+}
@@ -0,0 +1,42 @@
+package agentkit
+
+//go:generate go run ./internal/gen/options
+
+// Option configures an agent created with New. Every Config field has a
+// generated With<Field> option (see options_gen.go), so subsystems can be
+// added to Config without breaking callers that use options. Config is
+// itself an Option that replaces the configuration built so far.
+type Option interface {
+	applyOption(*options)
+}
+
+type options struct {
+	cfg         Config
+	tools       []Tool
+	middlewares []Middleware
+}
+
+type optionFunc func(*options)
+
+func (f optionFunc) applyOption(o *options) { f(o) }
+
+func (c Config) applyOption(o *options) { o.cfg = c }
+
+// WithTool adds a tool to the agent.
+func WithTool(tool Tool) Option {
+	return optionFunc(func(o *options) { o.tools = append(o.tools, tool) })
+}
+
+// WithTools adds several tools to the agent.
+func WithTools(tools ...Tool) Option {
+	return optionFunc(func(o *options) { o.tools = append(o.tools, tools...) })
+}
+
+// WithMiddleware adds execution middleware to the agent.
+func WithMiddleware(m Middleware) Option {
+	return optionFunc(func(o *options) {
+		if m != nil {
+			o.middlewares = append(o.middlewares, m)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestNew_FunctionalOptions(t *testing.T) {
+	recorder := &startRecorder{}
+	agent, err := New(
+		WithProvider(mockprovider.New().WithResponse("hello", nil)),
+		WithModel("test-model"),
+		WithName("support"),
+		WithMaxIterations(3),
+		WithStreamResponses(false),
+		WithRetry(RetryConfig{MaxRetries: 1}),
+		WithTool(echoTool("echo")),
+		WithTools(echoTool("other")),
+		WithMiddleware(recorder),
+		WithMiddleware(nil),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, name := range []string{"echo", "other"} {
+		if _, ok := agent.tools[name]; !ok {
+			t.Errorf("tool %q not registered", name)
+		}
+	}
+	if agent.maxIterations != 3 || agent.agentName != "support" || agent.streamResponses {
+		t.Errorf("agent = maxIterations %d, name %q, stream %v", agent.maxIterations, agent.agentName, agent.streamResponses)
+	}
+
+	collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second)
+	if len(recorder.inputs) != 1 || recorder.inputs[0] != "hi" {
+		t.Errorf("middleware inputs = %v", recorder.inputs)
+	}
+}
+
+func TestNew_ConfigThenOptions(t *testing.T) {
+	cfg := Config{Provider: mockprovider.New(), Model: "test-model", AgentName: "base", MaxIterations: 2}
+	agent, err := New(cfg, WithName("override"), WithTool(echoTool("echo")))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if agent.agentName != "override" || agent.maxIterations != 2 {
+		t.Errorf("agent = name %q, maxIterations %d", agent.agentName, agent.maxIterations)
+	}
+	if _, ok := agent.tools["echo"]; !ok {
+		t.Error("tool not registered")
+	}
+}
+
+func TestNew_OptionErrors(t *testing.T) {
+	_, err := New(WithProvider(mockprovider.New()), WithModel("test-model"), WithTool(echoTool("echo")), WithTool(echoTool("echo")))
+	if !errors.Is(err, ErrDuplicateTool) {
+		t.Fatalf("New() error = %v, want ErrDuplicateTool", err)
+	}
+
+	_, err = New(WithProvider(mockprovider.New()), WithModel("test-model"), WithTemperature(3))
+	if err == nil {
+		t.Fatal("New() error = nil, want validation error")
+	}
+}
+
+// TestOptionsGenerated fails when a Config field has no generated option;
+// run go generate to fix it.
+func TestOptionsGenerated(t *testing.T) {
+	src, err := os.ReadFile("options_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := typ.Field(i).Name
+		if name == "LLMProvider" {
+			continue
+		}
+		if !strings.Contains(string(src), "o.cfg."+name+" =") {
+			t.Errorf("no option for Config.%s; run go generate", name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package agentkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ResponseLength is a soft target for the length of the final answer.
+type ResponseLength string
+
+const (
+	ResponseLengthShort  ResponseLength = "short"  // About 100 words
+	ResponseLengthMedium ResponseLength = "medium" // About 300 words
+	ResponseLengthLong   ResponseLength = "long"   // About 800 words
+)
+
+var responseLengthWords = map[ResponseLength]int{
+	ResponseLengthShort:  100,
+	ResponseLengthMedium: 300,
+	ResponseLengthLong:   800,
+}
+
+// LengthEnforcement decides what happens to a final answer that overshoots
+// its word target.
+type LengthEnforcement string
+
+const (
+	// LengthEnforceNone only instructs the model.
+	LengthEnforceNone LengthEnforcement = ""
+	// LengthEnforceTruncate cuts the answer at the last sentence that fits.
+	LengthEnforceTruncate LengthEnforcement = "truncate"
+	// LengthEnforceTighten asks the model once to rewrite the answer within
+	// the target.
+	LengthEnforceTighten LengthEnforcement = "tighten"
+)
+
+// OutputLengthConfig controls the length of the final answer. The word
+// target is added to the system prompt; MaxTokens is a hard cap sent to the
+// provider. Override it per run with ContextWithOutputLength.
+type OutputLengthConfig struct {
+	Target    ResponseLength    // Soft target; ignored when MaxWords is set
+	MaxWords  int               // Soft target in words
+	MaxTokens int               // Hard cap on output tokens per model call (0 = provider default)
+	Enforce   LengthEnforcement // What to do with answers over the target
+	// Tolerance is how far over the target an answer may go before it is
+	// enforced, as a fraction (default 0.2, i.e. 20% over).
+	Tolerance float64
+}
+
+// words returns the word target, or 0 without one.
+func (c OutputLengthConfig) words() int {
+	if c.MaxWords > 0 {
+		return c.MaxWords
+	}
+	return responseLengthWords[c.Target]
+}
+
+// instruction returns the system prompt instruction for the word target.
+func (c OutputLengthConfig) instruction() string {
+	words := c.words()
+	if words <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("Keep your final answer under %d words.", words)
+}
+
+// overLimit reports whether output exceeds the target plus tolerance.
+func (c OutputLengthConfig) overLimit(output string) bool {
+	words := c.words()
+	if words <= 0 {
+		return false
+	}
+	tolerance := c.Tolerance
+	if tolerance <= 0 {
+		tolerance = 0.2
+	}
+	return float64(countWords(output)) > float64(words)*(1+tolerance)
+}
+
+type outputLengthKey struct{}
+
+// ContextWithOutputLength overrides Config.OutputLength for runs started with ctx.
+func ContextWithOutputLength(ctx context.Context, cfg OutputLengthConfig) context.Context {
+	return context.WithValue(ctx, outputLengthKey{}, &cfg)
+}
+
+// outputLength returns the output length settings for the run, if any.
+func (a *Agent) outputLength(ctx context.Context) *OutputLengthConfig {
+	if cfg, ok := ctx.Value(outputLengthKey{}).(*OutputLengthConfig); ok {
+		return cfg
+	}
+	return a.lengthConfig
+}
+
+// enforceOutputLength applies the run's enforcement to a final answer that
+// overshoots its target. Tightening failures keep the original answer.
+// The enforced answer is reported in final_output; streamed chunks carry the
+// original.
+func (a *Agent) enforceOutputLength(ctx context.Context, history []providers.Message, output string, events chan<- Event, outcome *runOutcome) string {
+	cfg := a.outputLength(ctx)
+	if cfg == nil || cfg.Enforce == LengthEnforceNone || !cfg.overLimit(output) {
+		return output
+	}
+	words := cfg.words()
+	a.logger.Info("final answer over length target",
+		"words", countWords(output),
+		"target", words,
+		"enforce", cfg.Enforce)
+
+	switch cfg.Enforce {
+	case LengthEnforceTruncate:
+		return truncateWords(output, words)
+	case LengthEnforceTighten:
+		tighten := append(append([]providers.Message{}, history...), providers.Message{
+			Role: providers.RoleUser,
+			Content: fmt.Sprintf("Your answer is %d words. Rewrite it in at most %d words, keeping the key facts. Reply with the rewritten answer only.",
+				countWords(output), words),
+		})
+		req := a.buildCompletionRequest(ctx, tighten)
+		req.Tools, req.ToolChoice = nil, ""
+		callCtx, cancel := a.withLLMTimeout(ctx)
+		if cancel != nil {
+			defer cancel()
+		}
+		// Not streamed: the rewrite replaces the answer in final_output
+		// instead of being appended to the streamed chunks.
+		resp, err := a.provider.Complete(callCtx, req)
+		if err != nil || resp.Content == "" {
+			a.logger.Warn("failed to tighten final answer", "error", err)
+			return output
+		}
+		a.recordUsage(ctx, events, req.Model, resp.Usage, outcome)
+		return resp.Content
+	}
+	return output
+}
+
+// countWords counts whitespace-separated words.
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
+// truncateWords cuts text to at most maxWords words, at the end of the last
+// complete sentence when one fits, and marks the cut with an ellipsis.
+func truncateWords(text string, maxWords int) string {
+	count := 0
+	inWord := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		if !inWord {
+			inWord = true
+			count++
+			if count > maxWords {
+				cut := strings.TrimRightFunc(text[:i], unicode.IsSpace)
+				if end := strings.LastIndexAny(cut, ".!?"); end > len(cut)/2 {
+					return cut[:end+1]
+				}
+				return cut + "…"
+			}
+		}
+	}
+	return text
+}
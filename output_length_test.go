@@ -0,0 +1,110 @@
+package agentkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func finalResponse(events []Event) string {
+	for _, e := range events {
+		if e.Type == EventTypeFinalOutput {
+			response, _ := e.Data["response"].(string)
+			return response
+		}
+	}
+	return ""
+}
+
+func TestOutputLength_InstructionAndCap(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("Short answer.", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		SystemPrompt:    func(context.Context) string { return "Be helpful." },
+		OutputLength:    &OutputLengthConfig{Target: ResponseLengthShort, MaxTokens: 400},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	collectEvents(agent.Run(context.Background(), "hi"), time.Second)
+
+	req := provider.requests[0]
+	if req.SystemPrompt != "Be helpful.\n\nKeep your final answer under 100 words." || req.MaxTokens != 400 {
+		t.Errorf("system prompt = %q, max tokens = %d", req.SystemPrompt, req.MaxTokens)
+	}
+
+	// A per-run override replaces the agent's settings.
+	provider.requests = nil
+	provider.Provider.WithResponse("ok", nil)
+	ctx := ContextWithOutputLength(context.Background(), OutputLengthConfig{MaxWords: 20})
+	collectEvents(agent.Run(ctx, "hi"), time.Second)
+	if req := provider.requests[0]; !strings.HasSuffix(req.SystemPrompt, "under 20 words.") || req.MaxTokens != 0 {
+		t.Errorf("override: system prompt = %q, max tokens = %d", req.SystemPrompt, req.MaxTokens)
+	}
+}
+
+func TestOutputLength_Truncate(t *testing.T) {
+	long := "First sentence has five words. Second sentence has five words. Third sentence has five words."
+	agent, err := New(Config{
+		Provider:        mockprovider.New().WithResponse(long, nil),
+		Model:           "test-model",
+		StreamResponses: false,
+		OutputLength:    &OutputLengthConfig{MaxWords: 10, Enforce: LengthEnforceTruncate},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	got := finalResponse(collectEvents(agent.Run(context.Background(), "hi"), time.Second))
+	if got != "First sentence has five words. Second sentence has five words." {
+		t.Errorf("final output = %q", got)
+	}
+}
+
+func TestOutputLength_Tighten(t *testing.T) {
+	long := strings.Repeat("word ", 50)
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse(long, nil).
+		WithResponse("Tight answer.", nil)}
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		OutputLength:    &OutputLengthConfig{MaxWords: 10, Enforce: LengthEnforceTighten},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	events := collectEvents(agent.Run(context.Background(), "hi"), time.Second)
+
+	if got := finalResponse(events); got != "Tight answer." {
+		t.Errorf("final output = %q", got)
+	}
+	if len(provider.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(provider.requests))
+	}
+	last := provider.requests[1].Messages
+	if !strings.Contains(last[len(last)-1].Content, "at most 10 words") || provider.requests[1].Tools != nil {
+		t.Errorf("tighten request = %+v", provider.requests[1])
+	}
+	for _, e := range events {
+		if e.Type == EventTypeAgentComplete {
+			if usage := e.Data["total_tokens"]; usage != 60 {
+				t.Errorf("total_tokens = %v, want both calls counted", usage)
+			}
+		}
+	}
+}
+
+func TestTruncateWords(t *testing.T) {
+	if got := truncateWords("one two three four five six", 4); got != "one two three four…" {
+		t.Errorf("truncateWords = %q", got)
+	}
+	if got := truncateWords("short", 4); got != "short" {
+		t.Errorf("truncateWords = %q", got)
+	}
+}
@@ -0,0 +1,211 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrNoFinalOutput is returned by RunStructured and RunTyped when a run ends without a
+// final answer.
+var ErrNoFinalOutput = errors.New("agentkit: run completed without final output")
+
+// OutputSchemaConfig makes the agent's final answer a JSON value matching
+// Schema instead of free text. Models with structured outputs (see
+// ModelInfo.StrictSchemas) are constrained to the schema by the provider;
+// others are asked for JSON and given the schema in the system prompt.
+// Either way the answer is validated with ValidateSchema, and an invalid
+// one is sent back to the model with the problems for a corrected answer.
+// Override it per run with ContextWithOutputSchema.
+type OutputSchemaConfig struct {
+	// Name identifies the schema to the provider (default "output").
+	Name string
+	// Schema is the JSON schema of the answer, e.g. from SchemaFromStruct.
+	Schema map[string]any
+	// DisableStrict sends the schema without strict enforcement, for schemas
+	// that use keywords OpenAI's strict mode rejects or leave properties
+	// optional. The answer is still validated locally.
+	DisableStrict bool
+	// MaxRepairs limits the corrections requested for invalid answers
+	// (0 uses DefaultJSONRepairs; negative disables them).
+	MaxRepairs int
+}
+
+func (c OutputSchemaConfig) name() string {
+	if c.Name == "" {
+		return "output"
+	}
+	return c.Name
+}
+
+func (c OutputSchemaConfig) maxRepairs() int {
+	switch {
+	case c.MaxRepairs < 0:
+		return 0
+	case c.MaxRepairs == 0:
+		return DefaultJSONRepairs
+	}
+	return c.MaxRepairs
+}
+
+type outputSchemaKey struct{}
+
+// ContextWithOutputSchema overrides Config.OutputSchema for runs started with ctx.
+func ContextWithOutputSchema(ctx context.Context, cfg OutputSchemaConfig) context.Context {
+	return context.WithValue(ctx, outputSchemaKey{}, &cfg)
+}
+
+// outputSchema returns the output schema settings for the run, if any.
+func (a *Agent) outputSchema(ctx context.Context) *OutputSchemaConfig {
+	if cfg, ok := ctx.Value(outputSchemaKey{}).(*OutputSchemaConfig); ok {
+		return cfg
+	}
+	return a.outputSchemaConfig
+}
+
+// schemaInstruction asks for a bare JSON value matching schema.
+func schemaInstruction(schema map[string]any) (string, error) {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return "", fmt.Errorf("agentkit: encode output schema: %w", err)
+	}
+	return "Respond with a single JSON value that matches this JSON schema, with no prose or code fences:\n" + string(schemaJSON), nil
+}
+
+// applyOutputSchema asks the model for an answer matching the run's output
+// schema: natively on models with structured outputs, otherwise through JSON
+// mode and the system prompt.
+func (a *Agent) applyOutputSchema(ctx context.Context, req *providers.CompletionRequest) {
+	cfg := a.outputSchema(ctx)
+	if cfg == nil {
+		return
+	}
+	if info, ok := LookupModelInfo(req.Model); ok && info.StrictSchemas {
+		req.OutputSchema = &providers.OutputSchema{Name: cfg.name(), Schema: cfg.Schema, Strict: !cfg.DisableStrict}
+		return
+	}
+	instruction, err := schemaInstruction(cfg.Schema)
+	if err != nil {
+		a.logger.Warn("output schema not sent", "error", err)
+		return
+	}
+	req.SystemPrompt = strings.TrimSpace(req.SystemPrompt + "\n\n" + instruction)
+	req.TextFormat = "json_object"
+}
+
+// checkOutputSchema validates a final answer against the run's output
+// schema. It returns the JSON in the answer, or the message asking the
+// model to correct it while repairs remain. Once they run out it reports
+// ErrInvalidJSONOutput.
+func (a *Agent) checkOutputSchema(ctx context.Context, cfg *OutputSchemaConfig, model, content string, repairs *int, events chan<- Event) (string, *providers.Message, error) {
+	raw, problems := jsonOutputProblems(model, content, cfg.Schema)
+	if len(problems) == 0 {
+		return raw, nil, nil
+	}
+	if *repairs >= cfg.maxRepairs() {
+		err := fmt.Errorf("%w after %d repairs: %s", ErrInvalidJSONOutput, *repairs, strings.Join(problems, "; "))
+		a.emit(ctx, events, Error(err))
+		return "", nil, err
+	}
+	*repairs++
+	a.logger.Info("final answer does not match output schema", "problems", len(problems), "repair", *repairs)
+	a.emit(ctx, events, OutputInvalid(model, problems, *repairs))
+	return "", &providers.Message{
+		Role: providers.RoleUser,
+		Content: "Your reply does not match the schema:\n- " + strings.Join(problems, "\n- ") +
+			"\nReply with the corrected JSON only.",
+	}, nil
+}
+
+// RunStructured runs the agent and decodes its final answer into v. Unless
+// the agent has Config.OutputSchema, the schema is derived from v, which
+// must point to a struct.
+func (a *Agent) RunStructured(ctx context.Context, userMessage string, v any) error {
+	if a.outputSchema(ctx) == nil {
+		schema, err := SchemaFromStruct(v)
+		if err != nil {
+			return err
+		}
+		ctx = ContextWithOutputSchema(ctx, OutputSchemaConfig{Schema: schema})
+	}
+
+	output, err := a.runFinalOutput(ctx, userMessage)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(output), v); err != nil {
+		return fmt.Errorf("agentkit: decode final output: %w", err)
+	}
+	return nil
+}
+
+// RunTyped runs agent with an output schema generated from T by
+// StructToSchema and returns the final answer decoded into T. It is
+// RunStructured for callers that prefer a return value, and the output
+// counterpart of NewStructTool.
+//
+//	type Verdict struct {
+//	    Approve bool   `json:"approve" required:"true"`
+//	    Reason  string `json:"reason" required:"true"`
+//	}
+//	verdict, err := agentkit.RunTyped[Verdict](agent, ctx, "Approve this refund?")
+func RunTyped[T any](agent *Agent, ctx context.Context, prompt string) (T, error) {
+	var result T
+	schema, err := StructToSchema[T]()
+	if err != nil {
+		return result, err
+	}
+	ctx = ContextWithOutputSchema(ctx, OutputSchemaConfig{Name: typeSchemaName[T](), Schema: schema.ToMapStrict()})
+	output, err := agent.runFinalOutput(ctx, prompt)
+	if err != nil {
+		return result, err
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return result, fmt.Errorf("agentkit: decode final output into %T: %w", result, err)
+	}
+	return result, nil
+}
+
+// typeSchemaName names an output schema after T, e.g. "Verdict", keeping
+// only the characters OpenAI allows in schema names.
+func typeSchemaName[T any]() string {
+	name := strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, reflect.TypeFor[T]().Name())
+	if name == "" {
+		return "output"
+	}
+	return name
+}
+
+// runFinalOutput runs the agent to completion and returns its final answer
+// or the run's first error.
+func (a *Agent) runFinalOutput(ctx context.Context, userMessage string) (string, error) {
+	var output string
+	var runErr error
+	for event := range a.Run(ctx, userMessage) {
+		switch event.Type {
+		case EventTypeFinalOutput:
+			output, _ = event.Data["response"].(string)
+		case EventTypeError:
+			if detail, ok := event.ErrorDetail(); ok && runErr == nil {
+				runErr = detail
+			}
+		}
+	}
+	if runErr != nil {
+		return "", runErr
+	}
+	if output == "" {
+		return "", ErrNoFinalOutput
+	}
+	return output, nil
+}
@@ -0,0 +1,147 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+type ticketSummary struct {
+	Title    string `json:"title" required:"true"`
+	Priority string `json:"priority" required:"true" enum:"low,high"`
+}
+
+func TestRunStructured_StrictModel(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse(`{"title": "Login fails", "priority": "high"}`, nil)}
+	agent, err := New(Config{Provider: provider, Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var summary ticketSummary
+	if err := agent.RunStructured(context.Background(), "Summarize the ticket", &summary); err != nil {
+		t.Fatalf("RunStructured() error = %v", err)
+	}
+	if summary.Title != "Login fails" || summary.Priority != "high" {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	req := provider.requests[0]
+	if req.OutputSchema == nil || !req.OutputSchema.Strict || req.OutputSchema.Name != "output" {
+		t.Fatalf("output schema = %+v", req.OutputSchema)
+	}
+	if req.TextFormat != "" || strings.Contains(req.SystemPrompt, "JSON schema") {
+		t.Errorf("strict model got prompted JSON mode: format %q, prompt %q", req.TextFormat, req.SystemPrompt)
+	}
+}
+
+func TestOutputSchema_RepairsInvalidAnswer(t *testing.T) {
+	schema, err := SchemaFromStruct(ticketSummary{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("The ticket is about a login failure.", nil).
+		WithResponse("```json\n{\"title\": \"Login fails\", \"priority\": \"urgent\"}\n```", nil).
+		WithResponse(`{"title": "Login fails", "priority": "high"}`, nil)}
+	agent, err := New(Config{
+		Provider:     provider,
+		Model:        "test-model",
+		OutputSchema: &OutputSchemaConfig{Schema: schema},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := collectEvents(agent.Run(context.Background(), "Summarize the ticket"), time.Second)
+
+	var repairs []any
+	var output any
+	for _, event := range events {
+		switch event.Type {
+		case EventTypeOutputInvalid:
+			repairs = append(repairs, event.Data["repair"])
+		case EventTypeFinalOutput:
+			output = event.Data["response"]
+		}
+	}
+	if len(repairs) != 2 || repairs[1] != 2 {
+		t.Errorf("output_invalid repairs = %v, want 1 and 2", repairs)
+	}
+	if output != `{"title": "Login fails", "priority": "high"}` {
+		t.Errorf("final output = %v", output)
+	}
+
+	first := provider.requests[0]
+	if first.TextFormat != "json_object" || first.OutputSchema != nil || !strings.Contains(first.SystemPrompt, `"priority"`) {
+		t.Errorf("first request format %q, schema %v, prompt %q", first.TextFormat, first.OutputSchema, first.SystemPrompt)
+	}
+	last := provider.requests[2].Messages
+	if feedback := last[len(last)-1].Content; !strings.Contains(feedback, "must be one of") {
+		t.Errorf("repair message = %q", feedback)
+	}
+}
+
+func TestRunStructured_GivesUpAfterMaxRepairs(t *testing.T) {
+	provider := mockprovider.New().
+		WithResponse("not json", nil).
+		WithResponse("still not json", nil)
+	agent, err := New(Config{Provider: provider, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := ContextWithOutputSchema(context.Background(), OutputSchemaConfig{
+		Schema:     map[string]any{"type": "object"},
+		MaxRepairs: 1,
+	})
+	var out map[string]any
+	if err := agent.RunStructured(ctx, "hi", &out); !errors.Is(err, ErrInvalidJSONOutput) {
+		t.Errorf("RunStructured() error = %v, want ErrInvalidJSONOutput", err)
+	}
+}
+
+func TestRunTyped(t *testing.T) {
+	type Verdict struct {
+		Approve bool    `json:"approve" required:"true"`
+		Reason  string  `json:"reason" required:"true"`
+		Amount  *string `json:"amount"`
+	}
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse(`{"approve": true, "reason": "Within policy", "amount": null}`, nil)}
+	agent, err := New(Config{Provider: provider, Model: "gpt-4.1"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	verdict, err := RunTyped[Verdict](agent, context.Background(), "Approve this refund?")
+	if err != nil {
+		t.Fatalf("RunTyped() error = %v", err)
+	}
+	if !verdict.Approve || verdict.Reason != "Within policy" || verdict.Amount != nil {
+		t.Errorf("verdict = %+v", verdict)
+	}
+
+	schema := provider.requests[0].OutputSchema
+	if schema == nil || schema.Name != "Verdict" || !schema.Strict {
+		t.Fatalf("output schema = %+v", schema)
+	}
+	if required, _ := schema.Schema["required"].([]string); len(required) != 3 {
+		t.Errorf("required = %v, want all properties for strict mode", schema.Schema["required"])
+	}
+}
+
+func TestRunTyped_RejectsNonStruct(t *testing.T) {
+	agent, err := New(Config{Provider: mockprovider.New(), Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := RunTyped[[]string](agent, context.Background(), "hi"); !errors.Is(err, ErrInvalidStructSchema) {
+		t.Errorf("RunTyped() error = %v, want ErrInvalidStructSchema", err)
+	}
+}
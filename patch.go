@@ -0,0 +1,360 @@
+package agentkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrPatchFailed is reported when a patch does not apply to a document.
+var ErrPatchFailed = errors.New("agentkit: patch does not apply")
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// diffHunk is a hunk of a unified diff: the lines it replaces and the
+// lines replacing them.
+type diffHunk struct {
+	start    int // 0-based line of old in the original, -1 if unknown
+	old, new []string
+}
+
+// ApplyUnifiedDiff applies a unified diff to content. Hunks are located by
+// their context and removed lines, near the line numbers in their headers,
+// so the slightly wrong counts and offsets models write still apply. File
+// headers (--- and +++) and code fences are ignored.
+func ApplyUnifiedDiff(content, patch string) (string, error) {
+	hunks, err := parseUnifiedDiff(patch)
+	if err != nil {
+		return "", err
+	}
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	cursor, delta := 0, 0
+	for i, h := range hunks {
+		want := cursor
+		if h.start >= 0 {
+			want = max(h.start+delta, cursor)
+		}
+		at := findLines(lines, h.old, cursor, want)
+		if at < 0 {
+			return "", fmt.Errorf("%w: hunk %d: lines to replace not found:\n%s", ErrPatchFailed, i+1, strings.Join(h.old, "\n"))
+		}
+		lines = slices.Replace(lines, at, at+len(h.old), h.new...)
+		cursor = at + len(h.new)
+		delta += len(h.new) - len(h.old)
+	}
+
+	result := strings.Join(lines, "\n")
+	if trailingNewline || content == "" && len(lines) > 0 {
+		result += "\n"
+	}
+	return result, nil
+}
+
+func parseUnifiedDiff(patch string) ([]diffHunk, error) {
+	var hunks []diffHunk
+	var current *diffHunk
+	for _, line := range strings.Split(strings.TrimRight(patch, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			h := diffHunk{start: -1}
+			if m := hunkHeader.FindStringSubmatch(line); m != nil {
+				start, _ := strconv.Atoi(m[1])
+				h.start = max(start-1, 0)
+			}
+			hunks = append(hunks, h)
+			current = &hunks[len(hunks)-1]
+		case current == nil, strings.HasPrefix(line, "```"), strings.HasPrefix(line, `\`):
+			// Headers and prose before the first hunk, fences, "\ No newline".
+		case strings.HasPrefix(line, "---") && len(current.old)+len(current.new) == 0,
+			strings.HasPrefix(line, "+++") && len(current.old)+len(current.new) == 0:
+		case strings.HasPrefix(line, "-"):
+			current.old = append(current.old, line[1:])
+		case strings.HasPrefix(line, "+"):
+			current.new = append(current.new, line[1:])
+		case strings.HasPrefix(line, " "):
+			current.old = append(current.old, line[1:])
+			current.new = append(current.new, line[1:])
+		case line == "":
+			// An empty context line whose leading space was dropped.
+			current.old = append(current.old, "")
+			current.new = append(current.new, "")
+		default:
+			return nil, fmt.Errorf("%w: unexpected line in hunk %d: %q", ErrPatchFailed, len(hunks), line)
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("%w: no hunks (lines starting with @@)", ErrPatchFailed)
+	}
+	for i := range hunks {
+		// Trailing blank lines are usually an artifact of the reply, not context.
+		h := &hunks[i]
+		for len(h.old) > 0 && len(h.new) > 0 && h.old[len(h.old)-1] == "" && h.new[len(h.new)-1] == "" {
+			h.old, h.new = h.old[:len(h.old)-1], h.new[:len(h.new)-1]
+		}
+	}
+	return hunks, nil
+}
+
+// findLines returns where old occurs in lines at or after from, choosing
+// the occurrence nearest want, or -1.
+func findLines(lines, old []string, from, want int) int {
+	if len(old) == 0 {
+		return min(want, len(lines))
+	}
+	best := -1
+	for at := from; at+len(old) <= len(lines); at++ {
+		if !slices.Equal(lines[at:at+len(old)], old) {
+			continue
+		}
+		if best < 0 || abs(at-want) < abs(best-want) {
+			best = at
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// jsonPatchOp is an RFC 6902 operation.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to the JSON document
+// content. The result is indented when content spans several lines.
+func ApplyJSONPatch(content, patch string) (string, error) {
+	var doc any
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		return "", fmt.Errorf("agentkit: document is not JSON: %w", err)
+	}
+	var ops []jsonPatchOp
+	if err := json.Unmarshal([]byte(extractJSON(patch)), &ops); err != nil {
+		return "", fmt.Errorf("%w: not a JSON Patch array: %v", ErrPatchFailed, err)
+	}
+	for i, op := range ops {
+		var err error
+		if doc, err = applyJSONPatchOp(doc, op); err != nil {
+			return "", fmt.Errorf("%w: operation %d (%s %s): %v", ErrPatchFailed, i+1, op.Op, op.Path, err)
+		}
+	}
+
+	var data []byte
+	var err error
+	if strings.Contains(strings.TrimSpace(content), "\n") {
+		data, err = json.MarshalIndent(doc, "", "  ")
+	} else {
+		data, err = json.Marshal(doc)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func applyJSONPatchOp(doc any, op jsonPatchOp) (any, error) {
+	value := func() (any, error) {
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+		var v any
+		err := json.Unmarshal(op.Value, &v)
+		return v, err
+	}
+	switch op.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerSet(doc, op.Path, v, true)
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerSet(doc, op.Path, v, false)
+	case "remove":
+		doc, _, err := jsonPointerRemove(doc, op.Path)
+		return doc, err
+	case "move", "copy":
+		v, err := jsonPointerGet(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "move" {
+			if op.Path == op.From || strings.HasPrefix(op.Path, op.From+"/") {
+				return nil, errors.New("cannot move a value into itself")
+			}
+			if doc, _, err = jsonPointerRemove(doc, op.From); err != nil {
+				return nil, err
+			}
+		} else {
+			v = cloneJSON(v)
+		}
+		return jsonPointerSet(doc, op.Path, v, true)
+	case "test":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		got, err := jsonPointerGet(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(v)
+		if string(gotJSON) != string(wantJSON) {
+			return nil, fmt.Errorf("value is %s, not %s", gotJSON, wantJSON)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+func splitJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, n int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > n || i == n && !appending {
+		return 0, fmt.Errorf("index %q out of range", token)
+	}
+	return i, nil
+}
+
+func jsonPointerGet(doc any, pointer string) (any, error) {
+	tokens, err := splitJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		switch node := doc.(type) {
+		case map[string]any:
+			v, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", token)
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%q not found", token)
+		}
+	}
+	return doc, nil
+}
+
+// jsonPointerSet adds (inserting into arrays) or replaces the value at
+// pointer, returning the new document.
+func jsonPointerSet(doc any, pointer string, value any, add bool) (any, error) {
+	tokens, err := splitJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(doc, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		if _, ok := node[last]; !ok && !add {
+			return nil, fmt.Errorf("%q not found", last)
+		}
+		node[last] = value
+		return doc, nil
+	case []any:
+		i, err := arrayIndex(last, len(node), add)
+		if err != nil {
+			return nil, err
+		}
+		if add {
+			node = slices.Insert(node, i, value)
+		} else {
+			node[i] = value
+		}
+		return jsonPointerSet(doc, pointer[:strings.LastIndex(pointer, "/")], node, false)
+	}
+	return nil, fmt.Errorf("cannot set %q on a %T", last, parent)
+}
+
+// jsonPointerRemove removes the value at pointer, returning the new
+// document and the removed value.
+func jsonPointerRemove(doc any, pointer string) (any, any, error) {
+	tokens, err := splitJSONPointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("cannot remove the document root")
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := jsonPointerGet(doc, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		removed, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("%q not found", last)
+		}
+		delete(node, last)
+		return doc, removed, nil
+	case []any:
+		i, err := arrayIndex(last, len(node), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		removed := node[i]
+		doc, err = jsonPointerSet(doc, parentPointer, slices.Delete(slices.Clone(node), i, i+1), false)
+		return doc, removed, err
+	}
+	return nil, nil, fmt.Errorf("%q not found", last)
+}
+
+func cloneJSON(v any) any {
+	data, _ := json.Marshal(v)
+	var clone any
+	_ = json.Unmarshal(data, &clone)
+	return clone
+}
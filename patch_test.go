@@ -0,0 +1,82 @@
+package agentkit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	doc := "# Title\n\nIntro line.\n\n## Usage\n\nRun it.\n\n## License\n\nMIT\n"
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "exact",
+			patch: "--- a/README.md\n+++ b/README.md\n@@ -5,3 +5,4 @@\n ## Usage\n \n-Run it.\n+Install it.\n+Then run it.\n",
+			want:  "# Title\n\nIntro line.\n\n## Usage\n\nInstall it.\nThen run it.\n\n## License\n\nMIT\n",
+		},
+		{
+			name:  "wrong line numbers and counts, fenced",
+			patch: "```diff\n@@ -1,9 +1,9 @@\n ## License\n \n-MIT\n+Apache-2.0\n```",
+			want:  "# Title\n\nIntro line.\n\n## Usage\n\nRun it.\n\n## License\n\nApache-2.0\n",
+		},
+		{
+			name:  "two hunks, empty context line without a space",
+			patch: "@@ -1,3 +1,3 @@\n-# Title\n+# New title\n\n Intro line.\n@@ -11 +11 @@\n-MIT\n+BSD\n",
+			want:  "# New title\n\nIntro line.\n\n## Usage\n\nRun it.\n\n## License\n\nBSD\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyUnifiedDiff(doc, tt.patch)
+			if err != nil {
+				t.Fatalf("ApplyUnifiedDiff() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ApplyUnifiedDiff() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	for _, patch := range []string{"Intro line.", "@@ -3 +3 @@\n-Outro line.\n+x\n", "@@ -1 +1 @@\n*bad\n"} {
+		if _, err := ApplyUnifiedDiff(doc, patch); !errors.Is(err, ErrPatchFailed) {
+			t.Errorf("ApplyUnifiedDiff(%q) error = %v, want ErrPatchFailed", patch, err)
+		}
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := `{"title":"Draft","tags":["a","b"],"meta":{"owner":"ann"}}`
+	patch := `[
+		{"op":"test","path":"/title","value":"Draft"},
+		{"op":"replace","path":"/title","value":"Final"},
+		{"op":"add","path":"/tags/1","value":"x"},
+		{"op":"add","path":"/tags/-","value":"z"},
+		{"op":"remove","path":"/tags/0"},
+		{"op":"move","from":"/meta/owner","path":"/owner"},
+		{"op":"copy","from":"/tags","path":"/meta/tags"},
+		{"op":"add","path":"/a~1b","value":1}
+	]`
+	got, err := ApplyJSONPatch(doc, patch)
+	if err != nil {
+		t.Fatalf("ApplyJSONPatch() error = %v", err)
+	}
+	want := `{"a/b":1,"meta":{"tags":["x","b","z"]},"owner":"ann","tags":["x","b","z"],"title":"Final"}`
+	if got != want {
+		t.Errorf("ApplyJSONPatch() =\n%s\nwant\n%s", got, want)
+	}
+
+	for _, patch := range []string{
+		`[{"op":"test","path":"/title","value":"Final"}]`,
+		`[{"op":"replace","path":"/missing","value":1}]`,
+		`[{"op":"remove","path":"/tags/5"}]`,
+		`[{"op":"move","from":"/meta","path":"/meta/inner"}]`,
+		`not a patch`,
+	} {
+		if _, err := ApplyJSONPatch(doc, patch); !errors.Is(err, ErrPatchFailed) {
+			t.Errorf("ApplyJSONPatch(%s) error = %v, want ErrPatchFailed", patch, err)
+		}
+	}
+}
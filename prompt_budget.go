@@ -0,0 +1,82 @@
+package agentkit
+
+import (
+	"encoding/json"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// PromptBudget breaks a request's estimated prompt tokens down by what they
+// are spent on, to show what to trim when costs creep up. The agent emits it
+// as a context.prompt_budget event before each model call and records it in
+// the generation's trace metadata under "prompt_budget".
+//
+// Counts come from the context policy's Estimate function when one is
+// configured and from EstimateTokens otherwise, so they approximate the
+// provider's reported prompt tokens rather than match them.
+type PromptBudget struct {
+	Instructions int `json:"instructions"` // System prompt and developer messages
+	Tools        int `json:"tools"`        // Tool definitions
+	History      int `json:"history"`      // Earlier turns, tool calls and tool results
+	Input        int `json:"input"`        // The latest user message
+}
+
+// Total returns the estimated prompt tokens of the whole request.
+func (b PromptBudget) Total() int {
+	return b.Instructions + b.Tools + b.History + b.Input
+}
+
+// attributes returns the budget as trace metadata.
+func (b PromptBudget) attributes() map[string]any {
+	return map[string]any{
+		"instructions": b.Instructions,
+		"tools":        b.Tools,
+		"history":      b.History,
+		"input":        b.Input,
+		"total":        b.Total(),
+	}
+}
+
+// promptBudget estimates the prompt tokens req spends on each part.
+func (a *Agent) promptBudget(req providers.CompletionRequest) PromptBudget {
+	estimate := EstimateTokens
+	if a.contextPolicy != nil && a.contextPolicy.Estimate != nil {
+		estimate = a.contextPolicy.Estimate
+	}
+
+	var budget PromptBudget
+	if req.SystemPrompt != "" {
+		budget.Instructions += estimate([]providers.Message{{Role: providers.RoleSystem, Content: req.SystemPrompt}})
+	}
+	if len(req.Tools) > 0 {
+		if data, err := json.Marshal(req.Tools); err == nil {
+			budget.Tools = estimate([]providers.Message{{Role: providers.RoleSystem, Content: string(data)}})
+		}
+	}
+
+	lastUser := -1
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == providers.RoleUser {
+			lastUser = i
+			break
+		}
+	}
+	var instructions, history []providers.Message
+	for i, msg := range req.Messages {
+		switch {
+		case msg.Role == providers.RoleDeveloper || msg.Role == providers.RoleSystem:
+			instructions = append(instructions, msg)
+		case i == lastUser:
+			budget.Input = estimate(req.Messages[i : i+1])
+		default:
+			history = append(history, msg)
+		}
+	}
+	if len(instructions) > 0 {
+		budget.Instructions += estimate(instructions)
+	}
+	if len(history) > 0 {
+		budget.History = estimate(history)
+	}
+	return budget
+}
@@ -0,0 +1,103 @@
+package agentkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestPromptBudget_SplitsRequest(t *testing.T) {
+	agent := &Agent{}
+	req := providers.CompletionRequest{
+		SystemPrompt: strings.Repeat("rule ", 40),
+		Tools: []providers.ToolDefinition{{
+			Name:        "search",
+			Description: strings.Repeat("find ", 20),
+		}},
+		Messages: []providers.Message{
+			{Role: providers.RoleDeveloper, Content: "Answer in French."},
+			{Role: providers.RoleUser, Content: strings.Repeat("old ", 100)},
+			{Role: providers.RoleAssistant, Content: strings.Repeat("reply ", 50)},
+			{Role: providers.RoleUser, Content: "What now?"},
+			{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "1", Name: "search"}}},
+			{Role: providers.RoleTool, Content: "result", ToolCallID: "1"},
+		},
+	}
+
+	budget := agent.promptBudget(req)
+
+	wantInstructions := EstimateTokens([]providers.Message{{Content: req.SystemPrompt}}) + EstimateTokens(req.Messages[:1])
+	if budget.Instructions != wantInstructions {
+		t.Errorf("Instructions = %d, want %d", budget.Instructions, wantInstructions)
+	}
+	if want := EstimateTokens(req.Messages[3:4]); budget.Input != want {
+		t.Errorf("Input = %d, want %d (the latest user message)", budget.Input, want)
+	}
+	history := []providers.Message{req.Messages[1], req.Messages[2], req.Messages[4], req.Messages[5]}
+	if want := EstimateTokens(history); budget.History != want {
+		t.Errorf("History = %d, want %d", budget.History, want)
+	}
+	if budget.Tools <= 25 {
+		t.Errorf("Tools = %d, want the definitions counted", budget.Tools)
+	}
+	if budget.Total() != budget.Instructions+budget.Tools+budget.History+budget.Input {
+		t.Errorf("Total() = %d, want the sum of the parts", budget.Total())
+	}
+}
+
+func TestPromptBudget_UsesPolicyEstimate(t *testing.T) {
+	agent := &Agent{contextPolicy: &ContextPolicy{Estimate: func(messages []providers.Message) int {
+		return 7 * len(messages)
+	}}}
+
+	budget := agent.promptBudget(providers.CompletionRequest{
+		SystemPrompt: "Be brief.",
+		Messages:     []providers.Message{{Role: providers.RoleUser, Content: "Hi"}},
+	})
+
+	if budget != (PromptBudget{Instructions: 7, Input: 7}) {
+		t.Errorf("budget = %+v, want the policy's estimate", budget)
+	}
+}
+
+func TestPromptBudget_EmittedPerCall(t *testing.T) {
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "lookup", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		SystemPrompt:    func(context.Context) string { return "You look things up." },
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("lookup").
+		WithDescription("Look up a record").
+		WithHandler(func(context.Context, map[string]any) (any, error) { return "record", nil }).
+		Build())
+
+	events := collectEvents(agent.Run(context.Background(), "Find the record"), time.Second)
+
+	var budgets []Event
+	for _, event := range events {
+		if event.Type == EventTypePromptBudget {
+			budgets = append(budgets, event)
+		}
+	}
+	if len(budgets) != 2 {
+		t.Fatalf("got %d context.prompt_budget events, want one per model call", len(budgets))
+	}
+	first, second := budgets[0].Data, budgets[1].Data
+	if first["model"] != "test-model" || first["tools_tokens"].(int) == 0 || first["input_tokens"].(int) == 0 {
+		t.Errorf("first budget = %+v", first)
+	}
+	if first["history_tokens"].(int) != 0 || second["history_tokens"].(int) == 0 {
+		t.Errorf("history_tokens = %v then %v, want the tool round counted as history", first["history_tokens"], second["history_tokens"])
+	}
+}
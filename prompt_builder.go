@@ -0,0 +1,239 @@
+package agentkit
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Suggested section orders, leaving room between them. Sections that
+// change often, like the date, go last so the stable start of the prompt
+// stays cacheable by the provider.
+const (
+	OrderPersona      = 100
+	OrderInstructions = 200
+	OrderTools        = 300
+	OrderMemory       = 400
+	OrderContext      = 500
+	OrderDateTime     = 900
+)
+
+// PromptSection is a named part of a system prompt composed by a
+// PromptBuilder.
+type PromptSection struct {
+	Name  string
+	Order int    // Sections render by Order, then by Name
+	Title string // Rendered as a "## Title" heading when set
+	// Render returns the section's text; an empty result leaves the
+	// section out of the prompt.
+	Render func(ctx context.Context) string
+	// TTL caches the rendered text for this long (0 renders on every model
+	// call), for sections that are slow to build or should stay stable.
+	TTL time.Duration
+	// CacheKey scopes the cache, e.g. by user or tenant; cached text is
+	// shared by every run with the same key.
+	CacheKey func(ctx context.Context) string
+
+	memory bool // Renders the sections added by memory, lessons and graph memory
+}
+
+// StaticSection returns a section with fixed text.
+func StaticSection(name string, order int, text string) PromptSection {
+	return PromptSection{Name: name, Order: order, Render: func(context.Context) string { return text }}
+}
+
+// DateTimeSection returns a section with the current date and time in loc
+// (UTC when nil), at OrderDateTime.
+func DateTimeSection(loc *time.Location) PromptSection {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return PromptSection{Name: "datetime", Order: OrderDateTime, Render: func(context.Context) string {
+		return "Current date and time: " + time.Now().In(loc).Format("Monday, 2 January 2006 15:04 MST")
+	}}
+}
+
+// ToolGuidanceSection returns a section with guidance on using tools,
+// listing the tools offered in the run, at OrderTools. It is left out when
+// the run has no tools.
+func ToolGuidanceSection(guidance string) PromptSection {
+	return PromptSection{Name: "tools", Order: OrderTools, Render: func(ctx context.Context) string {
+		run, ok := ctx.Value(activeRunKey).(*activeRun)
+		if !ok {
+			return ""
+		}
+		names := run.agent.enabledToolNames(ctx)
+		if len(names) == 0 {
+			return ""
+		}
+		return strings.TrimSpace(guidance + "\n\nAvailable tools: " + strings.Join(names, ", ") + ".")
+	}}
+}
+
+// MemorySection places what memory, lessons and graph memory add to the
+// prompt, at OrderMemory. Without it they are appended after the last
+// section.
+func MemorySection() PromptSection {
+	return PromptSection{Name: "memory", Order: OrderMemory, memory: true, Render: func(ctx context.Context) string {
+		return appendPromptSections(ctx, "")
+	}}
+}
+
+// PromptBuilder composes a system prompt from named sections in a
+// deterministic order, so large applications manage prompt parts
+// separately instead of concatenating strings. Set it as
+// Config.PromptBuilder. Sections can be added, replaced and removed while
+// the agent is serving.
+type PromptBuilder struct {
+	mu       sync.RWMutex
+	sections []PromptSection // Sorted by Order, then Name
+	cache    map[promptCacheKey]cachedSection
+}
+
+type promptCacheKey struct{ section, key string }
+
+type cachedSection struct {
+	text    string
+	expires time.Time
+}
+
+// maxCachedSections bounds the section cache; expired entries are swept
+// when it is exceeded.
+const maxCachedSections = 4096
+
+// NewPromptBuilder creates a builder with sections.
+func NewPromptBuilder(sections ...PromptSection) *PromptBuilder {
+	b := &PromptBuilder{cache: make(map[promptCacheKey]cachedSection)}
+	for _, section := range sections {
+		b.Set(section)
+	}
+	return b
+}
+
+// Set adds section, replacing any section with the same name and its
+// cached text.
+func (b *PromptBuilder) Set(section PromptSection) *PromptBuilder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sections = slices.DeleteFunc(b.sections, func(s PromptSection) bool { return s.Name == section.Name })
+	b.sections = append(b.sections, section)
+	slices.SortStableFunc(b.sections, func(x, y PromptSection) int {
+		return cmp.Or(cmp.Compare(x.Order, y.Order), strings.Compare(x.Name, y.Name))
+	})
+	b.invalidate(section.Name)
+	return b
+}
+
+// Remove removes the named section.
+func (b *PromptBuilder) Remove(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sections = slices.DeleteFunc(b.sections, func(s PromptSection) bool { return s.Name == name })
+	b.invalidate(name)
+}
+
+// Sections returns the section names in render order.
+func (b *PromptBuilder) Sections() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	names := make([]string, len(b.sections))
+	for i, section := range b.sections {
+		names[i] = section.Name
+	}
+	return names
+}
+
+// Invalidate drops the cached text of the named sections, or of every
+// section when called without names.
+func (b *PromptBuilder) Invalidate(names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(names) == 0 {
+		clear(b.cache)
+		return
+	}
+	b.invalidate(names...)
+}
+
+func (b *PromptBuilder) invalidate(names ...string) {
+	for key := range b.cache {
+		if slices.Contains(names, key.section) {
+			delete(b.cache, key)
+		}
+	}
+}
+
+// Build renders the sections in order, separated by blank lines.
+func (b *PromptBuilder) Build(ctx context.Context) string {
+	b.mu.RLock()
+	sections := slices.Clone(b.sections)
+	b.mu.RUnlock()
+
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		if placed, ok := ctx.Value(memoryPlacedKey).(*bool); ok && section.memory {
+			*placed = true
+		}
+		text := strings.TrimSpace(b.render(ctx, section))
+		if text == "" {
+			continue
+		}
+		if section.Title != "" {
+			text = fmt.Sprintf("## %s\n%s", section.Title, text)
+		}
+		parts = append(parts, text)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// SystemPrompt returns Build as a SystemPromptFunc, e.g. for a
+// SystemPromptVariants entry. A MemorySection places memory there too.
+func (b *PromptBuilder) SystemPrompt() SystemPromptFunc {
+	return b.Build
+}
+
+// render returns the section's text, from the cache while it is fresh.
+func (b *PromptBuilder) render(ctx context.Context, section PromptSection) string {
+	if section.Render == nil {
+		return ""
+	}
+	if section.TTL <= 0 {
+		return section.Render(ctx)
+	}
+	key := promptCacheKey{section: section.Name}
+	if section.CacheKey != nil {
+		key.key = section.CacheKey(ctx)
+	}
+	now := time.Now()
+	b.mu.RLock()
+	cached, ok := b.cache[key]
+	b.mu.RUnlock()
+	if ok && now.Before(cached.expires) {
+		return cached.text
+	}
+
+	text := section.Render(ctx)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !slices.ContainsFunc(b.sections, func(s PromptSection) bool { return s.Name == section.Name }) {
+		return text // Removed while rendering
+	}
+	if len(b.cache) >= maxCachedSections {
+		for k, c := range b.cache {
+			if !now.Before(c.expires) {
+				delete(b.cache, k)
+			}
+		}
+	}
+	b.cache[key] = cachedSection{text: text, expires: now.Add(section.TTL)}
+	return text
+}
+
+// memoryPlacedKey holds a *bool that Build sets when it renders a
+// MemorySection, so the agent doesn't append memory to the prompt again.
+// It works for any SystemPromptFunc backed by a builder.
+const memoryPlacedKey contextKey = "agentkit_memory_placed"
@@ -0,0 +1,110 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestPromptBuilder_OrdersSections(t *testing.T) {
+	pb := NewPromptBuilder(
+		StaticSection("policy", OrderInstructions, "Refunds need a receipt."),
+		StaticSection("persona", OrderPersona, "You are a support agent."),
+		PromptSection{Name: "empty", Order: OrderContext, Render: func(context.Context) string { return " " }},
+		PromptSection{Name: "faq", Order: OrderInstructions, Title: "FAQ", Render: func(context.Context) string { return "Shipping takes 3 days." }},
+	)
+	if got := pb.Sections(); !slices.Equal(got, []string{"persona", "faq", "policy", "empty"}) {
+		t.Errorf("Sections() = %v", got)
+	}
+	want := "You are a support agent.\n\n## FAQ\nShipping takes 3 days.\n\nRefunds need a receipt."
+	if got := pb.Build(context.Background()); got != want {
+		t.Errorf("Build() = %q, want %q", got, want)
+	}
+
+	pb.Set(StaticSection("persona", OrderPersona, "You are a sales agent."))
+	pb.Remove("faq")
+	if got := pb.Build(context.Background()); got != "You are a sales agent.\n\nRefunds need a receipt." {
+		t.Errorf("Build() after changes = %q", got)
+	}
+}
+
+func TestPromptBuilder_CachesSections(t *testing.T) {
+	renders := 0
+	type tenantKey struct{}
+	pb := NewPromptBuilder(PromptSection{
+		Name: "tenant",
+		TTL:  time.Hour,
+		CacheKey: func(ctx context.Context) string {
+			tenant, _ := ctx.Value(tenantKey{}).(string)
+			return tenant
+		},
+		Render: func(ctx context.Context) string {
+			renders++
+			return "Tenant: " + ctx.Value(tenantKey{}).(string)
+		},
+	})
+	acme := context.WithValue(context.Background(), tenantKey{}, "acme")
+	globex := context.WithValue(context.Background(), tenantKey{}, "globex")
+
+	pb.Build(acme)
+	if got := pb.Build(acme); got != "Tenant: acme" || renders != 1 {
+		t.Errorf("cached Build() = %q after %d renders, want one render", got, renders)
+	}
+	if got := pb.Build(globex); got != "Tenant: globex" || renders != 2 {
+		t.Errorf("Build() for another key = %q after %d renders", got, renders)
+	}
+	pb.Invalidate("tenant")
+	pb.Build(acme)
+	if renders != 3 {
+		t.Errorf("renders after Invalidate = %d, want 3", renders)
+	}
+}
+
+func TestPromptBuilder_Agent(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("ok", nil)}
+	pb := NewPromptBuilder(
+		StaticSection("persona", OrderPersona, "You are a triage bot."),
+		ToolGuidanceSection("Search before answering."),
+		MemorySection(),
+		StaticSection("footer", OrderDateTime, "Be brief."),
+	)
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false, PromptBuilder: pb})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("search").Build())
+
+	collectEvents(agent.Run(withPromptSection(context.Background(), "## Known facts\nUser prefers email."), "Help"), time.Second)
+	prompt := provider.requests[0].SystemPrompt
+	want := []string{"You are a triage bot.", "Search before answering.\n\nAvailable tools: search.", "## Known facts\nUser prefers email.", "Be brief."}
+	if !strings.HasPrefix(prompt, strings.Join(want, "\n\n")) || strings.Count(prompt, "Known facts") != 1 {
+		t.Errorf("system prompt = %q", prompt)
+	}
+
+	// As a variant, the builder still places memory once.
+	variant, err := New(Config{
+		Provider:             provider,
+		Model:                "test-model",
+		StreamResponses:      false,
+		SystemPrompt:         func(context.Context) string { return "Default prompt." },
+		SystemPromptVariants: map[string]SystemPromptFunc{"test": pb.SystemPrompt()},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	collectEvents(variant.Run(withPromptSection(context.Background(), "## Known facts\nUser prefers email."), "Help"), time.Second)
+	prompt = provider.requests[len(provider.requests)-1].SystemPrompt
+	if !strings.HasPrefix(prompt, "You are a triage bot.") || strings.Count(prompt, "Known facts") != 1 {
+		t.Errorf("variant system prompt = %q", prompt)
+	}
+
+	_, err = New(Config{Provider: provider, Model: "test-model", PromptBuilder: pb, SystemPrompt: func(context.Context) string { return "x" }})
+	if !errors.Is(err, ErrConflictingPrompts) {
+		t.Errorf("New() with both prompts: error = %v, want ErrConflictingPrompts", err)
+	}
+}
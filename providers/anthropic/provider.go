@@ -0,0 +1,421 @@
+// Package anthropic implements the Provider interface for Anthropic's Messages API.
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+const (
+	messagesEndpoint = "https://api.anthropic.com/v1/messages"
+	apiVersion       = "2023-06-01"
+	// DefaultMaxTokens is sent when a request sets no MaxTokens; the Messages
+	// API requires a limit.
+	DefaultMaxTokens = 8192
+)
+
+// Provider implements providers.Provider for Anthropic.
+type Provider struct {
+	apiKey     string
+	endpoint   string
+	httpClient *http.Client
+	logger     *slog.Logger
+}
+
+// New creates a new Anthropic provider.
+func New(apiKey string, logger *slog.Logger) *Provider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Provider{
+		apiKey:     apiKey,
+		endpoint:   messagesEndpoint,
+		httpClient: providers.DefaultHTTPClient(),
+		logger:     logger,
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with, e.g. one from
+// providers.NewHTTPClient with a larger pool. Providers share
+// providers.DefaultHTTPClient by default.
+func (p *Provider) WithHTTPClient(client *http.Client) *Provider {
+	if client != nil {
+		p.httpClient = client
+	}
+	return p
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "anthropic"
+}
+
+// Complete generates a non-streaming completion.
+func (p *Provider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	resp, err := p.send(ctx, p.toAPIRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var apiResp messageResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return fromAPIResponse(&apiResp), nil
+}
+
+// Stream generates a streaming completion.
+func (p *Provider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	apiReq := p.toAPIRequest(req)
+	apiReq.Stream = true
+
+	resp, err := p.send(ctx, apiReq)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamReader(resp.Body, p.logger), nil
+}
+
+// send posts apiReq and returns the response, or the API error for a non-200 status.
+func (p *Provider) send(ctx context.Context, apiReq apiRequest) (*http.Response, error) {
+	jsonData, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", apiVersion)
+	if apiReq.Stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	providers.ObserveRateLimits(ctx, p.Name(), resp.StatusCode, resp.Header)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, parseAPIError(resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+// toAPIRequest converts a provider-agnostic request to the Messages API format.
+// System and developer messages move into the top-level system prompt, which
+// is the only place the Messages API accepts instructions.
+func (p *Provider) toAPIRequest(req providers.CompletionRequest) apiRequest {
+	system, messages := providers.FoldDeveloperMessages(req.SystemPrompt, req.Messages)
+	var systemParts []string
+	if system != "" {
+		systemParts = append(systemParts, system)
+	}
+	conversation := make([]providers.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == providers.RoleSystem {
+			if msg.Content != "" {
+				systemParts = append(systemParts, msg.Content)
+			}
+			continue
+		}
+		conversation = append(conversation, msg)
+	}
+
+	apiReq := apiRequest{
+		Model:     req.Model,
+		System:    strings.Join(systemParts, "\n\n"),
+		Messages:  toAPIMessages(conversation),
+		MaxTokens: req.MaxTokens,
+		Stream:    req.Stream,
+	}
+	if apiReq.MaxTokens <= 0 {
+		apiReq.MaxTokens = DefaultMaxTokens
+	}
+	if req.Temperature != 0 || req.Deterministic {
+		temperature := req.Temperature
+		apiReq.Temperature = &temperature
+	}
+	if req.TopP != 0 {
+		topP := req.TopP
+		apiReq.TopP = &topP
+	}
+	if userID := req.Metadata["user_id"]; userID != "" {
+		apiReq.Metadata = &metadata{UserID: userID}
+	}
+
+	if len(req.Tools) > 0 {
+		apiReq.Tools = make([]tool, len(req.Tools))
+		for i, t := range req.Tools {
+			schema := t.Parameters
+			if schema == nil {
+				schema = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			apiReq.Tools[i] = tool{Name: t.Name, Description: t.Description, InputSchema: schema}
+		}
+		apiReq.ToolChoice = toAPIToolChoice(req.ToolChoice, req.ParallelToolCalls)
+	}
+	return apiReq
+}
+
+// toAPIToolChoice maps agentkit tool choices ("auto", "required", "none" or
+// a tool name) to the Messages API.
+func toAPIToolChoice(choice string, parallel bool) *toolChoice {
+	tc := &toolChoice{DisableParallelToolUse: !parallel}
+	switch choice {
+	case "", "auto":
+		tc.Type = "auto"
+	case "required":
+		tc.Type = "any"
+	case "none":
+		return &toolChoice{Type: "none"}
+	default:
+		tc.Type, tc.Name = "tool", choice
+	}
+	return tc
+}
+
+// toAPIMessages converts messages to content blocks. Tool results become
+// tool_result blocks in a user turn, and consecutive messages with the same
+// role are merged, as the Messages API expects alternating turns.
+func toAPIMessages(messages []providers.Message) []message {
+	out := make([]message, 0, len(messages))
+	for _, msg := range messages {
+		role := "user"
+		var blocks []contentBlock
+		switch {
+		case msg.ToolCallID != "" || msg.Role == providers.RoleTool:
+			blocks = append(blocks, contentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		case msg.Role == providers.RoleAssistant:
+			role = "assistant"
+			if msg.Content != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input := call.Arguments
+				if input == nil {
+					input = map[string]any{}
+				}
+				blocks = append(blocks, contentBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+			}
+		default:
+			if msg.Content != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, image := range msg.Images {
+				blocks = append(blocks, contentBlock{Type: "image", Source: toImageSource(image.URL)})
+			}
+			for _, file := range msg.Files {
+				if source := toDocumentSource(file); source != nil {
+					blocks = append(blocks, contentBlock{Type: "document", Source: source, Title: file.Name})
+				}
+			}
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Role == role {
+			out[n-1].Content = append(out[n-1].Content, blocks...)
+			continue
+		}
+		out = append(out, message{Role: role, Content: blocks})
+	}
+	return out
+}
+
+// toImageSource accepts https URLs and base64 data URLs.
+func toImageSource(url string) *imageSource {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if mediaType, data, ok := strings.Cut(rest, ";base64,"); ok {
+			return &imageSource{Type: "base64", MediaType: mediaType, Data: data}
+		}
+	}
+	return &imageSource{Type: "url", URL: url}
+}
+
+// toDocumentSource sends PDFs as base64 and text files (CSV, JSON,
+// Markdown) as plain text. Other files, and files uploaded elsewhere (only
+// an ID), have no document source and are skipped.
+func toDocumentSource(file providers.File) *imageSource {
+	switch {
+	case len(file.Data) == 0:
+		return nil
+	case file.MediaType == "application/pdf":
+		return &imageSource{Type: "base64", MediaType: file.MediaType, Data: base64.StdEncoding.EncodeToString(file.Data)}
+	case providers.IsTextMediaType(file.MediaType):
+		return &imageSource{Type: "text", MediaType: "text/plain", Data: string(file.Data)}
+	}
+	return nil
+}
+
+// fromAPIResponse converts a Messages API response to a provider-agnostic response.
+func fromAPIResponse(resp *messageResponse) *providers.CompletionResponse {
+	domainResp := &providers.CompletionResponse{
+		ID:           resp.ID,
+		Model:        resp.Model,
+		Created:      time.Now(),
+		FinishReason: toFinishReason(resp.StopReason),
+		Usage:        resp.Usage.tokenUsage(),
+	}
+	var text, thinking strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "thinking":
+			thinking.WriteString(block.Thinking)
+		case "tool_use":
+			args, _ := block.Input.(map[string]any)
+			if args == nil {
+				args = map[string]any{}
+			}
+			domainResp.ToolCalls = append(domainResp.ToolCalls, providers.ToolCall{
+				ID:        block.ID,
+				Name:      block.Name,
+				Arguments: args,
+			})
+		}
+	}
+	domainResp.Content = text.String()
+	domainResp.ReasoningSummary = thinking.String()
+	return domainResp
+}
+
+func toFinishReason(stopReason string) providers.FinishReason {
+	switch stopReason {
+	case "tool_use":
+		return providers.FinishReasonToolCalls
+	case "max_tokens":
+		return providers.FinishReasonLength
+	case "":
+		return ""
+	default:
+		return providers.FinishReasonStop
+	}
+}
+
+// Anthropic API types (internal to this package)
+
+type apiRequest struct {
+	Model       string      `json:"model"`
+	System      string      `json:"system,omitempty"`
+	Messages    []message   `json:"messages"`
+	MaxTokens   int         `json:"max_tokens"`
+	Temperature *float32    `json:"temperature,omitempty"`
+	TopP        *float32    `json:"top_p,omitempty"`
+	Stream      bool        `json:"stream,omitempty"`
+	Tools       []tool      `json:"tools,omitempty"`
+	ToolChoice  *toolChoice `json:"tool_choice,omitempty"`
+	Metadata    *metadata   `json:"metadata,omitempty"`
+}
+
+type message struct {
+	Role    string         `json:"role"`
+	Content []contentBlock `json:"content"`
+}
+
+type contentBlock struct {
+	Type      string       `json:"type"`
+	Text      string       `json:"text,omitempty"`
+	Thinking  string       `json:"thinking,omitempty"`
+	ID        string       `json:"id,omitempty"`
+	Name      string       `json:"name,omitempty"`
+	Input     any          `json:"input,omitempty"`
+	ToolUseID string       `json:"tool_use_id,omitempty"`
+	Content   string       `json:"content,omitempty"`
+	Source    *imageSource `json:"source,omitempty"`
+	Title     string       `json:"title,omitempty"`
+}
+
+type imageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type toolChoice struct {
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
+}
+
+type metadata struct {
+	UserID string `json:"user_id"`
+}
+
+type messageResponse struct {
+	ID         string         `json:"id"`
+	Model      string         `json:"model"`
+	Content    []contentBlock `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      usage          `json:"usage"`
+}
+
+type usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u usage) tokenUsage() providers.TokenUsage {
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return providers.TokenUsage{
+		PromptTokens:     prompt,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      prompt + u.OutputTokens,
+	}
+}
+
+type apiError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func parseAPIError(statusCode int, body []byte) error {
+	var errResp struct {
+		Error apiError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error.Message == "" {
+		return fmt.Errorf("API error (status %d): %s", statusCode, string(body))
+	}
+
+	msg := fmt.Sprintf("API error (status %d): %s (type: %s)", statusCode, errResp.Error.Message, errResp.Error.Type)
+	if strings.Contains(errResp.Error.Message, "prompt is too long") {
+		return fmt.Errorf("%w: %s", providers.ErrContextLengthExceeded, msg)
+	}
+	return fmt.Errorf("%s", msg)
+}
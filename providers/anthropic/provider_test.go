@@ -0,0 +1,205 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+func TestToAPIRequest(t *testing.T) {
+	p := New("key", nil)
+	req := p.toAPIRequest(providers.CompletionRequest{
+		Model:        "claude-sonnet-4-5",
+		SystemPrompt: "Be brief.",
+		Messages: []providers.Message{
+			{Role: providers.RoleDeveloper, Content: "Refunds need approval."},
+			{Role: providers.RoleSystem, Content: "Customer is on the pro plan."},
+			{Role: providers.RoleUser, Content: "refund order 7", Images: []providers.Image{{URL: "data:image/png;base64,AAAA"}}},
+			{Role: providers.RoleAssistant, Content: "Checking.", ToolCalls: []providers.ToolCall{
+				{ID: "toolu_1", Name: "lookup", Arguments: map[string]any{"id": "7"}},
+				{ID: "toolu_2", Name: "policy"},
+			}},
+			{Role: providers.RoleTool, ToolCallID: "toolu_1", Content: `{"status":"shipped"}`},
+			{Role: providers.RoleTool, ToolCallID: "toolu_2", Content: "30 days"},
+		},
+		Tools:             []providers.ToolDefinition{{Name: "lookup", Description: "Look up an order"}},
+		ToolChoice:        "required",
+		ParallelToolCalls: true,
+	})
+
+	if req.System != "Be brief.\n\nRefunds need approval.\n\nCustomer is on the pro plan." {
+		t.Errorf("system = %q", req.System)
+	}
+	if req.MaxTokens != DefaultMaxTokens || req.Temperature != nil {
+		t.Errorf("max_tokens = %d, temperature = %v", req.MaxTokens, req.Temperature)
+	}
+	if len(req.Messages) != 3 {
+		t.Fatalf("messages = %+v, want user, assistant, user", req.Messages)
+	}
+	user := req.Messages[0]
+	if user.Role != "user" || user.Content[1].Source.Type != "base64" || user.Content[1].Source.MediaType != "image/png" {
+		t.Errorf("user message = %+v", user)
+	}
+	assistant := req.Messages[1]
+	if assistant.Role != "assistant" || len(assistant.Content) != 3 || assistant.Content[1].Type != "tool_use" || assistant.Content[1].ID != "toolu_1" {
+		t.Errorf("assistant message = %+v", assistant)
+	}
+	results := req.Messages[2]
+	if results.Role != "user" || len(results.Content) != 2 || results.Content[1].ToolUseID != "toolu_2" {
+		t.Errorf("tool results = %+v, want both results in one user turn", results)
+	}
+	if req.ToolChoice.Type != "any" || req.ToolChoice.DisableParallelToolUse {
+		t.Errorf("tool_choice = %+v", req.ToolChoice)
+	}
+	if req.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("input_schema = %v", req.Tools[0].InputSchema)
+	}
+
+	deterministic := p.toAPIRequest(providers.CompletionRequest{Model: "claude-sonnet-4-5", Deterministic: true})
+	if deterministic.Temperature == nil || *deterministic.Temperature != 0 {
+		t.Errorf("temperature = %v, want explicit 0", deterministic.Temperature)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") != apiVersion {
+			t.Errorf("headers = %v", r.Header)
+		}
+		var body apiRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "claude-sonnet-4-5" || body.Stream {
+			t.Errorf("body = %+v", body)
+		}
+		_, _ = w.Write([]byte(`{
+			"id": "msg_1", "model": "claude-sonnet-4-5", "stop_reason": "tool_use",
+			"content": [
+				{"type": "text", "text": "Let me check."},
+				{"type": "tool_use", "id": "toolu_1", "name": "lookup", "input": {"id": "7"}}
+			],
+			"usage": {"input_tokens": 100, "output_tokens": 20, "cache_read_input_tokens": 50}
+		}`))
+	}))
+	defer server.Close()
+
+	p := New("key", nil)
+	p.endpoint = server.URL
+	resp, err := p.Complete(context.Background(), providers.CompletionRequest{
+		Model:    "claude-sonnet-4-5",
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "order 7?"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Content != "Let me check." || resp.FinishReason != providers.FinishReasonToolCalls {
+		t.Errorf("response = %+v", resp)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID != "toolu_1" || resp.ToolCalls[0].Arguments["id"] != "7" {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.Usage.PromptTokens != 150 || resp.Usage.CompletionTokens != 20 || resp.Usage.TotalTokens != 170 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+}
+
+func TestComplete_ContextLengthExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"type": "error", "error": {"type": "invalid_request_error", "message": "prompt is too long: 210000 tokens > 200000 maximum"}}`))
+	}))
+	defer server.Close()
+
+	p := New("key", nil)
+	p.endpoint = server.URL
+	_, err := p.Complete(context.Background(), providers.CompletionRequest{Model: "claude-sonnet-4-5"})
+	if !errors.Is(err, providers.ErrContextLengthExceeded) {
+		t.Errorf("error = %v, want ErrContextLengthExceeded", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	events := []string{
+		`{"type": "message_start", "message": {"id": "msg_1", "usage": {"input_tokens": 40, "output_tokens": 1}}}`,
+		`{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}`,
+		`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Checking"}}`,
+		`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": " now."}}`,
+		`{"type": "content_block_stop", "index": 0}`,
+		`{"type": "content_block_start", "index": 1, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "lookup", "input": {}}}`,
+		`{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "{\"id\": "}}`,
+		`{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "\"7\"}"}}`,
+		`{"type": "content_block_stop", "index": 1}`,
+		`{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 25}}`,
+		`{"type": "message_stop"}`,
+	}
+	var body strings.Builder
+	for _, e := range events {
+		body.WriteString("event: x\ndata: " + e + "\n\n")
+	}
+	body.WriteString("event: ping\ndata: {\"type\": \"ping\"}\n\n")
+
+	stream := newStreamReader(io.NopCloser(strings.NewReader(body.String())), nil)
+	var content, deltas, args string
+	var complete *providers.StreamChunk
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		content += chunk.Content
+		deltas += chunk.ToolArgsDelta
+		if chunk.ToolArgs != "" {
+			args = chunk.ToolArgs
+		}
+		if chunk.IsComplete {
+			complete = chunk
+		}
+	}
+
+	if content != "Checking now." || deltas != `{"id": "7"}` || args != `{"id": "7"}` {
+		t.Errorf("content = %q, deltas = %q, args = %q", content, deltas, args)
+	}
+	if complete == nil || complete.FinishReason != providers.FinishReasonToolCalls || complete.Usage.CompletionTokens != 25 || complete.Usage.PromptTokens != 40 {
+		t.Errorf("completion chunk = %+v", complete)
+	}
+}
+
+func TestStream_Error(t *testing.T) {
+	body := "event: error\ndata: {\"type\": \"error\", \"error\": {\"type\": \"overloaded_error\", \"message\": \"Overloaded\"}}\n\n"
+	stream := newStreamReader(io.NopCloser(strings.NewReader(body)), nil)
+	if _, err := stream.Next(); err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("Next() error = %v", err)
+	}
+}
+
+func TestToAPIMessages_Documents(t *testing.T) {
+	messages := toAPIMessages([]providers.Message{{
+		Role:    providers.RoleUser,
+		Content: "compare these",
+		Files: []providers.File{
+			{Name: "q3.pdf", MediaType: "application/pdf", Data: []byte("%PDF")},
+			{Name: "q3.csv", MediaType: "text/csv", Data: []byte("a,b")},
+			{Name: "q3.xlsx", MediaType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Data: []byte("PK")},
+			{ID: "file-abc"},
+		},
+	}})
+	blocks := messages[0].Content
+	if len(blocks) != 3 {
+		t.Fatalf("blocks = %+v, want text and two documents", blocks)
+	}
+	if pdf := blocks[1]; pdf.Type != "document" || pdf.Title != "q3.pdf" || pdf.Source.Type != "base64" || pdf.Source.Data != "JVBERg==" {
+		t.Errorf("pdf block = %+v %+v", pdf, pdf.Source)
+	}
+	if csv := blocks[2]; csv.Source.Type != "text" || csv.Source.MediaType != "text/plain" || csv.Source.Data != "a,b" {
+		t.Errorf("csv block = %+v", csv.Source)
+	}
+}
@@ -0,0 +1,171 @@
+package anthropic
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// streamReader turns Messages API server-sent events into stream chunks.
+// Text and thinking deltas are forwarded as they arrive; tool_use blocks
+// produce a name chunk, argument deltas and a final chunk with the complete
+// arguments; message_stop produces the completion chunk with usage.
+type streamReader struct {
+	reader  io.ReadCloser
+	lines   *bufio.Reader
+	logger  *slog.Logger
+	pending []*providers.StreamChunk
+	blocks  map[int]*streamBlock
+	usage   usage
+	stop    string
+	done    bool
+}
+
+// streamBlock is a content block being streamed.
+type streamBlock struct {
+	kind string
+	id   string
+	name string
+	args strings.Builder
+}
+
+func newStreamReader(reader io.ReadCloser, logger *slog.Logger) *streamReader {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &streamReader{
+		reader: reader,
+		lines:  bufio.NewReader(reader),
+		logger: logger,
+		blocks: make(map[int]*streamBlock),
+	}
+}
+
+// Next returns the next chunk, or io.EOF after the completion chunk.
+func (s *streamReader) Next() (*providers.StreamChunk, error) {
+	for {
+		if len(s.pending) > 0 {
+			chunk := s.pending[0]
+			s.pending = s.pending[1:]
+			return chunk, nil
+		}
+		if s.done {
+			return nil, io.EOF
+		}
+		data, err := s.readEvent()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.handle(data); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close closes the underlying response body.
+func (s *streamReader) Close() error {
+	return s.reader.Close()
+}
+
+// readEvent returns the data of the next server-sent event.
+func (s *streamReader) readEvent() (string, error) {
+	var data strings.Builder
+	for {
+		line, err := s.lines.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if after, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(after, " "))
+		}
+		if (line == "" || err != nil) && data.Len() > 0 {
+			return data.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (s *streamReader) handle(data string) error {
+	var event streamEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		s.logger.Error("failed to parse stream event", "error", err)
+		return nil
+	}
+
+	switch event.Type {
+	case "message_start":
+		s.usage = event.Message.Usage
+	case "content_block_start":
+		block := &streamBlock{kind: event.ContentBlock.Type, id: event.ContentBlock.ID, name: event.ContentBlock.Name}
+		s.blocks[event.Index] = block
+		if block.kind == "tool_use" {
+			s.pending = append(s.pending, &providers.StreamChunk{ToolCallID: block.id, ToolName: block.name})
+		}
+	case "content_block_delta":
+		block := s.blocks[event.Index]
+		switch event.Delta.Type {
+		case "text_delta":
+			s.pending = append(s.pending, &providers.StreamChunk{Content: event.Delta.Text})
+		case "thinking_delta":
+			s.pending = append(s.pending, &providers.StreamChunk{ReasoningSummary: event.Delta.Thinking})
+		case "input_json_delta":
+			if block == nil || event.Delta.PartialJSON == "" {
+				return nil
+			}
+			block.args.WriteString(event.Delta.PartialJSON)
+			s.pending = append(s.pending, &providers.StreamChunk{
+				ToolCallID:    block.id,
+				ToolName:      block.name,
+				ToolArgsDelta: event.Delta.PartialJSON,
+			})
+		}
+	case "content_block_stop":
+		block := s.blocks[event.Index]
+		delete(s.blocks, event.Index)
+		if block == nil || block.kind != "tool_use" {
+			return nil
+		}
+		args := block.args.String()
+		if args == "" {
+			args = "{}"
+		}
+		s.pending = append(s.pending, &providers.StreamChunk{ToolCallID: block.id, ToolName: block.name, ToolArgs: args})
+	case "message_delta":
+		s.stop = event.Delta.StopReason
+		if event.Usage.OutputTokens > 0 {
+			s.usage.OutputTokens = event.Usage.OutputTokens
+		}
+	case "message_stop":
+		usage := s.usage.tokenUsage()
+		s.pending = append(s.pending, &providers.StreamChunk{
+			IsComplete:   true,
+			FinishReason: toFinishReason(s.stop),
+			Usage:        &usage,
+		})
+		s.done = true
+	case "error":
+		return fmt.Errorf("stream error: %s (type: %s)", event.Error.Message, event.Error.Type)
+	}
+	return nil
+}
+
+type streamEvent struct {
+	Type         string          `json:"type"`
+	Index        int             `json:"index"`
+	Message      messageResponse `json:"message"`
+	ContentBlock contentBlock    `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage usage    `json:"usage"`
+	Error apiError `json:"error"`
+}
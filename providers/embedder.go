@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Embedder converts text into embedding vectors.
+// Implementations must return one vector per input, in input order.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFunc adapts a function to Embedder.
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// Embed implements Embedder.
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+// BatchEmbedder splits large Embed calls into batches of at most BatchSize
+// texts, sending up to Concurrency batches at a time, and reassembles the
+// vectors in input order. The first failing batch fails the call.
+type BatchEmbedder struct {
+	Embedder    Embedder
+	BatchSize   int // Texts per call to Embedder; 0 sends everything in one call
+	Concurrency int // Batches in flight (default 1)
+}
+
+// Embed implements Embedder.
+func (b BatchEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if b.BatchSize <= 0 || len(texts) <= b.BatchSize {
+		return embedExactly(ctx, b.Embedder, texts)
+	}
+	concurrency := max(b.Concurrency, 1)
+
+	vectors := make([][]float32, len(texts))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for start := 0; start < len(texts); start += b.BatchSize {
+		end := min(start+b.BatchSize, len(texts))
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			batch, err := embedExactly(ctx, b.Embedder, texts[start:end])
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("embed texts %d-%d: %w", start, end-1, err)
+					cancel()
+				})
+				return
+			}
+			copy(vectors[start:end], batch)
+		}(start, end)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// embedExactly calls e and checks it returned one vector per text.
+func embedExactly(ctx context.Context, e Embedder, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	vectors, err := e.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
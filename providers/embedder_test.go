@@ -0,0 +1,53 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatchEmbedder_PreservesOrder(t *testing.T) {
+	var calls atomic.Int32
+	inner := EmbedderFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		calls.Add(1)
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			vectors[i] = []float32{float32(len(text))}
+		}
+		return vectors, nil
+	})
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+
+	vectors, err := BatchEmbedder{Embedder: inner, BatchSize: 2, Concurrency: 3}.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("made %d calls, want 3", calls.Load())
+	}
+	for i, v := range vectors {
+		if int(v[0]) != i+1 {
+			t.Errorf("vectors[%d] = %v, want %d", i, v, i+1)
+		}
+	}
+}
+
+func TestBatchEmbedder_Errors(t *testing.T) {
+	failing := EmbedderFunc(func(_ context.Context, texts []string) ([][]float32, error) {
+		if texts[0] == "bad" {
+			return nil, errors.New("boom")
+		}
+		return make([][]float32, len(texts)), nil
+	})
+	_, err := BatchEmbedder{Embedder: failing, BatchSize: 1}.Embed(context.Background(), []string{"ok", "bad"})
+	if err == nil || !strings.Contains(err.Error(), "embed texts 1-1: boom") {
+		t.Errorf("error = %v", err)
+	}
+
+	short := EmbedderFunc(func(context.Context, []string) ([][]float32, error) { return nil, nil })
+	if _, err := (BatchEmbedder{Embedder: short}).Embed(context.Background(), []string{"a"}); err == nil {
+		t.Error("want an error for a missing vector")
+	}
+}
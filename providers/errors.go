@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrContextLengthExceeded is wrapped by provider errors reporting that the
+// request does not fit the model's context window.
+var ErrContextLengthExceeded = errors.New("providers: context length exceeded")
+
+// IsContextLengthExceeded reports whether err means the request did not fit
+// the model's context window. Besides ErrContextLengthExceeded it recognizes
+// the messages of providers that return plain errors.
+func IsContextLengthExceeded(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrContextLengthExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "context_length_exceeded") ||
+		strings.Contains(msg, "maximum context length") ||
+		strings.Contains(msg, "context window")
+}
+
+// ErrPreviousResponseNotFound is wrapped by provider errors reporting that
+// CompletionRequest.PreviousResponseID names a response the provider no
+// longer stores (expired, deleted or never stored).
+var ErrPreviousResponseNotFound = errors.New("providers: previous response not found")
+
+// IsPreviousResponseNotFound reports whether err means the previous response
+// a request continued from is gone.
+func IsPreviousResponseNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrPreviousResponseNotFound) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "previous_response_not_found") ||
+		(strings.Contains(msg, "previous response") && strings.Contains(msg, "not found"))
+}
+
+var statusPattern = regexp.MustCompile(`\(status (\d{3})\)`)
+
+// StatusCode returns the HTTP status code of a provider API error. The
+// built-in providers report it as "API error (status N)".
+func StatusCode(err error) (int, bool) {
+	if err == nil {
+		return 0, false
+	}
+	m := statusPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, false
+	}
+	code, _ := strconv.Atoi(m[1])
+	return code, true
+}
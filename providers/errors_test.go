@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsContextLengthExceeded(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("%w: too long", ErrContextLengthExceeded), true},
+		{errors.New("API error (status 400): too long (code: context_length_exceeded)"), true},
+		{errors.New("This model's maximum context length is 128000 tokens"), true},
+		{errors.New("rate limited"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsContextLengthExceeded(tt.err); got != tt.want {
+			t.Errorf("IsContextLengthExceeded(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestIsPreviousResponseNotFound(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("%w: gone", ErrPreviousResponseNotFound), true},
+		{errors.New("API error (status 400): Previous response with id 'resp_1' not found. (code: previous_response_not_found)"), true},
+		{errors.New("API error (status 404): model not found"), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsPreviousResponseNotFound(tt.err); got != tt.want {
+			t.Errorf("IsPreviousResponseNotFound(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
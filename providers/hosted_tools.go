@@ -0,0 +1,29 @@
+package providers
+
+// Hosted tool types of the OpenAI Responses API.
+const (
+	HostedToolWebSearch       = "web_search"
+	HostedToolFileSearch      = "file_search"
+	HostedToolCodeInterpreter = "code_interpreter"
+)
+
+// HostedTool is a tool the provider runs itself, such as OpenAI's web
+// search, instead of returning a tool call for the agent to execute.
+// Providers without hosted tools ignore them.
+type HostedTool struct {
+	Type string
+	// Options are type-specific settings sent with the tool, e.g.
+	// "vector_store_ids" for file search.
+	Options map[string]any
+}
+
+// HostedToolCall reports a hosted tool's activity during a response. A
+// streamed call is reported on every status change.
+type HostedToolCall struct {
+	ID     string
+	Type   string // The HostedTool type, e.g. "web_search"
+	Status string // e.g. "in_progress", "searching", "completed" or "failed"
+	// Details holds the provider-reported fields, such as the search
+	// action, file search results or the interpreted code and its outputs.
+	Details map[string]any
+}
@@ -0,0 +1,24 @@
+package providers
+
+import "context"
+
+// Moderator classifies text against a content policy. The OpenAI provider
+// implements it with the moderation endpoint.
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (*Moderation, error)
+}
+
+// ModeratorFunc adapts a function to Moderator.
+type ModeratorFunc func(ctx context.Context, text string) (*Moderation, error)
+
+// Moderate implements Moderator.
+func (f ModeratorFunc) Moderate(ctx context.Context, text string) (*Moderation, error) {
+	return f(ctx, text)
+}
+
+// Moderation is a moderator's verdict on a text.
+type Moderation struct {
+	Flagged    bool
+	Categories []string           // Flagged categories, e.g. "harassment", "violence"
+	Scores     map[string]float64 // Per-category scores, when the moderator reports them
+}
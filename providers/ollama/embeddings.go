@@ -0,0 +1,93 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Embedding defaults.
+const (
+	DefaultEmbeddingModel = "nomic-embed-text"
+	DefaultEmbeddingBatch = 64
+)
+
+// Embedder implements providers.Embedder with Ollama's /api/embed endpoint,
+// so embeddings can be computed offline. Large inputs are split into
+// batches of BatchSize.
+type Embedder struct {
+	provider *Provider
+	// Model defaults to DefaultEmbeddingModel.
+	Model string
+	// BatchSize caps inputs per request (default DefaultEmbeddingBatch).
+	BatchSize int
+	// Concurrency is how many batches are sent at once (default 1).
+	Concurrency int
+}
+
+// NewEmbedder creates an embedder for model on the server at baseURL
+// (DefaultBaseURL when empty).
+func NewEmbedder(baseURL, model string) *Embedder {
+	return New(baseURL, nil).Embedder(model)
+}
+
+// Embedder returns an embedder for model on the provider's server.
+func (p *Provider) Embedder(model string) *Embedder {
+	return &Embedder{provider: p, Model: model}
+}
+
+// Embed implements providers.Embedder.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	batchSize := e.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultEmbeddingBatch
+	}
+	return providers.BatchEmbedder{
+		Embedder:    providers.EmbedderFunc(e.embed),
+		BatchSize:   batchSize,
+		Concurrency: e.Concurrency,
+	}.Embed(ctx, texts)
+}
+
+// embed sends one request for texts.
+func (e *Embedder) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := e.Model
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+	jsonData, err := json.Marshal(map[string]any{"model": model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", e.provider.baseURL+"/api/embed", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.provider.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseAPIError(resp.StatusCode, body)
+	}
+	var apiResp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return apiResp.Embeddings, nil
+}
@@ -0,0 +1,46 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEmbedder_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		var body struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != DefaultEmbeddingModel {
+			t.Errorf("model = %q", body.Model)
+		}
+		if body.Input[0] == "fail" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "model not found"}`))
+			return
+		}
+		embeddings := make([][]float32, len(body.Input))
+		for i := range embeddings {
+			embeddings[i] = []float32{0.5, 0.5}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	defer server.Close()
+
+	embedder := NewEmbedder(server.URL, "")
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	if err != nil || len(vectors) != 2 || len(vectors[1]) != 2 {
+		t.Fatalf("Embed() = %v, %v", vectors, err)
+	}
+	if _, err := embedder.Embed(context.Background(), []string{"fail"}); err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Prompted tools mode: models without native function calling get the tool
+// definitions in the system prompt and reply with a JSON object to call
+// them. Tool results are sent back as user messages.
+
+// toolsPrompt describes tools and the tool call reply format.
+func toolsPrompt(tools []providers.ToolDefinition, choice string) string {
+	var b strings.Builder
+	b.WriteString("You can call these tools:\n")
+	for _, t := range tools {
+		params, _ := json.Marshal(t.Parameters)
+		fmt.Fprintf(&b, "- %s: %s\n  Parameters (JSON schema): %s\n", t.Name, t.Description, params)
+	}
+	b.WriteString("\nTo call tools, reply with only this JSON and nothing else:\n")
+	b.WriteString(`{"tool_calls": [{"name": "<tool name>", "arguments": {<arguments>}}]}`)
+	switch choice {
+	case "", "auto":
+		b.WriteString("\nOtherwise, answer the user directly in plain text.")
+	case "required":
+		b.WriteString("\nYou must call at least one tool.")
+	default:
+		fmt.Fprintf(&b, "\nYou must call the %s tool.", choice)
+	}
+	return b.String()
+}
+
+// toolResultPrompt wraps a tool result in a user message.
+func toolResultPrompt(name, content string) string {
+	if name == "" {
+		return "Tool result:\n" + content
+	}
+	return fmt.Sprintf("Result of the %s tool:\n%s", name, content)
+}
+
+// promptedCall is a tool call in the prompted reply format.
+type promptedCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// toolCallsJSON renders calls in the prompted reply format, so the model
+// sees its earlier calls the way it was asked to write them.
+func toolCallsJSON(calls []providers.ToolCall) string {
+	reply := struct {
+		ToolCalls []promptedCall `json:"tool_calls"`
+	}{}
+	for _, call := range calls {
+		reply.ToolCalls = append(reply.ToolCalls, promptedCall{Name: call.Name, Arguments: call.Arguments})
+	}
+	data, _ := json.Marshal(reply)
+	return string(data)
+}
+
+// parseToolCalls reads a prompted tool call reply. Code fences around the
+// JSON are tolerated; anything else is treated as a plain answer.
+func parseToolCalls(content string) ([]providers.ToolCall, bool) {
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+	if !strings.HasPrefix(content, "{") {
+		return nil, false
+	}
+	var reply struct {
+		ToolCalls []promptedCall `json:"tool_calls"`
+	}
+	if err := json.Unmarshal([]byte(content), &reply); err != nil || len(reply.ToolCalls) == 0 {
+		return nil, false
+	}
+	calls := make([]providers.ToolCall, 0, len(reply.ToolCalls))
+	for _, call := range reply.ToolCalls {
+		if call.Name == "" {
+			continue
+		}
+		args := call.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		calls = append(calls, providers.ToolCall{ID: newToolCallID(), Name: call.Name, Arguments: args})
+	}
+	return calls, len(calls) > 0
+}
+
+// mayBeToolCall reports whether the start of a streamed reply could be a
+// prompted tool call, which must be buffered until the reply is complete.
+func mayBeToolCall(prefix string) bool {
+	prefix = strings.TrimSpace(prefix)
+	return prefix == "" || strings.HasPrefix(prefix, "{") || strings.HasPrefix("```", prefix) || strings.HasPrefix(prefix, "```")
+}
@@ -0,0 +1,392 @@
+// Package ollama implements the Provider interface for Ollama's chat API, so
+// agents can run fully offline on local models.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultBaseURL is the address of a local Ollama server.
+const DefaultBaseURL = "http://localhost:11434"
+
+// Provider implements providers.Provider for Ollama.
+type Provider struct {
+	baseURL       string
+	promptedTools bool
+	httpClient    *http.Client
+	logger        *slog.Logger
+}
+
+// New creates a new Ollama provider for the server at baseURL
+// (DefaultBaseURL when empty).
+func New(baseURL string, logger *slog.Logger) *Provider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Provider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: providers.DefaultHTTPClient(),
+		logger:     logger,
+	}
+}
+
+// WithHTTPClient sets the client requests are sent with, e.g. one from
+// providers.NewHTTPClient with a larger pool. Providers share
+// providers.DefaultHTTPClient by default.
+func (p *Provider) WithHTTPClient(client *http.Client) *Provider {
+	if client != nil {
+		p.httpClient = client
+	}
+	return p
+}
+
+// WithPromptedTools describes tools in the system prompt and parses tool
+// calls from the model's JSON reply, for models without native function
+// calling.
+func (p *Provider) WithPromptedTools() *Provider {
+	p.promptedTools = true
+	return p
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "ollama"
+}
+
+// Complete generates a non-streaming completion.
+func (p *Provider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	apiReq := p.toAPIRequest(req)
+	apiReq.Stream = false
+
+	resp, err := p.send(ctx, apiReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var apiResp chatResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return p.fromAPIResponse(&apiResp, p.prompted(req)), nil
+}
+
+// Stream generates a streaming completion.
+func (p *Provider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	apiReq := p.toAPIRequest(req)
+	apiReq.Stream = true
+
+	resp, err := p.send(ctx, apiReq)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamReader(resp.Body, p.logger, p.prompted(req)), nil
+}
+
+// send posts apiReq and returns the response, or the API error for a non-200 status.
+func (p *Provider) send(ctx context.Context, apiReq chatRequest) (*http.Response, error) {
+	jsonData, err := json.Marshal(apiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, parseAPIError(resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+// prompted reports whether req's tools go through the prompted tools mode.
+func (p *Provider) prompted(req providers.CompletionRequest) bool {
+	return p.promptedTools && len(req.Tools) > 0 && req.ToolChoice != "none"
+}
+
+// toAPIRequest converts a provider-agnostic request to the chat API format.
+func (p *Provider) toAPIRequest(req providers.CompletionRequest) chatRequest {
+	prompted := p.prompted(req)
+	system, messages := providers.FoldDeveloperMessages(req.SystemPrompt, req.Messages)
+	if prompted {
+		system = joinNonEmpty(system, toolsPrompt(req.Tools, req.ToolChoice))
+	}
+
+	apiReq := chatRequest{Model: req.Model}
+	if system != "" {
+		apiReq.Messages = append(apiReq.Messages, message{Role: "system", Content: system})
+	}
+	apiReq.Messages = append(apiReq.Messages, p.toAPIMessages(messages, prompted)...)
+
+	if len(req.Tools) > 0 && !prompted && req.ToolChoice != "none" {
+		apiReq.Tools = make([]tool, len(req.Tools))
+		for i, t := range req.Tools {
+			schema := t.Parameters
+			if schema == nil {
+				schema = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			apiReq.Tools[i] = tool{Type: "function", Function: toolFunction{Name: t.Name, Description: t.Description, Parameters: schema}}
+		}
+	}
+	if req.TextFormat == "json_object" || req.TextFormat == "json_schema" {
+		apiReq.Format = "json"
+	}
+	if req.OutputSchema != nil {
+		apiReq.Format = req.OutputSchema.Schema
+	}
+
+	var opts options
+	if req.Temperature != 0 || req.Deterministic {
+		temperature := req.Temperature
+		opts.Temperature = &temperature
+	}
+	if req.TopP != 0 {
+		topP := req.TopP
+		opts.TopP = &topP
+	}
+	opts.Seed = req.Seed
+	opts.NumPredict = req.MaxTokens
+	if opts != (options{}) {
+		apiReq.Options = &opts
+	}
+	return apiReq
+}
+
+// toAPIMessages converts messages to the chat API format. Ollama has no tool
+// call IDs, so tool results carry the name of the tool that produced them.
+// In prompted mode, tool calls and results become plain assistant and user
+// messages.
+func (p *Provider) toAPIMessages(messages []providers.Message, prompted bool) []message {
+	toolNames := make(map[string]string)
+	out := make([]message, 0, len(messages))
+	for _, msg := range messages {
+		switch {
+		case msg.ToolCallID != "" || msg.Role == providers.RoleTool:
+			name := toolNames[msg.ToolCallID]
+			if prompted {
+				out = append(out, message{Role: "user", Content: toolResultPrompt(name, msg.Content)})
+			} else {
+				out = append(out, message{Role: "tool", Content: msg.Content, ToolName: name})
+			}
+		case msg.Role == providers.RoleAssistant:
+			for _, call := range msg.ToolCalls {
+				toolNames[call.ID] = call.Name
+			}
+			if prompted && len(msg.ToolCalls) > 0 {
+				out = append(out, message{Role: "assistant", Content: joinNonEmpty(msg.Content, toolCallsJSON(msg.ToolCalls))})
+				continue
+			}
+			apiMsg := message{Role: "assistant", Content: msg.Content}
+			for _, call := range msg.ToolCalls {
+				args := call.Arguments
+				if args == nil {
+					args = map[string]any{}
+				}
+				apiMsg.ToolCalls = append(apiMsg.ToolCalls, toolCall{Function: toolCallFunction{Name: call.Name, Arguments: args}})
+			}
+			out = append(out, apiMsg)
+		default:
+			role := string(msg.Role)
+			if role == "" {
+				role = "user"
+			}
+			apiMsg := message{Role: role, Content: msg.Content + p.inlineFiles(msg.Files)}
+			for _, image := range msg.Images {
+				data, ok := imageData(image.URL)
+				if !ok {
+					// Ollama only accepts inline image data.
+					p.logger.Warn("skipping image URL; ollama needs base64 data URLs", "url", image.URL)
+					continue
+				}
+				apiMsg.Images = append(apiMsg.Images, data)
+			}
+			out = append(out, apiMsg)
+		}
+	}
+	return out
+}
+
+// imageData returns the base64 payload of a data URL.
+func imageData(url string) (string, bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return "", false
+	}
+	_, data, ok := strings.Cut(rest, ";base64,")
+	return data, ok
+}
+
+// fromAPIResponse converts a chat API response to a provider-agnostic response.
+func (p *Provider) fromAPIResponse(resp *chatResponse, prompted bool) *providers.CompletionResponse {
+	domainResp := &providers.CompletionResponse{
+		Model:            resp.Model,
+		Content:          resp.Message.Content,
+		ReasoningSummary: resp.Message.Thinking,
+		Created:          time.Now(),
+		FinishReason:     toFinishReason(resp.DoneReason),
+		Usage:            resp.tokenUsage(),
+	}
+	for _, call := range resp.Message.ToolCalls {
+		domainResp.ToolCalls = append(domainResp.ToolCalls, providers.ToolCall{
+			ID:        newToolCallID(),
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	if prompted {
+		if calls, ok := parseToolCalls(resp.Message.Content); ok {
+			domainResp.Content, domainResp.ToolCalls = "", calls
+		}
+	}
+	if len(domainResp.ToolCalls) > 0 {
+		domainResp.FinishReason = providers.FinishReasonToolCalls
+	}
+	return domainResp
+}
+
+// inlineFiles appends text files to the message, since Ollama has no
+// document inputs. Other files are skipped.
+func (p *Provider) inlineFiles(files []providers.File) string {
+	var b strings.Builder
+	for _, file := range files {
+		if len(file.Data) == 0 || !providers.IsTextMediaType(file.MediaType) {
+			p.logger.Warn("skipping file; ollama only reads inline text files", "file", file.Name, "media_type", file.MediaType)
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n<file name=%q>\n%s\n</file>", file.Name, file.Data)
+	}
+	return b.String()
+}
+
+func toFinishReason(doneReason string) providers.FinishReason {
+	switch doneReason {
+	case "length":
+		return providers.FinishReasonLength
+	case "":
+		return ""
+	default:
+		return providers.FinishReasonStop
+	}
+}
+
+// newToolCallID returns a random tool call ID; Ollama does not assign any.
+func newToolCallID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
+
+func joinNonEmpty(parts ...string) string {
+	var kept []string
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// Ollama API types (internal to this package)
+
+type chatRequest struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+	Tools    []tool    `json:"tools,omitempty"`
+	Format   any       `json:"format,omitempty"` // "json" or a JSON schema
+	Options  *options  `json:"options,omitempty"`
+	Stream   bool      `json:"stream"`
+}
+
+type message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Thinking  string     `json:"thinking,omitempty"`
+	Images    []string   `json:"images,omitempty"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	ToolName  string     `json:"tool_name,omitempty"`
+}
+
+type toolCall struct {
+	Function toolCallFunction `json:"function"`
+}
+
+type toolCallFunction struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+type tool struct {
+	Type     string       `json:"type"`
+	Function toolFunction `json:"function"`
+}
+
+type toolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters"`
+}
+
+type options struct {
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+	Seed        *int64   `json:"seed,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+}
+
+type chatResponse struct {
+	Model           string  `json:"model"`
+	Message         message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
+}
+
+func (r *chatResponse) tokenUsage() providers.TokenUsage {
+	return providers.TokenUsage{
+		PromptTokens:     r.PromptEvalCount,
+		CompletionTokens: r.EvalCount,
+		TotalTokens:      r.PromptEvalCount + r.EvalCount,
+	}
+}
+
+func parseAPIError(statusCode int, body []byte) error {
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == "" {
+		return fmt.Errorf("API error (status %d): %s", statusCode, string(body))
+	}
+	return fmt.Errorf("API error (status %d): %s", statusCode, errResp.Error)
+}
@@ -0,0 +1,225 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+func TestToAPIRequest(t *testing.T) {
+	p := New("", nil)
+	seed := int64(7)
+	req := p.toAPIRequest(providers.CompletionRequest{
+		Model:        "llama3.1",
+		SystemPrompt: "Be brief.",
+		Messages: []providers.Message{
+			{Role: providers.RoleDeveloper, Content: "Refunds need approval."},
+			{Role: providers.RoleUser, Content: "refund order 7", Images: []providers.Image{
+				{URL: "data:image/png;base64,AAAA"},
+				{URL: "https://example.com/receipt.png"},
+			}},
+			{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "call_1", Name: "lookup", Arguments: map[string]any{"id": "7"}}}},
+			{Role: providers.RoleTool, ToolCallID: "call_1", Content: `{"status":"shipped"}`},
+		},
+		Tools:         []providers.ToolDefinition{{Name: "lookup", Description: "Look up an order"}},
+		Deterministic: true,
+		Seed:          &seed,
+		MaxTokens:     256,
+		TextFormat:    "json_object",
+	})
+
+	if p.baseURL != DefaultBaseURL {
+		t.Errorf("baseURL = %q", p.baseURL)
+	}
+	if len(req.Messages) != 4 || req.Messages[0].Role != "system" || req.Messages[0].Content != "Be brief.\n\nRefunds need approval." {
+		t.Fatalf("messages = %+v", req.Messages)
+	}
+	if images := req.Messages[1].Images; len(images) != 1 || images[0] != "AAAA" {
+		t.Errorf("images = %v, want only the inline image", images)
+	}
+	if calls := req.Messages[2].ToolCalls; len(calls) != 1 || calls[0].Function.Name != "lookup" {
+		t.Errorf("assistant tool calls = %+v", calls)
+	}
+	if result := req.Messages[3]; result.Role != "tool" || result.ToolName != "lookup" {
+		t.Errorf("tool result = %+v", result)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Function.Parameters["type"] != "object" {
+		t.Errorf("tools = %+v", req.Tools)
+	}
+	if req.Format != "json" {
+		t.Errorf("format = %q", req.Format)
+	}
+	if req.Options == nil || req.Options.Temperature == nil || *req.Options.Temperature != 0 || *req.Options.Seed != 7 || req.Options.NumPredict != 256 {
+		t.Errorf("options = %+v", req.Options)
+	}
+}
+
+func TestToAPIRequest_PromptedTools(t *testing.T) {
+	p := New("http://gpu-box:11434/", nil).WithPromptedTools()
+	req := p.toAPIRequest(providers.CompletionRequest{
+		Model: "phi3",
+		Messages: []providers.Message{
+			{Role: providers.RoleUser, Content: "order 7?"},
+			{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "call_1", Name: "lookup", Arguments: map[string]any{"id": "7"}}}},
+			{Role: providers.RoleTool, ToolCallID: "call_1", Content: "shipped"},
+		},
+		Tools:      []providers.ToolDefinition{{Name: "lookup", Description: "Look up an order"}},
+		ToolChoice: "required",
+	})
+
+	if p.baseURL != "http://gpu-box:11434" {
+		t.Errorf("baseURL = %q", p.baseURL)
+	}
+	if len(req.Tools) != 0 {
+		t.Errorf("tools sent natively in prompted mode: %+v", req.Tools)
+	}
+	system := req.Messages[0].Content
+	if !strings.Contains(system, "- lookup: Look up an order") || !strings.Contains(system, "You must call at least one tool.") {
+		t.Errorf("system prompt = %q", system)
+	}
+	if call := req.Messages[2]; call.Role != "assistant" || call.Content != `{"tool_calls":[{"name":"lookup","arguments":{"id":"7"}}]}` {
+		t.Errorf("assistant message = %+v", call)
+	}
+	if result := req.Messages[3]; result.Role != "user" || result.Content != "Result of the lookup tool:\nshipped" {
+		t.Errorf("tool result = %+v", result)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		var body chatRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "llama3.1" || body.Stream {
+			t.Errorf("body = %+v", body)
+		}
+		_, _ = w.Write([]byte(`{
+			"model": "llama3.1", "done": true, "done_reason": "stop",
+			"message": {"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "lookup", "arguments": {"id": "7"}}}]},
+			"prompt_eval_count": 40, "eval_count": 12
+		}`))
+	}))
+	defer server.Close()
+
+	resp, err := New(server.URL, nil).Complete(context.Background(), providers.CompletionRequest{
+		Model:    "llama3.1",
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "order 7?"}},
+		Tools:    []providers.ToolDefinition{{Name: "lookup"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].ID == "" || resp.ToolCalls[0].Arguments["id"] != "7" {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.FinishReason != providers.FinishReasonToolCalls || resp.Usage.TotalTokens != 52 {
+		t.Errorf("finish reason = %q, usage = %+v", resp.FinishReason, resp.Usage)
+	}
+}
+
+func TestComplete_PromptedToolCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"model": "phi3", "done": true, "done_reason": "stop",
+			"message": {"role": "assistant", "content": "` + "```json\\n" + `{\"tool_calls\": [{\"name\": \"lookup\", \"arguments\": {\"id\": \"7\"}}]}` + "\\n```" + `"}}`))
+	}))
+	defer server.Close()
+
+	resp, err := New(server.URL, nil).WithPromptedTools().Complete(context.Background(), providers.CompletionRequest{
+		Model: "phi3",
+		Tools: []providers.ToolDefinition{{Name: "lookup"}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Content != "" || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "lookup" || resp.FinishReason != providers.FinishReasonToolCalls {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestComplete_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "model \"llama9\" not found, try pulling it first"}`))
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, nil).Complete(context.Background(), providers.CompletionRequest{Model: "llama9"})
+	if err == nil || !strings.Contains(err.Error(), "try pulling it first") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func readStream(t *testing.T, stream *streamReader) (content string, calls []*providers.StreamChunk, complete *providers.StreamChunk) {
+	t.Helper()
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		content += chunk.Content
+		if chunk.ToolCallID != "" {
+			calls = append(calls, chunk)
+		}
+		if chunk.IsComplete {
+			complete = chunk
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	body := strings.Join([]string{
+		`{"message": {"role": "assistant", "content": "", "thinking": "Need the order."}, "done": false}`,
+		`{"message": {"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "lookup", "arguments": {"id": "7"}}}]}, "done": false}`,
+		`{"message": {"role": "assistant", "content": ""}, "done": true, "done_reason": "stop", "prompt_eval_count": 30, "eval_count": 9}`,
+	}, "\n") + "\n"
+
+	content, calls, complete := readStream(t, newStreamReader(io.NopCloser(strings.NewReader(body)), nil, false))
+	if content != "" || len(calls) != 1 || calls[0].ToolName != "lookup" || calls[0].ToolArgs != `{"id":"7"}` {
+		t.Errorf("content = %q, calls = %+v", content, calls)
+	}
+	if complete == nil || complete.FinishReason != providers.FinishReasonToolCalls || complete.Usage.TotalTokens != 39 {
+		t.Errorf("completion chunk = %+v", complete)
+	}
+}
+
+func TestStream_Prompted(t *testing.T) {
+	lines := func(parts ...string) string {
+		var b strings.Builder
+		for _, part := range parts {
+			data, _ := json.Marshal(chatResponse{Message: message{Role: "assistant", Content: part}})
+			b.Write(data)
+			b.WriteByte('\n')
+		}
+		b.WriteString(`{"message": {"role": "assistant", "content": ""}, "done": true, "done_reason": "stop"}` + "\n")
+		return b.String()
+	}
+
+	// A JSON tool call reply is buffered and turned into tool calls.
+	stream := newStreamReader(io.NopCloser(strings.NewReader(lines(` {"tool_calls": [{"name": "look`, `up", "arguments": {"id": "7"}}]}`))), nil, true)
+	content, calls, complete := readStream(t, stream)
+	if content != "" || len(calls) != 1 || calls[0].ToolName != "lookup" || complete.FinishReason != providers.FinishReasonToolCalls {
+		t.Errorf("tool call reply: content = %q, calls = %+v, complete = %+v", content, calls, complete)
+	}
+
+	// A plain answer streams as soon as it can't be a tool call.
+	stream = newStreamReader(io.NopCloser(strings.NewReader(lines("Order 7 ", "has shipped."))), nil, true)
+	first, err := stream.Next()
+	if err != nil || first.Content != "Order 7 " {
+		t.Errorf("first chunk = %+v, %v", first, err)
+	}
+	content, calls, complete = readStream(t, stream)
+	if content != "has shipped." || len(calls) != 0 || complete.FinishReason != providers.FinishReasonStop {
+		t.Errorf("plain reply: content = %q, calls = %+v, complete = %+v", content, calls, complete)
+	}
+}
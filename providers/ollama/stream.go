@@ -0,0 +1,142 @@
+package ollama
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// streamReader turns the chat API's newline-delimited JSON into stream
+// chunks. Ollama sends each tool call whole, so tool calls produce a single
+// chunk with the complete arguments. In prompted tools mode, a reply that
+// starts like a JSON object is buffered until it is complete, and becomes
+// tool calls when it parses as one.
+type streamReader struct {
+	reader    io.ReadCloser
+	lines     *bufio.Reader
+	logger    *slog.Logger
+	pending   []*providers.StreamChunk
+	prompted  bool
+	buffering bool
+	buffer    strings.Builder
+	toolCalls bool
+	done      bool
+}
+
+func newStreamReader(reader io.ReadCloser, logger *slog.Logger, prompted bool) *streamReader {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &streamReader{
+		reader:    reader,
+		lines:     bufio.NewReader(reader),
+		logger:    logger,
+		prompted:  prompted,
+		buffering: prompted,
+	}
+}
+
+// Next returns the next chunk, or io.EOF after the completion chunk.
+func (s *streamReader) Next() (*providers.StreamChunk, error) {
+	for {
+		if len(s.pending) > 0 {
+			chunk := s.pending[0]
+			s.pending = s.pending[1:]
+			return chunk, nil
+		}
+		if s.done {
+			return nil, io.EOF
+		}
+		line, err := s.lines.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			if herr := s.handle(line); herr != nil {
+				return nil, herr
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close closes the underlying response body.
+func (s *streamReader) Close() error {
+	return s.reader.Close()
+}
+
+func (s *streamReader) handle(line []byte) error {
+	var resp chatResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		s.logger.Error("failed to parse stream line", "error", err)
+		return nil
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("stream error: %s", resp.Error)
+	}
+
+	if resp.Message.Thinking != "" {
+		s.pending = append(s.pending, &providers.StreamChunk{ReasoningSummary: resp.Message.Thinking})
+	}
+	for _, call := range resp.Message.ToolCalls {
+		s.addToolCall(newToolCallID(), call.Function.Name, call.Function.Arguments)
+	}
+	if resp.Message.Content != "" {
+		if s.buffering {
+			s.buffer.WriteString(resp.Message.Content)
+			if !mayBeToolCall(s.buffer.String()) {
+				s.flush()
+			}
+		} else {
+			s.pending = append(s.pending, &providers.StreamChunk{Content: resp.Message.Content})
+		}
+	}
+
+	if !resp.Done {
+		return nil
+	}
+	if s.buffering {
+		if calls, ok := parseToolCalls(s.buffer.String()); ok {
+			for _, call := range calls {
+				s.addToolCall(call.ID, call.Name, call.Arguments)
+			}
+		} else {
+			s.flush()
+		}
+	}
+	finishReason := toFinishReason(resp.DoneReason)
+	if s.toolCalls {
+		finishReason = providers.FinishReasonToolCalls
+	}
+	usage := resp.tokenUsage()
+	s.pending = append(s.pending, &providers.StreamChunk{
+		IsComplete:   true,
+		FinishReason: finishReason,
+		Usage:        &usage,
+	})
+	s.done = true
+	return nil
+}
+
+// flush forwards buffered content and stops buffering.
+func (s *streamReader) flush() {
+	if s.buffer.Len() > 0 {
+		s.pending = append(s.pending, &providers.StreamChunk{Content: s.buffer.String()})
+		s.buffer.Reset()
+	}
+	s.buffering = false
+}
+
+func (s *streamReader) addToolCall(id, name string, args map[string]any) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	data, _ := json.Marshal(args)
+	s.pending = append(s.pending, &providers.StreamChunk{ToolCallID: id, ToolName: name, ToolArgs: string(data)})
+	s.toolCalls = true
+}
@@ -0,0 +1,99 @@
+package onnx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Model is a downloadable sentence-transformer model.
+type Model struct {
+	// Name is the model's directory in the cache.
+	Name string
+	// Files maps each file name in the model directory, model.onnx and
+	// vocab.txt, to its download URL.
+	Files map[string]string
+}
+
+// MiniLM is sentence-transformers/all-MiniLM-L6-v2: 384 dimensions, about
+// 90 MB, fast on CPU.
+var MiniLM = Model{
+	Name: "all-MiniLM-L6-v2",
+	Files: map[string]string{
+		"model.onnx": "https://huggingface.co/sentence-transformers/all-MiniLM-L6-v2/resolve/main/onnx/model.onnx",
+		"vocab.txt":  "https://huggingface.co/sentence-transformers/all-MiniLM-L6-v2/resolve/main/vocab.txt",
+	},
+}
+
+// DefaultCacheDir returns agentkit/onnx in the user's cache directory, e.g.
+// ~/.cache/agentkit/onnx on Linux or %LocalAppData%\agentkit\onnx on
+// Windows.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("onnx: locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "agentkit", "onnx"), nil
+}
+
+// Download fetches the model's files into cacheDir/model.Name, skipping
+// files already there, and returns that directory. Files are written to a
+// temporary name and renamed when complete, so an interrupted download is
+// retried on the next call.
+func Download(ctx context.Context, model Model, cacheDir string) (string, error) {
+	if model.Name == "" {
+		return "", fmt.Errorf("onnx: model has no name")
+	}
+	dir := filepath.Join(cacheDir, model.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("onnx: create model directory: %w", err)
+	}
+	names := make([]string, 0, len(model.Files))
+	for name := range model.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := downloadFile(ctx, model.Files[name], path); err != nil {
+			return "", fmt.Errorf("onnx: download %s: %w", name, err)
+		}
+	}
+	return dir, nil
+}
+
+func downloadFile(ctx context.Context, url, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
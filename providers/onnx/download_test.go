@@ -0,0 +1,57 @@
+package onnx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownload_CachesFiles(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("contents of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	model := Model{Name: "tiny", Files: map[string]string{
+		"model.onnx": server.URL + "/model.onnx",
+		"vocab.txt":  server.URL + "/vocab.txt",
+	}}
+	cacheDir := t.TempDir()
+
+	dir, err := Download(context.Background(), model, cacheDir)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if dir != filepath.Join(cacheDir, "tiny") {
+		t.Errorf("dir = %q", dir)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "vocab.txt"))
+	if err != nil || string(data) != "contents of /vocab.txt" {
+		t.Errorf("vocab.txt = %q, %v", data, err)
+	}
+
+	if _, err := Download(context.Background(), model, cacheDir); err != nil {
+		t.Fatalf("second Download() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2 (cached files are not fetched again)", requests)
+	}
+
+	model.Files["extra.bin"] = server.URL + "/missing"
+	if _, err := Download(context.Background(), model, cacheDir); err == nil {
+		t.Error("Download() error = nil for a missing file")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("model directory has %d entries after a failed download, want 2", len(entries))
+	}
+}
@@ -0,0 +1,221 @@
+// Package onnx computes embeddings locally with a sentence-transformer
+// model in ONNX format, so retrieval and semantic memory work offline
+// without an embeddings API or a model server.
+//
+// The tokenizer, pooling and model download helpers are plain Go. Running
+// the model needs ONNX Runtime: build with the onnx tag and cgo, with the
+// ONNX Runtime headers and shared library installed (on Windows, put
+// onnxruntime.dll next to the executable):
+//
+//	go build -tags onnx ./...
+//
+// Without the tag, NewSession returns ErrNoRuntime; NewEmbedder still
+// accepts any Session, e.g. one backed by another ONNX Runtime binding.
+package onnx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultBatchSize is the number of texts run through the model at once.
+const DefaultBatchSize = 32
+
+// ErrNoRuntime is returned by NewSession in builds without the onnx tag.
+var ErrNoRuntime = errors.New("onnx: ONNX Runtime not available; build with -tags onnx and cgo")
+
+// Batch is a padded batch of tokenized texts. The slices are row-major
+// [Size][Length].
+type Batch struct {
+	InputIDs      []int64
+	AttentionMask []int64
+	TokenTypeIDs  []int64
+	Size          int
+	Length        int
+}
+
+// Output is a model's float32 output: token embeddings shaped
+// [Size, Length, Dimensions], which are mean-pooled, or sentence embeddings
+// shaped [Size, Dimensions].
+type Output struct {
+	Data  []float32
+	Shape []int64
+}
+
+// Session runs an ONNX sentence-transformer model. Run must be safe for
+// concurrent use.
+type Session interface {
+	Run(ctx context.Context, batch Batch) (Output, error)
+	Close() error
+}
+
+// Embedder implements providers.Embedder with a local model. Vectors are
+// mean-pooled over the tokens and normalized to unit length, so cosine
+// similarity is a dot product.
+type Embedder struct {
+	session   Session
+	tokenizer *Tokenizer
+	// BatchSize caps texts per model run (default DefaultBatchSize).
+	BatchSize int
+	// Concurrency is how many batches run at once (default 1).
+	Concurrency int
+}
+
+var _ providers.Embedder = (*Embedder)(nil)
+
+// NewEmbedder creates an embedder from a model session and its tokenizer.
+func NewEmbedder(session Session, tokenizer *Tokenizer) *Embedder {
+	return &Embedder{session: session, tokenizer: tokenizer}
+}
+
+// Open loads model.onnx and vocab.txt from dir, as laid out by Download.
+func Open(dir string) (*Embedder, error) {
+	tokenizer, err := LoadTokenizer(filepath.Join(dir, "vocab.txt"))
+	if err != nil {
+		return nil, err
+	}
+	session, err := NewSession(filepath.Join(dir, "model.onnx"))
+	if err != nil {
+		return nil, err
+	}
+	return NewEmbedder(session, tokenizer), nil
+}
+
+// Load downloads model to DefaultCacheDir unless it is already cached and
+// opens it.
+func Load(ctx context.Context, model Model) (*Embedder, error) {
+	cacheDir, err := DefaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := Download(ctx, model, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	return Open(dir)
+}
+
+// Close releases the model session.
+func (e *Embedder) Close() error {
+	return e.session.Close()
+}
+
+// Embed implements providers.Embedder.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	batchSize := e.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return providers.BatchEmbedder{
+		Embedder:    providers.EmbedderFunc(e.embed),
+		BatchSize:   batchSize,
+		Concurrency: e.Concurrency,
+	}.Embed(ctx, texts)
+}
+
+// embed runs one batch through the model.
+func (e *Embedder) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	batch := e.tokenize(texts)
+	output, err := e.session.Run(ctx, batch)
+	if err != nil {
+		return nil, fmt.Errorf("onnx: run model: %w", err)
+	}
+	vectors, err := pool(output, batch)
+	if err != nil {
+		return nil, err
+	}
+	for _, vector := range vectors {
+		normalize(vector)
+	}
+	return vectors, nil
+}
+
+// tokenize encodes texts and pads them to the longest one.
+func (e *Embedder) tokenize(texts []string) Batch {
+	encoded := make([][]int64, len(texts))
+	length := 0
+	for i, text := range texts {
+		encoded[i] = e.tokenizer.Encode(text)
+		length = max(length, len(encoded[i]))
+	}
+	batch := Batch{
+		InputIDs:      make([]int64, len(texts)*length),
+		AttentionMask: make([]int64, len(texts)*length),
+		TokenTypeIDs:  make([]int64, len(texts)*length),
+		Size:          len(texts),
+		Length:        length,
+	}
+	for i, ids := range encoded {
+		row := i * length
+		for j := range length {
+			if j < len(ids) {
+				batch.InputIDs[row+j] = ids[j]
+				batch.AttentionMask[row+j] = 1
+			} else {
+				batch.InputIDs[row+j] = e.tokenizer.pad
+			}
+		}
+	}
+	return batch
+}
+
+// pool turns the model output into one vector per text, averaging token
+// embeddings over the attention mask.
+func pool(output Output, batch Batch) ([][]float32, error) {
+	shape := output.Shape
+	switch {
+	case len(shape) == 2 && shape[0] == int64(batch.Size) && int64(len(output.Data)) == shape[0]*shape[1]:
+		dims := int(shape[1])
+		vectors := make([][]float32, batch.Size)
+		for i := range vectors {
+			vectors[i] = append([]float32(nil), output.Data[i*dims:(i+1)*dims]...)
+		}
+		return vectors, nil
+	case len(shape) == 3 && shape[0] == int64(batch.Size) && shape[1] == int64(batch.Length) && int64(len(output.Data)) == shape[0]*shape[1]*shape[2]:
+		dims := int(shape[2])
+		vectors := make([][]float32, batch.Size)
+		for i := range vectors {
+			vector := make([]float32, dims)
+			tokens := 0
+			for j := range batch.Length {
+				if batch.AttentionMask[i*batch.Length+j] == 0 {
+					continue
+				}
+				tokens++
+				offset := (i*batch.Length + j) * dims
+				for k := range dims {
+					vector[k] += output.Data[offset+k]
+				}
+			}
+			for k := range vector {
+				vector[k] /= float32(max(tokens, 1))
+			}
+			vectors[i] = vector
+		}
+		return vectors, nil
+	}
+	return nil, fmt.Errorf("onnx: unexpected output shape %v for %d texts of %d tokens", shape, batch.Size, batch.Length)
+}
+
+// normalize scales vector to unit length.
+func normalize(vector []float32) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	scale := float32(1 / math.Sqrt(sum))
+	for i := range vector {
+		vector[i] *= scale
+	}
+}
@@ -0,0 +1,112 @@
+package onnx
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testVocab = "[PAD]\n[UNK]\n[CLS]\n[SEP]\nhello\nworld\nplay\n##ing\n!\n,\n"
+
+func testTokenizer(t *testing.T) *Tokenizer {
+	t.Helper()
+	tokenizer, err := NewTokenizer(strings.NewReader(testVocab))
+	if err != nil {
+		t.Fatalf("NewTokenizer() error = %v", err)
+	}
+	return tokenizer
+}
+
+func TestTokenizer_Encode(t *testing.T) {
+	tokenizer := testTokenizer(t)
+
+	got := tokenizer.Encode("Hello,  WORLD! Playing zebras")
+	want := []int64{2, 4, 9, 5, 8, 6, 7, 1, 3}
+	if !slices.Equal(got, want) {
+		t.Errorf("Encode() = %v, want %v", got, want)
+	}
+
+	tokenizer.MaxLength = 4
+	if got := tokenizer.Encode("hello world hello"); !slices.Equal(got, []int64{2, 4, 5, 3}) {
+		t.Errorf("truncated Encode() = %v", got)
+	}
+}
+
+func TestNewTokenizer_RequiresSpecialTokens(t *testing.T) {
+	if _, err := NewTokenizer(strings.NewReader("hello\nworld\n")); err == nil {
+		t.Error("NewTokenizer() error = nil, want missing special tokens")
+	}
+}
+
+// fakeSession returns each token's ID as a 2-dimensional token embedding,
+// recording the batches it ran.
+type fakeSession struct {
+	batches []Batch
+	pooled  bool
+}
+
+func (s *fakeSession) Run(_ context.Context, batch Batch) (Output, error) {
+	s.batches = append(s.batches, batch)
+	if s.pooled {
+		data := make([]float32, 0, batch.Size*2)
+		for range batch.Size {
+			data = append(data, 3, 4)
+		}
+		return Output{Data: data, Shape: []int64{int64(batch.Size), 2}}, nil
+	}
+	data := make([]float32, 0, len(batch.InputIDs)*2)
+	for _, id := range batch.InputIDs {
+		data = append(data, float32(id), 1)
+	}
+	return Output{Data: data, Shape: []int64{int64(batch.Size), int64(batch.Length), 2}}, nil
+}
+
+func (s *fakeSession) Close() error { return nil }
+
+func TestEmbedder_MeanPoolsOverAttentionMask(t *testing.T) {
+	session := &fakeSession{}
+	embedder := NewEmbedder(session, testTokenizer(t))
+
+	vectors, err := embedder.Embed(context.Background(), []string{"hello", "hello world"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	batch := session.batches[0]
+	if batch.Size != 2 || batch.Length != 4 || !slices.Equal(batch.AttentionMask, []int64{1, 1, 1, 0, 1, 1, 1, 1}) {
+		t.Fatalf("batch = %+v", batch)
+	}
+	// "hello": tokens 2, 4, 3 average to (3, 1); padding is ignored.
+	assertUnit(t, vectors[0], []float64{3, 1})
+	// "hello world": tokens 2, 4, 5, 3 average to (3.5, 1).
+	assertUnit(t, vectors[1], []float64{3.5, 1})
+}
+
+func TestEmbedder_PooledOutputAndBatching(t *testing.T) {
+	session := &fakeSession{pooled: true}
+	embedder := NewEmbedder(session, testTokenizer(t))
+	embedder.BatchSize = 2
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(session.batches) != 2 || len(vectors) != 3 {
+		t.Fatalf("%d batches, %d vectors", len(session.batches), len(vectors))
+	}
+	assertUnit(t, vectors[2], []float64{3, 4})
+}
+
+// assertUnit checks vector is direction normalized to unit length.
+func assertUnit(t *testing.T, vector []float32, direction []float64) {
+	t.Helper()
+	norm := math.Hypot(direction[0], direction[1])
+	for i := range direction {
+		if math.Abs(float64(vector[i])-direction[i]/norm) > 1e-6 {
+			t.Errorf("vector = %v, want %v normalized", vector, direction)
+			return
+		}
+	}
+}
@@ -0,0 +1,292 @@
+//go:build onnx && cgo
+
+package onnx
+
+/*
+#cgo LDFLAGS: -lonnxruntime
+#include <stdlib.h>
+#include <string.h>
+#include <onnxruntime_c_api.h>
+
+static const OrtApi *ort;
+
+static int ort_init(void) {
+	ort = OrtGetApiBase()->GetApi(ORT_API_VERSION);
+	return ort != NULL;
+}
+
+// ort_error converts a status to a malloc'ed message, or NULL on success.
+static char *ort_error(OrtStatus *status) {
+	if (status == NULL) {
+		return NULL;
+	}
+	char *msg = strdup(ort->GetErrorMessage(status));
+	ort->ReleaseStatus(status);
+	return msg;
+}
+
+static char *ort_create_env(OrtEnv **env) {
+	return ort_error(ort->CreateEnv(ORT_LOGGING_LEVEL_WARNING, "agentkit", env));
+}
+
+static char *ort_create_session(OrtEnv *env, const void *model, size_t len, OrtSession **session) {
+	OrtSessionOptions *opts;
+	char *err = ort_error(ort->CreateSessionOptions(&opts));
+	if (err) {
+		return err;
+	}
+	err = ort_error(ort->CreateSessionFromArray(env, model, len, opts, session));
+	ort->ReleaseSessionOptions(opts);
+	return err;
+}
+
+// ort_io_names returns the session's input or output names as one
+// malloc'ed, newline-separated string.
+static char *ort_io_names(OrtSession *session, int outputs, char **names) {
+	OrtAllocator *alloc;
+	char *err = ort_error(ort->GetAllocatorWithDefaultOptions(&alloc));
+	if (err) {
+		return err;
+	}
+	size_t count;
+	err = ort_error(outputs ? ort->SessionGetOutputCount(session, &count) : ort->SessionGetInputCount(session, &count));
+	if (err) {
+		return err;
+	}
+	size_t size = 1;
+	char *joined = calloc(1, 1);
+	for (size_t i = 0; i < count && !err; i++) {
+		char *name;
+		err = ort_error(outputs ? ort->SessionGetOutputName(session, i, alloc, &name) : ort->SessionGetInputName(session, i, alloc, &name));
+		if (err) {
+			break;
+		}
+		size += strlen(name) + 1;
+		joined = realloc(joined, size);
+		strcat(joined, name);
+		strcat(joined, "\n");
+		free(ort_error(ort->AllocatorFree(alloc, name)));
+	}
+	if (err) {
+		free(joined);
+		return err;
+	}
+	*names = joined;
+	return NULL;
+}
+
+static char *ort_run(OrtSession *session, int64_t *ids, int64_t *mask, int64_t *types,
+                     int64_t batch, int64_t length, const char *output_name, OrtValue **out) {
+	OrtMemoryInfo *mem;
+	char *err = ort_error(ort->CreateCpuMemoryInfo(OrtArenaAllocator, OrtMemTypeDefault, &mem));
+	if (err) {
+		return err;
+	}
+	const char *names[3] = {"input_ids", "attention_mask", "token_type_ids"};
+	int64_t *data[3] = {ids, mask, types};
+	OrtValue *values[3] = {NULL, NULL, NULL};
+	size_t count = types ? 3 : 2;
+	int64_t shape[2] = {batch, length};
+	for (size_t i = 0; i < count && !err; i++) {
+		err = ort_error(ort->CreateTensorWithDataAsOrtValue(mem, data[i], (size_t)(batch * length) * sizeof(int64_t),
+			shape, 2, ONNX_TENSOR_ELEMENT_DATA_TYPE_INT64, &values[i]));
+	}
+	if (!err) {
+		err = ort_error(ort->Run(session, NULL, names, (const OrtValue *const *)values, count, &output_name, 1, out));
+	}
+	for (size_t i = 0; i < count; i++) {
+		if (values[i]) {
+			ort->ReleaseValue(values[i]);
+		}
+	}
+	ort->ReleaseMemoryInfo(mem);
+	return err;
+}
+
+static char *ort_output(OrtValue *value, float **data, int64_t *dims, size_t *rank) {
+	OrtTensorTypeAndShapeInfo *info;
+	char *err = ort_error(ort->GetTensorTypeAndShape(value, &info));
+	if (err) {
+		return err;
+	}
+	err = ort_error(ort->GetDimensionsCount(info, rank));
+	if (!err && *rank > 3) {
+		err = strdup("output has more than 3 dimensions");
+	}
+	if (!err) {
+		err = ort_error(ort->GetDimensions(info, dims, *rank));
+	}
+	ort->ReleaseTensorTypeAndShapeInfo(info);
+	if (!err) {
+		err = ort_error(ort->GetTensorMutableData(value, (void **)data));
+	}
+	return err;
+}
+
+static void ort_release_value(OrtValue *value) { ort->ReleaseValue(value); }
+static void ort_release_session(OrtSession *session) { ort->ReleaseSession(session); }
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// ONNX Runtime allows one environment per process; sessions share it.
+var (
+	envOnce sync.Once
+	env     *C.OrtEnv
+	envErr  error
+)
+
+func ortError(msg *C.char) error {
+	if msg == nil {
+		return nil
+	}
+	defer C.free(unsafe.Pointer(msg))
+	return errors.New("onnx: " + C.GoString(msg))
+}
+
+func initEnv() error {
+	envOnce.Do(func() {
+		if C.ort_init() == 0 {
+			envErr = errors.New("onnx: ONNX Runtime does not support this API version")
+			return
+		}
+		envErr = ortError(C.ort_create_env(&env))
+	})
+	return envErr
+}
+
+// runtimeSession runs a model with ONNX Runtime on the CPU.
+type runtimeSession struct {
+	mu         sync.RWMutex
+	session    *C.OrtSession
+	output     *C.char
+	tokenTypes bool
+}
+
+// NewSession loads an ONNX model for ONNX Runtime. The model must take
+// input_ids and attention_mask (and optionally token_type_ids); its
+// sentence_embedding output is used when present, otherwise its first.
+func NewSession(modelPath string) (Session, error) {
+	if err := initEnv(); err != nil {
+		return nil, err
+	}
+	model, err := os.ReadFile(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	// The model is read into C memory: cgo forbids C keeping Go pointers.
+	data := C.CBytes(model)
+	defer C.free(data)
+
+	s := &runtimeSession{}
+	if err := ortError(C.ort_create_session(env, data, C.size_t(len(model)), &s.session)); err != nil {
+		return nil, err
+	}
+	inputs, err := s.names(false)
+	if err == nil {
+		var outputs []string
+		if outputs, err = s.names(true); err == nil {
+			s.tokenTypes = slices.Contains(inputs, "token_type_ids")
+			output := outputs[0]
+			if slices.Contains(outputs, "sentence_embedding") {
+				output = "sentence_embedding"
+			}
+			s.output = C.CString(output)
+		}
+	}
+	if err != nil {
+		C.ort_release_session(s.session)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *runtimeSession) names(outputs bool) ([]string, error) {
+	var joined *C.char
+	flag := C.int(0)
+	if outputs {
+		flag = 1
+	}
+	if err := ortError(C.ort_io_names(s.session, flag, &joined)); err != nil {
+		return nil, err
+	}
+	defer C.free(unsafe.Pointer(joined))
+	names := strings.Split(strings.TrimSuffix(C.GoString(joined), "\n"), "\n")
+	if len(names) == 0 || names[0] == "" {
+		return nil, fmt.Errorf("onnx: model has no %s", map[bool]string{false: "inputs", true: "outputs"}[outputs])
+	}
+	return names, nil
+}
+
+// Run implements Session.
+func (s *runtimeSession) Run(ctx context.Context, batch Batch) (Output, error) {
+	if err := ctx.Err(); err != nil {
+		return Output{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.session == nil {
+		return Output{}, errors.New("onnx: session closed")
+	}
+
+	ids := cInt64s(batch.InputIDs)
+	defer C.free(unsafe.Pointer(ids))
+	mask := cInt64s(batch.AttentionMask)
+	defer C.free(unsafe.Pointer(mask))
+	var types *C.int64_t
+	if s.tokenTypes {
+		types = cInt64s(batch.TokenTypeIDs)
+		defer C.free(unsafe.Pointer(types))
+	}
+
+	var value *C.OrtValue
+	if err := ortError(C.ort_run(s.session, ids, mask, types, C.int64_t(batch.Size), C.int64_t(batch.Length), s.output, &value)); err != nil {
+		return Output{}, err
+	}
+	defer C.ort_release_value(value)
+
+	var data *C.float
+	var dims [3]C.int64_t
+	var rank C.size_t
+	if err := ortError(C.ort_output(value, &data, &dims[0], &rank)); err != nil {
+		return Output{}, err
+	}
+	output := Output{Shape: make([]int64, int(rank))}
+	size := 1
+	for i := range output.Shape {
+		output.Shape[i] = int64(dims[i])
+		size *= int(dims[i])
+	}
+	output.Data = slices.Clone(unsafe.Slice((*float32)(unsafe.Pointer(data)), size))
+	return output, nil
+}
+
+// Close implements Session.
+func (s *runtimeSession) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session != nil {
+		C.ort_release_session(s.session)
+		C.free(unsafe.Pointer(s.output))
+		s.session = nil
+	}
+	return nil
+}
+
+// cInt64s copies values into C memory, which ONNX Runtime tensors may point
+// to while a run is in progress.
+func cInt64s(values []int64) *C.int64_t {
+	ptr := (*C.int64_t)(C.malloc(C.size_t(len(values)) * C.size_t(unsafe.Sizeof(C.int64_t(0)))))
+	copy(unsafe.Slice((*int64)(unsafe.Pointer(ptr)), len(values)), values)
+	return ptr
+}
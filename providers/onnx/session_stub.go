@@ -0,0 +1,9 @@
+//go:build !onnx || !cgo
+
+package onnx
+
+// NewSession needs the onnx build tag and cgo; without them it returns
+// ErrNoRuntime.
+func NewSession(modelPath string) (Session, error) {
+	return nil, ErrNoRuntime
+}
@@ -0,0 +1,148 @@
+package onnx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxLength is the token limit per text, matching the 256-token
+// window sentence-transformers models such as all-MiniLM-L6-v2 are trained
+// with. Longer texts are truncated.
+const DefaultMaxLength = 256
+
+// maxWordRunes is the longest word WordPiece splits; longer words become
+// the unknown token, as in BERT.
+const maxWordRunes = 100
+
+// Tokenizer is the uncased BERT WordPiece tokenizer used by
+// sentence-transformers models. Accented characters are kept as they are,
+// so words with accents the vocabulary lacks become the unknown token.
+type Tokenizer struct {
+	vocab              map[string]int64
+	cls, sep, unk, pad int64
+	// MaxLength caps tokens per text, including [CLS] and [SEP]
+	// (default DefaultMaxLength).
+	MaxLength int
+}
+
+// LoadTokenizer reads a vocab.txt file.
+func LoadTokenizer(path string) (*Tokenizer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewTokenizer(f)
+}
+
+// NewTokenizer reads a WordPiece vocabulary with one token per line; the
+// line number is the token ID.
+func NewTokenizer(r io.Reader) (*Tokenizer, error) {
+	vocab := make(map[string]int64)
+	scanner := bufio.NewScanner(r)
+	for id := int64(0); scanner.Scan(); id++ {
+		token := strings.TrimRight(scanner.Text(), "\r")
+		if _, ok := vocab[token]; !ok {
+			vocab[token] = id
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("onnx: read vocabulary: %w", err)
+	}
+
+	t := &Tokenizer{vocab: vocab}
+	for token, id := range map[string]*int64{"[CLS]": &t.cls, "[SEP]": &t.sep, "[UNK]": &t.unk, "[PAD]": &t.pad} {
+		var ok bool
+		if *id, ok = vocab[token]; !ok {
+			return nil, fmt.Errorf("onnx: vocabulary has no %s token", token)
+		}
+	}
+	return t, nil
+}
+
+// Encode returns the token IDs of text, starting with [CLS] and ending with
+// [SEP].
+func (t *Tokenizer) Encode(text string) []int64 {
+	maxLength := t.MaxLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	ids := []int64{t.cls}
+	for _, word := range basicTokens(text) {
+		ids = t.wordPiece(ids, word)
+		if len(ids) >= maxLength-1 {
+			ids = ids[:maxLength-1]
+			break
+		}
+	}
+	return append(ids, t.sep)
+}
+
+// wordPiece appends the longest-match-first subword IDs of word.
+func (t *Tokenizer) wordPiece(ids []int64, word string) []int64 {
+	runes := []rune(word)
+	if len(runes) > maxWordRunes {
+		return append(ids, t.unk)
+	}
+	var pieces []int64
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		found := false
+		for ; end > start; end-- {
+			piece := string(runes[start:end])
+			if start > 0 {
+				piece = "##" + piece
+			}
+			if id, ok := t.vocab[piece]; ok {
+				pieces = append(pieces, id)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return append(ids, t.unk)
+		}
+		start = end
+	}
+	return append(ids, pieces...)
+}
+
+// basicTokens lowercases text, drops control characters and splits it on
+// whitespace, punctuation and CJK characters.
+func basicTokens(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r == 0 || r == unicode.ReplacementChar || (unicode.IsControl(r) && !unicode.IsSpace(r)):
+		case unicode.IsSpace(r):
+			flush()
+		case isPunctuation(r) || unicode.Is(unicode.Han, r):
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			word.WriteRune(unicode.ToLower(r))
+		}
+	}
+	flush()
+	return tokens
+}
+
+// isPunctuation treats all non-alphanumeric ASCII as punctuation, as BERT
+// does, along with Unicode punctuation.
+func isPunctuation(r rune) bool {
+	if r >= 33 && r <= 47 || r >= 58 && r <= 64 || r >= 91 && r <= 96 || r >= 123 && r <= 126 {
+		return true
+	}
+	return unicode.IsPunct(r)
+}
@@ -0,0 +1,598 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/jsonrepair"
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Chat Completions API support, for OpenAI-compatible servers that do not
+// implement the Responses API. Developer messages are folded into the system
+// message, since few compatible servers accept the developer role.
+
+// Default spoken reply settings for requests with audio output.
+const (
+	DefaultVoice       = "alloy"
+	DefaultAudioFormat = "pcm16"
+)
+
+// needsChatCompletions reports whether req uses audio, which only the Chat
+// Completions API supports.
+func needsChatCompletions(req providers.CompletionRequest) bool {
+	if req.Audio != nil {
+		return true
+	}
+	for _, msg := range req.Messages {
+		if len(msg.Audio) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// completeChat generates a non-streaming completion with the Chat Completions API.
+func (p *Provider) completeChat(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	resp, err := p.post(ctx, "/chat/completions", p.toChatRequest(req), false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var apiResp chatResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return p.fromChatResponse(&apiResp), nil
+}
+
+// streamChat generates a streaming completion with the Chat Completions API.
+func (p *Provider) streamChat(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	chatReq := p.toChatRequest(req)
+	chatReq.Stream = true
+	chatReq.StreamOptions = &chatStreamOptions{IncludeUsage: true}
+
+	resp, err := p.post(ctx, "/chat/completions", chatReq, true)
+	if err != nil {
+		return nil, err
+	}
+	return newChatStreamReader(resp.Body, p.logger), nil
+}
+
+// toChatRequest converts a provider-agnostic request to the Chat Completions format.
+func (p *Provider) toChatRequest(req providers.CompletionRequest) chatRequest {
+	system, messages := providers.FoldDeveloperMessages(req.SystemPrompt, req.Messages)
+	chatReq := chatRequest{
+		Model:           req.Model,
+		MaxTokens:       req.MaxTokens,
+		Seed:            req.Seed,
+		ReasoningEffort: string(req.ReasoningEffort),
+	}
+	if system != "" {
+		chatReq.Messages = append(chatReq.Messages, chatMessage{Role: "system", Content: system})
+	}
+	chatReq.Messages = append(chatReq.Messages, toChatMessages(messages)...)
+
+	if req.Temperature != 0 || req.Deterministic {
+		temperature := req.Temperature
+		chatReq.Temperature = &temperature
+	}
+	if req.TopP != 0 {
+		topP := req.TopP
+		chatReq.TopP = &topP
+	}
+	if req.TextFormat == "json_object" {
+		chatReq.ResponseFormat = &chatResponseFormat{Type: "json_object"}
+	}
+	if schema := req.OutputSchema; schema != nil {
+		chatReq.ResponseFormat = &chatResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &chatJSONSchema{Name: schema.Name, Schema: schema.Schema, Strict: schema.Strict},
+		}
+	}
+
+	if audio := req.Audio; audio != nil {
+		chatReq.Modalities = []string{"text", "audio"}
+		chatReq.Audio = &chatAudioOutput{Voice: audio.Voice, Format: audio.Format}
+		if chatReq.Audio.Voice == "" {
+			chatReq.Audio.Voice = DefaultVoice
+		}
+		if chatReq.Audio.Format == "" {
+			chatReq.Audio.Format = DefaultAudioFormat
+		}
+	}
+
+	if len(req.Tools) > 0 {
+		for _, t := range req.Tools {
+			chatReq.Tools = append(chatReq.Tools, chatTool{
+				Type:     "function",
+				Function: chatFunction{Name: t.Name, Description: t.Description, Parameters: t.Parameters, Strict: true},
+			})
+		}
+		chatReq.ToolChoice = toChatToolChoice(req.ToolChoice)
+		parallel := req.ParallelToolCalls
+		chatReq.ParallelToolCalls = &parallel
+	}
+	return chatReq
+}
+
+// toChatToolChoice maps agentkit tool choices ("auto", "required", "none" or
+// a tool name) to the Chat Completions format.
+func toChatToolChoice(choice string) any {
+	switch choice {
+	case "":
+		return nil
+	case "auto", "required", "none":
+		return choice
+	default:
+		return map[string]any{"type": "function", "function": map[string]string{"name": choice}}
+	}
+}
+
+func toChatMessages(messages []providers.Message) []chatMessage {
+	out := make([]chatMessage, 0, len(messages))
+	for _, msg := range messages {
+		switch {
+		case msg.ToolCallID != "" || msg.Role == providers.RoleTool:
+			out = append(out, chatMessage{Role: "tool", ToolCallID: msg.ToolCallID, Content: msg.Content})
+		case msg.Role == providers.RoleAssistant:
+			chatMsg := chatMessage{Role: "assistant"}
+			if msg.Content != "" {
+				chatMsg.Content = msg.Content
+			}
+			for _, call := range msg.ToolCalls {
+				args := "{}"
+				if call.Arguments != nil {
+					if data, err := json.Marshal(call.Arguments); err == nil {
+						args = string(data)
+					}
+				}
+				chatMsg.ToolCalls = append(chatMsg.ToolCalls, chatToolCall{
+					ID:       call.ID,
+					Type:     "function",
+					Function: chatCallFunction{Name: call.Name, Arguments: args},
+				})
+			}
+			out = append(out, chatMsg)
+		default:
+			role := string(msg.Role)
+			if role == "" {
+				role = "user"
+			}
+			if len(msg.Images) == 0 && len(msg.Files) == 0 && len(msg.Audio) == 0 {
+				out = append(out, chatMessage{Role: role, Content: msg.Content})
+				continue
+			}
+			parts := make([]chatContentPart, 0, len(msg.Images)+len(msg.Files)+len(msg.Audio)+1)
+			if msg.Content != "" {
+				parts = append(parts, chatContentPart{Type: "text", Text: msg.Content})
+			}
+			for _, image := range msg.Images {
+				parts = append(parts, chatContentPart{Type: "image_url", ImageURL: &chatImageURL{URL: image.URL, Detail: image.Detail}})
+			}
+			for _, file := range msg.Files {
+				part := &chatFile{FileID: file.ID}
+				if file.ID == "" {
+					part.FileData = file.DataURL()
+					part.Filename = file.Name
+				}
+				parts = append(parts, chatContentPart{Type: "file", File: part})
+			}
+			for _, audio := range msg.Audio {
+				parts = append(parts, chatContentPart{Type: "input_audio", InputAudio: &chatInputAudio{
+					Data:   base64.StdEncoding.EncodeToString(audio.Data),
+					Format: audio.Format,
+				}})
+			}
+			out = append(out, chatMessage{Role: role, Content: parts})
+		}
+	}
+	return out
+}
+
+// fromChatResponse converts a Chat Completions response to a provider-agnostic response.
+func (p *Provider) fromChatResponse(resp *chatResponse) *providers.CompletionResponse {
+	domainResp := &providers.CompletionResponse{
+		ID:      resp.ID,
+		Model:   resp.Model,
+		Created: time.Unix(resp.Created, 0),
+		Usage:   resp.Usage.tokenUsage(),
+	}
+	if len(resp.Choices) == 0 {
+		return domainResp
+	}
+	choice := resp.Choices[0]
+	domainResp.Content = choice.Message.Content
+	domainResp.ReasoningSummary = choice.Message.ReasoningContent
+	domainResp.FinishReason = toChatFinishReason(choice.FinishReason)
+	if audio := choice.Message.Audio; audio != nil {
+		// Spoken replies carry their text as the transcript.
+		if domainResp.Content == "" {
+			domainResp.Content = audio.Transcript
+		}
+		if data, err := base64.StdEncoding.DecodeString(audio.Data); err == nil {
+			domainResp.Audio = data
+		} else {
+			p.logger.Warn("malformed audio in response", "error", err)
+		}
+	}
+	for _, call := range choice.Message.ToolCalls {
+		var args map[string]any
+		var raw string
+		if call.Function.Arguments != "" {
+			repaired, err := jsonrepair.Unmarshal(resp.Model, []byte(call.Function.Arguments), &args)
+			if repaired {
+				p.logger.Warn("malformed tool arguments", "tool", call.Function.Name, "model", resp.Model, "repaired", err == nil)
+			}
+			if err != nil {
+				raw = call.Function.Arguments
+			}
+		}
+		domainResp.ToolCalls = append(domainResp.ToolCalls, providers.ToolCall{
+			ID:           call.ID,
+			Name:         call.Function.Name,
+			Arguments:    args,
+			RawArguments: raw,
+		})
+	}
+	if len(domainResp.ToolCalls) > 0 {
+		domainResp.FinishReason = providers.FinishReasonToolCalls
+	}
+	return domainResp
+}
+
+func toChatFinishReason(reason string) providers.FinishReason {
+	switch reason {
+	case "tool_calls", "function_call":
+		return providers.FinishReasonToolCalls
+	case "length":
+		return providers.FinishReasonLength
+	case "":
+		return ""
+	default:
+		return providers.FinishReasonStop
+	}
+}
+
+// chatStreamReader turns Chat Completions server-sent events into stream
+// chunks. Tool calls produce a name chunk, argument deltas and, once the
+// choice finishes, a chunk with the complete arguments; [DONE] produces the
+// completion chunk with usage.
+type chatStreamReader struct {
+	reader       io.ReadCloser
+	lines        *bufio.Reader
+	logger       *slog.Logger
+	pending      []*providers.StreamChunk
+	calls        map[int]*chatStreamCall
+	finishReason providers.FinishReason
+	usage        *providers.TokenUsage
+	done         bool
+}
+
+type chatStreamCall struct {
+	id   string
+	name string
+	args strings.Builder
+}
+
+func newChatStreamReader(reader io.ReadCloser, logger *slog.Logger) *chatStreamReader {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &chatStreamReader{
+		reader: reader,
+		lines:  bufio.NewReader(reader),
+		logger: logger,
+		calls:  make(map[int]*chatStreamCall),
+	}
+}
+
+// Next returns the next chunk, or io.EOF after the completion chunk.
+func (s *chatStreamReader) Next() (*providers.StreamChunk, error) {
+	for {
+		if len(s.pending) > 0 {
+			chunk := s.pending[0]
+			s.pending = s.pending[1:]
+			return chunk, nil
+		}
+		if s.done {
+			return nil, io.EOF
+		}
+		line, err := s.lines.ReadString('\n')
+		if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:"); ok {
+			if herr := s.handle(strings.TrimSpace(data)); herr != nil {
+				return nil, herr
+			}
+			continue
+		}
+		if err == io.EOF {
+			// Some servers close the stream without [DONE].
+			s.complete()
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Close closes the underlying response body.
+func (s *chatStreamReader) Close() error {
+	return s.reader.Close()
+}
+
+func (s *chatStreamReader) handle(data string) error {
+	if data == "[DONE]" {
+		s.complete()
+		return nil
+	}
+	var event chatStreamEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		s.logger.Error("failed to parse stream event", "error", err)
+		return nil
+	}
+	if event.Error != nil {
+		return fmt.Errorf("stream error: %s", event.Error.Message)
+	}
+	if event.Usage != nil {
+		usage := event.Usage.tokenUsage()
+		s.usage = &usage
+	}
+
+	for _, choice := range event.Choices {
+		if choice.Index != 0 {
+			continue
+		}
+		if choice.Delta.ReasoningContent != "" {
+			s.pending = append(s.pending, &providers.StreamChunk{ReasoningSummary: choice.Delta.ReasoningContent})
+		}
+		if choice.Delta.Content != "" {
+			s.pending = append(s.pending, &providers.StreamChunk{Content: choice.Delta.Content})
+		}
+		if audio := choice.Delta.Audio; audio != nil {
+			chunk := &providers.StreamChunk{Content: audio.Transcript}
+			if audio.Data != "" {
+				data, err := base64.StdEncoding.DecodeString(audio.Data)
+				if err != nil {
+					s.logger.Error("failed to decode audio delta", "error", err)
+				}
+				chunk.Audio = data
+			}
+			if chunk.Content != "" || len(chunk.Audio) > 0 {
+				s.pending = append(s.pending, chunk)
+			}
+		}
+		for _, delta := range choice.Delta.ToolCalls {
+			call := s.calls[delta.Index]
+			if call == nil {
+				call = &chatStreamCall{}
+				s.calls[delta.Index] = call
+			}
+			if delta.ID != "" {
+				call.id = delta.ID
+			} else if call.id == "" {
+				// Some compatible servers omit tool call IDs.
+				call.id = fmt.Sprintf("call_%d", delta.Index)
+			}
+			if delta.Function.Name != "" {
+				call.name = delta.Function.Name
+				s.pending = append(s.pending, &providers.StreamChunk{ToolCallID: call.id, ToolName: call.name})
+			}
+			if delta.Function.Arguments != "" {
+				call.args.WriteString(delta.Function.Arguments)
+				s.pending = append(s.pending, &providers.StreamChunk{
+					ToolCallID:    call.id,
+					ToolName:      call.name,
+					ToolArgsDelta: delta.Function.Arguments,
+				})
+			}
+		}
+		if choice.FinishReason != "" {
+			s.finishReason = toChatFinishReason(choice.FinishReason)
+			s.flushToolCalls()
+		}
+	}
+	return nil
+}
+
+// flushToolCalls emits the complete arguments of every tool call, in index order.
+func (s *chatStreamReader) flushToolCalls() {
+	indexes := make([]int, 0, len(s.calls))
+	for index := range s.calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		call := s.calls[index]
+		args := call.args.String()
+		if args == "" {
+			args = "{}"
+		}
+		s.pending = append(s.pending, &providers.StreamChunk{ToolCallID: call.id, ToolName: call.name, ToolArgs: args})
+	}
+	if len(s.calls) > 0 {
+		s.finishReason = providers.FinishReasonToolCalls
+	}
+	s.calls = make(map[int]*chatStreamCall)
+}
+
+func (s *chatStreamReader) complete() {
+	if s.done {
+		return
+	}
+	s.flushToolCalls()
+	s.pending = append(s.pending, &providers.StreamChunk{
+		IsComplete:   true,
+		FinishReason: s.finishReason,
+		Usage:        s.usage,
+	})
+	s.done = true
+}
+
+// Chat Completions API types (internal to this package)
+
+type chatRequest struct {
+	Model             string              `json:"model"`
+	Messages          []chatMessage       `json:"messages"`
+	Tools             []chatTool          `json:"tools,omitempty"`
+	ToolChoice        any                 `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool               `json:"parallel_tool_calls,omitempty"`
+	Temperature       *float32            `json:"temperature,omitempty"`
+	TopP              *float32            `json:"top_p,omitempty"`
+	MaxTokens         int                 `json:"max_tokens,omitempty"`
+	Seed              *int64              `json:"seed,omitempty"`
+	ReasoningEffort   string              `json:"reasoning_effort,omitempty"`
+	ResponseFormat    *chatResponseFormat `json:"response_format,omitempty"`
+	Modalities        []string            `json:"modalities,omitempty"`
+	Audio             *chatAudioOutput    `json:"audio,omitempty"`
+	Stream            bool                `json:"stream,omitempty"`
+	StreamOptions     *chatStreamOptions  `json:"stream_options,omitempty"`
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    any            `json:"content"` // string, []chatContentPart or nil
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type chatContentPart struct {
+	Type       string          `json:"type"`
+	Text       string          `json:"text,omitempty"`
+	ImageURL   *chatImageURL   `json:"image_url,omitempty"`
+	File       *chatFile       `json:"file,omitempty"`
+	InputAudio *chatInputAudio `json:"input_audio,omitempty"`
+}
+
+type chatInputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
+
+type chatAudioOutput struct {
+	Voice  string `json:"voice"`
+	Format string `json:"format"`
+}
+
+// chatAudio is a spoken reply, or a fragment of one when streaming.
+type chatAudio struct {
+	ID         string `json:"id"`
+	Data       string `json:"data"`
+	Transcript string `json:"transcript"`
+}
+
+type chatFile struct {
+	FileID   string `json:"file_id,omitempty"`
+	FileData string `json:"file_data,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+type chatImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+type chatFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+	Strict      bool           `json:"strict,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Function chatCallFunction `json:"function"`
+}
+
+type chatCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type chatResponseFormat struct {
+	Type       string          `json:"type"`
+	JSONSchema *chatJSONSchema `json:"json_schema,omitempty"`
+}
+
+type chatJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict,omitempty"`
+}
+
+type chatStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatResponse struct {
+	ID      string `json:"id"`
+	Model   string `json:"model"`
+	Created int64  `json:"created"`
+	Choices []struct {
+		Message struct {
+			Content          string         `json:"content"`
+			ReasoningContent string         `json:"reasoning_content"`
+			ToolCalls        []chatToolCall `json:"tool_calls"`
+			Audio            *chatAudio     `json:"audio"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage chatUsage `json:"usage"`
+}
+
+type chatStreamEvent struct {
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Content          string     `json:"content"`
+			ReasoningContent string     `json:"reasoning_content"`
+			Audio            *chatAudio `json:"audio"`
+			ToolCalls        []struct {
+				Index    int              `json:"index"`
+				ID       string           `json:"id"`
+				Function chatCallFunction `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+	Error *apiError  `json:"error"`
+}
+
+type chatUsage struct {
+	PromptTokens            int `json:"prompt_tokens"`
+	CompletionTokens        int `json:"completion_tokens"`
+	TotalTokens             int `json:"total_tokens"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+func (u chatUsage) tokenUsage() providers.TokenUsage {
+	return providers.TokenUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		ReasoningTokens:  u.CompletionTokensDetails.ReasoningTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
@@ -0,0 +1,229 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+func TestProvider_FallsBackToChatCompletions(t *testing.T) {
+	var responsesCalls, chatCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" || r.Header.Get("X-Title") != "support-bot" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		switch r.URL.Path {
+		case "/v1/responses":
+			responsesCalls++
+			w.WriteHeader(http.StatusNotFound)
+		case "/v1/chat/completions":
+			chatCalls++
+			var body chatRequest
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body.Model != "meta-llama/llama-3.1-70b" || len(body.Messages) != 2 || body.Messages[0].Role != "system" {
+				t.Errorf("body = %+v", body)
+			}
+			_, _ = w.Write([]byte(`{
+				"id": "chatcmpl-1", "model": "meta-llama/llama-3.1-70b", "created": 1700000000,
+				"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {"role": "assistant", "content": null,
+					"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "lookup", "arguments": "{\"id\": \"7\"}"}}]}}],
+				"usage": {"prompt_tokens": 50, "completion_tokens": 10, "total_tokens": 60}
+			}`))
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	p := New("key", nil).WithBaseURL(server.URL + "/v1/").WithHeaders(map[string]string{"X-Title": "support-bot"})
+	req := providers.CompletionRequest{
+		Model:        "meta-llama/llama-3.1-70b",
+		SystemPrompt: "Be brief.",
+		Messages:     []providers.Message{{Role: providers.RoleUser, Content: "order 7?"}},
+	}
+	for range 2 {
+		resp, err := p.Complete(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Arguments["id"] != "7" || resp.FinishReason != providers.FinishReasonToolCalls {
+			t.Errorf("response = %+v", resp)
+		}
+		if resp.Usage.TotalTokens != 60 {
+			t.Errorf("usage = %+v", resp.Usage)
+		}
+	}
+	if responsesCalls != 1 || chatCalls != 2 {
+		t.Errorf("responses calls = %d, chat calls = %d; want the fallback remembered", responsesCalls, chatCalls)
+	}
+}
+
+func TestProvider_DefaultBaseURLDoesNotFallBack(t *testing.T) {
+	p := New("key", nil)
+	if p.baseURL != DefaultBaseURL || p.chatCompletions.Load() {
+		t.Errorf("baseURL = %q, chat completions = %v", p.baseURL, p.chatCompletions.Load())
+	}
+	if p.WithBaseURL("").baseURL != DefaultBaseURL {
+		t.Error("empty base URL replaced the default")
+	}
+}
+
+func TestToChatRequest(t *testing.T) {
+	p := New("", nil)
+	seed := int64(3)
+	req := p.toChatRequest(providers.CompletionRequest{
+		Model:        "qwen2.5",
+		SystemPrompt: "Be brief.",
+		Messages: []providers.Message{
+			{Role: providers.RoleDeveloper, Content: "Refunds need approval."},
+			{Role: providers.RoleUser, Content: "receipt", Images: []providers.Image{{URL: "https://example.com/r.png", Detail: "low"}}},
+			{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "call_1", Name: "lookup", Arguments: map[string]any{"id": "7"}}}},
+			{Role: providers.RoleTool, ToolCallID: "call_1", Content: "shipped"},
+		},
+		Tools:         []providers.ToolDefinition{{Name: "lookup", Parameters: map[string]any{"type": "object"}}},
+		ToolChoice:    "lookup",
+		Deterministic: true,
+		Seed:          &seed,
+		TextFormat:    "json_object",
+	})
+
+	data, _ := json.Marshal(req)
+	for _, want := range []string{
+		`{"role":"system","content":"Be brief.\n\nRefunds need approval."}`,
+		`{"type":"image_url","image_url":{"url":"https://example.com/r.png","detail":"low"}}`,
+		`{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"id\":\"7\"}"}}]}`,
+		`{"role":"tool","content":"shipped","tool_call_id":"call_1"}`,
+		`"tool_choice":{"function":{"name":"lookup"},"type":"function"}`,
+		`"parallel_tool_calls":false`,
+		`"temperature":0`,
+		`"seed":3`,
+		`"response_format":{"type":"json_object"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("request %s\nmissing %s", data, want)
+		}
+	}
+}
+
+func TestToChatRequest_OutputSchema(t *testing.T) {
+	p := New("", nil)
+	req := p.toChatRequest(providers.CompletionRequest{
+		Model:        "gpt-4o",
+		TextFormat:   "json_object",
+		OutputSchema: &providers.OutputSchema{Name: "answer", Schema: map[string]any{"type": "object"}, Strict: true},
+	})
+	data, _ := json.Marshal(req.ResponseFormat)
+	if want := `{"type":"json_schema","json_schema":{"name":"answer","schema":{"type":"object"},"strict":true}}`; string(data) != want {
+		t.Errorf("response_format = %s\nwant %s", data, want)
+	}
+}
+
+func TestChatStreamReader(t *testing.T) {
+	events := []string{
+		`{"choices": [{"index": 0, "delta": {"role": "assistant", "content": "Checking"}}]}`,
+		`{"choices": [{"index": 0, "delta": {"content": " now."}}]}`,
+		`{"choices": [{"index": 0, "delta": {"tool_calls": [{"index": 0, "id": "call_a", "function": {"name": "lookup", "arguments": ""}}]}}]}`,
+		`{"choices": [{"index": 0, "delta": {"tool_calls": [{"index": 1, "function": {"name": "policy", "arguments": "{}"}}]}}]}`,
+		`{"choices": [{"index": 0, "delta": {"tool_calls": [{"index": 0, "function": {"arguments": "{\"id\": "}}]}}]}`,
+		`{"choices": [{"index": 0, "delta": {"tool_calls": [{"index": 0, "function": {"arguments": "\"7\"}"}}]}}]}`,
+		`{"choices": [{"index": 0, "delta": {}, "finish_reason": "tool_calls"}]}`,
+		`{"choices": [], "usage": {"prompt_tokens": 40, "completion_tokens": 12, "total_tokens": 52}}`,
+		`[DONE]`,
+	}
+	var body strings.Builder
+	for _, e := range events {
+		body.WriteString("data: " + e + "\n\n")
+	}
+
+	stream := newChatStreamReader(io.NopCloser(strings.NewReader(body.String())), nil)
+	var content, deltas string
+	var final []*providers.StreamChunk
+	var complete *providers.StreamChunk
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		content += chunk.Content
+		deltas += chunk.ToolArgsDelta
+		if chunk.ToolArgs != "" {
+			final = append(final, chunk)
+		}
+		if chunk.IsComplete {
+			complete = chunk
+		}
+	}
+
+	if content != "Checking now." || deltas != `{}{"id": "7"}` {
+		t.Errorf("content = %q, deltas = %q", content, deltas)
+	}
+	if len(final) != 2 || final[0].ToolCallID != "call_a" || final[0].ToolArgs != `{"id": "7"}` || final[1].ToolCallID != "call_1" || final[1].ToolName != "policy" {
+		t.Errorf("final tool chunks = %+v", final)
+	}
+	if complete == nil || complete.FinishReason != providers.FinishReasonToolCalls || complete.Usage == nil || complete.Usage.TotalTokens != 52 {
+		t.Errorf("completion chunk = %+v", complete)
+	}
+}
+
+func TestProvider_AudioUsesChatCompletions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("path = %q, want chat completions for audio", r.URL.Path)
+		}
+		data, _ := io.ReadAll(r.Body)
+		for _, want := range []string{
+			`{"type":"input_audio","input_audio":{"data":"dm9pY2U=","format":"wav"}}`,
+			`"modalities":["text","audio"]`,
+			`"audio":{"voice":"alloy","format":"pcm16"}`,
+		} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("request %s\nmissing %s", data, want)
+			}
+		}
+		events := []string{
+			`{"choices": [{"index": 0, "delta": {"audio": {"id": "audio_1", "transcript": "Hel"}}}]}`,
+			`{"choices": [{"index": 0, "delta": {"audio": {"data": "AQI="}}}]}`,
+			`{"choices": [{"index": 0, "delta": {"audio": {"transcript": "lo", "data": "AwQ="}}, "finish_reason": "stop"}]}`,
+			`[DONE]`,
+		}
+		for _, e := range events {
+			_, _ = w.Write([]byte("data: " + e + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	stream, err := New("key", nil).WithBaseURL(server.URL).Stream(context.Background(), providers.CompletionRequest{
+		Model:    "gpt-4o-audio-preview",
+		Messages: []providers.Message{{Role: providers.RoleUser, Audio: []providers.Audio{{Data: []byte("voice"), Format: "wav"}}}},
+		Audio:    &providers.AudioOutput{},
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	defer stream.Close()
+	var transcript string
+	var audio []byte
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		transcript += chunk.Content
+		audio = append(audio, chunk.Audio...)
+	}
+	if transcript != "Hello" || string(audio) != "\x01\x02\x03\x04" {
+		t.Errorf("transcript = %q, audio = %v", transcript, audio)
+	}
+}
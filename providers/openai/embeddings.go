@@ -0,0 +1,104 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Embedding defaults.
+const (
+	DefaultEmbeddingModel = "text-embedding-3-small"
+	// MaxEmbeddingBatch is the most inputs the embeddings API takes per request.
+	MaxEmbeddingBatch = 2048
+)
+
+// Embedder implements providers.Embedder with the OpenAI embeddings API, on
+// the provider's server, key and headers. Large inputs are split into
+// batches of BatchSize.
+type Embedder struct {
+	provider *Provider
+	// Model defaults to DefaultEmbeddingModel.
+	Model string
+	// Dimensions shortens vectors on models that support it (text-embedding-3-*).
+	Dimensions int
+	// BatchSize caps inputs per request (default and maximum MaxEmbeddingBatch).
+	BatchSize int
+	// Concurrency is how many batches are sent at once (default 1).
+	Concurrency int
+}
+
+// NewEmbedder creates an OpenAI embedder for model.
+func NewEmbedder(apiKey, model string) *Embedder {
+	return New(apiKey, nil).Embedder(model)
+}
+
+// Embedder returns an embedder for model that shares the provider's base URL,
+// headers and HTTP client, e.g. for an OpenAI-compatible server.
+func (p *Provider) Embedder(model string) *Embedder {
+	return &Embedder{provider: p, Model: model}
+}
+
+// Embed implements providers.Embedder.
+func (e *Embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	batchSize := e.BatchSize
+	if batchSize <= 0 || batchSize > MaxEmbeddingBatch {
+		batchSize = MaxEmbeddingBatch
+	}
+	return providers.BatchEmbedder{
+		Embedder:    providers.EmbedderFunc(e.embed),
+		BatchSize:   batchSize,
+		Concurrency: e.Concurrency,
+	}.Embed(ctx, texts)
+}
+
+type embeddingRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// embed sends one request for texts.
+func (e *Embedder) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := e.Model
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+	resp, err := e.provider.post(ctx, "/embeddings", embeddingRequest{
+		Model:          model,
+		Input:          texts,
+		Dimensions:     e.Dimensions,
+		EncodingFormat: "float",
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	var apiResp embeddingResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	sort.Slice(apiResp.Data, func(i, j int) bool { return apiResp.Data[i].Index < apiResp.Data[j].Index })
+	vectors := make([][]float32, len(apiResp.Data))
+	for i, item := range apiResp.Data {
+		vectors[i] = item.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,51 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEmbedder_BatchesAndOrders(t *testing.T) {
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("request = %s %v", r.URL.Path, r.Header)
+		}
+		var body embeddingRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Model != "text-embedding-3-large" || body.Dimensions != 256 {
+			t.Errorf("body = %+v", body)
+		}
+		batches = append(batches, body.Input)
+		// Answer out of order; the embedder sorts by index.
+		var resp embeddingResponse
+		for i := len(body.Input) - 1; i >= 0; i-- {
+			resp.Data = append(resp.Data, struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			}{i, []float32{float32(len(body.Input[i]))}})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	embedder := New("key", nil).WithBaseURL(server.URL).Embedder("text-embedding-3-large")
+	embedder.Dimensions = 256
+	embedder.BatchSize = 2
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Errorf("batches = %v, want 2 then 1 inputs", batches)
+	}
+	for i, v := range vectors {
+		if int(v[0]) != i+1 {
+			t.Errorf("vectors[%d] = %v, want %d", i, v, i+1)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+)
+
+// FilePurpose is the purpose files are uploaded with, the one the API
+// requires for model inputs.
+const FilePurpose = "user_data"
+
+// UploadFile implements providers.FileUploader with the files API.
+func (p *Provider) UploadFile(ctx context.Context, name, mediaType string, data []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("purpose", FilePurpose); err != nil {
+		return "", err
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, name))
+	header.Set("Content-Type", mediaType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/files", &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", form.FormDataContentType())
+	var uploaded struct {
+		ID string `json:"id"`
+	}
+	if err := p.sendFileRequest(ctx, httpReq, &uploaded); err != nil {
+		return "", err
+	}
+	if uploaded.ID == "" {
+		return "", fmt.Errorf("openai: upload of %s returned no file ID", name)
+	}
+	return uploaded.ID, nil
+}
+
+// DeleteFile implements providers.FileUploader.
+func (p *Provider) DeleteFile(ctx context.Context, id string) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, p.baseURL+"/files/"+url.PathEscape(id), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return p.sendFileRequest(ctx, httpReq, nil)
+}
+
+func (p *Provider) sendFileRequest(ctx context.Context, httpReq *http.Request, out any) error {
+	resp, err := p.do(ctx, httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return parseAPIError(resp.StatusCode, body)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
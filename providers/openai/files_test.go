@@ -0,0 +1,81 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+func TestProvider_UploadAndDeleteFile(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("authorization = %q", r.Header.Get("Authorization"))
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("ParseMultipartForm() error = %v", err)
+			}
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Fatalf("FormFile() error = %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			if r.FormValue("purpose") != FilePurpose || header.Filename != "q3.pdf" || header.Header.Get("Content-Type") != "application/pdf" || string(data) != "%PDF-1.7" {
+				t.Errorf("upload = %q %q %v %q", r.FormValue("purpose"), header.Filename, header.Header, data)
+			}
+			_, _ = io.WriteString(w, `{"id": "file-abc", "object": "file"}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/files/file-abc":
+			deleted = r.URL.Path
+			_, _ = io.WriteString(w, `{"id": "file-abc", "deleted": true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	p := New("key", nil).WithBaseURL(server.URL)
+	var uploader providers.FileUploader = p
+	id, err := uploader.UploadFile(context.Background(), "q3.pdf", "application/pdf", []byte("%PDF-1.7"))
+	if err != nil || id != "file-abc" {
+		t.Fatalf("UploadFile() = %q, %v", id, err)
+	}
+	if err := uploader.DeleteFile(context.Background(), id); err != nil || deleted != "/files/file-abc" {
+		t.Errorf("DeleteFile() error = %v, path %q", err, deleted)
+	}
+	if err := uploader.DeleteFile(context.Background(), "missing/id"); err == nil {
+		t.Error("DeleteFile() of a missing file succeeded")
+	}
+}
+
+func TestToAPIInput_Files(t *testing.T) {
+	p := New("test", nil)
+	inputs := p.toAPIInput([]providers.Message{{
+		Role:    providers.RoleUser,
+		Content: "summarize",
+		Files: []providers.File{
+			{Name: "q3.csv", MediaType: "text/csv", Data: []byte("a,b")},
+			{ID: "file-abc", Name: "q3.pdf"},
+		},
+	}})
+	item := inputs[0].(input)
+	if len(item.Content) != 3 {
+		t.Fatalf("content = %+v", item.Content)
+	}
+	if inline := item.Content[1]; inline.Type != "input_file" || inline.FileData != "data:text/csv;base64,YSxi" || inline.Filename != "q3.csv" {
+		t.Errorf("inline file = %+v", inline)
+	}
+	if uploaded := item.Content[2]; uploaded.Type != "input_file" || uploaded.FileID != "file-abc" || uploaded.FileData != "" {
+		t.Errorf("uploaded file = %+v", uploaded)
+	}
+
+	chat := toChatMessages([]providers.Message{{Role: providers.RoleUser, Files: []providers.File{{ID: "file-abc"}}}})
+	if parts, ok := chat[0].Content.([]chatContentPart); !ok || parts[0].Type != "file" || parts[0].File.FileID != "file-abc" {
+		t.Errorf("chat content = %+v", chat[0].Content)
+	}
+}
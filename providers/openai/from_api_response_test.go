@@ -22,3 +22,28 @@ func TestFromAPIResponseSkipsFunctionCallWithoutCallID(t *testing.T) {
 		t.Fatalf("expected no tool calls without call_id, got %d", len(domain.ToolCalls))
 	}
 }
+
+func TestFromAPIResponseRepairsMalformedArguments(t *testing.T) {
+	p := New("test", nil)
+	resp := &responseObject{
+		ID:     "resp_1",
+		Model:  "gpt-4o-mini",
+		Status: "incomplete",
+		Output: []outputItem{
+			{
+				Type:      "function_call",
+				CallID:    "call_1",
+				Name:      "search",
+				Arguments: "{\"query\":\"refund policy\",\"limit\":",
+			},
+		},
+	}
+	domain := p.fromAPIResponse(resp)
+	if len(domain.ToolCalls) != 1 {
+		t.Fatalf("expected 1 tool call, got %d", len(domain.ToolCalls))
+	}
+	args := domain.ToolCalls[0].Arguments
+	if args["query"] != "refund policy" || args["limit"] != nil {
+		t.Fatalf("expected truncated arguments to be repaired, got %v", args)
+	}
+}
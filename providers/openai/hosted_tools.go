@@ -0,0 +1,76 @@
+package openai
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// hostedAPITool converts a hosted tool to its Responses API definition: the
+// type plus its options at the top level.
+func hostedAPITool(t providers.HostedTool) map[string]any {
+	def := make(map[string]any, len(t.Options)+1)
+	for key, value := range t.Options {
+		def[key] = value
+	}
+	def["type"] = t.Type
+	return def
+}
+
+// hostedToolType returns the hosted tool type of an output item type such
+// as "web_search_call", or false for other items.
+func hostedToolType(itemType string) (string, bool) {
+	if itemType == "function_call" {
+		return "", false
+	}
+	return strings.CutSuffix(itemType, "_call")
+}
+
+// hostedToolCallFromItem converts a hosted tool's output item.
+func hostedToolCallFromItem(item outputItem) *providers.HostedToolCall {
+	toolType, ok := hostedToolType(item.Type)
+	if !ok {
+		return nil
+	}
+	return &providers.HostedToolCall{
+		ID:      item.ID,
+		Type:    toolType,
+		Status:  item.Status,
+		Details: item.details,
+	}
+}
+
+// hostedToolStatus parses hosted tool progress events such as
+// "response.web_search_call.searching" into the tool type and status.
+func hostedToolStatus(eventType string) (toolType, status string, ok bool) {
+	rest, ok := strings.CutPrefix(eventType, "response.")
+	if !ok {
+		return "", "", false
+	}
+	item, status, ok := strings.Cut(rest, ".")
+	if !ok || strings.Contains(status, ".") {
+		return "", "", false
+	}
+	toolType, ok = hostedToolType(item)
+	return toolType, status, ok
+}
+
+// UnmarshalJSON keeps the tool-specific fields of hosted tool items, such as
+// a web search's action or code interpreter outputs, in details.
+func (o *outputItem) UnmarshalJSON(data []byte) error {
+	type plain outputItem
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		return err
+	}
+	if _, ok := hostedToolType(o.Type); !ok {
+		return nil
+	}
+	if err := json.Unmarshal(data, &o.details); err != nil {
+		return err
+	}
+	for _, key := range []string{"type", "id", "status"} {
+		delete(o.details, key)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+func TestToAPIRequest_HostedTools(t *testing.T) {
+	p := New("test", nil)
+	apiReq := p.toAPIRequest(providers.CompletionRequest{
+		Model: "gpt-4.1",
+		Tools: []providers.ToolDefinition{{Name: "lookup", Parameters: map[string]any{"type": "object"}}},
+		HostedTools: []providers.HostedTool{
+			{Type: providers.HostedToolWebSearch},
+			{Type: providers.HostedToolFileSearch, Options: map[string]any{"vector_store_ids": []string{"vs_1"}}},
+		},
+	})
+
+	data, err := json.Marshal(apiReq.Tools)
+	if err != nil {
+		t.Fatalf("marshal tools: %v", err)
+	}
+	want := `[{"type":"function","name":"lookup","parameters":{"type":"object"},"strict":true},{"type":"web_search"},{"type":"file_search","vector_store_ids":["vs_1"]}]`
+	if string(data) != want {
+		t.Errorf("tools = %s\nwant %s", data, want)
+	}
+}
+
+func TestFromAPIResponse_HostedToolCalls(t *testing.T) {
+	var resp responseObject
+	body := `{"id":"resp_1","status":"completed","output":[
+		{"type":"web_search_call","id":"ws_1","status":"completed","action":{"type":"search","query":"go 1.24 release"}},
+		{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Released in February."}]}
+	]}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	domain := New("test", nil).fromAPIResponse(&resp)
+
+	if len(domain.HostedToolCalls) != 1 {
+		t.Fatalf("hosted tool calls = %+v, want 1", domain.HostedToolCalls)
+	}
+	call := domain.HostedToolCalls[0]
+	if call.ID != "ws_1" || call.Type != "web_search" || call.Status != "completed" {
+		t.Errorf("call = %+v", call)
+	}
+	if action, _ := call.Details["action"].(map[string]any); action["query"] != "go 1.24 release" {
+		t.Errorf("details = %v, want the search action", call.Details)
+	}
+	if domain.Content != "Released in February." || domain.FinishReason != providers.FinishReasonStop {
+		t.Errorf("content %q, finish reason %q", domain.Content, domain.FinishReason)
+	}
+}
+
+func TestStreamReaderEmitsHostedToolProgress(t *testing.T) {
+	sseData := `data: {"type":"response.output_item.added","output_index":0,"item":{"type":"code_interpreter_call","id":"ci_1","status":"in_progress","code":""}}
+
+data: {"type":"response.code_interpreter_call.interpreting","output_index":0,"item_id":"ci_1"}
+
+data: {"type":"response.code_interpreter_call_code.delta","output_index":0,"item_id":"ci_1","delta":"print(1)"}
+
+data: {"type":"response.output_item.done","output_index":0,"item":{"type":"code_interpreter_call","id":"ci_1","status":"completed","code":"print(1)","outputs":[{"type":"logs","logs":"1"}]}}
+
+data: {"type":"response.output_text.delta","delta":"The answer is 1."}
+
+data: {"type":"response.completed","response":{"id":"resp_1","status":"completed","output":[]}}
+
+`
+	reader := newStreamReader(io.NopCloser(strings.NewReader(sseData)), nil)
+
+	var calls []providers.HostedToolCall
+	var content strings.Builder
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream read error: %v", err)
+		}
+		if chunk.HostedToolCall != nil {
+			calls = append(calls, *chunk.HostedToolCall)
+		}
+		content.WriteString(chunk.Content)
+	}
+
+	var statuses []string
+	for _, call := range calls {
+		if call.ID != "ci_1" || call.Type != "code_interpreter" {
+			t.Errorf("call = %+v", call)
+		}
+		statuses = append(statuses, call.Status)
+	}
+	if got := strings.Join(statuses, ","); got != "in_progress,interpreting,completed" {
+		t.Errorf("statuses = %s", got)
+	}
+	if last := calls[len(calls)-1]; last.Details["code"] != "print(1)" || last.Details["outputs"] == nil {
+		t.Errorf("completed details = %v", last.Details)
+	}
+	if content.String() != "The answer is 1." {
+		t.Errorf("content = %q, want only the message text", content.String())
+	}
+}
@@ -0,0 +1,58 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultModerationModel is the model Moderate uses.
+const DefaultModerationModel = "omni-moderation-latest"
+
+type moderationRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged        bool               `json:"flagged"`
+		Categories     map[string]bool    `json:"categories"`
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+}
+
+// Moderate implements providers.Moderator with the moderation endpoint.
+func (p *Provider) Moderate(ctx context.Context, text string) (*providers.Moderation, error) {
+	resp, err := p.post(ctx, "/moderations", moderationRequest{Model: DefaultModerationModel, Input: text}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	var apiResp moderationResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(apiResp.Results) == 0 {
+		return nil, fmt.Errorf("openai: moderation returned no results")
+	}
+
+	result := apiResp.Results[0]
+	moderation := &providers.Moderation{Flagged: result.Flagged, Scores: result.CategoryScores}
+	for category, flagged := range result.Categories {
+		if flagged {
+			moderation.Categories = append(moderation.Categories, category)
+		}
+	}
+	sort.Strings(moderation.Categories)
+	return moderation, nil
+}
@@ -0,0 +1,34 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProvider_Moderate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req moderationRequest
+		if r.URL.Path != "/moderations" || json.NewDecoder(r.Body).Decode(&req) != nil || req.Model != DefaultModerationModel || req.Input != "some text" {
+			t.Errorf("request = %s %+v", r.URL.Path, req)
+		}
+		_, _ = io.WriteString(w, `{"id": "modr-1", "results": [{"flagged": true,
+			"categories": {"violence": true, "harassment": true, "self-harm": false},
+			"category_scores": {"violence": 0.91, "harassment": 0.6, "self-harm": 0.01}}]}`)
+	}))
+	defer server.Close()
+
+	moderation, err := New("key", nil).WithBaseURL(server.URL).Moderate(context.Background(), "some text")
+	if err != nil {
+		t.Fatalf("Moderate() error = %v", err)
+	}
+	if !moderation.Flagged || len(moderation.Categories) != 2 || moderation.Categories[0] != "harassment" || moderation.Categories[1] != "violence" {
+		t.Errorf("moderation = %+v", moderation)
+	}
+	if moderation.Scores["violence"] != 0.91 {
+		t.Errorf("scores = %v", moderation.Scores)
+	}
+}
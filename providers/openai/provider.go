@@ -1,27 +1,39 @@
-// Package openai implements the Provider interface for OpenAI's Responses API.
+// Package openai implements the Provider interface for OpenAI's Responses
+// API, with a Chat Completions fallback for OpenAI-compatible servers.
 package openai
 
 import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/darkostanimirovic/agentkit/jsonrepair"
 	"github.com/darkostanimirovic/agentkit/providers"
 )
 
-const responsesEndpoint = "https://api.openai.com/v1/responses"
+// DefaultBaseURL is the OpenAI API base URL.
+const DefaultBaseURL = "https://api.openai.com/v1"
 
-// Provider implements providers.Provider for OpenAI.
+// Provider implements providers.Provider for OpenAI and OpenAI-compatible
+// servers (OpenRouter, vLLM, LiteLLM, Together and the like).
 type Provider struct {
 	apiKey     string
+	baseURL    string
+	headers    map[string]string
 	httpClient *http.Client
 	logger     *slog.Logger
+	// chatCompletions switches requests to /chat/completions, for servers
+	// without the Responses API. It is set by WithChatCompletions or the
+	// first time a custom server answers /responses with 404.
+	chatCompletions atomic.Bool
 }
 
 // New creates a new OpenAI provider.
@@ -31,11 +43,51 @@ func New(apiKey string, logger *slog.Logger) *Provider {
 	}
 	return &Provider{
 		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		baseURL:    DefaultBaseURL,
+		httpClient: providers.DefaultHTTPClient(),
 		logger:     logger,
 	}
 }
 
+// WithBaseURL points the provider at an OpenAI-compatible server, e.g.
+// "https://openrouter.ai/api/v1". Requests fall back to the Chat Completions
+// API when the server does not implement /responses.
+func (p *Provider) WithBaseURL(baseURL string) *Provider {
+	if baseURL != "" {
+		p.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	return p
+}
+
+// WithHTTPClient sets the client requests are sent with, e.g. one from
+// providers.NewHTTPClient with a larger pool. Providers share
+// providers.DefaultHTTPClient by default.
+func (p *Provider) WithHTTPClient(client *http.Client) *Provider {
+	if client != nil {
+		p.httpClient = client
+	}
+	return p
+}
+
+// WithHeaders adds headers to every request, e.g. OpenRouter's HTTP-Referer
+// and X-Title.
+func (p *Provider) WithHeaders(headers map[string]string) *Provider {
+	if p.headers == nil {
+		p.headers = make(map[string]string, len(headers))
+	}
+	for k, v := range headers {
+		p.headers[k] = v
+	}
+	return p
+}
+
+// WithChatCompletions sends requests to the Chat Completions API instead of
+// the Responses API.
+func (p *Provider) WithChatCompletions() *Provider {
+	p.chatCompletions.Store(true)
+	return p
+}
+
 // Name returns the provider name.
 func (p *Provider) Name() string {
 	return "openai"
@@ -43,24 +95,15 @@ func (p *Provider) Name() string {
 
 // Complete generates a non-streaming completion.
 func (p *Provider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
-	apiReq := p.toAPIRequest(req)
-
-	jsonData, err := json.Marshal(apiReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	if p.chatCompletions.Load() || needsChatCompletions(req) {
+		return p.completeChat(ctx, req)
 	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", responsesEndpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	resp, err := p.post(ctx, "/responses", p.toAPIRequest(req), false)
+	if errors.Is(err, errResponsesUnsupported) {
+		return p.completeChat(ctx, req)
 	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -69,10 +112,6 @@ func (p *Provider) Complete(ctx context.Context, req providers.CompletionRequest
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, parseAPIError(resp.StatusCode, body)
-	}
-
 	var apiResp responseObject
 	if err := json.Unmarshal(body, &apiResp); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
@@ -83,35 +122,79 @@ func (p *Provider) Complete(ctx context.Context, req providers.CompletionRequest
 
 // Stream generates a streaming completion.
 func (p *Provider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	if p.chatCompletions.Load() || needsChatCompletions(req) {
+		return p.streamChat(ctx, req)
+	}
 	apiReq := p.toAPIRequest(req)
 	apiReq.Stream = true
 
-	jsonData, err := json.Marshal(apiReq)
+	resp, err := p.post(ctx, "/responses", apiReq, true)
+	if errors.Is(err, errResponsesUnsupported) {
+		return p.streamChat(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newStreamReader(resp.Body, p.logger), nil
+}
+
+// errResponsesUnsupported reports that a custom server has no /responses
+// endpoint.
+var errResponsesUnsupported = errors.New("openai: server does not implement the Responses API")
+
+// post sends body to path under the base URL and returns the response, or
+// the API error for a non-200 status. A 404 from /responses on a custom
+// server switches the provider to the Chat Completions API.
+func (p *Provider) post(ctx context.Context, path string, body any, stream bool) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", responsesEndpoint, bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL+path, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-	httpReq.Header.Set("Accept", "text/event-stream")
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
 
-	resp, err := p.httpClient.Do(httpReq)
+	resp, err := p.do(ctx, httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
+		if path == "/responses" && p.baseURL != DefaultBaseURL &&
+			(resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed) {
+			p.logger.Info("server has no Responses API; using Chat Completions", "base_url", p.baseURL)
+			p.chatCompletions.Store(true)
+			return nil, errResponsesUnsupported
+		}
 		return nil, parseAPIError(resp.StatusCode, body)
 	}
+	return resp, nil
+}
 
-	return newStreamReader(resp.Body, p.logger), nil
+// do sends httpReq with the provider's credentials and headers.
+func (p *Provider) do(ctx context.Context, httpReq *http.Request) (*http.Response, error) {
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	for k, v := range p.headers {
+		httpReq.Header.Set(k, v)
+	}
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	providers.ObserveRateLimits(ctx, p.Name(), resp.StatusCode, resp.Header)
+	return resp, nil
 }
 
 // toAPIRequest converts provider-agnostic request to OpenAI API format.
@@ -119,7 +202,6 @@ func (p *Provider) toAPIRequest(req providers.CompletionRequest) apiRequest {
 	apiReq := apiRequest{
 		Model:             req.Model,
 		Instructions:      req.SystemPrompt,
-		Temperature:       req.Temperature,
 		MaxOutputTokens:   req.MaxTokens,
 		TopP:              req.TopP,
 		Stream:            req.Stream,
@@ -127,6 +209,14 @@ func (p *Provider) toAPIRequest(req providers.CompletionRequest) apiRequest {
 		Store:             req.Store,
 		Metadata:          req.Metadata,
 		ToolChoice:        req.ToolChoice,
+		PreviousResponseID: req.PreviousResponseID,
+	}
+
+	// Zero is omitted unless the caller asked for greedy sampling, since
+	// OpenAI's default temperature is not zero. The Responses API has no seed.
+	if req.Temperature != 0 || req.Deterministic {
+		temperature := req.Temperature
+		apiReq.Temperature = &temperature
 	}
 
 	// Convert messages to input
@@ -135,8 +225,8 @@ func (p *Provider) toAPIRequest(req providers.CompletionRequest) apiRequest {
 	}
 
 	// Convert tools
-	if len(req.Tools) > 0 {
-		apiReq.Tools = p.toAPITools(req.Tools)
+	if len(req.Tools) > 0 || len(req.HostedTools) > 0 {
+		apiReq.Tools = p.toAPITools(req.Tools, req.HostedTools)
 	}
 
 	// Convert reasoning effort
@@ -158,6 +248,12 @@ func (p *Provider) toAPIRequest(req providers.CompletionRequest) apiRequest {
 			Verbosity: req.TextVerbosity,
 		}
 	}
+	if schema := req.OutputSchema; schema != nil {
+		if apiReq.Text == nil {
+			apiReq.Text = &textConfig{}
+		}
+		apiReq.Text.Format = &textFormat{Type: "json_schema", Name: schema.Name, Schema: schema.Schema, Strict: schema.Strict}
+	}
 
 	return apiReq
 }
@@ -195,6 +291,24 @@ func (p *Provider) toAPIInput(messages []providers.Message) []any {
 			})
 		}
 
+		if msg.Role != providers.RoleAssistant {
+			for _, image := range msg.Images {
+				contentItems = append(contentItems, contentItem{
+					Type:     "input_image",
+					ImageURL: image.URL,
+					Detail:   image.Detail,
+				})
+			}
+			for _, file := range msg.Files {
+				item := contentItem{Type: "input_file", FileID: file.ID}
+				if file.ID == "" {
+					item.FileData = file.DataURL()
+					item.Filename = file.Name
+				}
+				contentItems = append(contentItems, item)
+			}
+		}
+
 		if len(contentItems) > 0 {
 			in.Content = contentItems
 			inputs = append(inputs, in)
@@ -221,17 +335,20 @@ func (p *Provider) toAPIInput(messages []providers.Message) []any {
 	return inputs
 }
 
-// toAPITools converts tools to OpenAI tool format.
-func (p *Provider) toAPITools(tools []providers.ToolDefinition) []tool {
-	apiTools := make([]tool, len(tools))
-	for i, t := range tools {
-		apiTools[i] = tool{
+// toAPITools converts function and hosted tools to OpenAI tool format.
+func (p *Provider) toAPITools(tools []providers.ToolDefinition, hosted []providers.HostedTool) []any {
+	apiTools := make([]any, 0, len(tools)+len(hosted))
+	for _, t := range tools {
+		apiTools = append(apiTools, tool{
 			Type:        "function",
 			Name:        t.Name,
 			Description: t.Description,
 			Parameters:  t.Parameters,
 			Strict:      true, // Enable structured outputs
-		}
+		})
+	}
+	for _, t := range hosted {
+		apiTools = append(apiTools, hostedAPITool(t))
 	}
 	return apiTools
 }
@@ -260,6 +377,7 @@ func (p *Provider) fromAPIResponse(resp *responseObject) *providers.CompletionRe
 					domainResp.Content += content.Text
 				}
 			}
+			domainResp.Annotations = append(domainResp.Annotations, extractAnnotationsFromItem(item)...)
 		case "reasoning":
 			if summary := extractSummaryTextFromItem(item); summary != "" {
 				if domainResp.ReasoningSummary != "" {
@@ -270,16 +388,28 @@ func (p *Provider) fromAPIResponse(resp *responseObject) *providers.CompletionRe
 		case "function_call":
 			// Parse tool call
 			var args map[string]any
+			var raw string
 			if item.Arguments != "" {
-				json.Unmarshal([]byte(item.Arguments), &args)
+				repaired, err := jsonrepair.Unmarshal(resp.Model, []byte(item.Arguments), &args)
+				if repaired {
+					p.logger.Warn("malformed tool arguments", "tool", item.Name, "model", resp.Model, "repaired", err == nil)
+				}
+				if err != nil {
+					raw = item.Arguments
+				}
 			}
 			if item.CallID != "" {
 				domainResp.ToolCalls = append(domainResp.ToolCalls, providers.ToolCall{
-					ID:        item.CallID,
-					Name:      item.Name,
-					Arguments: args,
+					ID:           item.CallID,
+					Name:         item.Name,
+					Arguments:    args,
+					RawArguments: raw,
 				})
 			}
+		default:
+			if call := hostedToolCallFromItem(item); call != nil {
+				domainResp.HostedToolCalls = append(domainResp.HostedToolCalls, *call)
+			}
 		}
 	}
 
@@ -311,6 +441,7 @@ type streamReader struct {
 	pending            []*providers.StreamChunk
 	textDeltaSource    string
 	summaryDeltaSource string
+	annotationSource   string
 }
 
 const (
@@ -323,6 +454,10 @@ const (
 	summarySourceContent   = "summary_part"
 	summarySourceOutput    = "output_item"
 	summarySourceResponse  = "response_done"
+
+	annotationSourceStream   = "annotation_added"
+	annotationSourceOutput   = "output_item"
+	annotationSourceResponse = "response_done"
 )
 
 type toolCall struct {
@@ -358,6 +493,13 @@ func (s *streamReader) acceptSummarySource(source string) bool {
 	return s.summaryDeltaSource == source
 }
 
+func (s *streamReader) acceptAnnotationSource(source string) bool {
+	if s.annotationSource == "" {
+		s.annotationSource = source
+	}
+	return s.annotationSource == source
+}
+
 func (s *streamReader) Next() (*providers.StreamChunk, error) {
 	for {
 		// Try to parse from buffer first
@@ -449,17 +591,35 @@ func (s *streamReader) parseNextChunk() *providers.StreamChunk {
 			text = apiChunk.Delta
 		}
 		return s.emitTextFinal(text)
+	case "response.output_text.annotation.added":
+		if apiChunk.Annotation == nil || !s.acceptAnnotationSource(annotationSourceStream) {
+			return nil
+		}
+		return &providers.StreamChunk{
+			Annotations: []providers.Annotation{toDomainAnnotation(*apiChunk.Annotation)},
+		}
 	case "response.output_item.done":
 		if apiChunk.Item != nil {
 			if apiChunk.Item.Type == "message" {
-				if s.textDeltaSource != "" {
-					return nil
+				var annotations []providers.Annotation
+				if found := extractAnnotationsFromItem(*apiChunk.Item); len(found) > 0 && s.acceptAnnotationSource(annotationSourceOutput) {
+					annotations = found
+				}
+				var chunk *providers.StreamChunk
+				if s.textDeltaSource == "" {
+					text := extractOutputTextFromItem(*apiChunk.Item)
+					if text != "" {
+						s.textDeltaSource = textSourceOutputItem
+					}
+					chunk = s.emitTextFinal(text)
 				}
-				text := extractOutputTextFromItem(*apiChunk.Item)
-				if text != "" {
-					s.textDeltaSource = textSourceOutputItem
+				if len(annotations) > 0 {
+					if chunk == nil {
+						chunk = &providers.StreamChunk{}
+					}
+					chunk.Annotations = annotations
 				}
-				return s.emitTextFinal(text)
+				return chunk
 			}
 			if apiChunk.Item.Type == "reasoning" {
 				if s.summaryDeltaSource != "" {
@@ -477,6 +637,9 @@ func (s *streamReader) parseNextChunk() *providers.StreamChunk {
 				}
 				return nil
 			}
+			if call := hostedToolCallFromItem(*apiChunk.Item); call != nil {
+				return &providers.StreamChunk{HostedToolCall: call}
+			}
 			if s.textDeltaSource != "" {
 				return nil
 			}
@@ -491,6 +654,11 @@ func (s *streamReader) parseNextChunk() *providers.StreamChunk {
 			_ = s.storeToolCallFromItem(*apiChunk.Item, false)
 			return nil
 		}
+		if apiChunk.Item != nil {
+			if call := hostedToolCallFromItem(*apiChunk.Item); call != nil {
+				return &providers.StreamChunk{HostedToolCall: call}
+			}
+		}
 	case "response.content_part.delta":
 		if apiChunk.Part != nil {
 			if apiChunk.Part.Type == "output_text" {
@@ -525,11 +693,13 @@ func (s *streamReader) parseNextChunk() *providers.StreamChunk {
 	case "response.function_call_arguments.delta":
 		tc := s.ensureToolCall(apiChunk.CallID, apiChunk.ItemID, apiChunk.OutputIndex)
 		tc.Arguments += apiChunk.Delta
-		if apiChunk.CallID == "" {
+		if tc.CallID == "" || apiChunk.Delta == "" {
 			return nil
 		}
 		return &providers.StreamChunk{
-			ToolCallID: apiChunk.CallID,
+			ToolCallID:    tc.CallID,
+			ToolName:      tc.Name,
+			ToolArgsDelta: apiChunk.Delta,
 		}
 
 	case "response.function_call_arguments.done":
@@ -550,6 +720,10 @@ func (s *streamReader) parseNextChunk() *providers.StreamChunk {
 		chunk := &providers.StreamChunk{
 			IsComplete:   true,
 			FinishReason: providers.FinishReasonStop,
+			ResponseID:   s.responseID,
+		}
+		if apiChunk.Response != nil && apiChunk.Response.ID != "" {
+			chunk.ResponseID = apiChunk.Response.ID
 		}
 		if apiChunk.Usage != nil {
 			chunk.Usage = &providers.TokenUsage{
@@ -582,6 +756,12 @@ func (s *streamReader) parseNextChunk() *providers.StreamChunk {
 					}
 				}
 			}
+			if s.annotationSource == "" {
+				if annotations := extractAnnotationsFromResponse(apiChunk.Response); len(annotations) > 0 {
+					s.annotationSource = annotationSourceResponse
+					chunk.Annotations = annotations
+				}
+			}
 			if s.textDeltaSource == "" {
 				if delta := s.emitTextFinal(extractOutputTextFromResponse(apiChunk.Response)); delta != nil {
 					s.textDeltaSource = textSourceResponseDone
@@ -593,6 +773,15 @@ func (s *streamReader) parseNextChunk() *providers.StreamChunk {
 		next := s.pending[0]
 		s.pending = s.pending[1:]
 		return next
+
+	default:
+		if toolType, status, ok := hostedToolStatus(apiChunk.Type); ok && apiChunk.ItemID != "" {
+			return &providers.StreamChunk{HostedToolCall: &providers.HostedToolCall{
+				ID:     apiChunk.ItemID,
+				Type:   toolType,
+				Status: status,
+			}}
+		}
 	}
 
 	return nil
@@ -793,6 +982,47 @@ func extractOutputTextFromItem(item outputItem) string {
 	return extractOutputText(item.Content)
 }
 
+func extractAnnotationsFromItem(item outputItem) []providers.Annotation {
+	if item.Type != "message" {
+		return nil
+	}
+	var annotations []providers.Annotation
+	for _, content := range item.Content {
+		if content.Type != "output_text" {
+			continue
+		}
+		for _, ann := range content.Annotations {
+			annotations = append(annotations, toDomainAnnotation(ann))
+		}
+	}
+	return annotations
+}
+
+func extractAnnotationsFromResponse(resp *responseObject) []providers.Annotation {
+	if resp == nil {
+		return nil
+	}
+	var annotations []providers.Annotation
+	for _, item := range resp.Output {
+		annotations = append(annotations, extractAnnotationsFromItem(item)...)
+	}
+	return annotations
+}
+
+func toDomainAnnotation(ann annotation) providers.Annotation {
+	return providers.Annotation{
+		Type:        providers.AnnotationType(ann.Type),
+		StartIndex:  ann.StartIndex,
+		EndIndex:    ann.EndIndex,
+		URL:         ann.URL,
+		Title:       ann.Title,
+		FileID:      ann.FileID,
+		Filename:    ann.Filename,
+		ContainerID: ann.ContainerID,
+		Index:       ann.Index,
+	}
+}
+
 func extractSummaryTextFromItem(item outputItem) string {
 	if item.Type != "reasoning" {
 		return ""
@@ -871,9 +1101,9 @@ type apiRequest struct {
 	Model             string            `json:"model"`
 	Instructions      string            `json:"instructions,omitempty"`
 	Input             any               `json:"input,omitempty"`
-	Tools             []tool            `json:"tools,omitempty"`
+	Tools             []any             `json:"tools,omitempty"`
 	ToolChoice        string            `json:"tool_choice,omitempty"`
-	Temperature       float32           `json:"temperature,omitempty"`
+	Temperature       *float32          `json:"temperature,omitempty"`
 	MaxOutputTokens   int               `json:"max_output_tokens,omitempty"`
 	TopP              float32           `json:"top_p,omitempty"`
 	Stream            bool              `json:"stream,omitempty"`
@@ -882,6 +1112,7 @@ type apiRequest struct {
 	Text              *textConfig       `json:"text,omitempty"`
 	Store             bool              `json:"store,omitempty"`
 	Metadata          map[string]string `json:"metadata,omitempty"`
+	PreviousResponseID string           `json:"previous_response_id,omitempty"`
 }
 
 type input struct {
@@ -903,10 +1134,28 @@ type functionCallInput struct {
 }
 
 type contentItem struct {
-	Type    string `json:"type"`
-	Text    string `json:"text,omitempty"`
-	CallID  string `json:"call_id,omitempty"`
-	Content string `json:"content,omitempty"`
+	Type        string       `json:"type"`
+	Text        string       `json:"text,omitempty"`
+	CallID      string       `json:"call_id,omitempty"`
+	Content     string       `json:"content,omitempty"`
+	ImageURL    string       `json:"image_url,omitempty"`
+	Detail      string       `json:"detail,omitempty"`
+	FileID      string       `json:"file_id,omitempty"`
+	FileData    string       `json:"file_data,omitempty"`
+	Filename    string       `json:"filename,omitempty"`
+	Annotations []annotation `json:"annotations,omitempty"`
+}
+
+type annotation struct {
+	Type        string `json:"type"`
+	StartIndex  int    `json:"start_index,omitempty"`
+	EndIndex    int    `json:"end_index,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Title       string `json:"title,omitempty"`
+	FileID      string `json:"file_id,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContainerID string `json:"container_id,omitempty"`
+	Index       int    `json:"index,omitempty"`
 }
 
 type tool struct {
@@ -928,7 +1177,10 @@ type textConfig struct {
 }
 
 type textFormat struct {
-	Type string `json:"type"`
+	Type   string         `json:"type"`
+	Name   string         `json:"name,omitempty"`
+	Schema map[string]any `json:"schema,omitempty"`
+	Strict bool           `json:"strict,omitempty"`
 }
 
 type responseObject struct {
@@ -952,6 +1204,9 @@ type outputItem struct {
 	Role      string        `json:"role,omitempty"`
 	Content   []contentItem `json:"content,omitempty"`
 	Summary   []contentItem `json:"summary,omitempty"`
+
+	// details holds a hosted tool item's own fields (see UnmarshalJSON).
+	details map[string]any
 }
 
 type usage struct {
@@ -975,6 +1230,7 @@ type streamChunk struct {
 	Response    *responseObject `json:"response,omitempty"`
 	Item        *outputItem     `json:"item,omitempty"`
 	Part        *contentItem    `json:"part,omitempty"`
+	Annotation  *annotation     `json:"annotation,omitempty"`
 }
 
 type apiError struct {
@@ -996,5 +1252,11 @@ func parseAPIError(statusCode int, body []byte) error {
 	if errResp.Error.Code != nil {
 		msg += fmt.Sprintf(" (code: %v)", errResp.Error.Code)
 	}
+	switch errResp.Error.Code {
+	case "context_length_exceeded":
+		return fmt.Errorf("%w: %s", providers.ErrContextLengthExceeded, msg)
+	case "previous_response_not_found":
+		return fmt.Errorf("%w: %s", providers.ErrPreviousResponseNotFound, msg)
+	}
 	return fmt.Errorf("%s", msg)
 }
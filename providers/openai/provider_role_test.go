@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/darkostanimirovic/agentkit/providers"
@@ -32,3 +33,55 @@ func TestToAPIInput_ToolRoleMappedToUser(t *testing.T) {
 		t.Fatalf("expected tool output 'tool output', got '%s'", item.Output)
 	}
 }
+
+func TestToAPIRequest_Temperature(t *testing.T) {
+	p := New("test", nil)
+	if req := p.toAPIRequest(providers.CompletionRequest{Model: "gpt-4o"}); req.Temperature != nil {
+		t.Errorf("temperature = %v, want omitted", *req.Temperature)
+	}
+	req := p.toAPIRequest(providers.CompletionRequest{Model: "gpt-4o", Deterministic: true})
+	if req.Temperature == nil || *req.Temperature != 0 {
+		t.Errorf("temperature = %v, want explicit 0 in deterministic mode", req.Temperature)
+	}
+	if req := p.toAPIRequest(providers.CompletionRequest{Model: "gpt-4o", Temperature: 0.3}); req.Temperature == nil || *req.Temperature != 0.3 {
+		t.Errorf("temperature = %v, want 0.3", req.Temperature)
+	}
+}
+
+func TestToAPIInput_Images(t *testing.T) {
+	p := New("test", nil)
+	inputs := p.toAPIInput([]providers.Message{{
+		Role:    providers.RoleUser,
+		Content: "what is this?",
+		Images:  []providers.Image{{URL: "data:image/png;base64,AAAA", Detail: "low"}},
+	}})
+	item, ok := inputs[0].(input)
+	if !ok || len(item.Content) != 2 {
+		t.Fatalf("inputs = %+v", inputs)
+	}
+	if image := item.Content[1]; image.Type != "input_image" || image.ImageURL != "data:image/png;base64,AAAA" || image.Detail != "low" {
+		t.Errorf("image item = %+v", image)
+	}
+}
+
+func TestToAPIInput_DeveloperRole(t *testing.T) {
+	p := New("test", nil)
+	inputs := p.toAPIInput([]providers.Message{{Role: providers.RoleDeveloper, Content: "rules"}})
+	item, ok := inputs[0].(input)
+	if !ok || item.Role != "developer" || item.Content[0].Type != "input_text" {
+		t.Errorf("inputs = %+v", inputs)
+	}
+}
+
+func TestToAPIRequest_OutputSchema(t *testing.T) {
+	p := New("test", nil)
+	req := p.toAPIRequest(providers.CompletionRequest{
+		Model:         "gpt-4o",
+		TextVerbosity: "low",
+		OutputSchema:  &providers.OutputSchema{Name: "answer", Schema: map[string]any{"type": "object"}, Strict: true},
+	})
+	data, _ := json.Marshal(req.Text)
+	if want := `{"format":{"type":"json_schema","name":"answer","schema":{"type":"object"},"strict":true},"verbosity":"low"}`; string(data) != want {
+		t.Errorf("text = %s\nwant %s", data, want)
+	}
+}
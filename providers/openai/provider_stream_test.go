@@ -4,6 +4,8 @@ import (
 	"io"
 	"strings"
 	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
 )
 
 func TestStreamReaderOutputItemDoneProvidesContent(t *testing.T) {
@@ -200,3 +202,74 @@ data: [DONE]
 		t.Fatalf("expected summary to avoid duplication, got %q", got)
 	}
 }
+
+func TestStreamReaderAnnotationAddedEmitsCitations(t *testing.T) {
+	sseData := `data: {"type":"response.output_text.delta","delta":"Go 1.24 shipped."}
+
+data: {"type":"response.output_text.annotation.added","annotation":{"type":"url_citation","start_index":0,"end_index":16,"url":"https://go.dev/doc/go1.24","title":"Go 1.24 Release Notes"}}
+
+data: {"type":"response.output_item.done","output_index":0,"item":{"type":"message","id":"msg_001","status":"completed","role":"assistant","content":[{"type":"output_text","text":"Go 1.24 shipped.","annotations":[{"type":"url_citation","start_index":0,"end_index":16,"url":"https://go.dev/doc/go1.24","title":"Go 1.24 Release Notes"}]}]}}
+
+data: {"type":"response.done","response_id":"resp_123","output":[]}
+
+`
+
+	reader := newStreamReader(io.NopCloser(strings.NewReader(sseData)), nil)
+
+	var annotations []providers.Annotation
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream read error: %v", err)
+		}
+		annotations = append(annotations, chunk.Annotations...)
+	}
+
+	if len(annotations) != 1 {
+		t.Fatalf("expected 1 annotation (no duplicates from output_item.done), got %d", len(annotations))
+	}
+	ann := annotations[0]
+	if ann.Type != providers.AnnotationTypeURLCitation {
+		t.Errorf("expected url_citation, got %q", ann.Type)
+	}
+	if ann.URL != "https://go.dev/doc/go1.24" || ann.Title != "Go 1.24 Release Notes" {
+		t.Errorf("unexpected citation: %+v", ann)
+	}
+	if ann.EndIndex != 16 {
+		t.Errorf("expected end_index 16, got %d", ann.EndIndex)
+	}
+}
+
+func TestStreamReaderOutputItemDoneProvidesAnnotations(t *testing.T) {
+	sseData := `data: {"type":"response.output_item.done","output_index":0,"item":{"type":"message","id":"msg_001","status":"completed","role":"assistant","content":[{"type":"output_text","text":"See the report.","annotations":[{"type":"file_citation","index":8,"file_id":"file_abc","filename":"report.pdf"}]}]}}
+
+data: {"type":"response.done","response_id":"resp_123","output":[]}
+
+`
+
+	reader := newStreamReader(io.NopCloser(strings.NewReader(sseData)), nil)
+
+	var annotations []providers.Annotation
+	var content strings.Builder
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("stream read error: %v", err)
+		}
+		content.WriteString(chunk.Content)
+		annotations = append(annotations, chunk.Annotations...)
+	}
+
+	if content.String() != "See the report." {
+		t.Fatalf("expected content alongside annotations, got %q", content.String())
+	}
+	if len(annotations) != 1 || annotations[0].FileID != "file_abc" || annotations[0].Filename != "report.pdf" {
+		t.Fatalf("unexpected annotations: %+v", annotations)
+	}
+}
@@ -0,0 +1,43 @@
+package openai
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamReaderEmitsToolArgsDeltas(t *testing.T) {
+	sse := "data: {\"type\":\"response.output_item.added\",\"item\":{\"type\":\"function_call\",\"id\":\"fc_1\",\"call_id\":\"call_1\",\"name\":\"search\"}}\n\n" +
+		"data: {\"type\":\"response.function_call_arguments.delta\",\"item_id\":\"fc_1\",\"delta\":\"{\\\"query\\\":\"}\n\n" +
+		"data: {\"type\":\"response.function_call_arguments.delta\",\"item_id\":\"fc_1\",\"delta\":\"\\\"go\\\"}\"}\n\n" +
+		"data: {\"type\":\"response.function_call_arguments.done\",\"item_id\":\"fc_1\",\"arguments\":\"{\\\"query\\\":\\\"go\\\"}\"}\n\n" +
+		"data: {\"type\":\"response.done\"}\n\n"
+	reader := newStreamReader(io.NopCloser(strings.NewReader(sse)), nil)
+	defer reader.Close()
+
+	var deltas []string
+	for i := 0; i < 2; i++ {
+		chunk, err := reader.Next()
+		if err != nil {
+			t.Fatalf("expected delta chunk, got error: %v", err)
+		}
+		if chunk.ToolCallID != "call_1" || chunk.ToolName != "search" {
+			t.Fatalf("expected delta for call_1/search, got id=%q name=%q", chunk.ToolCallID, chunk.ToolName)
+		}
+		if chunk.ToolArgs != "" {
+			t.Fatalf("expected no complete args on delta chunk, got %q", chunk.ToolArgs)
+		}
+		deltas = append(deltas, chunk.ToolArgsDelta)
+	}
+	if got := strings.Join(deltas, ""); got != "{\"query\":\"go\"}" {
+		t.Fatalf("expected deltas to concatenate to arguments, got %q", got)
+	}
+
+	chunk, err := reader.Next()
+	if err != nil {
+		t.Fatalf("expected tool chunk, got error: %v", err)
+	}
+	if chunk.ToolArgs != "{\"query\":\"go\"}" || chunk.ToolArgsDelta != "" {
+		t.Fatalf("expected complete tool args, got args=%q delta=%q", chunk.ToolArgs, chunk.ToolArgsDelta)
+	}
+}
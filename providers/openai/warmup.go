@@ -0,0 +1,32 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WarmUp implements providers.Warmer by listing models. Servers without a
+// models endpoint still leave a warm connection behind; only rejected
+// credentials are reported.
+func (p *Provider) WarmUp(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/models", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := p.do(ctx, httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection returns to the pool.
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return parseAPIError(resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package openai
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+func TestProvider_WarmUp(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer key":
+			_, _ = w.Write([]byte(`{"object": "list", "data": []}`))
+		case "Bearer no-models":
+			http.NotFound(w, r)
+		default:
+			http.Error(w, `{"error": {"message": "Incorrect API key", "type": "invalid_request_error"}}`, http.StatusUnauthorized)
+		}
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	p := New("key", nil).WithBaseURL(server.URL)
+	var warmer providers.Warmer = p
+	if err := warmer.WarmUp(context.Background()); err != nil {
+		t.Fatalf("WarmUp() error = %v", err)
+	}
+	if err := warmer.WarmUp(context.Background()); err != nil {
+		t.Fatalf("WarmUp() error = %v", err)
+	}
+	if n := connections.Load(); n != 1 {
+		t.Errorf("connections = %d, want the warm connection reused", n)
+	}
+
+	if err := New("no-models", nil).WithBaseURL(server.URL).WarmUp(context.Background()); err != nil {
+		t.Errorf("WarmUp() without a models endpoint: %v", err)
+	}
+	if err := New("wrong", nil).WithBaseURL(server.URL).WarmUp(context.Background()); err == nil {
+		t.Error("WarmUp() accepted a rejected key")
+	}
+}
@@ -3,6 +3,8 @@ package providers
 
 import (
 	"context"
+	"encoding/base64"
+	"strings"
 	"time"
 )
 
@@ -33,6 +35,8 @@ type CompletionRequest struct {
 	Model             string
 	Messages          []Message
 	Tools             []ToolDefinition
+	// HostedTools are run by the provider itself (see HostedTool).
+	HostedTools       []HostedTool
 	Temperature       float32
 	MaxTokens         int
 	SystemPrompt      string
@@ -44,8 +48,23 @@ type CompletionRequest struct {
 	ReasoningSummary  string
 	TextVerbosity     string
 	TextFormat        string
+	// OutputSchema constrains the final answer to a JSON schema on
+	// providers with structured outputs; it takes precedence over TextFormat.
+	OutputSchema *OutputSchema
 	Store             bool
+	// PreviousResponseID continues a response stored by the provider
+	// (OpenAI Responses API); Messages then hold only what came after it.
+	PreviousResponseID string
 	Metadata          map[string]string
+	// Deterministic asks for greedy, reproducible sampling: providers send
+	// Temperature even when it is zero instead of using their default.
+	Deterministic bool
+	// Seed pins sampling on providers that support a seed; nil leaves it
+	// to the provider.
+	Seed *int64
+	// Audio asks for a spoken reply alongside the text on providers with
+	// audio output; nil requests text only.
+	Audio *AudioOutput
 }
 
 // CompletionResponse represents a provider-agnostic completion response.
@@ -54,6 +73,12 @@ type CompletionResponse struct {
 	Content      string
 	ToolCalls    []ToolCall
 	ReasoningSummary string
+	Annotations  []Annotation
+	// HostedToolCalls reports the hosted tools the provider ran.
+	HostedToolCalls []HostedToolCall
+	// Audio is the spoken reply requested with CompletionRequest.Audio;
+	// Content holds its transcript.
+	Audio []byte
 	FinishReason FinishReason
 	Usage        TokenUsage
 	Model        string
@@ -66,8 +91,73 @@ type Message struct {
 	Role       MessageRole
 	Content    string
 	ToolCalls  []ToolCall
-	ToolCallID string // For tool result messages
-	Name       string // Optional name
+	ToolCallID string  // For tool result messages
+	Name       string  // Optional name
+	Images     []Image // Image inputs sent with user messages
+	Files      []File  // File inputs (PDFs, CSVs) sent with user messages
+	Audio      []Audio // Audio inputs sent with user messages
+}
+
+// Audio is an audio input in Format: "wav" or "mp3" for the OpenAI Chat
+// Completions API, "pcm16" (24 kHz mono) for realtime sessions.
+type Audio struct {
+	Data   []byte
+	Format string
+}
+
+// AudioOutput configures a spoken reply.
+type AudioOutput struct {
+	Voice  string // e.g. "alloy"
+	Format string // "pcm16", "wav", "mp3", "flac" or "opus"; streaming needs "pcm16"
+}
+
+// Image is an image input. URL is an https URL or a base64 data URL
+// ("data:image/png;base64,...").
+type Image struct {
+	URL    string
+	Detail string // "low", "high" or "auto"; empty uses the provider default
+}
+
+// File is a document input such as a PDF or CSV: either a file uploaded
+// with the provider's FileUploader (ID) or inline Data.
+type File struct {
+	ID        string
+	Name      string // File name shown to the model, e.g. "invoice.pdf"
+	MediaType string // e.g. "application/pdf" or "text/csv"
+	Data      []byte
+}
+
+// DataURL returns the inline data as a base64 data URL.
+func (f File) DataURL() string {
+	return "data:" + f.MediaType + ";base64," + base64.StdEncoding.EncodeToString(f.Data)
+}
+
+// IsTextMediaType reports whether files of mediaType are plain text, such as
+// CSV, JSON or Markdown, which providers without document support can read
+// inline.
+func IsTextMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	switch mediaType {
+	case "application/json", "application/xml", "application/x-ndjson", "application/yaml":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "text/")
+}
+
+// FileUploader is implemented by providers with a files API. Agents upload
+// large attachments once instead of inlining them in every request of a
+// run, and delete them when the run ends.
+type FileUploader interface {
+	// UploadFile stores a file and returns its ID for File.ID.
+	UploadFile(ctx context.Context, name, mediaType string, data []byte) (string, error)
+	DeleteFile(ctx context.Context, id string) error
+}
+
+// Warmer is implemented by providers that can prepare for a fast first
+// request: WarmUp opens a pooled connection to the API, so the request skips
+// DNS, TCP and TLS setup, and reports rejected credentials.
+type Warmer interface {
+	WarmUp(ctx context.Context) error
 }
 
 // MessageRole defines the role of a message sender.
@@ -75,16 +165,41 @@ type MessageRole string
 
 const (
 	RoleSystem    MessageRole = "system"
+	RoleDeveloper MessageRole = "developer" // Per-request instructions, ranked below the system prompt
 	RoleUser      MessageRole = "user"
 	RoleAssistant MessageRole = "assistant"
 	RoleTool      MessageRole = "tool"
 )
 
+// FoldDeveloperMessages moves developer messages into the system prompt, for
+// providers without a developer role. Each is appended to systemPrompt as
+// its own paragraph, in order.
+func FoldDeveloperMessages(systemPrompt string, messages []Message) (string, []Message) {
+	var parts []string
+	if systemPrompt != "" {
+		parts = append(parts, systemPrompt)
+	}
+	rest := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == RoleDeveloper {
+			if msg.Content != "" {
+				parts = append(parts, msg.Content)
+			}
+			continue
+		}
+		rest = append(rest, msg)
+	}
+	return strings.Join(parts, "\n\n"), rest
+}
+
 // ToolCall represents a request to execute a tool.
 type ToolCall struct {
 	ID        string
 	Name      string
 	Arguments map[string]any
+	// RawArguments holds the arguments as the model sent them when they
+	// could not be parsed, even after repair; Arguments is then nil.
+	RawArguments string
 }
 
 // ToolDefinition defines a tool that can be called by the agent.
@@ -94,6 +209,14 @@ type ToolDefinition struct {
 	Parameters  map[string]any
 }
 
+// ToolDefinitionReuser is implemented by providers whose stored responses
+// keep the tool definitions they were created with. A request continuing
+// such a response (PreviousResponseID) may then leave Tools empty when they
+// are unchanged. The OpenAI Responses API does not keep tools.
+type ToolDefinitionReuser interface {
+	ReusesToolDefinitions() bool
+}
+
 // FinishReason indicates why the model stopped generating.
 type FinishReason string
 
@@ -119,9 +242,53 @@ type StreamChunk struct {
 	ToolCallID   string
 	ToolName     string
 	ToolArgs     string
+	// ToolArgsDelta carries a partial arguments fragment while a tool call is
+	// still streaming. ToolArgs holds the complete arguments once available.
+	ToolArgsDelta string
+	Annotations  []Annotation
 	IsComplete   bool
 	FinishReason FinishReason
 	Usage        *TokenUsage
+	// ResponseID identifies the stored response on the completing chunk,
+	// for providers that keep response state.
+	ResponseID string
+	// HostedToolCall reports a hosted tool's progress.
+	HostedToolCall *HostedToolCall
+	// Audio is a fragment of the spoken reply, in the requested format.
+	Audio []byte
+}
+
+// AnnotationType identifies the kind of citation attached to output text.
+type AnnotationType string
+
+const (
+	AnnotationTypeURLCitation   AnnotationType = "url_citation"
+	AnnotationTypeFileCitation  AnnotationType = "file_citation"
+	AnnotationTypeContainerFile AnnotationType = "container_file_citation"
+	AnnotationTypeFilePath      AnnotationType = "file_path"
+)
+
+// Annotation is a citation attached to a span of output text, typically produced
+// by hosted tools such as web search, file search, or code interpreter.
+type Annotation struct {
+	Type        AnnotationType `json:"type"`
+	StartIndex  int            `json:"start_index,omitempty"`
+	EndIndex    int            `json:"end_index,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Title       string         `json:"title,omitempty"`
+	FileID      string         `json:"file_id,omitempty"`
+	Filename    string         `json:"filename,omitempty"`
+	ContainerID string         `json:"container_id,omitempty"`
+	Index       int            `json:"index,omitempty"`
+}
+
+// OutputSchema is a JSON schema the model's text output must match.
+type OutputSchema struct {
+	Name   string // Identifies the schema to the provider, e.g. "ticket_summary"
+	Schema map[string]any
+	// Strict asks the provider to enforce the schema exactly. OpenAI then
+	// requires every property to be required and additionalProperties false.
+	Strict bool
 }
 
 // ReasoningEffort controls compute for reasoning models.
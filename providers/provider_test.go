@@ -0,0 +1,21 @@
+package providers
+
+import "testing"
+
+func TestFoldDeveloperMessages(t *testing.T) {
+	prompt, messages := FoldDeveloperMessages("Be brief.", []Message{
+		{Role: RoleDeveloper, Content: "Refunds need approval."},
+		{Role: RoleUser, Content: "hi"},
+		{Role: RoleDeveloper, Content: "Reply in German."},
+	})
+	if prompt != "Be brief.\n\nRefunds need approval.\n\nReply in German." {
+		t.Errorf("prompt = %q", prompt)
+	}
+	if len(messages) != 1 || messages[0].Role != RoleUser {
+		t.Errorf("messages = %+v", messages)
+	}
+
+	if prompt, _ := FoldDeveloperMessages("", []Message{{Role: RoleDeveloper, Content: "Rules."}}); prompt != "Rules." {
+		t.Errorf("prompt without system prompt = %q", prompt)
+	}
+}
@@ -0,0 +1,104 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimits is a provider's report of the remaining request and token quota,
+// as sent in response headers. Zero limits mean the provider did not report them.
+type RateLimits struct {
+	Provider          string
+	Model             string
+	LimitRequests     int
+	RemainingRequests int
+	ResetRequests     time.Duration
+	LimitTokens       int
+	RemainingTokens   int
+	ResetTokens       time.Duration
+	// StatusCode is the HTTP status of the response carrying the headers.
+	StatusCode int
+	ObservedAt time.Time
+}
+
+// ParseRateLimitHeaders reads OpenAI-style x-ratelimit-* headers (also used
+// by Azure OpenAI, Groq and others) and Anthropic's anthropic-ratelimit-*
+// headers. It reports false when no limits were present.
+func ParseRateLimitHeaders(h http.Header) (RateLimits, bool) {
+	limits := RateLimits{ObservedAt: time.Now()}
+	found := false
+	intHeader := func(dst *int, names ...string) {
+		for _, name := range names {
+			if v, err := strconv.Atoi(strings.TrimSpace(h.Get(name))); err == nil {
+				*dst, found = v, true
+				return
+			}
+		}
+	}
+	durationHeader := func(dst *time.Duration, names ...string) {
+		for _, name := range names {
+			if d, ok := parseReset(h.Get(name), limits.ObservedAt); ok {
+				*dst = d
+				return
+			}
+		}
+	}
+
+	intHeader(&limits.LimitRequests, "x-ratelimit-limit-requests", "anthropic-ratelimit-requests-limit")
+	intHeader(&limits.RemainingRequests, "x-ratelimit-remaining-requests", "anthropic-ratelimit-requests-remaining")
+	intHeader(&limits.LimitTokens, "x-ratelimit-limit-tokens", "anthropic-ratelimit-tokens-limit")
+	intHeader(&limits.RemainingTokens, "x-ratelimit-remaining-tokens", "anthropic-ratelimit-tokens-remaining")
+	durationHeader(&limits.ResetRequests, "x-ratelimit-reset-requests", "anthropic-ratelimit-requests-reset")
+	durationHeader(&limits.ResetTokens, "x-ratelimit-reset-tokens", "anthropic-ratelimit-tokens-reset")
+	return limits, found
+}
+
+// parseReset accepts durations ("6m0s", "20ms"), seconds ("1.5") and RFC 3339
+// timestamps, which are converted to the time remaining from now.
+func parseReset(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return d, true
+	}
+	if secs, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+type rateLimitObserverKey struct{}
+
+// RateLimitObserver receives rate limits reported during a provider call.
+type RateLimitObserver func(limits RateLimits)
+
+// WithRateLimitObserver returns a context whose provider calls report their
+// rate-limit headers to observer.
+func WithRateLimitObserver(ctx context.Context, observer RateLimitObserver) context.Context {
+	return context.WithValue(ctx, rateLimitObserverKey{}, observer)
+}
+
+// ObserveRateLimits parses h and reports the limits to the observer in ctx,
+// if any. Provider implementations call it for every HTTP response,
+// including errors such as 429.
+func ObserveRateLimits(ctx context.Context, provider string, statusCode int, h http.Header) {
+	observer, ok := ctx.Value(rateLimitObserverKey{}).(RateLimitObserver)
+	if !ok || observer == nil {
+		return
+	}
+	limits, found := ParseRateLimitHeaders(h)
+	if !found && statusCode != http.StatusTooManyRequests {
+		return
+	}
+	limits.Provider = provider
+	limits.StatusCode = statusCode
+	observer(limits)
+}
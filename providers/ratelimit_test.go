@@ -0,0 +1,55 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("x-ratelimit-limit-requests", "60")
+	h.Set("x-ratelimit-remaining-requests", "59")
+	h.Set("x-ratelimit-reset-requests", "1s")
+	h.Set("x-ratelimit-limit-tokens", "150000")
+	h.Set("x-ratelimit-remaining-tokens", "149984")
+	h.Set("x-ratelimit-reset-tokens", "6m0s")
+
+	limits, ok := ParseRateLimitHeaders(h)
+	if !ok {
+		t.Fatal("no limits found")
+	}
+	if limits.LimitRequests != 60 || limits.RemainingRequests != 59 || limits.ResetRequests != time.Second ||
+		limits.LimitTokens != 150000 || limits.RemainingTokens != 149984 || limits.ResetTokens != 6*time.Minute {
+		t.Fatalf("limits = %+v", limits)
+	}
+
+	if _, ok := ParseRateLimitHeaders(http.Header{}); ok {
+		t.Fatal("found limits in empty headers")
+	}
+}
+
+func TestParseRateLimitHeaders_Anthropic(t *testing.T) {
+	h := http.Header{}
+	h.Set("anthropic-ratelimit-tokens-limit", "80000")
+	h.Set("anthropic-ratelimit-tokens-remaining", "100")
+	h.Set("anthropic-ratelimit-tokens-reset", time.Now().Add(time.Minute).UTC().Format(time.RFC3339))
+
+	limits, _ := ParseRateLimitHeaders(h)
+	if limits.LimitTokens != 80000 || limits.RemainingTokens != 100 || limits.ResetTokens <= 0 || limits.ResetTokens > time.Minute {
+		t.Fatalf("limits = %+v", limits)
+	}
+}
+
+func TestObserveRateLimits(t *testing.T) {
+	var got []RateLimits
+	ctx := WithRateLimitObserver(context.Background(), func(l RateLimits) { got = append(got, l) })
+
+	ObserveRateLimits(context.Background(), "openai", http.StatusOK, http.Header{"X-Ratelimit-Limit-Requests": {"1"}})
+	ObserveRateLimits(ctx, "openai", http.StatusOK, http.Header{})
+	ObserveRateLimits(ctx, "openai", http.StatusTooManyRequests, http.Header{})
+	if len(got) != 1 || got[0].StatusCode != http.StatusTooManyRequests || got[0].Provider != "openai" {
+		t.Fatalf("observed = %+v", got)
+	}
+}
@@ -0,0 +1,437 @@
+// Package realtime is an experimental provider for the OpenAI Realtime API,
+// for voice agents built on agentkit's tool, approval and tracing
+// infrastructure.
+//
+// Every completion is one response on its own WebSocket session: the
+// session is configured with the request's instructions, tools and audio
+// settings, the conversation is replayed as items, and the response's text,
+// audio and function calls stream back as chunks. The agent loop is the one
+// other providers use, so tool calls run locally between responses.
+//
+//	provider := realtime.New(os.Getenv("OPENAI_API_KEY"), logger)
+//	agent, _ := agentkit.New(agentkit.WithProvider(provider), agentkit.WithModel(realtime.DefaultModel))
+//	events := agent.RunAudio(ctx, agentkit.AudioInput{Data: pcm, Format: "pcm16"})
+//
+// Sessions are not kept across completions, so server-side voice activity
+// detection and interruptions are not supported. Audio input must be pcm16
+// (24 kHz mono) or G.711; images and files are not sent.
+package realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/internal/websocket"
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Defaults for New.
+const (
+	DefaultBaseURL = "wss://api.openai.com/v1/realtime"
+	DefaultModel   = "gpt-4o-realtime-preview"
+)
+
+// Provider implements providers.Provider over Realtime API sessions.
+type Provider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	logger     *slog.Logger
+}
+
+// New creates a realtime provider.
+func New(apiKey string, logger *slog.Logger) *Provider {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Provider{
+		apiKey:     apiKey,
+		baseURL:    DefaultBaseURL,
+		httpClient: &http.Client{},
+		logger:     logger,
+	}
+}
+
+// WithBaseURL points the provider at another Realtime endpoint, e.g. an
+// Azure OpenAI deployment or a proxy.
+func (p *Provider) WithBaseURL(baseURL string) *Provider {
+	if baseURL != "" {
+		p.baseURL = strings.TrimRight(baseURL, "/")
+	}
+	return p
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "openai-realtime"
+}
+
+// Complete runs a response and collects its stream.
+func (p *Provider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	stream, err := p.Stream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	resp := &providers.CompletionResponse{Model: req.Model}
+	var content strings.Builder
+	calls := make(map[string]int)
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content.WriteString(chunk.Content)
+		resp.Audio = append(resp.Audio, chunk.Audio...)
+		if chunk.ToolCallID != "" && chunk.ToolArgs != "" {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(chunk.ToolArgs), &args); err != nil {
+				p.logger.Warn("malformed tool arguments", "tool", chunk.ToolName, "error", err)
+			}
+			if i, ok := calls[chunk.ToolCallID]; ok {
+				resp.ToolCalls[i].Arguments = args
+			} else {
+				calls[chunk.ToolCallID] = len(resp.ToolCalls)
+				resp.ToolCalls = append(resp.ToolCalls, providers.ToolCall{ID: chunk.ToolCallID, Name: chunk.ToolName, Arguments: args})
+			}
+		}
+		if chunk.IsComplete {
+			resp.ID = chunk.ResponseID
+			resp.FinishReason = chunk.FinishReason
+			if chunk.Usage != nil {
+				resp.Usage = *chunk.Usage
+			}
+		}
+	}
+	resp.Content = content.String()
+	return resp, nil
+}
+
+// Stream opens a session, sends the request and streams the response.
+func (p *Provider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	model := req.Model
+	if model == "" {
+		model = DefaultModel
+	}
+	header := http.Header{"OpenAI-Beta": {"realtime=v1"}}
+	if p.apiKey != "" {
+		header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	conn, err := websocket.Dial(ctx, p.httpClient, p.baseURL+"?model="+url.QueryEscape(model), header)
+	if err != nil {
+		return nil, fmt.Errorf("realtime: connect: %w", err)
+	}
+	// Closing the connection unblocks a pending read when ctx ends.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	for _, event := range p.clientEvents(req) {
+		data, err := json.Marshal(event)
+		if err == nil {
+			err = conn.WriteMessage(data)
+		}
+		if err != nil {
+			stop()
+			conn.Close()
+			return nil, fmt.Errorf("realtime: send %s: %w", event["type"], err)
+		}
+	}
+	return &streamReader{ctx: ctx, conn: conn, stop: stop, logger: p.logger, names: make(map[string]string)}, nil
+}
+
+// clientEvents configures the session, replays the conversation and asks
+// for a response.
+func (p *Provider) clientEvents(req providers.CompletionRequest) []map[string]any {
+	instructions, messages := providers.FoldDeveloperMessages(req.SystemPrompt, req.Messages)
+	session := map[string]any{
+		"instructions": instructions,
+		"modalities":   []string{"text"},
+		"tools":        toTools(req.Tools),
+		"tool_choice":  toToolChoice(req.ToolChoice),
+	}
+	if audio := req.Audio; audio != nil {
+		session["modalities"] = []string{"text", "audio"}
+		if audio.Voice != "" {
+			session["voice"] = audio.Voice
+		}
+		if format := audioFormat(audio.Format); format != "" {
+			session["output_audio_format"] = format
+		} else if audio.Format != "" {
+			p.logger.Warn("unsupported realtime output format; using pcm16", "format", audio.Format)
+		}
+	}
+	if req.Temperature != 0 {
+		session["temperature"] = req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		session["max_response_output_tokens"] = req.MaxTokens
+	}
+
+	for _, msg := range messages {
+		for _, audio := range msg.Audio {
+			format := audioFormat(audio.Format)
+			if format == "" {
+				p.logger.Warn("unsupported realtime input format; sending as pcm16", "format", audio.Format)
+				format = "pcm16"
+			}
+			session["input_audio_format"] = format
+		}
+	}
+
+	events := []map[string]any{{"type": "session.update", "session": session}}
+	for _, msg := range messages {
+		for _, item := range p.toItems(msg) {
+			events = append(events, map[string]any{"type": "conversation.item.create", "item": item})
+		}
+	}
+	return append(events, map[string]any{"type": "response.create"})
+}
+
+// toItems converts a message to conversation items.
+func (p *Provider) toItems(msg providers.Message) []map[string]any {
+	switch {
+	case msg.ToolCallID != "" || msg.Role == providers.RoleTool:
+		return []map[string]any{{"type": "function_call_output", "call_id": msg.ToolCallID, "output": msg.Content}}
+	case msg.Role == providers.RoleAssistant:
+		var items []map[string]any
+		if msg.Content != "" {
+			items = append(items, map[string]any{
+				"type":    "message",
+				"role":    "assistant",
+				"content": []map[string]any{{"type": "text", "text": msg.Content}},
+			})
+		}
+		for _, call := range msg.ToolCalls {
+			args := "{}"
+			if call.Arguments != nil {
+				if data, err := json.Marshal(call.Arguments); err == nil {
+					args = string(data)
+				}
+			}
+			items = append(items, map[string]any{"type": "function_call", "call_id": call.ID, "name": call.Name, "arguments": args})
+		}
+		return items
+	}
+
+	role := "user"
+	if msg.Role == providers.RoleSystem {
+		role = "system"
+	}
+	if len(msg.Images) > 0 || len(msg.Files) > 0 {
+		p.logger.Warn("realtime sessions take no images or files; dropping them", "images", len(msg.Images), "files", len(msg.Files))
+	}
+	var content []map[string]any
+	if msg.Content != "" {
+		content = append(content, map[string]any{"type": "input_text", "text": msg.Content})
+	}
+	for _, audio := range msg.Audio {
+		content = append(content, map[string]any{"type": "input_audio", "audio": base64.StdEncoding.EncodeToString(audio.Data)})
+	}
+	return []map[string]any{{"type": "message", "role": role, "content": content}}
+}
+
+// audioFormat maps a format to its realtime name, or "" when sessions do
+// not support it.
+func audioFormat(format string) string {
+	switch strings.ToLower(format) {
+	case "", "pcm16", "pcm":
+		return "pcm16"
+	case "g711_ulaw", "ulaw":
+		return "g711_ulaw"
+	case "g711_alaw", "alaw":
+		return "g711_alaw"
+	}
+	return ""
+}
+
+func toTools(defs []providers.ToolDefinition) []map[string]any {
+	tools := make([]map[string]any, 0, len(defs))
+	for _, def := range defs {
+		tools = append(tools, map[string]any{
+			"type":        "function",
+			"name":        def.Name,
+			"description": def.Description,
+			"parameters":  def.Parameters,
+		})
+	}
+	return tools
+}
+
+func toToolChoice(choice string) any {
+	switch choice {
+	case "", "auto":
+		return "auto"
+	case "none", "required":
+		return choice
+	}
+	return map[string]any{"type": "function", "name": choice}
+}
+
+// streamReader turns server events into stream chunks until response.done.
+type streamReader struct {
+	ctx    context.Context
+	conn   *websocket.Conn
+	stop   func() bool
+	logger *slog.Logger
+	names  map[string]string // function call names by call ID
+	calls  int
+	done   bool
+}
+
+type serverEvent struct {
+	Type      string `json:"type"`
+	Delta     string `json:"delta"`
+	CallID    string `json:"call_id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Item      struct {
+		Type   string `json:"type"`
+		CallID string `json:"call_id"`
+		Name   string `json:"name"`
+	} `json:"item"`
+	Error *struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Response struct {
+		ID            string `json:"id"`
+		Status        string `json:"status"`
+		StatusDetails *struct {
+			Reason string `json:"reason"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"status_details"`
+		Usage *struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	} `json:"response"`
+}
+
+// Next returns the next chunk, or io.EOF after the completion chunk.
+func (s *streamReader) Next() (*providers.StreamChunk, error) {
+	for {
+		if s.done {
+			return nil, io.EOF
+		}
+		data, err := s.conn.ReadMessage()
+		if err != nil {
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("realtime: session closed before the response finished")
+			}
+			return nil, fmt.Errorf("realtime: read: %w", err)
+		}
+		var event serverEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			s.logger.Error("failed to parse realtime event", "error", err)
+			continue
+		}
+		chunk, err := s.handle(event)
+		if err != nil {
+			return nil, err
+		}
+		if chunk != nil {
+			return chunk, nil
+		}
+	}
+}
+
+// handle maps a server event to a chunk, or nil for events without one.
+// Both the beta and the GA event names are accepted.
+func (s *streamReader) handle(event serverEvent) (*providers.StreamChunk, error) {
+	switch event.Type {
+	case "error":
+		if event.Error == nil {
+			return nil, errors.New("realtime: server error")
+		}
+		err := fmt.Errorf("realtime: %s", event.Error.Message)
+		if event.Error.Code == "context_length_exceeded" {
+			err = fmt.Errorf("%w: %s", providers.ErrContextLengthExceeded, err)
+		}
+		return nil, err
+	case "response.text.delta", "response.output_text.delta",
+		"response.audio_transcript.delta", "response.output_audio_transcript.delta":
+		if event.Delta == "" {
+			return nil, nil
+		}
+		return &providers.StreamChunk{Content: event.Delta}, nil
+	case "response.audio.delta", "response.output_audio.delta":
+		audio, err := base64.StdEncoding.DecodeString(event.Delta)
+		if err != nil {
+			s.logger.Error("failed to decode audio delta", "error", err)
+			return nil, nil
+		}
+		return &providers.StreamChunk{Audio: audio}, nil
+	case "response.output_item.added":
+		if event.Item.Type != "function_call" {
+			return nil, nil
+		}
+		s.names[event.Item.CallID] = event.Item.Name
+		return &providers.StreamChunk{ToolCallID: event.Item.CallID, ToolName: event.Item.Name}, nil
+	case "response.function_call_arguments.delta":
+		return &providers.StreamChunk{ToolCallID: event.CallID, ToolName: s.names[event.CallID], ToolArgsDelta: event.Delta}, nil
+	case "response.function_call_arguments.done":
+		s.calls++
+		name := event.Name
+		if name == "" {
+			name = s.names[event.CallID]
+		}
+		args := event.Arguments
+		if args == "" {
+			args = "{}"
+		}
+		return &providers.StreamChunk{ToolCallID: event.CallID, ToolName: name, ToolArgs: args}, nil
+	case "response.done":
+		return s.complete(event)
+	}
+	return nil, nil
+}
+
+func (s *streamReader) complete(event serverEvent) (*providers.StreamChunk, error) {
+	s.done = true
+	resp := event.Response
+	if resp.Status == "failed" {
+		msg := "response failed"
+		if details := resp.StatusDetails; details != nil && details.Error != nil {
+			msg = details.Error.Message
+		}
+		return nil, fmt.Errorf("realtime: %s", msg)
+	}
+	chunk := &providers.StreamChunk{IsComplete: true, ResponseID: resp.ID, FinishReason: providers.FinishReasonStop}
+	switch {
+	case s.calls > 0:
+		chunk.FinishReason = providers.FinishReasonToolCalls
+	case resp.Status == "incomplete" && resp.StatusDetails != nil && resp.StatusDetails.Reason == "max_output_tokens":
+		chunk.FinishReason = providers.FinishReasonLength
+	}
+	if usage := resp.Usage; usage != nil {
+		chunk.Usage = &providers.TokenUsage{
+			PromptTokens:     usage.InputTokens,
+			CompletionTokens: usage.OutputTokens,
+			TotalTokens:      usage.TotalTokens,
+		}
+	}
+	return chunk, nil
+}
+
+// Close ends the session.
+func (s *streamReader) Close() error {
+	s.stop()
+	return s.conn.Close()
+}
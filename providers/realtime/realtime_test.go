@@ -0,0 +1,195 @@
+package realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/internal/websocket"
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// fakeServer answers every session with script once the client asks for a
+// response, and records what the client sent.
+type fakeServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	model  string
+	events []map[string]any
+}
+
+func newFakeServer(t *testing.T, script ...string) *fakeServer {
+	t.Helper()
+	s := &fakeServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer key" || r.Header.Get("OpenAI-Beta") != "realtime=v1" {
+			http.Error(w, `{"error":{"message":"bad auth"}}`, http.StatusUnauthorized)
+			return
+		}
+		conn, err := websocket.Accept(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s.mu.Lock()
+		s.model = r.URL.Query().Get("model")
+		s.mu.Unlock()
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var event map[string]any
+			_ = json.Unmarshal(data, &event)
+			s.mu.Lock()
+			s.events = append(s.events, event)
+			s.mu.Unlock()
+			if event["type"] == "response.create" {
+				break
+			}
+		}
+		for _, event := range script {
+			if err := conn.WriteMessage([]byte(event)); err != nil {
+				return
+			}
+		}
+		_, _ = conn.ReadMessage() // until the client closes
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) provider() *Provider {
+	return New("key", nil).WithBaseURL("ws://" + strings.TrimPrefix(s.URL, "http://"))
+}
+
+func (s *fakeServer) sent() []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.events
+}
+
+func TestStream_ToolCall(t *testing.T) {
+	server := newFakeServer(t,
+		`{"type":"session.created"}`,
+		`{"type":"response.output_item.added","item":{"type":"function_call","call_id":"call_1","name":"get_weather"}}`,
+		`{"type":"response.function_call_arguments.delta","call_id":"call_1","delta":"{\"city\":"}`,
+		`{"type":"response.function_call_arguments.done","call_id":"call_1","name":"get_weather","arguments":"{\"city\":\"Paris\"}"}`,
+		`{"type":"response.done","response":{"id":"resp_1","status":"completed","usage":{"input_tokens":12,"output_tokens":5,"total_tokens":17}}}`,
+	)
+	stream, err := server.provider().Stream(context.Background(), providers.CompletionRequest{
+		SystemPrompt: "You are a weather assistant.",
+		Messages: []providers.Message{
+			{Role: providers.RoleUser, Content: "Weather in Paris?"},
+			{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{{ID: "call_0", Name: "get_time", Arguments: map[string]any{}}}},
+			{Role: providers.RoleTool, ToolCallID: "call_0", Content: "noon"},
+		},
+		Tools: []providers.ToolDefinition{{Name: "get_weather", Description: "Current weather", Parameters: map[string]any{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	defer stream.Close()
+
+	var chunks []*providers.StreamChunk
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("got %d chunks: %+v", len(chunks), chunks)
+	}
+	if chunks[0].ToolName != "get_weather" || chunks[1].ToolArgsDelta != `{"city":` || chunks[2].ToolArgs != `{"city":"Paris"}` {
+		t.Errorf("tool call chunks = %+v %+v %+v", chunks[0], chunks[1], chunks[2])
+	}
+	done := chunks[3]
+	if !done.IsComplete || done.FinishReason != providers.FinishReasonToolCalls || done.ResponseID != "resp_1" || done.Usage.TotalTokens != 17 {
+		t.Errorf("completion chunk = %+v", done)
+	}
+
+	sent := server.sent()
+	var types []string
+	for _, event := range sent {
+		types = append(types, event["type"].(string))
+	}
+	want := "session.update,conversation.item.create,conversation.item.create,conversation.item.create,response.create"
+	if strings.Join(types, ",") != want {
+		t.Errorf("client events = %v", types)
+	}
+	session := sent[0]["session"].(map[string]any)
+	if session["instructions"] != "You are a weather assistant." || len(session["tools"].([]any)) != 1 {
+		t.Errorf("session = %v", session)
+	}
+	if item := sent[2]["item"].(map[string]any); item["type"] != "function_call" || item["call_id"] != "call_0" {
+		t.Errorf("assistant item = %v", item)
+	}
+	if item := sent[3]["item"].(map[string]any); item["type"] != "function_call_output" || item["output"] != "noon" {
+		t.Errorf("tool item = %v", item)
+	}
+	if server.model != DefaultModel {
+		t.Errorf("model = %q", server.model)
+	}
+}
+
+func TestComplete_AudioReply(t *testing.T) {
+	pcm := []byte{1, 2, 3, 4}
+	server := newFakeServer(t,
+		`{"type":"response.audio_transcript.delta","delta":"Bon"}`,
+		`{"type":"response.audio.delta","delta":"`+base64.StdEncoding.EncodeToString(pcm[:2])+`"}`,
+		`{"type":"response.output_audio_transcript.delta","delta":"jour"}`,
+		`{"type":"response.output_audio.delta","delta":"`+base64.StdEncoding.EncodeToString(pcm[2:])+`"}`,
+		`{"type":"response.done","response":{"id":"resp_2","status":"completed"}}`,
+	)
+	resp, err := server.provider().Complete(context.Background(), providers.CompletionRequest{
+		Model:    "gpt-realtime",
+		Messages: []providers.Message{{Role: providers.RoleUser, Audio: []providers.Audio{{Data: []byte("voice"), Format: "pcm16"}}}},
+		Audio:    &providers.AudioOutput{Voice: "verse", Format: "pcm16"},
+	})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if resp.Content != "Bonjour" || string(resp.Audio) != string(pcm) || resp.FinishReason != providers.FinishReasonStop {
+		t.Errorf("response = %+v", resp)
+	}
+
+	sent := server.sent()
+	session := sent[0]["session"].(map[string]any)
+	if session["voice"] != "verse" || session["input_audio_format"] != "pcm16" || len(session["modalities"].([]any)) != 2 {
+		t.Errorf("session = %v", session)
+	}
+	content := sent[1]["item"].(map[string]any)["content"].([]any)
+	if part := content[0].(map[string]any); part["type"] != "input_audio" || part["audio"] != base64.StdEncoding.EncodeToString([]byte("voice")) {
+		t.Errorf("audio item = %v", part)
+	}
+}
+
+func TestStream_Errors(t *testing.T) {
+	server := newFakeServer(t, `{"type":"error","error":{"code":"context_length_exceeded","message":"too long"}}`)
+	_, err := server.provider().Complete(context.Background(), providers.CompletionRequest{})
+	if !errors.Is(err, providers.ErrContextLengthExceeded) {
+		t.Errorf("err = %v, want ErrContextLengthExceeded", err)
+	}
+
+	_, err = New("wrong", nil).WithBaseURL(server.provider().baseURL).Stream(context.Background(), providers.CompletionRequest{})
+	if status, ok := providers.StatusCode(err); !ok || status != http.StatusUnauthorized {
+		t.Errorf("err = %v, want status 401", err)
+	}
+
+	failed := newFakeServer(t, `{"type":"response.done","response":{"status":"failed","status_details":{"error":{"message":"server overloaded"}}}}`)
+	if _, err := failed.provider().Complete(context.Background(), providers.CompletionRequest{}); err == nil || !strings.Contains(err.Error(), "server overloaded") {
+		t.Errorf("err = %v, want the failure message", err)
+	}
+}
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Transport defaults. http.DefaultTransport keeps only 2 idle connections
+// per host, so an agent server with more concurrent model calls than that
+// closes and redials connections constantly.
+const (
+	DefaultMaxIdleConns        = 256
+	DefaultMaxIdleConnsPerHost = 64
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultDialTimeout         = 30 * time.Second
+	DefaultKeepAlive           = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultHealthCheckInterval = 30 * time.Second
+)
+
+// TransportConfig tunes the HTTP transport of provider clients. Zero fields
+// take the defaults above.
+type TransportConfig struct {
+	MaxIdleConns        int           // Idle connections kept across all hosts
+	MaxIdleConnsPerHost int           // Idle connections kept per host; set it near the expected concurrent calls
+	MaxConnsPerHost     int           // Caps connections per host, queueing requests beyond it (0: unlimited)
+	IdleConnTimeout     time.Duration // How long an idle connection is kept
+	DialTimeout         time.Duration
+	KeepAlive           time.Duration // TCP keep-alive probe interval
+	TLSHandshakeTimeout time.Duration
+	// HealthCheckInterval is how long an HTTP/2 connection may go without
+	// frames before it is pinged; dead connections then fail fast instead
+	// of stalling streams.
+	HealthCheckInterval time.Duration
+	DisableHTTP2        bool // Use only HTTP/1.1, e.g. for proxies that mishandle HTTP/2
+}
+
+// NewTransport returns a pooled transport with keep-alives and HTTP/2,
+// honoring proxy environment variables like http.DefaultTransport.
+func NewTransport(cfg TransportConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   orDefault(cfg.DialTimeout, DefaultDialTimeout),
+		KeepAlive: orDefault(cfg.KeepAlive, DefaultKeepAlive),
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          orDefault(cfg.MaxIdleConns, DefaultMaxIdleConns),
+		MaxIdleConnsPerHost:   orDefault(cfg.MaxIdleConnsPerHost, DefaultMaxIdleConnsPerHost),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       orDefault(cfg.IdleConnTimeout, DefaultIdleConnTimeout),
+		TLSHandshakeTimeout:   orDefault(cfg.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout),
+		ExpectContinueTimeout: time.Second,
+	}
+	if cfg.DisableHTTP2 {
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP1(true)
+		return transport
+	}
+	transport.ForceAttemptHTTP2 = true
+	transport.HTTP2 = &http.HTTP2Config{
+		SendPingTimeout: orDefault(cfg.HealthCheckInterval, DefaultHealthCheckInterval),
+	}
+	return transport
+}
+
+// NewHTTPClient returns a client using NewTransport(cfg). It sets no
+// overall timeout, since streamed completions can run for minutes; bound
+// requests with their context instead.
+func NewHTTPClient(cfg TransportConfig) *http.Client {
+	return &http.Client{Transport: NewTransport(cfg)}
+}
+
+// DefaultHTTPClient returns the client the built-in providers share unless
+// given their own, so agents created per request still reuse connections.
+func DefaultHTTPClient() *http.Client {
+	return defaultHTTPClient()
+}
+
+var defaultHTTPClient = sync.OnceValue(func() *http.Client {
+	return NewHTTPClient(TransportConfig{})
+})
+
+func orDefault[T int | time.Duration](value, fallback T) T {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingServer answers with a small completion-sized body over HTTP/1.1,
+// with or without TLS, and counts the connections opened to it.
+func countingServer(tb testing.TB, tls bool) (*httptest.Server, *atomic.Int64) {
+	tb.Helper()
+	var connections atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"id":"resp_1","output":[{"type":"message","content":[{"type":"output_text","text":"ok"}]}]}`)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	if tls {
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	tb.Cleanup(server.Close)
+	return server, &connections
+}
+
+func get(tb testing.TB, client *http.Client, url string) {
+	resp, err := client.Get(url)
+	if err != nil {
+		tb.Error(err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+func TestNewTransport_Defaults(t *testing.T) {
+	transport := NewTransport(TransportConfig{MaxIdleConnsPerHost: 16})
+	if transport.MaxIdleConnsPerHost != 16 || transport.MaxIdleConns != DefaultMaxIdleConns || transport.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Errorf("pool = %d/%d idle %v", transport.MaxIdleConnsPerHost, transport.MaxIdleConns, transport.IdleConnTimeout)
+	}
+	if !transport.ForceAttemptHTTP2 || transport.HTTP2 == nil || transport.HTTP2.SendPingTimeout != DefaultHealthCheckInterval {
+		t.Errorf("HTTP/2 not configured: %v %+v", transport.ForceAttemptHTTP2, transport.HTTP2)
+	}
+	if transport.Proxy == nil {
+		t.Error("proxy environment ignored")
+	}
+
+	h1 := NewTransport(TransportConfig{DisableHTTP2: true})
+	if h1.ForceAttemptHTTP2 || h1.Protocols == nil || h1.Protocols.HTTP2() || !h1.Protocols.HTTP1() {
+		t.Errorf("DisableHTTP2 transport protocols = %v", h1.Protocols)
+	}
+	if DefaultHTTPClient() != DefaultHTTPClient() {
+		t.Error("DefaultHTTPClient is not shared")
+	}
+}
+
+func TestNewHTTPClient_ReusesConnectionsUnderLoad(t *testing.T) {
+	const concurrency, rounds = 16, 5
+	server, connections := countingServer(t, false)
+	client := NewHTTPClient(TransportConfig{})
+
+	for range rounds {
+		var wg sync.WaitGroup
+		for range concurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				get(t, client, server.URL)
+			}()
+		}
+		wg.Wait()
+	}
+	if n := connections.Load(); n > concurrency {
+		t.Errorf("opened %d connections for %d concurrent requests", n, concurrency)
+	}
+}
+
+func TestNewHTTPClient_HTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	transport := NewTransport(TransportConfig{})
+	transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	resp, err := (&http.Client{Transport: transport, Timeout: 5 * time.Second}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("protocol = %s, want HTTP/2", resp.Proto)
+	}
+}
+
+// BenchmarkHTTPClient compares http.DefaultTransport, which keeps 2 idle
+// connections per host, with NewTransport. Each op is a burst of 32
+// concurrent requests to one HTTPS host speaking HTTP/1.1, the shape of an
+// agent server fanning out tool-loop calls to a self-hosted or proxied model
+// API:
+//
+//	go test ./providers -run '^$' -bench HTTPClient
+//
+// Between bursts the default transport closes all but 2 connections, so
+// every burst redials and repeats TLS handshakes. See the README for
+// results.
+func BenchmarkHTTPClient(b *testing.B) {
+	const burst = 32
+	clients := map[string]func() *http.Transport{
+		"default": func() *http.Transport { return http.DefaultTransport.(*http.Transport).Clone() },
+		"tuned":   func() *http.Transport { return NewTransport(TransportConfig{}) },
+	}
+	for _, name := range []string{"default", "tuned"} {
+		b.Run(name, func(b *testing.B) {
+			server, connections := countingServer(b, true)
+			transport := clients[name]()
+			transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+			client := &http.Client{Transport: transport}
+			b.ResetTimer()
+			for range b.N {
+				var wg sync.WaitGroup
+				for range burst {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						get(b, client, server.URL)
+					}()
+				}
+				wg.Wait()
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(connections.Load())/float64(b.N), "dials/op")
+			client.CloseIdleConnections()
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package agentkit
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// QuotaResource names a rate-limited resource.
+type QuotaResource string
+
+const (
+	QuotaRequests QuotaResource = "requests"
+	QuotaTokens   QuotaResource = "tokens"
+)
+
+// DefaultQuotaThresholds warn when 20% and then 5% of a quota remain.
+var DefaultQuotaThresholds = []float64{0.2, 0.05}
+
+// QuotaAlert reports that the remaining quota of a resource fell to or below
+// a threshold. Threshold is 0 when the provider answered 429.
+type QuotaAlert struct {
+	Provider  string
+	Model     string
+	Resource  QuotaResource
+	Remaining int
+	Limit     int
+	Threshold float64
+	ResetIn   time.Duration
+}
+
+// QuotaConfig configures a QuotaMonitor.
+type QuotaConfig struct {
+	// Thresholds are remaining-quota fractions that trigger alerts
+	// (default DefaultQuotaThresholds).
+	Thresholds []float64
+	// OnAlert is called for each alert, in addition to the quota.warning
+	// event emitted to the run.
+	OnAlert func(alert QuotaAlert)
+}
+
+// QuotaMonitor tracks provider-reported rate limits per provider and model
+// and alerts once per threshold as quota runs down. Alerts re-arm when the
+// quota recovers above the highest threshold. Share one monitor between
+// agents using the same API key. It is safe for concurrent use.
+type QuotaMonitor struct {
+	mu         sync.Mutex
+	thresholds []float64 // descending
+	onAlert    func(QuotaAlert)
+	limits     map[string]providers.RateLimits
+	fired      map[string]float64 // lowest threshold alerted per provider/model/resource
+}
+
+// NewQuotaMonitor creates a quota monitor.
+func NewQuotaMonitor(cfg QuotaConfig) *QuotaMonitor {
+	thresholds := cfg.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = DefaultQuotaThresholds
+	}
+	thresholds = slices.Clone(thresholds)
+	slices.Sort(thresholds)
+	slices.Reverse(thresholds)
+	return &QuotaMonitor{
+		thresholds: thresholds,
+		onAlert:    cfg.OnAlert,
+		limits:     make(map[string]providers.RateLimits),
+		fired:      make(map[string]float64),
+	}
+}
+
+// Observe records limits and returns the alerts they trigger.
+func (m *QuotaMonitor) Observe(limits providers.RateLimits) []QuotaAlert {
+	m.mu.Lock()
+	key := limits.Provider + "/" + limits.Model
+	m.limits[key] = limits
+
+	var alerts []QuotaAlert
+	check := func(resource QuotaResource, remaining, limit int, reset time.Duration) {
+		if limit <= 0 {
+			return
+		}
+		alert := QuotaAlert{
+			Provider:  limits.Provider,
+			Model:     limits.Model,
+			Resource:  resource,
+			Remaining: remaining,
+			Limit:     limit,
+			ResetIn:   reset,
+		}
+		if a, ok := m.crossed(key+"/"+string(resource), float64(remaining)/float64(limit), alert); ok {
+			alerts = append(alerts, a)
+		}
+	}
+	check(QuotaRequests, limits.RemainingRequests, limits.LimitRequests, limits.ResetRequests)
+	check(QuotaTokens, limits.RemainingTokens, limits.LimitTokens, limits.ResetTokens)
+
+	if limits.StatusCode == http.StatusTooManyRequests && len(alerts) == 0 {
+		alerts = append(alerts, QuotaAlert{
+			Provider: limits.Provider,
+			Model:    limits.Model,
+			Resource: QuotaRequests,
+			Limit:    limits.LimitRequests,
+			ResetIn:  max(limits.ResetRequests, limits.ResetTokens),
+		})
+	}
+	m.mu.Unlock()
+
+	if m.onAlert != nil {
+		for _, alert := range alerts {
+			m.onAlert(alert)
+		}
+	}
+	return alerts
+}
+
+// crossed reports the lowest threshold at or above fraction, if it has not
+// alerted yet. Callers hold m.mu.
+func (m *QuotaMonitor) crossed(key string, fraction float64, alert QuotaAlert) (QuotaAlert, bool) {
+	if fraction > m.thresholds[0] {
+		delete(m.fired, key)
+		return alert, false
+	}
+	threshold := m.thresholds[0]
+	for _, t := range m.thresholds {
+		if fraction <= t {
+			threshold = t
+		}
+	}
+	if last, ok := m.fired[key]; ok && last <= threshold {
+		return alert, false
+	}
+	m.fired[key] = threshold
+	alert.Threshold = threshold
+	return alert, true
+}
+
+// Limits returns the last limits reported for provider and model.
+func (m *QuotaMonitor) Limits(provider, model string) (providers.RateLimits, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	limits, ok := m.limits[provider+"/"+model]
+	return limits, ok
+}
+
+// Snapshot returns the last reported limits of every provider and model.
+func (m *QuotaMonitor) Snapshot() []providers.RateLimits {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]providers.RateLimits, 0, len(m.limits))
+	for _, limits := range m.limits {
+		out = append(out, limits)
+	}
+	slices.SortFunc(out, func(a, b providers.RateLimits) int {
+		if a.Provider != b.Provider {
+			if a.Provider < b.Provider {
+				return -1
+			}
+			return 1
+		}
+		if a.Model < b.Model {
+			return -1
+		}
+		if a.Model > b.Model {
+			return 1
+		}
+		return 0
+	})
+	return out
+}
+
+// observeQuota routes rate limits reported during a provider call to the
+// agent's quota monitor, emitting quota.warning events for new alerts.
+func (a *Agent) observeQuota(ctx context.Context, model string, events chan<- Event) context.Context {
+	if a.quota == nil {
+		return ctx
+	}
+	return providers.WithRateLimitObserver(ctx, func(limits providers.RateLimits) {
+		limits.Model = model
+		for _, alert := range a.quota.Observe(limits) {
+			a.logger.Warn("provider quota running low",
+				"provider", alert.Provider,
+				"model", alert.Model,
+				"resource", alert.Resource,
+				"remaining", alert.Remaining,
+				"limit", alert.Limit)
+			a.emit(ctx, events, QuotaWarning(alert))
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package agentkit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// headerProvider reports fixed rate-limit headers on every call.
+type headerProvider struct {
+	*mockprovider.Provider
+	header http.Header
+}
+
+func (p *headerProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	providers.ObserveRateLimits(ctx, p.Name(), http.StatusOK, p.header)
+	return p.Provider.Complete(ctx, req)
+}
+
+func (p *headerProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	providers.ObserveRateLimits(ctx, p.Name(), http.StatusOK, p.header)
+	return p.Provider.Stream(ctx, req)
+}
+
+func TestQuotaMonitor_AlertsOncePerThreshold(t *testing.T) {
+	var alerts []QuotaAlert
+	m := NewQuotaMonitor(QuotaConfig{OnAlert: func(a QuotaAlert) { alerts = append(alerts, a) }})
+	observe := func(remaining int) []QuotaAlert {
+		return m.Observe(providers.RateLimits{Provider: "openai", Model: "gpt", LimitRequests: 100, RemainingRequests: remaining})
+	}
+
+	if got := observe(50); len(got) != 0 {
+		t.Fatalf("alerts at 50%% = %+v", got)
+	}
+	if got := observe(20); len(got) != 1 || got[0].Threshold != 0.2 || got[0].Resource != QuotaRequests {
+		t.Fatalf("alerts at 20%% = %+v", got)
+	}
+	if got := observe(10); len(got) != 0 {
+		t.Fatalf("repeated alert at 10%% = %+v", got)
+	}
+	if got := observe(3); len(got) != 1 || got[0].Threshold != 0.05 {
+		t.Fatalf("alerts at 3%% = %+v", got)
+	}
+	// Recovering above the highest threshold re-arms the alerts.
+	observe(90)
+	if got := observe(15); len(got) != 1 || got[0].Threshold != 0.2 {
+		t.Fatalf("alerts after reset = %+v", got)
+	}
+	if len(alerts) != 3 {
+		t.Fatalf("OnAlert calls = %d, want 3", len(alerts))
+	}
+	if limits, ok := m.Limits("openai", "gpt"); !ok || limits.RemainingRequests != 15 {
+		t.Fatalf("Limits() = %+v, %v", limits, ok)
+	}
+}
+
+func TestQuotaMonitor_RateLimitedResponse(t *testing.T) {
+	m := NewQuotaMonitor(QuotaConfig{})
+	alerts := m.Observe(providers.RateLimits{Provider: "openai", StatusCode: http.StatusTooManyRequests, ResetTokens: time.Second})
+	if len(alerts) != 1 || alerts[0].Threshold != 0 || alerts[0].ResetIn != time.Second {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+}
+
+func TestAgent_EmitsQuotaWarning(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-tokens", "10000")
+	header.Set("x-ratelimit-remaining-tokens", "400")
+	header.Set("x-ratelimit-reset-tokens", "6m0s")
+	provider := &headerProvider{Provider: mockprovider.New().WithResponse("ok", nil), header: header}
+
+	monitor := NewQuotaMonitor(QuotaConfig{})
+	agent, err := New(Config{Provider: provider, Model: "test-model", Quota: monitor})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	events := collectEvents(agent.Run(context.Background(), "hi"), 2*time.Second)
+
+	var warning *Event
+	for i := range events {
+		if events[i].Type == EventTypeQuotaWarning {
+			warning = &events[i]
+		}
+	}
+	if warning == nil {
+		t.Fatal("no quota.warning event")
+	}
+	if warning.Data["resource"] != "tokens" || warning.Data["remaining"] != 400 || warning.Data["threshold"] != 0.05 ||
+		warning.Data["model"] != "test-model" || warning.Data["reset_ms"] != int64(360000) {
+		t.Fatalf("warning data = %+v", warning.Data)
+	}
+	if snapshot := monitor.Snapshot(); len(snapshot) != 1 || snapshot[0].LimitTokens != 10000 {
+		t.Fatalf("Snapshot() = %+v", snapshot)
+	}
+}
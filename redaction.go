@@ -0,0 +1,475 @@
+package agentkit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/internal/logging"
+)
+
+// RedactionMode decides what replaces a redacted value.
+type RedactionMode string
+
+const (
+	// RedactDrop replaces the value with "[redacted]", or
+	// "[redacted:<pattern name>]" for pattern matches.
+	RedactDrop RedactionMode = "drop"
+	// RedactHash replaces the value with a short keyed hash, "[hash:1a2b3c4d5e6f]",
+	// so equal values can still be correlated across logs and traces.
+	RedactHash RedactionMode = "hash"
+)
+
+// RedactionPattern redacts the parts of string values matching Regexp,
+// wherever they appear.
+type RedactionPattern struct {
+	Name   string // Shown in the replacement, e.g. "email"
+	Regexp *regexp.Regexp
+}
+
+// RedactionPolicy describes what to redact from a destination's payloads.
+// A value is redacted when its field name is in Keys, when its location
+// matches one of Paths, or, for strings, where a Pattern matches. Allow
+// exempts fields from all three.
+//
+// Paths are dotted JSON paths from the payload root, "$." optional; "*"
+// matches any field or array index: "user.email", "args.cards.*.number".
+// Payload roots are the log record's attributes (groups nest), the event's
+// Data, and for traces the field being recorded: "input", "output",
+// "metadata" or "attributes", e.g. "input.messages.*.content".
+type RedactionPolicy struct {
+	Keys     []string // Field names, case-insensitive, at any depth
+	Paths    []string
+	Patterns []RedactionPattern
+	Allow    []string      // Field names or paths never redacted
+	Mode     RedactionMode // Default RedactDrop
+	HashKey  []byte        // HMAC key for RedactHash; set one so hashes can't be brute-forced
+	// NoDefaultKeys leaves out the built-in secret field names (api_key,
+	// authorization, password, token...) that Keys otherwise extends.
+	NoDefaultKeys bool
+}
+
+// RedactionConfig sets a policy per destination. Each destination is left
+// as is when its policy is nil.
+type RedactionConfig struct {
+	Logs   *RedactionPolicy // The agent's logger
+	Traces *RedactionPolicy // Trace and span inputs, outputs, metadata and attributes
+	Events *RedactionPolicy // Event Data on the Run channel
+	// Sinks covers event sinks, such as an audit trail on a broker
+	// (default: the Events policy).
+	Sinks *RedactionPolicy
+}
+
+// CommonSecretPatterns matches secrets that turn up in free text: bearer
+// tokens, OpenAI and Anthropic style API keys and AWS access key IDs.
+func CommonSecretPatterns() []RedactionPattern {
+	return []RedactionPattern{
+		{Name: "bearer", Regexp: regexp.MustCompile(`(?i)\bbearer\s+[a-z0-9._~+/-]+=*`)},
+		{Name: "api_key", Regexp: regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+		{Name: "aws_key", Regexp: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	}
+}
+
+// Redactor applies a RedactionPolicy. It is safe for concurrent use.
+type Redactor struct {
+	keys       map[string]struct{}
+	allowKeys  map[string]struct{}
+	paths      [][]string
+	allowPaths [][]string
+	patterns   []RedactionPattern
+	mode       RedactionMode
+	hashKey    []byte
+}
+
+// NewRedactor compiles policy.
+func NewRedactor(policy RedactionPolicy) *Redactor {
+	r := &Redactor{
+		keys:      map[string]struct{}{},
+		allowKeys: map[string]struct{}{},
+		mode:      policy.Mode,
+		hashKey:   policy.HashKey,
+	}
+	if r.mode == "" {
+		r.mode = RedactDrop
+	}
+	if !policy.NoDefaultKeys {
+		for _, key := range logging.SensitiveKeys() {
+			r.keys[key] = struct{}{}
+		}
+	}
+	for _, key := range policy.Keys {
+		r.keys[normalizeRedactionKey(key)] = struct{}{}
+	}
+	for _, path := range policy.Paths {
+		r.paths = append(r.paths, splitRedactionPath(path))
+	}
+	for _, allow := range policy.Allow {
+		if segments := splitRedactionPath(allow); len(segments) > 1 {
+			r.allowPaths = append(r.allowPaths, segments)
+		} else {
+			r.allowKeys[normalizeRedactionKey(allow)] = struct{}{}
+		}
+	}
+	for _, pattern := range policy.Patterns {
+		if pattern.Regexp != nil {
+			r.patterns = append(r.patterns, pattern)
+		}
+	}
+	return r
+}
+
+func normalizeRedactionKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func splitRedactionPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(path), "$"), ".")
+	return strings.Split(path, ".")
+}
+
+// Redact returns value with the policy applied. Values the policy doesn't
+// touch are returned as they are; structs and other typed values containing
+// a redacted field come back in their generic JSON form (maps and slices).
+func (r *Redactor) Redact(value any) any {
+	redacted, _ := r.redact(nil, value)
+	return redacted
+}
+
+// RedactString applies the policy's patterns to s.
+func (r *Redactor) RedactString(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+			return r.replacement(pattern.Name, match)
+		})
+	}
+	return s
+}
+
+// redactAt redacts value found at the dotted location root.
+func (r *Redactor) redactAt(root string, value any) any {
+	redacted, _ := r.redactField(nil, root, value)
+	return redacted
+}
+
+// redactData redacts a map payload such as event Data, returning data
+// itself when nothing changes.
+func (r *Redactor) redactData(data map[string]any) map[string]any {
+	if redacted, changed := r.redact(nil, data); changed {
+		if m, ok := redacted.(map[string]any); ok {
+			return m
+		}
+	}
+	return data
+}
+
+// redact walks value, which sits at path, and reports whether anything was
+// redacted.
+func (r *Redactor) redact(path []string, value any) (any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		redacted := r.RedactString(v)
+		return redacted, redacted != v
+	case error:
+		if redacted := r.RedactString(v.Error()); redacted != v.Error() {
+			return redacted, true
+		}
+		return v, false
+	case map[string]any:
+		var out map[string]any
+		for key, item := range v {
+			redacted, changed := r.redactField(path, key, item)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]any, len(v))
+				for k, val := range v {
+					out[k] = val
+				}
+			}
+			out[key] = redacted
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	case []any:
+		var out []any
+		for i, item := range v {
+			redacted, changed := r.redactField(path, strconv.Itoa(i), item)
+			if !changed {
+				continue
+			}
+			if out == nil {
+				out = slices.Clone(v)
+			}
+			out[i] = redacted
+		}
+		if out == nil {
+			return v, false
+		}
+		return out, true
+	}
+	if generic, ok := toGenericJSON(value); ok {
+		if redacted, changed := r.redact(path, generic); changed {
+			return redacted, true
+		}
+	}
+	return value, false
+}
+
+// redactField redacts the value stored under key in the container at path.
+func (r *Redactor) redactField(path []string, key string, value any) (any, bool) {
+	fieldPath := append(path[:len(path):len(path)], key)
+	if r.allowed(key, fieldPath) {
+		return value, false
+	}
+	if r.targeted(key, fieldPath) {
+		return r.replacement("", value), true
+	}
+	return r.redact(fieldPath, value)
+}
+
+func (r *Redactor) allowed(key string, path []string) bool {
+	if _, ok := r.allowKeys[normalizeRedactionKey(key)]; ok {
+		return true
+	}
+	return matchesAnyPath(r.allowPaths, path)
+}
+
+func (r *Redactor) targeted(key string, path []string) bool {
+	if _, ok := r.keys[normalizeRedactionKey(key)]; ok {
+		return true
+	}
+	return matchesAnyPath(r.paths, path)
+}
+
+func matchesAnyPath(patterns [][]string, path []string) bool {
+	for _, pattern := range patterns {
+		if len(pattern) != len(path) {
+			continue
+		}
+		matched := true
+		for i, segment := range pattern {
+			if segment != "*" && segment != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// replacement is what a redacted value becomes under the policy's mode.
+func (r *Redactor) replacement(name string, value any) string {
+	if r.mode == RedactHash {
+		var data []byte
+		if s, ok := value.(string); ok {
+			data = []byte(s)
+		} else {
+			data, _ = json.Marshal(value)
+		}
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write(data)
+		return "[hash:" + hex.EncodeToString(mac.Sum(nil))[:12] + "]"
+	}
+	if name != "" {
+		return "[redacted:" + name + "]"
+	}
+	return "[redacted]"
+}
+
+// toGenericJSON converts structs, typed maps and slices to the maps and
+// slices encoding/json decodes into, so the policy can walk them.
+func toGenericJSON(value any) (any, bool) {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Pointer, reflect.Interface:
+	default:
+		return nil, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, false
+	}
+	return generic, true
+}
+
+// redactors holds an agent's compiled per-destination policies; nil entries
+// leave their destination untouched.
+type redactors struct {
+	logs, traces, events, sinks *Redactor
+}
+
+func newRedactors(cfg *RedactionConfig, logging LoggingConfig) redactors {
+	var r redactors
+	compile := func(policy *RedactionPolicy) *Redactor {
+		if policy == nil {
+			return nil
+		}
+		return NewRedactor(*policy)
+	}
+	if cfg != nil {
+		r.logs = compile(cfg.Logs)
+		r.traces = compile(cfg.Traces)
+		r.events = compile(cfg.Events)
+		r.sinks = compile(cfg.Sinks)
+		if cfg.Sinks == nil {
+			r.sinks = r.events
+		}
+	}
+	if r.logs == nil && logging.RedactSensitive {
+		r.logs = NewRedactor(RedactionPolicy{})
+	}
+	return r
+}
+
+// LogHandler returns a slog.Handler that redacts messages and attributes
+// before passing records to next.
+func (r *Redactor) LogHandler(next slog.Handler) slog.Handler {
+	return &redactingHandler{next: next, redactor: r}
+}
+
+type redactingHandler struct {
+	next     slog.Handler
+	redactor *Redactor
+	groups   []string
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, h.redactor.RedactString(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(h.groups, attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactAttr(h.groups, attr)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted), redactor: h.redactor, groups: h.groups}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &redactingHandler{next: h.next.WithGroup(name), redactor: h.redactor, groups: append(slices.Clip(h.groups), name)}
+}
+
+func (h *redactingHandler) redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		path := groups
+		if attr.Key != "" {
+			path = append(slices.Clip(groups), attr.Key)
+		}
+		members := value.Group()
+		redacted := make([]slog.Attr, len(members))
+		for i, member := range members {
+			redacted[i] = h.redactAttr(path, member)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	}
+	redacted, changed := h.redactor.redactField(groups, attr.Key, value.Any())
+	if !changed {
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+	return slog.Any(attr.Key, redacted)
+}
+
+// Tracer returns a Tracer that redacts trace and span inputs, outputs,
+// metadata and attributes before passing them to next.
+func (r *Redactor) Tracer(next Tracer) Tracer {
+	return &redactingTracer{Tracer: next, redactor: r}
+}
+
+type redactingTracer struct {
+	Tracer
+	redactor *Redactor
+}
+
+// Unwrap returns the tracer being redacted for.
+func (t *redactingTracer) Unwrap() Tracer {
+	return t.Tracer
+}
+
+// TraceIDs implements TraceIDProvider when the wrapped tracer does.
+func (t *redactingTracer) TraceIDs(ctx context.Context) (traceID, spanID string) {
+	if provider, ok := t.Tracer.(TraceIDProvider); ok {
+		return provider.TraceIDs(ctx)
+	}
+	return "", ""
+}
+
+func (t *redactingTracer) StartTrace(ctx context.Context, name string, opts ...TraceOption) (context.Context, func()) {
+	opts = append(slices.Clip(opts), func(cfg *TraceConfig) {
+		cfg.Input = t.redactor.redactAt("input", cfg.Input)
+		cfg.Metadata = t.redactMap("metadata", cfg.Metadata)
+	})
+	return t.Tracer.StartTrace(ctx, name, opts...)
+}
+
+func (t *redactingTracer) StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, func()) {
+	opts = append(slices.Clip(opts), func(cfg *SpanConfig) {
+		cfg.Input = t.redactor.redactAt("input", cfg.Input)
+		cfg.Metadata = t.redactMap("metadata", cfg.Metadata)
+	})
+	return t.Tracer.StartSpan(ctx, name, opts...)
+}
+
+func (t *redactingTracer) LogGeneration(ctx context.Context, opts GenerationOptions) error {
+	opts.Input = t.redactor.redactAt("input", opts.Input)
+	opts.Output = t.redactor.redactAt("output", opts.Output)
+	opts.Metadata = t.redactMap("metadata", opts.Metadata)
+	return t.Tracer.LogGeneration(ctx, opts)
+}
+
+func (t *redactingTracer) LogEvent(ctx context.Context, name string, attributes map[string]any) error {
+	return t.Tracer.LogEvent(ctx, name, t.redactMap("attributes", attributes))
+}
+
+func (t *redactingTracer) SetTraceAttributes(ctx context.Context, attributes map[string]any) error {
+	return t.Tracer.SetTraceAttributes(ctx, t.redactMap("attributes", attributes))
+}
+
+func (t *redactingTracer) SetSpanOutput(ctx context.Context, output any) error {
+	return t.Tracer.SetSpanOutput(ctx, t.redactor.redactAt("output", output))
+}
+
+func (t *redactingTracer) SetSpanAttributes(ctx context.Context, attributes map[string]any) error {
+	return t.Tracer.SetSpanAttributes(ctx, t.redactMap("attributes", attributes))
+}
+
+func (t *redactingTracer) redactMap(root string, m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	if redacted, ok := t.redactor.redactAt(root, m).(map[string]any); ok {
+		return redacted
+	}
+	return m
+}
@@ -0,0 +1,159 @@
+package agentkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+var emailPattern = RedactionPattern{Name: "email", Regexp: regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)}
+
+func TestRedactor_Redact(t *testing.T) {
+	type card struct {
+		Number string `json:"number"`
+		Holder string `json:"holder"`
+	}
+	redactor := NewRedactor(RedactionPolicy{
+		Keys:     []string{"SSN"},
+		Paths:    []string{"$.payment.cards.*.number"},
+		Patterns: []RedactionPattern{emailPattern},
+		Allow:    []string{"token", "audit.email"},
+	})
+
+	got := redactor.Redact(map[string]any{
+		"password": "hunter2",
+		"token":    "allowed",
+		"user":     map[string]any{"ssn": "123-45-6789", "note": "mail ann@example.com"},
+		"payment":  map[string]any{"cards": []card{{Number: "4111", Holder: "Ann"}}},
+		"audit":    map[string]any{"email": "ann@example.com"},
+		"count":    3,
+	})
+	data, _ := json.Marshal(got)
+	want := `{"audit":{"email":"ann@example.com"},"count":3,"password":"[redacted]","payment":{"cards":[{"holder":"Ann","number":"[redacted]"}]},"token":"allowed","user":{"note":"mail [redacted:email]","ssn":"[redacted]"}}`
+	if string(data) != want {
+		t.Errorf("Redact() =\n%s\nwant\n%s", data, want)
+	}
+
+	untouched := card{Number: "1", Holder: "Bo"}
+	if got := redactor.Redact(untouched); got != untouched {
+		t.Errorf("expected untouched values to keep their type, got %#v", got)
+	}
+}
+
+func TestRedactor_HashMode(t *testing.T) {
+	redactor := NewRedactor(RedactionPolicy{Mode: RedactHash, HashKey: []byte("k"), Patterns: []RedactionPattern{emailPattern}})
+
+	first := redactor.RedactString("from ann@example.com")
+	second := redactor.RedactString("to ann@example.com")
+	other := NewRedactor(RedactionPolicy{Mode: RedactHash, HashKey: []byte("other"), Patterns: []RedactionPattern{emailPattern}}).
+		RedactString("to ann@example.com")
+
+	if !strings.HasPrefix(first, "from [hash:") || strings.Contains(first, "ann@") {
+		t.Fatalf("unexpected hash redaction: %q", first)
+	}
+	if strings.TrimPrefix(first, "from ") != strings.TrimPrefix(second, "to ") {
+		t.Errorf("equal values hashed differently: %q, %q", first, second)
+	}
+	if second == other {
+		t.Errorf("hash doesn't depend on the key: %q", other)
+	}
+}
+
+func TestRedactor_LogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	redactor := NewRedactor(RedactionPolicy{Paths: []string{"request.user"}, Patterns: CommonSecretPatterns()})
+	logger := slog.New(redactor.LogHandler(slog.NewJSONHandler(&buf, nil)))
+
+	logger.With("api_key", "secret").WithGroup("request").Info("calling with Bearer abc.def",
+		"user", "ann", "path", "/v1", "error", errors.New("bad key sk-abcdefghijklmnopqrstuvwxyz"))
+
+	out := buf.String()
+	for _, leaked := range []string{"secret", "abc.def", "ann", "sk-abc"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("log leaked %q: %s", leaked, out)
+		}
+	}
+	for _, kept := range []string{`"api_key":"[redacted]"`, `"user":"[redacted]"`, `"path":"/v1"`, "[redacted:bearer]", "bad key [redacted:api_key]"} {
+		if !strings.Contains(out, kept) {
+			t.Errorf("log missing %q: %s", kept, out)
+		}
+	}
+}
+
+func TestLoggingConfig_RedactSensitive(t *testing.T) {
+	var buf bytes.Buffer
+	agent, err := New(Config{
+		Provider: mockprovider.New(),
+		Model:    "test-model",
+		Logging:  &LoggingConfig{Handler: slog.NewTextHandler(&buf, nil), RedactSensitive: true},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.logger.Info("connecting", "password", "hunter2")
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("RedactSensitive left the password in the log: %s", buf.String())
+	}
+}
+
+func TestRedaction_PerDestination(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		published []Event
+	)
+	sink := EventSinkFunc(func(ctx context.Context, event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, event)
+	})
+	tracer := &generationTracer{}
+	agent, err := New(Config{
+		Provider:   mockprovider.New().WithResponse("your key is sk-abcdefghijklmnopqrstuvwxyz", nil),
+		Model:      "test-model",
+		Tracer:     tracer,
+		EventSinks: []EventSink{sink},
+		Redaction: &RedactionConfig{
+			Traces: &RedactionPolicy{Patterns: []RedactionPattern{emailPattern}},
+			Events: &RedactionPolicy{Patterns: CommonSecretPatterns()},
+			Sinks:  &RedactionPolicy{Patterns: CommonSecretPatterns(), Mode: RedactHash},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := collectEvents(agent.Run(context.Background(), "I'm ann@example.com"), time.Second)
+
+	final := findEvent(events, EventTypeFinalOutput)
+	if final == nil || final.Data["response"] != "your key is [redacted:api_key]" {
+		t.Fatalf("unexpected final output event: %+v", final)
+	}
+	mu.Lock()
+	sinkFinal := findEvent(published, EventTypeFinalOutput)
+	mu.Unlock()
+	if sinkFinal == nil || !strings.HasPrefix(sinkFinal.Data["response"].(string), "your key is [hash:") {
+		t.Errorf("unexpected sink event: %+v", sinkFinal)
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.generations) == 0 {
+		t.Fatal("expected a traced generation")
+	}
+	input, _ := json.Marshal(tracer.generations[0].Input)
+	if strings.Contains(string(input), "ann@example.com") || !strings.Contains(string(input), "[redacted:email]") {
+		t.Errorf("trace input not redacted: %s", input)
+	}
+	if _, ok := agent.tracer.(TraceIDProvider); !ok {
+		t.Error("redacting tracer should still provide trace IDs")
+	}
+}
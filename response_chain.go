@@ -0,0 +1,182 @@
+package agentkit
+
+import (
+	"context"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultResponseTTL is how long OpenAI keeps stored responses.
+const DefaultResponseTTL = 30 * 24 * time.Hour
+
+// Conversation metadata keys under which RunWithConversation keeps the
+// response chain between turns.
+const (
+	metaPreviousResponseID    = "previous_response_id"
+	metaPreviousResponseAt    = "previous_response_at"
+	metaPreviousResponseTurns = "previous_response_turns"
+)
+
+// ResponseChainingConfig continues each model call from the previous stored
+// response (CompletionRequest.PreviousResponseID) instead of resending the
+// whole history, on providers that keep response state such as the OpenAI
+// Responses API. Requests are sent with Store set.
+//
+// Chains span the iterations of a run and, with RunWithConversation, the
+// turns of a conversation. A stored response older than TTL, or one the
+// provider reports as gone, is not continued: the full history is sent
+// instead, rebuilt from the conversation store, and an
+// EventTypeServerStateLost event is emitted. Compacting the history and
+// falling back to another model also send the full history.
+type ResponseChainingConfig struct {
+	// TTL is how long a stored response is continued from (default
+	// DefaultResponseTTL). Set it at or below the provider's retention.
+	TTL time.Duration
+}
+
+// responseChain is the last stored response of a run or conversation and
+// how many messages of the history it already includes.
+type responseChain struct {
+	id   string
+	at   time.Time
+	sent int
+	// tools fingerprints the tool definitions the stored response was
+	// created with; pendingTools those of the request in flight.
+	tools, pendingTools string
+}
+
+// record continues the chain from response id, which includes the first
+// sent messages of the history. Responses of fallback models (primary false)
+// cannot be continued by the primary model.
+func (c *responseChain) record(id string, primary bool, sent int) {
+	if c == nil {
+		return
+	}
+	if !primary || id == "" {
+		c.reset()
+		return
+	}
+	*c = responseChain{id: id, at: time.Now(), sent: sent, tools: c.pendingTools}
+}
+
+func (c *responseChain) reset() {
+	if c != nil {
+		*c = responseChain{}
+	}
+}
+
+const (
+	responseChainKey    contextKey = "agentkit_response_chain"
+	unchainedRequestKey contextKey = "agentkit_unchained_request"
+	previousResponseKey contextKey = "agentkit_previous_response"
+)
+
+// withResponseChain makes runLoop continue from and update chain.
+func withResponseChain(ctx context.Context, chain *responseChain) context.Context {
+	return context.WithValue(ctx, responseChainKey, chain)
+}
+
+// startResponseChain returns the chain runLoop continues from, dropping one
+// whose stored response has outlived the TTL.
+func (a *Agent) startResponseChain(ctx context.Context, historyLen int, events chan<- Event) *responseChain {
+	if a.responseChaining == nil {
+		return nil
+	}
+	chain, _ := ctx.Value(responseChainKey).(*responseChain)
+	if chain == nil {
+		return &responseChain{}
+	}
+	if chain.id != "" && time.Since(chain.at) > a.responseChaining.TTL {
+		a.emit(ctx, events, ServerStateLost(chain.id, "expired", historyLen))
+		chain.reset()
+	}
+	return chain
+}
+
+// chainRequest sends only the messages after the stored response, and no
+// tools when they are unchanged and the provider keeps them, keeping the
+// full request in the returned context for fallback models.
+func (a *Agent) chainRequest(ctx context.Context, chain *responseChain, req *providers.CompletionRequest, history []providers.Message) context.Context {
+	if chain == nil {
+		return ctx
+	}
+	req.Store = true
+	reuseTools := reusesToolDefinitions(a.provider)
+	chain.pendingTools = ""
+	if reuseTools {
+		chain.pendingTools = toolSetFingerprint(req.Tools)
+	}
+	if chain.id == "" {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, unchainedRequestKey, *req)
+	req.PreviousResponseID = chain.id
+	req.Messages = history[chain.sent:]
+	if reuseTools && chain.tools != "" && chain.tools == chain.pendingTools {
+		req.Tools = nil
+	}
+	return context.WithValue(ctx, previousResponseKey, true)
+}
+
+// unchainedRequest returns req with the full history when it continues a
+// stored response, for models that cannot see that response.
+func unchainedRequest(ctx context.Context, req providers.CompletionRequest) providers.CompletionRequest {
+	if req.PreviousResponseID == "" {
+		return req
+	}
+	full, ok := ctx.Value(unchainedRequestKey).(providers.CompletionRequest)
+	if !ok {
+		return req
+	}
+	full.Model = req.Model
+	return full
+}
+
+// loadResponseChain reads the chain RunWithConversation stored in conv.
+func loadResponseChain(conv Conversation) *responseChain {
+	chain := &responseChain{}
+	id, _ := conv.Metadata[metaPreviousResponseID].(string)
+	at, _ := conv.Metadata[metaPreviousResponseAt].(string)
+	stored, err := time.Parse(time.RFC3339Nano, at)
+	if id == "" || err != nil {
+		return chain
+	}
+	var turns int
+	switch n := conv.Metadata[metaPreviousResponseTurns].(type) {
+	case int:
+		turns = n
+	case float64: // Decoded from JSON
+		turns = int(n)
+	}
+	// Turns appended without a response (another writer, a failed run) are
+	// not in the stored response; send the full history.
+	if turns != len(conv.Turns) {
+		return chain
+	}
+	chain.id = id
+	chain.at = stored
+	chain.sent = len(conversationMessages(conv.Turns))
+	return chain
+}
+
+// saveResponseChain records chain in the metadata of conversationID.
+func (a *Agent) saveResponseChain(ctx context.Context, conversationID string, chain *responseChain) error {
+	conv, err := a.conversationStore.Load(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	if conv.Metadata == nil {
+		conv.Metadata = map[string]any{}
+	}
+	if chain.id == "" {
+		delete(conv.Metadata, metaPreviousResponseID)
+		delete(conv.Metadata, metaPreviousResponseAt)
+		delete(conv.Metadata, metaPreviousResponseTurns)
+	} else {
+		conv.Metadata[metaPreviousResponseID] = chain.id
+		conv.Metadata[metaPreviousResponseAt] = chain.at.Format(time.RFC3339Nano)
+		conv.Metadata[metaPreviousResponseTurns] = len(conv.Turns)
+	}
+	return a.conversationStore.Save(ctx, conv)
+}
@@ -0,0 +1,128 @@
+package agentkit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// forgetfulProvider rejects requests continuing from a response in lost.
+type forgetfulProvider struct {
+	recordingProvider
+	lost map[string]bool
+}
+
+func (f *forgetfulProvider) Complete(ctx context.Context, req providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	if f.lost[req.PreviousResponseID] {
+		f.requests = append(f.requests, req)
+		return nil, fmt.Errorf("%w: API error (status 404)", providers.ErrPreviousResponseNotFound)
+	}
+	return f.recordingProvider.Complete(ctx, req)
+}
+
+func newChainingAgent(t *testing.T, provider providers.Provider, chaining ResponseChainingConfig) *Agent {
+	t.Helper()
+	agent, err := New(Config{
+		Provider:          provider,
+		Model:             "test-model",
+		StreamResponses:   false,
+		ConversationStore: NewMemoryConversationStore(),
+		ResponseChaining:  &chaining,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return agent
+}
+
+func TestResponseChaining_SendsOnlyNewMessages(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "lookup", Arguments: map[string]any{}}}).
+		WithResponse("Paris", nil).
+		WithResponse("About 2 million", nil)}
+	agent := newChainingAgent(t, provider, ResponseChainingConfig{})
+	agent.AddTool(NewTool("lookup").WithHandler(func(context.Context, map[string]any) (any, error) { return "France", nil }).Build())
+
+	ctx := context.Background()
+	collectEvents(agent.RunWithConversation(ctx, "conv-1", "Capital?"), time.Second)
+	collectEvents(agent.RunWithConversation(ctx, "conv-1", "Population?"), time.Second)
+
+	if len(provider.requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(provider.requests))
+	}
+	first, tool, next := provider.requests[0], provider.requests[1], provider.requests[2]
+	if first.PreviousResponseID != "" || !first.Store {
+		t.Errorf("first request = %+v, want stored without a previous response", first)
+	}
+	if tool.PreviousResponseID == "" || len(tool.Messages) != 1 || tool.Messages[0].Role != providers.RoleTool {
+		t.Errorf("tool request = %+v, want only the tool result after the previous response", tool)
+	}
+	if next.PreviousResponseID == "" || len(next.Messages) != 1 || next.Messages[0].Content != "Population?" {
+		t.Errorf("next turn request = %+v, want only the new user message", next)
+	}
+}
+
+func TestResponseChaining_RebuildsLostState(t *testing.T) {
+	provider := &forgetfulProvider{
+		recordingProvider: recordingProvider{Provider: mockprovider.New().
+			WithResponse("Paris", nil).
+			WithResponse("About 2 million", nil)},
+		lost: map[string]bool{},
+	}
+	agent := newChainingAgent(t, provider, ResponseChainingConfig{})
+	ctx := context.Background()
+	collectEvents(agent.RunWithConversation(ctx, "conv-1", "Capital?"), time.Second)
+
+	// The provider dropped the stored response between turns.
+	conv, _ := agent.conversationStore.Load(ctx, "conv-1")
+	provider.lost[conv.Metadata[metaPreviousResponseID].(string)] = true
+
+	events := collectEvents(agent.RunWithConversation(ctx, "conv-1", "Population?"), time.Second)
+
+	retry := provider.requests[len(provider.requests)-1]
+	if retry.PreviousResponseID != "" || len(retry.Messages) != 3 {
+		t.Errorf("retry = %+v, want the full history from the conversation store", retry)
+	}
+	var lost *Event
+	for i := range events {
+		if events[i].Type == EventTypeServerStateLost {
+			lost = &events[i]
+		}
+		if events[i].Type == EventTypeError {
+			t.Errorf("unexpected error event: %+v", events[i].Data)
+		}
+	}
+	if lost == nil || lost.Data["reason"] != "not_found" || lost.Data["messages"] != 3 {
+		t.Errorf("server state lost event = %+v", lost)
+	}
+}
+
+func TestResponseChaining_ExpiredResponseNotContinued(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("Paris", nil).
+		WithResponse("About 2 million", nil)}
+	agent := newChainingAgent(t, provider, ResponseChainingConfig{TTL: time.Hour})
+	ctx := context.Background()
+	collectEvents(agent.RunWithConversation(ctx, "conv-1", "Capital?"), time.Second)
+
+	conv, _ := agent.conversationStore.Load(ctx, "conv-1")
+	conv.Metadata[metaPreviousResponseAt] = time.Now().Add(-2 * time.Hour).Format(time.RFC3339Nano)
+	_ = agent.conversationStore.Save(ctx, conv)
+
+	events := collectEvents(agent.RunWithConversation(ctx, "conv-1", "Population?"), time.Second)
+
+	if req := provider.requests[1]; req.PreviousResponseID != "" || len(req.Messages) != 3 {
+		t.Errorf("request = %+v, want the full history", req)
+	}
+	found := false
+	for _, event := range events {
+		found = found || (event.Type == EventTypeServerStateLost && event.Data["reason"] == "expired")
+	}
+	if !found {
+		t.Error("want a context.server_state_lost event with reason expired")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
 )
 
 const (
@@ -79,6 +81,7 @@ func parseAPIError(statusCode int, body []byte) error {
 // ResponsesClient wraps OpenAI's Responses API
 type ResponsesClient struct {
 	apiKey     string
+	endpoint   string
 	httpClient *http.Client
 	logger     *slog.Logger
 }
@@ -90,11 +93,21 @@ func NewResponsesClient(apiKey string, logger *slog.Logger) *ResponsesClient {
 	}
 	return &ResponsesClient{
 		apiKey:     apiKey,
-		httpClient: &http.Client{},
+		endpoint:   responsesEndpoint,
+		httpClient: providers.DefaultHTTPClient(),
 		logger:     logger,
 	}
 }
 
+// WithBaseURL points the client at an OpenAI-compatible server that
+// implements the Responses API, e.g. "http://localhost:8000/v1".
+func (c *ResponsesClient) WithBaseURL(baseURL string) *ResponsesClient {
+	if baseURL != "" {
+		c.endpoint = strings.TrimRight(baseURL, "/") + "/responses"
+	}
+	return c
+}
+
 // ResponseInput represents input to the model
 type ResponseInput struct {
 	Role    string                `json:"role"`
@@ -118,9 +131,19 @@ type ResponseImageURL struct {
 	URL string `json:"url"`
 }
 
-// ResponseAnnotation represents an annotation in content
+// ResponseAnnotation represents an annotation in content.
+// Types include "url_citation" (web search), "file_citation" (file search),
+// "container_file_citation" (code interpreter), and "file_path".
 type ResponseAnnotation struct {
-	Type string `json:"type"`
+	Type        string `json:"type"`
+	StartIndex  int    `json:"start_index,omitempty"`
+	EndIndex    int    `json:"end_index,omitempty"`
+	URL         string `json:"url,omitempty"`          // For url_citation
+	Title       string `json:"title,omitempty"`        // For url_citation
+	FileID      string `json:"file_id,omitempty"`      // For file_citation, container_file_citation, file_path
+	Filename    string `json:"filename,omitempty"`     // For file_citation, container_file_citation
+	ContainerID string `json:"container_id,omitempty"` // For container_file_citation
+	Index       int    `json:"index,omitempty"`        // Position in the text for file_citation
 }
 
 // ResponseTextFormat represents text format configuration
@@ -154,12 +177,16 @@ type ResponseReasoning struct {
 
 // ResponseTool represents a tool definition for Responses API
 // Note: In Responses API, name/description/parameters are at top level, not nested
+// Hosted tools ("web_search", "file_search", "code_interpreter") have no name
+// and use VectorStoreIDs or Container instead.
 type ResponseTool struct {
-	Type        string                 `json:"type"`
-	Name        string                 `json:"name"`
-	Description string                 `json:"description,omitempty"`
-	Parameters  map[string]any `json:"parameters,omitempty"`
-	Strict      bool                   `json:"strict,omitempty"`
+	Type           string         `json:"type"`
+	Name           string         `json:"name,omitempty"`
+	Description    string         `json:"description,omitempty"`
+	Parameters     map[string]any `json:"parameters,omitempty"`
+	Strict         bool           `json:"strict,omitempty"`
+	VectorStoreIDs []string       `json:"vector_store_ids,omitempty"`
+	Container      any            `json:"container,omitempty"`
 }
 
 // ResponseRequest represents a request to create a response
@@ -260,6 +287,7 @@ type ResponseStreamChunk struct {
 	Response       *ResponseObject      `json:"response,omitempty"`
 	Error          *ResponseError       `json:"error,omitempty"` // For error events
 	ItemID         string               `json:"item_id,omitempty"`
+	CallID         string               `json:"call_id,omitempty"` // For function_call_arguments events
 	OutputIndex    int                  `json:"output_index,omitempty"`
 	Delta          string               `json:"delta,omitempty"`     // For delta events
 	Text           string               `json:"text,omitempty"`      // For done events with text
@@ -269,6 +297,7 @@ type ResponseStreamChunk struct {
 	Status         string               `json:"status,omitempty"`
 	Output         []ResponseOutputItem `json:"output,omitempty"` // For response.done
 	Usage          *ResponseUsage       `json:"usage,omitempty"`
+	Annotation     *ResponseAnnotation  `json:"annotation,omitempty"` // For output_text.annotation.added
 	Obfuscation    string               `json:"obfuscation,omitempty"` // Sent by API, purpose unclear
 }
 
@@ -287,7 +316,7 @@ func (c *ResponsesClient) CreateResponse(ctx context.Context, req ResponseReques
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", responsesEndpoint, bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -342,7 +371,7 @@ func (c *ResponsesClient) CreateResponseStream(ctx context.Context, req Response
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", responsesEndpoint, bytes.NewBuffer(jsonData))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
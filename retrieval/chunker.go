@@ -0,0 +1,215 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Default chunking parameters.
+const (
+	DefaultChunkSize         = 1000 // characters
+	DefaultChunkOverlap      = 100  // characters
+	DefaultSemanticThreshold = 0.75 // cosine similarity
+)
+
+// Chunker splits document text into pieces sized for embedding.
+type Chunker interface {
+	Chunk(ctx context.Context, text string) ([]string, error)
+}
+
+// FixedChunker splits text into windows of Size characters, with Overlap
+// characters repeated between consecutive chunks. Boundaries are moved back
+// to the nearest whitespace so words are not cut in half.
+type FixedChunker struct {
+	Size    int
+	Overlap int
+}
+
+// Chunk implements Chunker.
+func (c FixedChunker) Chunk(_ context.Context, text string) ([]string, error) {
+	size := c.Size
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	overlap := c.Overlap
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	runes := []rune(strings.TrimSpace(text))
+	var chunks []string
+	for start := 0; start < len(runes); {
+		end := min(start+size, len(runes))
+		if end < len(runes) {
+			// Prefer to break on whitespace in the second half of the window.
+			for i := end; i > start+size/2; i-- {
+				if unicode.IsSpace(runes[i]) {
+					end = i
+					break
+				}
+			}
+		}
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(runes) {
+			break
+		}
+		start = max(end-overlap, start+1)
+	}
+	return chunks, nil
+}
+
+// SentenceChunker packs whole sentences into chunks of at most MaxChars
+// characters. A sentence longer than MaxChars is split with FixedChunker.
+type SentenceChunker struct {
+	MaxChars int
+	// Overlap is the number of trailing sentences repeated at the start of the next chunk.
+	Overlap int
+}
+
+// Chunk implements Chunker.
+func (c SentenceChunker) Chunk(ctx context.Context, text string) ([]string, error) {
+	maxChars := c.MaxChars
+	if maxChars <= 0 {
+		maxChars = DefaultChunkSize
+	}
+	return packSentences(ctx, SplitSentences(text), maxChars, c.Overlap)
+}
+
+// SemanticChunker groups adjacent sentences by meaning: it embeds every
+// sentence and starts a new chunk wherever the similarity between neighbours
+// drops below Threshold, or when the chunk would exceed MaxChars.
+type SemanticChunker struct {
+	Embedder  providers.Embedder
+	Threshold float64
+	MaxChars  int
+}
+
+// Chunk implements Chunker.
+func (c SemanticChunker) Chunk(ctx context.Context, text string) ([]string, error) {
+	if c.Embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+	threshold := c.Threshold
+	if threshold <= 0 {
+		threshold = DefaultSemanticThreshold
+	}
+	maxChars := c.MaxChars
+	if maxChars <= 0 {
+		maxChars = DefaultChunkSize
+	}
+
+	sentences := SplitSentences(text)
+	if len(sentences) <= 1 {
+		return packSentences(ctx, sentences, maxChars, 0)
+	}
+	vectors, err := c.Embedder.Embed(ctx, sentences)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: embed sentences: %w", err)
+	}
+	if len(vectors) != len(sentences) {
+		return nil, fmt.Errorf("retrieval: embedder returned %d vectors for %d sentences", len(vectors), len(sentences))
+	}
+
+	var chunks []string
+	group := []string{sentences[0]}
+	for i := 1; i < len(sentences); i++ {
+		if CosineSimilarity(vectors[i-1], vectors[i]) < threshold {
+			packed, err := packSentences(ctx, group, maxChars, 0)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, packed...)
+			group = group[:0]
+		}
+		group = append(group, sentences[i])
+	}
+	packed, err := packSentences(ctx, group, maxChars, 0)
+	if err != nil {
+		return nil, err
+	}
+	return append(chunks, packed...), nil
+}
+
+// packSentences joins sentences into chunks no longer than maxChars.
+func packSentences(ctx context.Context, sentences []string, maxChars, overlap int) ([]string, error) {
+	var (
+		chunks  []string
+		current []string
+		length  int
+	)
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		chunks = append(chunks, strings.Join(current, " "))
+		if overlap <= 0 || overlap >= len(current) {
+			current, length = nil, 0
+			return
+		}
+		current = append([]string(nil), current[len(current)-overlap:]...)
+		length = 0
+		for _, s := range current {
+			length += len([]rune(s)) + 1
+		}
+	}
+
+	for _, sentence := range sentences {
+		n := len([]rune(sentence))
+		if n > maxChars {
+			flush()
+			current, length = nil, 0
+			pieces, err := FixedChunker{Size: maxChars}.Chunk(ctx, sentence)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, pieces...)
+			continue
+		}
+		if length > 0 && length+n > maxChars {
+			flush()
+			// Drop overlap that would leave no room for the next sentence.
+			for len(current) > 0 && length+n > maxChars {
+				length -= len([]rune(current[0])) + 1
+				current = current[1:]
+			}
+		}
+		current = append(current, sentence)
+		length += n + 1
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, " "))
+	}
+	return chunks, nil
+}
+
+// SplitSentences splits text into sentences on terminal punctuation followed
+// by whitespace, and on blank lines. Whitespace inside sentences is collapsed.
+func SplitSentences(text string) []string {
+	var sentences []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		runes := []rune(strings.Join(strings.Fields(paragraph), " "))
+		start := 0
+		for i, r := range runes {
+			if r != '.' && r != '!' && r != '?' {
+				continue
+			}
+			if i+1 < len(runes) && runes[i+1] != ' ' {
+				continue
+			}
+			if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+		if s := strings.TrimSpace(string(runes[start:])); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
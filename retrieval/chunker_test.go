@@ -0,0 +1,88 @@
+package retrieval
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	got := SplitSentences("First one.  Second\none? Version 1.2 ships!\n\nNew paragraph")
+	want := []string{"First one.", "Second one?", "Version 1.2 ships!", "New paragraph"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitSentences() = %q, want %q", got, want)
+	}
+}
+
+func TestFixedChunker(t *testing.T) {
+	text := strings.Repeat("word ", 50)
+	chunks, err := FixedChunker{Size: 40, Overlap: 10}.Chunk(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+	if len(chunks) < 6 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if len(chunk) > 40 {
+			t.Errorf("chunk exceeds size: %q", chunk)
+		}
+		if strings.HasPrefix(chunk, "ord") || strings.HasSuffix(chunk, "wor") {
+			t.Errorf("chunk splits a word: %q", chunk)
+		}
+	}
+}
+
+func TestSentenceChunker(t *testing.T) {
+	text := "Alpha is first. Beta is second. Gamma is third. Delta is fourth."
+	chunks, err := SentenceChunker{MaxChars: 35}.Chunk(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+	want := []string{"Alpha is first. Beta is second.", "Gamma is third. Delta is fourth."}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("Chunk() = %q, want %q", chunks, want)
+	}
+
+	chunks, err = SentenceChunker{MaxChars: 35, Overlap: 1}.Chunk(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+	want = []string{"Alpha is first. Beta is second.", "Beta is second. Gamma is third.", "Gamma is third. Delta is fourth."}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("Chunk() with overlap = %q, want %q", chunks, want)
+	}
+}
+
+func TestSemanticChunker(t *testing.T) {
+	text := "Cats purr. Cats nap all day. Stocks fell sharply. Markets closed lower."
+	chunker := SemanticChunker{Embedder: topicEmbedder{}, Threshold: 0.5, MaxChars: 500}
+	chunks, err := chunker.Chunk(context.Background(), text)
+	if err != nil {
+		t.Fatalf("Chunk() error = %v", err)
+	}
+	want := []string{"Cats purr. Cats nap all day.", "Stocks fell sharply. Markets closed lower."}
+	if !reflect.DeepEqual(chunks, want) {
+		t.Errorf("Chunk() = %q, want %q", chunks, want)
+	}
+
+	if _, err := (SemanticChunker{}).Chunk(context.Background(), text); err != ErrNoEmbedder {
+		t.Errorf("error = %v, want ErrNoEmbedder", err)
+	}
+}
+
+// topicEmbedder maps text about cats and markets onto orthogonal axes.
+type topicEmbedder struct{}
+
+func (topicEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if strings.Contains(text, "Cats") {
+			vectors[i] = []float32{1, 0.1}
+		} else {
+			vectors[i] = []float32{0.1, 1}
+		}
+	}
+	return vectors, nil
+}
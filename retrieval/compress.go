@@ -0,0 +1,167 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Compressor shortens retrieved text before it is sent to the model, keeping
+// the parts most relevant to query. Compression trades a little fidelity for
+// fewer prompt tokens; implementations return text unchanged when it is
+// already short.
+type Compressor interface {
+	Compress(ctx context.Context, query, text string) (string, error)
+}
+
+// CompressorFunc adapts a function to the Compressor interface.
+type CompressorFunc func(ctx context.Context, query, text string) (string, error)
+
+// Compress calls f.
+func (f CompressorFunc) Compress(ctx context.Context, query, text string) (string, error) {
+	return f(ctx, query, text)
+}
+
+// SentenceCompressor keeps the sentences that share the most terms with the
+// query, in their original order, until Ratio of the text is used. It needs
+// no model and runs in microseconds. Without a query, sentences are scored by
+// how many of the text's frequent terms they contain.
+type SentenceCompressor struct {
+	// Ratio is the fraction of characters to keep (default 0.5).
+	Ratio float64
+	// MinChars leaves texts shorter than this untouched (default 500).
+	MinChars int
+}
+
+// Compress implements Compressor.
+func (c SentenceCompressor) Compress(_ context.Context, query, text string) (string, error) {
+	ratio := c.Ratio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 0.5
+	}
+	minChars := c.MinChars
+	if minChars <= 0 {
+		minChars = 500
+	}
+	if len(text) < minChars {
+		return text, nil
+	}
+	sentences := SplitSentences(text)
+	if len(sentences) < 2 {
+		return text, nil
+	}
+
+	weights := queryWeights(query, sentences)
+	type scored struct {
+		index int
+		score float64
+	}
+	ranked := make([]scored, len(sentences))
+	for i, sentence := range sentences {
+		var score float64
+		for _, term := range uniqueTerms(Tokenize(sentence)) {
+			score += weights[term]
+		}
+		// Lead sentences usually state the topic; break ties towards them.
+		score += 0.1 / float64(i+1)
+		ranked[i] = scored{index: i, score: score}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+
+	budget := int(float64(len(text)) * ratio)
+	keep := make([]int, 0, len(ranked))
+	used := 0
+	for _, s := range ranked {
+		length := len(sentences[s.index]) + 1
+		if len(keep) > 0 && used+length > budget {
+			continue
+		}
+		keep = append(keep, s.index)
+		used += length
+	}
+	slices.Sort(keep)
+
+	var b strings.Builder
+	for i, index := range keep {
+		if i > 0 {
+			if index != keep[i-1]+1 {
+				b.WriteString(" ...")
+			}
+			b.WriteByte(' ')
+		}
+		b.WriteString(sentences[index])
+	}
+	return b.String(), nil
+}
+
+// queryWeights weights the query's terms, or without a query, terms that
+// occur in more than one sentence.
+func queryWeights(query string, sentences []string) map[string]float64 {
+	weights := make(map[string]float64)
+	if terms := uniqueTerms(Tokenize(query)); len(terms) > 0 {
+		for _, term := range terms {
+			weights[term] = 1
+		}
+		return weights
+	}
+	counts := make(map[string]int)
+	for _, sentence := range sentences {
+		for _, term := range uniqueTerms(Tokenize(sentence)) {
+			counts[term]++
+		}
+	}
+	for term, n := range counts {
+		if n > 1 && len(term) > 3 {
+			weights[term] = float64(n) / float64(len(sentences))
+		}
+	}
+	return weights
+}
+
+// LLMCompressor asks a (small) model to extract the passages of a text that
+// are relevant to the query, verbatim.
+type LLMCompressor struct {
+	Provider providers.Provider
+	Model    string
+	// MinChars leaves texts shorter than this untouched (default 500).
+	MinChars int
+}
+
+// Compress implements Compressor.
+func (c *LLMCompressor) Compress(ctx context.Context, query, text string) (string, error) {
+	minChars := c.MinChars
+	if minChars <= 0 {
+		minChars = 500
+	}
+	if len(text) < minChars {
+		return text, nil
+	}
+	resp, err := c.Provider.Complete(ctx, providers.CompletionRequest{
+		Model: c.Model,
+		SystemPrompt: "You compress documents. Copy, verbatim, only the sentences of the document needed to answer the query, " +
+			"keeping names, numbers and identifiers exact. Output the extracted text only.",
+		Messages: []providers.Message{{
+			Role:    providers.RoleUser,
+			Content: fmt.Sprintf("Query: %s\n\nDocument:\n%s", query, text),
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("retrieval: compress: %w", err)
+	}
+	compressed := strings.TrimSpace(resp.Content)
+	if compressed == "" || len(compressed) >= len(text) {
+		return text, nil
+	}
+	return compressed, nil
+}
@@ -0,0 +1,51 @@
+package retrieval
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+const compressDoc = "Acme was founded in 1999 in Berlin. The office has a rooftop garden. " +
+	"Refunds are issued within 30 days of purchase. Employees get free coffee on Fridays. " +
+	"Refund requests need the order number. The cafeteria serves lunch from noon. " +
+	"Annual plans renew automatically each January."
+
+func TestSentenceCompressor(t *testing.T) {
+	c := SentenceCompressor{Ratio: 0.4, MinChars: 10}
+	got, err := c.Compress(context.Background(), "how do refunds work? refund order", compressDoc)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if !strings.Contains(got, "Refunds are issued within 30 days") || !strings.Contains(got, "Refund requests need the order number.") {
+		t.Errorf("compressed = %q, want refund sentences kept", got)
+	}
+	if strings.Contains(got, "coffee") || len(got) > len(compressDoc)/2 {
+		t.Errorf("compressed = %q (%d of %d chars)", got, len(got), len(compressDoc))
+	}
+	if strings.Index(got, "Refunds are") > strings.Index(got, "Refund requests") {
+		t.Errorf("compressed = %q, want original sentence order", got)
+	}
+}
+
+func TestSentenceCompressor_ShortTextUnchanged(t *testing.T) {
+	got, err := SentenceCompressor{}.Compress(context.Background(), "refunds", compressDoc)
+	if err != nil || got != compressDoc {
+		t.Errorf("Compress() = %q, %v; want text below MinChars unchanged", got, err)
+	}
+}
+
+func TestLLMCompressor(t *testing.T) {
+	provider := mock.New().WithResponse("Refunds are issued within 30 days of purchase.", nil)
+	c := &LLMCompressor{Provider: provider, Model: "mock-model", MinChars: 10}
+
+	got, err := c.Compress(context.Background(), "refunds", compressDoc)
+	if err != nil {
+		t.Fatalf("Compress() error = %v", err)
+	}
+	if got != "Refunds are issued within 30 days of purchase." {
+		t.Errorf("compressed = %q", got)
+	}
+}
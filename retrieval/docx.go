@@ -0,0 +1,129 @@
+package retrieval
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DocxLoader loads Word (.docx) documents. Paragraph text is extracted from
+// word/document.xml; title and author come from docProps/core.xml.
+func DocxLoader() Loader {
+	return LoaderFunc(func(ctx context.Context, r io.Reader, source string) (*Document, error) {
+		reader, size, err := readAllAt(r)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: read %s: %w", source, err)
+		}
+		archive, err := zip.NewReader(reader, size)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: open docx %s: %w", source, err)
+		}
+
+		var body, core *zip.File
+		for _, f := range archive.File {
+			switch f.Name {
+			case "word/document.xml":
+				body = f
+			case "docProps/core.xml":
+				core = f
+			}
+		}
+		if body == nil {
+			return nil, fmt.Errorf("%w: %s has no word/document.xml", ErrUnsupportedFormat, source)
+		}
+
+		text, err := docxText(body)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: parse docx %s: %w", source, err)
+		}
+		doc := newDocument(source, FormatDocx, text)
+		if core != nil {
+			// Core properties are optional; a malformed part should not fail the load.
+			if props, err := docxCoreProperties(core); err == nil {
+				if props.Title != "" {
+					doc.Metadata[MetaTitle] = props.Title
+				}
+				if props.Creator != "" {
+					doc.Metadata[MetaAuthor] = props.Creator
+				}
+			}
+		}
+		return doc, nil
+	})
+}
+
+// docxText walks the document XML, joining text runs (w:t) and breaking on
+// paragraphs (w:p), line breaks (w:br) and tabs (w:tab).
+func docxText(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var (
+		out       strings.Builder
+		paragraph strings.Builder
+		inText    bool
+	)
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				paragraph.WriteByte('\t')
+			case "br", "cr":
+				paragraph.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if line := strings.TrimSpace(paragraph.String()); line != "" {
+					if out.Len() > 0 {
+						out.WriteString("\n\n")
+					}
+					out.WriteString(line)
+				}
+				paragraph.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				paragraph.Write(t)
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+type docxCore struct {
+	Title   string `xml:"title"`
+	Creator string `xml:"creator"`
+}
+
+func docxCoreProperties(f *zip.File) (docxCore, error) {
+	var props docxCore
+	rc, err := f.Open()
+	if err != nil {
+		return props, err
+	}
+	defer rc.Close()
+	err = xml.NewDecoder(rc).Decode(&props)
+	props.Title = strings.TrimSpace(props.Title)
+	props.Creator = strings.TrimSpace(props.Creator)
+	return props, err
+}
@@ -0,0 +1,220 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultRRFK is the rank constant used by reciprocal rank fusion.
+const DefaultRRFK = 60
+
+// ErrNoSearchBackend is returned when a Retriever cannot serve its search mode.
+var ErrNoSearchBackend = errors.New("retrieval: no search backend configured for mode")
+
+// SearchMode selects how a Retriever finds candidates.
+type SearchMode string
+
+const (
+	SearchVector  SearchMode = "vector"  // Embedding similarity only
+	SearchKeyword SearchMode = "keyword" // Keyword index only
+	SearchHybrid  SearchMode = "hybrid"  // Both, merged with reciprocal rank fusion
+)
+
+// Retriever answers text queries from a VectorStore, a KeywordIndex, or both.
+type Retriever struct {
+	Store    VectorStore
+	Embedder providers.Embedder
+	Keyword  KeywordIndex
+
+	// Mode defaults to SearchHybrid when both backends are set, otherwise to
+	// whichever backend is available.
+	Mode SearchMode
+	// CandidateK is how many results each backend contributes before fusion
+	// and reranking. Defaults to 4x the requested TopK.
+	CandidateK int
+	// RRFK is the reciprocal rank fusion constant; defaults to DefaultRRFK.
+	RRFK int
+	// KeywordWeight and VectorWeight scale each list's fused score; both default to 1.
+	KeywordWeight float64
+	VectorWeight  float64
+
+	// Reranker, when set, re-scores the candidates before the top results are returned.
+	Reranker Reranker
+	// MinScore drops reranked matches scoring below it. Only applies with a Reranker.
+	MinScore float64
+	// OnRerank is called after every reranking pass with the before/after rankings.
+	OnRerank func(ctx context.Context, trace RerankTrace)
+}
+
+// Retrieve returns the best matches for query.
+//
+// In hybrid mode the returned Score is the fused RRF score, not a similarity,
+// and is only meaningful for ordering. With a Reranker the Score is the
+// reranker's relevance score.
+func (r *Retriever) Retrieve(ctx context.Context, query string, opts QueryOptions) ([]Match, error) {
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = defaultTopK
+	}
+	candidateK := topK
+	if r.Reranker != nil || r.mode() == SearchHybrid {
+		candidateK = r.CandidateK
+		if candidateK <= 0 {
+			candidateK = topK * 4
+		}
+	}
+
+	matches, err := r.search(ctx, query, QueryOptions{TopK: candidateK, Filter: opts.Filter})
+	if err != nil {
+		return nil, err
+	}
+	if r.Reranker != nil {
+		if matches, err = r.rerank(ctx, query, matches); err != nil {
+			return nil, err
+		}
+	}
+	return truncateMatches(matches, topK), nil
+}
+
+// search returns up to opts.TopK candidates from the configured backends.
+func (r *Retriever) search(ctx context.Context, query string, opts QueryOptions) ([]Match, error) {
+	switch mode := r.mode(); mode {
+	case SearchVector:
+		if r.Store == nil || r.Embedder == nil {
+			return nil, fmt.Errorf("%w %q", ErrNoSearchBackend, mode)
+		}
+		return r.vectorSearch(ctx, query, opts)
+	case SearchKeyword:
+		if r.Keyword == nil {
+			return nil, fmt.Errorf("%w %q", ErrNoSearchBackend, mode)
+		}
+		return r.Keyword.Search(ctx, query, opts)
+	case SearchHybrid:
+		if r.Keyword == nil || r.Store == nil || r.Embedder == nil {
+			return nil, fmt.Errorf("%w %q", ErrNoSearchBackend, mode)
+		}
+		vectorMatches, err := r.vectorSearch(ctx, query, opts)
+		if err != nil {
+			return nil, err
+		}
+		keywordMatches, err := r.Keyword.Search(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: keyword search: %w", err)
+		}
+		fused := FuseRanked(r.RRFK,
+			RankedList{Matches: vectorMatches, Weight: r.VectorWeight},
+			RankedList{Matches: keywordMatches, Weight: r.KeywordWeight},
+		)
+		return truncateMatches(fused, opts.TopK), nil
+	default:
+		return nil, fmt.Errorf("retrieval: unknown search mode %q", mode)
+	}
+}
+
+// rerank re-scores matches, applies MinScore and reports the change to OnRerank.
+func (r *Retriever) rerank(ctx context.Context, query string, matches []Match) ([]Match, error) {
+	if len(matches) == 0 {
+		return matches, nil
+	}
+	start := time.Now()
+	reranked, err := r.Reranker.Rerank(ctx, query, matches)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: rerank: %w", err)
+	}
+
+	kept := reranked[:0:0]
+	for _, m := range reranked {
+		if m.Score >= r.MinScore {
+			kept = append(kept, m)
+		}
+	}
+
+	if r.OnRerank != nil {
+		r.OnRerank(ctx, RerankTrace{
+			Query:     query,
+			Before:    rankedIDs(matches),
+			After:     rankedIDs(kept),
+			Threshold: r.MinScore,
+			Dropped:   len(reranked) - len(kept),
+			Duration:  time.Since(start),
+		})
+	}
+	return kept, nil
+}
+
+func (r *Retriever) mode() SearchMode {
+	if r.Mode != "" {
+		return r.Mode
+	}
+	switch {
+	case r.Keyword != nil && r.Store != nil:
+		return SearchHybrid
+	case r.Keyword != nil:
+		return SearchKeyword
+	default:
+		return SearchVector
+	}
+}
+
+func (r *Retriever) vectorSearch(ctx context.Context, query string, opts QueryOptions) ([]Match, error) {
+	vectors, err := r.Embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("retrieval: embedder returned %d vectors for 1 query", len(vectors))
+	}
+	return r.Store.Query(ctx, vectors[0], opts)
+}
+
+// RankedList is one input to FuseRanked. A zero Weight counts as 1.
+type RankedList struct {
+	Matches []Match
+	Weight  float64
+}
+
+// ReciprocalRankFusion merges ranked result lists with equal weight.
+// See FuseRanked.
+func ReciprocalRankFusion(k int, lists ...[]Match) []Match {
+	ranked := make([]RankedList, len(lists))
+	for i, matches := range lists {
+		ranked[i] = RankedList{Matches: matches}
+	}
+	return FuseRanked(k, ranked...)
+}
+
+// FuseRanked merges ranked lists with reciprocal rank fusion: each record
+// scores the sum of weight/(k+rank) over the lists it appears in, so items
+// ranked well by several retrievers rise to the top regardless of how each
+// retriever scales its own scores. A k of zero or less uses DefaultRRFK.
+// Records are identified by ID; the first occurrence supplies content and metadata.
+func FuseRanked(k int, lists ...RankedList) []Match {
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+	scores := make(map[string]float64)
+	records := make(map[string]Record)
+	for _, list := range lists {
+		weight := list.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		for rank, match := range list.Matches {
+			scores[match.ID] += weight / float64(k+rank+1)
+			if _, ok := records[match.ID]; !ok {
+				records[match.ID] = match.Record
+			}
+		}
+	}
+
+	fused := make([]Match, 0, len(scores))
+	for id, score := range scores {
+		fused = append(fused, Match{Record: records[id], Score: score})
+	}
+	sortMatches(fused)
+	return fused
+}
@@ -0,0 +1,184 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	got := Tokenize("Error ERR-4012 in v1.2.3, see docs/setup.")
+	want := []string{"error", "err-4012", "err", "4012", "in", "v1.2.3", "v1", "2", "3", "see", "docs/setup", "docs", "setup"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize() = %q, want %q", got, want)
+	}
+}
+
+func TestBM25Index(t *testing.T) {
+	ctx := context.Background()
+	idx := NewBM25Index()
+	err := idx.Index(ctx,
+		Record{ID: "a", Content: "Reset your password from the account settings page."},
+		Record{ID: "b", Content: "Error ERR-4012 means the password reset token expired.", Metadata: Metadata{"lang": "en"}},
+		Record{ID: "c", Content: "Billing settings and invoices."},
+	)
+	if err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+
+	matches, err := idx.Search(ctx, "ERR-4012", QueryOptions{})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != "b" {
+		t.Fatalf("matches = %+v, want only b", matches)
+	}
+
+	matches, _ = idx.Search(ctx, "settings", QueryOptions{TopK: 5})
+	if len(matches) != 2 {
+		t.Errorf("settings matches = %d, want 2", len(matches))
+	}
+	matches, _ = idx.Search(ctx, "password", QueryOptions{Filter: Metadata{"lang": "en"}})
+	if len(matches) != 1 || matches[0].ID != "b" {
+		t.Errorf("filtered matches = %+v", matches)
+	}
+
+	// Replacing and deleting keep the postings consistent.
+	_ = idx.Index(ctx, Record{ID: "b", Content: "Nothing relevant."})
+	if matches, _ := idx.Search(ctx, "ERR-4012", QueryOptions{}); len(matches) != 0 {
+		t.Errorf("stale postings after replace: %+v", matches)
+	}
+	_ = idx.Delete(ctx, "a", "missing")
+	if idx.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", idx.Len())
+	}
+}
+
+func TestReciprocalRankFusion(t *testing.T) {
+	rec := func(id string) Match { return Match{Record: Record{ID: id}} }
+	fused := ReciprocalRankFusion(60,
+		[]Match{rec("x"), rec("y"), rec("z")},
+		[]Match{rec("y"), rec("w")},
+	)
+	var ids []string
+	for _, m := range fused {
+		ids = append(ids, m.ID)
+	}
+	if want := []string{"y", "x", "w", "z"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("fused order = %v, want %v", ids, want)
+	}
+
+	weighted := FuseRanked(60,
+		RankedList{Matches: []Match{rec("x"), rec("y")}},
+		RankedList{Matches: []Match{rec("z")}, Weight: 3},
+	)
+	if weighted[0].ID != "z" {
+		t.Errorf("weighted top = %s, want z", weighted[0].ID)
+	}
+}
+
+func TestRetriever_HybridFindsExactIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	keyword := NewBM25Index()
+	records := []Record{
+		{ID: "general", Content: "How to fix login problems and sign-in failures."},
+		{ID: "code", Content: "ERR-4012: token expired, request a new link."},
+		{ID: "billing", Content: "Update your card on the billing page."},
+	}
+	embedder := semanticStub{}
+	for i := range records {
+		vectors, _ := embedder.Embed(ctx, []string{records[i].Content})
+		records[i].Vector = vectors[0]
+	}
+	if err := store.Upsert(ctx, records...); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyword.Index(ctx, records...); err != nil {
+		t.Fatal(err)
+	}
+
+	query := "login fails with ERR-4012"
+	vectorOnly := &Retriever{Store: store, Embedder: embedder}
+	matches, err := vectorOnly.Retrieve(ctx, query, QueryOptions{TopK: 1})
+	if err != nil {
+		t.Fatalf("vector Retrieve() error = %v", err)
+	}
+	if matches[0].ID != "general" {
+		t.Fatalf("vector top = %s, want general", matches[0].ID)
+	}
+
+	hybrid := &Retriever{Store: store, Embedder: embedder, Keyword: keyword}
+	matches, err = hybrid.Retrieve(ctx, query, QueryOptions{TopK: 2})
+	if err != nil {
+		t.Fatalf("hybrid Retrieve() error = %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("hybrid matches = %d, want 2", len(matches))
+	}
+	if matches[0].ID != "code" && matches[1].ID != "code" {
+		t.Errorf("hybrid results %+v do not include the exact identifier match", matches)
+	}
+
+	if _, err := (&Retriever{Mode: SearchHybrid, Keyword: keyword}).Retrieve(ctx, query, QueryOptions{}); !errors.Is(err, ErrNoSearchBackend) {
+		t.Errorf("error = %v, want ErrNoSearchBackend", err)
+	}
+}
+
+func TestIngest_WithKeywordIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir+"/faq.txt", "Invoices are emailed monthly. Contact billing for refunds.")
+
+	store := NewMemoryStore()
+	keyword := NewBM25Index()
+	result, err := Ingest(context.Background(), dir, store, WithEmbedder(&lengthEmbedder{}), WithKeywordIndex(keyword))
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if keyword.Len() != result.Chunks || keyword.Len() == 0 {
+		t.Errorf("keyword index has %d records, want %d", keyword.Len(), result.Chunks)
+	}
+}
+
+func TestPostgresKeywordIndex_Identifiers(t *testing.T) {
+	index := NewPostgresKeywordIndex(nil, "docs; DROP TABLE users")
+	if _, err := index.Search(context.Background(), "x", QueryOptions{}); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("error = %v, want ErrInvalidIdentifier", err)
+	}
+
+	query := postgresSearchQuery("rag.chunks", true)
+	for _, want := range []string{"FROM rag.chunks", "websearch_to_tsquery($1::regconfig, $2)", "metadata @> $3::jsonb", "LIMIT $4"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+	if query := postgresSearchQuery("chunks", false); !strings.Contains(query, "LIMIT $3") || strings.Contains(query, "jsonb") {
+		t.Errorf("unfiltered query:\n%s", query)
+	}
+}
+
+// semanticStub embeds text on a "login" axis and a "billing" axis, ignoring
+// identifiers the way real embeddings blur them.
+type semanticStub struct{}
+
+func (semanticStub) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		text = strings.ToLower(text)
+		var login, billing float32 = 0.1, 0.1
+		for _, word := range []string{"login", "sign-in", "fail"} {
+			if strings.Contains(text, word) {
+				login++
+			}
+		}
+		for _, word := range []string{"billing", "card", "invoice"} {
+			if strings.Contains(text, word) {
+				billing++
+			}
+		}
+		vectors[i] = []float32{login, billing}
+	}
+	return vectors, nil
+}
@@ -0,0 +1,340 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// DefaultEmbedBatchSize is the number of chunks embedded per Embedder call.
+const DefaultEmbedBatchSize = 64
+
+// staleChunkPage is how many chunks of a re-ingested document are looked up
+// per query when deleting its previous version.
+const staleChunkPage = 1000
+
+const maxRemoteDocumentBytes = 64 << 20
+
+// MetadataExtractor derives additional metadata from a loaded document.
+// Returned entries are merged into the document metadata and copied onto every chunk.
+type MetadataExtractor func(ctx context.Context, doc *Document) (Metadata, error)
+
+// IngestResult summarizes an ingestion run.
+type IngestResult struct {
+	Documents int      // Documents loaded and stored
+	Chunks    int      // Chunks embedded and upserted
+	Skipped   []string // Directory entries skipped because their format is unknown
+}
+
+type ingestConfig struct {
+	embedder   providers.Embedder
+	keyword    KeywordIndex
+	chunker    Chunker
+	loader     Loader
+	metadata   Metadata
+	extractors []MetadataExtractor
+	batchSize  int
+	client     *http.Client
+}
+
+// IngestOption configures Ingest.
+type IngestOption func(*ingestConfig)
+
+// WithEmbedder sets the embedder used to vectorize chunks. It is required.
+func WithEmbedder(embedder providers.Embedder) IngestOption {
+	return func(c *ingestConfig) {
+		c.embedder = embedder
+	}
+}
+
+// WithKeywordIndex also indexes every chunk in a keyword index for hybrid retrieval.
+func WithKeywordIndex(index KeywordIndex) IngestOption {
+	return func(c *ingestConfig) {
+		c.keyword = index
+	}
+}
+
+// WithChunker sets the chunking strategy. Defaults to a SentenceChunker.
+func WithChunker(chunker Chunker) IngestOption {
+	return func(c *ingestConfig) {
+		c.chunker = chunker
+	}
+}
+
+// WithLoader forces a loader instead of detecting the format per source.
+func WithLoader(loader Loader) IngestOption {
+	return func(c *ingestConfig) {
+		c.loader = loader
+	}
+}
+
+// WithMetadata adds static metadata to every ingested chunk.
+func WithMetadata(metadata Metadata) IngestOption {
+	return func(c *ingestConfig) {
+		for k, v := range metadata {
+			c.metadata[k] = v
+		}
+	}
+}
+
+// WithMetadataExtractor adds an extractor that runs on every loaded document.
+func WithMetadataExtractor(extractor MetadataExtractor) IngestOption {
+	return func(c *ingestConfig) {
+		c.extractors = append(c.extractors, extractor)
+	}
+}
+
+// WithBatchSize sets how many chunks are embedded per Embedder call.
+func WithBatchSize(size int) IngestOption {
+	return func(c *ingestConfig) {
+		c.batchSize = size
+	}
+}
+
+// WithHTTPClient sets the client used to download http(s) sources.
+func WithHTTPClient(client *http.Client) IngestOption {
+	return func(c *ingestConfig) {
+		c.client = client
+	}
+}
+
+// Ingest loads source, chunks it, embeds the chunks and upserts them into store.
+//
+// source may be a file path, a directory (walked recursively; files with an
+// unknown format are skipped) or an http(s) URL. Each chunk is stored with the
+// ID "<source>#<index>" and metadata including source, format, title,
+// document_id and chunk_index. Re-ingesting a source replaces its chunks: the
+// ones stored for the previous version are deleted first, found through a
+// document_id filter.
+func Ingest(ctx context.Context, source string, store VectorStore, opts ...IngestOption) (*IngestResult, error) {
+	cfg := ingestConfig{
+		chunker:   SentenceChunker{MaxChars: DefaultChunkSize, Overlap: 1},
+		metadata:  Metadata{},
+		batchSize: DefaultEmbedBatchSize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.embedder == nil {
+		return nil, ErrNoEmbedder
+	}
+	if cfg.batchSize <= 0 {
+		cfg.batchSize = DefaultEmbedBatchSize
+	}
+
+	result := &IngestResult{}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		doc, err := cfg.loadURL(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return result, cfg.ingestDocument(ctx, doc, store, result)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: %w", err)
+	}
+	if !info.IsDir() {
+		doc, err := cfg.loadFile(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return result, cfg.ingestDocument(ctx, doc, store, result)
+	}
+
+	err = filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != source && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if cfg.loader == nil && DetectFormat(path, "") == "" {
+			result.Skipped = append(result.Skipped, path)
+			return nil
+		}
+		doc, err := cfg.loadFile(ctx, path)
+		if err != nil {
+			return err
+		}
+		return cfg.ingestDocument(ctx, doc, store, result)
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (c *ingestConfig) loadFile(ctx context.Context, path string) (*Document, error) {
+	loader, err := c.loaderFor(path, "")
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: %w", err)
+	}
+	defer f.Close()
+
+	doc, err := loader.Load(ctx, f, path)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err == nil {
+		doc.Metadata["modified_at"] = info.ModTime().UTC().Format(time.RFC3339)
+	}
+	return doc, nil
+}
+
+func (c *ingestConfig) loadURL(ctx context.Context, rawURL string) (*Document, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: build request: %w", err)
+	}
+	client := c.client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("retrieval: fetch %s: unexpected status %s", rawURL, resp.Status)
+	}
+
+	loader, err := c.loaderFor(rawURL, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(ctx, io.LimitReader(resp.Body, maxRemoteDocumentBytes), rawURL)
+}
+
+func (c *ingestConfig) loaderFor(source, contentType string) (Loader, error) {
+	if c.loader != nil {
+		return c.loader, nil
+	}
+	format := DetectFormat(source, contentType)
+	if format == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, source)
+	}
+	return LoaderFor(format)
+}
+
+// ingestDocument extracts metadata, chunks, embeds and upserts a single document.
+func (c *ingestConfig) ingestDocument(ctx context.Context, doc *Document, store VectorStore, result *IngestResult) error {
+	if doc.Metadata == nil {
+		doc.Metadata = Metadata{}
+	}
+	if doc.ID == "" {
+		doc.ID = doc.Source
+	}
+	doc.Metadata[MetaWordCount] = len(strings.Fields(doc.Content))
+	for k, v := range c.metadata {
+		doc.Metadata[k] = v
+	}
+	for _, extract := range c.extractors {
+		extra, err := extract(ctx, doc)
+		if err != nil {
+			return fmt.Errorf("retrieval: extract metadata from %s: %w", doc.Source, err)
+		}
+		for k, v := range extra {
+			doc.Metadata[k] = v
+		}
+	}
+
+	chunks, err := c.chunker.Chunk(ctx, doc.Content)
+	if err != nil {
+		return fmt.Errorf("retrieval: chunk %s: %w", doc.Source, err)
+	}
+
+	for start := 0; start < len(chunks); start += c.batchSize {
+		batch := chunks[start:min(start+c.batchSize, len(chunks))]
+		vectors, err := c.embedder.Embed(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("retrieval: embed %s: %w", doc.Source, err)
+		}
+		if len(vectors) != len(batch) {
+			return fmt.Errorf("retrieval: embedder returned %d vectors for %d chunks", len(vectors), len(batch))
+		}
+		if start == 0 {
+			if err := c.deleteChunks(ctx, doc, store, vectors[0]); err != nil {
+				return err
+			}
+		}
+
+		records := make([]Record, len(batch))
+		for i, chunk := range batch {
+			index := start + i
+			metadata := cloneMetadata(doc.Metadata)
+			metadata[MetaDocumentID] = doc.ID
+			metadata[MetaChunkIndex] = index
+			records[i] = Record{
+				ID:       doc.ID + "#" + strconv.Itoa(index),
+				Content:  chunk,
+				Vector:   vectors[i],
+				Metadata: metadata,
+			}
+		}
+		if err := store.Upsert(ctx, records...); err != nil {
+			return fmt.Errorf("retrieval: upsert %s: %w", doc.Source, err)
+		}
+		if c.keyword != nil {
+			if err := c.keyword.Index(ctx, records...); err != nil {
+				return fmt.Errorf("retrieval: keyword index %s: %w", doc.Source, err)
+			}
+		}
+	}
+
+	result.Documents++
+	result.Chunks += len(chunks)
+	return nil
+}
+
+// deleteChunks removes the chunks stored for doc by an earlier ingestion.
+// They are found with a query filtered on the document ID; probe is any
+// vector of the store's dimension.
+func (c *ingestConfig) deleteChunks(ctx context.Context, doc *Document, store VectorStore, probe []float32) error {
+	seen := make(map[string]bool)
+	for {
+		matches, err := store.Query(ctx, probe, QueryOptions{TopK: staleChunkPage, Filter: Metadata{MetaDocumentID: doc.ID}})
+		if err != nil {
+			return fmt.Errorf("retrieval: find chunks of %s: %w", doc.Source, err)
+		}
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			if !seen[match.ID] {
+				seen[match.ID] = true
+				ids = append(ids, match.ID)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := store.Delete(ctx, ids...); err != nil {
+			return fmt.Errorf("retrieval: delete chunks of %s: %w", doc.Source, err)
+		}
+		if c.keyword != nil {
+			if err := c.keyword.Delete(ctx, ids...); err != nil {
+				return fmt.Errorf("retrieval: delete keyword entries of %s: %w", doc.Source, err)
+			}
+		}
+		if len(matches) < staleChunkPage {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// lengthEmbedder embeds text as [len, vowels] and counts calls.
+type lengthEmbedder struct {
+	calls int
+}
+
+func (e *lengthEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float32{float32(len(text)), float32(strings.Count(text, "a") + 1)}
+	}
+	return vectors, nil
+}
+
+func TestIngest_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "guide.md"), "# Guide\n\nInstall the CLI. Run the setup command. Enjoy.")
+	writeFile(t, filepath.Join(dir, "sub", "page.html"), "<html><head><title>FAQ</title></head><body><p>Answers live here.</p></body></html>")
+	writeFile(t, filepath.Join(dir, "image.png"), "\x89PNG")
+	writeFile(t, filepath.Join(dir, ".git", "config.txt"), "ignored")
+
+	store := NewMemoryStore()
+	embedder := &lengthEmbedder{}
+	result, err := Ingest(context.Background(), dir, store,
+		WithEmbedder(embedder),
+		WithChunker(SentenceChunker{MaxChars: 30}),
+		WithMetadata(Metadata{"tenant": "acme"}),
+		WithBatchSize(1),
+	)
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if result.Documents != 2 {
+		t.Errorf("Documents = %d, want 2", result.Documents)
+	}
+	if result.Chunks != store.Len() || result.Chunks < 3 {
+		t.Errorf("Chunks = %d, store has %d", result.Chunks, store.Len())
+	}
+	if len(result.Skipped) != 1 || !strings.HasSuffix(result.Skipped[0], "image.png") {
+		t.Errorf("Skipped = %v", result.Skipped)
+	}
+	if embedder.calls != result.Chunks {
+		t.Errorf("embed calls = %d, want one per chunk with batch size 1", embedder.calls)
+	}
+
+	matches, err := store.Query(context.Background(), []float32{17, 2}, QueryOptions{TopK: 10, Filter: Metadata{MetaTitle: "FAQ"}})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("matches = %d, want 1", len(matches))
+	}
+	match := matches[0]
+	if match.Content != "Answers live here." || match.Metadata["tenant"] != "acme" || match.Metadata[MetaChunkIndex] != 0 {
+		t.Errorf("match = %+v", match)
+	}
+	if !strings.HasSuffix(match.ID, "page.html#0") || match.Metadata[MetaFormat] != FormatHTML {
+		t.Errorf("match ID/format = %s/%v", match.ID, match.Metadata[MetaFormat])
+	}
+}
+
+func TestIngest_ReplacesPreviousVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	store, keyword := NewMemoryStore(), NewBM25Index()
+	ingest := func(content string) {
+		t.Helper()
+		writeFile(t, path, content)
+		opts := []IngestOption{WithEmbedder(&lengthEmbedder{}), WithKeywordIndex(keyword), WithChunker(SentenceChunker{MaxChars: 20})}
+		if _, err := Ingest(context.Background(), path, store, opts...); err != nil {
+			t.Fatalf("Ingest() error = %v", err)
+		}
+	}
+
+	ingest("First old sentence. Second old sentence. Third old sentence.")
+	ingest("Only the new one.")
+	if store.Len() != 1 {
+		t.Errorf("store has %d chunks, want only the new version's", store.Len())
+	}
+	matches, err := keyword.Search(context.Background(), "old sentence", QueryOptions{TopK: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("keyword index still has %d stale chunks", len(matches))
+	}
+}
+
+func TestIngest_URLAndExtractor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown")
+		_, _ = w.Write([]byte("# Changelog\n\nVersion two adds search."))
+	}))
+	defer server.Close()
+
+	store := NewMemoryStore()
+	_, err := Ingest(context.Background(), server.URL+"/changelog", store,
+		WithEmbedder(&lengthEmbedder{}),
+		WithMetadataExtractor(func(_ context.Context, doc *Document) (Metadata, error) {
+			return Metadata{"mentions_search": strings.Contains(doc.Content, "search")}, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	matches, err := store.Query(context.Background(), []float32{1, 1}, QueryOptions{Filter: Metadata{"mentions_search": true}})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(matches) != 1 || matches[0].Metadata[MetaTitle] != "Changelog" {
+		t.Errorf("matches = %+v", matches)
+	}
+}
+
+func TestIngest_Errors(t *testing.T) {
+	store := NewMemoryStore()
+	if _, err := Ingest(context.Background(), "missing.md", store); !errors.Is(err, ErrNoEmbedder) {
+		t.Errorf("error = %v, want ErrNoEmbedder", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "image.png")
+	writeFile(t, path, "data")
+	if _, err := Ingest(context.Background(), path, store, WithEmbedder(&lengthEmbedder{})); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
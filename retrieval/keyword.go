@@ -0,0 +1,191 @@
+package retrieval
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Default BM25 parameters.
+const (
+	DefaultBM25K1 = 1.2
+	DefaultBM25B  = 0.75
+)
+
+// KeywordIndex is a lexical index searched by query terms rather than vectors.
+// It complements a VectorStore for exact identifiers, codes and names that
+// embeddings tend to blur.
+type KeywordIndex interface {
+	Index(ctx context.Context, records ...Record) error
+	Search(ctx context.Context, query string, opts QueryOptions) ([]Match, error)
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// BM25Index is an in-memory KeywordIndex ranked with Okapi BM25.
+// It is safe for concurrent use.
+type BM25Index struct {
+	K1 float64 // Term frequency saturation; defaults to DefaultBM25K1
+	B  float64 // Length normalization; defaults to DefaultBM25B
+
+	mu          sync.RWMutex
+	docs        map[string]bm25Doc
+	postings    map[string]map[string]int // term -> record ID -> term frequency
+	totalLength int
+}
+
+type bm25Doc struct {
+	record Record
+	length int
+}
+
+// NewBM25Index creates an empty BM25 index with default parameters.
+func NewBM25Index() *BM25Index {
+	return &BM25Index{
+		K1:       DefaultBM25K1,
+		B:        DefaultBM25B,
+		docs:     make(map[string]bm25Doc),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// Index adds or replaces records by ID. Vectors are not retained.
+func (idx *BM25Index) Index(ctx context.Context, records ...Record) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, record := range records {
+		idx.remove(record.ID)
+		terms := Tokenize(record.Content)
+		record.Vector = nil
+		record.Metadata = cloneMetadata(record.Metadata)
+		idx.docs[record.ID] = bm25Doc{record: record, length: len(terms)}
+		idx.totalLength += len(terms)
+		for _, term := range terms {
+			postings, ok := idx.postings[term]
+			if !ok {
+				postings = make(map[string]int)
+				idx.postings[term] = postings
+			}
+			postings[record.ID]++
+		}
+	}
+	return nil
+}
+
+// Search returns the records that best match the query terms.
+func (idx *BM25Index) Search(ctx context.Context, query string, opts QueryOptions) ([]Match, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if len(idx.docs) == 0 {
+		return nil, nil
+	}
+	k1, b := idx.K1, idx.B
+	if k1 <= 0 {
+		k1 = DefaultBM25K1
+	}
+	if b < 0 || b > 1 {
+		b = DefaultBM25B
+	}
+	n := float64(len(idx.docs))
+	avgLength := float64(idx.totalLength) / n
+
+	scores := make(map[string]float64)
+	for _, term := range uniqueTerms(Tokenize(query)) {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range postings {
+			length := float64(idx.docs[id].length)
+			freq := float64(tf)
+			scores[id] += idf * freq * (k1 + 1) / (freq + k1*(1-b+b*length/avgLength))
+		}
+	}
+
+	matches := make([]Match, 0, len(scores))
+	for id, score := range scores {
+		record := idx.docs[id].record
+		if !matchesFilter(record.Metadata, opts.Filter) {
+			continue
+		}
+		matches = append(matches, Match{Record: record, Score: score})
+	}
+	sortMatches(matches)
+	return truncateMatches(matches, opts.TopK), nil
+}
+
+// Delete removes records by ID. Unknown IDs are ignored.
+func (idx *BM25Index) Delete(ctx context.Context, ids ...string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, id := range ids {
+		idx.remove(id)
+	}
+	return nil
+}
+
+// Len returns the number of indexed records.
+func (idx *BM25Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+func (idx *BM25Index) remove(id string) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range Tokenize(doc.record.Content) {
+		if postings := idx.postings[term]; postings != nil {
+			delete(postings, id)
+			if len(postings) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.docs, id)
+}
+
+// Tokenize lowercases text and splits it into search terms. Identifiers
+// joined by '-', '.', '/' or ':' (such as "ERR-4012" or "v1.2.3") are kept
+// whole in addition to their parts, so exact codes match exactly.
+func Tokenize(text string) []string {
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' }
+	isJoiner := func(r rune) bool { return r == '-' || r == '.' || r == '/' || r == ':' }
+
+	var terms []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !isWord(r) && !isJoiner(r)
+	}) {
+		field = strings.TrimFunc(field, isJoiner)
+		if field == "" {
+			continue
+		}
+		parts := strings.FieldsFunc(field, isJoiner)
+		if len(parts) > 1 {
+			terms = append(terms, field)
+		}
+		terms = append(terms, parts...)
+	}
+	return terms
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := terms[:0:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,211 @@
+package retrieval
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/internal/htmltext"
+)
+
+// Document formats recognized by the built-in loaders.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+	FormatDocx     = "docx"
+)
+
+// Loader parses raw bytes into a Document.
+type Loader interface {
+	Load(ctx context.Context, r io.Reader, source string) (*Document, error)
+}
+
+// LoaderFunc adapts a function to the Loader interface.
+type LoaderFunc func(ctx context.Context, r io.Reader, source string) (*Document, error)
+
+// Load calls f.
+func (f LoaderFunc) Load(ctx context.Context, r io.Reader, source string) (*Document, error) {
+	return f(ctx, r, source)
+}
+
+// DetectFormat guesses a document format from its file extension or media type.
+// It returns an empty string when the format is unknown.
+func DetectFormat(source, contentType string) string {
+	switch strings.ToLower(filepath.Ext(stripQuery(source))) {
+	case ".txt", ".text", ".log", ".csv":
+		return FormatText
+	case ".md", ".markdown", ".mdx":
+		return FormatMarkdown
+	case ".html", ".htm", ".xhtml":
+		return FormatHTML
+	case ".pdf":
+		return FormatPDF
+	case ".docx":
+		return FormatDocx
+	}
+
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil {
+			switch mediaType {
+			case "text/html", "application/xhtml+xml":
+				return FormatHTML
+			case "text/markdown", "text/x-markdown":
+				return FormatMarkdown
+			case "application/pdf":
+				return FormatPDF
+			case "application/vnd.openxmlformats-officedocument.wordprocessingml.document":
+				return FormatDocx
+			}
+			if strings.HasPrefix(mediaType, "text/") {
+				return FormatText
+			}
+		}
+	}
+	return ""
+}
+
+// LoaderFor returns the built-in loader for format.
+func LoaderFor(format string) (Loader, error) {
+	switch format {
+	case FormatText:
+		return TextLoader(), nil
+	case FormatMarkdown:
+		return MarkdownLoader(), nil
+	case FormatHTML:
+		return HTMLLoader(), nil
+	case FormatPDF:
+		return PDFLoader(), nil
+	case FormatDocx:
+		return DocxLoader(), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedFormat, format)
+}
+
+// TextLoader loads plain text documents.
+func TextLoader() Loader {
+	return LoaderFunc(func(ctx context.Context, r io.Reader, source string) (*Document, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: read %s: %w", source, err)
+		}
+		return newDocument(source, FormatText, strings.TrimSpace(string(data))), nil
+	})
+}
+
+// MarkdownLoader loads Markdown documents. YAML-style front matter
+// ("key: value" lines between --- fences) becomes document metadata, and the
+// first level-one heading becomes the title when front matter sets none.
+func MarkdownLoader() Loader {
+	return LoaderFunc(func(ctx context.Context, r io.Reader, source string) (*Document, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: read %s: %w", source, err)
+		}
+		frontMatter, body := splitFrontMatter(string(data))
+		doc := newDocument(source, FormatMarkdown, strings.TrimSpace(body))
+		for key, value := range frontMatter {
+			doc.Metadata[key] = value
+		}
+		if _, ok := doc.Metadata[MetaTitle]; !ok {
+			if title := firstHeading(body); title != "" {
+				doc.Metadata[MetaTitle] = title
+			}
+		}
+		return doc, nil
+	})
+}
+
+// HTMLLoader loads HTML documents, keeping only the readable main text.
+func HTMLLoader() Loader {
+	return LoaderFunc(func(ctx context.Context, r io.Reader, source string) (*Document, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: read %s: %w", source, err)
+		}
+		title, text := htmltext.Extract(string(data))
+		doc := newDocument(source, FormatHTML, text)
+		if title != "" {
+			doc.Metadata[MetaTitle] = title
+		}
+		return doc, nil
+	})
+}
+
+func newDocument(source, format, content string) *Document {
+	return &Document{
+		ID:      source,
+		Source:  source,
+		Content: content,
+		Metadata: Metadata{
+			MetaSource: source,
+			MetaFormat: format,
+		},
+	}
+}
+
+// splitFrontMatter separates a leading --- fenced block of "key: value" lines from the body.
+func splitFrontMatter(text string) (map[string]string, string) {
+	text = strings.TrimPrefix(text, "\ufeff")
+	if !strings.HasPrefix(text, "---\n") && !strings.HasPrefix(text, "---\r\n") {
+		return nil, text
+	}
+	rest := text[strings.Index(text, "\n")+1:]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return nil, text
+	}
+
+	values := make(map[string]string)
+	for _, line := range strings.Split(rest[:end], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if key != "" && value != "" {
+			values[key] = value
+		}
+	}
+
+	body := rest[end+len("\n---"):]
+	if i := strings.Index(body, "\n"); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+	return values, body
+}
+
+func firstHeading(markdown string) string {
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ""
+}
+
+func stripQuery(source string) string {
+	if i := strings.IndexAny(source, "?#"); i >= 0 {
+		return source[:i]
+	}
+	return source
+}
+
+// readAllAt reads r fully and returns a ReaderAt over its content.
+func readAllAt(r io.Reader) (*bytes.Reader, int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
@@ -0,0 +1,179 @@
+package retrieval
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		source, contentType, want string
+	}{
+		{source: "notes/readme.md", want: FormatMarkdown},
+		{source: "report.PDF", want: FormatPDF},
+		{source: "contract.docx", want: FormatDocx},
+		{source: "https://example.com/page.html?x=1", want: FormatHTML},
+		{source: "https://example.com/page", contentType: "text/html; charset=utf-8", want: FormatHTML},
+		{source: "https://example.com/doc", contentType: "application/pdf", want: FormatPDF},
+		{source: "data.txt", want: FormatText},
+		{source: "image.png", want: ""},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.source, tt.contentType); got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, want %q", tt.source, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownLoader_FrontMatterAndTitle(t *testing.T) {
+	input := "---\ntitle: \"Onboarding\"\nteam: platform\n---\n# Ignored Heading\n\nWelcome aboard."
+	doc, err := MarkdownLoader().Load(context.Background(), strings.NewReader(input), "onboarding.md")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if doc.Metadata[MetaTitle] != "Onboarding" || doc.Metadata["team"] != "platform" {
+		t.Errorf("metadata = %v", doc.Metadata)
+	}
+	if !strings.HasPrefix(doc.Content, "# Ignored Heading") || strings.Contains(doc.Content, "team:") {
+		t.Errorf("content = %q", doc.Content)
+	}
+
+	doc, err = MarkdownLoader().Load(context.Background(), strings.NewReader("Intro\n\n# Guide\n\nBody"), "guide.md")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if doc.Metadata[MetaTitle] != "Guide" {
+		t.Errorf("title = %v, want Guide", doc.Metadata[MetaTitle])
+	}
+}
+
+func TestHTMLLoader(t *testing.T) {
+	input := `<html><head><title>Pricing</title></head><body><nav>Home</nav><main><p>Plans start at $10.</p></main></body></html>`
+	doc, err := HTMLLoader().Load(context.Background(), strings.NewReader(input), "pricing.html")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if doc.Metadata[MetaTitle] != "Pricing" || doc.Metadata[MetaFormat] != FormatHTML {
+		t.Errorf("metadata = %v", doc.Metadata)
+	}
+	if doc.Content != "Plans start at $10." {
+		t.Errorf("content = %q", doc.Content)
+	}
+}
+
+func TestDocxLoader(t *testing.T) {
+	data := buildDocx(t, []string{"First paragraph.", "Second paragraph."}, "Quarterly Plan", "Ana")
+	doc, err := DocxLoader().Load(context.Background(), bytes.NewReader(data), "plan.docx")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if doc.Content != "First paragraph.\n\nSecond paragraph." {
+		t.Errorf("content = %q", doc.Content)
+	}
+	if doc.Metadata[MetaTitle] != "Quarterly Plan" || doc.Metadata[MetaAuthor] != "Ana" {
+		t.Errorf("metadata = %v", doc.Metadata)
+	}
+}
+
+func TestDocxLoader_NotAnArchive(t *testing.T) {
+	if _, err := DocxLoader().Load(context.Background(), strings.NewReader("plain text"), "x.docx"); err == nil {
+		t.Fatal("expected error for non-zip input")
+	}
+}
+
+func TestPDFLoader(t *testing.T) {
+	data := buildPDF(t, "BT /F1 12 Tf 72 720 Td (Hello \\(PDF\\) world) Tj 0 -14 Td [(Second) -300 (line)] TJ ET", "Test Report")
+	doc, err := PDFLoader().Load(context.Background(), bytes.NewReader(data), "report.pdf")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if doc.Content != "Hello (PDF) world\nSecond line" {
+		t.Errorf("content = %q", doc.Content)
+	}
+	if doc.Metadata[MetaTitle] != "Test Report" {
+		t.Errorf("title = %v", doc.Metadata[MetaTitle])
+	}
+
+	// Outline entries have titles too; only the trailer's /Info counts.
+	outline := bytes.Replace(data, []byte("trailer"), []byte("6 0 obj << /Title (Chapter 1) /Parent 7 0 R >> endobj\ntrailer"), 1)
+	doc, err = PDFLoader().Load(context.Background(), bytes.NewReader(outline), "report.pdf")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if doc.Metadata[MetaTitle] != "Test Report" {
+		t.Errorf("title with an outline = %v", doc.Metadata[MetaTitle])
+	}
+	noInfo := bytes.Replace(data, []byte(" /Info 5 0 R"), nil, 1)
+	doc, err = PDFLoader().Load(context.Background(), bytes.NewReader(noInfo), "report.pdf")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if title, ok := doc.Metadata[MetaTitle]; ok {
+		t.Errorf("title without /Info = %v", title)
+	}
+}
+
+func TestPDFLoader_RejectsNonPDF(t *testing.T) {
+	_, err := PDFLoader().Load(context.Background(), strings.NewReader("hello"), "fake.pdf")
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func buildDocx(t *testing.T, paragraphs []string, title, author string) []byte {
+	t.Helper()
+	var body strings.Builder
+	for _, p := range paragraphs {
+		fmt.Fprintf(&body, "<w:p><w:r><w:t>%s</w:t></w:r></w:p>", p)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"word/document.xml": `<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body.String() + `</w:body></w:document>`,
+		"docProps/core.xml": `<?xml version="1.0" encoding="UTF-8"?><cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>` + title + `</dc:title><dc:creator>` + author + `</dc:creator></cp:coreProperties>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildPDF(t *testing.T, content, title string) []byte {
+	t.Helper()
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	buf.WriteString("1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	buf.WriteString("2 0 obj << /Type /Pages /Kids [3 0 R] /Count 1 >> endobj\n")
+	buf.WriteString("3 0 obj << /Type /Page /Parent 2 0 R /Contents 4 0 R >> endobj\n")
+	fmt.Fprintf(&buf, "4 0 obj << /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len())
+	buf.Write(compressed.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+	fmt.Fprintf(&buf, "5 0 obj << /Title (%s) /Producer (agentkit) >> endobj\n", title)
+	buf.WriteString("trailer << /Root 1 0 R /Info 5 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,98 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// MemoryStore is an in-memory VectorStore using cosine similarity.
+// It is safe for concurrent use and suited to tests and small corpora.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]Record
+	dims    int
+}
+
+// NewMemoryStore creates an empty in-memory vector store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Upsert inserts or replaces records by ID.
+func (s *MemoryStore) Upsert(ctx context.Context, records ...Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		if record.ID == "" {
+			return fmt.Errorf("retrieval: record ID is required")
+		}
+		if s.dims == 0 {
+			s.dims = len(record.Vector)
+		} else if len(record.Vector) != s.dims {
+			return fmt.Errorf("%w: got %d, want %d", ErrDimensionMismatch, len(record.Vector), s.dims)
+		}
+		record.Metadata = cloneMetadata(record.Metadata)
+		s.records[record.ID] = record
+	}
+	return nil
+}
+
+// Query returns the records most similar to vector.
+func (s *MemoryStore) Query(ctx context.Context, vector []float32, opts QueryOptions) ([]Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.dims != 0 && len(vector) != s.dims {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrDimensionMismatch, len(vector), s.dims)
+	}
+
+	matches := make([]Match, 0, len(s.records))
+	for _, record := range s.records {
+		if !matchesFilter(record.Metadata, opts.Filter) {
+			continue
+		}
+		matches = append(matches, Match{Record: record, Score: CosineSimilarity(vector, record.Vector)})
+	}
+
+	sortMatches(matches)
+	return truncateMatches(matches, opts.TopK), nil
+}
+
+// Delete removes records by ID. Unknown IDs are ignored.
+func (s *MemoryStore) Delete(ctx context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.records, id)
+	}
+	return nil
+}
+
+// Len returns the number of stored records.
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.records)
+}
+
+// CosineSimilarity returns the cosine similarity of two vectors, or 0 if either is zero
+// or their lengths differ.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
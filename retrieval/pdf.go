@@ -0,0 +1,315 @@
+package retrieval
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const maxPDFStreamBytes = 32 << 20
+
+var (
+	pdfStream   = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfInfoKeys = regexp.MustCompile(`/(Title|Author)\s*\(`)
+	pdfInfoRef  = regexp.MustCompile(`/Info\s+(\d+)\s+(\d+)\s+R`)
+)
+
+// PDFLoader loads PDF documents with a best-effort text extractor. It reads
+// uncompressed and FlateDecode content streams and collects the strings shown
+// by the Tj, TJ, ' and " operators. Scanned PDFs, custom font encodings and
+// encrypted files yield little or no text; use a dedicated extraction service
+// through a custom Loader for those.
+func PDFLoader() Loader {
+	return LoaderFunc(func(ctx context.Context, r io.Reader, source string) (*Document, error) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: read %s: %w", source, err)
+		}
+		if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) {
+			return nil, fmt.Errorf("%w: %s is not a PDF file", ErrUnsupportedFormat, source)
+		}
+
+		doc := newDocument(source, FormatPDF, pdfText(data))
+		info := pdfInfoDict(data)
+		for _, m := range pdfInfoKeys.FindAllSubmatchIndex(info, -1) {
+			key := string(info[m[2]:m[3]])
+			value, _ := pdfLiteralString(info[m[1]-1:])
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			switch key {
+			case "Title":
+				doc.Metadata[MetaTitle] = value
+			case "Author":
+				doc.Metadata[MetaAuthor] = value
+			}
+		}
+		return doc, nil
+	})
+}
+
+// pdfInfoDict returns the document information dictionary the trailer
+// points to, or nil. Other dictionaries, such as outline entries, have
+// /Title keys too. The last /Info reference wins, as with incremental updates.
+func pdfInfoDict(data []byte) []byte {
+	refs := pdfInfoRef.FindAllSubmatch(data, -1)
+	if len(refs) == 0 {
+		return nil
+	}
+	ref := refs[len(refs)-1]
+	header := regexp.MustCompile(`(?:^|[^0-9])` + string(ref[1]) + `\s+` + string(ref[2]) + `\s+obj\b`)
+	objects := header.FindAllIndex(data, -1)
+	if len(objects) == 0 {
+		return nil
+	}
+	body := data[objects[len(objects)-1][1]:]
+	start := bytes.Index(body, []byte("<<"))
+	if start < 0 || bytes.Contains(body[:start], []byte("endobj")) {
+		return nil
+	}
+	// Find the matching >>, skipping strings that may contain brackets.
+	depth := 0
+	for i := start; i < len(body); {
+		switch {
+		case body[i] == '(':
+			_, n := pdfLiteralString(body[i:])
+			i += n
+		case bytes.HasPrefix(body[i:], []byte("<<")):
+			depth++
+			i += 2
+		case bytes.HasPrefix(body[i:], []byte(">>")):
+			depth--
+			i += 2
+			if depth == 0 {
+				return body[start:i]
+			}
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// pdfText extracts shown text from every content stream in data.
+func pdfText(data []byte) string {
+	var pages []string
+	for _, m := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		dict := data[m[2]:m[3]]
+		start := m[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		raw := data[start : start+end]
+
+		if bytes.Contains(dict, []byte("/Subtype/Image")) || bytes.Contains(dict, []byte("/Subtype /Image")) {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			decoded, err := io.ReadAll(io.LimitReader(zlibReader(raw), maxPDFStreamBytes))
+			if len(decoded) == 0 && err != nil {
+				continue
+			}
+			raw = decoded
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue // Other filters (DCT, LZW, ...) are not text we can read.
+		}
+
+		if text := pdfContentText(raw); text != "" {
+			pages = append(pages, text)
+		}
+	}
+	return strings.Join(pages, "\n\n")
+}
+
+func zlibReader(raw []byte) io.Reader {
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return bytes.NewReader(nil)
+	}
+	return zr
+}
+
+// pdfContentText interprets the text operators of a content stream.
+func pdfContentText(content []byte) string {
+	var (
+		out     strings.Builder
+		pending []string // string operands seen since the last operator
+	)
+	newline := func() {
+		if out.Len() > 0 && !strings.HasSuffix(out.String(), "\n") {
+			out.WriteByte('\n')
+		}
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '(':
+			s, n := pdfLiteralString(content[i:])
+			pending = append(pending, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] != '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				i = len(content)
+				continue
+			}
+			pending = append(pending, pdfHexString(content[i+1:i+end]))
+			i += end + 1
+		case c == '[' || c == ']':
+			i++
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			// Numeric operands; in TJ arrays large negative kerning means a word gap.
+			j := i + 1
+			for j < len(content) && (content[j] == '.' || (content[j] >= '0' && content[j] <= '9')) {
+				j++
+			}
+			if c == '-' && len(pending) > 0 && j-i > 3 {
+				pending = append(pending, " ")
+			}
+			i = j
+		case isPDFDelimiter(c):
+			i++
+		default:
+			j := i
+			for j < len(content) && !isPDFDelimiter(content[j]) && content[j] != '(' && content[j] != '<' && content[j] != '[' {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			op := string(content[i:j])
+			switch op {
+			case "Tj", "TJ":
+				out.WriteString(strings.Join(pending, ""))
+			case "'", "\"":
+				newline()
+				out.WriteString(strings.Join(pending, ""))
+			case "T*", "Td", "TD", "Tm", "ET":
+				newline()
+			}
+			pending = pending[:0]
+			i = j
+		}
+	}
+	return strings.TrimSpace(out.String())
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, ')', '>', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// pdfLiteralString decodes a (...) string starting at data[0] and returns it
+// with the number of bytes consumed.
+func pdfLiteralString(data []byte) (string, int) {
+	if len(data) == 0 || data[0] != '(' {
+		return "", 0
+	}
+	var out []byte
+	depth := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '(':
+			if depth > 0 {
+				out = append(out, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return decodePDFText(out), i + 1
+			}
+			out = append(out, c)
+		case '\\':
+			i++
+			if i >= len(data) {
+				break
+			}
+			switch e := data[i]; e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation.
+			default:
+				if e >= '0' && e <= '7' {
+					v, n := 0, 0
+					for n < 3 && i+n < len(data) && data[i+n] >= '0' && data[i+n] <= '7' {
+						v = v*8 + int(data[i+n]-'0')
+						n++
+					}
+					out = append(out, byte(v))
+					i += n - 1
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return decodePDFText(out), len(data)
+}
+
+func pdfHexString(hex []byte) string {
+	var digits []byte
+	for _, c := range hex {
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		out[i] = hexValue(digits[2*i])<<4 | hexValue(digits[2*i+1])
+	}
+	return decodePDFText(out)
+}
+
+// decodePDFText handles UTF-16BE strings (with BOM) and treats everything else as Latin-1.
+func decodePDFText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xFE && b[1] == 0xFF {
+		var sb strings.Builder
+		for i := 2; i+1 < len(b); i += 2 {
+			sb.WriteRune(rune(b[i])<<8 | rune(b[i+1]))
+		}
+		return sb.String()
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,200 @@
+package retrieval
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultVectorTable is the table used by PgVectorStore when none is set.
+const DefaultVectorTable = "agentkit_vectors"
+
+// PgVectorStore is a VectorStore backed by PostgreSQL with the pgvector
+// extension. Vectors are compared by cosine distance through an HNSW index;
+// Score is the cosine similarity, as with MemoryStore. Filters match
+// metadata with JSONB containment.
+//
+// The caller supplies a *sql.DB opened with any Postgres driver (pgx, lib/pq).
+type PgVectorStore struct {
+	DB         *sql.DB
+	Table      string // Defaults to DefaultVectorTable
+	Dimensions int    // Vector size, required by Migrate
+}
+
+// NewPgVectorStore creates a vector store in table for vectors of dims dimensions.
+func NewPgVectorStore(db *sql.DB, table string, dims int) *PgVectorStore {
+	return &PgVectorStore{DB: db, Table: table, Dimensions: dims}
+}
+
+// Migrate enables the vector extension and creates the table and its HNSW
+// index if they do not exist.
+func (p *PgVectorStore) Migrate(ctx context.Context) error {
+	table, err := p.table()
+	if err != nil {
+		return err
+	}
+	if p.Dimensions <= 0 {
+		return fmt.Errorf("retrieval: migrate vector store: Dimensions must be set")
+	}
+	indexName := strings.ReplaceAll(table, ".", "_") + "_embedding_idx"
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	content TEXT NOT NULL,
+	metadata JSONB NOT NULL DEFAULT '{}',
+	embedding vector(%d) NOT NULL
+)`, table, p.Dimensions),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s USING hnsw (embedding vector_cosine_ops)`, indexName, table),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s_metadata_idx ON %s USING GIN (metadata jsonb_path_ops)`, strings.ReplaceAll(table, ".", "_"), table),
+	}
+	for _, stmt := range statements {
+		if _, err := p.DB.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("retrieval: migrate vector store: %w", err)
+		}
+	}
+	return nil
+}
+
+// Upsert inserts or replaces records by ID.
+func (p *PgVectorStore) Upsert(ctx context.Context, records ...Record) error {
+	table, err := p.table()
+	if err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf(`INSERT INTO %s (id, content, metadata, embedding) VALUES ($1, $2, $3::jsonb, $4::vector)
+ON CONFLICT (id) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding`, table)
+
+	for _, record := range records {
+		if record.ID == "" {
+			return fmt.Errorf("retrieval: record ID is required")
+		}
+		if p.Dimensions > 0 && len(record.Vector) != p.Dimensions {
+			return fmt.Errorf("%w: got %d, want %d", ErrDimensionMismatch, len(record.Vector), p.Dimensions)
+		}
+		metadata, err := json.Marshal(cloneMetadata(record.Metadata))
+		if err != nil {
+			return fmt.Errorf("retrieval: encode metadata for %s: %w", record.ID, err)
+		}
+		if _, err := p.DB.ExecContext(ctx, stmt, record.ID, record.Content, string(metadata), vectorLiteral(record.Vector)); err != nil {
+			return fmt.Errorf("retrieval: upsert %s: %w", record.ID, err)
+		}
+	}
+	return nil
+}
+
+// Query returns the records nearest to vector by cosine distance.
+func (p *PgVectorStore) Query(ctx context.Context, vector []float32, opts QueryOptions) ([]Match, error) {
+	table, err := p.table()
+	if err != nil {
+		return nil, err
+	}
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = defaultTopK
+	}
+
+	args := []any{vectorLiteral(vector)}
+	stmt := pgVectorQuery(table, len(opts.Filter) > 0)
+	if len(opts.Filter) > 0 {
+		filter, err := json.Marshal(opts.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: encode filter: %w", err)
+		}
+		args = append(args, string(filter))
+	}
+	args = append(args, topK)
+
+	rows, err := p.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: vector query: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []Match
+	for rows.Next() {
+		var (
+			match    Match
+			metadata []byte
+		)
+		if err := rows.Scan(&match.ID, &match.Content, &metadata, &match.Score); err != nil {
+			return nil, fmt.Errorf("retrieval: vector query: %w", err)
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &match.Metadata); err != nil {
+				return nil, fmt.Errorf("retrieval: decode metadata for %s: %w", match.ID, err)
+			}
+		}
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("retrieval: vector query: %w", err)
+	}
+	return matches, nil
+}
+
+// Delete removes records by ID.
+func (p *PgVectorStore) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	table, err := p.table()
+	if err != nil {
+		return err
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	stmt := fmt.Sprintf(`DELETE FROM %s WHERE id IN (%s)`, table, strings.Join(placeholders, ", "))
+	if _, err := p.DB.ExecContext(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("retrieval: delete from vector store: %w", err)
+	}
+	return nil
+}
+
+func (p *PgVectorStore) table() (string, error) {
+	table := p.Table
+	if table == "" {
+		table = DefaultVectorTable
+	}
+	if !sqlIdentifier.MatchString(table) {
+		return "", fmt.Errorf("%w: table %q", ErrInvalidIdentifier, table)
+	}
+	return table, nil
+}
+
+// pgVectorQuery builds the nearest-neighbour query. Parameters are $1 the
+// query vector, then the JSON filter when withFilter is set, then the limit.
+func pgVectorQuery(table string, withFilter bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `SELECT id, content, metadata, 1 - (embedding <=> $1::vector) AS score
+FROM %s`, table)
+	limit := "$2"
+	if withFilter {
+		sb.WriteString(`
+WHERE metadata @> $2::jsonb`)
+		limit = "$3"
+	}
+	fmt.Fprintf(&sb, "\nORDER BY embedding <=> $1::vector, id\nLIMIT %s", limit)
+	return sb.String()
+}
+
+// vectorLiteral formats v in pgvector's text form, e.g. "[0.1,0.2]".
+func vectorLiteral(v []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
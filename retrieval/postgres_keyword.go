@@ -0,0 +1,185 @@
+package retrieval
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultKeywordTable is the table used by PostgresKeywordIndex when none is set.
+const DefaultKeywordTable = "agentkit_keyword_index"
+
+// ErrInvalidIdentifier is returned when a table or text search configuration name is unsafe to use in SQL.
+var ErrInvalidIdentifier = errors.New("retrieval: invalid SQL identifier")
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// PostgresKeywordIndex is a KeywordIndex backed by PostgreSQL full-text search.
+// Content is indexed in a generated tsvector column with a GIN index and
+// ranked with ts_rank_cd; queries use websearch_to_tsquery syntax.
+//
+// The caller supplies a *sql.DB opened with any Postgres driver (pgx, lib/pq).
+type PostgresKeywordIndex struct {
+	DB       *sql.DB
+	Table    string // Defaults to DefaultKeywordTable
+	Language string // Text search configuration; defaults to "english"
+}
+
+// NewPostgresKeywordIndex creates a keyword index stored in table.
+func NewPostgresKeywordIndex(db *sql.DB, table string) *PostgresKeywordIndex {
+	return &PostgresKeywordIndex{DB: db, Table: table, Language: "english"}
+}
+
+// Migrate creates the index table and its GIN index if they do not exist.
+func (p *PostgresKeywordIndex) Migrate(ctx context.Context) error {
+	table, language, err := p.identifiers()
+	if err != nil {
+		return err
+	}
+	indexName := strings.ReplaceAll(table, ".", "_") + "_tsv_idx"
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id TEXT PRIMARY KEY,
+	content TEXT NOT NULL,
+	metadata JSONB NOT NULL DEFAULT '{}',
+	tsv TSVECTOR GENERATED ALWAYS AS (to_tsvector('%s'::regconfig, content)) STORED
+)`, table, language),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s USING GIN (tsv)`, indexName, table),
+	}
+	for _, stmt := range statements {
+		if _, err := p.DB.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("retrieval: migrate keyword index: %w", err)
+		}
+	}
+	return nil
+}
+
+// Index inserts or replaces records by ID.
+func (p *PostgresKeywordIndex) Index(ctx context.Context, records ...Record) error {
+	table, _, err := p.identifiers()
+	if err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf(`INSERT INTO %s (id, content, metadata) VALUES ($1, $2, $3)
+ON CONFLICT (id) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata`, table)
+
+	for _, record := range records {
+		metadata, err := json.Marshal(cloneMetadata(record.Metadata))
+		if err != nil {
+			return fmt.Errorf("retrieval: encode metadata for %s: %w", record.ID, err)
+		}
+		if _, err := p.DB.ExecContext(ctx, stmt, record.ID, record.Content, string(metadata)); err != nil {
+			return fmt.Errorf("retrieval: index %s: %w", record.ID, err)
+		}
+	}
+	return nil
+}
+
+// Search runs a full-text query and returns matches ranked by ts_rank_cd.
+func (p *PostgresKeywordIndex) Search(ctx context.Context, query string, opts QueryOptions) ([]Match, error) {
+	table, language, err := p.identifiers()
+	if err != nil {
+		return nil, err
+	}
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = defaultTopK
+	}
+
+	args := []any{language, query}
+	stmt := postgresSearchQuery(table, len(opts.Filter) > 0)
+	if len(opts.Filter) > 0 {
+		filter, err := json.Marshal(opts.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("retrieval: encode filter: %w", err)
+		}
+		args = append(args, string(filter))
+	}
+	args = append(args, topK)
+
+	rows, err := p.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: keyword search: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []Match
+	for rows.Next() {
+		var (
+			match    Match
+			metadata []byte
+		)
+		if err := rows.Scan(&match.ID, &match.Content, &metadata, &match.Score); err != nil {
+			return nil, fmt.Errorf("retrieval: keyword search: %w", err)
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &match.Metadata); err != nil {
+				return nil, fmt.Errorf("retrieval: decode metadata for %s: %w", match.ID, err)
+			}
+		}
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("retrieval: keyword search: %w", err)
+	}
+	return matches, nil
+}
+
+// Delete removes records by ID.
+func (p *PostgresKeywordIndex) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	table, _, err := p.identifiers()
+	if err != nil {
+		return err
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	stmt := fmt.Sprintf(`DELETE FROM %s WHERE id IN (%s)`, table, strings.Join(placeholders, ", "))
+	if _, err := p.DB.ExecContext(ctx, stmt, args...); err != nil {
+		return fmt.Errorf("retrieval: delete from keyword index: %w", err)
+	}
+	return nil
+}
+
+func (p *PostgresKeywordIndex) identifiers() (table, language string, err error) {
+	table, language = p.Table, p.Language
+	if table == "" {
+		table = DefaultKeywordTable
+	}
+	if language == "" {
+		language = "english"
+	}
+	if !sqlIdentifier.MatchString(table) {
+		return "", "", fmt.Errorf("%w: table %q", ErrInvalidIdentifier, table)
+	}
+	if !sqlIdentifier.MatchString(language) {
+		return "", "", fmt.Errorf("%w: language %q", ErrInvalidIdentifier, language)
+	}
+	return table, language, nil
+}
+
+// postgresSearchQuery builds the ranked full-text query. Parameters are
+// $1 language, $2 query, then the JSON filter when withFilter is set, then the limit.
+func postgresSearchQuery(table string, withFilter bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `SELECT id, content, metadata, ts_rank_cd(tsv, q) AS score
+FROM %s, websearch_to_tsquery($1::regconfig, $2) AS q
+WHERE tsv @@ q`, table)
+	limit := "$3"
+	if withFilter {
+		sb.WriteString(` AND metadata @> $3::jsonb`)
+		limit = "$4"
+	}
+	fmt.Fprintf(&sb, "\nORDER BY score DESC, id\nLIMIT %s", limit)
+	return sb.String()
+}
@@ -0,0 +1,185 @@
+package retrieval
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Payload keys under which QdrantStore keeps a record's ID and content.
+const (
+	qdrantIDKey      = "_agentkit_id"
+	qdrantContentKey = "_agentkit_content"
+)
+
+// QdrantStore is a VectorStore backed by a Qdrant collection, through its
+// REST API. Qdrant point IDs must be UUIDs or integers, so each record ID is
+// mapped to a stable UUID and kept in the payload next to the content and
+// metadata. Filters match payload values exactly.
+type QdrantStore struct {
+	BaseURL    string // Defaults to http://localhost:6333
+	Collection string
+	APIKey     string // Sent as the api-key header when set (Qdrant Cloud)
+	Client     *http.Client
+}
+
+// NewQdrantStore creates a vector store on collection of the Qdrant server at baseURL.
+func NewQdrantStore(baseURL, collection string) *QdrantStore {
+	return &QdrantStore{BaseURL: baseURL, Collection: collection}
+}
+
+// EnsureCollection creates the collection with cosine distance for vectors
+// of dims dimensions, if it does not exist.
+func (q *QdrantStore) EnsureCollection(ctx context.Context, dims int) error {
+	status, err := q.do(ctx, http.MethodGet, "", nil, nil)
+	if err == nil {
+		return nil
+	}
+	if status != http.StatusNotFound {
+		return err
+	}
+	_, err = q.do(ctx, http.MethodPut, "", map[string]any{
+		"vectors": map[string]any{"size": dims, "distance": "Cosine"},
+	}, nil)
+	return err
+}
+
+// Upsert inserts or replaces records by ID.
+func (q *QdrantStore) Upsert(ctx context.Context, records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	points := make([]map[string]any, len(records))
+	for i, record := range records {
+		if record.ID == "" {
+			return fmt.Errorf("retrieval: record ID is required")
+		}
+		payload := cloneMetadata(record.Metadata)
+		payload[qdrantIDKey] = record.ID
+		payload[qdrantContentKey] = record.Content
+		points[i] = map[string]any{"id": qdrantPointID(record.ID), "vector": record.Vector, "payload": payload}
+	}
+	_, err := q.do(ctx, http.MethodPut, "/points?wait=true", map[string]any{"points": points}, nil)
+	return err
+}
+
+// Query returns the records nearest to vector.
+func (q *QdrantStore) Query(ctx context.Context, vector []float32, opts QueryOptions) ([]Match, error) {
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = defaultTopK
+	}
+	body := map[string]any{"vector": vector, "limit": topK, "with_payload": true}
+	if len(opts.Filter) > 0 {
+		must := make([]map[string]any, 0, len(opts.Filter))
+		for key, value := range opts.Filter {
+			must = append(must, map[string]any{"key": key, "match": map[string]any{"value": value}})
+		}
+		body["filter"] = map[string]any{"must": must}
+	}
+
+	var resp struct {
+		Result []struct {
+			Score   float64        `json:"score"`
+			Payload map[string]any `json:"payload"`
+		} `json:"result"`
+	}
+	if _, err := q.do(ctx, http.MethodPost, "/points/search", body, &resp); err != nil {
+		return nil, err
+	}
+	matches := make([]Match, 0, len(resp.Result))
+	for _, point := range resp.Result {
+		match := Match{Score: point.Score}
+		match.ID, _ = point.Payload[qdrantIDKey].(string)
+		match.Content, _ = point.Payload[qdrantContentKey].(string)
+		delete(point.Payload, qdrantIDKey)
+		delete(point.Payload, qdrantContentKey)
+		match.Metadata = Metadata(point.Payload)
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// Delete removes records by ID.
+func (q *QdrantStore) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	points := make([]string, len(ids))
+	for i, id := range ids {
+		points[i] = qdrantPointID(id)
+	}
+	_, err := q.do(ctx, http.MethodPost, "/points/delete?wait=true", map[string]any{"points": points}, nil)
+	return err
+}
+
+// do sends a request to path under the collection and decodes the response
+// into out. It returns the HTTP status alongside any error.
+func (q *QdrantStore) do(ctx context.Context, method, path string, payload, out any) (int, error) {
+	if q.Collection == "" {
+		return 0, fmt.Errorf("retrieval: qdrant: collection is required")
+	}
+	baseURL := q.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost:6333"
+	}
+	endpoint := strings.TrimRight(baseURL, "/") + "/collections/" + url.PathEscape(q.Collection) + path
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return 0, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return 0, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if q.APIKey != "" {
+		req.Header.Set("api-key", q.APIKey)
+	}
+
+	client := q.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("retrieval: qdrant: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("retrieval: qdrant: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("retrieval: qdrant: unexpected status %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("retrieval: qdrant: decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// qdrantPointID maps a record ID to a stable UUID (name-based, SHA-1).
+func qdrantPointID(id string) string {
+	sum := sha1.Sum([]byte("agentkit:" + id))
+	sum[6] = sum[6]&0x0f | 0x50 // Version 5
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,273 @@
+package retrieval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrMissingRerankKey is returned when an API reranker has no API key.
+var ErrMissingRerankKey = errors.New("retrieval: reranker API key is required")
+
+// Reranker re-scores retrieved matches against the query. Implementations
+// return the matches ordered by descending relevance with Score replaced by
+// the reranker's relevance score.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, matches []Match) ([]Match, error)
+}
+
+// RerankerFunc adapts a function to the Reranker interface.
+type RerankerFunc func(ctx context.Context, query string, matches []Match) ([]Match, error)
+
+// Rerank calls f.
+func (f RerankerFunc) Rerank(ctx context.Context, query string, matches []Match) ([]Match, error) {
+	return f(ctx, query, matches)
+}
+
+// RankedID is a record's position in a ranking.
+type RankedID struct {
+	ID    string  `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// RerankTrace describes one reranking pass, for tracing and debugging.
+type RerankTrace struct {
+	Query     string        `json:"query"`
+	Before    []RankedID    `json:"before"`    // Candidate order from search
+	After     []RankedID    `json:"after"`     // Order after reranking and thresholding
+	Threshold float64       `json:"threshold"` // Minimum score kept
+	Dropped   int           `json:"dropped"`   // Matches removed by the threshold
+	Duration  time.Duration `json:"duration"`
+}
+
+func rankedIDs(matches []Match) []RankedID {
+	ids := make([]RankedID, len(matches))
+	for i, m := range matches {
+		ids[i] = RankedID{ID: m.ID, Score: m.Score}
+	}
+	return ids
+}
+
+// applyScores reorders matches using per-index scores from an API response.
+func applyScores(matches []Match, scores map[int]float64) []Match {
+	reranked := make([]Match, 0, len(scores))
+	for index, score := range scores {
+		if index < 0 || index >= len(matches) {
+			continue
+		}
+		m := matches[index]
+		m.Score = score
+		reranked = append(reranked, m)
+	}
+	sortMatches(reranked)
+	return reranked
+}
+
+func documents(matches []Match) []string {
+	docs := make([]string, len(matches))
+	for i, m := range matches {
+		docs[i] = m.Content
+	}
+	return docs
+}
+
+// CohereReranker reranks with the Cohere Rerank API.
+type CohereReranker struct {
+	APIKey  string
+	Model   string // Defaults to "rerank-v3.5"
+	BaseURL string // Defaults to https://api.cohere.com
+	Client  *http.Client
+}
+
+// NewCohereReranker creates a Cohere reranker.
+func NewCohereReranker(apiKey string) *CohereReranker {
+	return &CohereReranker{APIKey: apiKey, Model: "rerank-v3.5", BaseURL: "https://api.cohere.com"}
+}
+
+// Rerank implements Reranker.
+func (r *CohereReranker) Rerank(ctx context.Context, query string, matches []Match) ([]Match, error) {
+	if r.APIKey == "" {
+		return nil, ErrMissingRerankKey
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	var resp struct {
+		Results []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"results"`
+	}
+	err := postRerank(ctx, r.Client, "cohere", r.BaseURL+"/v2/rerank", r.APIKey, map[string]any{
+		"model":     r.Model,
+		"query":     query,
+		"documents": documents(matches),
+		"top_n":     len(matches),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int]float64, len(resp.Results))
+	for _, result := range resp.Results {
+		scores[result.Index] = result.RelevanceScore
+	}
+	return applyScores(matches, scores), nil
+}
+
+// VoyageReranker reranks with the Voyage AI rerank API.
+type VoyageReranker struct {
+	APIKey  string
+	Model   string // Defaults to "rerank-2"
+	BaseURL string // Defaults to https://api.voyageai.com
+	Client  *http.Client
+}
+
+// NewVoyageReranker creates a Voyage AI reranker.
+func NewVoyageReranker(apiKey string) *VoyageReranker {
+	return &VoyageReranker{APIKey: apiKey, Model: "rerank-2", BaseURL: "https://api.voyageai.com"}
+}
+
+// Rerank implements Reranker.
+func (r *VoyageReranker) Rerank(ctx context.Context, query string, matches []Match) ([]Match, error) {
+	if r.APIKey == "" {
+		return nil, ErrMissingRerankKey
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	var resp struct {
+		Data []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"data"`
+	}
+	err := postRerank(ctx, r.Client, "voyage", r.BaseURL+"/v1/rerank", r.APIKey, map[string]any{
+		"model":     r.Model,
+		"query":     query,
+		"documents": documents(matches),
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[int]float64, len(resp.Data))
+	for _, result := range resp.Data {
+		scores[result.Index] = result.RelevanceScore
+	}
+	return applyScores(matches, scores), nil
+}
+
+func postRerank(ctx context.Context, client *http.Client, provider, endpoint, apiKey string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("retrieval: %s rerank: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return fmt.Errorf("retrieval: %s rerank: read response: %w", provider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("retrieval: %s rerank: unexpected status %s: %s", provider, resp.Status, bytes.TrimSpace(data))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("retrieval: %s rerank: decode response: %w", provider, err)
+	}
+	return nil
+}
+
+// LLMReranker asks a language model to grade each match's relevance from 0 to
+// 10 and reorders by the grade, normalized to 0..1. It is slower and costlier
+// than a dedicated rerank model but needs no extra provider.
+type LLMReranker struct {
+	Provider providers.Provider
+	Model    string
+	// MaxChars truncates each passage in the prompt; defaults to 1000.
+	MaxChars int
+}
+
+// Rerank implements Reranker.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, matches []Match) ([]Match, error) {
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	maxChars := r.MaxChars
+	if maxChars <= 0 {
+		maxChars = 1000
+	}
+
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Query: %s\n\nPassages:\n", query)
+	for i, m := range matches {
+		content := []rune(m.Content)
+		if len(content) > maxChars {
+			content = content[:maxChars]
+		}
+		fmt.Fprintf(&prompt, "[%d] %s\n", i, strings.ReplaceAll(string(content), "\n", " "))
+	}
+
+	resp, err := r.Provider.Complete(ctx, providers.CompletionRequest{
+		Model: r.Model,
+		SystemPrompt: "You grade search results. For every passage, rate how well it answers the query from 0 (irrelevant) to 10 (fully answers it). " +
+			`Respond with JSON only: {"scores": [{"index": 0, "score": 7}, ...]} covering every passage.`,
+		Messages:   []providers.Message{{Role: providers.RoleUser, Content: prompt.String()}},
+		TextFormat: "json_object",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieval: llm rerank: %w", err)
+	}
+
+	var graded struct {
+		Scores []struct {
+			Index int     `json:"index"`
+			Score float64 `json:"score"`
+		} `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(extractJSONObject(resp.Content)), &graded); err != nil {
+		return nil, fmt.Errorf("retrieval: llm rerank: decode grades: %w", err)
+	}
+
+	// Passages the model skipped keep their place at the bottom with a zero score.
+	scores := make(map[int]float64, len(matches))
+	for i := range matches {
+		scores[i] = 0
+	}
+	for _, s := range graded.Scores {
+		if _, ok := scores[s.Index]; ok {
+			scores[s.Index] = min(max(s.Score, 0), 10) / 10
+		}
+	}
+	return applyScores(matches, scores), nil
+}
+
+// extractJSONObject trims any prose or code fences around a JSON object.
+func extractJSONObject(text string) string {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return text
+	}
+	return text[start : end+1]
+}
@@ -0,0 +1,135 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func rerankCandidates() []Match {
+	return []Match{
+		{Record: Record{ID: "a", Content: "Office hours are 9 to 5."}, Score: 0.9},
+		{Record: Record{ID: "b", Content: "Refunds are issued within 30 days."}, Score: 0.8},
+		{Record: Record{ID: "c", Content: "Our refund policy covers all plans."}, Score: 0.7},
+	}
+}
+
+func TestCohereReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/rerank" || r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("unexpected request %s auth=%q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var body struct {
+			Query     string   `json:"query"`
+			Documents []string `json:"documents"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Query != "refunds" || len(body.Documents) != 3 {
+			t.Errorf("body = %+v", body)
+		}
+		_, _ = w.Write([]byte(`{"results": [{"index": 2, "relevance_score": 0.95}, {"index": 1, "relevance_score": 0.6}, {"index": 0, "relevance_score": 0.01}]}`))
+	}))
+	defer server.Close()
+
+	reranker := NewCohereReranker("key")
+	reranker.BaseURL = server.URL
+	matches, err := reranker.Rerank(context.Background(), "refunds", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if got := ids(matches); got != "c,b,a" || matches[0].Score != 0.95 {
+		t.Errorf("order = %s, top score = %v", got, matches[0].Score)
+	}
+
+	if _, err := NewCohereReranker("").Rerank(context.Background(), "q", rerankCandidates()); !errors.Is(err, ErrMissingRerankKey) {
+		t.Errorf("error = %v, want ErrMissingRerankKey", err)
+	}
+}
+
+func TestVoyageReranker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/rerank" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data": [{"index": 1, "relevance_score": 0.9}, {"index": 0, "relevance_score": 0.2}, {"index": 2, "relevance_score": 0.5}]}`))
+	}))
+	defer server.Close()
+
+	reranker := NewVoyageReranker("key")
+	reranker.BaseURL = server.URL
+	matches, err := reranker.Rerank(context.Background(), "refunds", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if got := ids(matches); got != "b,c,a" {
+		t.Errorf("order = %s, want b,c,a", got)
+	}
+}
+
+func TestLLMReranker(t *testing.T) {
+	provider := mock.New().WithResponse("```json\n{\"scores\": [{\"index\": 0, \"score\": 1}, {\"index\": 1, \"score\": 9}, {\"index\": 2, \"score\": 12}]}\n```", nil)
+	reranker := &LLMReranker{Provider: provider, Model: "mock-model"}
+
+	matches, err := reranker.Rerank(context.Background(), "refunds", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+	if got := ids(matches); got != "c,b,a" {
+		t.Errorf("order = %s, want c,b,a", got)
+	}
+	if matches[0].Score != 1 || matches[1].Score != 0.9 {
+		t.Errorf("scores = %v, %v; want clamped and normalized", matches[0].Score, matches[1].Score)
+	}
+}
+
+func TestRetriever_RerankThresholdAndTrace(t *testing.T) {
+	ctx := context.Background()
+	keyword := NewBM25Index()
+	for _, m := range rerankCandidates() {
+		_ = keyword.Index(ctx, m.Record)
+	}
+
+	var trace RerankTrace
+	retriever := &Retriever{
+		Keyword: keyword,
+		Reranker: RerankerFunc(func(_ context.Context, _ string, matches []Match) ([]Match, error) {
+			scores := map[string]float64{"a": 0.1, "b": 0.7, "c": 0.9}
+			out := make([]Match, len(matches))
+			for i, m := range matches {
+				m.Score = scores[m.ID]
+				out[i] = m
+			}
+			sortMatches(out)
+			return out, nil
+		}),
+		MinScore: 0.5,
+		OnRerank: func(_ context.Context, t RerankTrace) { trace = t },
+	}
+
+	matches, err := retriever.Retrieve(ctx, "refund refunds are office", QueryOptions{TopK: 3})
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if got := ids(matches); got != "c,b" {
+		t.Errorf("order = %s, want c,b", got)
+	}
+	if len(trace.Before) != 3 || len(trace.After) != 2 || trace.Dropped != 1 || trace.Threshold != 0.5 {
+		t.Errorf("trace = %+v", trace)
+	}
+}
+
+func ids(matches []Match) string {
+	var out string
+	for i, m := range matches {
+		if i > 0 {
+			out += ","
+		}
+		out += m.ID
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+// Package retrieval provides the building blocks for retrieval-augmented
+// generation: document loaders, chunkers, vector stores and ingestion.
+//
+//	store := retrieval.NewMemoryStore()
+//	_, err := retrieval.Ingest(ctx, "docs/", store, retrieval.WithEmbedder(embedder))
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+)
+
+// defaultTopK is used when QueryOptions.TopK is not set.
+const defaultTopK = 5
+
+// Common retrieval errors.
+var (
+	ErrNoEmbedder        = errors.New("retrieval: embedder is required")
+	ErrUnsupportedFormat = errors.New("retrieval: unsupported document format")
+	ErrDimensionMismatch = errors.New("retrieval: vector dimension mismatch")
+)
+
+// Metadata holds arbitrary key/value attributes of documents and chunks.
+type Metadata map[string]any
+
+// Well-known metadata keys set by loaders and Ingest.
+const (
+	MetaSource     = "source"
+	MetaFormat     = "format"
+	MetaTitle      = "title"
+	MetaAuthor     = "author"
+	MetaDocumentID = "document_id"
+	MetaChunkIndex = "chunk_index"
+	MetaWordCount  = "word_count"
+)
+
+// Document is a loaded source document before chunking.
+type Document struct {
+	ID       string
+	Source   string
+	Content  string
+	Metadata Metadata
+}
+
+// Record is a chunk stored in a vector store.
+type Record struct {
+	ID       string
+	Content  string
+	Vector   []float32
+	Metadata Metadata
+}
+
+// Match is a record returned from a query together with its similarity score.
+type Match struct {
+	Record
+	Score float64
+}
+
+// QueryOptions controls a vector query.
+type QueryOptions struct {
+	TopK int
+	// Filter restricts matches to records whose metadata contains every key/value pair.
+	Filter Metadata
+}
+
+// VectorStore persists embedded records and answers similarity queries.
+type VectorStore interface {
+	Upsert(ctx context.Context, records ...Record) error
+	Query(ctx context.Context, vector []float32, opts QueryOptions) ([]Match, error)
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// matchesFilter reports whether metadata contains every entry of filter.
+func matchesFilter(metadata, filter Metadata) bool {
+	for key, want := range filter {
+		if got, ok := metadata[key]; !ok || !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneMetadata(metadata Metadata) Metadata {
+	cloned := make(Metadata, len(metadata))
+	for k, v := range metadata {
+		cloned[k] = v
+	}
+	return cloned
+}
+
+// sortMatches orders matches by descending score, breaking ties by ID.
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score == matches[j].Score {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].Score > matches[j].Score
+	})
+}
+
+func truncateMatches(matches []Match, topK int) []Match {
+	if topK <= 0 {
+		topK = defaultTopK
+	}
+	if len(matches) > topK {
+		return matches[:topK]
+	}
+	return matches
+}
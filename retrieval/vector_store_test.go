@@ -0,0 +1,111 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPgVectorStore_Queries(t *testing.T) {
+	store := NewPgVectorStore(nil, "docs; DROP TABLE users", 3)
+	if _, err := store.Query(context.Background(), []float32{1, 0, 0}, QueryOptions{}); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("error = %v, want ErrInvalidIdentifier", err)
+	}
+	if err := NewPgVectorStore(nil, "", 3).Upsert(context.Background(), Record{ID: "a", Vector: []float32{1}}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("error = %v, want ErrDimensionMismatch", err)
+	}
+
+	query := pgVectorQuery("rag.chunks", true)
+	for _, want := range []string{"FROM rag.chunks", "1 - (embedding <=> $1::vector) AS score", "metadata @> $2::jsonb", "LIMIT $3"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+	if got := vectorLiteral([]float32{0.5, -1, 0.25}); got != "[0.5,-1,0.25]" {
+		t.Errorf("vectorLiteral() = %s", got)
+	}
+}
+
+// fakeQdrant keeps points in memory and answers the REST calls QdrantStore
+// makes, scoring by dot product.
+func fakeQdrant(t *testing.T) *httptest.Server {
+	t.Helper()
+	points := map[string]map[string]any{}
+	collection := false
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/collections/docs":
+			if !collection {
+				http.NotFound(w, r)
+				return
+			}
+		case r.Method == http.MethodPut && r.URL.Path == "/collections/docs":
+			collection = true
+		case r.URL.Path == "/collections/docs/points" && r.Method == http.MethodPut:
+			for _, p := range body["points"].([]any) {
+				point := p.(map[string]any)
+				points[point["id"].(string)] = point
+			}
+		case r.URL.Path == "/collections/docs/points/delete":
+			for _, id := range body["points"].([]any) {
+				delete(points, id.(string))
+			}
+		case r.URL.Path == "/collections/docs/points/search":
+			query := body["vector"].([]any)
+			var results []map[string]any
+			for _, point := range points {
+				var score float64
+				for i, x := range point["vector"].([]any) {
+					score += x.(float64) * query[i].(float64)
+				}
+				results = append(results, map[string]any{"id": point["id"], "score": score, "payload": point["payload"]})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": results})
+			return
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": true})
+	}))
+}
+
+func TestQdrantStore_RoundTrip(t *testing.T) {
+	server := fakeQdrant(t)
+	defer server.Close()
+	ctx := context.Background()
+	store := NewQdrantStore(server.URL, "docs")
+
+	if err := store.EnsureCollection(ctx, 2); err != nil {
+		t.Fatalf("EnsureCollection() error = %v", err)
+	}
+	err := store.Upsert(ctx,
+		Record{ID: "guide.md#0", Content: "Reset your password", Vector: []float32{1, 0}, Metadata: Metadata{"source": "guide.md"}},
+		Record{ID: "guide.md#1", Content: "Update billing", Vector: []float32{0, 1}},
+	)
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	if err := store.Delete(ctx, "guide.md#1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	matches, err := store.Query(ctx, []float32{1, 0}, QueryOptions{TopK: 2})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != "guide.md#0" || matches[0].Content != "Reset your password" || matches[0].Metadata["source"] != "guide.md" {
+		t.Errorf("matches = %+v", matches)
+	}
+	if _, ok := matches[0].Metadata[qdrantIDKey]; ok {
+		t.Errorf("metadata leaks internal payload keys: %+v", matches[0].Metadata)
+	}
+	if qdrantPointID("a") != qdrantPointID("a") || qdrantPointID("a") == qdrantPointID("b") || len(qdrantPointID("a")) != 36 {
+		t.Errorf("qdrantPointID() = %s, want a stable UUID per ID", qdrantPointID("a"))
+	}
+}
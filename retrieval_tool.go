@@ -0,0 +1,245 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	"github.com/darkostanimirovic/agentkit/retrieval"
+)
+
+const (
+	defaultRetrievalToolName = "search_knowledge_base"
+	defaultRetrievalTopK     = 5
+	maxRetrievalTopK         = 50
+)
+
+type retrievalOptions struct {
+	name        string                 // Tool name
+	description string                 // Tool description shown to the model
+	topK        int                    // Results returned when the model does not ask for a number
+	filter      retrieval.Metadata     // Metadata filter applied to every query
+	keyword     retrieval.KeywordIndex // Keyword index for keyword or hybrid search
+	mode        retrieval.SearchMode   // Explicit search mode; inferred from backends when empty
+	rrfK        int                    // Reciprocal rank fusion constant for hybrid search
+	reranker    retrieval.Reranker     // Re-scores candidates before they are returned
+	minScore    float64                // Reranked matches scoring below this are dropped
+	compressor  retrieval.Compressor   // Shortens each passage before it is returned
+}
+
+// RetrievalOption configures a retrieval tool.
+type RetrievalOption func(*retrievalOptions)
+
+// WithRetrievalName sets the tool name. Defaults to "search_knowledge_base".
+func WithRetrievalName(name string) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.name = name
+	}
+}
+
+// WithRetrievalDescription sets the tool description shown to the model.
+func WithRetrievalDescription(description string) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.description = description
+	}
+}
+
+// WithRetrievalTopK sets how many results are returned by default.
+func WithRetrievalTopK(k int) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.topK = k
+	}
+}
+
+// WithRetrievalFilter restricts every query to records whose metadata matches filter.
+func WithRetrievalFilter(filter retrieval.Metadata) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.filter = filter
+	}
+}
+
+// WithKeywordSearch adds a keyword index (BM25, Postgres full-text) to the tool.
+// With a vector store also configured, search becomes hybrid: keyword and
+// vector results are merged with reciprocal rank fusion, so exact identifiers
+// that embeddings miss are still found.
+func WithKeywordSearch(index retrieval.KeywordIndex) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.keyword = index
+	}
+}
+
+// WithSearchMode forces vector, keyword or hybrid search.
+func WithSearchMode(mode retrieval.SearchMode) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.mode = mode
+	}
+}
+
+// WithFusionK sets the reciprocal rank fusion constant used in hybrid search.
+// Smaller values favour items ranked at the very top of either list.
+func WithFusionK(k int) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.rrfK = k
+	}
+}
+
+// WithReranker re-scores search candidates with reranker before the top
+// results are returned to the model. Reranking passes are recorded on the
+// retrieval span as rerank.before / rerank.after attributes when tracing is enabled.
+func WithReranker(reranker retrieval.Reranker) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.reranker = reranker
+	}
+}
+
+// WithRerankThreshold drops reranked results scoring below minScore.
+// Cohere, Voyage and LLM rerankers all score on a 0..1 scale.
+func WithRerankThreshold(minScore float64) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.minScore = minScore
+	}
+}
+
+// WithRetrievalCompression shortens each returned passage with compressor,
+// keeping the sentences most relevant to the query. Use
+// retrieval.SentenceCompressor for a fast heuristic or retrieval.LLMCompressor
+// for a small model. A passage that fails to compress is returned as is.
+// With tracing enabled the retrieval span carries compression.chars_before /
+// compression.chars_after attributes.
+func WithRetrievalCompression(compressor retrieval.Compressor) RetrievalOption {
+	return func(o *retrievalOptions) {
+		o.compressor = compressor
+	}
+}
+
+// NewRetrievalTool returns a tool that searches store for passages relevant to
+// the model's query. store and embedder may be nil for keyword-only search.
+func NewRetrievalTool(store retrieval.VectorStore, embedder providers.Embedder, opts ...RetrievalOption) Tool {
+	options := retrievalOptions{
+		name:        defaultRetrievalToolName,
+		description: "Search the knowledge base and return the most relevant passages with their sources.",
+		topK:        defaultRetrievalTopK,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	retriever := &retrieval.Retriever{
+		Store:    store,
+		Embedder: embedder,
+		Keyword:  options.keyword,
+		Mode:     options.mode,
+		RRFK:     options.rrfK,
+		Reranker: options.reranker,
+		MinScore: options.minScore,
+		OnRerank: traceRerank,
+	}
+
+	return NewTool(options.name).
+		WithDescription(options.description).
+		WithParameter("query", String().Required().WithDescription("What to search for; include exact names, codes or identifiers when known")).
+		WithParameter("top_k", Integer().Optional().WithDescription(fmt.Sprintf("Number of passages to return (default %d, max %d)", options.topK, maxRetrievalTopK))).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			query, _ := args["query"].(string)
+			if query == "" {
+				return nil, errors.New("agentkit: retrieval query is required")
+			}
+			topK := options.topK
+			if n, ok := args["top_k"].(float64); ok && n > 0 {
+				topK = min(int(n), maxRetrievalTopK)
+			}
+
+			tracer := GetTracer(ctx)
+			if tracer != nil && !isNoOpTracer(tracer) {
+				var endSpan func()
+				ctx, endSpan = tracer.StartSpan(ctx, "retrieval."+options.name,
+					WithSpanType(SpanTypeRetrieval),
+					WithSpanInput(map[string]any{"query": query, "top_k": topK}),
+				)
+				defer endSpan()
+			}
+
+			matches, err := retriever.Retrieve(ctx, query, retrieval.QueryOptions{TopK: topK, Filter: options.filter})
+			if err != nil {
+				return nil, err
+			}
+			if tracer != nil && !isNoOpTracer(tracer) {
+				_ = tracer.SetSpanOutput(ctx, rankedMatchIDs(matches))
+			}
+			if options.compressor != nil {
+				compressMatches(ctx, options.compressor, query, matches)
+			}
+
+			results := make([]map[string]any, 0, len(matches))
+			for _, match := range matches {
+				result := map[string]any{
+					"id":      match.ID,
+					"content": match.Content,
+					"score":   match.Score,
+				}
+				if source, ok := match.Metadata[retrieval.MetaSource]; ok {
+					result["source"] = source
+				}
+				if title, ok := match.Metadata[retrieval.MetaTitle]; ok {
+					result["title"] = title
+				}
+				results = append(results, result)
+			}
+			return map[string]any{
+				"query":   query,
+				"results": results,
+			}, nil
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Searching knowledge base for %q...", args["query"])
+		}).
+		Build()
+}
+
+// compressMatches replaces the content of each match with its compressed
+// form and records the savings on the current retrieval span.
+func compressMatches(ctx context.Context, compressor retrieval.Compressor, query string, matches []retrieval.Match) {
+	before, after := 0, 0
+	for i, match := range matches {
+		before += len(match.Content)
+		compressed, err := compressor.Compress(ctx, query, match.Content)
+		if err != nil {
+			after += len(match.Content)
+			continue
+		}
+		matches[i].Content = compressed
+		after += len(compressed)
+	}
+	tracer := GetTracer(ctx)
+	if tracer == nil || isNoOpTracer(tracer) {
+		return
+	}
+	_ = tracer.SetSpanAttributes(ctx, map[string]any{
+		"compression.chars_before": before,
+		"compression.chars_after":  after,
+	})
+}
+
+// traceRerank records a reranking pass on the current retrieval span.
+func traceRerank(ctx context.Context, trace retrieval.RerankTrace) {
+	tracer := GetTracer(ctx)
+	if tracer == nil || isNoOpTracer(tracer) {
+		return
+	}
+	_ = tracer.SetSpanAttributes(ctx, map[string]any{
+		"rerank.before":      trace.Before,
+		"rerank.after":       trace.After,
+		"rerank.threshold":   trace.Threshold,
+		"rerank.dropped":     trace.Dropped,
+		"rerank.duration_ms": trace.Duration.Milliseconds(),
+	})
+}
+
+func rankedMatchIDs(matches []retrieval.Match) []retrieval.RankedID {
+	ids := make([]retrieval.RankedID, len(matches))
+	for i, m := range matches {
+		ids[i] = retrieval.RankedID{ID: m.ID, Score: m.Score}
+	}
+	return ids
+}
@@ -0,0 +1,174 @@
+package agentkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit/retrieval"
+)
+
+// keywordEmbedder gives every text the same direction, so vector search alone
+// cannot tell documents apart.
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i := range texts {
+		vectors[i] = []float32{1, 1}
+	}
+	return vectors, nil
+}
+
+func TestNewRetrievalTool_Hybrid(t *testing.T) {
+	ctx := context.Background()
+	store := retrieval.NewMemoryStore()
+	keyword := retrieval.NewBM25Index()
+	records := []retrieval.Record{
+		{ID: "a", Content: "General troubleshooting guide.", Vector: []float32{1, 1}, Metadata: retrieval.Metadata{retrieval.MetaSource: "guide.md"}},
+		{ID: "b", Content: "SKU-99812 ships in two days.", Vector: []float32{1, 1}, Metadata: retrieval.Metadata{retrieval.MetaSource: "catalog.md"}},
+	}
+	if err := store.Upsert(ctx, records...); err != nil {
+		t.Fatal(err)
+	}
+	if err := keyword.Index(ctx, records...); err != nil {
+		t.Fatal(err)
+	}
+
+	tool := NewRetrievalTool(store, keywordEmbedder{},
+		WithKeywordSearch(keyword),
+		WithRetrievalName("search_docs"),
+		WithRetrievalTopK(1),
+	)
+	if tool.Name() != "search_docs" {
+		t.Errorf("Name() = %q", tool.Name())
+	}
+
+	result, err := tool.Execute(ctx, `{"query": "when does SKU-99812 ship?"}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	results := result.(map[string]any)["results"].([]map[string]any)
+	if len(results) != 1 {
+		t.Fatalf("results = %d, want 1", len(results))
+	}
+	if results[0]["id"] != "b" || results[0]["source"] != "catalog.md" {
+		t.Errorf("top result = %v, want b from catalog.md", results[0])
+	}
+}
+
+func TestNewRetrievalTool_KeywordOnly(t *testing.T) {
+	keyword := retrieval.NewBM25Index()
+	_ = keyword.Index(context.Background(), retrieval.Record{ID: "x", Content: "Refund policy: 30 days."})
+
+	tool := NewRetrievalTool(nil, nil, WithKeywordSearch(keyword))
+	result, err := tool.Execute(context.Background(), `{"query": "refund", "top_k": 3}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	results := result.(map[string]any)["results"].([]map[string]any)
+	if len(results) != 1 || !strings.Contains(results[0]["content"].(string), "Refund") {
+		t.Errorf("results = %v", results)
+	}
+
+	if _, err := tool.Execute(context.Background(), `{"query": ""}`); err == nil {
+		t.Error("expected error for empty query")
+	}
+}
+
+// spanAttributeTracer records span attributes and output.
+type spanAttributeTracer struct {
+	NoOpTracer
+	spans      []string
+	attributes map[string]any
+	output     any
+}
+
+func (s *spanAttributeTracer) StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, func()) {
+	s.spans = append(s.spans, name)
+	return ctx, func() {}
+}
+
+func (s *spanAttributeTracer) SetSpanAttributes(ctx context.Context, attributes map[string]any) error {
+	s.attributes = attributes
+	return nil
+}
+
+func (s *spanAttributeTracer) SetSpanOutput(ctx context.Context, output any) error {
+	s.output = output
+	return nil
+}
+
+func TestNewRetrievalTool_RerankerTraceAttributes(t *testing.T) {
+	keyword := retrieval.NewBM25Index()
+	_ = keyword.Index(context.Background(),
+		retrieval.Record{ID: "old", Content: "Refund policy from 2019."},
+		retrieval.Record{ID: "new", Content: "Current refund policy: refunds within 30 days."},
+	)
+	reranker := retrieval.RerankerFunc(func(_ context.Context, _ string, matches []retrieval.Match) ([]retrieval.Match, error) {
+		out := make([]retrieval.Match, 0, len(matches))
+		for _, m := range matches {
+			if m.ID == "new" {
+				m.Score = 0.9
+				out = append([]retrieval.Match{m}, out...)
+			} else {
+				m.Score = 0.2
+				out = append(out, m)
+			}
+		}
+		return out, nil
+	})
+
+	tool := NewRetrievalTool(nil, nil,
+		WithKeywordSearch(keyword),
+		WithReranker(reranker),
+		WithRerankThreshold(0.5),
+	)
+	tracer := &spanAttributeTracer{}
+	ctx := WithTracer(context.Background(), tracer)
+
+	result, err := tool.Execute(ctx, `{"query": "refund policy"}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	results := result.(map[string]any)["results"].([]map[string]any)
+	if len(results) != 1 || results[0]["id"] != "new" {
+		t.Fatalf("results = %v, want only the reranked match above threshold", results)
+	}
+
+	if len(tracer.spans) != 1 || tracer.spans[0] != "retrieval.search_knowledge_base" {
+		t.Errorf("spans = %v", tracer.spans)
+	}
+	before, _ := tracer.attributes["rerank.before"].([]retrieval.RankedID)
+	after, _ := tracer.attributes["rerank.after"].([]retrieval.RankedID)
+	if len(before) != 2 || len(after) != 1 || after[0].ID != "new" || tracer.attributes["rerank.dropped"] != 1 {
+		t.Errorf("rerank attributes = %v", tracer.attributes)
+	}
+}
+
+func TestNewRetrievalTool_Compression(t *testing.T) {
+	content := "Our company history goes back decades. Refunds are issued within 30 days. " +
+		"The office is closed on public holidays. Parking is available behind the building."
+	keyword := retrieval.NewBM25Index()
+	_ = keyword.Index(context.Background(), retrieval.Record{ID: "doc", Content: content})
+
+	tool := NewRetrievalTool(nil, nil,
+		WithKeywordSearch(keyword),
+		WithRetrievalCompression(retrieval.SentenceCompressor{Ratio: 0.3, MinChars: 10}),
+	)
+	tracer := &spanAttributeTracer{}
+	ctx := WithTracer(context.Background(), tracer)
+
+	result, err := tool.Execute(ctx, `{"query": "refunds"}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	results := result.(map[string]any)["results"].([]map[string]any)
+	if len(results) != 1 || results[0]["content"] != "Refunds are issued within 30 days." {
+		t.Fatalf("results = %v, want the compressed passage", results)
+	}
+	if tracer.attributes["compression.chars_before"] != len(content) ||
+		tracer.attributes["compression.chars_after"] != len("Refunds are issued within 30 days.") {
+		t.Errorf("compression attributes = %v", tracer.attributes)
+	}
+}
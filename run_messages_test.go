@@ -0,0 +1,65 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestRunMessages_SendsInitialMessages(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	recorder := &startRecorder{}
+	agent.Use(recorder)
+
+	messages := []providers.Message{
+		{Role: providers.RoleSystem, Content: "The customer is on the enterprise plan."},
+		{Role: providers.RoleAssistant, Content: "How can I help?"},
+		{Role: providers.RoleUser, Content: "Why was I charged twice?"},
+		{Role: providers.RoleUser, Content: "Here is the invoice.", Images: []providers.Image{{URL: "https://example.com/invoice.png"}}},
+	}
+	events := collectEvents(agent.RunMessages(context.Background(), messages), time.Second)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(provider.requests))
+	}
+	sent := provider.requests[0].Messages
+	if len(sent) != 4 || sent[0].Role != providers.RoleSystem || sent[3].Images[0].URL != "https://example.com/invoice.png" {
+		t.Errorf("sent messages = %+v", sent)
+	}
+	if len(recorder.inputs) != 1 || recorder.inputs[0] != "Why was I charged twice?\n\nHere is the invoice." {
+		t.Errorf("middleware inputs = %q", recorder.inputs)
+	}
+	var output any
+	for _, e := range events {
+		if e.Type == EventTypeFinalOutput {
+			output = e.Data["response"]
+		}
+	}
+	if output != "done" {
+		t.Errorf("final output = %v", output)
+	}
+}
+
+func TestRunMessages_Empty(t *testing.T) {
+	agent := newMockAgent(t, mockprovider.New().WithResponse("unused", nil))
+	events := collectEvents(agent.RunMessages(context.Background(), nil), time.Second)
+
+	for _, e := range events {
+		if detail, ok := e.ErrorDetail(); ok {
+			if !errors.Is(detail, ErrNoMessages) {
+				t.Errorf("error = %v, want ErrNoMessages", detail)
+			}
+			return
+		}
+	}
+	t.Fatal("expected an error event")
+}
@@ -0,0 +1,135 @@
+package agentkit
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// RunOption adjusts a single run, for per-request variations that don't
+// warrant building another Agent.
+type RunOption func(*runOptions)
+
+// runOptions holds the RunOptions of one run.
+type runOptions struct {
+	temperature    *float32
+	maxIterations  int
+	metadata       map[string]any
+	tools          []string
+	conversationID string
+	audioOutput    *providers.AudioOutput // Set by RunAudio
+}
+
+// WithRunTemperature overrides Config.Temperature for the run. Deterministic
+// agents keep a temperature of 0.
+func WithRunTemperature(temperature float32) RunOption {
+	return func(o *runOptions) { o.temperature = &temperature }
+}
+
+// WithRunMaxIterations overrides Config.MaxIterations for the run.
+func WithRunMaxIterations(n int) RunOption {
+	return func(o *runOptions) { o.maxIterations = n }
+}
+
+// WithRunMetadata attaches metadata to the run, such as a tenant or request
+// ID. It is recorded on the trace as "metadata.<key>" attributes, listed by
+// ListActiveRuns and available to tools and middleware through RunMetadata.
+// Several calls merge.
+func WithRunMetadata(metadata map[string]any) RunOption {
+	return func(o *runOptions) {
+		if o.metadata == nil {
+			o.metadata = make(map[string]any, len(metadata))
+		}
+		maps.Copy(o.metadata, metadata)
+	}
+}
+
+// WithRunTools limits the run to the named tools and toolsets, like
+// WithEnabledTools. Unlike WithEnabledTools, the selection doesn't apply to
+// nested agents the run calls as tools.
+func WithRunTools(names ...string) RunOption {
+	return func(o *runOptions) { o.tools = append(o.tools, names...) }
+}
+
+// WithRunConversationID runs in the conversation with the given ID. With a
+// conversation store configured, the run continues the stored history like
+// RunWithConversation; otherwise the ID only scopes the run, like
+// WithConversation.
+func WithRunConversationID(conversationID string) RunOption {
+	return func(o *runOptions) { o.conversationID = conversationID }
+}
+
+func newRunOptions(opts []RunOption) *runOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	o := &runOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// apply sets the context-scoped options on ctx.
+func (o *runOptions) apply(ctx context.Context) context.Context {
+	if o == nil {
+		return ctx
+	}
+	if o.conversationID != "" {
+		ctx = WithConversation(ctx, o.conversationID)
+	}
+	return ctx
+}
+
+// currentRunOptions returns the options of the run of ctx. Runs of nested
+// agents have their own, so overrides don't leak into them.
+func currentRunOptions(ctx context.Context) *runOptions {
+	if run, ok := ctx.Value(activeRunKey).(*activeRun); ok {
+		return run.options
+	}
+	return nil
+}
+
+// RunMetadata returns the metadata attached to the current run with
+// WithRunMetadata.
+func RunMetadata(ctx context.Context) map[string]any {
+	if o := currentRunOptions(ctx); o != nil {
+		return maps.Clone(o.metadata)
+	}
+	return nil
+}
+
+// runTemperature returns the sampling temperature for this run.
+func (a *Agent) runTemperature(ctx context.Context) float32 {
+	if o := currentRunOptions(ctx); o != nil && o.temperature != nil {
+		return *o.temperature
+	}
+	return a.temperature
+}
+
+// runOptionsAllowTool reports whether the WithRunTools selection of the run
+// of ctx includes tool, by its name or its toolset's.
+func runOptionsAllowTool(ctx context.Context, tool *Tool) bool {
+	o := currentRunOptions(ctx)
+	if o == nil || o.tools == nil {
+		return true
+	}
+	return slices.Contains(o.tools, tool.name) || (tool.toolset != "" && slices.Contains(o.tools, tool.toolset))
+}
+
+// recordRunMetadata records the run's metadata on its trace.
+func (a *Agent) recordRunMetadata(ctx context.Context) {
+	o := currentRunOptions(ctx)
+	if o == nil || len(o.metadata) == 0 {
+		return
+	}
+	attributes := make(map[string]any, len(o.metadata))
+	for key, value := range o.metadata {
+		attributes["metadata."+key] = value
+	}
+	if err := a.tracer.SetTraceAttributes(ctx, attributes); err != nil {
+		a.logger.Debug("failed to record run metadata", "error", err)
+	}
+}
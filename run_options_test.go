@@ -0,0 +1,132 @@
+package agentkit
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestRunOptions_OverrideOneRun(t *testing.T) {
+	var seen map[string]any
+	tracer := &traceAttributeTracer{}
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "lookup", Arguments: map[string]any{}}}).
+		WithResponse("done", nil).
+		WithResponse("default", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", Temperature: 0.7, StreamResponses: false, Tracer: tracer})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("lookup").WithHandler(func(ctx context.Context, _ map[string]any) (any, error) {
+		seen = RunMetadata(ctx)
+		return "ok", nil
+	}).Build())
+	agent.AddTool(NewTool("delete").Build())
+
+	collectEvents(agent.Run(context.Background(), "Find it",
+		WithRunTemperature(0.1),
+		WithRunTools("lookup"),
+		WithRunMetadata(map[string]any{"tenant": "acme"}),
+		WithRunMetadata(map[string]any{"request_id": "r-1"}),
+	), time.Second)
+
+	first := provider.requests[0]
+	if first.Temperature != 0.1 {
+		t.Errorf("temperature = %v, want 0.1", first.Temperature)
+	}
+	if got := offeredTools(first); !slices.Equal(got, []string{"lookup"}) {
+		t.Errorf("offered tools = %v, want [lookup]", got)
+	}
+	if seen["tenant"] != "acme" || seen["request_id"] != "r-1" {
+		t.Errorf("RunMetadata() in tool = %v", seen)
+	}
+	if tracer.attributes["metadata.tenant"] != "acme" {
+		t.Errorf("trace attributes = %v, want metadata.tenant", tracer.attributes)
+	}
+
+	collectEvents(agent.Run(context.Background(), "Again"), time.Second)
+	last := provider.requests[len(provider.requests)-1]
+	if last.Temperature != 0.7 || len(last.Tools) != 2 {
+		t.Errorf("options leaked into the next run: temperature = %v, tools = %v", last.Temperature, offeredTools(last))
+	}
+}
+
+func TestRunOptions_ToolsDontApplyToNestedAgents(t *testing.T) {
+	subProvider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-2", Name: "search", Arguments: map[string]any{}}}).
+		WithResponse("found", nil)}
+	sub, err := New(Config{Provider: subProvider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	searched := false
+	sub.AddTool(NewTool("search").WithHandler(func(context.Context, map[string]any) (any, error) {
+		searched = true
+		return "result", nil
+	}).Build())
+
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "research", Arguments: map[string]any{"input": "look it up"}}}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(sub.AsTool("research", "Research a topic"))
+	agent.AddTool(NewTool("delete").Build())
+
+	collectEvents(agent.Run(context.Background(), "Research it", WithRunTools("research")), time.Second)
+
+	if got := offeredTools(subProvider.requests[0]); !slices.Equal(got, []string{"search"}) {
+		t.Errorf("nested agent offered tools = %v, want [search]", got)
+	}
+	if !searched {
+		t.Error("nested agent could not call its own tool")
+	}
+}
+
+func TestRunOptions_MaxIterations(t *testing.T) {
+	call := []providers.ToolCall{{ID: "call-1", Name: "lookup", Arguments: map[string]any{}}}
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", call).
+		WithResponse("", call).
+		WithResponse("", call)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", MaxIterations: 5, StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("lookup").WithHandler(func(context.Context, map[string]any) (any, error) {
+		return "not yet", nil
+	}).Build())
+
+	collectEvents(agent.Run(context.Background(), "Loop", WithRunMaxIterations(1)), time.Second)
+	if len(provider.requests) != 1 {
+		t.Errorf("requests = %d, want 1", len(provider.requests))
+	}
+}
+
+func TestRunOptions_ConversationID(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("Hi Ana.", nil).
+		WithResponse("Your name is Ana.", nil)}
+	store := NewMemoryConversationStore()
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false, ConversationStore: store})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	collectEvents(agent.Run(context.Background(), "I'm Ana.", WithRunConversationID("conv-1")), time.Second)
+	collectEvents(agent.Run(context.Background(), "What's my name?", WithRunConversationID("conv-1")), time.Second)
+
+	if sent := provider.requests[1].Messages; len(sent) != 3 || sent[0].Content != "I'm Ana." {
+		t.Errorf("second run messages = %+v, want the stored history", sent)
+	}
+	conv, err := store.Load(context.Background(), "conv-1")
+	if err != nil || len(conv.Turns) != 4 {
+		t.Errorf("stored conversation = %+v, %v", conv, err)
+	}
+}
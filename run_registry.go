@@ -0,0 +1,199 @@
+package agentkit
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// abandonedRunGrace is how long a run keeps waiting for its consumer to
+// receive an event after the caller's context is done. A consumer that is
+// still draining the channel gets the remaining events; one that has gone
+// away no longer holds the run's goroutines forever.
+var abandonedRunGrace = 5 * time.Second
+
+const activeRunKey contextKey = "agentkit_active_run"
+
+// ActiveRun describes a run in progress, as listed by ListActiveRuns.
+type ActiveRun struct {
+	ID      uint64    // Unique within the process, in start order
+	Agent   string    // Name of the agent running
+	Started time.Time // When Run was called
+	// BlockedSince is when the run started waiting for its consumer to
+	// receive an event, or zero when it isn't waiting. A run blocked for
+	// long usually means the caller stopped reading the event channel
+	// without cancelling the context.
+	BlockedSince time.Time
+	Metadata     map[string]any // Set with WithRunMetadata
+}
+
+// ActiveRuns returns the number of runs in progress across all agents in
+// the process. A run counts from the call to Run until its event channel is
+// closed, so a gauge that keeps growing under steady load points to runs
+// whose channels are never drained.
+func ActiveRuns() int {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return len(registry.runs)
+}
+
+// ListActiveRuns returns the runs in progress, oldest first.
+func ListActiveRuns() []ActiveRun {
+	registry.mu.Lock()
+	list := make([]ActiveRun, 0, len(registry.runs))
+	for _, run := range registry.runs {
+		list = append(list, run.snapshot())
+	}
+	registry.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+var registry = runRegistry{runs: make(map[uint64]*activeRun)}
+
+// runRegistry tracks the runs in progress.
+type runRegistry struct {
+	mu   sync.Mutex
+	next uint64
+	runs map[uint64]*activeRun
+}
+
+// add registers run, unless its agent was stopped by Runtime.Shutdown.
+func (r *runRegistry) add(run *activeRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if run.agent.stopped {
+		return ErrRuntimeShutdown
+	}
+	r.next++
+	run.id = r.next
+	r.runs[run.id] = run
+	return nil
+}
+
+func (r *runRegistry) remove(run *activeRun) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.runs, run.id)
+}
+
+// stop makes agents refuse new runs and returns how many of their runs are
+// still in progress.
+func (r *runRegistry) stop(agents []*Agent) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, agent := range agents {
+		agent.stopped = true
+	}
+	return r.count(agents)
+}
+
+// active returns how many runs of agents are in progress.
+func (r *runRegistry) active(agents []*Agent) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count(agents)
+}
+
+func (r *runRegistry) count(agents []*Agent) int {
+	n := 0
+	for _, run := range r.runs {
+		if slices.Contains(agents, run.agent) {
+			n++
+		}
+	}
+	return n
+}
+
+// activeRun is the registry entry of one run. It also delivers the run's
+// events, so it knows when the run is waiting on its consumer.
+type activeRun struct {
+	id        uint64
+	agent     *Agent
+	started   time.Time
+	done      <-chan struct{} // Done channel of the caller's context
+	logger    *slog.Logger
+	blocked   atomic.Int64 // Unix nanoseconds a send started blocking, or 0
+	abandoned atomic.Bool
+	options   *runOptions // Set before the run starts, read-only after
+}
+
+// startRun registers a run of agent a, called with ctx. It fails with
+// ErrRuntimeShutdown once a was stopped.
+func (a *Agent) startRun(ctx context.Context, started time.Time) (*activeRun, error) {
+	run := &activeRun{agent: a, started: started, done: ctx.Done(), logger: a.logger}
+	if err := registry.add(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// forwarder returns a sender for a goroutine that relays a run's events to
+// another channel, such as the wrapper of RunWithConversation. It drops
+// events like the run's own send once ctx is done and nobody reads them. It
+// isn't registered, so the run is counted once.
+func (a *Agent) forwarder(ctx context.Context) *activeRun {
+	return &activeRun{agent: a, started: time.Now(), done: ctx.Done(), logger: a.logger}
+}
+
+func (r *activeRun) snapshot() ActiveRun {
+	info := ActiveRun{ID: r.id, Agent: r.agent.agentName, Started: r.started}
+	if r.options != nil {
+		info.Metadata = maps.Clone(r.options.metadata)
+	}
+	if since := r.blocked.Load(); since != 0 {
+		info.BlockedSince = time.Unix(0, since)
+	}
+	return info
+}
+
+// send delivers event on events. Once the caller's context is done and the
+// consumer hasn't taken an event for abandonedRunGrace, the run counts as
+// abandoned and events that would block are dropped, so the run can finish.
+func (r *activeRun) send(events chan<- Event, event Event) {
+	select {
+	case events <- event:
+		return
+	default:
+	}
+	if r.abandoned.Load() {
+		return
+	}
+	if r.blocked.CompareAndSwap(0, time.Now().UnixNano()) {
+		defer r.blocked.Store(0)
+	}
+	select {
+	case events <- event:
+		return
+	case <-r.done:
+	}
+
+	timer := time.NewTimer(abandonedRunGrace)
+	defer timer.Stop()
+	select {
+	case events <- event:
+	case <-timer.C:
+		if r.abandoned.CompareAndSwap(false, true) {
+			attrs := []any{"agent", r.agent.agentName, "dropped_event", event.Type}
+			if r.id != 0 { // Forwarders aren't registered
+				attrs = append(attrs, "run_id", r.id)
+			}
+			r.logger.Warn("run abandoned: the context is done and events are no longer read; dropping the rest", attrs...)
+		}
+	}
+}
+
+// sendEvent delivers event on events through the run registered in ctx, or
+// directly when there is none.
+func sendEvent(ctx context.Context, events chan<- Event, event Event) {
+	if run, ok := ctx.Value(activeRunKey).(*activeRun); ok {
+		run.send(events, event)
+		return
+	}
+	events <- event
+}
@@ -0,0 +1,181 @@
+package agentkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/internal/leakcheck"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// blockingToolAgent returns an agent whose one tool call blocks until
+// release is closed or the context is done.
+func blockingToolAgent(t *testing.T, release <-chan struct{}) *Agent {
+	t.Helper()
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "wait", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)
+	agent, err := New(Config{Provider: provider, Model: "test-model", EventBuffer: 1, StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("wait").WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+		select {
+		case <-release:
+			return "released", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}).Build())
+	return agent
+}
+
+// readUntil reads events until one of type typ arrives.
+func readUntil(t *testing.T, events <-chan Event, typ EventType) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("events closed before %s", typ)
+			}
+			if event.Type == typ {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+// waitForActiveRuns waits for ActiveRuns to drop to want.
+func waitForActiveRuns(t *testing.T, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for ActiveRuns() != want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := ActiveRuns(); got != want {
+		t.Errorf("ActiveRuns() = %d, want %d", got, want)
+	}
+}
+
+func TestActiveRuns(t *testing.T) {
+	defer leakcheck.Check(t)()
+	before := ActiveRuns()
+	release := make(chan struct{})
+	agent := blockingToolAgent(t, release)
+	agent.agentName = "waiter"
+
+	events := agent.Run(context.Background(), "wait")
+	readUntil(t, events, EventTypeActionDetected)
+	if got := ActiveRuns(); got != before+1 {
+		t.Errorf("ActiveRuns() during run = %d, want %d", got, before+1)
+	}
+	list := ListActiveRuns()
+	if last := list[len(list)-1]; last.Agent != "waiter" || last.Started.IsZero() {
+		t.Errorf("ListActiveRuns() = %+v", list)
+	}
+
+	close(release)
+	collectEvents(events, time.Second)
+	if got := ActiveRuns(); got != before {
+		t.Errorf("ActiveRuns() after run = %d, want %d", got, before)
+	}
+}
+
+func TestActiveRuns_ReportsBlockedConsumer(t *testing.T) {
+	defer leakcheck.Check(t)()
+	release := make(chan struct{})
+	close(release)
+	agent := blockingToolAgent(t, release)
+
+	// Nobody reads: the run fills the buffer and waits on its consumer.
+	events := agent.Run(context.Background(), "wait")
+	var blocked bool
+	for deadline := time.Now().Add(time.Second); !blocked && time.Now().Before(deadline); {
+		for _, run := range ListActiveRuns() {
+			blocked = blocked || !run.BlockedSince.IsZero()
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !blocked {
+		t.Errorf("no run reported as blocked: %+v", ListActiveRuns())
+	}
+	collectEvents(events, time.Second)
+}
+
+func TestRun_NoLeaks(t *testing.T) {
+	defer func(grace time.Duration) { abandonedRunGrace = grace }(abandonedRunGrace)
+	abandonedRunGrace = 50 * time.Millisecond
+
+	// Each case starts a run whose tool blocks until release is closed.
+	// Wrappers relay the run's events from goroutines of their own, which
+	// must give up on an abandoned consumer too.
+	tests := map[string]func(t *testing.T, ctx context.Context, release <-chan struct{}) <-chan Event{
+		"top level": func(t *testing.T, ctx context.Context, release <-chan struct{}) <-chan Event {
+			return blockingToolAgent(t, release).Run(ctx, "wait")
+		},
+		"nested": func(t *testing.T, ctx context.Context, release <-chan struct{}) <-chan Event {
+			return blockingToolAgent(t, release).Run(WithEventPublisher(ctx, func(Event) {}), "wait")
+		},
+		"conversation": func(t *testing.T, ctx context.Context, release <-chan struct{}) <-chan Event {
+			agent := blockingToolAgent(t, release)
+			agent.conversationStore = NewMemoryConversationStore()
+			return agent.Run(ctx, "wait", WithRunConversationID("conv-1"))
+		},
+		"stream shaping": func(t *testing.T, ctx context.Context, release <-chan struct{}) <-chan Event {
+			agent := blockingToolAgent(t, release)
+			agent.streamShaping = StreamShapingConfig{CoalesceThinking: true}
+			return agent.Run(ctx, "wait")
+		},
+		"shadow": func(t *testing.T, ctx context.Context, release <-chan struct{}) <-chan Event {
+			done := make(chan struct{})
+			close(done)
+			shadow := NewShadow(blockingToolAgent(t, release), ShadowConfig{Candidate: blockingToolAgent(t, done)})
+			return shadow.Run(ctx, "wait")
+		},
+		"multimodal": func(t *testing.T, ctx context.Context, release <-chan struct{}) <-chan Event {
+			agent := blockingToolAgent(t, release)
+			agent.provider = &uploadingProvider{recordingProvider: &recordingProvider{Provider: agent.provider.(*mockprovider.Provider)}}
+			return agent.RunMultimodal(ctx, Input{Text: "wait", Files: []FileSource{{Name: "q3.pdf", Data: []byte("%PDF-1.7"), Upload: true}}})
+		},
+	}
+	for name, start := range tests {
+		t.Run(name+"/completed", func(t *testing.T) {
+			defer leakcheck.Check(t)()
+			before := ActiveRuns()
+			release := make(chan struct{})
+			close(release)
+			collectEvents(start(t, context.Background(), release), time.Second)
+			waitForActiveRuns(t, before)
+		})
+
+		t.Run(name+"/cancelled and drained", func(t *testing.T) {
+			defer leakcheck.Check(t)()
+			before := ActiveRuns()
+			ctx, cancel := context.WithCancel(context.Background())
+			events := start(t, ctx, make(chan struct{}))
+			readUntil(t, events, EventTypeActionDetected)
+			cancel()
+			got := collectEvents(events, time.Second)
+			if len(got) == 0 || got[len(got)-1].Type != EventTypeAgentComplete {
+				t.Errorf("drained events = %v, want them to end with %s", got, EventTypeAgentComplete)
+			}
+			waitForActiveRuns(t, before)
+		})
+
+		t.Run(name+"/cancelled and abandoned", func(t *testing.T) {
+			defer leakcheck.Check(t)()
+			before := ActiveRuns()
+			ctx, cancel := context.WithCancel(context.Background())
+			events := start(t, ctx, make(chan struct{}))
+			readUntil(t, events, EventTypeActionDetected)
+			cancel() // And stop reading
+			waitForActiveRuns(t, before)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrRuntimeShutdown is reported by runs started on an agent after its
+// Runtime began shutting down.
+var ErrRuntimeShutdown = errors.New("agentkit: runtime is shutting down")
+
+// DefaultShutdownTimeout bounds Runtime.Shutdown when its context has no
+// deadline.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// ShutdownStage orders the components a Runtime closes. Stages run in
+// order; within a stage, components close in the reverse order they were
+// added, as deferred calls would.
+type ShutdownStage int
+
+const (
+	// StageIngress stops what starts runs, such as HTTP servers and queue
+	// consumers. It runs before the runs in progress are waited for.
+	StageIngress ShutdownStage = iota
+	// StageSinks flushes and closes event sinks, once runs no longer emit.
+	StageSinks
+	// StageStores closes conversation, state, memory and artifact stores.
+	StageStores
+	// StageTracing flushes and shuts down tracers, last so the earlier
+	// stages are still traced.
+	StageTracing
+)
+
+func (s ShutdownStage) String() string {
+	switch s {
+	case StageIngress:
+		return "ingress"
+	case StageSinks:
+		return "sinks"
+	case StageStores:
+		return "stores"
+	case StageTracing:
+		return "tracing"
+	}
+	return fmt.Sprintf("stage %d", int(s))
+}
+
+// RuntimeConfig configures a Runtime.
+type RuntimeConfig struct {
+	// Timeout bounds Shutdown when its context has no deadline
+	// (default DefaultShutdownTimeout).
+	Timeout time.Duration
+	// DrainTimeout limits the wait for runs in progress, so time is left
+	// to close the components after them (default: half the time left).
+	DrainTimeout time.Duration
+	Logger       *slog.Logger
+}
+
+// Runtime owns the long-lived components around agents (tracers, event
+// sinks and stores) and shuts them down in dependency order:
+//
+//	rt := agentkit.NewRuntime(agentkit.RuntimeConfig{})
+//	rt.AddAgent(agent) // Its tracer, event sinks and stores
+//	rt.OnShutdown(agentkit.StageIngress, "http", server.Shutdown)
+//	...
+//	if err := rt.Shutdown(ctx); err != nil {
+//		log.Println(err)
+//	}
+//
+// Shutdown makes the added agents refuse new runs with ErrRuntimeShutdown,
+// stops the ingress, waits for the runs in progress and then closes sinks,
+// stores and tracers, in that order. Finally it closes the idle connections
+// of the shared provider HTTP client.
+type Runtime struct {
+	cfg RuntimeConfig
+
+	mu         sync.Mutex
+	agents     []*Agent
+	components []component
+	seen       map[any]bool
+
+	once sync.Once
+	err  error
+}
+
+// component is something a Runtime closes.
+type component struct {
+	name  string
+	stage ShutdownStage
+	close func(context.Context) error
+}
+
+// NewRuntime returns an empty Runtime.
+func NewRuntime(cfg RuntimeConfig) *Runtime {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultShutdownTimeout
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return &Runtime{cfg: cfg, seen: make(map[any]bool)}
+}
+
+// AddAgent adds agent's tracer, event sinks, cost monitor (its webhook
+// deliveries are awaited with the sinks), conversation store, state store
+// and memory, and lets Shutdown wait for its runs. Components shared
+// by several agents are closed once; those without a Close or Shutdown
+// method are skipped.
+func (r *Runtime) AddAgent(agent *Agent) {
+	r.mu.Lock()
+	if !slices.Contains(r.agents, agent) {
+		r.agents = append(r.agents, agent)
+	}
+	r.mu.Unlock()
+
+	r.AddTracer(agent.tracer)
+	for _, sink := range agent.eventSinks {
+		r.AddSink(sink)
+	}
+	if agent.costMonitor != nil {
+		r.add(agent.costMonitor, component{name: "cost monitor", stage: StageSinks, close: agent.costMonitor.Close})
+	}
+	r.AddStore(agent.conversationStore)
+	r.AddStore(agent.stateStore)
+	if agent.memory != nil {
+		r.AddStore(agent.memory.Memory)
+		r.AddStore(agent.memory.Store)
+	}
+	if agent.graphMemory != nil && agent.graphMemory.Graph != nil {
+		r.AddStore(agent.graphMemory.Graph)
+	}
+}
+
+// AddTracer adds a tracer, flushed and then shut down, when it has a
+// Shutdown(context.Context) error method, in StageTracing.
+func (r *Runtime) AddTracer(tracer Tracer) {
+	if tracer == nil {
+		return
+	}
+	if wrapped, ok := tracer.(interface{ Unwrap() Tracer }); ok {
+		tracer = wrapped.Unwrap()
+	}
+	r.add(tracer, component{name: fmt.Sprintf("tracer %T", tracer), stage: StageTracing, close: func(ctx context.Context) error {
+		err := tracer.Flush(ctx)
+		if s, ok := tracer.(shutdowner); ok {
+			err = errors.Join(err, s.Shutdown(ctx))
+		}
+		return err
+	}})
+}
+
+// AddSink adds an event sink, closed in StageSinks. Sinks such as
+// BrokerSink publish what they buffered before Close returns.
+func (r *Runtime) AddSink(sink EventSink) {
+	if close := closerOf(sink); close != nil {
+		r.add(sink, component{name: fmt.Sprintf("sink %T", sink), stage: StageSinks, close: close})
+	}
+}
+
+// AddStore adds a store, or anything else with a Shutdown or Close method
+// such as a *sql.DB, closed in StageStores.
+func (r *Runtime) AddStore(store any) {
+	if close := closerOf(store); close != nil {
+		r.add(store, component{name: fmt.Sprintf("store %T", store), stage: StageStores, close: close})
+	}
+}
+
+// OnShutdown adds fn, called in stage with the shutdown context.
+func (r *Runtime) OnShutdown(stage ShutdownStage, name string, fn func(context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.components = append(r.components, component{name: name, stage: stage, close: fn})
+}
+
+func (r *Runtime) add(key any, c component) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reflect.ValueOf(key).Comparable() {
+		if r.seen[key] {
+			return
+		}
+		r.seen[key] = true
+	}
+	r.components = append(r.components, c)
+}
+
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// closerOf returns a function closing v, or nil when v has no Shutdown or
+// Close method.
+func closerOf(v any) func(context.Context) error {
+	switch c := v.(type) {
+	case shutdowner:
+		return c.Shutdown
+	case interface{ Close(context.Context) error }:
+		return c.Close
+	case interface{ Close() error }:
+		return func(context.Context) error { return c.Close() }
+	case interface{ Close() }:
+		return func(context.Context) error {
+			c.Close()
+			return nil
+		}
+	}
+	return nil
+}
+
+// Shutdown stops the runtime's agents and closes its components, stage by
+// stage, within ctx's deadline or RuntimeConfig.Timeout. Components that
+// fail or don't close in time are reported in the returned error; a
+// failure doesn't keep the others from closing. Later calls return the same error; components
+// added after the first call are not closed.
+func (r *Runtime) Shutdown(ctx context.Context) error {
+	r.once.Do(func() { r.err = r.shutdown(ctx) })
+	return r.err
+}
+
+func (r *Runtime) shutdown(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+	r.mu.Lock()
+	agents := slices.Clone(r.agents)
+	components := slices.Clone(r.components)
+	r.mu.Unlock()
+
+	registry.stop(agents)
+	errs := r.closeStage(ctx, StageIngress, components)
+	if err := r.drain(ctx, agents); err != nil {
+		errs = append(errs, err)
+	}
+	for _, stage := range []ShutdownStage{StageSinks, StageStores, StageTracing} {
+		errs = append(errs, r.closeStage(ctx, stage, components)...)
+	}
+	providers.DefaultHTTPClient().CloseIdleConnections()
+	return errors.Join(errs...)
+}
+
+// drain waits for the runs of agents to finish.
+func (r *Runtime) drain(ctx context.Context, agents []*Agent) error {
+	timeout := r.cfg.DrainTimeout
+	if timeout <= 0 {
+		deadline, _ := ctx.Deadline()
+		timeout = time.Until(deadline) / 2
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		n := registry.active(agents)
+		if n == 0 {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("agentkit: shutdown: %d runs still in progress: %w", n, ctx.Err())
+		}
+	}
+}
+
+// closeStage closes the components of stage, last added first.
+func (r *Runtime) closeStage(ctx context.Context, stage ShutdownStage, components []component) []error {
+	var errs []error
+	for _, c := range slices.Backward(components) {
+		if c.stage != stage {
+			continue
+		}
+		if err := closeWithin(ctx, c.close); err != nil {
+			r.cfg.Logger.Warn("shutdown failed", "stage", stage, "component", c.name, "error", err)
+			errs = append(errs, fmt.Errorf("agentkit: shutdown %s: %w", c.name, err))
+		}
+	}
+	return errs
+}
+
+// closeWithin calls close, giving up when ctx is done; close keeps running
+// in the background then.
+func closeWithin(ctx context.Context, close func(context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- close(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
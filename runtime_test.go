@@ -0,0 +1,185 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// shutdownLog records the order components are closed in.
+type shutdownLog struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *shutdownLog) add(entry string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *shutdownLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.entries)
+}
+
+type shutdownTracer struct {
+	NoOpTracer
+	log *shutdownLog
+}
+
+func (t *shutdownTracer) Flush(ctx context.Context) error {
+	t.log.add("tracer flush")
+	return nil
+}
+
+func (t *shutdownTracer) Shutdown(ctx context.Context) error {
+	t.log.add("tracer shutdown")
+	return nil
+}
+
+type closingSink struct {
+	name string
+	log  *shutdownLog
+}
+
+func (s *closingSink) Publish(ctx context.Context, event Event) {}
+
+func (s *closingSink) Close() { s.log.add(s.name) }
+
+type closingStore struct {
+	ConversationStore
+	log *shutdownLog
+}
+
+func (s *closingStore) Close() error {
+	s.log.add("store")
+	return nil
+}
+
+func TestRuntime_ShutdownOrder(t *testing.T) {
+	log := &shutdownLog{}
+	tracer := &shutdownTracer{log: log}
+	agent, err := New(Config{
+		Provider:          mockprovider.New(),
+		Model:             "test-model",
+		Tracer:            tracer,
+		EventSinks:        []EventSink{&closingSink{name: "sink 1", log: log}, &closingSink{name: "sink 2", log: log}},
+		ConversationStore: &closingStore{ConversationStore: NewMemoryConversationStore(), log: log},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	rt := NewRuntime(RuntimeConfig{})
+	rt.AddAgent(agent)
+	rt.AddAgent(agent) // Closed once all the same
+	rt.OnShutdown(StageIngress, "server", func(ctx context.Context) error {
+		log.add("server")
+		return nil
+	})
+	if err := rt.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	want := []string{"server", "sink 2", "sink 1", "store", "tracer flush", "tracer shutdown"}
+	if got := log.get(); !slices.Equal(got, want) {
+		t.Errorf("closed %v, want %v", got, want)
+	}
+	if err := rt.Shutdown(context.Background()); err != nil || len(log.get()) != len(want) {
+		t.Errorf("second Shutdown() = %v, closed %v", err, log.get())
+	}
+}
+
+func stopped(agent *Agent) bool {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	return agent.stopped
+}
+
+func TestRuntime_WaitsForRuns(t *testing.T) {
+	log := &shutdownLog{}
+	release := make(chan struct{})
+	agent := blockingToolAgent(t, release)
+	agent.eventSinks = []EventSink{&closingSink{name: "sink", log: log}}
+	rt := NewRuntime(RuntimeConfig{})
+	rt.AddAgent(agent)
+
+	events := agent.Run(context.Background(), "wait")
+	readUntil(t, events, EventTypeActionDetected)
+	done := make(chan error, 1)
+	go func() { done <- rt.Shutdown(context.Background()) }()
+
+	// New runs are refused while the one in progress finishes.
+	for deadline := time.Now().Add(time.Second); !stopped(agent); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("agent not stopped")
+		}
+	}
+	refused := collectEvents(agent.Run(context.Background(), "again"), time.Second)
+	if len(refused) != 1 {
+		t.Fatalf("refused run events = %v", refused)
+	}
+	if detail, _ := refused[0].ErrorDetail(); !errors.Is(detail, ErrRuntimeShutdown) {
+		t.Errorf("refused run error = %v", refused[0].Data)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown() returned %v with a run in progress", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if len(log.get()) != 0 {
+		t.Errorf("closed %v before the run finished", log.get())
+	}
+
+	close(release)
+	collectEvents(events, time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if got := log.get(); !slices.Equal(got, []string{"sink"}) {
+		t.Errorf("closed %v", got)
+	}
+}
+
+func TestRuntime_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	agent := blockingToolAgent(t, release)
+	rt := NewRuntime(RuntimeConfig{DrainTimeout: 20 * time.Millisecond})
+	rt.AddAgent(agent)
+	rt.OnShutdown(StageIngress, "queue", func(ctx context.Context) error {
+		return errors.New("connection reset")
+	})
+	rt.OnShutdown(StageSinks, "stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	events := agent.Run(context.Background(), "wait")
+	readUntil(t, events, EventTypeActionDetected)
+	defer collectEvents(events, time.Second)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := rt.Shutdown(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown() took %v", elapsed)
+	}
+	for _, want := range []string{"shutdown queue: connection reset", "1 runs still in progress", "shutdown stuck: context deadline exceeded"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Shutdown() error = %v, want %q", err, want)
+		}
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,240 @@
+package agentkit
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SchemaViolation is a place where a value does not match a JSON schema.
+type SchemaViolation struct {
+	Path    string // JSON path of the offending value, e.g. "$.items[2].name"
+	Message string
+}
+
+// String formats the violation as "path: message".
+func (v SchemaViolation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidateSchema checks value, as decoded by encoding/json, against schema.
+// It covers the JSON Schema keywords agentkit's schema builders produce:
+// type, properties, required, additionalProperties, items, enum, anyOf,
+// minimum/maximum, minLength/maxLength and minItems/maxItems. Other keywords
+// are ignored. It returns nil when value matches.
+func ValidateSchema(schema map[string]any, value any) []SchemaViolation {
+	return schemaValidator{}.validate(schema, value)
+}
+
+// schemaValidator holds validation options.
+type schemaValidator struct {
+	// omitNullable lets required properties that accept null be left out.
+	// Strict tool schemas list optional parameters as required with a null
+	// alternative, and models without structured outputs omit them instead.
+	omitNullable bool
+}
+
+func (sv schemaValidator) validate(schema map[string]any, value any) []SchemaViolation {
+	var violations []SchemaViolation
+	sv.check(schema, value, "$", &violations)
+	return violations
+}
+
+func (sv schemaValidator) check(schema map[string]any, value any, path string, violations *[]SchemaViolation) {
+	if schema == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*violations = append(*violations, SchemaViolation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if anyOf := schemaList(schema["anyOf"]); len(anyOf) > 0 {
+		matched := false
+		for _, option := range anyOf {
+			if len(sv.validate(option, value)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("does not match any allowed schema")
+			return
+		}
+	}
+
+	if types := stringList(schema["type"]); len(types) > 0 && !slices.ContainsFunc(types, func(t string) bool { return schemaTypeMatches(t, value) }) {
+		fail("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value))
+		return
+	}
+
+	if enum, ok := schema["enum"]; ok {
+		if values := enumValues(enum); len(values) > 0 && !slices.ContainsFunc(values, func(v any) bool { return fmt.Sprint(v) == fmt.Sprint(value) }) {
+			fail("must be one of %v", values)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range stringList(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				if propertySchema, ok := properties[name].(map[string]any); ok && sv.omitNullable && len(sv.validate(propertySchema, nil)) == 0 {
+					continue
+				}
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propertySchema, known := properties[name].(map[string]any)
+			if !known {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					fail("unexpected property %q", name)
+				}
+				continue
+			}
+			sv.check(propertySchema, v[name], path+"."+name, violations)
+		}
+	case []any:
+		if n, ok := schemaNumber(schema["minItems"]); ok && float64(len(v)) < n {
+			fail("must have at least %v items", n)
+		}
+		if n, ok := schemaNumber(schema["maxItems"]); ok && float64(len(v)) > n {
+			fail("must have at most %v items", n)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				sv.check(items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case string:
+		length := float64(len([]rune(v)))
+		if n, ok := schemaNumber(schema["minLength"]); ok && length < n {
+			fail("must be at least %v characters", n)
+		}
+		if n, ok := schemaNumber(schema["maxLength"]); ok && length > n {
+			fail("must be at most %v characters", n)
+		}
+	case float64:
+		if n, ok := schemaNumber(schema["minimum"]); ok && v < n {
+			fail("must be >= %v", n)
+		}
+		if n, ok := schemaNumber(schema["maximum"]); ok && v > n {
+			fail("must be <= %v", n)
+		}
+	}
+}
+
+// schemaTypeMatches reports whether value has JSON Schema type t.
+func schemaTypeMatches(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// Schemas built in Go hold []string and []map[string]any where decoded JSON
+// holds []any; the helpers below accept both.
+
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func schemaList(v any) []map[string]any {
+	switch v := v.(type) {
+	case []map[string]any:
+		return v
+	case []any:
+		out := make([]map[string]any, 0, len(v))
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				out = append(out, m)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func enumValues(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		return v
+	case []string:
+		out := make([]any, len(v))
+		for i, s := range v {
+			out[i] = s
+		}
+		return out
+	}
+	return nil
+}
+
+func schemaNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
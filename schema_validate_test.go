@@ -0,0 +1,69 @@
+package agentkit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	type Ticket struct {
+		Title    string   `json:"title" required:"true"`
+		Priority string   `json:"priority" required:"true" enum:"low,high"`
+		Count    int      `json:"count" required:"true"`
+		Tags     []string `json:"tags"`
+	}
+	schema, err := SchemaFromStruct(Ticket{})
+	if err != nil {
+		t.Fatalf("SchemaFromStruct() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "valid", input: `{"title": "Login broken", "priority": "high", "count": 2, "tags": null}`},
+		{name: "optional as array", input: `{"title": "x", "priority": "low", "count": 0, "tags": ["a"]}`},
+		{name: "missing required", input: `{"priority": "low", "count": 1, "tags": null}`, want: []string{`$: missing required property "title"`}},
+		{name: "wrong types", input: `{"title": 1, "priority": "urgent", "count": 1.5, "tags": [2]}`, want: []string{
+			"$.count: expected integer, got number",
+			"$.priority: must be one of [low high]",
+			"$.tags: does not match any allowed schema",
+			"$.title: expected string, got number",
+		}},
+		{name: "extra property", input: `{"title": "x", "priority": "low", "count": 1, "tags": null, "owner": "me"}`, want: []string{`$: unexpected property "owner"`}},
+		{name: "not an object", input: `["x"]`, want: []string{"$: expected object, got array"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.input), &value); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, v := range ValidateSchema(schema, value) {
+				got = append(got, v.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ValidateSchema() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSchema_Bounds(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"score": map[string]any{"type": "number", "minimum": 0, "maximum": 1.0},
+			"name":  map[string]any{"type": "string", "maxLength": 3},
+			"ids":   map[string]any{"type": "array", "minItems": 1},
+		},
+	}
+	var value any
+	_ = json.Unmarshal([]byte(`{"score": 1.5, "name": "abcd", "ids": []}`), &value)
+	if got := ValidateSchema(schema, value); len(got) != 3 {
+		t.Errorf("ValidateSchema() = %v, want 3 violations", got)
+	}
+}
@@ -0,0 +1,230 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ShadowRun summarizes one side of a shadowed request.
+type ShadowRun struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+	// Actions lists the tool calls the run made, as shown in action_detected events.
+	Actions     []string      `json:"actions,omitempty"`
+	TotalTokens int           `json:"total_tokens"`
+	Cost        float64       `json:"cost,omitempty"`
+	Iterations  int           `json:"iterations"`
+	Duration    time.Duration `json:"duration"`
+}
+
+// ShadowComparison pairs the production and candidate runs for one request.
+type ShadowComparison struct {
+	Input          string    `json:"input"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	StartedAt      time.Time `json:"started_at"`
+	Production     ShadowRun `json:"production"`
+	Candidate      ShadowRun `json:"candidate"`
+}
+
+// ShadowRecorder receives comparisons once both runs have finished.
+type ShadowRecorder interface {
+	RecordShadow(ctx context.Context, comparison ShadowComparison)
+}
+
+// ShadowRecorderFunc adapts a function to ShadowRecorder.
+type ShadowRecorderFunc func(ctx context.Context, comparison ShadowComparison)
+
+// RecordShadow implements ShadowRecorder.
+func (f ShadowRecorderFunc) RecordShadow(ctx context.Context, comparison ShadowComparison) {
+	f(ctx, comparison)
+}
+
+// ShadowConfig configures shadow traffic.
+type ShadowConfig struct {
+	// Candidate is the agent configuration under evaluation.
+	Candidate *Agent
+	Recorder  ShadowRecorder
+	// MaxPerSecond caps how many requests per second are mirrored; requests
+	// over the limit run on production only. Zero mirrors every request.
+	MaxPerSecond float64
+	// Timeout bounds each candidate run. Zero uses the candidate's own timeouts.
+	Timeout time.Duration
+}
+
+// Shadow mirrors requests to a candidate agent while only the production
+// agent's events reach the caller. The candidate runs on a context detached
+// from the caller's cancellation. It reads the conversation history but
+// doesn't store turns, agent state or memories, or publish to event sinks;
+// tools with side effects should check IsShadowRun and skip them.
+type Shadow struct {
+	production *Agent
+	cfg        ShadowConfig
+	limiter    *tokenBucket
+	wg         sync.WaitGroup
+}
+
+// NewShadow creates a shadowing helper in front of production.
+func NewShadow(production *Agent, cfg ShadowConfig) *Shadow {
+	s := &Shadow{production: production, cfg: cfg}
+	if cfg.MaxPerSecond > 0 {
+		s.limiter = newTokenBucket(cfg.MaxPerSecond)
+	}
+	return s
+}
+
+const shadowRunKey contextKey = "agentkit_shadow_run"
+
+// IsShadowRun reports whether ctx belongs to a candidate run started by Shadow.
+func IsShadowRun(ctx context.Context) bool {
+	shadow, _ := ctx.Value(shadowRunKey).(bool)
+	return shadow
+}
+
+// Run executes userMessage on the production agent and returns its events.
+// When the rate limit allows, the same request is mirrored to the candidate
+// and both runs are recorded once they finish.
+func (s *Shadow) Run(ctx context.Context, userMessage string, opts ...RunOption) <-chan Event {
+	if s.cfg.Candidate == nil || (s.limiter != nil && !s.limiter.allow()) {
+		return s.production.Run(ctx, userMessage, opts...)
+	}
+
+	comparison := ShadowComparison{Input: userMessage, StartedAt: time.Now()}
+	comparison.ConversationID, _ = GetConversationID(ctx)
+
+	candidateCtx := context.WithValue(context.WithoutCancel(ctx), shadowRunKey, true)
+	options := newRunOptions(opts)
+	messages := []providers.Message{{Role: providers.RoleUser, Content: userMessage}}
+	if options != nil && options.conversationID != "" {
+		// Give the candidate the history production continues, read before
+		// production appends its turn, without storing the candidate's.
+		comparison.ConversationID = options.conversationID
+		candidateCtx = WithConversation(candidateCtx, options.conversationID)
+		messages = s.history(ctx, options.conversationID, messages)
+		candidateOptions := *options
+		candidateOptions.conversationID = ""
+		options = &candidateOptions
+	}
+	var cancel context.CancelFunc = func() {}
+	if s.cfg.Timeout > 0 {
+		candidateCtx, cancel = context.WithTimeout(candidateCtx, s.cfg.Timeout)
+	}
+
+	var both sync.WaitGroup
+	both.Add(2)
+	s.wg.Add(1)
+	go func() {
+		defer cancel()
+		defer both.Done()
+		comparison.Candidate = summarizeRun(s.cfg.Candidate.runMessages(candidateCtx, messages, options), nil)
+	}()
+
+	out := make(chan Event, s.production.eventBuffer)
+	forward := s.production.forwarder(ctx)
+	go func() {
+		defer close(out)
+		defer both.Done()
+		comparison.Production = summarizeRun(s.production.Run(ctx, userMessage, opts...), func(event Event) {
+			forward.send(out, event)
+		})
+	}()
+
+	go func() {
+		defer s.wg.Done()
+		both.Wait()
+		if s.cfg.Recorder != nil {
+			s.cfg.Recorder.RecordShadow(context.WithoutCancel(ctx), comparison)
+		}
+	}()
+	return out
+}
+
+// Wait blocks until every in-flight candidate run has been recorded.
+func (s *Shadow) Wait() {
+	s.wg.Wait()
+}
+
+// history prepends the stored turns of conversationID to messages. A
+// missing conversation or store leaves messages as they are.
+func (s *Shadow) history(ctx context.Context, conversationID string, messages []providers.Message) []providers.Message {
+	store := s.production.conversationStore
+	if store == nil {
+		return messages
+	}
+	conv, err := store.Load(ctx, conversationID)
+	if err != nil {
+		if !errors.Is(err, ErrConversationNotFound) {
+			s.production.logger.Warn("shadow history load failed", "conversation_id", conversationID, "error", err)
+		}
+		return messages
+	}
+	return append(conversationMessages(conv.Turns), messages...)
+}
+
+// summarizeRun drains events into a ShadowRun, passing them to forward when
+// set.
+func summarizeRun(events <-chan Event, forward func(Event)) ShadowRun {
+	start := time.Now()
+	var run ShadowRun
+	for event := range events {
+		run.observe(event)
+		if forward != nil {
+			forward(event)
+		}
+	}
+	run.Duration = time.Since(start)
+	return run
+}
+
+// observe folds one event into the summary. Numbers may be ints or, for
+// events decoded from JSON, float64s.
+func (run *ShadowRun) observe(event Event) {
+	switch event.Type {
+	case EventTypeFinalOutput:
+		run.Output, _ = event.Data["response"].(string)
+	case EventTypeActionDetected:
+		if description, ok := event.Data["description"].(string); ok {
+			run.Actions = append(run.Actions, description)
+		}
+	case EventTypeAgentComplete:
+		run.TotalTokens = int(toFloat(event.Data["total_tokens"]))
+		run.Iterations = int(toFloat(event.Data["iterations"]))
+	case EventTypeCostUpdate:
+		if cost, ok := event.Data["run_cost"]; ok {
+			run.Cost = toFloat(cost)
+		}
+	case EventTypeError:
+		if run.Error == "" {
+			run.Error, _ = event.Data["error"].(string)
+		}
+	}
+}
+
+// tokenBucket is a minimal rate limiter allowing rate events per second with
+// a burst of one second's worth.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: max(rate, 1), last: time.Now()}
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens = min(max(b.rate, 1), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
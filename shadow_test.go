@@ -0,0 +1,181 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/memory"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func newMockAgent(t *testing.T, provider *mockprovider.Provider) *Agent {
+	t.Helper()
+	agent, err := New(Config{Provider: provider, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return agent
+}
+
+func TestShadow_RecordsBothRunsAndReturnsProduction(t *testing.T) {
+	production := newMockAgent(t, mockprovider.New().WithResponse("production answer", nil))
+	candidate := newMockAgent(t, mockprovider.New().WithResponse("candidate answer", nil))
+
+	var mu sync.Mutex
+	var comparisons []ShadowComparison
+	shadow := NewShadow(production, ShadowConfig{
+		Candidate: candidate,
+		Recorder: ShadowRecorderFunc(func(_ context.Context, c ShadowComparison) {
+			mu.Lock()
+			defer mu.Unlock()
+			comparisons = append(comparisons, c)
+		}),
+	})
+
+	events := collectEvents(shadow.Run(WithConversation(context.Background(), "conv-1"), "hello"), 2*time.Second)
+	for _, e := range events {
+		if e.Type == EventTypeFinalOutput && e.Data["response"] != "production answer" {
+			t.Errorf("caller saw %v, want production output only", e.Data["response"])
+		}
+	}
+	shadow.Wait()
+
+	if len(comparisons) != 1 {
+		t.Fatalf("comparisons = %d, want 1", len(comparisons))
+	}
+	c := comparisons[0]
+	if c.Input != "hello" || c.ConversationID != "conv-1" {
+		t.Errorf("comparison = %+v", c)
+	}
+	if c.Production.Output != "production answer" || c.Candidate.Output != "candidate answer" {
+		t.Errorf("outputs = %q / %q", c.Production.Output, c.Candidate.Output)
+	}
+	if c.Production.TotalTokens != 30 || c.Candidate.Iterations != 1 {
+		t.Errorf("summaries = %+v / %+v", c.Production, c.Candidate)
+	}
+}
+
+func TestShadow_CandidateLeavesConversationUntouched(t *testing.T) {
+	store := NewMemoryConversationStore()
+	store.Save(context.Background(), Conversation{ID: "conv-1", Turns: []ConversationTurn{
+		{Role: string(providers.RoleUser), Content: "I'm Ana."},
+		{Role: string(providers.RoleAssistant), Content: "Hi Ana."},
+	}})
+	production := newMockAgent(t, mockprovider.New().WithResponse("production answer", nil))
+	candidateProvider := &recordingProvider{Provider: mockprovider.New().WithResponse("candidate answer", nil)}
+	candidate, err := New(Config{Provider: candidateProvider, Model: "test-model", ConversationStore: store})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	production.conversationStore = store
+
+	var comparison ShadowComparison
+	shadow := NewShadow(production, ShadowConfig{
+		Candidate: candidate,
+		Recorder:  ShadowRecorderFunc(func(_ context.Context, c ShadowComparison) { comparison = c }),
+	})
+	collectEvents(shadow.Run(context.Background(), "hello", WithRunConversationID("conv-1")), 2*time.Second)
+	shadow.Wait()
+
+	conv, err := store.Load(context.Background(), "conv-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(conv.Turns) != 4 || conv.Turns[3].Content != "production answer" {
+		t.Errorf("stored turns = %+v, want the history, the user turn and the production answer", conv.Turns)
+	}
+	if sent := candidateProvider.requests[0].Messages; len(sent) != 3 || sent[0].Content != "I'm Ana." || sent[2].Content != "hello" {
+		t.Errorf("candidate messages = %+v, want the stored history and the new message", sent)
+	}
+	if comparison.ConversationID != "conv-1" || comparison.Candidate.Output != "candidate answer" {
+		t.Errorf("comparison = %+v", comparison)
+	}
+}
+
+func TestShadow_CandidatePersistsNothing(t *testing.T) {
+	states := NewMemoryAgentStateStore()
+	memories := memory.NewInMemoryStore()
+	published := 0
+	candidate, err := New(Config{
+		Provider: mockprovider.New().
+			WithResponse("", []providers.ToolCall{{ID: "1", Name: "count", Arguments: map[string]any{}}}).
+			WithResponse("Noted.", nil),
+		Model:      "test-model",
+		StateStore: states,
+		Memory:     &MemoryConfig{Store: memories, Policy: memory.NewRulePolicy()},
+		EventSinks: []EventSink{EventSinkFunc(func(context.Context, Event) { published++ })},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	candidate.AddTool(NewTool("count").WithHandler(func(ctx context.Context, _ map[string]any) (any, error) {
+		state, _ := GetAgentState(ctx)
+		return state.Increment("calls", 1), nil
+	}).Build())
+	production := newMockAgent(t, mockprovider.New().WithResponse("production answer", nil))
+
+	shadow := NewShadow(production, ShadowConfig{Candidate: candidate})
+	collectEvents(shadow.Run(WithUser(context.Background(), "alice"), "My timezone is CET."), 2*time.Second)
+	shadow.Wait()
+
+	if state, err := states.Load(context.Background(), candidate.agentName); !errors.Is(err, ErrAgentStateNotFound) {
+		t.Errorf("stored state = %+v, %v", state, err)
+	}
+	if items, _ := memories.Search(context.Background(), "", []memory.Namespace{{Scope: memory.ScopeUser, Owner: "alice"}}, 0); len(items) != 0 {
+		t.Errorf("stored memories = %+v", items)
+	}
+	if published != 0 {
+		t.Errorf("published %d events to the candidate's sinks", published)
+	}
+}
+
+func TestShadow_RateLimitsMirroredTraffic(t *testing.T) {
+	production := newMockAgent(t, mockprovider.New().WithResponse("a", nil).WithResponse("b", nil))
+	candidate := newMockAgent(t, mockprovider.New().WithResponse("c", nil).WithResponse("d", nil))
+
+	recorded := 0
+	var mu sync.Mutex
+	shadow := NewShadow(production, ShadowConfig{
+		Candidate:    candidate,
+		MaxPerSecond: 0.001,
+		Recorder: ShadowRecorderFunc(func(context.Context, ShadowComparison) {
+			mu.Lock()
+			recorded++
+			mu.Unlock()
+		}),
+	})
+
+	collectEvents(shadow.Run(context.Background(), "one"), 2*time.Second)
+	collectEvents(shadow.Run(context.Background(), "two"), 2*time.Second)
+	shadow.Wait()
+
+	if recorded != 1 {
+		t.Errorf("recorded = %d, want only the first request mirrored", recorded)
+	}
+}
+
+func TestIsShadowRun(t *testing.T) {
+	production := newMockAgent(t, mockprovider.New().WithResponse("", nil))
+	candidate := newMockAgent(t, mockprovider.New().WithResponse("", []providers.ToolCall{{ID: "1", Name: "send_email"}}).WithResponse("done", nil))
+
+	sent := make(chan bool, 1)
+	candidate.AddTool(NewTool("send_email").
+		WithDescription("Send an email").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			sent <- !IsShadowRun(ctx)
+			return "ok", nil
+		}).
+		Build())
+
+	shadow := NewShadow(production, ShadowConfig{Candidate: candidate})
+	collectEvents(shadow.Run(context.Background(), "email bob"), 2*time.Second)
+	shadow.Wait()
+
+	if <-sent {
+		t.Error("candidate tool did not see IsShadowRun")
+	}
+}
@@ -0,0 +1,200 @@
+// Package nats publishes agentkit events to a NATS server using the core
+// NATS text protocol, without a client library dependency.
+//
+// Publisher implements agentkit.BrokerPublisher:
+//
+//	pub, err := nats.Dial(ctx, "nats://localhost:4222")
+//	sink := agentkit.NewBrokerSink(pub, agentkit.BrokerSinkConfig{Topic: "agentkit.events"})
+//
+// Each message goes to "<topic>.<key>", where key is the conversation ID, so
+// subscribers (or a JetStream stream on "agentkit.events.>") can consume one
+// conversation in order.
+package nats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when publishing on a closed Publisher.
+var ErrClosed = errors.New("nats: publisher closed")
+
+// Options configure the connection.
+type Options struct {
+	Name     string
+	User     string
+	Password string
+	Token    string
+	// DialTimeout bounds connecting (default 5s).
+	DialTimeout time.Duration
+}
+
+// Publisher is a minimal NATS publisher. It reconnects once on write failure
+// and is safe for concurrent use.
+type Publisher struct {
+	addr string
+	opts Options
+
+	mu     sync.Mutex
+	conn   net.Conn
+	writer *bufio.Writer
+	closed bool
+}
+
+// Dial connects to a NATS server given as "nats://host:port" or "host:port".
+func Dial(ctx context.Context, address string, opts ...Options) (*Publisher, error) {
+	p := &Publisher{addr: address}
+	if len(opts) > 0 {
+		p.opts = opts[0]
+	}
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		p.addr = u.Host
+		if u.User != nil {
+			if password, ok := u.User.Password(); ok {
+				p.opts.User, p.opts.Password = u.User.Username(), password
+			} else {
+				p.opts.Token = u.User.Username()
+			}
+		}
+	}
+	if p.opts.DialTimeout <= 0 {
+		p.opts.DialTimeout = 5 * time.Second
+	}
+	if p.opts.Name == "" {
+		p.opts.Name = "agentkit"
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.connect(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+type connectOptions struct {
+	Verbose   bool   `json:"verbose"`
+	Pedantic  bool   `json:"pedantic"`
+	Name      string `json:"name"`
+	Lang      string `json:"lang"`
+	User      string `json:"user,omitempty"`
+	Pass      string `json:"pass,omitempty"`
+	AuthToken string `json:"auth_token,omitempty"`
+}
+
+// connect dials, reads the server INFO and sends CONNECT. Callers hold p.mu.
+func (p *Publisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.opts.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("nats: dial %s: %w", p.addr, err)
+	}
+	reader := bufio.NewReader(conn)
+	_ = conn.SetReadDeadline(time.Now().Add(p.opts.DialTimeout))
+	info, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("nats: unexpected greeting %q: %v", strings.TrimSpace(info), err)
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	connect, _ := json.Marshal(connectOptions{
+		Name:      p.opts.Name,
+		Lang:      "go",
+		User:      p.opts.User,
+		Pass:      p.opts.Password,
+		AuthToken: p.opts.Token,
+	})
+	writer := bufio.NewWriter(conn)
+	fmt.Fprintf(writer, "CONNECT %s\r\n", connect)
+	if err := writer.Flush(); err != nil {
+		conn.Close()
+		return fmt.Errorf("nats: connect: %w", err)
+	}
+
+	p.conn, p.writer = conn, writer
+	go p.readLoop(conn, reader)
+	return nil
+}
+
+// readLoop answers server PINGs so the connection stays alive.
+func (p *Publisher) readLoop(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			p.mu.Lock()
+			if p.conn == conn {
+				p.writer.WriteString("PONG\r\n")
+				p.writer.Flush()
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Publish implements agentkit.BrokerPublisher. A non-empty key is appended
+// to topic as a subject token.
+func (p *Publisher) Publish(ctx context.Context, topic string, key, value []byte) error {
+	subject := Subject(topic, string(key))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	err := p.write(subject, value)
+	if err == nil {
+		return nil
+	}
+	// One reconnect attempt for dropped connections.
+	p.conn.Close()
+	if cerr := p.connect(ctx); cerr != nil {
+		return fmt.Errorf("nats: publish to %s: %w", subject, err)
+	}
+	return p.write(subject, value)
+}
+
+func (p *Publisher) write(subject string, value []byte) error {
+	fmt.Fprintf(p.writer, "PUB %s %d\r\n", subject, len(value))
+	p.writer.Write(value)
+	p.writer.WriteString("\r\n")
+	return p.writer.Flush()
+}
+
+// Close closes the connection.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	return p.conn.Close()
+}
+
+// Subject joins topic and key into a NATS subject, replacing characters
+// that are not valid inside a subject token.
+func Subject(topic, key string) string {
+	if key == "" {
+		return topic
+	}
+	token := strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, key)
+	return topic + "." + token
+}
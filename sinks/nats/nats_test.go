@@ -0,0 +1,77 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer accepts one connection, greets it and reports every line it receives.
+func fakeServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	lines := make(chan string, 16)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- strings.TrimRight(line, "\r\n")
+		}
+	}()
+	return ln.Addr().String(), lines
+}
+
+func next(t *testing.T, lines <-chan string) string {
+	t.Helper()
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the server to receive a line")
+		return ""
+	}
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	addr, lines := fakeServer(t)
+	pub, err := Dial(context.Background(), "nats://secret@"+addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer pub.Close()
+
+	if connect := next(t, lines); !strings.HasPrefix(connect, "CONNECT ") || !strings.Contains(connect, `"auth_token":"secret"`) {
+		t.Errorf("CONNECT = %q", connect)
+	}
+
+	if err := pub.Publish(context.Background(), "agentkit.events", []byte("conv.1"), []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got := next(t, lines); got != "PUB agentkit.events.conv_1 7" {
+		t.Errorf("PUB line = %q", got)
+	}
+	if got := next(t, lines); got != `{"a":1}` {
+		t.Errorf("payload = %q", got)
+	}
+
+	pub.Close()
+	if err := pub.Publish(context.Background(), "x", nil, nil); err != ErrClosed {
+		t.Errorf("Publish() after Close error = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build sqlite
+
+package sqlite
+
+import _ "modernc.org/sqlite" // Registers the "sqlite" driver for TestStore_RealSQLite
@@ -0,0 +1,350 @@
+// Package sqlite implements agentkit.ConversationStore on SQLite, so CLI
+// tools and desktop apps can persist conversations in a single file without
+// external infrastructure.
+//
+// The caller supplies a *sql.DB opened with any SQLite driver
+// (modernc.org/sqlite, github.com/mattn/go-sqlite3). Open it with Open, or
+// wrap the driver's connector with Connector, so every pooled connection
+// gets the store's per-connection pragmas. Set a busy timeout in the DSN,
+// e.g. "file:agent.db?_pragma=busy_timeout(5000)" for modernc.org/sqlite,
+// so concurrent writers wait instead of failing.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+const (
+	conversationsTable = "agentkit_conversations"
+	turnsTable         = "agentkit_conversation_turns"
+)
+
+// connectionPragmas apply to a single connection, so Connector runs them on
+// every connection the pool opens. synchronous=NORMAL is safe in WAL mode
+// and avoids an fsync per commit.
+var connectionPragmas = []string{
+	`PRAGMA synchronous=NORMAL`,
+}
+
+// Open opens dsn with the registered SQLite driver driverName, running the
+// store's per-connection pragmas on every connection.
+func Open(driverName, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: open: %w", err)
+	}
+	drv := db.Driver()
+	db.Close()
+
+	var base driver.Connector = dsnConnector{dsn: dsn, driver: drv}
+	if opener, ok := drv.(driver.DriverContext); ok {
+		if base, err = opener.OpenConnector(dsn); err != nil {
+			return nil, fmt.Errorf("sqlite: open: %w", err)
+		}
+	}
+	return sql.OpenDB(Connector(base)), nil
+}
+
+// Connector wraps base so every connection it opens runs the store's
+// per-connection pragmas. Use it with sql.OpenDB when the driver's connector
+// is built directly.
+func Connector(base driver.Connector) driver.Connector {
+	return pragmaConnector{base}
+}
+
+type pragmaConnector struct {
+	driver.Connector
+}
+
+func (c pragmaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pragma := range connectionPragmas {
+		if err := execConn(ctx, conn, pragma); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("sqlite: %s: %w", pragma, err)
+		}
+	}
+	return conn, nil
+}
+
+// execConn runs a statement without arguments on a driver connection.
+func execConn(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil) // Drivers without ExecerContext only have Exec
+	return err
+}
+
+// dsnConnector adapts drivers that don't implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+// Store is a ConversationStore backed by SQLite. Each turn is its own row,
+// so Append does not rewrite the conversation.
+type Store struct {
+	DB *sql.DB
+}
+
+var _ agentkit.ConversationStore = (*Store)(nil)
+
+// New creates a store on db. Call Migrate before first use.
+func New(db *sql.DB) *Store {
+	return &Store{DB: db}
+}
+
+// Migrate switches the database to WAL mode, so readers do not block the
+// writer, and creates the tables if they do not exist. WAL mode is stored in
+// the database file; per-connection pragmas are set by Open and Connector.
+func (s *Store) Migrate(ctx context.Context) error {
+	statements := []string{
+		`PRAGMA journal_mode=WAL`,
+		`CREATE TABLE IF NOT EXISTS ` + conversationsTable + ` (
+	id TEXT PRIMARY KEY,
+	agent_id TEXT NOT NULL DEFAULT '',
+	metadata TEXT NOT NULL DEFAULT '{}',
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL
+)`,
+		`CREATE TABLE IF NOT EXISTS ` + turnsTable + ` (
+	conversation_id TEXT NOT NULL,
+	seq INTEGER NOT NULL,
+	turn TEXT NOT NULL,
+	PRIMARY KEY (conversation_id, seq)
+)`,
+		`CREATE INDEX IF NOT EXISTS ` + conversationsTable + `_updated_idx ON ` + conversationsTable + ` (updated_at)`,
+	}
+	for _, stmt := range statements {
+		if _, err := s.DB.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("sqlite: migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// Save persists a complete conversation, replacing any stored turns.
+func (s *Store) Save(ctx context.Context, conv agentkit.Conversation) error {
+	metadata, err := json.Marshal(conv.Metadata)
+	if err != nil {
+		return fmt.Errorf("sqlite: encode metadata for %s: %w", conv.ID, err)
+	}
+	now := time.Now()
+	if conv.CreatedAt.IsZero() {
+		conv.CreatedAt = now
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO `+conversationsTable+` (id, agent_id, metadata, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET agent_id = excluded.agent_id, metadata = excluded.metadata,
+	created_at = excluded.created_at, updated_at = excluded.updated_at`,
+			conv.ID, conv.AgentID, string(metadata), conv.CreatedAt.UnixNano(), now.UnixNano())
+		if err != nil {
+			return fmt.Errorf("sqlite: save %s: %w", conv.ID, err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+turnsTable+` WHERE conversation_id = ?`, conv.ID); err != nil {
+			return fmt.Errorf("sqlite: save %s: %w", conv.ID, err)
+		}
+		for i, turn := range conv.Turns {
+			if err := insertTurn(ctx, tx, conv.ID, int64(i+1), turn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Load retrieves a conversation by ID. It returns
+// agentkit.ErrConversationNotFound when there is none.
+func (s *Store) Load(ctx context.Context, id string) (agentkit.Conversation, error) {
+	conv := agentkit.Conversation{ID: id}
+	var metadata string
+	var createdAt, updatedAt int64
+	err := s.DB.QueryRowContext(ctx, `SELECT agent_id, metadata, created_at, updated_at FROM `+conversationsTable+` WHERE id = ?`, id).
+		Scan(&conv.AgentID, &metadata, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return agentkit.Conversation{}, agentkit.ErrConversationNotFound
+	}
+	if err != nil {
+		return agentkit.Conversation{}, fmt.Errorf("sqlite: load %s: %w", id, err)
+	}
+	if err := json.Unmarshal([]byte(metadata), &conv.Metadata); err != nil {
+		return agentkit.Conversation{}, fmt.Errorf("sqlite: decode metadata for %s: %w", id, err)
+	}
+	conv.CreatedAt = time.Unix(0, createdAt)
+	conv.UpdatedAt = time.Unix(0, updatedAt)
+
+	rows, err := s.DB.QueryContext(ctx, `SELECT turn FROM `+turnsTable+` WHERE conversation_id = ? ORDER BY seq`, id)
+	if err != nil {
+		return agentkit.Conversation{}, fmt.Errorf("sqlite: load turns of %s: %w", id, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return agentkit.Conversation{}, fmt.Errorf("sqlite: load turns of %s: %w", id, err)
+		}
+		var turn agentkit.ConversationTurn
+		if err := json.Unmarshal([]byte(data), &turn); err != nil {
+			return agentkit.Conversation{}, fmt.Errorf("sqlite: decode turn of %s: %w", id, err)
+		}
+		conv.Turns = append(conv.Turns, turn)
+	}
+	if err := rows.Err(); err != nil {
+		return agentkit.Conversation{}, fmt.Errorf("sqlite: load turns of %s: %w", id, err)
+	}
+	return conv, nil
+}
+
+// Append adds a turn to an existing conversation. It returns
+// agentkit.ErrConversationNotFound when there is none.
+func (s *Store) Append(ctx context.Context, id string, turn agentkit.ConversationTurn) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `UPDATE `+conversationsTable+` SET updated_at = ? WHERE id = ?`, time.Now().UnixNano(), id)
+		if err != nil {
+			return fmt.Errorf("sqlite: append to %s: %w", id, err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return agentkit.ErrConversationNotFound
+		}
+		var seq int64
+		if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) + 1 FROM `+turnsTable+` WHERE conversation_id = ?`, id).Scan(&seq); err != nil {
+			return fmt.Errorf("sqlite: append to %s: %w", id, err)
+		}
+		return insertTurn(ctx, tx, id, seq, turn)
+	})
+}
+
+// Delete removes a conversation. It returns agentkit.ErrConversationNotFound
+// when there is none.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+turnsTable+` WHERE conversation_id = ?`, id); err != nil {
+			return fmt.Errorf("sqlite: delete %s: %w", id, err)
+		}
+		result, err := tx.ExecContext(ctx, `DELETE FROM `+conversationsTable+` WHERE id = ?`, id)
+		if err != nil {
+			return fmt.Errorf("sqlite: delete %s: %w", id, err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return agentkit.ErrConversationNotFound
+		}
+		return nil
+	})
+}
+
+// CompactOptions selects what Compact removes. Zero values keep everything.
+type CompactOptions struct {
+	MaxTurns int           // Keep only the newest MaxTurns turns of each conversation
+	MaxAge   time.Duration // Delete conversations not updated for longer than MaxAge
+	Vacuum   bool          // Rebuild the database file to return freed space to the OS
+}
+
+// CompactResult reports what Compact removed.
+type CompactResult struct {
+	ConversationsDeleted int64
+	TurnsDeleted         int64
+}
+
+// Compact trims long conversations and deletes stale ones, then
+// checkpoints the WAL so the log file does not keep growing. With
+// opts.Vacuum it also rebuilds the database file, which needs up to twice
+// its size in free disk space and blocks writers while it runs.
+func (s *Store) Compact(ctx context.Context, opts CompactOptions) (CompactResult, error) {
+	var result CompactResult
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if opts.MaxAge > 0 {
+			cutoff := time.Now().Add(-opts.MaxAge).UnixNano()
+			stale := `SELECT id FROM ` + conversationsTable + ` WHERE updated_at < ?`
+			deleted, err := tx.ExecContext(ctx, `DELETE FROM `+turnsTable+` WHERE conversation_id IN (`+stale+`)`, cutoff)
+			if err != nil {
+				return fmt.Errorf("sqlite: compact: %w", err)
+			}
+			result.TurnsDeleted += rowsAffected(deleted)
+			deleted, err = tx.ExecContext(ctx, `DELETE FROM `+conversationsTable+` WHERE updated_at < ?`, cutoff)
+			if err != nil {
+				return fmt.Errorf("sqlite: compact: %w", err)
+			}
+			result.ConversationsDeleted = rowsAffected(deleted)
+		}
+		if opts.MaxTurns > 0 {
+			deleted, err := tx.ExecContext(ctx, `DELETE FROM `+turnsTable+` AS t WHERE seq <= (
+	SELECT MAX(seq) FROM `+turnsTable+` WHERE conversation_id = t.conversation_id
+) - ?`, opts.MaxTurns)
+			if err != nil {
+				return fmt.Errorf("sqlite: compact: %w", err)
+			}
+			result.TurnsDeleted += rowsAffected(deleted)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if _, err := s.DB.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return result, fmt.Errorf("sqlite: checkpoint: %w", err)
+	}
+	if opts.Vacuum {
+		if _, err := s.DB.ExecContext(ctx, `VACUUM`); err != nil {
+			return result, fmt.Errorf("sqlite: vacuum: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func insertTurn(ctx context.Context, tx *sql.Tx, id string, seq int64, turn agentkit.ConversationTurn) error {
+	data, err := json.Marshal(turn)
+	if err != nil {
+		return fmt.Errorf("sqlite: encode turn of %s: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO `+turnsTable+` (conversation_id, seq, turn) VALUES (?, ?, ?)`, id, seq, string(data)); err != nil {
+		return fmt.Errorf("sqlite: insert turn of %s: %w", id, err)
+	}
+	return nil
+}
+
+func rowsAffected(result sql.Result) int64 {
+	n, _ := result.RowsAffected()
+	return n
+}
+
+// inTx runs fn in a transaction, committing when it returns nil.
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite: begin: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite: commit: %w", err)
+	}
+	return nil
+}
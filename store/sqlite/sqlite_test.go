@@ -0,0 +1,444 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// fakeDB emulates the statements Store issues, so the store's logic can be
+// tested without a SQLite driver. Unknown statements fail the call.
+type fakeDB struct {
+	mu      sync.Mutex
+	convs   map[string]fakeConv
+	turns   map[string]map[int64]string // Turn JSON by conversation and seq
+	pragmas map[int][]string            // Pragmas run, by connection
+	conns   int
+}
+
+type fakeConv struct {
+	agentID, metadata    string
+	createdAt, updatedAt int64
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{convs: map[string]fakeConv{}, turns: map[string]map[int64]string{}, pragmas: map[int][]string{}}
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.conns++
+	return &fakeConn{db: db, id: db.conns}, nil
+}
+
+func (db *fakeDB) Driver() driver.Driver { return nil }
+
+// snapshot copies the tables, for rolling back a transaction.
+func (db *fakeDB) snapshot() (map[string]fakeConv, map[string]map[int64]string) {
+	turns := make(map[string]map[int64]string, len(db.turns))
+	for id, seqs := range db.turns {
+		turns[id] = maps.Clone(seqs)
+	}
+	return maps.Clone(db.convs), turns
+}
+
+type fakeConn struct {
+	db *fakeDB
+	id int
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("fake: prepare unsupported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	convs, turns := c.db.snapshot()
+	return &fakeTx{db: c.db, convs: convs, turns: turns}, nil
+}
+
+type fakeTx struct {
+	db    *fakeDB
+	convs map[string]fakeConv
+	turns map[string]map[int64]string
+}
+
+func (tx *fakeTx) Commit() error { return nil }
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	tx.db.convs, tx.db.turns = tx.convs, tx.turns
+	return nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	db, query := c.db, strings.Join(strings.Fields(query), " ")
+	var n int64
+	switch {
+	case strings.HasPrefix(query, "PRAGMA "):
+		db.pragmas[c.id] = append(db.pragmas[c.id], query)
+	case strings.HasPrefix(query, "CREATE "), query == "VACUUM":
+	case strings.HasPrefix(query, "INSERT INTO "+conversationsTable):
+		db.convs[str(args[0])] = fakeConv{agentID: str(args[1]), metadata: str(args[2]), createdAt: num(args[3]), updatedAt: num(args[4])}
+		n = 1
+	case strings.HasPrefix(query, "INSERT INTO "+turnsTable):
+		id, seq := str(args[0]), num(args[1])
+		if db.turns[id] == nil {
+			db.turns[id] = map[int64]string{}
+		}
+		if _, ok := db.turns[id][seq]; ok {
+			return nil, errors.New("fake: UNIQUE constraint failed")
+		}
+		db.turns[id][seq] = str(args[2])
+		n = 1
+	case query == "UPDATE "+conversationsTable+" SET updated_at = ? WHERE id = ?":
+		if conv, ok := db.convs[str(args[1])]; ok {
+			conv.updatedAt = num(args[0])
+			db.convs[str(args[1])] = conv
+			n = 1
+		}
+	case query == "DELETE FROM "+turnsTable+" WHERE conversation_id = ?":
+		n = int64(len(db.turns[str(args[0])]))
+		delete(db.turns, str(args[0]))
+	case strings.HasPrefix(query, "DELETE FROM "+turnsTable+" WHERE conversation_id IN (SELECT id FROM "+conversationsTable+" WHERE updated_at < ?"):
+		for id, conv := range db.convs {
+			if conv.updatedAt < num(args[0]) {
+				n += int64(len(db.turns[id]))
+				delete(db.turns, id)
+			}
+		}
+	case query == "DELETE FROM "+conversationsTable+" WHERE updated_at < ?":
+		for id, conv := range db.convs {
+			if conv.updatedAt < num(args[0]) {
+				delete(db.convs, id)
+				n++
+			}
+		}
+	case query == "DELETE FROM "+conversationsTable+" WHERE id = ?":
+		if _, ok := db.convs[str(args[0])]; ok {
+			delete(db.convs, str(args[0]))
+			n = 1
+		}
+	case strings.HasPrefix(query, "DELETE FROM "+turnsTable+" AS t WHERE seq <= ("):
+		for _, seqs := range db.turns {
+			newest := slices.Max(slices.Collect(maps.Keys(seqs)))
+			for seq := range seqs {
+				if seq <= newest-num(args[0]) {
+					delete(seqs, seq)
+					n++
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("fake: unsupported statement %q", query)
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	db, query := c.db, strings.Join(strings.Fields(query), " ")
+	switch {
+	case strings.HasPrefix(query, "SELECT agent_id, metadata, created_at, updated_at FROM "+conversationsTable):
+		rows := &fakeRows{columns: []string{"agent_id", "metadata", "created_at", "updated_at"}}
+		if conv, ok := db.convs[str(args[0])]; ok {
+			rows.values = append(rows.values, []driver.Value{conv.agentID, conv.metadata, conv.createdAt, conv.updatedAt})
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT turn FROM "+turnsTable):
+		rows := &fakeRows{columns: []string{"turn"}}
+		seqs := db.turns[str(args[0])]
+		for _, seq := range slices.Sorted(maps.Keys(seqs)) {
+			rows.values = append(rows.values, []driver.Value{seqs[seq]})
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT COALESCE(MAX(seq), 0) + 1 FROM "+turnsTable):
+		var next int64 = 1
+		for seq := range db.turns[str(args[0])] {
+			next = max(next, seq+1)
+		}
+		return &fakeRows{columns: []string{"seq"}, values: [][]driver.Value{{next}}}, nil
+	}
+	return nil, fmt.Errorf("fake: unsupported query %q", query)
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+func str(arg driver.NamedValue) string {
+	s, _ := arg.Value.(string)
+	return s
+}
+
+func num(arg driver.NamedValue) int64 {
+	n, _ := arg.Value.(int64)
+	return n
+}
+
+func newTestStore(t *testing.T) (*Store, *fakeDB) {
+	t.Helper()
+	fake := newFakeDB()
+	db := sql.OpenDB(Connector(fake))
+	t.Cleanup(func() { db.Close() })
+	store := New(db)
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return store, fake
+}
+
+func turn(role, content string) agentkit.ConversationTurn {
+	return agentkit.ConversationTurn{Role: role, Content: content, Timestamp: time.Unix(1700000000, 0).UTC()}
+}
+
+func TestStore_SaveLoadAppendDelete(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.Load(ctx, "conv-1"); !errors.Is(err, agentkit.ErrConversationNotFound) {
+		t.Errorf("Load(missing) error = %v, want ErrConversationNotFound", err)
+	}
+	if err := store.Append(ctx, "conv-1", turn("user", "hi")); !errors.Is(err, agentkit.ErrConversationNotFound) {
+		t.Errorf("Append(missing) error = %v, want ErrConversationNotFound", err)
+	}
+
+	conv := agentkit.Conversation{
+		ID:       "conv-1",
+		AgentID:  "support",
+		Metadata: map[string]any{"tenant": "acme"},
+		Turns:    []agentkit.ConversationTurn{turn("user", "Where is my order?"), turn("assistant", "Checking.")},
+	}
+	if err := store.Save(ctx, conv); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := store.Append(ctx, "conv-1", turn("assistant", "It shipped.")); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	loaded, err := store.Load(ctx, "conv-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AgentID != "support" || loaded.Metadata["tenant"] != "acme" || loaded.CreatedAt.IsZero() || loaded.UpdatedAt.IsZero() {
+		t.Errorf("loaded = %+v", loaded)
+	}
+	var contents []string
+	for _, turn := range loaded.Turns {
+		contents = append(contents, turn.Content)
+	}
+	if want := []string{"Where is my order?", "Checking.", "It shipped."}; !slices.Equal(contents, want) {
+		t.Errorf("turns = %q, want %q", contents, want)
+	}
+
+	// Save replaces the stored turns.
+	conv.Turns = conv.Turns[:1]
+	if err := store.Save(ctx, conv); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if loaded, _ := store.Load(ctx, "conv-1"); len(loaded.Turns) != 1 {
+		t.Errorf("turns after re-save = %d, want 1", len(loaded.Turns))
+	}
+
+	if err := store.Delete(ctx, "conv-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Load(ctx, "conv-1"); !errors.Is(err, agentkit.ErrConversationNotFound) {
+		t.Errorf("Load(deleted) error = %v", err)
+	}
+	if err := store.Delete(ctx, "conv-1"); !errors.Is(err, agentkit.ErrConversationNotFound) {
+		t.Errorf("Delete(missing) error = %v, want ErrConversationNotFound", err)
+	}
+}
+
+func TestStore_Compact(t *testing.T) {
+	store, fake := newTestStore(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"fresh", "stale"} {
+		conv := agentkit.Conversation{ID: id}
+		for i := range 5 {
+			conv.Turns = append(conv.Turns, turn("user", fmt.Sprint(i)))
+		}
+		if err := store.Save(ctx, conv); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	fake.mu.Lock()
+	stale := fake.convs["stale"]
+	stale.updatedAt = time.Now().Add(-48 * time.Hour).UnixNano()
+	fake.convs["stale"] = stale
+	fake.mu.Unlock()
+
+	result, err := store.Compact(ctx, CompactOptions{MaxTurns: 2, MaxAge: 24 * time.Hour, Vacuum: true})
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if result.ConversationsDeleted != 1 || result.TurnsDeleted != 8 {
+		t.Errorf("result = %+v, want 1 conversation and 5+3 turns deleted", result)
+	}
+	if _, err := store.Load(ctx, "stale"); !errors.Is(err, agentkit.ErrConversationNotFound) {
+		t.Errorf("Load(stale) error = %v", err)
+	}
+	fresh, err := store.Load(ctx, "fresh")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(fresh.Turns) != 2 || fresh.Turns[0].Content != "3" || fresh.Turns[1].Content != "4" {
+		t.Errorf("fresh turns = %+v, want the newest two", fresh.Turns)
+	}
+	if err := store.Append(ctx, "fresh", turn("user", "5")); err != nil {
+		t.Errorf("Append() after compaction error = %v", err)
+	}
+}
+
+func TestConnector_AppliesPragmasToEveryConnection(t *testing.T) {
+	fake := newFakeDB()
+	db := sql.OpenDB(Connector(fake))
+	defer db.Close()
+	ctx := context.Background()
+
+	// Hold two connections at once so the pool opens a second one.
+	first, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	defer first.Close()
+	second, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("Conn() error = %v", err)
+	}
+	defer second.Close()
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.conns != 2 {
+		t.Fatalf("connections = %d, want 2", fake.conns)
+	}
+	for id := 1; id <= 2; id++ {
+		if !slices.Equal(fake.pragmas[id], connectionPragmas) {
+			t.Errorf("connection %d pragmas = %q, want %q", id, fake.pragmas[id], connectionPragmas)
+		}
+	}
+}
+
+// TestStore_RealSQLite runs the store against a real SQLite driver, which
+// the fake above only emulates. Run it with go test -tags sqlite.
+func TestStore_RealSQLite(t *testing.T) {
+	var driverName string
+	for _, name := range sql.Drivers() {
+		if name == "sqlite" || name == "sqlite3" {
+			driverName = name
+		}
+	}
+	if driverName == "" {
+		t.Skip("no SQLite driver registered; run with -tags sqlite")
+	}
+	db, err := Open(driverName, filepath.Join(t.TempDir(), "agent.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	store := New(db)
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+
+	conv := agentkit.Conversation{ID: "conv-1", AgentID: "agent", Metadata: map[string]any{"tenant": "acme"}}
+	for i := range 5 {
+		conv.Turns = append(conv.Turns, turn("user", fmt.Sprint(i)))
+	}
+	if err := store.Save(ctx, conv); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	conv.AgentID = "other"
+	if err := store.Save(ctx, conv); err != nil {
+		t.Fatalf("Save() over an existing conversation error = %v", err)
+	}
+	if err := store.Append(ctx, "conv-1", turn("assistant", "5")); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	loaded, err := store.Load(ctx, "conv-1")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AgentID != "other" || loaded.Metadata["tenant"] != "acme" || len(loaded.Turns) != 6 || loaded.Turns[5].Content != "5" {
+		t.Errorf("loaded = %+v", loaded)
+	}
+
+	result, err := store.Compact(ctx, CompactOptions{MaxTurns: 2, Vacuum: true})
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if result.TurnsDeleted != 4 {
+		t.Errorf("result = %+v, want 4 turns deleted", result)
+	}
+	if loaded, _ := store.Load(ctx, "conv-1"); len(loaded.Turns) != 2 || loaded.Turns[0].Content != "4" {
+		t.Errorf("compacted turns = %+v, want the newest two", loaded.Turns)
+	}
+
+	// Hold two connections at once so the pool opens a second one.
+	for range 2 {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Conn() error = %v", err)
+		}
+		defer conn.Close()
+		var journal string
+		var synchronous int
+		if err := conn.QueryRowContext(ctx, `PRAGMA journal_mode`).Scan(&journal); err != nil || journal != "wal" {
+			t.Errorf("journal_mode = %q, %v, want wal", journal, err)
+		}
+		if err := conn.QueryRowContext(ctx, `PRAGMA synchronous`).Scan(&synchronous); err != nil || synchronous != 1 {
+			t.Errorf("synchronous = %d, %v, want 1 (NORMAL)", synchronous, err)
+		}
+	}
+
+	if err := store.Delete(ctx, "conv-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Load(ctx, "conv-1"); !errors.Is(err, agentkit.ErrConversationNotFound) {
+		t.Errorf("Load() after Delete error = %v, want ErrConversationNotFound", err)
+	}
+}
@@ -0,0 +1,151 @@
+package agentkit
+
+import (
+	"strings"
+	"time"
+)
+
+// StreamShapingConfig controls how fine-grained events are presented to consumers.
+type StreamShapingConfig struct {
+	// CoalesceThinking merges consecutive thinking chunks into thinking.segment events.
+	// A segment is flushed when a different event arrives (e.g. an action), when the
+	// producing agent changes, or when FlushInterval elapses.
+	CoalesceThinking bool
+
+	// FlushInterval bounds how long a segment is held before being emitted.
+	// Zero means segments are only flushed at event boundaries.
+	FlushInterval time.Duration
+
+	// ThinkingTypes lists the chunk event types treated as thinking.
+	// Defaults to thinking_chunk and reasoning_chunk.
+	ThinkingTypes []EventType
+}
+
+// DefaultThinkingTypes are the chunk event types coalesced by default.
+var DefaultThinkingTypes = []EventType{EventTypeThinkingChunk, EventTypeReasoningChunk}
+
+// ShapeEvents applies the stream shaping config to an event stream.
+func ShapeEvents(input <-chan Event, cfg StreamShapingConfig) <-chan Event {
+	return shapeEvents(input, cfg, sendBlocking)
+}
+
+// shapeEvents is ShapeEvents delivering events through send.
+func shapeEvents(input <-chan Event, cfg StreamShapingConfig, send eventSender) <-chan Event {
+	if !cfg.CoalesceThinking {
+		return input
+	}
+	return coalesceThinking(input, cfg.FlushInterval, cfg.ThinkingTypes, send)
+}
+
+// CoalesceThinking merges runs of thinking chunks into single thinking.segment events.
+// If no types are given, DefaultThinkingTypes are used.
+func CoalesceThinking(input <-chan Event, flushInterval time.Duration, types ...EventType) <-chan Event {
+	return coalesceThinking(input, flushInterval, types, sendBlocking)
+}
+
+// eventSender delivers an event on a channel.
+type eventSender func(out chan<- Event, event Event)
+
+// sendBlocking waits for the consumer to take the event.
+func sendBlocking(out chan<- Event, event Event) {
+	out <- event
+}
+
+func coalesceThinking(input <-chan Event, flushInterval time.Duration, types []EventType, send eventSender) <-chan Event {
+	if len(types) == 0 {
+		types = DefaultThinkingTypes
+	}
+	thinking := make(map[EventType]struct{}, len(types))
+	for _, typ := range types {
+		thinking[typ] = struct{}{}
+	}
+
+	out := make(chan Event)
+
+	go func() {
+		defer close(out)
+
+		var segment *thinkingSegment
+		var timer *time.Timer
+		var timeout <-chan time.Time
+
+		flush := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+			if segment != nil {
+				send(out, segment.event())
+				segment = nil
+			}
+		}
+
+		for {
+			select {
+			case event, ok := <-input:
+				if !ok {
+					flush()
+					return
+				}
+				if _, isThinking := thinking[event.Type]; !isThinking {
+					flush()
+					send(out, event)
+					continue
+				}
+				if segment != nil && !segment.accepts(event) {
+					flush()
+				}
+				if segment == nil {
+					segment = newThinkingSegment(event)
+					if flushInterval > 0 {
+						timer = time.NewTimer(flushInterval)
+						timeout = timer.C
+					}
+				}
+				segment.add(event)
+			case <-timeout:
+				timer, timeout = nil, nil
+				flush()
+			}
+		}
+	}()
+
+	return out
+}
+
+// thinkingSegment accumulates consecutive thinking chunks from one source.
+type thinkingSegment struct {
+	first   Event
+	content strings.Builder
+	chunks  int
+}
+
+func newThinkingSegment(first Event) *thinkingSegment {
+	return &thinkingSegment{first: first}
+}
+
+func (s *thinkingSegment) accepts(event Event) bool {
+	return event.Type == s.first.Type &&
+		event.Data["agent_name"] == s.first.Data["agent_name"] &&
+		event.Data["agent_path"] == s.first.Data["agent_path"]
+}
+
+func (s *thinkingSegment) add(event Event) {
+	if chunk, ok := event.Data["chunk"].(string); ok {
+		s.content.WriteString(chunk)
+	}
+	s.chunks++
+}
+
+func (s *thinkingSegment) event() Event {
+	segment := ThinkingSegment(s.content.String(), s.first.Type, s.chunks)
+	segment.Timestamp = s.first.Timestamp
+	segment.TraceID = s.first.TraceID
+	segment.SpanID = s.first.SpanID
+	for _, key := range []string{"agent_name", "agent_path", "agent_depth", "parent_call_id", "iteration"} {
+		if value, ok := s.first.Data[key]; ok {
+			segment.Data[key] = value
+		}
+	}
+	return segment
+}
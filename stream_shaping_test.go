@@ -0,0 +1,67 @@
+package agentkit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCoalesceThinking_FlushesAtActions(t *testing.T) {
+	input := make(chan Event, 8)
+	input <- ThinkingChunk("Let me ")
+	input <- ThinkingChunk("check.")
+	input <- ActionDetected("Searching...", "call_1")
+	input <- ReasoningChunk("Found ")
+	input <- ReasoningChunk("it.")
+	input <- ResponseChunk("Answer")
+	close(input)
+
+	events := collectEvents(CoalesceThinking(input, 0), time.Second)
+
+	wantTypes := []EventType{EventTypeThinkingSegment, EventTypeActionDetected, EventTypeThinkingSegment, EventTypeResponseChunk}
+	if len(events) != len(wantTypes) {
+		t.Fatalf("expected %d events, got %d", len(wantTypes), len(events))
+	}
+	for i, want := range wantTypes {
+		if events[i].Type != want {
+			t.Errorf("event %d: expected %s, got %s", i, want, events[i].Type)
+		}
+	}
+	if events[0].Data["content"] != "Let me check." || events[0].Data["chunks"] != 2 {
+		t.Errorf("unexpected first segment: %v", events[0].Data)
+	}
+	if events[2].Data["source"] != string(EventTypeReasoningChunk) {
+		t.Errorf("expected reasoning source, got %v", events[2].Data["source"])
+	}
+}
+
+func TestCoalesceThinking_FlushInterval(t *testing.T) {
+	input := make(chan Event)
+	out := CoalesceThinking(input, 10*time.Millisecond)
+
+	input <- ThinkingChunk("partial")
+
+	select {
+	case event := <-out:
+		if event.Type != EventTypeThinkingSegment || event.Data["content"] != "partial" {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected segment to flush after interval")
+	}
+
+	close(input)
+	if remaining := collectEvents(out, time.Second); len(remaining) != 0 {
+		t.Errorf("expected no further events, got %d", len(remaining))
+	}
+}
+
+func TestShapeEvents_DisabledPassesThrough(t *testing.T) {
+	input := make(chan Event, 1)
+	input <- ThinkingChunk("x")
+	close(input)
+
+	events := collectEvents(ShapeEvents(input, StreamShapingConfig{}), time.Second)
+	if len(events) != 1 || events[0].Type != EventTypeThinkingChunk {
+		t.Fatalf("expected chunk to pass through unchanged, got %+v", events)
+	}
+}
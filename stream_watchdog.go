@@ -0,0 +1,65 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ErrStreamStalled is reported to middleware when the stream watchdog
+// aborts a stream that stopped sending chunks (see Config.StreamIdleTimeout).
+var ErrStreamStalled = errors.New("agentkit: stream stalled")
+
+// streamWatchdog aborts a stream that sends no chunk for idle. Unlike a
+// deadline on each read it also catches connections that stay open but go
+// quiet mid-response.
+type streamWatchdog struct {
+	idle    time.Duration
+	timer   *time.Timer
+	stalled atomic.Bool
+}
+
+// watchStream starts a watchdog that cancels the stream's context and closes
+// it once it has been idle for the agent's StreamIdleTimeout. It does
+// nothing when the timeout is zero.
+func (a *Agent) watchStream(stream providers.StreamReader, abort context.CancelFunc) *streamWatchdog {
+	w := &streamWatchdog{idle: a.streamIdleTimeout}
+	if w.idle <= 0 {
+		return w
+	}
+	w.timer = time.AfterFunc(w.idle, func() {
+		w.stalled.Store(true)
+		abort()
+		_ = stream.Close()
+	})
+	return w
+}
+
+// reset restarts the idle period after a chunk arrives.
+func (w *streamWatchdog) reset() {
+	if w.timer != nil && !w.stalled.Load() {
+		w.timer.Reset(w.idle)
+	}
+}
+
+func (w *streamWatchdog) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// fired reports whether the watchdog aborted the stream.
+func (w *streamWatchdog) fired() bool {
+	return w.stalled.Load()
+}
+
+// retryStalledStream repeats an iteration whose stream stalled as a
+// non-streaming call, after telling clients to discard the partial output.
+func (a *Agent) retryStalledStream(ctx context.Context, provider providers.Provider, req providers.CompletionRequest, events chan<- Event, chunks int) (*providers.CompletionResponse, error) {
+	a.logger.Warn("stream stalled; retrying without streaming", "model", req.Model, "idle", a.streamIdleTimeout, "chunks", chunks)
+	a.emit(ctx, events, StreamStalled(req.Model, a.streamIdleTimeout, chunks))
+	return a.runNonStreamingIteration(ctx, provider, req, events)
+}
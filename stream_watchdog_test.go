@@ -0,0 +1,108 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// stallingProvider streams one chunk and then goes quiet until the stream is
+// aborted. Complete is served by the embedded mock.
+type stallingProvider struct {
+	*mockprovider.Provider
+}
+
+func (p stallingProvider) Stream(ctx context.Context, req providers.CompletionRequest) (providers.StreamReader, error) {
+	return &stallingStream{ctx: ctx, closed: make(chan struct{})}, nil
+}
+
+type stallingStream struct {
+	ctx    context.Context
+	sent   bool
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (s *stallingStream) Next() (*providers.StreamChunk, error) {
+	if !s.sent {
+		s.sent = true
+		return &providers.StreamChunk{Content: "Par"}, nil
+	}
+	select {
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	case <-s.closed:
+		return nil, errors.New("stream closed")
+	}
+}
+
+func (s *stallingStream) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	return nil
+}
+
+func TestStreamIdleTimeout_RetriesWithoutStreaming(t *testing.T) {
+	agent, err := New(Config{
+		Provider:          stallingProvider{mockprovider.New().WithResponse("Paris", nil)},
+		Model:             "test-model",
+		StreamResponses:   true,
+		StreamIdleTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := collectEvents(agent.Run(context.Background(), "Capital of France?"), 2*time.Second)
+
+	var stalled *Event
+	var final string
+	for i, event := range events {
+		switch event.Type {
+		case EventTypeStreamStalled:
+			stalled = &events[i]
+		case EventTypeFinalOutput:
+			final, _ = event.Data["response"].(string)
+		case EventTypeError:
+			t.Errorf("unexpected error event: %v", event.Data)
+		}
+	}
+	if stalled == nil || stalled.Data["chunks_received"] != 1 || stalled.Data["idle_ms"] != int64(20) {
+		t.Errorf("model.stream_stalled event = %+v", stalled)
+	}
+	if final != "Paris" {
+		t.Errorf("final output = %q, want the non-streaming answer", final)
+	}
+}
+
+func TestStreamIdleTimeout_ResetsOnChunks(t *testing.T) {
+	chunks := make([]providers.StreamChunk, 0, 6)
+	for range 5 {
+		chunks = append(chunks, providers.StreamChunk{Content: "."})
+	}
+	chunks = append(chunks, providers.StreamChunk{IsComplete: true, FinishReason: providers.FinishReasonStop})
+	agent, err := New(Config{
+		Provider:          mockprovider.New().WithStream(chunks),
+		Model:             "test-model",
+		StreamResponses:   true,
+		StreamIdleTimeout: time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := collectEvents(agent.Run(context.Background(), "Go"), 2*time.Second)
+
+	for _, event := range events {
+		if event.Type == EventTypeStreamStalled {
+			t.Fatalf("healthy stream reported as stalled: %v", event.Data)
+		}
+		if event.Type == EventTypeFinalOutput && event.Data["response"] != "....." {
+			t.Errorf("final output = %v", event.Data["response"])
+		}
+	}
+}
@@ -2,9 +2,7 @@ package agentkit
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"reflect"
 	"strings"
 )
@@ -73,7 +71,8 @@ func StructToSchema[T any]() (*ParameterSchema, error) {
 	return ps, nil
 }
 
-// NewStructTool creates a tool builder using a struct type for schema and decoding.
+// NewStructTool creates a tool builder using a struct type for schema and
+// decoding; see WithTypedHandler.
 func NewStructTool[T any](name string, handler func(context.Context, T) (any, error)) (*ToolBuilder, error) {
 	var zero T
 	schema, err := SchemaFromStruct(zero)
@@ -81,22 +80,7 @@ func NewStructTool[T any](name string, handler func(context.Context, T) (any, er
 		return nil, err
 	}
 
-	wrapper := func(ctx context.Context, args map[string]any) (any, error) {
-		var typed T
-		payload, err := json.Marshal(args)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encode tool args: %w", err)
-		}
-		if err := json.Unmarshal(payload, &typed); err != nil {
-			return nil, fmt.Errorf("failed to decode tool args: %w", err)
-		}
-		return handler(ctx, typed)
-	}
-
-	builder := NewTool(name).
-		WithRawParameters(schema).
-		WithHandler(wrapper)
-	return builder, nil
+	return WithTypedHandler(NewTool(name).WithRawParameters(schema), handler), nil
 }
 
 func schemaFromStructType(t reflect.Type, visited map[reflect.Type]struct{}) (map[string]any, error) {
@@ -0,0 +1,202 @@
+package agentkit
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// GlossaryTerm is a term the final answer must not use. With a Replacement
+// it is a preferred-term rule ("e-mail" -> "email"); without one the term is
+// banned outright.
+type GlossaryTerm struct {
+	Term          string
+	Replacement   string
+	CaseSensitive bool // Match case exactly (default: case-insensitive)
+}
+
+// TerminologyMode decides what the guard does with violations.
+type TerminologyMode string
+
+const (
+	// TerminologyFlag leaves the answer unchanged and emits a guard.violation
+	// event.
+	TerminologyFlag TerminologyMode = "flag"
+	// TerminologyReplace substitutes preferred terms in place. Banned terms
+	// without a replacement are flagged.
+	TerminologyReplace TerminologyMode = "replace"
+	// TerminologyRewrite asks a model once to rewrite the answer following
+	// the glossary, then substitutes anything it missed.
+	TerminologyRewrite TerminologyMode = "rewrite"
+)
+
+// TerminologyConfig configures the terminology guard, which checks the final
+// answer against a glossary of banned and preferred terms. Terms match whole
+// words.
+type TerminologyConfig struct {
+	Glossary []GlossaryTerm
+	Mode     TerminologyMode // Default TerminologyFlag
+	// Provider and Model run the rewrite pass; a small, cheap model is
+	// enough. They default to the agent's provider and model.
+	Provider providers.Provider
+	Model    string
+}
+
+// TerminologyViolation reports a glossary term found in the final answer.
+type TerminologyViolation struct {
+	Term        string `json:"term"`
+	Replacement string `json:"replacement,omitempty"`
+	Count       int    `json:"count"`
+}
+
+// Check returns the glossary terms used in text, in glossary order.
+func (g *TerminologyConfig) Check(text string) []TerminologyViolation {
+	var violations []TerminologyViolation
+	for _, term := range g.Glossary {
+		pattern := termPattern(term)
+		if pattern == nil {
+			continue
+		}
+		if n := len(pattern.FindAllStringIndex(text, -1)); n > 0 {
+			violations = append(violations, TerminologyViolation{Term: term.Term, Replacement: term.Replacement, Count: n})
+		}
+	}
+	return violations
+}
+
+// Replace substitutes every term that has a replacement, keeping a leading
+// capital letter of the matched text.
+func (g *TerminologyConfig) Replace(text string) string {
+	for _, term := range g.Glossary {
+		pattern := termPattern(term)
+		if pattern == nil || term.Replacement == "" {
+			continue
+		}
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			return matchCapital(match, term.Replacement)
+		})
+	}
+	return text
+}
+
+// rules describes the glossary for the rewrite prompt.
+func (g *TerminologyConfig) rules() string {
+	var b strings.Builder
+	for _, term := range g.Glossary {
+		if term.Replacement != "" {
+			fmt.Fprintf(&b, "- Write %q instead of %q.\n", term.Replacement, term.Term)
+		} else {
+			fmt.Fprintf(&b, "- Never use %q.\n", term.Term)
+		}
+	}
+	return b.String()
+}
+
+// termPattern matches term as a whole word. Word boundaries are only added
+// next to word characters so terms like "C++" still match.
+func termPattern(term GlossaryTerm) *regexp.Regexp {
+	if strings.TrimSpace(term.Term) == "" {
+		return nil
+	}
+	expr := regexp.QuoteMeta(term.Term)
+	if first, _ := utf8.DecodeRuneInString(term.Term); isWordRune(first) {
+		expr = `\b` + expr
+	}
+	if last, _ := utf8.DecodeLastRuneInString(term.Term); isWordRune(last) {
+		expr += `\b`
+	}
+	if !term.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.MustCompile(expr)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// matchCapital capitalizes replacement when match starts with a capital
+// letter, so replacements at the start of a sentence stay capitalized.
+func matchCapital(match, replacement string) string {
+	first, _ := utf8.DecodeRuneInString(match)
+	if !unicode.IsUpper(first) {
+		return replacement
+	}
+	r, size := utf8.DecodeRuneInString(replacement)
+	return string(unicode.ToUpper(r)) + replacement[size:]
+}
+
+// applyTerminology runs the terminology guard on the final answer and emits a
+// guard.violation event when it finds glossary terms. A failed rewrite falls
+// back to in-place replacement. Like length enforcement, the guarded answer is
+// reported in final_output; streamed chunks carry the original.
+func (a *Agent) applyTerminology(ctx context.Context, output string, events chan<- Event, outcome *runOutcome) string {
+	guard := a.terminology
+	if guard == nil || len(guard.Glossary) == 0 {
+		return output
+	}
+	violations := guard.Check(output)
+	if len(violations) == 0 {
+		return output
+	}
+
+	action := "flagged"
+	guarded := output
+	switch guard.Mode {
+	case TerminologyReplace:
+		guarded, action = guard.Replace(output), "replaced"
+	case TerminologyRewrite:
+		action = "replaced"
+		if rewritten := a.rewriteTerminology(ctx, guard, output, events, outcome); rewritten != output {
+			guarded, action = rewritten, "rewritten"
+		}
+		guarded = guard.Replace(guarded)
+	}
+	unresolved := 0
+	for _, v := range guard.Check(guarded) {
+		unresolved += v.Count
+	}
+
+	a.logger.Info("final answer violates glossary",
+		"violations", len(violations),
+		"action", action,
+		"unresolved", unresolved)
+	a.emit(ctx, events, GuardViolation("terminology", action, violations, unresolved))
+	return guarded
+}
+
+// rewriteTerminology asks the guard's model to rewrite output following the
+// glossary. It returns output unchanged when the call fails.
+func (a *Agent) rewriteTerminology(ctx context.Context, guard *TerminologyConfig, output string, events chan<- Event, outcome *runOutcome) string {
+	provider, model := guard.Provider, guard.Model
+	if provider == nil {
+		provider = a.provider
+	}
+	if model == "" {
+		model = a.model
+	}
+	callCtx, cancel := a.withLLMTimeout(ctx)
+	if cancel != nil {
+		defer cancel()
+	}
+	resp, err := provider.Complete(callCtx, providers.CompletionRequest{
+		Model: model,
+		SystemPrompt: "You are a copy editor. Rewrite the text so it follows the glossary rules, changing nothing else. " +
+			"Keep the meaning, formatting and tone. Reply with the rewritten text only.",
+		Messages: []providers.Message{{
+			Role:    providers.RoleUser,
+			Content: fmt.Sprintf("Glossary rules:\n%s\nText:\n%s", guard.rules(), output),
+		}},
+	})
+	if err != nil || strings.TrimSpace(resp.Content) == "" {
+		a.logger.Warn("failed to rewrite final answer for glossary", "error", err)
+		return output
+	}
+	a.recordUsage(ctx, events, model, resp.Usage, outcome)
+	return strings.TrimSpace(resp.Content)
+}
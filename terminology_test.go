@@ -0,0 +1,117 @@
+package agentkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+var testGlossary = []GlossaryTerm{
+	{Term: "e-mail", Replacement: "email"},
+	{Term: "Acme Cloud", Replacement: "AcmeCloud", CaseSensitive: true},
+	{Term: "cheap"},
+	{Term: "C++", Replacement: "C plus plus"},
+}
+
+func TestTerminologyConfig_CheckAndReplace(t *testing.T) {
+	guard := &TerminologyConfig{Glossary: testGlossary}
+	text := "E-mail us about Acme Cloud (not acme cloud), it's cheap. We use C++ and cheaply e-mails."
+
+	violations := guard.Check(text)
+	if len(violations) != 4 {
+		t.Fatalf("violations = %+v", violations)
+	}
+	if violations[0].Term != "e-mail" || violations[0].Count != 1 {
+		t.Errorf("e-mail violation = %+v", violations[0])
+	}
+	if violations[1].Count != 1 || violations[2].Count != 1 {
+		t.Errorf("case-sensitive or whole-word matching failed: %+v", violations)
+	}
+
+	got := guard.Replace(text)
+	want := "Email us about AcmeCloud (not acme cloud), it's cheap. We use C plus plus and cheaply e-mails."
+	if got != want {
+		t.Errorf("Replace() = %q, want %q", got, want)
+	}
+}
+
+func TestTerminology_Modes(t *testing.T) {
+	answer := "Send an e-mail, it's cheap."
+	tests := []struct {
+		name       string
+		mode       TerminologyMode
+		responses  []string
+		want       string
+		action     string
+		unresolved int
+	}{
+		{name: "flag", mode: TerminologyFlag, responses: []string{answer}, want: answer, action: "flagged", unresolved: 2},
+		{name: "replace", mode: TerminologyReplace, responses: []string{answer}, want: "Send an email, it's cheap.", action: "replaced", unresolved: 1},
+		{name: "rewrite", mode: TerminologyRewrite, responses: []string{answer, "Send an e-mail, it's affordable."},
+			want: "Send an email, it's affordable.", action: "rewritten", unresolved: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := mockprovider.New()
+			for _, r := range tt.responses {
+				mock.WithResponse(r, nil)
+			}
+			provider := &recordingProvider{Provider: mock}
+			agent, err := New(Config{
+				Provider:        provider,
+				Model:           "test-model",
+				StreamResponses: false,
+				Terminology:     &TerminologyConfig{Glossary: testGlossary, Mode: tt.mode},
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			events := collectEvents(agent.Run(context.Background(), "hi"), time.Second)
+
+			if got := finalResponse(events); got != tt.want {
+				t.Errorf("final output = %q, want %q", got, tt.want)
+			}
+			var violation *Event
+			for i := range events {
+				if events[i].Type == EventTypeGuardViolation {
+					violation = &events[i]
+				}
+			}
+			if violation == nil {
+				t.Fatal("expected guard.violation event")
+			}
+			if violation.Data["guard"] != "terminology" || violation.Data["action"] != tt.action || violation.Data["unresolved"] != tt.unresolved {
+				t.Errorf("guard.violation data = %v", violation.Data)
+			}
+			if len(provider.requests) != len(tt.responses) {
+				t.Fatalf("requests = %d, want %d", len(provider.requests), len(tt.responses))
+			}
+			if tt.mode == TerminologyRewrite {
+				rewrite := provider.requests[1]
+				if len(rewrite.Tools) != 0 || !strings.Contains(rewrite.Messages[0].Content, `Write "email" instead of "e-mail"`) {
+					t.Errorf("rewrite request = %+v", rewrite)
+				}
+			}
+		})
+	}
+}
+
+func TestTerminology_CleanAnswerEmitsNothing(t *testing.T) {
+	agent, err := New(Config{
+		Provider:        mockprovider.New().WithResponse("Send an email.", nil),
+		Model:           "test-model",
+		StreamResponses: false,
+		Terminology:     &TerminologyConfig{Glossary: testGlossary, Mode: TerminologyRewrite},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for _, e := range collectEvents(agent.Run(context.Background(), "hi"), time.Second) {
+		if e.Type == EventTypeGuardViolation {
+			t.Errorf("unexpected guard.violation event: %v", e.Data)
+		}
+	}
+}
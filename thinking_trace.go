@@ -0,0 +1,55 @@
+package agentkit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ThinkingTraceConfig limits how much of a streamed response's reasoning is
+// recorded in traces, to cut tracer ingest volume on reasoning-heavy models.
+// It keeps the First and Last chunks and every SampleEvery-th chunk in
+// between; omitted runs are replaced with a marker giving their count.
+// Streaming to the client is unaffected: every chunk is still emitted.
+//
+// With all fields zero, reasoning is left out of traces entirely.
+// Non-streamed responses return reasoning as one block and are traced in full.
+type ThinkingTraceConfig struct {
+	First       int // Chunks kept from the start of the reasoning
+	Last        int // Chunks kept from the end of the reasoning
+	SampleEvery int // Also keep every Nth chunk in between (0: none)
+}
+
+// sample returns the traced reasoning of chunks.
+func (c ThinkingTraceConfig) sample(chunks []string) string {
+	var b strings.Builder
+	omitted := 0
+	flush := func() {
+		if omitted > 0 {
+			fmt.Fprintf(&b, "\n[… %d reasoning chunks omitted …]\n", omitted)
+			omitted = 0
+		}
+	}
+	kept := 0
+	for i, chunk := range chunks {
+		if !c.keeps(i, len(chunks)) {
+			omitted++
+			continue
+		}
+		flush()
+		b.WriteString(chunk)
+		kept++
+	}
+	if kept == 0 {
+		return ""
+	}
+	flush()
+	return b.String()
+}
+
+// keeps reports whether chunk i of n is traced.
+func (c ThinkingTraceConfig) keeps(i, n int) bool {
+	if i < c.First || i >= n-c.Last {
+		return true
+	}
+	return c.SampleEvery > 0 && (i-c.First+1)%c.SampleEvery == 0
+}
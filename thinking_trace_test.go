@@ -0,0 +1,89 @@
+package agentkit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// generationTracer records logged generations.
+type generationTracer struct {
+	NoOpTracer
+	mu          sync.Mutex
+	generations []GenerationOptions
+}
+
+func (g *generationTracer) LogGeneration(ctx context.Context, opts GenerationOptions) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.generations = append(g.generations, opts)
+	return nil
+}
+
+func TestThinkingTraceConfig_Sample(t *testing.T) {
+	chunks := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	tests := []struct {
+		name string
+		cfg  ThinkingTraceConfig
+		want string
+	}{
+		{"first and last", ThinkingTraceConfig{First: 2, Last: 1}, "ab\n[… 5 reasoning chunks omitted …]\nh"},
+		{"sampled", ThinkingTraceConfig{First: 1, SampleEvery: 3}, "a\n[… 2 reasoning chunks omitted …]\nd\n[… 2 reasoning chunks omitted …]\ng\n[… 1 reasoning chunks omitted …]\n"},
+		{"everything kept", ThinkingTraceConfig{First: 5, Last: 5}, "abcdefgh"},
+		{"nothing kept", ThinkingTraceConfig{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.sample(chunks); got != tt.want {
+				t.Errorf("sample() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThinkingTrace_SamplesTracedReasoning(t *testing.T) {
+	mock := mockprovider.New().WithStream([]providers.StreamChunk{
+		{ReasoningSummary: "one "},
+		{ReasoningSummary: "two "},
+		{ReasoningSummary: "three "},
+		{ReasoningSummary: "four"},
+		{Content: "done"},
+		{IsComplete: true, FinishReason: providers.FinishReasonStop},
+	})
+	tracer := &generationTracer{}
+	agent, err := New(Config{
+		Provider:        mock,
+		Model:           "test-model",
+		StreamResponses: true,
+		Tracer:          tracer,
+		ThinkingTrace:   &ThinkingTraceConfig{First: 1, Last: 1},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	events := collectEvents(agent.Run(context.Background(), "Think"), time.Second)
+
+	streamed := 0
+	for _, event := range events {
+		if event.Type == EventTypeReasoningChunk {
+			streamed++
+		}
+	}
+	if streamed != 4 {
+		t.Errorf("streamed %d reasoning chunks, want all 4", streamed)
+	}
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.generations) != 1 {
+		t.Fatalf("logged %d generations, want 1", len(tracer.generations))
+	}
+	output := tracer.generations[0].Output.(map[string]any)
+	if want := "one \n[… 2 reasoning chunks omitted …]\nfour"; output["reasoning_summary"] != want {
+		t.Errorf("traced reasoning = %q, want %q", output["reasoning_summary"], want)
+	}
+}
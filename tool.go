@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/darkostanimirovic/agentkit/providers"
 )
@@ -19,16 +20,26 @@ type PendingFormatter func(toolName string, args map[string]any) string
 // It receives the tool name and the result returned by the handler
 type ResultFormatter func(toolName string, result any) string
 
+// ApprovalPreviewFunc renders a pending tool call for approval UIs, e.g. the SQL
+// about to run or a file diff, along with a risk hint
+type ApprovalPreviewFunc func(ctx context.Context, args map[string]any) (ApprovalPreview, error)
+
 // Tool represents an agent tool with its metadata and handler
 type Tool struct {
 	name             string
 	description      string
 	parameters       map[string]any
+	resultSchema     map[string]any // Declared with WithResultSchema
 	handler          ToolHandler
 	pendingFormatter PendingFormatter
 	resultFormatter  ResultFormatter
+	approvalPreview  ApprovalPreviewFunc
 	concurrency      ConcurrencyMode
 	strict           bool // Enable OpenAI Structured Outputs (strict schema validation)
+	requiredScopes   []string
+	requiresApproval bool
+	tags             ToolTags
+	toolset          string // Set when added with Agent.AddToolset
 }
 
 // ToolBuilder helps construct tools with a fluent API
@@ -105,6 +116,12 @@ func (tb *ToolBuilder) WithResultFormatter(formatter ResultFormatter) *ToolBuild
 	return tb
 }
 
+// WithApprovalPreview sets the function that previews this tool's calls in approval requests
+func (tb *ToolBuilder) WithApprovalPreview(preview ApprovalPreviewFunc) *ToolBuilder {
+	tb.tool.approvalPreview = preview
+	return tb
+}
+
 // WithConcurrency controls whether a tool can run in parallel.
 func (tb *ToolBuilder) WithConcurrency(mode ConcurrencyMode) *ToolBuilder {
 	if mode == "" {
@@ -123,6 +140,27 @@ func (tb *ToolBuilder) WithStrictMode(strict bool) *ToolBuilder {
 	return tb
 }
 
+// WithRequiredScopes restricts the tool to runs granted all of scopes (see
+// WithGrantedScopes). Other runs neither see nor can call it.
+func (tb *ToolBuilder) WithRequiredScopes(scopes ...string) *ToolBuilder {
+	tb.tool.requiredScopes = append([]string(nil), scopes...)
+	return tb
+}
+
+// RequireApproval sends every call of this tool through the approval
+// handler, as if it were listed in ApprovalConfig.Tools.
+func (tb *ToolBuilder) RequireApproval() *ToolBuilder {
+	tb.tool.requiresApproval = true
+	return tb
+}
+
+// WithTags describes the tool's effect, latency and cost to the model (see
+// ToolTags).
+func (tb *ToolBuilder) WithTags(tags ToolTags) *ToolBuilder {
+	tb.tool.tags = tags
+	return tb
+}
+
 // Build returns the constructed tool
 func (tb *ToolBuilder) Build() Tool {
 	// Ensure additionalProperties: false is set for strict mode
@@ -162,7 +200,7 @@ func (tb *ToolBuilder) Build() Tool {
 func (t *Tool) ToToolDefinition() providers.ToolDefinition {
 	return providers.ToolDefinition{
 		Name:        t.name,
-		Description: t.description,
+		Description: t.modelDescription(),
 		Parameters:  t.parameters,
 	}
 }
@@ -208,6 +246,30 @@ func (t *Tool) Name() string {
 	return t.name
 }
 
+// RequiredScopes returns the scopes a run needs to use the tool
+func (t *Tool) RequiredScopes() []string {
+	return t.requiredScopes
+}
+
+// Tags returns the tool's effect tags
+func (t *Tool) Tags() ToolTags {
+	return t.tags
+}
+
+// ToBuilder returns a builder starting from a copy of the tool, to derive a
+// variant with another description, schema or access rules
+func (t *Tool) ToBuilder() *ToolBuilder {
+	tool := *t
+	if t.parameters != nil {
+		tool.parameters = make(map[string]any, len(t.parameters))
+		for k, v := range t.parameters {
+			tool.parameters[k] = v
+		}
+	}
+	tool.requiredScopes = append([]string(nil), t.requiredScopes...)
+	return &ToolBuilder{tool: tool}
+}
+
 // FormatPending formats the pending message for this tool
 func (t *Tool) FormatPending(args map[string]any) string {
 	if t.pendingFormatter != nil {
@@ -284,9 +346,12 @@ type ParameterSchema struct {
 }
 
 const (
-	paramTypeString = "string"
-	paramTypeArray  = "array"
-	paramTypeObject = "object"
+	paramTypeString  = "string"
+	paramTypeNumber  = "number"
+	paramTypeInteger = "integer"
+	paramTypeBoolean = "boolean"
+	paramTypeArray   = "array"
+	paramTypeObject  = "object"
 )
 
 // String creates a string parameter schema
@@ -294,6 +359,21 @@ func String() *ParameterSchema {
 	return &ParameterSchema{paramType: paramTypeString}
 }
 
+// Number creates a number parameter schema
+func Number() *ParameterSchema {
+	return &ParameterSchema{paramType: paramTypeNumber}
+}
+
+// Integer creates an integer parameter schema
+func Integer() *ParameterSchema {
+	return &ParameterSchema{paramType: paramTypeInteger}
+}
+
+// Boolean creates a boolean parameter schema
+func Boolean() *ParameterSchema {
+	return &ParameterSchema{paramType: paramTypeBoolean}
+}
+
 // Array creates an array parameter schema
 func Array(itemType string) *ParameterSchema {
 	return &ParameterSchema{
@@ -321,6 +401,7 @@ func ArrayOf(itemSchema *ParameterSchema) *ParameterSchema {
 				props[name] = schema.toMapInternal(true)
 				allRequired = append(allRequired, name)
 			}
+			sort.Strings(allRequired) // map order varies; keep requests identical
 			items["properties"] = props
 			items["required"] = allRequired
 			items["additionalProperties"] = false
@@ -446,8 +527,10 @@ func (ps *ParameterSchema) toMapInternal(strictMode bool) map[string]any {
 			for name := range ps.properties {
 				allRequired = append(allRequired, name)
 			}
+			sort.Strings(allRequired) // map order varies; keep requests identical
 			m["required"] = allRequired
 		} else if len(required) > 0 {
+			sort.Strings(required)
 			m["required"] = required
 		}
 		// Add additionalProperties: false for strict mode compliance
@@ -0,0 +1,78 @@
+package agentkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// compactToolDefinition returns def with its description and every
+// parameter description cut to limit characters. The tool's own schema is
+// not modified.
+func compactToolDefinition(def providers.ToolDefinition, limit int) providers.ToolDefinition {
+	def.Description = truncateDescription(def.Description, limit)
+	if def.Parameters != nil {
+		def.Parameters, _ = compactSchema(def.Parameters, limit).(map[string]any)
+	}
+	return def
+}
+
+// compactSchema copies schema, truncating "description" values.
+func compactSchema(schema any, limit int) any {
+	switch v := schema.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(v))
+		for key, value := range v {
+			if s, ok := value.(string); ok && key == "description" {
+				copied[key] = truncateDescription(s, limit)
+				continue
+			}
+			copied[key] = compactSchema(value, limit)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(v))
+		for i, value := range v {
+			copied[i] = compactSchema(value, limit)
+		}
+		return copied
+	default:
+		return schema
+	}
+}
+
+// truncateDescription cuts s to at most limit characters, at a word
+// boundary when there is one, and marks the cut with an ellipsis.
+func truncateDescription(s string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	cut := string(runes[:max(limit-1, 1)])
+	if i := strings.LastIndexAny(cut, " \n\t"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \n\t.,;:") + "…"
+}
+
+// toolSetFingerprint identifies a set of tool definitions, to detect when a
+// request sends the same tools as the stored response it continues.
+func toolSetFingerprint(tools []providers.ToolDefinition) string {
+	data, err := json.Marshal(tools)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// reusesToolDefinitions reports whether provider keeps tool definitions with
+// stored responses.
+func reusesToolDefinitions(provider providers.Provider) bool {
+	reuser, ok := unwrapProvider(provider).(providers.ToolDefinitionReuser)
+	return ok && reuser.ReusesToolDefinitions()
+}
@@ -0,0 +1,88 @@
+package agentkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestCompactToolDefinition(t *testing.T) {
+	tool := NewTool("search").
+		WithDescription("Search the knowledge base for passages about the user's question and return them ranked").
+		WithParameter("query", String().Required().WithDescription("What to search for, including exact identifiers when known")).
+		Build()
+
+	def := compactToolDefinition(tool.ToToolDefinition(), 30)
+	if def.Description != "Search the knowledge base…" {
+		t.Errorf("description = %q", def.Description)
+	}
+	query := def.Parameters["properties"].(map[string]any)["query"].(map[string]any)
+	if got := query["description"].(string); len([]rune(got)) > 30 || !strings.HasSuffix(got, "…") {
+		t.Errorf("parameter description = %q", got)
+	}
+	original := tool.ToToolDefinition().Parameters["properties"].(map[string]any)["query"].(map[string]any)
+	if !strings.HasSuffix(original["description"].(string), "when known") {
+		t.Error("compacting modified the tool's own schema")
+	}
+	if got := truncateDescription("short", 30); got != "short" {
+		t.Errorf("truncateDescription() = %q", got)
+	}
+}
+
+// toolKeepingProvider keeps tool definitions with stored responses.
+type toolKeepingProvider struct {
+	recordingProvider
+}
+
+func (*toolKeepingProvider) ReusesToolDefinitions() bool { return true }
+
+func TestResponseChaining_OmitsUnchangedTools(t *testing.T) {
+	provider := &toolKeepingProvider{recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "lookup", Arguments: map[string]any{}}}).
+		WithResponse("Paris", nil)}}
+	agent := newChainingAgent(t, provider, ResponseChainingConfig{})
+	agent.AddTool(NewTool("lookup").WithHandler(func(context.Context, map[string]any) (any, error) { return "France", nil }).Build())
+
+	collectEvents(agent.Run(context.Background(), "Capital?"), time.Second)
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(provider.requests))
+	}
+	if len(provider.requests[0].Tools) != 1 {
+		t.Errorf("first request tools = %v, want the tool definitions", provider.requests[0].Tools)
+	}
+	if second := provider.requests[1]; second.PreviousResponseID == "" || second.Tools != nil {
+		t.Errorf("second request = %+v, want a chained request without tools", second)
+	}
+}
+
+func TestResponseChaining_OmitsUnchangedToolsBehindAdmission(t *testing.T) {
+	provider := &toolKeepingProvider{recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "lookup", Arguments: map[string]any{}}}).
+		WithResponse("Paris", nil)}}
+	agent, err := New(Config{
+		Provider:          provider,
+		Model:             "test-model",
+		StreamResponses:   false,
+		ConversationStore: NewMemoryConversationStore(),
+		ResponseChaining:  &ResponseChainingConfig{},
+		Admission:         NewAdmissionQueue(AdmissionConfig{MaxConcurrent: 1}),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("lookup").WithHandler(func(context.Context, map[string]any) (any, error) { return "France", nil }).Build())
+
+	collectEvents(agent.Run(context.Background(), "Capital?"), time.Second)
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(provider.requests))
+	}
+	if second := provider.requests[1]; second.PreviousResponseID == "" || second.Tools != nil {
+		t.Errorf("second request = %+v, want a chained request without tools", second)
+	}
+}
@@ -0,0 +1,75 @@
+package agentkit
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ToolArtifact is a file or other output a tool produced alongside its
+// result, e.g. a generated report. Set URI for content stored elsewhere or
+// Data for small inline content.
+type ToolArtifact struct {
+	Name     string         `json:"name"`
+	MIMEType string         `json:"mime_type,omitempty"`
+	URI      string         `json:"uri,omitempty"`
+	Data     []byte         `json:"data,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// ToolReporter lets a tool handler report what it is doing while it runs.
+// Reports become tool.log, tool.progress and tool.artifact events on the
+// run, span events on the tracer, and (for logs) records in the agent's
+// logger, instead of output printed to stdout.
+//
+// Get it with GetToolReporter. All methods are safe to call on a nil
+// reporter and from several goroutines.
+type ToolReporter struct {
+	toolName string
+	toolID   string
+	agent    *Agent
+	ctx      context.Context
+	events   chan<- Event
+}
+
+// GetToolReporter returns the reporter of the tool call running in ctx, or
+// nil outside a tool call.
+func GetToolReporter(ctx context.Context) *ToolReporter {
+	reporter, _ := ctx.Value(toolReporterKey).(*ToolReporter)
+	return reporter
+}
+
+// Log reports a message with optional key-value attributes.
+func (r *ToolReporter) Log(msg string, keyvals ...any) {
+	if r == nil {
+		return
+	}
+	attributes := make(map[string]any, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if key, ok := keyvals[i].(string); ok {
+			attributes[key] = keyvals[i+1]
+		}
+	}
+	r.agent.logger.Info(msg, append([]any{"tool", r.toolName, "tool_id", r.toolID}, keyvals...)...)
+	r.report(ToolLog(r.toolName, r.toolID, slog.LevelInfo.String(), msg, attributes))
+}
+
+// Progress reports completion as a percentage between 0 and 100.
+func (r *ToolReporter) Progress(percent float64, msg string) {
+	if r == nil {
+		return
+	}
+	r.report(ToolProgress(r.toolName, r.toolID, min(max(percent, 0), 100), msg))
+}
+
+// Artifact reports an artifact produced by the tool.
+func (r *ToolReporter) Artifact(artifact ToolArtifact) {
+	if r == nil {
+		return
+	}
+	r.report(ToolArtifactEvent(r.toolName, r.toolID, artifact))
+}
+
+func (r *ToolReporter) report(event Event) {
+	_ = r.agent.tracer.LogEvent(r.ctx, string(event.Type), event.Data)
+	r.agent.emit(r.ctx, r.events, event)
+}
@@ -0,0 +1,91 @@
+package agentkit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// spanEventTracer records LogEvent calls.
+type spanEventTracer struct {
+	NoOpTracer
+	mu     sync.Mutex
+	events []string
+}
+
+func (t *spanEventTracer) LogEvent(ctx context.Context, name string, attributes map[string]any) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, name)
+	return nil
+}
+
+func TestToolReporter(t *testing.T) {
+	tracer := &spanEventTracer{}
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "export", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)
+	agent, err := New(
+		WithProvider(provider),
+		WithModel("test-model"),
+		WithTracing(tracer),
+		WithTool(NewTool("export").
+			WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+				reporter := GetToolReporter(ctx)
+				reporter.Log("exporting rows", "rows", 3)
+				reporter.Progress(50, "halfway")
+				reporter.Progress(150, "done")
+				reporter.Artifact(ToolArtifact{Name: "rows.csv", MIMEType: "text/csv", Data: []byte("a,b\n")})
+				return "exported", nil
+			}).
+			Build()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logs, progress, artifacts []Event
+	for _, e := range collectEvents(agent.Run(context.Background(), "export"), 2*time.Second) {
+		switch e.Type {
+		case EventTypeToolLog:
+			logs = append(logs, e)
+		case EventTypeToolProgress:
+			progress = append(progress, e)
+		case EventTypeToolArtifact:
+			artifacts = append(artifacts, e)
+		}
+	}
+
+	if len(logs) != 1 || logs[0].Data["message"] != "exporting rows" || logs[0].Data["tool_id"] != "call-1" {
+		t.Fatalf("log events = %+v", logs)
+	}
+	if attrs, _ := logs[0].Data["attributes"].(map[string]any); attrs["rows"] != 3 {
+		t.Errorf("log attributes = %v", logs[0].Data["attributes"])
+	}
+	if len(progress) != 2 || progress[0].Data["percent"] != 50.0 || progress[1].Data["percent"] != 100.0 {
+		t.Errorf("progress events = %+v", progress)
+	}
+	if len(artifacts) != 1 || artifacts[0].Data["artifact"].(ToolArtifact).Name != "rows.csv" || artifacts[0].Data["tool_name"] != "export" {
+		t.Errorf("artifact events = %+v", artifacts)
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if len(tracer.events) != 4 {
+		t.Errorf("span events = %v", tracer.events)
+	}
+}
+
+func TestToolReporter_NilOutsideToolCall(t *testing.T) {
+	reporter := GetToolReporter(context.Background())
+	if reporter != nil {
+		t.Fatal("GetToolReporter() outside a tool call is not nil")
+	}
+	reporter.Log("ignored")
+	reporter.Progress(10, "ignored")
+	reporter.Artifact(ToolArtifact{Name: "ignored"})
+}
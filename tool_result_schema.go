@@ -0,0 +1,64 @@
+package agentkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidToolResult is reported when a tool returns a result that doesn't
+// match the schema declared with WithResultSchema.
+var ErrInvalidToolResult = errors.New("agentkit: tool result does not match its schema")
+
+// WithResultSchema declares the JSON schema of the tool's results, e.g. from
+// SchemaFromStruct. The schema is added to the description the model sees,
+// so it knows what the call gives back, and each result is checked against
+// it before it is sent. A result that doesn't match fails the call with
+// ErrInvalidToolResult, as a handler error would: the model is told the
+// tool failed and a ToolError event is emitted.
+func (tb *ToolBuilder) WithResultSchema(schema map[string]any) *ToolBuilder {
+	tb.tool.resultSchema = schema
+	return tb
+}
+
+// ResultSchema returns the schema declared with WithResultSchema, or nil.
+func (t *Tool) ResultSchema() map[string]any {
+	return t.resultSchema
+}
+
+// modelDescription returns the description sent to the model, with the
+// result schema appended when there is one.
+func (t *Tool) modelDescription() string {
+	if t.resultSchema == nil {
+		return t.description
+	}
+	schemaJSON, err := json.Marshal(t.resultSchema)
+	if err != nil {
+		return t.description
+	}
+	return strings.TrimSpace(t.description + "\n\nThe result matches this JSON schema: " + string(schemaJSON))
+}
+
+// checkResult validates result, as it will be sent to the model, against
+// the tool's result schema. Results sent as text that isn't JSON are
+// checked as a JSON string.
+func (t *Tool) checkResult(result any) error {
+	if t.resultSchema == nil {
+		return nil
+	}
+	content := formatToolResult(result)
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		value = content
+	}
+	violations := ValidateSchema(t.resultSchema, value)
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.String()
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidToolResult, strings.Join(messages, "; "))
+}
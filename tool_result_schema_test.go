@@ -0,0 +1,98 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+var reportSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"location":      map[string]any{"type": "string"},
+		"temperature_c": map[string]any{"type": "number"},
+	},
+	"required": []any{"location", "temperature_c"},
+}
+
+// runResultTool runs an agent whose "report" tool returns result, and
+// returns what the model was sent and the run's events.
+func runResultTool(t *testing.T, schema map[string]any, result any) (*recordingProvider, []Event) {
+	t.Helper()
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "report", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("report").WithDescription("Weather report").WithResultSchema(schema).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return result, nil
+		}).Build())
+	return provider, collectEvents(agent.Run(context.Background(), "Weather?"), time.Second)
+}
+
+func TestWithResultSchema_DescribedToModel(t *testing.T) {
+	tool := NewTool("report").WithDescription("Weather report").WithResultSchema(reportSchema).Build()
+	def := tool.ToToolDefinition()
+	if !strings.HasPrefix(def.Description, "Weather report\n\nThe result matches this JSON schema: {") ||
+		!strings.Contains(def.Description, `"temperature_c":{"type":"number"}`) {
+		t.Errorf("description = %q", def.Description)
+	}
+	if tool.ResultSchema() == nil {
+		t.Error("ResultSchema() = nil")
+	}
+
+	plain := NewTool("report").WithDescription("Weather report").Build()
+	if def := plain.ToToolDefinition(); def.Description != "Weather report" {
+		t.Errorf("description without result schema = %q", def.Description)
+	}
+}
+
+func TestWithResultSchema_ValidResult(t *testing.T) {
+	provider, events := runResultTool(t, reportSchema, weatherReport{Location: "Oslo", TemperatureC: 12.5})
+
+	if got := toolResult(t, provider); got != `{"location":"Oslo","temperature_c":12.5}` {
+		t.Errorf("tool result = %s", got)
+	}
+	if e := findEvent(events, EventTypeError); e != nil {
+		t.Errorf("unexpected error event: %v", e.Data)
+	}
+	if len(provider.requests[0].Tools) != 1 || !strings.Contains(provider.requests[0].Tools[0].Description, "JSON schema") {
+		t.Errorf("tools sent = %+v", provider.requests[0].Tools)
+	}
+}
+
+func TestWithResultSchema_InvalidResult(t *testing.T) {
+	provider, events := runResultTool(t, reportSchema, map[string]any{"location": "Oslo", "temperature_c": "warm"})
+
+	got := toolResult(t, provider)
+	if !strings.HasPrefix(got, "Error executing tool:") || !strings.Contains(got, "$.temperature_c: expected number, got string") {
+		t.Errorf("tool result = %s", got)
+	}
+	e := findEvent(events, EventTypeError)
+	if e == nil {
+		t.Fatal("no error event")
+	}
+	if detail, ok := e.ErrorDetail(); !ok || detail.Tool != "report" || !errors.Is(detail, ErrInvalidToolResult) {
+		t.Errorf("error detail = %+v", detail)
+	}
+}
+
+func TestWithResultSchema_TextResult(t *testing.T) {
+	provider, _ := runResultTool(t, map[string]any{"type": "string"}, "sunny")
+	if got := toolResult(t, provider); got != "sunny" {
+		t.Errorf("tool result = %s", got)
+	}
+
+	provider, _ = runResultTool(t, reportSchema, "sunny")
+	if got := toolResult(t, provider); !strings.Contains(got, "expected object, got string") {
+		t.Errorf("tool result = %s", got)
+	}
+}
@@ -0,0 +1,36 @@
+package agentkit
+
+import (
+	"context"
+	"slices"
+)
+
+const grantedScopesKey contextKey = "agentkit_granted_scopes"
+
+// WithGrantedScopes records the scopes the caller of runs using ctx holds,
+// typically from their access token or role. Tools built with
+// WithRequiredScopes are offered only when every scope they require is
+// granted; calls to withheld tools are answered like calls to unknown tools.
+func WithGrantedScopes(ctx context.Context, scopes ...string) context.Context {
+	return context.WithValue(ctx, grantedScopesKey, append(GrantedScopes(ctx), scopes...))
+}
+
+// GrantedScopes returns the scopes granted by WithGrantedScopes.
+func GrantedScopes(ctx context.Context) []string {
+	scopes, _ := ctx.Value(grantedScopesKey).([]string)
+	return slices.Clone(scopes)
+}
+
+// hasScopes reports whether ctx grants every scope in required.
+func hasScopes(ctx context.Context, required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	granted, _ := ctx.Value(grantedScopesKey).([]string)
+	for _, scope := range required {
+		if !slices.Contains(granted, scope) {
+			return false
+		}
+	}
+	return true
+}
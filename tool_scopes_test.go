@@ -0,0 +1,97 @@
+package agentkit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestToolScopes_WithholdUngrantedTools(t *testing.T) {
+	executed := 0
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "drop_table", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("query").WithRequiredScopes("db:read").Build())
+	agent.AddTool(NewTool("drop_table").
+		WithRequiredScopes("db:read", "db:admin").
+		WithHandler(func(context.Context, map[string]any) (any, error) {
+			executed++
+			return "dropped", nil
+		}).
+		Build())
+
+	ctx := WithGrantedScopes(context.Background(), "db:read")
+	events := collectEvents(agent.Run(ctx, "Clean up"), time.Second)
+
+	var names []string
+	for _, def := range provider.requests[0].Tools {
+		names = append(names, def.Name)
+	}
+	if len(names) != 1 || names[0] != "query" {
+		t.Errorf("offered tools = %v, want [query]", names)
+	}
+	if executed != 0 {
+		t.Error("withheld tool was executed")
+	}
+	unknown := false
+	for _, event := range events {
+		unknown = unknown || event.Type == EventTypeToolUnknown
+	}
+	if !unknown {
+		t.Error("call to a withheld tool was not answered as unknown")
+	}
+
+	if granted := GrantedScopes(WithGrantedScopes(ctx, "db:admin")); len(granted) != 2 {
+		t.Errorf("granted scopes = %v, want both", granted)
+	}
+}
+
+func TestTool_RequireApproval(t *testing.T) {
+	var requests []ApprovalRequest
+	executed := false
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "deploy", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)
+	agent, err := New(Config{
+		Provider:        provider,
+		Model:           "test-model",
+		StreamResponses: false,
+		Approval: &ApprovalConfig{Handler: func(ctx context.Context, req ApprovalRequest) (bool, error) {
+			requests = append(requests, req)
+			return false, nil
+		}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("deploy").
+		RequireApproval().
+		WithHandler(func(context.Context, map[string]any) (any, error) {
+			executed = true
+			return "deployed", nil
+		}).
+		Build())
+
+	events := collectEvents(agent.Run(context.Background(), "Ship it"), time.Second)
+
+	if len(requests) != 1 || requests[0].ToolName != "deploy" {
+		t.Fatalf("approval requests = %+v", requests)
+	}
+	if executed {
+		t.Error("denied tool was executed")
+	}
+	denied := false
+	for _, event := range events {
+		denied = denied || event.Type == EventTypeApprovalDenied
+	}
+	if !denied {
+		t.Error("no approval.denied event")
+	}
+}
@@ -0,0 +1,94 @@
+package agentkit
+
+import (
+	"strings"
+)
+
+// ToolEffect says what calling a tool changes.
+type ToolEffect string
+
+const (
+	EffectReadOnly    ToolEffect = "read_only"
+	EffectWrite       ToolEffect = "write"
+	EffectDestructive ToolEffect = "destructive"
+)
+
+// ToolLatency is how long a call typically takes.
+type ToolLatency string
+
+const (
+	LatencyFast   ToolLatency = "fast"   // well under a second
+	LatencyMedium ToolLatency = "medium" // a few seconds
+	LatencySlow   ToolLatency = "slow"   // tens of seconds or more
+)
+
+// ToolCost is a relative cost hint covering money, quota and load.
+type ToolCost string
+
+const (
+	CostLow    ToolCost = "low"
+	CostMedium ToolCost = "medium"
+	CostHigh   ToolCost = "high"
+)
+
+// ToolTags describe a tool's effects to the model. They are appended to the
+// tool's description as "[effect: read_only; latency: slow; cost: high]",
+// and the system prompt explains the convention whenever a tagged tool is
+// offered. Unset fields are omitted.
+type ToolTags struct {
+	Effect  ToolEffect
+	Latency ToolLatency
+	Cost    ToolCost
+}
+
+// toolTagsGuidance is added to the system prompt when a tagged tool is offered.
+const toolTagsGuidance = `## Tool costs and effects
+Some tool descriptions end with tags such as [effect: read_only; latency: fast; cost: low].
+- Prefer read_only, fast and low-cost tools when they can answer the question.
+- Call slow or high-cost tools only when cheaper tools cannot give the answer, and do not repeat such a call with the same arguments.
+- Call write or destructive tools only to make a change the user asked for; destructive changes cannot be undone.`
+
+// String formats the tags as they appear in tool descriptions.
+func (t ToolTags) String() string {
+	var parts []string
+	if t.Effect != "" {
+		parts = append(parts, "effect: "+string(t.Effect))
+	}
+	if t.Latency != "" {
+		parts = append(parts, "latency: "+string(t.Latency))
+	}
+	if t.Cost != "" {
+		parts = append(parts, "cost: "+string(t.Cost))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(parts, "; ") + "]"
+}
+
+// describe appends the tags to a tool description.
+func (t ToolTags) describe(description string) string {
+	tags := t.String()
+	if tags == "" {
+		return description
+	}
+	if description == "" {
+		return tags
+	}
+	return description + " " + tags
+}
+
+// attributes returns the set tags for events.
+func (t ToolTags) attributes() map[string]any {
+	attributes := map[string]any{}
+	if t.Effect != "" {
+		attributes["effect"] = string(t.Effect)
+	}
+	if t.Latency != "" {
+		attributes["latency"] = string(t.Latency)
+	}
+	if t.Cost != "" {
+		attributes["cost"] = string(t.Cost)
+	}
+	return attributes
+}
@@ -0,0 +1,74 @@
+package agentkit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestToolTags_SurfacedToModel(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "web_search", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("lookup").WithDescription("Look up a cached answer").
+		WithTags(ToolTags{Effect: EffectReadOnly, Latency: LatencyFast, Cost: CostLow}).Build())
+	agent.AddTool(NewTool("web_search").WithDescription("Search the web").
+		WithTags(ToolTags{Latency: LatencySlow, Cost: CostHigh}).
+		WithHandler(func(context.Context, map[string]any) (any, error) { return "results", nil }).
+		Build())
+	agent.AddTool(NewTool("plain").WithDescription("Untagged").Build())
+
+	events := collectEvents(agent.Run(context.Background(), "What's new?"), time.Second)
+
+	req := provider.requests[0]
+	descriptions := map[string]string{}
+	for _, def := range req.Tools {
+		descriptions[def.Name] = def.Description
+	}
+	want := map[string]string{
+		"lookup":     "Look up a cached answer [effect: read_only; latency: fast; cost: low]",
+		"web_search": "Search the web [latency: slow; cost: high]",
+		"plain":      "Untagged",
+	}
+	for name, description := range want {
+		if descriptions[name] != description {
+			t.Errorf("%s description = %q, want %q", name, descriptions[name], description)
+		}
+	}
+	if !strings.Contains(req.SystemPrompt, "## Tool costs and effects") {
+		t.Errorf("system prompt lacks tag guidance: %q", req.SystemPrompt)
+	}
+
+	var tags map[string]any
+	for _, event := range events {
+		if event.Type == EventTypeActionDetected {
+			tags, _ = event.Data["tool_tags"].(map[string]any)
+		}
+	}
+	if tags["cost"] != "high" || tags["latency"] != "slow" || len(tags) != 2 {
+		t.Errorf("tool_tags = %v", tags)
+	}
+}
+
+func TestToolTags_NoGuidanceWithoutTags(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("plain").WithDescription("Untagged").Build())
+
+	collectEvents(agent.Run(context.Background(), "hi"), time.Second)
+
+	if strings.Contains(provider.requests[0].SystemPrompt, "Tool costs") {
+		t.Errorf("unexpected tag guidance: %q", provider.requests[0].SystemPrompt)
+	}
+}
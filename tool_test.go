@@ -268,6 +268,23 @@ func TestParameterSchema_String(t *testing.T) {
 	}
 }
 
+func TestParameterSchema_ScalarTypes(t *testing.T) {
+	tests := []struct {
+		schema *ParameterSchema
+		want   string
+	}{
+		{schema: Number(), want: "number"},
+		{schema: Integer(), want: "integer"},
+		{schema: Boolean(), want: "boolean"},
+	}
+
+	for _, tt := range tests {
+		if m := tt.schema.ToMap(); m["type"] != tt.want {
+			t.Errorf("expected type %s in map, got %v", tt.want, m["type"])
+		}
+	}
+}
+
 func TestParameterSchema_Array(t *testing.T) {
 	schema := Array(typeString)
 
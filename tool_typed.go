@@ -0,0 +1,74 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidToolArguments is returned by typed tool handlers for arguments
+// that don't fit their type. The message lists the problems, so the model
+// can fix the call.
+var ErrInvalidToolArguments = errors.New("agentkit: invalid tool arguments")
+
+// WithTypedHandler sets tb's handler to one taking its arguments as TArgs
+// and returning TResult, instead of reading a map[string]any. Go methods
+// cannot have type parameters, so it takes the builder and returns it:
+//
+//	type weatherArgs struct {
+//		Location string `json:"location" required:"true" desc:"City name"`
+//		Unit     string `json:"unit" enum:"celsius,fahrenheit"`
+//	}
+//
+//	tool := agentkit.WithTypedHandler(agentkit.NewTool("get_weather").WithDescription("Current weather"),
+//		func(ctx context.Context, args weatherArgs) (Weather, error) {
+//			return lookupWeather(ctx, args.Location, args.Unit)
+//		}).Build()
+//
+// When TArgs is a struct and tb has no parameters yet, they are derived from
+// it as by SchemaFromStruct. The arguments are checked against that schema
+// before decoding: missing required fields, wrong types and unknown fields
+// fail the call with ErrInvalidToolArguments. The result is sent to the
+// model as JSON, or as is when it is a string.
+func WithTypedHandler[TArgs, TResult any](tb *ToolBuilder, handler func(context.Context, TArgs) (TResult, error)) *ToolBuilder {
+	var zero TArgs
+	schema, err := SchemaFromStruct(zero)
+	if err != nil {
+		schema = nil // Not a struct: decode without checking
+	} else if len(tb.tool.parameters) == 0 {
+		tb.WithRawParameters(schema)
+	}
+	return tb.WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+		typed, err := decodeToolArgs[TArgs](schema, args)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, typed)
+	})
+}
+
+// decodeToolArgs checks args against schema, when there is one, and decodes
+// them into T.
+func decodeToolArgs[T any](schema map[string]any, args map[string]any) (T, error) {
+	var typed T
+	if args == nil {
+		args = map[string]any{}
+	}
+	if violations := (schemaValidator{omitNullable: true}).validate(schema, args); len(violations) > 0 {
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = v.String()
+		}
+		return typed, fmt.Errorf("%w: %s", ErrInvalidToolArguments, strings.Join(messages, "; "))
+	}
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return typed, fmt.Errorf("failed to encode tool args: %w", err)
+	}
+	if err := json.Unmarshal(payload, &typed); err != nil {
+		return typed, fmt.Errorf("%w: %v", ErrInvalidToolArguments, err)
+	}
+	return typed, nil
+}
@@ -0,0 +1,125 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+type weatherArgs struct {
+	Location string `json:"location" required:"true" desc:"City name"`
+	Unit     string `json:"unit" enum:"celsius,fahrenheit"`
+}
+
+type weatherReport struct {
+	Location     string  `json:"location"`
+	TemperatureC float64 `json:"temperature_c"`
+}
+
+func typedWeatherTool(calls *int) Tool {
+	return WithTypedHandler(NewTool("get_weather").WithDescription("Current weather"),
+		func(ctx context.Context, args weatherArgs) (weatherReport, error) {
+			*calls++
+			return weatherReport{Location: args.Location, TemperatureC: 12.5}, nil
+		}).Build()
+}
+
+func TestWithTypedHandler(t *testing.T) {
+	var calls int
+	tool := typedWeatherTool(&calls)
+
+	properties, _ := tool.parameters["properties"].(map[string]any)
+	if _, ok := properties["location"]; !ok || tool.parameters["additionalProperties"] != false {
+		t.Errorf("parameters = %v, want the schema of weatherArgs", tool.parameters)
+	}
+
+	result, err := tool.Execute(context.Background(), `{"location":"Oslo","unit":"celsius"}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if got, ok := result.(weatherReport); !ok || got.Location != "Oslo" {
+		t.Errorf("result = %#v", result)
+	}
+	// Optional fields may be left out.
+	if _, err := tool.Execute(context.Background(), `{"location":"Oslo"}`); err != nil {
+		t.Errorf("Execute() without optional field error = %v", err)
+	}
+}
+
+func TestWithTypedHandler_InvalidArguments(t *testing.T) {
+	var calls int
+	tool := typedWeatherTool(&calls)
+
+	tests := map[string]struct {
+		args string
+		want string
+	}{
+		"missing required": {`{"unit":"celsius"}`, `$: missing required property "location"`},
+		"wrong type":       {`{"location":42}`, "$.location: expected string, got number"},
+		"not in enum":      {`{"location":"Oslo","unit":"kelvin"}`, "$.unit: does not match any allowed schema"},
+		"unknown field":    {`{"location":"Oslo","city":"Oslo"}`, `$: unexpected property "city"`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), tt.args)
+			if !errors.Is(err, ErrInvalidToolArguments) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Execute() error = %v, want ErrInvalidToolArguments with %q", err, tt.want)
+			}
+		})
+	}
+	if calls != 0 {
+		t.Errorf("handler called %d times with invalid arguments", calls)
+	}
+}
+
+func TestWithTypedHandler_KeepsDeclaredParameters(t *testing.T) {
+	tool := WithTypedHandler(NewTool("echo").WithParameter("text", String().Required().WithDescription("Text to echo")),
+		func(ctx context.Context, args struct {
+			Text string `json:"text" required:"true"`
+		}) (string, error) {
+			return args.Text, nil
+		}).Build()
+
+	properties := tool.parameters["properties"].(map[string]any)
+	if text, _ := properties["text"].(map[string]any); text["description"] != "Text to echo" {
+		t.Errorf("parameters = %v, want the declared ones", tool.parameters)
+	}
+	if result, err := tool.Execute(context.Background(), `{"text":"hi"}`); err != nil || result != "hi" {
+		t.Errorf("Execute() = %v, %v", result, err)
+	}
+}
+
+func TestWithTypedHandler_NonStructArguments(t *testing.T) {
+	tool := WithTypedHandler(NewTool("tags"), func(ctx context.Context, args map[string]string) (int, error) {
+		return len(args), nil
+	}).Build()
+
+	if result, err := tool.Execute(context.Background(), `{"a":"x","b":"y"}`); err != nil || result != 2 {
+		t.Errorf("Execute() = %v, %v", result, err)
+	}
+	if _, err := tool.Execute(context.Background(), `{"a":1}`); !errors.Is(err, ErrInvalidToolArguments) {
+		t.Errorf("Execute() error = %v, want ErrInvalidToolArguments", err)
+	}
+}
+
+func TestWithTypedHandler_ResultSentAsJSON(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "get_weather", Arguments: map[string]any{"location": "Oslo"}}}).
+		WithResponse("12.5°C in Oslo", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var calls int
+	agent.AddTool(typedWeatherTool(&calls))
+
+	collectEvents(agent.Run(context.Background(), "Weather in Oslo?"), time.Second)
+	if got := toolResult(t, provider); got != `{"location":"Oslo","temperature_c":12.5}` {
+		t.Errorf("tool result = %s", got)
+	}
+}
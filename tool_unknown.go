@@ -0,0 +1,85 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// Bounds on the tool listing returned for a call to an unknown tool, so an
+// agent with many tools doesn't flood the model's context.
+const (
+	unknownToolListLimit        = 25
+	unknownToolDescriptionLimit = 120
+)
+
+// availableTool describes a tool in an unknown-tool result.
+type availableTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// unknownToolResult is the tool result sent back for a call to a tool that
+// doesn't exist or is disabled for the run.
+type unknownToolResult struct {
+	Error          string          `json:"error"`
+	Message        string          `json:"message"`
+	AvailableTools []availableTool `json:"available_tools"`
+	Omitted        int             `json:"omitted_tools,omitempty"`
+}
+
+// enabledToolNames returns the names of the tools the model may call in this
+// run, sorted.
+func (a *Agent) enabledToolNames(ctx context.Context) []string {
+	defer a.readTools()()
+	names := make([]string, 0, len(a.tools))
+	for name, tool := range a.tools {
+		if toolEnabled(ctx, &tool) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unknownToolCall reports a call to a tool the model cannot use: it emits a
+// tool.unknown event and returns a result listing the available tools, so the
+// model can correct the call.
+func (a *Agent) unknownToolCall(ctx context.Context, toolCall providers.ToolCall, events chan<- Event) providers.Message {
+	names := a.enabledToolNames(ctx)
+	a.logger.Warn("tool not found", "tool", toolCall.Name, "available", len(names))
+	a.emit(ctx, events, ToolUnknown(toolCall.Name, toolCall.ID, names))
+
+	result := unknownToolResult{
+		Error:          "unknown_tool",
+		Message:        fmt.Sprintf("Tool %q does not exist. Call one of the available tools instead.", toolCall.Name),
+		AvailableTools: make([]availableTool, 0, min(len(names), unknownToolListLimit)),
+	}
+	if len(names) == 0 {
+		result.Message = fmt.Sprintf("Tool %q does not exist and no tools are available. Answer without calling tools.", toolCall.Name)
+	}
+	for i, name := range names {
+		if i == unknownToolListLimit {
+			result.Omitted = len(names) - i
+			break
+		}
+		tool, _ := a.lookupTool(name)
+		result.AvailableTools = append(result.AvailableTools, availableTool{
+			Name:        name,
+			Description: truncateDescription(tool.description, unknownToolDescriptionLimit),
+		})
+	}
+
+	content, err := json.Marshal(result)
+	if err != nil {
+		content = []byte(fmt.Sprintf("Error: Tool '%s' not found", toolCall.Name))
+	}
+	return providers.Message{
+		Role:       providers.RoleTool,
+		Content:    string(content),
+		ToolCallID: toolCall.ID,
+	}
+}
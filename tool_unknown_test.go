@@ -0,0 +1,87 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestUnknownTool_ListsAvailableTools(t *testing.T) {
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "serch", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("search").
+		WithDescription("Search the knowledge base").
+		WithHandler(func(context.Context, map[string]any) (any, error) { return "ok", nil }).
+		Build())
+
+	events := collectEvents(agent.Run(context.Background(), "Find it"), time.Second)
+
+	var unknown *Event
+	for i := range events {
+		switch events[i].Type {
+		case EventTypeToolUnknown:
+			unknown = &events[i]
+		case EventTypeError:
+			t.Errorf("unexpected error event: %v", events[i].Data)
+		}
+	}
+	if unknown == nil || unknown.Data["tool_name"] != "serch" || unknown.Data["tool_id"] != "call-1" {
+		t.Fatalf("tool.unknown event = %+v", unknown)
+	}
+	if available := unknown.Data["available_tools"].([]string); len(available) != 1 || available[0] != "search" {
+		t.Errorf("available_tools = %v", available)
+	}
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(provider.requests))
+	}
+	messages := provider.requests[1].Messages
+	var result unknownToolResult
+	if err := json.Unmarshal([]byte(messages[len(messages)-1].Content), &result); err != nil {
+		t.Fatalf("tool result is not JSON: %v", err)
+	}
+	if result.Error != "unknown_tool" || !strings.Contains(result.Message, `"serch"`) {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.AvailableTools) != 1 || result.AvailableTools[0] != (availableTool{Name: "search", Description: "Search the knowledge base"}) {
+		t.Errorf("available tools = %+v", result.AvailableTools)
+	}
+}
+
+func TestUnknownTool_BoundsListing(t *testing.T) {
+	agent := &Agent{tools: map[string]Tool{}, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	for i := range unknownToolListLimit + 5 {
+		name := fmt.Sprintf("tool_%02d", i)
+		agent.tools[name] = NewTool(name).WithDescription(strings.Repeat("long ", 100)).Build()
+	}
+	events := make(chan Event, 1)
+
+	msg := agent.unknownToolCall(context.Background(), providers.ToolCall{ID: "1", Name: "nope"}, events)
+
+	var result unknownToolResult
+	if err := json.Unmarshal([]byte(msg.Content), &result); err != nil {
+		t.Fatalf("tool result is not JSON: %v", err)
+	}
+	if len(result.AvailableTools) != unknownToolListLimit || result.Omitted != 5 {
+		t.Errorf("listed %d tools, omitted %d; want %d and 5", len(result.AvailableTools), result.Omitted, unknownToolListLimit)
+	}
+	if got := len([]rune(result.AvailableTools[0].Description)); got > unknownToolDescriptionLimit {
+		t.Errorf("description has %d characters, want at most %d", got, unknownToolDescriptionLimit)
+	}
+	if event := <-events; len(event.Data["available_tools"].([]string)) != unknownToolListLimit+5 {
+		t.Errorf("tool.unknown lists %d tools, want all of them", len(event.Data["available_tools"].([]string)))
+	}
+}
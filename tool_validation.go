@@ -0,0 +1,84 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// ToolArgValidation decides what happens to tool calls whose arguments are
+// not valid JSON or don't match the tool's parameter schema.
+type ToolArgValidation string
+
+const (
+	// ToolArgsLenient reports the problems with a tool.args.invalid event
+	// and calls the handler with the arguments as parsed, empty if they were
+	// not valid JSON.
+	ToolArgsLenient ToolArgValidation = ""
+	// ToolArgsStrict sends the problems back to the model as the tool result,
+	// without calling the handler, so the model can correct the call.
+	ToolArgsStrict ToolArgValidation = "strict"
+)
+
+// invalidArgsResult is the tool result sent back for a call rejected by
+// argument validation.
+type invalidArgsResult struct {
+	Error      string   `json:"error"`
+	Message    string   `json:"message"`
+	Violations []string `json:"violations"`
+}
+
+// toolArgViolations checks the arguments of toolCall against tool's
+// parameter schema.
+func toolArgViolations(tool *Tool, toolCall providers.ToolCall) []SchemaViolation {
+	if toolCall.RawArguments != "" {
+		var value any
+		err := json.Unmarshal([]byte(toolCall.RawArguments), &value)
+		if err == nil {
+			err = fmt.Errorf("expected a JSON object, got %s", jsonTypeName(value))
+		}
+		return []SchemaViolation{{Path: "$", Message: "invalid JSON: " + err.Error()}}
+	}
+	args := toolCall.Arguments
+	if args == nil {
+		args = map[string]any{}
+	}
+	return schemaValidator{omitNullable: true}.validate(tool.parameters, args)
+}
+
+// validateToolArgs reports invalid arguments for toolCall with a
+// tool.args.invalid event. In strict mode it also returns the tool result
+// rejecting the call; it returns nil when the handler should run.
+func (a *Agent) validateToolArgs(ctx context.Context, tool *Tool, toolCall providers.ToolCall, events chan<- Event) *providers.Message {
+	violations := toolArgViolations(tool, toolCall)
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.String()
+	}
+	rejected := a.toolArgValidation == ToolArgsStrict
+	a.logger.Warn("invalid tool arguments", "tool", toolCall.Name, "violations", messages, "rejected", rejected)
+	a.emit(ctx, events, ToolArgsInvalid(toolCall.Name, toolCall.ID, messages, rejected))
+	if !rejected {
+		return nil
+	}
+
+	content, err := json.Marshal(invalidArgsResult{
+		Error:      "invalid_arguments",
+		Message:    fmt.Sprintf("The arguments for tool %q do not match its parameters, so it was not run. Fix them and call it again.", toolCall.Name),
+		Violations: messages,
+	})
+	if err != nil {
+		content = []byte(fmt.Sprintf("Error: invalid arguments for tool '%s'", toolCall.Name))
+	}
+	return &providers.Message{
+		Role:       providers.RoleTool,
+		Content:    string(content),
+		ToolCallID: toolCall.ID,
+		Name:       toolCall.Name,
+	}
+}
@@ -0,0 +1,145 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// weatherTool records the arguments it is called with.
+func weatherTool(calls *[]map[string]any) Tool {
+	return NewTool("weather").
+		WithParameter("city", String().Required()).
+		WithParameter("days", Integer().Optional()).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			*calls = append(*calls, args)
+			return "sunny", nil
+		}).
+		Build()
+}
+
+func runWeather(t *testing.T, mode ToolArgValidation, call providers.ToolCall) (*recordingProvider, []Event, []map[string]any) {
+	t.Helper()
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{call}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false, ToolArgValidation: mode})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var calls []map[string]any
+	agent.AddTool(weatherTool(&calls))
+	events := collectEvents(agent.Run(context.Background(), "weather?"), time.Second)
+	return provider, events, calls
+}
+
+// toolResult returns the tool message sent to the model in the second request.
+func toolResult(t *testing.T, provider *recordingProvider) string {
+	t.Helper()
+	if len(provider.requests) != 2 {
+		t.Fatalf("model called %d times, want 2", len(provider.requests))
+	}
+	for _, msg := range provider.requests[1].Messages {
+		if msg.Role == providers.RoleTool {
+			return msg.Content
+		}
+	}
+	t.Fatal("no tool result sent to the model")
+	return ""
+}
+
+func TestToolArgValidation_StrictRejectsInvalidArguments(t *testing.T) {
+	provider, events, calls := runWeather(t, ToolArgsStrict,
+		providers.ToolCall{ID: "call-1", Name: "weather", Arguments: map[string]any{"days": "three"}})
+
+	if len(calls) != 0 {
+		t.Errorf("handler called with %v", calls)
+	}
+	var result invalidArgsResult
+	if err := json.Unmarshal([]byte(toolResult(t, provider)), &result); err != nil {
+		t.Fatalf("tool result is not JSON: %v", err)
+	}
+	want := []string{`$: missing required property "city"`, "$.days: does not match any allowed schema"}
+	if result.Error != "invalid_arguments" || strings.Join(result.Violations, "|") != strings.Join(want, "|") {
+		t.Errorf("tool result = %+v", result)
+	}
+	invalid := findEvent(events, EventTypeToolArgsInvalid)
+	if invalid == nil || invalid.Data["rejected"] != true || invalid.Data["tool_id"] != "call-1" {
+		t.Fatalf("tool.args.invalid event = %+v", invalid)
+	}
+	if detected := findEvent(events, EventTypeActionDetected); detected != nil {
+		t.Errorf("rejected call reported as detected: %v", detected.Data)
+	}
+}
+
+func TestToolArgValidation_StrictRejectsUnparsableJSON(t *testing.T) {
+	provider, _, calls := runWeather(t, ToolArgsStrict,
+		providers.ToolCall{ID: "call-1", Name: "weather", RawArguments: `{"city": "Oslo"`})
+
+	if len(calls) != 0 {
+		t.Errorf("handler called with %v", calls)
+	}
+	if result := toolResult(t, provider); !strings.Contains(result, "invalid JSON") {
+		t.Errorf("tool result = %s", result)
+	}
+}
+
+func TestToolArgValidation_OmittedOptionalParameters(t *testing.T) {
+	_, events, calls := runWeather(t, ToolArgsStrict,
+		providers.ToolCall{ID: "call-1", Name: "weather", Arguments: map[string]any{"city": "Oslo"}})
+
+	if len(calls) != 1 || calls[0]["city"] != "Oslo" {
+		t.Errorf("handler calls = %v", calls)
+	}
+	if invalid := findEvent(events, EventTypeToolArgsInvalid); invalid != nil {
+		t.Errorf("unexpected tool.args.invalid event: %v", invalid.Data)
+	}
+}
+
+func TestToolArgValidation_LenientRunsHandler(t *testing.T) {
+	provider, events, calls := runWeather(t, ToolArgsLenient,
+		providers.ToolCall{ID: "call-1", Name: "weather", Arguments: map[string]any{"town": "Oslo"}})
+
+	if len(calls) != 1 || calls[0]["town"] != "Oslo" {
+		t.Errorf("handler calls = %v", calls)
+	}
+	if result := toolResult(t, provider); result != "sunny" {
+		t.Errorf("tool result = %q", result)
+	}
+	invalid := findEvent(events, EventTypeToolArgsInvalid)
+	if invalid == nil || invalid.Data["rejected"] != false || len(invalid.Data["violations"].([]string)) != 2 {
+		t.Fatalf("tool.args.invalid event = %+v", invalid)
+	}
+}
+
+func TestToolArgValidation_UnparsableStreamedArguments(t *testing.T) {
+	mock := mockprovider.New().
+		WithStream([]providers.StreamChunk{
+			{ToolCallID: "call-1", ToolName: "weather", ToolArgs: `"Oslo"`},
+			{IsComplete: true, FinishReason: providers.FinishReasonToolCalls},
+		}).
+		WithStream([]providers.StreamChunk{{Content: "done"}, {IsComplete: true, FinishReason: providers.FinishReasonStop}})
+	agent, err := New(Config{Provider: mock, Model: "test-model", StreamResponses: true, ToolArgValidation: ToolArgsStrict})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var calls []map[string]any
+	agent.AddTool(weatherTool(&calls))
+
+	events := collectEvents(agent.Run(context.Background(), "weather?"), time.Second)
+	if len(calls) != 0 {
+		t.Errorf("handler called with %v", calls)
+	}
+	invalid := findEvent(events, EventTypeToolArgsInvalid)
+	if invalid == nil {
+		t.Fatal("no tool.args.invalid event")
+	}
+	if violations := invalid.Data["violations"].([]string); len(violations) != 1 || !strings.Contains(violations[0], "expected a JSON object, got string") {
+		t.Errorf("violations = %q", violations)
+	}
+}
@@ -0,0 +1,380 @@
+// Package files provides read_file, write_file and list_dir tools confined
+// to a sandbox directory, for coding and document agents:
+//
+//	sandbox, err := files.New(files.Config{Root: "./workspace"})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer sandbox.Close()
+//	sandbox.Register(agent)
+//
+// Paths are relative to Root. Absolute paths, ".." and symlinks leading out
+// of Root are refused, as the files are opened through os.Root. Reads and
+// writes are size-limited, and write_file asks the agent's approval handler
+// before every write unless Config.AutoApproveWrites is set.
+package files
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// Default limits for Config.
+const (
+	DefaultMaxReadBytes  int64 = 1 << 20 // 1 MiB returned by read_file
+	DefaultMaxWriteBytes int64 = 1 << 20 // 1 MiB accepted by write_file
+	DefaultMaxEntries          = 1000    // entries returned by list_dir
+)
+
+// previewBytes caps the content shown in a write approval preview.
+const previewBytes = 4096
+
+// Common errors.
+var (
+	ErrOutsideSandbox = errors.New("files: path is outside the sandbox")
+	ErrTooLarge       = errors.New("files: content exceeds the size limit")
+	ErrBinaryFile     = errors.New("files: not a text file")
+)
+
+// Config configures a Sandbox.
+type Config struct {
+	// Root is the directory the tools are confined to. It must exist.
+	Root          string
+	MaxReadBytes  int64 // Longer files are returned truncated
+	MaxWriteBytes int64 // Larger writes are refused
+	MaxEntries    int   // Larger directories are listed truncated
+	// ReadOnly leaves out write_file.
+	ReadOnly bool
+	// AutoApproveWrites lets write_file run without approval. By default
+	// every write goes through the agent's approval handler, with a
+	// preview of the change.
+	AutoApproveWrites bool
+}
+
+// Sandbox reads and writes files under a root directory.
+type Sandbox struct {
+	cfg     Config
+	root    *os.Root
+	escapes error // The error os.Root wraps for paths escaping it
+}
+
+// File is a file read by ReadFile.
+type File struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Size      int64  `json:"size"`
+	Truncated bool   `json:"truncated"`
+}
+
+// Entry is a directory entry listed by ListDir.
+type Entry struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // "file", "dir" or "symlink"
+	Size int64  `json:"size,omitempty"`
+}
+
+// Listing is a directory listed by ListDir.
+type Listing struct {
+	Path      string  `json:"path"`
+	Entries   []Entry `json:"entries"`
+	Truncated bool    `json:"truncated"`
+}
+
+// Write is the outcome of WriteFile.
+type Write struct {
+	Path         string `json:"path"`
+	BytesWritten int    `json:"bytes_written"`
+	Created      bool   `json:"created"`
+}
+
+// New opens the sandbox at cfg.Root, filling in defaults for unset limits.
+func New(cfg Config) (*Sandbox, error) {
+	if cfg.Root == "" {
+		return nil, errors.New("files: Root is required")
+	}
+	if cfg.MaxReadBytes <= 0 {
+		cfg.MaxReadBytes = DefaultMaxReadBytes
+	}
+	if cfg.MaxWriteBytes <= 0 {
+		cfg.MaxWriteBytes = DefaultMaxWriteBytes
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultMaxEntries
+	}
+	root, err := os.OpenRoot(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("files: open root: %w", err)
+	}
+	return &Sandbox{cfg: cfg, root: root, escapes: escapeErr(root)}, nil
+}
+
+// escapeErr returns the error root reports for paths leading out of it.
+// os.Root doesn't export it, so it is captured by asking for the parent.
+func escapeErr(root *os.Root) error {
+	var pathErr *fs.PathError
+	if _, err := root.Stat(".."); errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return nil
+}
+
+// Close releases the root directory.
+func (s *Sandbox) Close() error {
+	return s.root.Close()
+}
+
+// clean checks that path names something under the root and returns it in
+// the form os.Root expects.
+func clean(path string) (string, error) {
+	if path == "" || path == "/" {
+		return ".", nil
+	}
+	path = filepath.FromSlash(strings.TrimPrefix(path, "./"))
+	if !filepath.IsLocal(path) && filepath.Clean(path) != "." {
+		return "", fmt.Errorf("%w: %s", ErrOutsideSandbox, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// rootErr marks errors from os.Root about paths escaping it.
+func (s *Sandbox) rootErr(path string, err error) error {
+	if err != nil && s.escapes != nil && errors.Is(err, s.escapes) {
+		return fmt.Errorf("%w: %s", ErrOutsideSandbox, path)
+	}
+	return err
+}
+
+// ReadFile returns the text of the file at path, truncated to MaxReadBytes.
+func (s *Sandbox) ReadFile(path string) (*File, error) {
+	name, err := clean(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.root.Open(name)
+	if err != nil {
+		return nil, s.rootErr(path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("files: %s is a directory; use list_dir", path)
+	}
+	data, err := io.ReadAll(io.LimitReader(f, s.cfg.MaxReadBytes))
+	if err != nil {
+		return nil, err
+	}
+	truncated := info.Size() > s.cfg.MaxReadBytes
+	if truncated {
+		// Don't cut a character in half.
+		for i := 0; i < utf8.UTFMax-1 && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: %s", ErrBinaryFile, path)
+	}
+	return &File{Path: filepath.ToSlash(name), Content: string(data), Size: info.Size(), Truncated: truncated}, nil
+}
+
+// WriteFile replaces the file at path with content, creating it and its
+// parent directories as needed.
+func (s *Sandbox) WriteFile(path, content string) (*Write, error) {
+	name, err := clean(path)
+	if err != nil {
+		return nil, err
+	}
+	if name == "." {
+		return nil, fmt.Errorf("files: %q is not a file path", path)
+	}
+	if int64(len(content)) > s.cfg.MaxWriteBytes {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrTooLarge, len(content), s.cfg.MaxWriteBytes)
+	}
+	if err := s.mkdirAll(filepath.Dir(name)); err != nil {
+		return nil, s.rootErr(path, err)
+	}
+	_, err = s.root.Lstat(name)
+	created := errors.Is(err, fs.ErrNotExist)
+	f, err := s.root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, s.rootErr(path, err)
+	}
+	n, err := f.WriteString(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Write{Path: filepath.ToSlash(name), BytesWritten: n, Created: created}, nil
+}
+
+// mkdirAll creates dir and its parents under the root.
+func (s *Sandbox) mkdirAll(dir string) error {
+	if dir == "." {
+		return nil
+	}
+	if info, err := s.root.Stat(dir); err == nil {
+		if !info.IsDir() {
+			return fmt.Errorf("files: %s is not a directory", filepath.ToSlash(dir))
+		}
+		return nil
+	}
+	if err := s.mkdirAll(filepath.Dir(dir)); err != nil {
+		return err
+	}
+	if err := s.root.Mkdir(dir, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	return nil
+}
+
+// ListDir lists the directory at path, sorted by name and truncated to
+// MaxEntries.
+func (s *Sandbox) ListDir(path string) (*Listing, error) {
+	name, err := clean(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.root.Open(name)
+	if err != nil {
+		return nil, s.rootErr(path, err)
+	}
+	defer f.Close()
+	dirEntries, err := f.ReadDir(-1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(dirEntries, func(i, j int) bool { return dirEntries[i].Name() < dirEntries[j].Name() })
+
+	listing := &Listing{Path: filepath.ToSlash(name), Entries: []Entry{}}
+	if len(dirEntries) > s.cfg.MaxEntries {
+		dirEntries = dirEntries[:s.cfg.MaxEntries]
+		listing.Truncated = true
+	}
+	for _, de := range dirEntries {
+		entry := Entry{Name: de.Name(), Type: "file"}
+		switch {
+		case de.Type()&fs.ModeSymlink != 0:
+			entry.Type = "symlink"
+		case de.IsDir():
+			entry.Type = "dir"
+		default:
+			if info, err := de.Info(); err == nil {
+				entry.Size = info.Size()
+			}
+		}
+		listing.Entries = append(listing.Entries, entry)
+	}
+	return listing, nil
+}
+
+// Tools returns read_file, list_dir and, unless ReadOnly, write_file.
+func (s *Sandbox) Tools() []agentkit.Tool {
+	tools := []agentkit.Tool{s.readFileTool(), s.listDirTool()}
+	if !s.cfg.ReadOnly {
+		tools = append(tools, s.writeFileTool())
+	}
+	return tools
+}
+
+// Register adds the sandbox's tools to the agent.
+func (s *Sandbox) Register(agent *agentkit.Agent) {
+	for _, tool := range s.Tools() {
+		agent.AddTool(tool)
+	}
+}
+
+func (s *Sandbox) readFileTool() agentkit.Tool {
+	return agentkit.NewTool("read_file").
+		WithDescription(fmt.Sprintf("Read a text file from the workspace. Files over %d bytes are truncated.", s.cfg.MaxReadBytes)).
+		WithParameter("path", agentkit.String().Required().WithDescription("Path relative to the workspace root, e.g. \"src/main.go\"")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			path, _ := args["path"].(string)
+			return s.ReadFile(path)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Reading %v...", args["path"])
+		}).
+		WithTags(agentkit.ToolTags{Effect: agentkit.EffectReadOnly, Latency: agentkit.LatencyFast, Cost: agentkit.CostLow}).
+		Build()
+}
+
+func (s *Sandbox) listDirTool() agentkit.Tool {
+	return agentkit.NewTool("list_dir").
+		WithDescription("List the files and directories in a workspace directory.").
+		WithParameter("path", agentkit.String().Optional().WithDescription("Directory relative to the workspace root; empty for the root")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			path, _ := args["path"].(string)
+			return s.ListDir(path)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			if path, _ := args["path"].(string); path != "" {
+				return fmt.Sprintf("Listing %s...", path)
+			}
+			return "Listing workspace..."
+		}).
+		WithTags(agentkit.ToolTags{Effect: agentkit.EffectReadOnly, Latency: agentkit.LatencyFast, Cost: agentkit.CostLow}).
+		Build()
+}
+
+func (s *Sandbox) writeFileTool() agentkit.Tool {
+	tb := agentkit.NewTool("write_file").
+		WithDescription("Create or overwrite a text file in the workspace with the given content. Parent directories are created as needed.").
+		WithParameter("path", agentkit.String().Required().WithDescription("Path relative to the workspace root")).
+		WithParameter("content", agentkit.String().Required().WithDescription("The complete new content of the file")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			path, _ := args["path"].(string)
+			content, _ := args["content"].(string)
+			return s.WriteFile(path, content)
+		}).
+		WithApprovalPreview(func(ctx context.Context, args map[string]any) (agentkit.ApprovalPreview, error) {
+			path, _ := args["path"].(string)
+			content, _ := args["content"].(string)
+			return s.writePreview(path, content)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Writing %v...", args["path"])
+		}).
+		WithTags(agentkit.ToolTags{Effect: agentkit.EffectWrite, Latency: agentkit.LatencyFast, Cost: agentkit.CostLow})
+	if !s.cfg.AutoApproveWrites {
+		tb.RequireApproval()
+	}
+	return tb.Build()
+}
+
+// writePreview describes a write for approval: creating a file is medium
+// risk, replacing one high.
+func (s *Sandbox) writePreview(path, content string) (agentkit.ApprovalPreview, error) {
+	name, err := clean(path)
+	if err != nil {
+		return agentkit.ApprovalPreview{}, err
+	}
+	diff := content
+	if len(diff) > previewBytes {
+		diff = diff[:previewBytes] + fmt.Sprintf("\n... (%d more bytes)", len(content)-previewBytes)
+	}
+	preview := agentkit.ApprovalPreview{
+		Summary: fmt.Sprintf("Create %s (%d bytes)", filepath.ToSlash(name), len(content)),
+		Diff:    diff,
+		Risk:    agentkit.RiskLevelMedium,
+	}
+	if info, err := s.root.Stat(name); err == nil {
+		preview.Summary = fmt.Sprintf("Overwrite %s (%d bytes, was %d)", filepath.ToSlash(name), len(content), info.Size())
+		preview.Risk = agentkit.RiskLevelHigh
+	}
+	return preview, nil
+}
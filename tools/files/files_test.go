@@ -0,0 +1,170 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+func newSandbox(t *testing.T, cfg Config) (*Sandbox, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg.Root = dir
+	sandbox, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { sandbox.Close() })
+	return sandbox, dir
+}
+
+func TestSandbox_ReadWriteList(t *testing.T) {
+	sandbox, dir := newSandbox(t, Config{})
+
+	write, err := sandbox.WriteFile("notes/todo.md", "- ship it\n")
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if !write.Created || write.BytesWritten != 10 || write.Path != "notes/todo.md" {
+		t.Errorf("unexpected write: %+v", write)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "notes", "todo.md")); string(data) != "- ship it\n" {
+		t.Errorf("file content = %q", data)
+	}
+
+	write, err = sandbox.WriteFile("./notes/todo.md", "- shipped\n")
+	if err != nil || write.Created {
+		t.Fatalf("overwrite = %+v, %v", write, err)
+	}
+
+	file, err := sandbox.ReadFile("notes/todo.md")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if file.Content != "- shipped\n" || file.Truncated {
+		t.Errorf("unexpected file: %+v", file)
+	}
+
+	listing, err := sandbox.ListDir("")
+	if err != nil {
+		t.Fatalf("ListDir() error = %v", err)
+	}
+	if len(listing.Entries) != 1 || listing.Entries[0] != (Entry{Name: "notes", Type: "dir"}) {
+		t.Errorf("unexpected listing: %+v", listing.Entries)
+	}
+}
+
+func TestSandbox_RejectsEscapes(t *testing.T) {
+	sandbox, dir := newSandbox(t, Config{})
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644)
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	for _, path := range []string{"../secret.txt", "/etc/passwd", "a/../../secret.txt", "link/secret.txt"} {
+		if _, err := sandbox.ReadFile(path); !errors.Is(err, ErrOutsideSandbox) {
+			t.Errorf("ReadFile(%q) error = %v, want ErrOutsideSandbox", path, err)
+		}
+		if _, err := sandbox.WriteFile(path, "x"); !errors.Is(err, ErrOutsideSandbox) {
+			t.Errorf("WriteFile(%q) error = %v, want ErrOutsideSandbox", path, err)
+		}
+	}
+	if _, err := sandbox.ListDir("link"); !errors.Is(err, ErrOutsideSandbox) {
+		t.Errorf("ListDir(link) error = %v, want ErrOutsideSandbox", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(outside, "secret.txt")); string(data) != "secret" {
+		t.Errorf("file outside the sandbox was modified: %q", data)
+	}
+}
+
+func TestSandbox_Limits(t *testing.T) {
+	sandbox, dir := newSandbox(t, Config{MaxReadBytes: 5, MaxWriteBytes: 8, MaxEntries: 2})
+
+	if _, err := sandbox.WriteFile("big.txt", "123456789"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("expected ErrTooLarge, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "long.txt"), []byte("abcdé"), 0o644) // é is two bytes
+	file, err := sandbox.ReadFile("long.txt")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if file.Content != "abcd" || !file.Truncated || file.Size != 6 {
+		t.Errorf("unexpected file: %+v", file)
+	}
+
+	os.WriteFile(filepath.Join(dir, "image.png"), []byte{0x89, 'P', 'N', 'G', 0}, 0o644)
+	if _, err := sandbox.ReadFile("image.png"); !errors.Is(err, ErrBinaryFile) {
+		t.Errorf("expected ErrBinaryFile, got %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "z.txt"), nil, 0o644)
+	listing, err := sandbox.ListDir(".")
+	if err != nil {
+		t.Fatalf("ListDir() error = %v", err)
+	}
+	if len(listing.Entries) != 2 || !listing.Truncated || listing.Entries[0].Name != "image.png" {
+		t.Errorf("unexpected listing: %+v", listing)
+	}
+}
+
+func TestSandbox_Tools(t *testing.T) {
+	sandbox, _ := newSandbox(t, Config{})
+
+	var names []string
+	for _, tool := range sandbox.Tools() {
+		names = append(names, tool.Name())
+	}
+	if strings.Join(names, ",") != "read_file,list_dir,write_file" {
+		t.Errorf("tools = %v", names)
+	}
+
+	tools := sandbox.Tools()
+	write := tools[2]
+	if _, err := write.Execute(context.Background(), `{"path":"a.txt","content":"hello"}`); err != nil {
+		t.Fatalf("write_file error = %v", err)
+	}
+	result, err := tools[0].Execute(context.Background(), `{"path":"a.txt"}`)
+	if err != nil {
+		t.Fatalf("read_file error = %v", err)
+	}
+	if result.(*File).Content != "hello" {
+		t.Errorf("read_file = %+v", result)
+	}
+	if tags := write.Tags(); tags.Effect != agentkit.EffectWrite {
+		t.Errorf("write_file effect = %v", tags.Effect)
+	}
+
+	readOnly, _ := newSandbox(t, Config{ReadOnly: true})
+	if n := len(readOnly.Tools()); n != 2 {
+		t.Errorf("read-only sandbox has %d tools, want 2", n)
+	}
+}
+
+func TestSandbox_WritePreview(t *testing.T) {
+	sandbox, _ := newSandbox(t, Config{})
+
+	preview, err := sandbox.writePreview("new.txt", "hello")
+	if err != nil {
+		t.Fatalf("writePreview() error = %v", err)
+	}
+	if preview.Risk != agentkit.RiskLevelMedium || preview.Summary != "Create new.txt (5 bytes)" || preview.Diff != "hello" {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+
+	sandbox.WriteFile("new.txt", "hi")
+	preview, _ = sandbox.writePreview("new.txt", "hello")
+	if preview.Risk != agentkit.RiskLevelHigh || preview.Summary != "Overwrite new.txt (5 bytes, was 2)" {
+		t.Errorf("unexpected preview: %+v", preview)
+	}
+
+	if _, err := sandbox.writePreview("../x", "hello"); !errors.Is(err, ErrOutsideSandbox) {
+		t.Errorf("expected ErrOutsideSandbox, got %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+// Package manifest enables agent tools from configuration data instead of
+// code. A manifest lists each tool, where it runs (compiled into the
+// binary, on a remote worker or in a WebAssembly module) and who may use
+// it, so deployments turn tools on and off by editing a file:
+//
+//	{
+//	  "tools": [
+//	    {"name": "calculate", "effect": "read_only", "latency": "fast", "cost": "low"},
+//	    {"name": "query_db", "endpoint": "https://db-worker.internal/mcp",
+//	     "token_env": "DB_WORKER_TOKEN", "scopes": ["db:read"]},
+//	    {"name": "drop_table", "endpoint": "https://db-worker.internal/mcp",
+//	     "token_env": "DB_WORKER_TOKEN", "scopes": ["db:admin"], "approval": true},
+//	    {"name": "resize_image", "wasm": "file:///opt/tools/resize.wasm",
+//	     "description": "Resize an image", "parameters": {"type": "object", "properties": {}}},
+//	    {"name": "legacy_search", "disabled": true}
+//	  ]
+//	}
+//
+// A Loader resolves the entries. Entries without an endpoint or wasm module
+// name a tool compiled into the binary and passed to NewLoader:
+//
+//	m, err := manifest.LoadFile("tools.json")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	loader := manifest.NewLoader(std.Calculator(), searchTool)
+//	if err := loader.Register(ctx, agent, m); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Scopes become the tool's required scopes, so only runs granted them with
+// agentkit.WithGrantedScopes see the tool, and approval sends every call
+// through the agent's approval handler. Remote entries run on MCP workers
+// (see tools/remote); their schema comes from the worker unless the entry
+// sets parameters.
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/tools/remote"
+)
+
+// Common errors.
+var (
+	ErrInvalidManifest = errors.New("manifest: invalid manifest")
+	ErrUnknownTool     = errors.New("manifest: unknown tool")
+)
+
+// Where an entry's tool runs, as reported by Entry.Source.
+const (
+	SourceBuiltin = "builtin"
+	SourceRemote  = "remote"
+	SourceWasm    = "wasm"
+)
+
+// Manifest lists the tools a deployment enables.
+type Manifest struct {
+	Tools []Entry `json:"tools"`
+}
+
+// Entry declares one tool.
+type Entry struct {
+	Name string `json:"name"`
+	// Description and Parameters (a JSON schema) replace the tool's own.
+	// Wasm entries require Parameters.
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+	// Endpoint is the MCP endpoint of a worker serving the tool.
+	Endpoint string `json:"endpoint,omitempty"`
+	// TokenEnv names the environment variable holding the worker's bearer
+	// token, so manifests carry no secrets.
+	TokenEnv string `json:"token_env,omitempty"`
+	// Wasm references a WebAssembly module run by Loader.Wasm.
+	Wasm string `json:"wasm,omitempty"`
+	// Scopes a run must be granted to use the tool.
+	Scopes []string `json:"scopes,omitempty"`
+	// Approval requires approval for every call.
+	Approval bool `json:"approval,omitempty"`
+	// Effect, Latency and Cost tag the tool for the model (see
+	// agentkit.ToolTags), replacing the tool's own tags.
+	Effect  agentkit.ToolEffect  `json:"effect,omitempty"`
+	Latency agentkit.ToolLatency `json:"latency,omitempty"`
+	Cost    agentkit.ToolCost    `json:"cost,omitempty"`
+	// Disabled entries are skipped.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Source reports where the tool runs.
+func (e Entry) Source() string {
+	switch {
+	case e.Endpoint != "":
+		return SourceRemote
+	case e.Wasm != "":
+		return SourceWasm
+	}
+	return SourceBuiltin
+}
+
+// Parse decodes a JSON manifest and validates it. Unknown fields are
+// rejected, so a misspelled "scopes" cannot silently open up a tool.
+func Parse(data []byte) (*Manifest, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var m Manifest
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// LoadFile reads and parses a manifest file.
+func LoadFile(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Validate checks that names are set and unique and that every entry has
+// one consistent source.
+func (m *Manifest) Validate() error {
+	seen := make(map[string]bool, len(m.Tools))
+	for i, entry := range m.Tools {
+		var problem string
+		switch {
+		case entry.Name == "":
+			problem = "name is required"
+		case seen[entry.Name]:
+			problem = "duplicate name"
+		case entry.Endpoint != "" && entry.Wasm != "":
+			problem = "endpoint and wasm are exclusive"
+		case entry.TokenEnv != "" && entry.Endpoint == "":
+			problem = "token_env needs an endpoint"
+		case entry.Wasm != "" && entry.Parameters == nil:
+			problem = "wasm tools need parameters"
+		case !oneOf(entry.Effect, agentkit.EffectReadOnly, agentkit.EffectWrite, agentkit.EffectDestructive):
+			problem = fmt.Sprintf("unknown effect %q", entry.Effect)
+		case !oneOf(entry.Latency, agentkit.LatencyFast, agentkit.LatencyMedium, agentkit.LatencySlow):
+			problem = fmt.Sprintf("unknown latency %q", entry.Latency)
+		case !oneOf(entry.Cost, agentkit.CostLow, agentkit.CostMedium, agentkit.CostHigh):
+			problem = fmt.Sprintf("unknown cost %q", entry.Cost)
+		}
+		if problem != "" {
+			return fmt.Errorf("%w: tool %d (%q): %s", ErrInvalidManifest, i, entry.Name, problem)
+		}
+		seen[entry.Name] = true
+	}
+	return nil
+}
+
+// oneOf reports whether value is unset or one of allowed.
+func oneOf[T comparable](value T, allowed ...T) bool {
+	var zero T
+	return value == zero || slices.Contains(allowed, value)
+}
+
+// WasmRuntime runs tools compiled to WebAssembly. agentkit ships no
+// runtime; adapt one such as wazero.
+type WasmRuntime interface {
+	// Handler loads the module ref refers to and returns a handler calling
+	// it.
+	Handler(ctx context.Context, ref string, entry Entry) (agentkit.ToolHandler, error)
+}
+
+// Loader resolves manifest entries to tools.
+type Loader struct {
+	builtins map[string]agentkit.Tool
+	// Wasm runs wasm entries; manifests enabling one fail to load without
+	// it.
+	Wasm WasmRuntime
+	// HTTPClient calls remote workers (default http.DefaultClient).
+	HTTPClient *http.Client
+	// Getenv resolves TokenEnv (default os.Getenv).
+	Getenv func(string) string
+}
+
+// NewLoader creates a loader for manifests naming the given compiled-in
+// tools.
+func NewLoader(builtins ...agentkit.Tool) *Loader {
+	l := &Loader{builtins: make(map[string]agentkit.Tool, len(builtins))}
+	for _, tool := range builtins {
+		l.builtins[tool.Name()] = tool
+	}
+	return l
+}
+
+// Tools resolves the manifest's enabled entries, in order. Remote tools are
+// fetched from their workers, one request per endpoint.
+func (l *Loader) Tools(ctx context.Context, m *Manifest) ([]agentkit.Tool, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	workers := make(map[string]map[string]agentkit.Tool)
+	tools := make([]agentkit.Tool, 0, len(m.Tools))
+	for _, entry := range m.Tools {
+		if entry.Disabled {
+			continue
+		}
+		var tool agentkit.Tool
+		var err error
+		switch entry.Source() {
+		case SourceRemote:
+			tool, err = l.remoteTool(ctx, entry, workers)
+		case SourceWasm:
+			tool, err = l.wasmTool(ctx, entry)
+		default:
+			var ok bool
+			if tool, ok = l.builtins[entry.Name]; !ok {
+				err = ErrUnknownTool
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("manifest: tool %q: %w", entry.Name, err)
+		}
+		tools = append(tools, apply(entry, tool))
+	}
+	return tools, nil
+}
+
+// Register adds the manifest's enabled tools to agent.
+func (l *Loader) Register(ctx context.Context, agent *agentkit.Agent, m *Manifest) error {
+	tools, err := l.Tools(ctx, m)
+	if err != nil {
+		return err
+	}
+	for _, tool := range tools {
+		agent.AddTool(tool)
+	}
+	return nil
+}
+
+// remoteTool fetches an entry's tool, listing each worker's tools once.
+func (l *Loader) remoteTool(ctx context.Context, entry Entry, workers map[string]map[string]agentkit.Tool) (agentkit.Tool, error) {
+	token := ""
+	if entry.TokenEnv != "" {
+		getenv := l.Getenv
+		if getenv == nil {
+			getenv = os.Getenv
+		}
+		if token = getenv(entry.TokenEnv); token == "" {
+			return agentkit.Tool{}, fmt.Errorf("%s is not set", entry.TokenEnv)
+		}
+	}
+	key := entry.Endpoint + "\x00" + token
+	worker, ok := workers[key]
+	if !ok {
+		client := remote.NewClient(entry.Endpoint, token)
+		client.HTTPClient = l.HTTPClient
+		tools, err := client.Tools(ctx)
+		if err != nil {
+			return agentkit.Tool{}, err
+		}
+		worker = make(map[string]agentkit.Tool, len(tools))
+		for _, tool := range tools {
+			worker[tool.Name()] = tool
+		}
+		workers[key] = worker
+	}
+	tool, ok := worker[entry.Name]
+	if !ok {
+		return agentkit.Tool{}, fmt.Errorf("%w on worker %s", ErrUnknownTool, entry.Endpoint)
+	}
+	return tool, nil
+}
+
+func (l *Loader) wasmTool(ctx context.Context, entry Entry) (agentkit.Tool, error) {
+	if l.Wasm == nil {
+		return agentkit.Tool{}, errors.New("no WebAssembly runtime configured (Loader.Wasm)")
+	}
+	handler, err := l.Wasm.Handler(ctx, entry.Wasm, entry)
+	if err != nil {
+		return agentkit.Tool{}, err
+	}
+	return agentkit.NewTool(entry.Name).WithHandler(handler).Build(), nil
+}
+
+// apply layers the entry's description, schema, access rules and tags on
+// tool.
+func apply(entry Entry, tool agentkit.Tool) agentkit.Tool {
+	builder := tool.ToBuilder()
+	if entry.Description != "" {
+		builder.WithDescription(entry.Description)
+	}
+	if entry.Parameters != nil {
+		builder.WithRawParameters(entry.Parameters)
+	}
+	if len(entry.Scopes) > 0 {
+		builder.WithRequiredScopes(entry.Scopes...)
+	}
+	if entry.Approval {
+		builder.RequireApproval()
+	}
+	if tags := (agentkit.ToolTags{Effect: entry.Effect, Latency: entry.Latency, Cost: entry.Cost}); tags != (agentkit.ToolTags{}) {
+		builder.WithTags(tags)
+	}
+	return builder.Build()
+}
@@ -0,0 +1,142 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/tools/remote"
+	"github.com/darkostanimirovic/agentkit/transport/mcp"
+)
+
+func echoTool(name string) agentkit.Tool {
+	return agentkit.NewTool(name).
+		WithDescription("Echo " + name).
+		WithHandler(func(context.Context, map[string]any) (any, error) { return name + " ran", nil }).
+		Build()
+}
+
+type fakeWasm struct{ refs []string }
+
+func (w *fakeWasm) Handler(ctx context.Context, ref string, entry Entry) (agentkit.ToolHandler, error) {
+	w.refs = append(w.refs, ref)
+	return func(context.Context, map[string]any) (any, error) { return "wasm " + entry.Name, nil }, nil
+}
+
+func TestLoader_Tools(t *testing.T) {
+	worker := httptest.NewServer(remote.RequireToken("secret", mcp.NewServer("worker", "1.0.0", echoTool("query"), echoTool("drop_table"))))
+	t.Cleanup(worker.Close)
+
+	m, err := Parse([]byte(`{"tools": [
+		{"name": "search", "description": "Search the docs", "scopes": ["docs:read"], "effect": "read_only", "cost": "low"},
+		{"name": "legacy", "disabled": true},
+		{"name": "query", "endpoint": "` + worker.URL + `", "token_env": "WORKER_TOKEN"},
+		{"name": "drop_table", "endpoint": "` + worker.URL + `", "token_env": "WORKER_TOKEN", "approval": true},
+		{"name": "resize", "wasm": "file:///tools/resize.wasm", "parameters": {"type": "object", "properties": {"width": {"type": "integer"}}}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	loader := NewLoader(echoTool("search"), echoTool("legacy"))
+	wasm := &fakeWasm{}
+	loader.Wasm = wasm
+	loader.Getenv = func(name string) string {
+		if name == "WORKER_TOKEN" {
+			return "secret"
+		}
+		return ""
+	}
+
+	tools, err := loader.Tools(context.Background(), m)
+	if err != nil {
+		t.Fatalf("Tools: %v", err)
+	}
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name())
+	}
+	if strings.Join(names, ",") != "search,query,drop_table,resize" {
+		t.Fatalf("tools = %v", names)
+	}
+
+	search := tools[0]
+	if def := search.ToToolDefinition(); def.Description != "Search the docs" {
+		t.Errorf("description = %q", def.Description)
+	}
+	if !slices.Equal(search.RequiredScopes(), []string{"docs:read"}) {
+		t.Errorf("scopes = %v", search.RequiredScopes())
+	}
+	if tags := search.Tags(); tags != (agentkit.ToolTags{Effect: agentkit.EffectReadOnly, Cost: agentkit.CostLow}) {
+		t.Errorf("tags = %+v", tags)
+	}
+	for i, want := range []string{"search ran", "query ran", "drop_table ran", "wasm resize"} {
+		if got, err := tools[i].Execute(context.Background(), "{}"); err != nil || got != want {
+			t.Errorf("%s: Execute = %v, %v; want %q", names[i], got, err, want)
+		}
+	}
+	props, _ := tools[3].ToToolDefinition().Parameters["properties"].(map[string]any)
+	if _, ok := props["width"]; !ok || !slices.Equal(wasm.refs, []string{"file:///tools/resize.wasm"}) {
+		t.Errorf("wasm tool = %v, refs %v", tools[3].ToToolDefinition().Parameters, wasm.refs)
+	}
+}
+
+func TestLoader_Errors(t *testing.T) {
+	tests := map[string]struct {
+		manifest string
+		want     error
+		contains string
+	}{
+		"unknown builtin": {`{"tools": [{"name": "missing"}]}`, ErrUnknownTool, ""},
+		"no wasm runtime": {`{"tools": [{"name": "w", "wasm": "w.wasm", "parameters": {}}]}`, nil, "Loader.Wasm"},
+		"unset token":     {`{"tools": [{"name": "r", "endpoint": "http://127.0.0.1:1", "token_env": "UNSET"}]}`, nil, "UNSET is not set"},
+	}
+	for name, tt := range tests {
+		m, err := Parse([]byte(tt.manifest))
+		if err != nil {
+			t.Fatalf("%s: Parse: %v", name, err)
+		}
+		loader := NewLoader()
+		loader.Getenv = func(string) string { return "" }
+		_, err = loader.Tools(context.Background(), m)
+		if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) || !strings.Contains(err.Error(), tt.contains) {
+			t.Errorf("%s: err = %v", name, err)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"syntax":           `{"tools": [`,
+		"unknown field":    `{"tools": [{"name": "a", "scope": ["x"]}]}`,
+		"missing name":     `{"tools": [{"description": "nameless"}]}`,
+		"duplicate":        `{"tools": [{"name": "a"}, {"name": "a"}]}`,
+		"two sources":      `{"tools": [{"name": "a", "endpoint": "http://w", "wasm": "a.wasm", "parameters": {}}]}`,
+		"stray token":      `{"tools": [{"name": "a", "token_env": "TOKEN"}]}`,
+		"wasm sans schema": `{"tools": [{"name": "a", "wasm": "a.wasm"}]}`,
+		"unknown cost":     `{"tools": [{"name": "a", "cost": "cheap"}]}`,
+	} {
+		if _, err := Parse([]byte(data)); !errors.Is(err, ErrInvalidManifest) {
+			t.Errorf("%s: err = %v, want ErrInvalidManifest", name, err)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(`{"tools": [{"name": "search", "approval": true}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if len(m.Tools) != 1 || !m.Tools[0].Approval || m.Tools[0].Source() != SourceBuiltin {
+		t.Errorf("manifest = %+v", m)
+	}
+}
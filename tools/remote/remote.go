@@ -0,0 +1,361 @@
+// Package remote runs agent tools in a separate worker service, so heavy or
+// privileged tools (database access, shell) live outside the agent process
+// and can be scaled, sandboxed and credentialed on their own.
+//
+// Workers serve their tools with transport/mcp, behind RequireToken:
+//
+//	server := mcp.NewServer("db-worker", "1.0.0", queryTool, migrateTool)
+//	http.Handle("/mcp", remote.RequireToken(os.Getenv("WORKER_TOKEN"), server))
+//
+// The agent fetches the tools, with their schemas, from the worker and adds
+// them like local ones; executing one forwards the call:
+//
+//	client := remote.NewClient("https://db-worker.internal/mcp", token)
+//	tools, err := client.Tools(ctx)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, tool := range tools {
+//		agent.AddTool(tool)
+//	}
+//
+// Calls use MCP's Streamable HTTP transport (JSON-RPC over POST), so any MCP
+// server reachable over HTTP works as a worker.
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/internal/retry"
+)
+
+// DefaultTimeout bounds one attempt of a worker request.
+const DefaultTimeout = 60 * time.Second
+
+// protocolVersion is the MCP revision the client asks for.
+const protocolVersion = "2025-06-18"
+
+// sessionHeader carries the MCP session a server assigned on initialize.
+const sessionHeader = "Mcp-Session-Id"
+
+// Common errors.
+var (
+	ErrToolNotFound = errors.New("remote: tool not found")
+	// ErrWorker wraps JSON-RPC errors returned by the worker.
+	ErrWorker = errors.New("remote: worker error")
+)
+
+// Client calls tools on a worker. It is safe for concurrent use.
+type Client struct {
+	// URL is the worker's MCP endpoint.
+	URL string
+	// Token is sent as a bearer token; see RequireToken.
+	Token string
+	// Headers are added to every request.
+	Headers map[string]string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Timeout bounds each attempt of a request (default DefaultTimeout).
+	Timeout time.Duration
+	// Retry retries requests that failed to reach the worker, timed out,
+	// or were answered with 429 or a 5xx status (default: 2 retries from
+	// 250ms). A call retried after a timeout may run twice on the worker,
+	// so set MaxRetries to 0 for tools that must not.
+	Retry *agentkit.RetryConfig
+
+	nextID atomic.Int64
+	// mu serializes the handshake; sessionMu guards session.
+	mu        sync.Mutex
+	ready     bool
+	sessionMu sync.Mutex
+	session   string
+}
+
+// NewClient creates a client for the worker at url.
+func NewClient(url, token string) *Client {
+	return &Client{URL: url, Token: token}
+}
+
+// Tools fetches the worker's tools. Executing one calls the worker.
+func (c *Client) Tools(ctx context.Context) ([]agentkit.Tool, error) {
+	var tools []agentkit.Tool
+	cursor := ""
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var page struct {
+			Tools []struct {
+				Name        string         `json:"name"`
+				Description string         `json:"description"`
+				InputSchema map[string]any `json:"inputSchema"`
+			} `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, err
+		}
+		for _, info := range page.Tools {
+			tools = append(tools, c.tool(info.Name, info.Description, info.InputSchema))
+		}
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+// Tool fetches one of the worker's tools.
+func (c *Client) Tool(ctx context.Context, name string) (agentkit.Tool, error) {
+	tools, err := c.Tools(ctx)
+	if err != nil {
+		return agentkit.Tool{}, err
+	}
+	for _, tool := range tools {
+		if tool.Name() == name {
+			return tool, nil
+		}
+	}
+	return agentkit.Tool{}, fmt.Errorf("%w: %s", ErrToolNotFound, name)
+}
+
+func (c *Client) tool(name, description string, schema map[string]any) agentkit.Tool {
+	return agentkit.NewTool(name).
+		WithDescription(description).
+		WithRawParameters(schema).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return c.Call(ctx, name, args)
+		}).
+		Build()
+}
+
+// Call runs a tool on the worker and returns its text result. A tool that
+// fails on the worker returns its message as the error.
+func (c *Client) Call(ctx context.Context, name string, args map[string]any) (string, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := c.call(ctx, "tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		return "", err
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			texts = append(texts, content.Text)
+		}
+	}
+	text := strings.Join(texts, "\n")
+	if result.IsError {
+		return "", fmt.Errorf("remote tool %s: %s", name, text)
+	}
+	return text, nil
+}
+
+// call sends a request, initializing the session first.
+func (c *Client) call(ctx context.Context, method string, params, out any) error {
+	if err := c.initialize(ctx); err != nil {
+		return err
+	}
+	return c.request(ctx, method, params, out)
+}
+
+// initialize performs the MCP handshake once. A failed handshake is tried
+// again on the next call.
+func (c *Client) initialize(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ready {
+		return nil
+	}
+	err := c.request(ctx, "initialize", map[string]any{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "agentkit-remote", "version": "1.0.0"},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("remote: initialize: %w", err)
+	}
+	c.ready = true
+	// Notifications get no response; a failure only matters to servers that
+	// require it, and surfaces on the next request.
+	_ = c.send(ctx, map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"}, nil)
+	return nil
+}
+
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// request sends one JSON-RPC request with retries and decodes its result
+// into out.
+func (c *Client) request(ctx context.Context, method string, params, out any) error {
+	cfg := retry.RetryConfig{
+		MaxRetries:      2,
+		InitialDelay:    250 * time.Millisecond,
+		MaxDelay:        5 * time.Second,
+		Multiplier:      2,
+		RetryableErrors: []error{retry.ErrRateLimited, retry.ErrTimeout, retry.ErrServerError},
+	}
+	if c.Retry != nil {
+		cfg = *c.Retry
+	}
+	body := map[string]any{"jsonrpc": "2.0", "id": c.nextID.Add(1), "method": method, "params": params}
+	resp, err := retry.WithRetry(ctx, cfg, func() (*rpcResponse, error) {
+		var resp rpcResponse
+		return &resp, c.send(ctx, body, &resp)
+	})
+	if err != nil {
+		return fmt.Errorf("remote: %s: %w", method, errors.Unwrap(err))
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%w: %s (code %d)", ErrWorker, resp.Error.Message, resp.Error.Code)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, out)
+}
+
+// send posts one message and decodes the response, a JSON body or an SSE
+// stream, into resp when it is a request.
+func (c *Client) send(ctx context.Context, message map[string]any, resp *rpcResponse) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	httpReq.Header.Set("MCP-Protocol-Version", protocolVersion)
+	if c.Token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	c.sessionMu.Lock()
+	if c.session != "" {
+		httpReq.Header.Set(sessionHeader, c.session)
+	}
+	c.sessionMu.Unlock()
+	for k, v := range c.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", retry.ErrTimeout, err)
+	}
+	defer httpResp.Body.Close()
+	if session := httpResp.Header.Get(sessionHeader); session != "" {
+		c.sessionMu.Lock()
+		c.session = session
+		c.sessionMu.Unlock()
+	}
+
+	switch {
+	case httpResp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %s", retry.ErrRateLimited, httpResp.Status)
+	case httpResp.StatusCode >= 500:
+		return fmt.Errorf("%w: %s", retry.ErrServerError, httpResp.Status)
+	case httpResp.StatusCode == http.StatusAccepted && resp == nil:
+		return nil
+	case httpResp.StatusCode != http.StatusOK:
+		detail, _ := io.ReadAll(io.LimitReader(httpResp.Body, 1024))
+		return fmt.Errorf("worker returned %s: %s", httpResp.Status, strings.TrimSpace(string(detail)))
+	case resp == nil:
+		return nil
+	}
+
+	if strings.HasPrefix(httpResp.Header.Get("Content-Type"), "text/event-stream") {
+		return readStreamResponse(httpResp.Body, message["id"], resp)
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// readStreamResponse reads SSE events until the response to id.
+func readStreamResponse(r io.Reader, id any, resp *rpcResponse) error {
+	want, _ := json.Marshal(id)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if rest, ok := strings.CutPrefix(line, "data:"); ok {
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(rest, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+		var msg rpcResponse
+		err := json.Unmarshal([]byte(data.String()), &msg)
+		data.Reset()
+		if err == nil && bytes.Equal(msg.ID, want) {
+			*resp = msg
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %v", retry.ErrTimeout, err)
+	}
+	return errors.New("worker stream ended without a response")
+}
+
+// RequireToken guards a worker: requests without "Authorization: Bearer
+// <token>" get 401 Unauthorized.
+func RequireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if token == "" || subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
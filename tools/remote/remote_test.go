@@ -0,0 +1,177 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/transport/mcp"
+)
+
+func newWorker(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	query := agentkit.NewTool("query").
+		WithDescription("Run a read-only SQL query").
+		WithParameter("sql", agentkit.String().Required().WithDescription("SQL to run")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			sql, _ := args["sql"].(string)
+			if strings.HasPrefix(sql, "DROP") {
+				return nil, errors.New("statement not allowed")
+			}
+			return "3 rows for " + sql, nil
+		}).
+		Build()
+	var handler http.Handler = RequireToken("secret", mcp.NewServer("db-worker", "1.0.0", query))
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_ToolsForwardExecution(t *testing.T) {
+	server := newWorker(t, nil)
+	client := NewClient(server.URL, "secret")
+
+	tools, err := client.Tools(context.Background())
+	if err != nil {
+		t.Fatalf("Tools: %v", err)
+	}
+	if len(tools) != 1 || tools[0].Name() != "query" {
+		t.Fatalf("tools = %+v", tools)
+	}
+	def := tools[0].ToToolDefinition()
+	if def.Description != "Run a read-only SQL query" {
+		t.Errorf("description = %q", def.Description)
+	}
+	props, _ := def.Parameters["properties"].(map[string]any)
+	if _, ok := props["sql"]; !ok {
+		t.Errorf("schema not fetched from worker: %v", def.Parameters)
+	}
+
+	result, err := tools[0].Execute(context.Background(), `{"sql":"SELECT 1"}`)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result != "3 rows for SELECT 1" {
+		t.Errorf("result = %v", result)
+	}
+
+	_, err = tools[0].Execute(context.Background(), `{"sql":"DROP TABLE users"}`)
+	if err == nil || !strings.Contains(err.Error(), "statement not allowed") {
+		t.Errorf("worker tool error = %v, want its message", err)
+	}
+}
+
+func TestClient_Tool(t *testing.T) {
+	client := NewClient(newWorker(t, nil).URL, "secret")
+	if _, err := client.Tool(context.Background(), "query"); err != nil {
+		t.Fatalf("Tool: %v", err)
+	}
+	if _, err := client.Tool(context.Background(), "shell"); !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("err = %v, want ErrToolNotFound", err)
+	}
+}
+
+func TestClient_RejectedWithoutToken(t *testing.T) {
+	server := newWorker(t, nil)
+	for _, token := range []string{"", "wrong"} {
+		client := NewClient(server.URL, token)
+		_, err := client.Tools(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "401") {
+			t.Errorf("token %q: err = %v, want 401", token, err)
+		}
+	}
+}
+
+func TestClient_RetriesUnavailableWorker(t *testing.T) {
+	var requests atomic.Int32
+	server := newWorker(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1)%2 == 1 {
+				http.Error(w, "restarting", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	client := NewClient(server.URL, "secret")
+	client.Retry = &agentkit.RetryConfig{MaxRetries: 1, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+	client.Retry.RetryableErrors = agentkit.DefaultRetryConfig().RetryableErrors
+
+	got, err := client.Call(context.Background(), "query", map[string]any{"sql": "SELECT 2"})
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if got != "3 rows for SELECT 2" {
+		t.Errorf("result = %q", got)
+	}
+
+	client.Retry.MaxRetries = 0
+	requests.Store(0)
+	if _, err := client.Call(context.Background(), "query", map[string]any{"sql": "SELECT 3"}); err == nil {
+		t.Error("expected the 503 without retries")
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := newWorker(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		})
+	})
+	// Registered after the server's cleanup, so it runs before Close waits
+	// for the handler.
+	t.Cleanup(func() { close(release) })
+	client := NewClient(server.URL, "secret")
+	client.Timeout = 20 * time.Millisecond
+	client.Retry = &agentkit.RetryConfig{}
+
+	start := time.Now()
+	_, err := client.Tools(context.Background())
+	if err == nil || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("err = %v after %v, want a timeout", err, time.Since(start))
+	}
+}
+
+func TestClient_StreamResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body := string(data)
+		switch {
+		case strings.Contains(body, `"initialize"`):
+			w.Header().Set(sessionHeader, "s-1")
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+		case strings.Contains(body, "notifications/initialized"):
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			if r.Header.Get(sessionHeader) != "s-1" {
+				http.Error(w, "no session", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":2,\n")
+			fmt.Fprint(w, "data: \"result\":{\"content\":[{\"type\":\"text\",\"text\":\"streamed\"}]}}\n\n")
+		}
+	}))
+	defer server.Close()
+
+	got, err := NewClient(server.URL, "").Call(context.Background(), "query", nil)
+	if err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	if got != "streamed" {
+		t.Errorf("result = %q", got)
+	}
+}
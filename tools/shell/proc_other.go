@@ -0,0 +1,9 @@
+//go:build !unix
+
+package shell
+
+import "os/exec"
+
+// killGroup leaves cancellation to exec.CommandContext, which kills only
+// the command itself.
+func killGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,469 @@
+// Package shell provides a run_command tool for coding and ops agents,
+// with the policy controls an exec wrapper needs:
+//
+//	sh, err := shell.New(shell.Config{
+//		Dir:             "./workspace",
+//		AllowedCommands: []string{"go", "git", "ls", "grep"},
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	sh.Register(agent)
+//
+// Every call goes through the agent's approval handler; the tool can't be
+// built without approval. Commands start in Dir or a directory below it,
+// get a scrubbed environment, are killed with their children on timeout,
+// and have their output truncated. This confines the starting point, not
+// the process: run agents that execute commands in a container or VM.
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// Defaults for Config.
+const (
+	DefaultTimeout        = 30 * time.Second
+	DefaultMaxOutputBytes = 64 << 10 // Per stream
+)
+
+// DefaultPassEnv are the variables copied from the parent environment when
+// Config.PassEnv is nil. Everything else, API keys included, is dropped.
+var DefaultPassEnv = []string{"PATH", "HOME", "LANG", "LC_ALL", "TMPDIR", "TERM"}
+
+// Common errors.
+var (
+	ErrCommandNotAllowed = errors.New("shell: command not allowed")
+	ErrOutsideDir        = errors.New("shell: directory is outside the working directory")
+	ErrEmptyCommand      = errors.New("shell: empty command")
+)
+
+// Config configures a Shell.
+type Config struct {
+	// Dir is the working directory commands are confined to. It must exist.
+	Dir string
+	// AllowedCommands are the program names that may run (default: any).
+	// With UseShell, the first word of every pipeline and list element is
+	// checked, and command substitution is refused.
+	AllowedCommands []string
+	// UseShell runs commands with /bin/sh -c, allowing pipes and
+	// redirection. By default the command is split into arguments and run
+	// directly.
+	UseShell bool
+	// PassEnv names the variables copied from the parent environment
+	// (default DefaultPassEnv); Env adds KEY=VALUE pairs.
+	PassEnv []string
+	Env     []string
+	// Timeout caps each command (default DefaultTimeout); the model may ask
+	// for less.
+	Timeout        time.Duration
+	MaxOutputBytes int // Stdout and stderr are each truncated to this (default DefaultMaxOutputBytes)
+}
+
+// Shell runs commands under a Config.
+type Shell struct {
+	cfg     Config
+	dir     string // Dir with symlinks resolved
+	allowed map[string]bool
+}
+
+// Result is the outcome of a command. A non-zero exit code is a result,
+// not an error, so the model can react to it.
+type Result struct {
+	Command    string `json:"command"`
+	Dir        string `json:"dir"`
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Truncated  bool   `json:"truncated"`
+	TimedOut   bool   `json:"timed_out"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// New checks cfg and fills in defaults.
+func New(cfg Config) (*Shell, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("shell: Dir is required")
+	}
+	dir, err := filepath.Abs(cfg.Dir)
+	if err == nil {
+		dir, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("shell: resolve Dir: %w", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("shell: Dir %s is not a directory", cfg.Dir)
+	}
+	if cfg.PassEnv == nil {
+		cfg.PassEnv = DefaultPassEnv
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxOutputBytes <= 0 {
+		cfg.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	s := &Shell{cfg: cfg, dir: dir}
+	if len(cfg.AllowedCommands) > 0 {
+		s.allowed = make(map[string]bool, len(cfg.AllowedCommands))
+		for _, name := range cfg.AllowedCommands {
+			s.allowed[name] = true
+		}
+	}
+	return s, nil
+}
+
+// Run runs command in dir, relative to the working directory, for at most
+// timeout (0: Config.Timeout).
+func (s *Shell) Run(ctx context.Context, command, dir string, timeout time.Duration) (*Result, error) {
+	argv, err := s.check(command)
+	if err != nil {
+		return nil, err
+	}
+	workDir, err := s.resolveDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 || timeout > s.cfg.Timeout {
+		timeout = s.cfg.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if s.cfg.UseShell {
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	} else {
+		cmd = exec.CommandContext(ctx, argv[0], argv[1:]...)
+	}
+	cmd.Dir = workDir
+	cmd.Env = s.env()
+	stdout := &limitedBuffer{max: s.cfg.MaxOutputBytes}
+	stderr := &limitedBuffer{max: s.cfg.MaxOutputBytes}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = time.Second
+	killGroup(cmd)
+
+	started := time.Now()
+	err = cmd.Run()
+	result := &Result{
+		Command:    command,
+		Dir:        s.relative(workDir),
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		Truncated:  stdout.dropped > 0 || stderr.dropped > 0,
+		TimedOut:   errors.Is(ctx.Err(), context.DeadlineExceeded),
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case result.TimedOut:
+		result.ExitCode = -1
+	default:
+		return nil, fmt.Errorf("shell: %w", err)
+	}
+	return result, nil
+}
+
+var (
+	// assignmentWord matches a leading NAME=value assignment.
+	assignmentWord = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+	// redirectOperator matches a standalone redirection such as "2>" or "<".
+	redirectOperator = regexp.MustCompile(`^[0-9]*([<>]&|<<?|>>?|<>|>\|)$`)
+	// redirectWord matches a redirection fused with its target, as in ">out"
+	// or "2>&1".
+	redirectWord = regexp.MustCompile(`^[0-9]*([<>]&|<<?|>>?|<>|>\|)`)
+)
+
+// check applies the command policy, returning the arguments to run.
+func (s *Shell) check(command string) ([]string, error) {
+	argv, err := splitArgs(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(argv) == 0 {
+		return nil, ErrEmptyCommand
+	}
+	if s.allowed == nil {
+		return argv, nil
+	}
+	if !s.cfg.UseShell {
+		if !s.allowed[argv[0]] {
+			return nil, fmt.Errorf("%w: %s", ErrCommandNotAllowed, argv[0])
+		}
+		return argv, nil
+	}
+	if strings.Contains(command, "$(") || strings.Contains(command, "`") || strings.Contains(command, "<(") || strings.Contains(command, ">(") {
+		return nil, fmt.Errorf("%w: command substitution", ErrCommandNotAllowed)
+	}
+	commands, err := splitCommands(command)
+	if err != nil {
+		return nil, err
+	}
+	for _, words := range commands {
+		words = skipCommandPrefix(words)
+		if len(words) == 0 {
+			continue
+		}
+		// A redirection fused to the command name, as in "cat</etc/passwd",
+		// still runs the name before it.
+		name := words[0]
+		if i := strings.IndexAny(name, "<>"); i >= 0 {
+			name = name[:i]
+		}
+		if !s.allowed[name] {
+			return nil, fmt.Errorf("%w: %s", ErrCommandNotAllowed, words[0])
+		}
+	}
+	return argv, nil
+}
+
+// skipCommandPrefix drops the VAR=value assignments and redirections that
+// may precede the command name.
+func skipCommandPrefix(words []string) []string {
+	for len(words) > 0 {
+		switch word := words[0]; {
+		case assignmentWord.MatchString(word):
+			words = words[1:]
+		case redirectOperator.MatchString(word):
+			words = words[min(2, len(words)):]
+		case redirectWord.MatchString(word):
+			words = words[1:]
+		default:
+			return words
+		}
+	}
+	return words
+}
+
+// resolveDir returns dir under the working directory, refusing paths and
+// symlinks leading out of it.
+func (s *Shell) resolveDir(dir string) (string, error) {
+	if dir == "" || dir == "." {
+		return s.dir, nil
+	}
+	if !filepath.IsLocal(dir) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideDir, dir)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(s.dir, dir))
+	if err != nil {
+		return "", fmt.Errorf("shell: %w", err)
+	}
+	if rel, err := filepath.Rel(s.dir, resolved); err != nil || !filepath.IsLocal(rel) && rel != "." {
+		return "", fmt.Errorf("%w: %s", ErrOutsideDir, dir)
+	}
+	return resolved, nil
+}
+
+func (s *Shell) relative(dir string) string {
+	rel, err := filepath.Rel(s.dir, dir)
+	if err != nil {
+		return dir
+	}
+	return filepath.ToSlash(rel)
+}
+
+// env builds the scrubbed environment.
+func (s *Shell) env() []string {
+	env := make([]string, 0, len(s.cfg.PassEnv)+len(s.cfg.Env))
+	for _, name := range s.cfg.PassEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return append(env, s.cfg.Env...)
+}
+
+// splitArgs splits a command line into words, honoring single and double
+// quotes and backslash escapes.
+func splitArgs(line string) ([]string, error) {
+	commands, err := splitWords(line, false)
+	if err != nil || len(commands) == 0 {
+		return nil, err
+	}
+	return commands[0], nil
+}
+
+// splitCommands splits a shell command line into the words of each command
+// it runs. Unquoted |, ||, &, &&, ; and newlines separate commands; an &
+// following < or >, as in "2>&1", belongs to the redirection.
+func splitCommands(line string) ([][]string, error) {
+	return splitWords(line, true)
+}
+
+// splitWords splits line into words, and with separators set into commands,
+// skipping empty ones.
+func splitWords(line string, separators bool) ([][]string, error) {
+	var (
+		commands [][]string
+		words    []string
+		word     strings.Builder
+		inWord   bool
+		quote    rune
+		escaped  bool
+		prev     rune // The previous rune, if unquoted and unescaped
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+	for _, r := range line {
+		last := prev
+		prev = 0
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case separators && (r == '|' || r == ';' || r == '\n' || r == '&' && last != '<' && last != '>'):
+			endCommand()
+		case r == ' ' || r == '\t' || r == '\n':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+			prev = r
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("shell: unterminated quote or escape")
+	}
+	endCommand()
+	return commands, nil
+}
+
+// limitedBuffer keeps the first max bytes written and counts the rest.
+type limitedBuffer struct {
+	buf     bytes.Buffer
+	max     int
+	dropped int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	keep := min(len(p), b.max-b.buf.Len())
+	b.buf.Write(p[:keep])
+	b.dropped += len(p) - keep
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	if b.dropped == 0 {
+		return b.buf.String()
+	}
+	return fmt.Sprintf("%s\n... (%d more bytes)", b.buf.String(), b.dropped)
+}
+
+// destructiveCommands raise the approval preview to critical risk.
+var destructiveCommands = []string{"rm", "sudo", "su", "dd", "mkfs", "shutdown", "reboot", "kill", "killall", "chmod", "chown", "truncate", "shred"}
+
+// Tool returns the run_command tool. It always requires approval.
+func (s *Shell) Tool() agentkit.Tool {
+	description := "Run a command in the workspace and return its exit code and output."
+	if s.cfg.UseShell {
+		description += " The command runs with /bin/sh -c."
+	}
+	if len(s.cfg.AllowedCommands) > 0 {
+		description += " Allowed programs: " + strings.Join(s.cfg.AllowedCommands, ", ") + "."
+	}
+	return agentkit.NewTool("run_command").
+		WithDescription(description).
+		WithParameter("command", agentkit.String().Required().WithDescription("The command line, e.g. \"go test ./...\"")).
+		WithParameter("dir", agentkit.String().Optional().WithDescription("Directory relative to the workspace root to run in; empty for the root")).
+		WithParameter("timeout_seconds", agentkit.Integer().Optional().WithDescription(fmt.Sprintf("Timeout, at most %d seconds", int(s.cfg.Timeout.Seconds())))).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			command, _ := args["command"].(string)
+			dir, _ := args["dir"].(string)
+			var timeout time.Duration
+			switch seconds := args["timeout_seconds"].(type) { // JSON numbers decode as float64
+			case float64:
+				timeout = time.Duration(seconds * float64(time.Second))
+			case int:
+				timeout = time.Duration(seconds) * time.Second
+			}
+			return s.Run(ctx, command, dir, timeout)
+		}).
+		WithApprovalPreview(func(ctx context.Context, args map[string]any) (agentkit.ApprovalPreview, error) {
+			command, _ := args["command"].(string)
+			dir, _ := args["dir"].(string)
+			return s.preview(command, dir)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Running %v...", args["command"])
+		}).
+		WithTags(agentkit.ToolTags{Effect: agentkit.EffectDestructive}).
+		RequireApproval().
+		Build()
+}
+
+// preview describes a command for approval, refusing ones the policy
+// would reject.
+func (s *Shell) preview(command, dir string) (agentkit.ApprovalPreview, error) {
+	argv, err := s.check(command)
+	if err != nil {
+		return agentkit.ApprovalPreview{}, err
+	}
+	workDir, err := s.resolveDir(dir)
+	if err != nil {
+		return agentkit.ApprovalPreview{}, err
+	}
+	preview := agentkit.ApprovalPreview{
+		Summary: fmt.Sprintf("Run in %s: %s", s.relative(workDir), command),
+		Diff:    "$ " + command,
+		Risk:    agentkit.RiskLevelHigh,
+	}
+	words := argv
+	if s.cfg.UseShell {
+		commands, err := splitCommands(command)
+		if err != nil {
+			return agentkit.ApprovalPreview{}, err
+		}
+		words = slices.Concat(commands...)
+	}
+	for _, word := range words {
+		if slices.Contains(destructiveCommands, filepath.Base(word)) {
+			preview.Risk = agentkit.RiskLevelCritical
+			break
+		}
+	}
+	return preview, nil
+}
+
+// Register adds the run_command tool to the agent.
+func (s *Shell) Register(agent *agentkit.Agent) {
+	agent.AddTool(s.Tool())
+}
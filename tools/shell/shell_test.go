@@ -0,0 +1,162 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func newShell(t *testing.T, cfg Config) (*Shell, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cfg.Dir = dir
+	sh, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return sh, dir
+}
+
+func TestShell_Run(t *testing.T) {
+	sh, dir := newShell(t, Config{})
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+
+	result, err := sh.Run(context.Background(), `sh -c 'pwd; echo oops >&2; exit 3'`, "sub", 0)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.ExitCode != 3 || result.Dir != "sub" || !strings.HasSuffix(strings.TrimSpace(result.Stdout), "/sub") || result.Stderr != "oops\n" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestShell_ScrubsEnvironment(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+	sh, _ := newShell(t, Config{Env: []string{"MODE=test"}})
+
+	result, err := sh.Run(context.Background(), "env", "", 0)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.Contains(result.Stdout, "sk-secret") || !strings.Contains(result.Stdout, "MODE=test") || !strings.Contains(result.Stdout, "PATH=") {
+		t.Errorf("unexpected environment:\n%s", result.Stdout)
+	}
+}
+
+func TestShell_Policy(t *testing.T) {
+	sh, dir := newShell(t, Config{AllowedCommands: []string{"echo", "grep"}})
+	outside := t.TempDir()
+	os.Symlink(outside, filepath.Join(dir, "link"))
+
+	if _, err := sh.Run(context.Background(), "rm -rf .", "", 0); !errors.Is(err, ErrCommandNotAllowed) {
+		t.Errorf("expected ErrCommandNotAllowed, got %v", err)
+	}
+	for _, target := range []string{"..", "/tmp", "link"} {
+		if _, err := sh.Run(context.Background(), "echo hi", target, 0); !errors.Is(err, ErrOutsideDir) {
+			t.Errorf("Run in %q: expected ErrOutsideDir, got %v", target, err)
+		}
+	}
+	if _, err := sh.Run(context.Background(), "echo 'unterminated", "", 0); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+
+	piped, _ := newShell(t, Config{AllowedCommands: []string{"echo", "grep"}, UseShell: true})
+	if result, err := piped.Run(context.Background(), "echo hello | grep ell > out.txt && LANG=C grep -c ell out.txt", "", 0); err != nil || result.Stdout != "1\n" {
+		t.Errorf("allowed pipeline = %+v, %v", result, err)
+	}
+	if result, err := piped.Run(context.Background(), "2>/dev/null <out.txt grep -c ell", "", 0); err != nil || result.Stdout != "1\n" {
+		t.Errorf("leading redirections = %+v, %v", result, err)
+	}
+	for command, want := range map[string]string{"echo 'a;b' \"c|d\"": "a;b c|d\n", "echo hi 2>&1": "hi\n", "echo hi >&2 2>/dev/null; echo ok": "ok\n"} {
+		if result, err := piped.Run(context.Background(), command, "", 0); err != nil || result.Stdout != want {
+			t.Errorf("Run(%q) = %+v, %v", command, result, err)
+		}
+	}
+	for _, command := range []string{"echo hi; rm -rf .", "echo $(rm -rf .)", "echo >(rm -rf .)", "echo hi | sh", "echo hi & sh", "cat</etc/hostname", "id>/dev/stderr", "> out.txt cat out.txt"} {
+		if _, err := piped.Run(context.Background(), command, "", 0); !errors.Is(err, ErrCommandNotAllowed) {
+			t.Errorf("Run(%q): expected ErrCommandNotAllowed, got %v", command, err)
+		}
+	}
+}
+
+func TestShell_TimeoutAndTruncation(t *testing.T) {
+	sh, _ := newShell(t, Config{UseShell: true, Timeout: 5 * time.Second, MaxOutputBytes: 10})
+
+	started := time.Now()
+	result, err := sh.Run(context.Background(), "sleep 10 & sleep 10", "", 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.TimedOut || time.Since(started) > 3*time.Second {
+		t.Errorf("expected a prompt timeout, got %+v after %s", result, time.Since(started))
+	}
+
+	result, err = sh.Run(context.Background(), "printf 0123456789abcdef", "", 0)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Truncated || result.Stdout != "0123456789\n... (6 more bytes)" {
+		t.Errorf("unexpected truncation: %+v", result)
+	}
+}
+
+func TestShell_Tool(t *testing.T) {
+	sh, _ := newShell(t, Config{})
+	tool := sh.Tool()
+	if tool.Name() != "run_command" || tool.Tags().Effect != agentkit.EffectDestructive {
+		t.Errorf("unexpected tool: %s %+v", tool.Name(), tool.Tags())
+	}
+
+	result, err := tool.Execute(context.Background(), `{"command":"echo hi","timeout_seconds":5}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.(*Result).Stdout != "hi\n" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	preview, err := sh.preview("ls -la", "")
+	if err != nil || preview.Risk != agentkit.RiskLevelHigh || preview.Summary != "Run in .: ls -la" {
+		t.Errorf("preview = %+v, %v", preview, err)
+	}
+	if preview, _ := sh.preview("sudo ls", ""); preview.Risk != agentkit.RiskLevelCritical {
+		t.Errorf("expected critical risk for sudo, got %s", preview.Risk)
+	}
+}
+
+func TestShell_RequiresApproval(t *testing.T) {
+	sh, dir := newShell(t, Config{})
+	var requests []agentkit.ApprovalRequest
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "run_command", Arguments: map[string]any{"command": "touch ran"}}}).
+		WithResponse("done", nil)
+	agent, err := agentkit.New(agentkit.Config{
+		Provider: provider,
+		Model:    "test-model",
+		Approval: &agentkit.ApprovalConfig{Handler: func(ctx context.Context, request agentkit.ApprovalRequest) (bool, error) {
+			requests = append(requests, request)
+			return false, nil
+		}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	sh.Register(agent)
+
+	for range agent.Run(context.Background(), "touch a file") {
+	}
+	if len(requests) != 1 || requests[0].Preview == nil || requests[0].Preview.Summary != "Run in .: touch ran" {
+		t.Fatalf("unexpected approval requests: %+v", requests)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran")); !os.IsNotExist(err) {
+		t.Error("command ran without approval")
+	}
+}
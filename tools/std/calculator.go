@@ -0,0 +1,362 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// ErrInvalidExpression is returned when a math expression cannot be parsed.
+var ErrInvalidExpression = errors.New("std: invalid expression")
+
+// Calculator returns a tool that evaluates arithmetic expressions.
+//
+// Supported: + - * / % ^, parentheses, unary minus, the constants pi and e,
+// and the functions abs, sqrt, cbrt, exp, ln, log (base 10), log2, sin, cos,
+// tan, asin, acos, atan, floor, ceil, round, min, max and pow.
+func Calculator() agentkit.Tool {
+	return agentkit.NewTool("calculate").
+		WithDescription("Evaluate a math expression exactly, e.g. \"(2.5 + 3) * 4 ^ 2\" or \"sqrt(2) * pi\". Use this instead of doing arithmetic yourself.").
+		WithParameter("expression", agentkit.String().Required().WithDescription("The expression to evaluate")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			expression, _ := args["expression"].(string)
+			result, err := Evaluate(expression)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{
+				"expression": expression,
+				"result":     result,
+			}, nil
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Calculating %v...", args["expression"])
+		}).
+		Build()
+}
+
+// Evaluate parses and evaluates an arithmetic expression.
+func Evaluate(expression string) (float64, error) {
+	p := &exprParser{input: expression}
+	p.next()
+	value, err := p.parseExpression()
+	if err != nil {
+		return 0, err
+	}
+	if p.tok.kind != tokEOF {
+		return 0, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidExpression, p.tok.text, p.tok.pos)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%w: result is not a finite number", ErrInvalidExpression)
+	}
+	return value, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+	pos   int
+}
+
+// exprParser is a recursive descent parser over a single expression.
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%") unary }
+//	unary      = ("-" | "+") unary | power
+//	power      = primary [ "^" unary ]
+//	primary    = number | ident | ident "(" args ")" | "(" expression ")"
+type exprParser struct {
+	input string
+	pos   int
+	tok   token
+	err   error
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		// Exponent notation, e.g. 1e-3
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			end := p.pos + 1
+			if end < len(p.input) && (p.input[end] == '+' || p.input[end] == '-') {
+				end++
+			}
+			if end < len(p.input) && isDigit(p.input[end]) {
+				for end < len(p.input) && isDigit(p.input[end]) {
+					end++
+				}
+				p.pos = end
+			}
+		}
+		text := p.input[start:p.pos]
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil && p.err == nil {
+			p.err = fmt.Errorf("%w: bad number %q", ErrInvalidExpression, text)
+		}
+		p.tok = token{kind: tokNumber, text: text, value: value, pos: start}
+	case unicode.IsLetter(rune(c)):
+		for p.pos < len(p.input) && (unicode.IsLetter(rune(p.input[p.pos])) || isDigit(p.input[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokIdent, text: strings.ToLower(p.input[start:p.pos]), pos: start}
+	case c == '(':
+		p.pos++
+		p.tok = token{kind: tokLParen, text: "(", pos: start}
+	case c == ')':
+		p.pos++
+		p.tok = token{kind: tokRParen, text: ")", pos: start}
+	case c == ',':
+		p.pos++
+		p.tok = token{kind: tokComma, text: ",", pos: start}
+	case strings.ContainsRune("+-*/%^", rune(c)):
+		p.pos++
+		p.tok = token{kind: tokOperator, text: string(c), pos: start}
+	default:
+		p.pos++
+		p.tok = token{kind: tokOperator, text: string(c), pos: start}
+		if p.err == nil {
+			p.err = fmt.Errorf("%w: unexpected character %q at position %d", ErrInvalidExpression, c, start)
+		}
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (p *exprParser) parseExpression() (float64, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for p.tok.kind == tokOperator && (p.tok.text == "+" || p.tok.text == "-") {
+		op := p.tok.text
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+	return left, p.err
+}
+
+func (p *exprParser) parseTerm() (float64, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for p.tok.kind == tokOperator && (p.tok.text == "*" || p.tok.text == "/" || p.tok.text == "%") {
+		op := p.tok.text
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "*":
+			left *= right
+		case "/":
+			if right == 0 {
+				return 0, fmt.Errorf("%w: division by zero", ErrInvalidExpression)
+			}
+			left /= right
+		case "%":
+			if right == 0 {
+				return 0, fmt.Errorf("%w: modulo by zero", ErrInvalidExpression)
+			}
+			left = math.Mod(left, right)
+		}
+	}
+	return left, p.err
+}
+
+func (p *exprParser) parseUnary() (float64, error) {
+	if p.tok.kind == tokOperator && (p.tok.text == "-" || p.tok.text == "+") {
+		negate := p.tok.text == "-"
+		p.next()
+		value, err := p.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		if negate {
+			return -value, nil
+		}
+		return value, nil
+	}
+	return p.parsePower()
+}
+
+func (p *exprParser) parsePower() (float64, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return 0, err
+	}
+	if p.tok.kind == tokOperator && p.tok.text == "^" {
+		p.next()
+		exponent, err := p.parseUnary() // right-associative
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exponent), nil
+	}
+	return base, nil
+}
+
+func (p *exprParser) parsePrimary() (float64, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	switch p.tok.kind {
+	case tokNumber:
+		value := p.tok.value
+		p.next()
+		return value, p.err
+	case tokLParen:
+		p.next()
+		value, err := p.parseExpression()
+		if err != nil {
+			return 0, err
+		}
+		if p.tok.kind != tokRParen {
+			return 0, fmt.Errorf("%w: missing closing parenthesis", ErrInvalidExpression)
+		}
+		p.next()
+		return value, p.err
+	case tokIdent:
+		name := p.tok.text
+		p.next()
+		if p.tok.kind != tokLParen {
+			switch name {
+			case "pi":
+				return math.Pi, nil
+			case "e":
+				return math.E, nil
+			}
+			return 0, fmt.Errorf("%w: unknown constant %q", ErrInvalidExpression, name)
+		}
+		p.next()
+		args, err := p.parseArgs()
+		if err != nil {
+			return 0, err
+		}
+		return callFunction(name, args)
+	case tokEOF:
+		return 0, fmt.Errorf("%w: unexpected end of expression", ErrInvalidExpression)
+	default:
+		return 0, fmt.Errorf("%w: unexpected %q at position %d", ErrInvalidExpression, p.tok.text, p.tok.pos)
+	}
+}
+
+func (p *exprParser) parseArgs() ([]float64, error) {
+	var args []float64
+	if p.tok.kind == tokRParen {
+		p.next()
+		return args, nil
+	}
+	for {
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+		switch p.tok.kind {
+		case tokComma:
+			p.next()
+		case tokRParen:
+			p.next()
+			return args, nil
+		default:
+			return nil, fmt.Errorf("%w: expected ',' or ')' at position %d", ErrInvalidExpression, p.tok.pos)
+		}
+	}
+}
+
+var unaryFunctions = map[string]func(float64) float64{
+	"abs":   math.Abs,
+	"sqrt":  math.Sqrt,
+	"cbrt":  math.Cbrt,
+	"exp":   math.Exp,
+	"ln":    math.Log,
+	"log":   math.Log10,
+	"log10": math.Log10,
+	"log2":  math.Log2,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+}
+
+func callFunction(name string, args []float64) (float64, error) {
+	if fn, ok := unaryFunctions[name]; ok {
+		if len(args) != 1 {
+			return 0, fmt.Errorf("%w: %s expects 1 argument, got %d", ErrInvalidExpression, name, len(args))
+		}
+		return fn(args[0]), nil
+	}
+
+	switch name {
+	case "pow":
+		if len(args) != 2 {
+			return 0, fmt.Errorf("%w: pow expects 2 arguments, got %d", ErrInvalidExpression, len(args))
+		}
+		return math.Pow(args[0], args[1]), nil
+	case "min", "max":
+		if len(args) == 0 {
+			return 0, fmt.Errorf("%w: %s expects at least 1 argument", ErrInvalidExpression, name)
+		}
+		result := args[0]
+		for _, v := range args[1:] {
+			if name == "min" {
+				result = math.Min(result, v)
+			} else {
+				result = math.Max(result, v)
+			}
+		}
+		return result, nil
+	}
+
+	return 0, fmt.Errorf("%w: unknown function %q", ErrInvalidExpression, name)
+}
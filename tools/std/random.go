@@ -0,0 +1,97 @@
+package std
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	mathrand "math/rand/v2"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+const maxUUIDCount = 100
+
+// UUID returns a tool that generates random (version 4) UUIDs.
+func UUID() agentkit.Tool {
+	return agentkit.NewTool("generate_uuid").
+		WithDescription("Generate one or more random UUIDs (version 4).").
+		WithParameter("count", agentkit.Integer().Optional().WithDescription("How many UUIDs to generate (1-100, default 1)")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			count := intArg(args, "count")
+			if count <= 0 {
+				count = 1
+			}
+			if count > maxUUIDCount {
+				return nil, fmt.Errorf("std: count must be at most %d", maxUUIDCount)
+			}
+			ids := make([]string, count)
+			for i := range ids {
+				id, err := NewUUID()
+				if err != nil {
+					return nil, err
+				}
+				ids[i] = id
+			}
+			return map[string]any{"uuids": ids}, nil
+		}).
+		Build()
+}
+
+// NewUUID returns a random version 4 UUID string.
+func NewUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("std: generate uuid: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// RandomNumber returns a tool that draws a random number from a range.
+func RandomNumber() agentkit.Tool {
+	return agentkit.NewTool("random_number").
+		WithDescription("Draw a uniformly distributed random number between min and max (inclusive for integers).").
+		WithParameter("min", agentkit.Number().Required()).
+		WithParameter("max", agentkit.Number().Required()).
+		WithParameter("integer", agentkit.Boolean().Optional().WithDescription("Return a whole number (default true)")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			lo, _ := args["min"].(float64)
+			hi, _ := args["max"].(float64)
+			if lo > hi {
+				return nil, errors.New("std: min must not exceed max")
+			}
+
+			integer := true
+			if v, ok := args["integer"].(bool); ok {
+				integer = v
+			}
+
+			if integer {
+				lo, hi = math.Ceil(lo), math.Floor(hi)
+				if lo > hi {
+					return nil, errors.New("std: range contains no integers")
+				}
+				// 2^63 is exactly representable, so hi must stay below it.
+				if lo < math.MinInt64 || hi >= -math.MinInt64 {
+					return nil, errors.New("std: integer range exceeds 64 bits")
+				}
+				// The span may overflow int64, so draw it as an unsigned offset.
+				span := uint64(int64(hi)) - uint64(int64(lo))
+				var offset uint64
+				if span == math.MaxUint64 {
+					offset = mathrand.Uint64()
+				} else {
+					offset = mathrand.Uint64N(span + 1)
+				}
+				return map[string]any{"result": int64(uint64(int64(lo)) + offset)}, nil
+			}
+			if math.IsInf(hi-lo, 0) {
+				return nil, errors.New("std: range is too wide")
+			}
+			return map[string]any{"result": lo + mathrand.Float64()*(hi-lo)}, nil
+		}).
+		Build()
+}
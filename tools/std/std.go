@@ -0,0 +1,46 @@
+// Package std provides deterministic utility tools (math, time, dates, unit
+// conversion, UUIDs and random numbers) so agents don't have to do arithmetic
+// or date math themselves.
+//
+//	agent.AddTool(std.Calculator())  // register individually
+//	std.Register(agent)              // or register all of them
+package std
+
+import "github.com/darkostanimirovic/agentkit"
+
+// Tools returns every tool in the standard library.
+func Tools() []agentkit.Tool {
+	return []agentkit.Tool{
+		Calculator(),
+		CurrentTime(),
+		DateAdd(),
+		DateDiff(),
+		UnitConverter(),
+		UUID(),
+		RandomNumber(),
+	}
+}
+
+// Register adds every standard tool to the agent.
+func Register(agent *agentkit.Agent) {
+	for _, tool := range Tools() {
+		agent.AddTool(tool)
+	}
+}
+
+func stringArg(args map[string]any, key string) string {
+	value, _ := args[key].(string)
+	return value
+}
+
+// intArg reads an integer argument; JSON numbers decode as float64.
+func intArg(args map[string]any, key string) int {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,170 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want float64
+	}{
+		{expr: "1 + 2 * 3", want: 7},
+		{expr: "(1 + 2) * 3", want: 9},
+		{expr: "2 ^ 3 ^ 2", want: 512},
+		{expr: "-2 ^ 2", want: -4},
+		{expr: "10 % 4", want: 2},
+		{expr: "sqrt(16) + abs(-3)", want: 7},
+		{expr: "max(1, 5, 3) - min(2, 4)", want: 3},
+		{expr: "round(pi * 100) / 100", want: 3.14},
+		{expr: "1.5e3 / 3", want: 500},
+		{expr: "pow(2, 10)", want: 1024},
+	}
+
+	for _, tt := range tests {
+		got, err := Evaluate(tt.expr)
+		if err != nil {
+			t.Errorf("Evaluate(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Evaluate(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluate_Errors(t *testing.T) {
+	for _, expr := range []string{"", "1 +", "(1 + 2", "1 / 0", "foo(1)", "2 $ 3", "sqrt(1, 2)", "x"} {
+		if _, err := Evaluate(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("Evaluate(%q) error = %v, want ErrInvalidExpression", expr, err)
+		}
+	}
+}
+
+func TestConvertUnits(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{value: 1, from: "mile", to: "km", want: 1.609344},
+		{value: 2, from: "lbs", to: "kg", want: 0.90718474},
+		{value: 100, from: "C", to: "F", want: 212},
+		{value: 0, from: "°C", to: "kelvin", want: 273.15},
+		{value: 1, from: "GiB", to: "MiB", want: 1024},
+		{value: 90, from: "minutes", to: "hours", want: 1.5},
+		{value: 12, from: "inches", to: "ft", want: 1},
+	}
+
+	for _, tt := range tests {
+		got, err := ConvertUnits(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("ConvertUnits(%v, %s, %s) error = %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("ConvertUnits(%v, %s, %s) = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if _, err := ConvertUnits(1, "kg", "km"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("expected incompatible units error, got %v", err)
+	}
+	if _, err := ConvertUnits(1, "parsec", "km"); !errors.Is(err, ErrUnknownUnit) {
+		t.Errorf("expected unknown unit error, got %v", err)
+	}
+}
+
+func TestTimeTools(t *testing.T) {
+	fixed := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	defer func() { now = time.Now }()
+
+	ctx := context.Background()
+
+	current := CurrentTime()
+	result, err := current.Execute(ctx, `{"timezone":"Asia/Tokyo"}`)
+	if err != nil {
+		t.Fatalf("current_time error = %v", err)
+	}
+	if got := result.(map[string]any)["iso"]; got != "2026-03-01T21:00:00+09:00" {
+		t.Errorf("current_time iso = %v", got)
+	}
+
+	add := DateAdd()
+	result, err = add.Execute(ctx, `{"date":"2026-01-31","months":1,"days":null}`)
+	if err != nil {
+		t.Fatalf("date_add error = %v", err)
+	}
+	if got := result.(map[string]any)["date"]; got != "2026-03-03" {
+		t.Errorf("date_add date = %v, want 2026-03-03 (Go normalizes Feb 31)", got)
+	}
+
+	diff := DateDiff()
+	result, err = diff.Execute(ctx, `{"start":"2026-02-01","end":"now"}`)
+	if err != nil {
+		t.Fatalf("date_diff error = %v", err)
+	}
+	if got := result.(map[string]any)["days"]; got != 28.5 {
+		t.Errorf("date_diff days = %v, want 28.5", got)
+	}
+
+	if _, err := diff.Execute(ctx, `{"start":"yesterday","end":"now"}`); !errors.Is(err, ErrInvalidDate) {
+		t.Errorf("expected ErrInvalidDate, got %v", err)
+	}
+}
+
+func TestRandomTools(t *testing.T) {
+	ctx := context.Background()
+
+	uuid := UUID()
+	result, err := uuid.Execute(ctx, `{"count":3}`)
+	if err != nil {
+		t.Fatalf("generate_uuid error = %v", err)
+	}
+	ids := result.(map[string]any)["uuids"].([]string)
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if len(ids) != 3 {
+		t.Fatalf("expected 3 uuids, got %d", len(ids))
+	}
+	for _, id := range ids {
+		if !pattern.MatchString(id) {
+			t.Errorf("invalid uuid %q", id)
+		}
+	}
+
+	random := RandomNumber()
+	for i := 0; i < 20; i++ {
+		result, err := random.Execute(ctx, `{"min":1,"max":3,"integer":null}`)
+		if err != nil {
+			t.Fatalf("random_number error = %v", err)
+		}
+		n := result.(map[string]any)["result"].(int64)
+		if n < 1 || n > 3 {
+			t.Fatalf("random_number out of range: %d", n)
+		}
+	}
+	for _, args := range []string{`{"min":0,"max":1e19}`, `{"min":-1e19,"max":0}`, `{"min":5,"max":1}`, `{"min":-1e308,"max":1e308,"integer":false}`} {
+		if _, err := random.Execute(ctx, args); err == nil {
+			t.Errorf("random_number(%s): expected an error", args)
+		}
+	}
+	if _, err := random.Execute(ctx, `{"min":-9.2e18,"max":9.2e18}`); err != nil {
+		t.Errorf("random_number over a span wider than int64: %v", err)
+	}
+}
+
+func TestTools_UniqueNames(t *testing.T) {
+	seen := map[string]bool{}
+	for _, tool := range Tools() {
+		if seen[tool.Name()] {
+			t.Errorf("duplicate tool name %q", tool.Name())
+		}
+		seen[tool.Name()] = true
+	}
+}
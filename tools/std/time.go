@@ -0,0 +1,141 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// ErrInvalidDate is returned when a date argument cannot be parsed.
+var ErrInvalidDate = errors.New("std: invalid date")
+
+// now is the clock used by the time tools; replaced in tests.
+var now = time.Now
+
+// dateLayouts are the accepted input formats, tried in order.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// CurrentTime returns a tool that reports the current time in a given IANA timezone.
+func CurrentTime() agentkit.Tool {
+	return agentkit.NewTool("current_time").
+		WithDescription("Get the current date and time, optionally in a specific IANA timezone such as \"Europe/Berlin\".").
+		WithParameter("timezone", agentkit.String().Optional().WithDescription("IANA timezone name; defaults to UTC")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			loc, err := locationArg(args)
+			if err != nil {
+				return nil, err
+			}
+			return describeTime(now().In(loc)), nil
+		}).
+		Build()
+}
+
+// DateAdd returns a tool that adds a duration to a date.
+func DateAdd() agentkit.Tool {
+	return agentkit.NewTool("date_add").
+		WithDescription("Add (or subtract, using negative values) years, months, days, hours and minutes to a date.").
+		WithParameter("date", agentkit.String().Required().WithDescription("Start date as YYYY-MM-DD or RFC 3339; use \"now\" for the current time")).
+		WithParameter("years", agentkit.Integer().Optional()).
+		WithParameter("months", agentkit.Integer().Optional()).
+		WithParameter("days", agentkit.Integer().Optional()).
+		WithParameter("hours", agentkit.Integer().Optional()).
+		WithParameter("minutes", agentkit.Integer().Optional()).
+		WithParameter("timezone", agentkit.String().Optional().WithDescription("IANA timezone used for dates without an offset; defaults to UTC")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			loc, err := locationArg(args)
+			if err != nil {
+				return nil, err
+			}
+			start, err := parseDate(stringArg(args, "date"), loc)
+			if err != nil {
+				return nil, err
+			}
+			result := start.AddDate(intArg(args, "years"), intArg(args, "months"), intArg(args, "days"))
+			result = result.Add(time.Duration(intArg(args, "hours"))*time.Hour +
+				time.Duration(intArg(args, "minutes"))*time.Minute)
+			return describeTime(result), nil
+		}).
+		Build()
+}
+
+// DateDiff returns a tool that computes the difference between two dates.
+func DateDiff() agentkit.Tool {
+	return agentkit.NewTool("date_diff").
+		WithDescription("Compute the time between two dates (end minus start).").
+		WithParameter("start", agentkit.String().Required().WithDescription("Start date as YYYY-MM-DD or RFC 3339; use \"now\" for the current time")).
+		WithParameter("end", agentkit.String().Required().WithDescription("End date as YYYY-MM-DD or RFC 3339; use \"now\" for the current time")).
+		WithParameter("timezone", agentkit.String().Optional().WithDescription("IANA timezone used for dates without an offset; defaults to UTC")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			loc, err := locationArg(args)
+			if err != nil {
+				return nil, err
+			}
+			start, err := parseDate(stringArg(args, "start"), loc)
+			if err != nil {
+				return nil, err
+			}
+			end, err := parseDate(stringArg(args, "end"), loc)
+			if err != nil {
+				return nil, err
+			}
+			diff := end.Sub(start)
+			return map[string]any{
+				"days":    math.Trunc(diff.Hours()/24*1000) / 1000,
+				"hours":   diff.Hours(),
+				"minutes": diff.Minutes(),
+				"seconds": diff.Seconds(),
+				"human":   diff.String(),
+			}, nil
+		}).
+		Build()
+}
+
+func describeTime(t time.Time) map[string]any {
+	zone, offset := t.Zone()
+	return map[string]any{
+		"iso":             t.Format(time.RFC3339),
+		"date":            t.Format(time.DateOnly),
+		"time":            t.Format(time.TimeOnly),
+		"weekday":         t.Weekday().String(),
+		"timezone":        t.Location().String(),
+		"zone":            zone,
+		"utc_offset_secs": offset,
+		"unix":            t.Unix(),
+	}
+}
+
+func locationArg(args map[string]any) (*time.Location, error) {
+	name := stringArg(args, "timezone")
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("std: unknown timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	if value == "" || value == "now" {
+		return now().In(loc), nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q (use YYYY-MM-DD or RFC 3339)", ErrInvalidDate, value)
+}
@@ -0,0 +1,188 @@
+package std
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// ErrUnknownUnit is returned when a unit is not supported or units are incompatible.
+var ErrUnknownUnit = errors.New("std: unknown unit")
+
+type unitDef struct {
+	category string
+	factor   float64 // multiplier to the category's base unit
+}
+
+// units maps unit names and aliases to their definition. Temperature is handled separately.
+var units = map[string]unitDef{
+	// Length (base: meter)
+	"mm": {"length", 0.001}, "millimeter": {"length", 0.001},
+	"cm": {"length", 0.01}, "centimeter": {"length", 0.01},
+	"m": {"length", 1}, "meter": {"length", 1},
+	"km": {"length", 1000}, "kilometer": {"length", 1000},
+	"in": {"length", 0.0254}, "inch": {"length", 0.0254},
+	"ft": {"length", 0.3048}, "foot": {"length", 0.3048}, "feet": {"length", 0.3048},
+	"yd": {"length", 0.9144}, "yard": {"length", 0.9144},
+	"mi": {"length", 1609.344}, "mile": {"length", 1609.344},
+	"nmi": {"length", 1852}, "nautical_mile": {"length", 1852},
+
+	// Mass (base: kilogram)
+	"mg": {"mass", 1e-6}, "milligram": {"mass", 1e-6},
+	"g": {"mass", 0.001}, "gram": {"mass", 0.001},
+	"kg": {"mass", 1}, "kilogram": {"mass", 1},
+	"t": {"mass", 1000}, "tonne": {"mass", 1000},
+	"oz": {"mass", 0.028349523125}, "ounce": {"mass", 0.028349523125},
+	"lb": {"mass", 0.45359237}, "pound": {"mass", 0.45359237},
+	"st": {"mass", 6.35029318}, "stone": {"mass", 6.35029318},
+
+	// Volume (base: liter)
+	"ml": {"volume", 0.001}, "milliliter": {"volume", 0.001},
+	"l": {"volume", 1}, "liter": {"volume", 1},
+	"m3": {"volume", 1000}, "cubic_meter": {"volume", 1000},
+	"tsp": {"volume", 0.00492892159375}, "teaspoon": {"volume", 0.00492892159375},
+	"tbsp": {"volume", 0.01478676478125}, "tablespoon": {"volume", 0.01478676478125},
+	"floz": {"volume", 0.0295735295625}, "fluid_ounce": {"volume", 0.0295735295625},
+	"cup": {"volume", 0.2365882365},
+	"pt":  {"volume", 0.473176473}, "pint": {"volume", 0.473176473},
+	"qt": {"volume", 0.946352946}, "quart": {"volume", 0.946352946},
+	"gal": {"volume", 3.785411784}, "gallon": {"volume", 3.785411784},
+
+	// Time (base: second)
+	"ms": {"time", 0.001}, "millisecond": {"time", 0.001},
+	"s": {"time", 1}, "sec": {"time", 1}, "second": {"time", 1},
+	"min": {"time", 60}, "minute": {"time", 60},
+	"h": {"time", 3600}, "hr": {"time", 3600}, "hour": {"time", 3600},
+	"d": {"time", 86400}, "day": {"time", 86400},
+	"wk": {"time", 604800}, "week": {"time", 604800},
+
+	// Digital storage (base: byte)
+	"b": {"data", 1}, "byte": {"data", 1},
+	"kb": {"data", 1e3}, "kilobyte": {"data", 1e3},
+	"mb": {"data", 1e6}, "megabyte": {"data", 1e6},
+	"gb": {"data", 1e9}, "gigabyte": {"data", 1e9},
+	"tb": {"data", 1e12}, "terabyte": {"data", 1e12},
+	"kib": {"data", 1 << 10}, "kibibyte": {"data", 1 << 10},
+	"mib": {"data", 1 << 20}, "mebibyte": {"data", 1 << 20},
+	"gib": {"data", 1 << 30}, "gibibyte": {"data", 1 << 30},
+	"tib": {"data", 1 << 40}, "tebibyte": {"data", 1 << 40},
+
+	// Speed (base: meter per second)
+	"mps": {"speed", 1}, "m/s": {"speed", 1},
+	"kph": {"speed", 1000.0 / 3600}, "km/h": {"speed", 1000.0 / 3600},
+	"mph":  {"speed", 1609.344 / 3600},
+	"knot": {"speed", 1852.0 / 3600}, "kn": {"speed", 1852.0 / 3600},
+}
+
+var temperatureUnits = map[string]string{
+	"c": "c", "celsius": "c",
+	"f": "f", "fahrenheit": "f",
+	"k": "k", "kelvin": "k",
+}
+
+// UnitConverter returns a tool that converts values between units.
+func UnitConverter() agentkit.Tool {
+	return agentkit.NewTool("convert_units").
+		WithDescription("Convert a value between units of length, mass, volume, time, digital storage, speed or temperature, e.g. 5 mi to km or 72 F to C.").
+		WithParameter("value", agentkit.Number().Required()).
+		WithParameter("from", agentkit.String().Required().WithDescription("Source unit, e.g. \"mi\", \"lb\", \"F\", \"GiB\"")).
+		WithParameter("to", agentkit.String().Required().WithDescription("Target unit")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			value, _ := args["value"].(float64)
+			from, to := stringArg(args, "from"), stringArg(args, "to")
+			result, err := ConvertUnits(value, from, to)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{
+				"value":  value,
+				"from":   from,
+				"to":     to,
+				"result": result,
+			}, nil
+		}).
+		Build()
+}
+
+// ConvertUnits converts value from one unit to another.
+func ConvertUnits(value float64, from, to string) (float64, error) {
+	fromKey, toKey := normalizeUnit(from), normalizeUnit(to)
+
+	if fromTemp, ok := temperatureUnits[fromKey]; ok {
+		toTemp, ok := temperatureUnits[toKey]
+		if !ok {
+			return 0, fmt.Errorf("%w: cannot convert temperature to %q", ErrUnknownUnit, to)
+		}
+		return convertTemperature(value, fromTemp, toTemp), nil
+	}
+
+	fromDef, ok := units[fromKey]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownUnit, from, supportedUnits())
+	}
+	toDef, ok := units[toKey]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q (supported: %s)", ErrUnknownUnit, to, supportedUnits())
+	}
+	if fromDef.category != toDef.category {
+		return 0, fmt.Errorf("%w: cannot convert %s (%s) to %s (%s)", ErrUnknownUnit, from, fromDef.category, to, toDef.category)
+	}
+	return value * fromDef.factor / toDef.factor, nil
+}
+
+func convertTemperature(value float64, from, to string) float64 {
+	var celsius float64
+	switch from {
+	case "c":
+		celsius = value
+	case "f":
+		celsius = (value - 32) * 5 / 9
+	case "k":
+		celsius = value - 273.15
+	}
+	switch to {
+	case "f":
+		return celsius*9/5 + 32
+	case "k":
+		return celsius + 273.15
+	default:
+		return celsius
+	}
+}
+
+func normalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	unit = strings.TrimPrefix(unit, "°")
+	unit = strings.ReplaceAll(unit, " ", "_")
+	if _, ok := units[unit]; ok {
+		return unit
+	}
+	if _, ok := temperatureUnits[unit]; ok {
+		return unit
+	}
+	// Accept simple plurals such as "miles", "kilograms" or "inches".
+	for _, suffix := range []string{"s", "es"} {
+		if singular := strings.TrimSuffix(unit, suffix); singular != unit {
+			if _, ok := units[singular]; ok {
+				return singular
+			}
+		}
+	}
+	return unit
+}
+
+func supportedUnits() string {
+	names := make([]string, 0, len(units)+len(temperatureUnits))
+	for name := range units {
+		names = append(names, name)
+	}
+	for name := range temperatureUnits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,386 @@
+package table
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Condition tests one column of a row.
+type Condition struct {
+	Column string `json:"column" required:"true" desc:"Column to test"`
+	Op     string `json:"op" required:"true" enum:"eq,ne,gt,gte,lt,lte,contains,starts_with,in,empty,not_empty" desc:"Comparison; numbers compare numerically, contains and starts_with ignore case, in takes a comma-separated list"`
+	Value  string `json:"value" desc:"Value to compare with; unused for empty and not_empty"`
+}
+
+// Filter selects, sorts and limits the rows of a table.
+type Filter struct {
+	Table      string      `json:"table" required:"true" desc:"Table to filter"`
+	Where      []Condition `json:"where" desc:"Conditions every kept row must meet; none keeps all rows"`
+	Columns    []string    `json:"columns" desc:"Columns to keep, in order; none keeps all"`
+	SortBy     string      `json:"sort_by" desc:"Column to sort by"`
+	Descending bool        `json:"descending" desc:"Sort from largest to smallest"`
+	Limit      int         `json:"limit" desc:"Keep at most this many rows; 0 keeps all"`
+	Into       string      `json:"into" desc:"Name for the result table; defaults to <table>_filtered"`
+}
+
+// Aggregation computes one value per group.
+type Aggregation struct {
+	Func   string `json:"func" required:"true" enum:"count,count_distinct,sum,avg,min,max" desc:"count counts rows, or non-empty cells when column is set"`
+	Column string `json:"column" desc:"Column to aggregate; optional for count"`
+	As     string `json:"as" desc:"Result column name; defaults to <func>_<column>"`
+}
+
+// Aggregate groups the rows of a table and aggregates each group.
+type Aggregate struct {
+	Table        string        `json:"table" required:"true" desc:"Table to aggregate"`
+	GroupBy      []string      `json:"group_by" desc:"Columns to group by; none aggregates the whole table into one row"`
+	Aggregations []Aggregation `json:"aggregations" required:"true" desc:"Values to compute for each group"`
+	Into         string        `json:"into" desc:"Name for the result table; defaults to <table>_grouped"`
+}
+
+// Pivot spreads the values of one column into columns.
+type Pivot struct {
+	Table   string `json:"table" required:"true" desc:"Table to pivot"`
+	Index   string `json:"index" required:"true" desc:"Column whose values become the rows"`
+	Columns string `json:"columns" required:"true" desc:"Column whose values become the columns"`
+	Values  string `json:"values" desc:"Column to aggregate into each cell; optional for count"`
+	Func    string `json:"func" required:"true" enum:"count,count_distinct,sum,avg,min,max" desc:"How to combine the values in a cell"`
+	Into    string `json:"into" desc:"Name for the result table; defaults to <table>_pivot"`
+}
+
+// Filter stores the rows of f.Table meeting every condition of f.Where as a
+// new table.
+func (w *Workspace) Filter(f Filter) (*Summary, error) {
+	t, err := w.get(f.Table)
+	if err != nil {
+		return nil, err
+	}
+	type test struct {
+		column int
+		match  func(string) bool
+	}
+	tests := make([]test, len(f.Where))
+	for i, cond := range f.Where {
+		column, err := t.column(cond.Column)
+		if err != nil {
+			return nil, err
+		}
+		match, err := matcher(cond)
+		if err != nil {
+			return nil, err
+		}
+		tests[i] = test{column, match}
+	}
+
+	rows := make([][]string, 0, len(t.Rows))
+rows:
+	for _, row := range t.Rows {
+		for _, test := range tests {
+			if !test.match(row[test.column]) {
+				continue rows
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if f.SortBy != "" {
+		column, err := t.column(f.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		slices.SortStableFunc(rows, func(a, b []string) int {
+			if f.Descending {
+				return compareCells(b[column], a[column])
+			}
+			return compareCells(a[column], b[column])
+		})
+	}
+	if f.Limit > 0 && len(rows) > f.Limit {
+		rows = rows[:f.Limit]
+	}
+
+	result := &Table{Name: resultName(f.Into, f.Table, "filtered"), Columns: t.Columns, Rows: rows}
+	if len(f.Columns) > 0 {
+		if result, err = project(result, f.Columns); err != nil {
+			return nil, err
+		}
+	}
+	return w.Add(result)
+}
+
+// matcher returns the test for cond.
+func matcher(cond Condition) (func(string) bool, error) {
+	value := cond.Value
+	switch cond.Op {
+	case "eq":
+		return func(cell string) bool { return equalCells(cell, value) }, nil
+	case "ne":
+		return func(cell string) bool { return !equalCells(cell, value) }, nil
+	case "gt":
+		return func(cell string) bool { return cell != "" && compareCells(cell, value) > 0 }, nil
+	case "gte":
+		return func(cell string) bool { return cell != "" && compareCells(cell, value) >= 0 }, nil
+	case "lt":
+		return func(cell string) bool { return cell != "" && compareCells(cell, value) < 0 }, nil
+	case "lte":
+		return func(cell string) bool { return cell != "" && compareCells(cell, value) <= 0 }, nil
+	case "contains":
+		value = strings.ToLower(value)
+		return func(cell string) bool { return strings.Contains(strings.ToLower(cell), value) }, nil
+	case "starts_with":
+		value = strings.ToLower(value)
+		return func(cell string) bool { return strings.HasPrefix(strings.ToLower(cell), value) }, nil
+	case "in":
+		values := strings.Split(value, ",")
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		return func(cell string) bool {
+			return slices.ContainsFunc(values, func(v string) bool { return equalCells(cell, v) })
+		}, nil
+	case "empty":
+		return func(cell string) bool { return strings.TrimSpace(cell) == "" }, nil
+	case "not_empty":
+		return func(cell string) bool { return strings.TrimSpace(cell) != "" }, nil
+	}
+	return nil, fmt.Errorf("table: unknown condition op %q", cond.Op)
+}
+
+// equalCells compares numbers by value, so "10" equals "10.0".
+func equalCells(a, b string) bool {
+	if x, ok := parseNumber(a); ok {
+		if y, ok := parseNumber(b); ok {
+			return x.Cmp(y) == 0
+		}
+	}
+	return a == b
+}
+
+// project keeps the named columns of t, in order.
+func project(t *Table, columns []string) (*Table, error) {
+	indexes := make([]int, len(columns))
+	for i, name := range columns {
+		column, err := t.column(name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = column
+	}
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i] = make([]string, len(indexes))
+		for j, column := range indexes {
+			rows[i][j] = row[column]
+		}
+	}
+	return &Table{Name: t.Name, Columns: slices.Clone(columns), Rows: rows}, nil
+}
+
+func resultName(into, source, suffix string) string {
+	if into != "" {
+		return into
+	}
+	return source + "_" + suffix
+}
+
+// group is the rows sharing the same group-by values, in order of first
+// appearance.
+type group struct {
+	key  []string
+	rows [][]string
+}
+
+func groupRows(rows [][]string, columns []int) []*group {
+	var groups []*group
+	index := make(map[string]*group)
+	for _, row := range rows {
+		key := make([]string, len(columns))
+		for i, column := range columns {
+			key[i] = row[column]
+		}
+		id := strings.Join(key, "\x00")
+		g, ok := index[id]
+		if !ok {
+			g = &group{key: key}
+			index[id] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row)
+	}
+	return groups
+}
+
+// Aggregate stores one row per group of a.Table, with the group-by columns
+// followed by the aggregations, as a new table.
+func (w *Workspace) Aggregate(a Aggregate) (*Summary, error) {
+	t, err := w.get(a.Table)
+	if err != nil {
+		return nil, err
+	}
+	if len(a.Aggregations) == 0 {
+		return nil, fmt.Errorf("table: aggregate %s: at least one aggregation is required", a.Table)
+	}
+	groupColumns := make([]int, len(a.GroupBy))
+	for i, name := range a.GroupBy {
+		if groupColumns[i], err = t.column(name); err != nil {
+			return nil, err
+		}
+	}
+	valueColumns := make([]int, len(a.Aggregations))
+	columns := slices.Clone(a.GroupBy)
+	for i, agg := range a.Aggregations {
+		if valueColumns[i], err = aggregationColumn(t, agg.Func, agg.Column); err != nil {
+			return nil, err
+		}
+		name := agg.As
+		if name == "" {
+			name = strings.TrimSuffix(agg.Func+"_"+agg.Column, "_")
+		}
+		columns = append(columns, name)
+	}
+
+	groups := groupRows(t.Rows, groupColumns)
+	if len(groupColumns) == 0 && len(groups) == 0 {
+		groups = []*group{{}} // Aggregating an empty table still yields a row
+	}
+	rows := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		row := slices.Clone(g.key)
+		for i, agg := range a.Aggregations {
+			value, err := aggregate(agg.Func, g.rows, valueColumns[i])
+			if err != nil {
+				return nil, fmt.Errorf("%w (%s of %s)", err, agg.Func, agg.Column)
+			}
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+	return w.Add(&Table{Name: resultName(a.Into, a.Table, "grouped"), Columns: columns, Rows: rows})
+}
+
+// aggregationColumn returns the column fn aggregates, or -1 for counting
+// rows.
+func aggregationColumn(t *Table, fn, column string) (int, error) {
+	if column == "" {
+		if fn != "count" {
+			return 0, fmt.Errorf("table: %s needs a column", fn)
+		}
+		return -1, nil
+	}
+	return t.column(column)
+}
+
+// aggregate computes fn over column of rows. Empty cells are skipped; sum
+// and avg fail on other non-numeric cells, while min and max compare them
+// as strings.
+func aggregate(fn string, rows [][]string, column int) (string, error) {
+	if column < 0 {
+		return formatInt(len(rows)), nil
+	}
+	var values []string
+	for _, row := range rows {
+		if strings.TrimSpace(row[column]) != "" {
+			values = append(values, row[column])
+		}
+	}
+	switch fn {
+	case "count":
+		return formatInt(len(values)), nil
+	case "count_distinct":
+		seen := make(map[string]bool, len(values))
+		for _, v := range values {
+			seen[v] = true
+		}
+		return formatInt(len(seen)), nil
+	case "sum", "avg":
+		if len(values) == 0 {
+			return "", nil
+		}
+		total := new(big.Rat)
+		for _, v := range values {
+			n, ok := parseNumber(v)
+			if !ok {
+				return "", fmt.Errorf("%w: %q", ErrNotNumeric, v)
+			}
+			total.Add(total, n)
+		}
+		if fn == "avg" {
+			total.Quo(total, new(big.Rat).SetInt64(int64(len(values))))
+		}
+		return formatNumber(total), nil
+	case "min", "max":
+		if len(values) == 0 {
+			return "", nil
+		}
+		best := values[0]
+		for _, v := range values[1:] {
+			if c := compareCells(v, best); fn == "min" && c < 0 || fn == "max" && c > 0 {
+				best = v
+			}
+		}
+		return best, nil
+	}
+	return "", fmt.Errorf("table: unknown aggregation %q", fn)
+}
+
+// Pivot stores a table with a row per value of p.Index and a column per
+// value of p.Columns, sorted, each cell aggregating p.Values with p.Func.
+func (w *Workspace) Pivot(p Pivot) (*Summary, error) {
+	t, err := w.get(p.Table)
+	if err != nil {
+		return nil, err
+	}
+	index, err := t.column(p.Index)
+	if err != nil {
+		return nil, err
+	}
+	spread, err := t.column(p.Columns)
+	if err != nil {
+		return nil, err
+	}
+	values, err := aggregationColumn(t, p.Func, p.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers []string
+	seen := make(map[string]bool)
+	for _, row := range t.Rows {
+		if !seen[row[spread]] {
+			seen[row[spread]] = true
+			headers = append(headers, row[spread])
+		}
+	}
+	sort.SliceStable(headers, func(i, j int) bool { return compareCells(headers[i], headers[j]) < 0 })
+	if len(headers)+1 > w.cfg.MaxColumns {
+		return nil, fmt.Errorf("%w: %s has %d distinct values, limit %d columns", ErrTooLarge, p.Columns, len(headers), w.cfg.MaxColumns)
+	}
+
+	groups := groupRows(t.Rows, []int{index})
+	sort.SliceStable(groups, func(i, j int) bool { return compareCells(groups[i].key[0], groups[j].key[0]) < 0 })
+	rows := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		cells := make(map[string][][]string)
+		for _, row := range g.rows {
+			cells[row[spread]] = append(cells[row[spread]], row)
+		}
+		row := []string{g.key[0]}
+		for _, header := range headers {
+			if len(cells[header]) == 0 {
+				row = append(row, "")
+				continue
+			}
+			value, err := aggregate(p.Func, cells[header], values)
+			if err != nil {
+				return nil, fmt.Errorf("%w (%s of %s)", err, p.Func, p.Values)
+			}
+			row = append(row, value)
+		}
+		rows = append(rows, row)
+	}
+	columns := append([]string{p.Index}, headers...)
+	return w.Add(&Table{Name: resultName(p.Into, p.Table, "pivot"), Columns: columns, Rows: rows})
+}
@@ -0,0 +1,355 @@
+// Package table provides in-memory table tools (load_csv, filter_table,
+// aggregate_table, pivot_table, export_table, list_tables and drop_table)
+// so data-wrangling agents compute over rows deterministically instead of
+// doing the arithmetic in the model:
+//
+//	ws := table.New(table.Config{})
+//	ws.Register(agent)
+//
+// Tables live in a Workspace under names the model chooses. Every operation
+// stores its result as a new table, so steps chain: filter "sales" into
+// "eu_sales", then aggregate "eu_sales" by month. Cells are strings; numbers
+// are parsed when compared or aggregated and summed exactly, so 0.1 + 0.2 is
+// 0.3. Results show the model a preview of their first rows; export_table
+// returns a whole table as CSV, JSON or Markdown.
+package table
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Default limits for Config.
+const (
+	DefaultMaxRows        = 100_000 // rows in a table
+	DefaultMaxColumns     = 200     // columns in a table, including pivot results
+	DefaultMaxTables      = 32      // tables in a workspace
+	DefaultPreviewRows    = 20      // rows shown after each operation
+	DefaultMaxExportBytes = 1 << 20 // 1 MiB returned by export_table
+)
+
+// Common errors.
+var (
+	ErrTableNotFound  = errors.New("table: table not found")
+	ErrColumnNotFound = errors.New("table: column not found")
+	ErrNotNumeric     = errors.New("table: value is not a number")
+	ErrTooLarge       = errors.New("table: exceeds the size limit")
+)
+
+// Config configures a Workspace.
+type Config struct {
+	MaxRows        int // Larger loads and results are refused
+	MaxColumns     int // Wider loads and pivots are refused
+	MaxTables      int // Further tables are refused until one is dropped
+	PreviewRows    int // Rows returned with each result
+	MaxExportBytes int // Larger exports are refused
+	// Open reads the CSV named by load_csv's source argument, e.g. a file
+	// in a sandbox or an object in a bucket. Without it, load_csv takes
+	// inline CSV text only.
+	Open func(ctx context.Context, source string) (io.ReadCloser, error)
+}
+
+// Table is a named table of string cells.
+type Table struct {
+	Name    string     `json:"name"`
+	Columns []string   `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// column returns the index of the named column.
+func (t *Table) column(name string) (int, error) {
+	if i := slices.Index(t.Columns, name); i >= 0 {
+		return i, nil
+	}
+	return 0, fmt.Errorf("%w: %q in %s (columns: %s)", ErrColumnNotFound, name, t.Name, strings.Join(t.Columns, ", "))
+}
+
+func (t *Table) clone() *Table {
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i] = slices.Clone(row)
+	}
+	return &Table{Name: t.Name, Columns: slices.Clone(t.Columns), Rows: rows}
+}
+
+// Summary describes a table and previews its first rows.
+type Summary struct {
+	Name      string     `json:"name"`
+	Columns   []string   `json:"columns"`
+	RowCount  int        `json:"row_count"`
+	Rows      [][]string `json:"rows,omitempty"`
+	Truncated bool       `json:"truncated,omitempty"` // More rows than shown
+}
+
+// Workspace holds the tables an agent works on. It is safe for concurrent
+// use; tables are never modified once stored.
+type Workspace struct {
+	cfg    Config
+	mu     sync.Mutex
+	tables map[string]*Table
+}
+
+// New creates an empty workspace, filling in defaults for unset limits.
+func New(cfg Config) *Workspace {
+	if cfg.MaxRows <= 0 {
+		cfg.MaxRows = DefaultMaxRows
+	}
+	if cfg.MaxColumns <= 0 {
+		cfg.MaxColumns = DefaultMaxColumns
+	}
+	if cfg.MaxTables <= 0 {
+		cfg.MaxTables = DefaultMaxTables
+	}
+	if cfg.PreviewRows <= 0 {
+		cfg.PreviewRows = DefaultPreviewRows
+	}
+	if cfg.MaxExportBytes <= 0 {
+		cfg.MaxExportBytes = DefaultMaxExportBytes
+	}
+	return &Workspace{cfg: cfg, tables: make(map[string]*Table)}
+}
+
+// Add stores a copy of t, replacing any table with the same name. Rows
+// shorter than the header are padded with empty cells.
+func (w *Workspace) Add(t *Table) (*Summary, error) {
+	if t.Name == "" {
+		return nil, errors.New("table: a table name is required")
+	}
+	t = t.clone()
+	if len(t.Columns) > w.cfg.MaxColumns {
+		return nil, fmt.Errorf("%w: %d columns, limit %d", ErrTooLarge, len(t.Columns), w.cfg.MaxColumns)
+	}
+	if len(t.Rows) > w.cfg.MaxRows {
+		return nil, fmt.Errorf("%w: %d rows, limit %d", ErrTooLarge, len(t.Rows), w.cfg.MaxRows)
+	}
+	t.Columns = uniqueColumns(t.Columns)
+	for i, row := range t.Rows {
+		if len(row) > len(t.Columns) {
+			return nil, fmt.Errorf("table: row %d of %s has %d cells, but there are %d columns", i+1, t.Name, len(row), len(t.Columns))
+		}
+		for len(row) < len(t.Columns) {
+			row = append(row, "")
+		}
+		t.Rows[i] = row
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.tables[t.Name]; !ok && len(w.tables) >= w.cfg.MaxTables {
+		return nil, fmt.Errorf("%w: %d tables, limit %d; drop one first", ErrTooLarge, len(w.tables), w.cfg.MaxTables)
+	}
+	w.tables[t.Name] = t
+	return w.summarize(t), nil
+}
+
+// uniqueColumns names empty columns after their position and numbers
+// repeated names, so every column can be referred to.
+func uniqueColumns(columns []string) []string {
+	seen := make(map[string]bool, len(columns))
+	for i, name := range columns {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		for base, n := name, 2; seen[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	return columns
+}
+
+// Table returns a copy of the named table.
+func (w *Workspace) Table(name string) (*Table, error) {
+	t, err := w.get(name)
+	if err != nil {
+		return nil, err
+	}
+	return t.clone(), nil
+}
+
+func (w *Workspace) get(name string) (*Table, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	t, ok := w.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrTableNotFound, name)
+	}
+	return t, nil
+}
+
+// List returns a summary of every table, without rows, sorted by name.
+func (w *Workspace) List() []Summary {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	list := make([]Summary, 0, len(w.tables))
+	for _, t := range w.tables {
+		list = append(list, Summary{Name: t.Name, Columns: t.Columns, RowCount: len(t.Rows)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Drop removes the named table, reporting whether it existed.
+func (w *Workspace) Drop(name string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.tables[name]
+	delete(w.tables, name)
+	return ok
+}
+
+// summarize describes t with a preview of its first rows.
+func (w *Workspace) summarize(t *Table) *Summary {
+	preview := t.Rows[:min(len(t.Rows), w.cfg.PreviewRows)]
+	return &Summary{
+		Name:      t.Name,
+		Columns:   t.Columns,
+		RowCount:  len(t.Rows),
+		Rows:      preview,
+		Truncated: len(preview) < len(t.Rows),
+	}
+}
+
+// LoadCSV reads a CSV with a header row from r into the table name.
+// delimiter is the field separator; 0 means a comma.
+func (w *Workspace) LoadCSV(name string, r io.Reader, delimiter rune) (*Summary, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	if delimiter != 0 {
+		reader.Comma = delimiter
+	}
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("table: the CSV is empty; the first row must name the columns")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("table: read CSV: %w", err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff") // Byte order mark
+	t := &Table{Name: name, Columns: header}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("table: read CSV: %w", err)
+		}
+		if len(t.Rows) == w.cfg.MaxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrTooLarge, w.cfg.MaxRows)
+		}
+		t.Rows = append(t.Rows, record)
+	}
+	return w.Add(t)
+}
+
+// Export formats.
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json" // an array of objects keyed by column
+	FormatMarkdown = "markdown"
+)
+
+// Export returns the named table in format, up to MaxExportBytes.
+func (w *Workspace) Export(name, format string) (string, error) {
+	t, err := w.get(name)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	switch format {
+	case FormatCSV, "":
+		cw := csv.NewWriter(&buf)
+		_ = cw.Write(t.Columns)
+		_ = cw.WriteAll(t.Rows)
+		err = cw.Error()
+	case FormatJSON:
+		records := make([]map[string]string, len(t.Rows))
+		for i, row := range t.Rows {
+			records[i] = make(map[string]string, len(row))
+			for j, cell := range row {
+				records[i][t.Columns[j]] = cell
+			}
+		}
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(records)
+	case FormatMarkdown:
+		writeMarkdown(&buf, t)
+	default:
+		return "", fmt.Errorf("table: unknown export format %q (use csv, json or markdown)", format)
+	}
+	if err != nil {
+		return "", err
+	}
+	if buf.Len() > w.cfg.MaxExportBytes {
+		return "", fmt.Errorf("%w: %s is %d bytes, limit %d; filter or aggregate it first", ErrTooLarge, name, buf.Len(), w.cfg.MaxExportBytes)
+	}
+	return buf.String(), nil
+}
+
+func writeMarkdown(buf *bytes.Buffer, t *Table) {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	writeRow := func(cells []string) {
+		buf.WriteString("|")
+		for _, cell := range cells {
+			buf.WriteString(" " + escape.Replace(cell) + " |")
+		}
+		buf.WriteString("\n")
+	}
+	writeRow(t.Columns)
+	buf.WriteString("|" + strings.Repeat(" --- |", len(t.Columns)) + "\n")
+	for _, row := range t.Rows {
+		writeRow(row)
+	}
+}
+
+// parseNumber parses a cell as an exact decimal, allowing surrounding
+// spaces.
+func parseNumber(s string) (*big.Rat, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(s)
+	return r, ok
+}
+
+// formatNumber formats r as a decimal with up to ten fractional digits.
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	s := strings.TrimRight(r.FloatString(10), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// compareCells orders two cells numerically when both are numbers and as
+// strings otherwise.
+func compareCells(a, b string) int {
+	if x, ok := parseNumber(a); ok {
+		if y, ok := parseNumber(b); ok {
+			return x.Cmp(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// formatInt formats a count.
+func formatInt(n int) string {
+	return strconv.Itoa(n)
+}
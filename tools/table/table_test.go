@@ -0,0 +1,183 @@
+package table
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const salesCSV = `region,month,product,amount
+EU,2024-01,widget,0.10
+EU,2024-01,gadget,0.20
+US,2024-01,widget,12.5
+EU,2024-02,widget,3
+US,2024-02,gadget,
+US,2024-02,widget,7.25
+`
+
+func newSales(t *testing.T, cfg Config) *Workspace {
+	t.Helper()
+	ws := New(cfg)
+	if _, err := ws.LoadCSV("sales", strings.NewReader(salesCSV), 0); err != nil {
+		t.Fatalf("LoadCSV() error = %v", err)
+	}
+	return ws
+}
+
+func rows(t *testing.T, ws *Workspace, name string) [][]string {
+	t.Helper()
+	table, err := ws.Table(name)
+	if err != nil {
+		t.Fatalf("Table(%q) error = %v", name, err)
+	}
+	return table.Rows
+}
+
+func TestWorkspace_FilterSortLimit(t *testing.T) {
+	ws := newSales(t, Config{})
+	summary, err := ws.Filter(Filter{
+		Table:      "sales",
+		Where:      []Condition{{Column: "amount", Op: "gte", Value: "3"}, {Column: "product", Op: "contains", Value: "WID"}},
+		Columns:    []string{"region", "amount"},
+		SortBy:     "amount",
+		Descending: true,
+		Limit:      2,
+	})
+	if err != nil {
+		t.Fatalf("Filter() error = %v", err)
+	}
+	if summary.Name != "sales_filtered" || summary.RowCount != 2 {
+		t.Errorf("summary = %+v", summary)
+	}
+	want := [][]string{{"US", "12.5"}, {"US", "7.25"}}
+	if got := rows(t, ws, "sales_filtered"); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+
+	if _, err := ws.Filter(Filter{Table: "sales", Where: []Condition{{Column: "price", Op: "eq"}}}); !errors.Is(err, ErrColumnNotFound) {
+		t.Errorf("unknown column: error = %v, want ErrColumnNotFound", err)
+	}
+	if _, err := ws.Filter(Filter{Table: "missing"}); !errors.Is(err, ErrTableNotFound) {
+		t.Errorf("unknown table: error = %v, want ErrTableNotFound", err)
+	}
+}
+
+func TestWorkspace_AggregateIsExact(t *testing.T) {
+	ws := newSales(t, Config{})
+	_, err := ws.Aggregate(Aggregate{
+		Table:   "sales",
+		GroupBy: []string{"region"},
+		Aggregations: []Aggregation{
+			{Func: "count"},
+			{Func: "sum", Column: "amount"},
+			{Func: "avg", Column: "amount", As: "mean"},
+			{Func: "max", Column: "month"},
+		},
+		Into: "by_region",
+	})
+	if err != nil {
+		t.Fatalf("Aggregate() error = %v", err)
+	}
+	table, _ := ws.Table("by_region")
+	if !slices.Equal(table.Columns, []string{"region", "count", "sum_amount", "mean", "max_month"}) {
+		t.Errorf("columns = %v", table.Columns)
+	}
+	want := [][]string{
+		{"EU", "3", "3.3", "1.1", "2024-02"},
+		{"US", "3", "19.75", "9.875", "2024-02"},
+	}
+	if !slices.EqualFunc(table.Rows, want, slices.Equal) {
+		t.Errorf("rows = %v, want %v", table.Rows, want)
+	}
+
+	if _, err := ws.Aggregate(Aggregate{Table: "sales", Aggregations: []Aggregation{{Func: "sum", Column: "product"}}}); !errors.Is(err, ErrNotNumeric) {
+		t.Errorf("sum of text: error = %v, want ErrNotNumeric", err)
+	}
+}
+
+func TestWorkspace_PivotAndExport(t *testing.T) {
+	ws := newSales(t, Config{})
+	if _, err := ws.Pivot(Pivot{Table: "sales", Index: "month", Columns: "region", Values: "amount", Func: "sum"}); err != nil {
+		t.Fatalf("Pivot() error = %v", err)
+	}
+	csv, err := ws.Export("sales_pivot", FormatCSV)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if want := "month,EU,US\n2024-01,0.3,12.5\n2024-02,3,7.25\n"; csv != want {
+		t.Errorf("csv = %q, want %q", csv, want)
+	}
+	markdown, _ := ws.Export("sales_pivot", FormatMarkdown)
+	if !strings.HasPrefix(markdown, "| month | EU | US |\n| --- | --- | --- |\n") {
+		t.Errorf("markdown = %q", markdown)
+	}
+	json, _ := ws.Export("sales_pivot", FormatJSON)
+	if !strings.Contains(json, `"EU": "0.3"`) {
+		t.Errorf("json = %s", json)
+	}
+}
+
+func TestWorkspace_Limits(t *testing.T) {
+	if _, err := New(Config{MaxRows: 3}).LoadCSV("sales", strings.NewReader(salesCSV), 0); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("too many rows: error = %v, want ErrTooLarge", err)
+	}
+
+	ws := New(Config{MaxTables: 1, PreviewRows: 1, MaxExportBytes: 10})
+	summary, err := ws.LoadCSV("sales", strings.NewReader(salesCSV), 0)
+	if err != nil {
+		t.Fatalf("LoadCSV() error = %v", err)
+	}
+	if len(summary.Rows) != 1 || !summary.Truncated || summary.RowCount != 6 {
+		t.Errorf("preview = %+v", summary)
+	}
+	if _, err := ws.Filter(Filter{Table: "sales"}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("too many tables: error = %v, want ErrTooLarge", err)
+	}
+	if _, err := ws.Export("sales", FormatCSV); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("large export: error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestWorkspace_Tools(t *testing.T) {
+	ws := New(Config{Open: func(_ context.Context, source string) (io.ReadCloser, error) {
+		if source != "data/sales.csv" {
+			return nil, errors.New("not found")
+		}
+		return io.NopCloser(strings.NewReader(salesCSV)), nil
+	}})
+	tools := ws.Tools()
+	byName := make(map[string]int, len(tools))
+	for i, tool := range tools {
+		byName[tool.Name()] = i
+	}
+
+	if _, err := tools[byName["load_csv"]].Execute(context.Background(), `{"name":"sales","source":"data/sales.csv"}`); err != nil {
+		t.Fatalf("load_csv error = %v", err)
+	}
+	if _, err := tools[byName["load_csv"]].Execute(context.Background(), `{"name":"costs","csv":"item;cost\na;1,5","delimiter":";"}`); err != nil {
+		t.Fatalf("load_csv inline error = %v", err)
+	}
+	result, err := tools[byName["aggregate_table"]].Execute(context.Background(),
+		`{"table":"sales","group_by":["product"],"aggregations":[{"func":"sum","column":"amount","as":"total"}]}`)
+	if err != nil {
+		t.Fatalf("aggregate_table error = %v", err)
+	}
+	if summary := result.(*Summary); summary.RowCount != 2 || summary.Rows[0][1] != "22.85" {
+		t.Errorf("aggregate_table result = %+v", summary)
+	}
+	if _, err := tools[byName["filter_table"]].Execute(context.Background(), `{"table":"sales","where":[{"column":"amount","op":"empty"}]}`); err != nil {
+		t.Fatalf("filter_table error = %v", err)
+	}
+	if got := rows(t, ws, "sales_filtered"); len(got) != 1 || got[0][2] != "gadget" {
+		t.Errorf("empty filter rows = %v", got)
+	}
+	if _, err := tools[byName["drop_table"]].Execute(context.Background(), `{"table":"costs"}`); err != nil {
+		t.Fatalf("drop_table error = %v", err)
+	}
+	if list := ws.List(); len(list) != 3 {
+		t.Errorf("tables = %+v, want sales, sales_filtered and sales_grouped", list)
+	}
+}
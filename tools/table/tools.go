@@ -0,0 +1,158 @@
+package table
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+var readOnly = agentkit.ToolTags{Effect: agentkit.EffectReadOnly, Latency: agentkit.LatencyFast, Cost: agentkit.CostLow}
+
+// Tools returns the workspace's tools.
+func (w *Workspace) Tools() []agentkit.Tool {
+	return []agentkit.Tool{
+		w.loadCSVTool(),
+		w.listTablesTool(),
+		w.filterTool(),
+		w.aggregateTool(),
+		w.pivotTool(),
+		w.exportTool(),
+		w.dropTool(),
+	}
+}
+
+// Register adds the workspace's tools to the agent.
+func (w *Workspace) Register(agent *agentkit.Agent) {
+	for _, tool := range w.Tools() {
+		agent.AddTool(tool)
+	}
+}
+
+type loadArgs struct {
+	Name      string `json:"name" required:"true" desc:"Name for the table, e.g. \"sales\""`
+	CSV       string `json:"csv" desc:"CSV text whose first row names the columns"`
+	Source    string `json:"source" desc:"Where to read the CSV from instead of csv, e.g. a file path"`
+	Delimiter string `json:"delimiter" desc:"Field separator; defaults to a comma"`
+}
+
+func (w *Workspace) loadCSVTool() agentkit.Tool {
+	description := "Load CSV text into a named table. The first row names the columns."
+	if w.cfg.Open != nil {
+		description = "Load a CSV into a named table, from inline text or a source such as a file path. The first row names the columns."
+	}
+	return agentkit.WithTypedHandler(agentkit.NewTool("load_csv").WithDescription(description),
+		func(ctx context.Context, args loadArgs) (*Summary, error) {
+			var delimiter rune
+			if args.Delimiter != "" {
+				if utf8.RuneCountInString(args.Delimiter) != 1 {
+					return nil, fmt.Errorf("table: delimiter must be a single character, got %q", args.Delimiter)
+				}
+				delimiter, _ = utf8.DecodeRuneInString(args.Delimiter)
+			}
+			if args.Source == "" {
+				return w.LoadCSV(args.Name, strings.NewReader(args.CSV), delimiter)
+			}
+			if w.cfg.Open == nil {
+				return nil, errors.New("table: loading from a source is not enabled; pass the CSV text in csv")
+			}
+			r, err := w.cfg.Open(ctx, args.Source)
+			if err != nil {
+				return nil, fmt.Errorf("table: open %s: %w", args.Source, err)
+			}
+			defer r.Close()
+			return w.LoadCSV(args.Name, r, delimiter)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Loading %v...", args["name"])
+		}).
+		WithTags(readOnly).
+		Build()
+}
+
+func (w *Workspace) listTablesTool() agentkit.Tool {
+	return agentkit.NewTool("list_tables").
+		WithDescription("List the loaded tables with their columns and row counts.").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return w.List(), nil
+		}).
+		WithTags(readOnly).
+		Build()
+}
+
+func (w *Workspace) filterTool() agentkit.Tool {
+	return agentkit.WithTypedHandler(agentkit.NewTool("filter_table").
+		WithDescription("Select the rows of a table that meet conditions, optionally keeping some columns, sorting and limiting. "+
+			"The result is stored as a new table and its first rows are shown."),
+		func(ctx context.Context, f Filter) (*Summary, error) {
+			return w.Filter(f)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Filtering %v...", args["table"])
+		}).
+		WithTags(readOnly).
+		Build()
+}
+
+func (w *Workspace) aggregateTool() agentkit.Tool {
+	return agentkit.WithTypedHandler(agentkit.NewTool("aggregate_table").
+		WithDescription("Group the rows of a table and compute counts, sums, averages, minimums or maximums per group. "+
+			"Use this for any arithmetic over rows. The result is stored as a new table."),
+		func(ctx context.Context, a Aggregate) (*Summary, error) {
+			return w.Aggregate(a)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Aggregating %v...", args["table"])
+		}).
+		WithTags(readOnly).
+		Build()
+}
+
+func (w *Workspace) pivotTool() agentkit.Tool {
+	return agentkit.WithTypedHandler(agentkit.NewTool("pivot_table").
+		WithDescription("Pivot a table: one row per value of index, one column per value of columns, "+
+			"each cell aggregating values. The result is stored as a new table."),
+		func(ctx context.Context, p Pivot) (*Summary, error) {
+			return w.Pivot(p)
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Pivoting %v...", args["table"])
+		}).
+		WithTags(readOnly).
+		Build()
+}
+
+type exportArgs struct {
+	Table  string `json:"table" required:"true" desc:"Table to export"`
+	Format string `json:"format" enum:"csv,json,markdown" desc:"Output format; defaults to csv"`
+}
+
+func (w *Workspace) exportTool() agentkit.Tool {
+	return agentkit.WithTypedHandler(agentkit.NewTool("export_table").
+		WithDescription(fmt.Sprintf("Return a whole table as CSV, JSON or a Markdown table, up to %d bytes.", w.cfg.MaxExportBytes)),
+		func(ctx context.Context, args exportArgs) (string, error) {
+			return w.Export(args.Table, args.Format)
+		}).
+		WithTags(readOnly).
+		Build()
+}
+
+type dropArgs struct {
+	Table string `json:"table" required:"true" desc:"Table to remove"`
+}
+
+func (w *Workspace) dropTool() agentkit.Tool {
+	return agentkit.WithTypedHandler(agentkit.NewTool("drop_table").
+		WithDescription("Remove a table that is no longer needed."),
+		func(ctx context.Context, args dropArgs) (map[string]any, error) {
+			if !w.Drop(args.Table) {
+				return nil, fmt.Errorf("%w: %q", ErrTableNotFound, args.Table)
+			}
+			return map[string]any{"dropped": args.Table}, nil
+		}).
+		WithTags(readOnly).
+		Build()
+}
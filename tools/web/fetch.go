@@ -0,0 +1,228 @@
+// Package web provides tools for agents that read the web.
+//
+//	agent.AddTool(web.FetchPage(web.FetchConfig{}))
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// Default limits for FetchConfig.
+const (
+	DefaultMaxBytes  int64 = 2 << 20 // 2 MiB of response body
+	DefaultMaxChars        = 20000   // characters of extracted text
+	DefaultTimeout         = 15 * time.Second
+	DefaultUserAgent       = "agentkit-fetch/1.0"
+)
+
+// Common fetch errors.
+var (
+	ErrUnsupportedScheme      = errors.New("web: only http and https URLs are supported")
+	ErrDisallowedByRobots     = errors.New("web: fetching disallowed by robots.txt")
+	ErrUnsupportedContentType = errors.New("web: unsupported content type")
+)
+
+// FetchConfig configures page fetching. The zero value is usable.
+type FetchConfig struct {
+	Client       *http.Client  // Defaults to a client with Timeout
+	UserAgent    string        // Sent with every request and matched against robots.txt
+	Timeout      time.Duration // Per-request timeout when Client is nil
+	MaxBytes     int64         // Maximum response body size read
+	MaxChars     int           // Maximum extracted text length; longer text is truncated
+	IgnoreRobots bool          // Skip robots.txt checks
+	// AllowPrivateNetworks lets the fetcher reach loopback, private and
+	// link-local addresses. It is off by default, so a model can't use the
+	// tool to probe internal services or cloud metadata endpoints. Without
+	// it, the default client connects directly rather than through
+	// HTTP_PROXY, and hosts of a custom Client are checked before each
+	// request and redirect.
+	AllowPrivateNetworks bool
+}
+
+// Page is the cleaned result of fetching a URL.
+type Page struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title,omitempty"`
+	Text        string   `json:"text"`
+	Chunks      []string `json:"chunks,omitempty"`
+	ContentType string   `json:"content_type"`
+	Truncated   bool     `json:"truncated"`
+}
+
+// Fetcher downloads pages and extracts readable text.
+type Fetcher struct {
+	cfg          FetchConfig
+	client       *http.Client // Checks every redirect hop against robots.txt
+	robotsClient *http.Client // Fetches robots.txt
+	robots       robotsCache
+}
+
+// NewFetcher creates a fetcher, filling in defaults for unset config fields.
+func NewFetcher(cfg FetchConfig) *Fetcher {
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.MaxChars <= 0 {
+		cfg.MaxChars = DefaultMaxChars
+	}
+	base := http.Client{Timeout: cfg.Timeout}
+	if cfg.Client != nil {
+		base = *cfg.Client
+	} else if !cfg.AllowPrivateNetworks {
+		base.Transport = guardedTransport()
+	}
+	f := &Fetcher{cfg: cfg}
+	client, robotsClient := base, base
+	client.CheckRedirect = f.checkRedirect(base.CheckRedirect, true)
+	robotsClient.CheckRedirect = f.checkRedirect(base.CheckRedirect, false)
+	f.client, f.robotsClient = &client, &robotsClient
+	return f
+}
+
+// checkRedirect vets every redirect hop like the first request: the scheme,
+// the address and, with robots set, robots.txt of the new target. next is
+// the client's own policy, if any.
+func (f *Fetcher) checkRedirect(next func(*http.Request, []*http.Request) error, robots bool) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("web: stopped after %d redirects", maxRedirects)
+		}
+		if err := f.checkTarget(req.Context(), req.URL); err != nil {
+			return err
+		}
+		if robots && !f.cfg.IgnoreRobots && !f.robots.allowed(req.Context(), f.robotsClient, f.cfg.UserAgent, req.URL) {
+			return fmt.Errorf("%w: %s", ErrDisallowedByRobots, req.URL)
+		}
+		if next != nil {
+			return next(req, via)
+		}
+		return nil
+	}
+}
+
+// checkTarget refuses non-http(s) URLs and, for custom clients, hosts that
+// resolve to blocked addresses. The default client's dialer guards the
+// addresses it connects to.
+func (f *Fetcher) checkTarget(ctx context.Context, target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return ErrUnsupportedScheme
+	}
+	if f.cfg.AllowPrivateNetworks || f.cfg.Client == nil {
+		return nil
+	}
+	return checkHost(ctx, target.Hostname())
+}
+
+// Fetch downloads rawURL and returns its readable text.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("web: invalid url %q: %w", rawURL, err)
+	}
+	if err := f.checkTarget(ctx, target); err != nil {
+		return nil, err
+	}
+
+	if !f.cfg.IgnoreRobots && !f.robots.allowed(ctx, f.robotsClient, f.cfg.UserAgent, target) {
+		return nil, fmt.Errorf("%w: %s", ErrDisallowedByRobots, rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("web: build request: %w", err)
+	}
+	req.Header.Set("User-Agent", f.cfg.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("web: fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("web: fetch %s: unexpected status %s", rawURL, resp.Status)
+	}
+
+	mediaType := "text/html"
+	if header := resp.Header.Get("Content-Type"); header != "" {
+		if parsed, _, err := mime.ParseMediaType(header); err == nil {
+			mediaType = parsed
+		}
+	}
+	isHTML := mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	if !isHTML && !strings.HasPrefix(mediaType, "text/") {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("web: read %s: %w", rawURL, err)
+	}
+	truncated := int64(len(body)) > f.cfg.MaxBytes
+	if truncated {
+		body = body[:f.cfg.MaxBytes]
+	}
+
+	page := &Page{
+		URL:         resp.Request.URL.String(),
+		ContentType: mediaType,
+		Truncated:   truncated,
+	}
+	if isHTML {
+		page.Title, page.Text = Extract(string(body))
+	} else {
+		page.Text = strings.TrimSpace(string(body))
+	}
+
+	if runes := []rune(page.Text); len(runes) > f.cfg.MaxChars {
+		page.Text = string(runes[:f.cfg.MaxChars])
+		page.Truncated = true
+	}
+
+	return page, nil
+}
+
+// FetchPage returns a fetch_page tool backed by a new Fetcher.
+func FetchPage(cfg FetchConfig) agentkit.Tool {
+	return NewFetcher(cfg).Tool()
+}
+
+// Tool returns a fetch_page tool that uses this fetcher.
+func (f *Fetcher) Tool() agentkit.Tool {
+	return agentkit.NewTool("fetch_page").
+		WithDescription("Download a web page and return its readable text (navigation, ads and scripts removed).").
+		WithParameter("url", agentkit.String().Required().WithDescription("Absolute http(s) URL to fetch")).
+		WithParameter("chunk_size", agentkit.Integer().Optional().WithDescription("If set, also split the text into chunks of at most this many characters")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			rawURL, _ := args["url"].(string)
+			page, err := f.Fetch(ctx, rawURL)
+			if err != nil {
+				return nil, err
+			}
+			if size, ok := args["chunk_size"].(float64); ok && size > 0 {
+				page.Chunks = ChunkText(page.Text, int(size))
+			}
+			return page, nil
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Reading %v...", args["url"])
+		}).
+		Build()
+}
@@ -0,0 +1,181 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+<head><title>Go &amp; Agents</title><style>body { color: red; }</style></head>
+<body>
+  <nav><a href="/">Home</a> | <a href="/about">About</a></nav>
+  <script>trackEverything();</script>
+  <article>
+    <h1>Building agents</h1>
+    <p>Agents call   <b>tools</b>.</p>
+    <ul><li>Fetch pages</li><li>Search</li></ul>
+  </article>
+  <footer>Copyright 2026</footer>
+</body>
+</html>`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("User-agent: *\nDisallow: /private\nAllow: /private/public\n"))
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(articlePage))
+	})
+	mux.HandleFunc("/private/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<p>secret</p>"))
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte{0x89, 'P', 'N', 'G'})
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("a", 5000)))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher_ExtractsReadableText(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewFetcher(FetchConfig{AllowPrivateNetworks: true})
+
+	page, err := fetcher.Fetch(context.Background(), server.URL+"/article")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if page.Title != "Go & Agents" {
+		t.Errorf("Title = %q", page.Title)
+	}
+	want := "Building agents\n\nAgents call tools.\n\n- Fetch pages\n\n- Search"
+	if page.Text != want {
+		t.Errorf("Text = %q, want %q", page.Text, want)
+	}
+	for _, noise := range []string{"Home", "trackEverything", "Copyright", "color"} {
+		if strings.Contains(page.Text, noise) {
+			t.Errorf("expected %q to be stripped from text", noise)
+		}
+	}
+}
+
+func TestFetcher_RespectsRobots(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewFetcher(FetchConfig{AllowPrivateNetworks: true})
+
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/private/data")
+	if !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots, got %v", err)
+	}
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/private/public"); err != nil {
+		t.Errorf("expected Allow rule to permit fetch, got %v", err)
+	}
+
+	ignoring := NewFetcher(FetchConfig{IgnoreRobots: true, AllowPrivateNetworks: true})
+	if _, err := ignoring.Fetch(context.Background(), server.URL+"/private/data"); err != nil {
+		t.Errorf("expected IgnoreRobots to permit fetch, got %v", err)
+	}
+}
+
+func TestFetcher_ChecksRobotsOnRedirect(t *testing.T) {
+	server := newTestServer(t)
+	redirector := httptest.NewServer(http.RedirectHandler(server.URL+"/private/data", http.StatusFound))
+	t.Cleanup(redirector.Close)
+	fetcher := NewFetcher(FetchConfig{AllowPrivateNetworks: true})
+
+	_, err := fetcher.Fetch(context.Background(), redirector.URL+"/moved")
+	if !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("expected ErrDisallowedByRobots after redirect, got %v", err)
+	}
+}
+
+func TestFetcher_BlocksPrivateAddresses(t *testing.T) {
+	server := newTestServer(t)
+
+	for name, fetcher := range map[string]*Fetcher{
+		"default client": NewFetcher(FetchConfig{IgnoreRobots: true}),
+		"custom client":  NewFetcher(FetchConfig{IgnoreRobots: true, Client: server.Client()}),
+	} {
+		if _, err := fetcher.Fetch(context.Background(), server.URL+"/article"); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("%s: expected ErrPrivateAddress for loopback, got %v", name, err)
+		}
+		if _, err := fetcher.Fetch(context.Background(), "http://169.254.169.254/latest/meta-data/"); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("%s: expected ErrPrivateAddress for link-local, got %v", name, err)
+		}
+	}
+
+	// Redirect hops are checked too, since a custom client's dialer isn't guarded.
+	fetcher := NewFetcher(FetchConfig{IgnoreRobots: true, Client: server.Client()})
+	hop := httptest.NewRequest(http.MethodGet, server.URL+"/article", nil)
+	if err := fetcher.client.CheckRedirect(hop, nil); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("expected ErrPrivateAddress for redirect hop, got %v", err)
+	}
+}
+
+func TestFetcher_Limits(t *testing.T) {
+	server := newTestServer(t)
+
+	fetcher := NewFetcher(FetchConfig{MaxBytes: 100, AllowPrivateNetworks: true})
+	page, err := fetcher.Fetch(context.Background(), server.URL+"/large")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !page.Truncated || len(page.Text) != 100 {
+		t.Errorf("expected 100 truncated bytes, got %d (truncated=%v)", len(page.Text), page.Truncated)
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/image"); !errors.Is(err, ErrUnsupportedContentType) {
+		t.Errorf("expected ErrUnsupportedContentType, got %v", err)
+	}
+	if _, err := fetcher.Fetch(context.Background(), "file:///etc/passwd"); !errors.Is(err, ErrUnsupportedScheme) {
+		t.Errorf("expected ErrUnsupportedScheme, got %v", err)
+	}
+}
+
+func TestFetchPageTool_Chunks(t *testing.T) {
+	server := newTestServer(t)
+	tool := FetchPage(FetchConfig{AllowPrivateNetworks: true})
+
+	result, err := tool.Execute(context.Background(), `{"url":"`+server.URL+`/article","chunk_size":30}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	page := result.(*Page)
+	if len(page.Chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(page.Chunks))
+	}
+	for _, chunk := range page.Chunks {
+		if len([]rune(chunk)) > 30 {
+			t.Errorf("chunk exceeds size: %q", chunk)
+		}
+	}
+}
+
+func TestParseRobots_SpecificAgentWins(t *testing.T) {
+	robots := "User-agent: *\nDisallow: /\n\nUser-agent: agentkit-fetch\nDisallow: /tmp/*.json$\n"
+	rules := parseRobots(strings.NewReader(robots), DefaultUserAgent)
+
+	if !rules.allowed("/docs") {
+		t.Error("expected /docs to be allowed for specific agent")
+	}
+	if rules.allowed("/tmp/cache/data.json") {
+		t.Error("expected wildcard rule to block /tmp/cache/data.json")
+	}
+	if !rules.allowed("/tmp/data.json.bak") {
+		t.Error("expected anchored rule not to match /tmp/data.json.bak")
+	}
+}
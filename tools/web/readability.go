@@ -0,0 +1,61 @@
+package web
+
+import (
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit/internal/htmltext"
+)
+
+// Extract returns the page title and readable main text of an HTML document,
+// with navigation, scripts and other boilerplate removed.
+func Extract(document string) (title, text string) {
+	return htmltext.Extract(document)
+}
+
+// ChunkText splits text into chunks of at most size characters, breaking on
+// paragraph boundaries where possible. A size of zero or less returns the text
+// as a single chunk.
+func ChunkText(text string, size int) []string {
+	if text == "" {
+		return nil
+	}
+	if size <= 0 || len([]rune(text)) <= size {
+		return []string{text}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		runes := []rune(paragraph)
+		if current.Len() > 0 && len([]rune(current.String()))+2+len(runes) > size {
+			flush()
+		}
+		// Hard-split paragraphs that are larger than a chunk on their own.
+		for len(runes) > size {
+			flush()
+			chunks = append(chunks, string(runes[:size]))
+			runes = runes[size:]
+		}
+		if len(runes) == 0 {
+			continue
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(string(runes))
+	}
+	flush()
+
+	return chunks
+}
@@ -0,0 +1,276 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// DefaultMaxResults is used when a search does not specify how many results to return.
+const DefaultMaxResults = 5
+
+// maxSearchResults caps what the web_search tool may request.
+const maxSearchResults = 20
+
+// ErrMissingSearchKey is returned when a search provider has no API key.
+var ErrMissingSearchKey = errors.New("web: search provider API key is required")
+
+// SearchResult is a normalized search hit.
+type SearchResult struct {
+	Title   string  `json:"title"`
+	URL     string  `json:"url"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score,omitempty"` // Relevance score, when the provider reports one
+}
+
+// SearchOptions tunes a single search.
+type SearchOptions struct {
+	MaxResults int
+}
+
+// SearchProvider is implemented by web search backends.
+type SearchProvider interface {
+	Name() string
+	Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error)
+}
+
+// WebSearch returns a web_search tool backed by provider.
+func WebSearch(provider SearchProvider) agentkit.Tool {
+	return agentkit.NewTool("web_search").
+		WithDescription("Search the web and return the most relevant results with title, URL and snippet. Use fetch_page to read a result in full.").
+		WithParameter("query", agentkit.String().Required().WithDescription("Search query")).
+		WithParameter("max_results", agentkit.Integer().Optional().WithDescription("Number of results to return (default 5, max 20)")).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			query, _ := args["query"].(string)
+			if query == "" {
+				return nil, errors.New("web: query is required")
+			}
+			opts := SearchOptions{MaxResults: DefaultMaxResults}
+			if n, ok := args["max_results"].(float64); ok && n > 0 {
+				opts.MaxResults = min(int(n), maxSearchResults)
+			}
+			results, err := provider.Search(ctx, query, opts)
+			if err != nil {
+				return nil, err
+			}
+			if len(results) > opts.MaxResults {
+				results = results[:opts.MaxResults]
+			}
+			return map[string]any{
+				"query":    query,
+				"provider": provider.Name(),
+				"results":  results,
+			}, nil
+		}).
+		WithPendingFormatter(func(_ string, args map[string]any) string {
+			return fmt.Sprintf("Searching the web for %q...", args["query"])
+		}).
+		Build()
+}
+
+func maxResults(opts SearchOptions) int {
+	if opts.MaxResults <= 0 {
+		return DefaultMaxResults
+	}
+	return opts.MaxResults
+}
+
+func httpClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// doSearchRequest executes req and decodes a JSON response into out.
+func doSearchRequest(client *http.Client, req *http.Request, provider string, out any) error {
+	resp, err := httpClient(client).Do(req)
+	if err != nil {
+		// Drop the request URL, which may carry the API key as a query parameter.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("web: %s search: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, DefaultMaxBytes))
+	if err != nil {
+		return fmt.Errorf("web: %s search: read response: %w", provider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("web: %s search: unexpected status %s: %s", provider, resp.Status, bytes.TrimSpace(body))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("web: %s search: decode response: %w", provider, err)
+	}
+	return nil
+}
+
+// TavilyProvider searches with the Tavily API.
+type TavilyProvider struct {
+	APIKey  string
+	BaseURL string // Defaults to https://api.tavily.com
+	Client  *http.Client
+}
+
+// NewTavily creates a Tavily search provider.
+func NewTavily(apiKey string) *TavilyProvider {
+	return &TavilyProvider{APIKey: apiKey, BaseURL: "https://api.tavily.com"}
+}
+
+// Name returns the provider name.
+func (p *TavilyProvider) Name() string { return "tavily" }
+
+// Search runs a Tavily search.
+func (p *TavilyProvider) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	if p.APIKey == "" {
+		return nil, ErrMissingSearchKey
+	}
+	payload, err := json.Marshal(map[string]any{
+		"query":       query,
+		"max_results": maxResults(opts),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/search", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	var resp struct {
+		Results []struct {
+			Title   string  `json:"title"`
+			URL     string  `json:"url"`
+			Content string  `json:"content"`
+			Score   float64 `json:"score"`
+		} `json:"results"`
+	}
+	if err := doSearchRequest(p.Client, req, p.Name(), &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: r.Content, Score: r.Score})
+	}
+	return results, nil
+}
+
+// BraveProvider searches with the Brave Search API.
+type BraveProvider struct {
+	APIKey  string
+	BaseURL string // Defaults to https://api.search.brave.com
+	Client  *http.Client
+}
+
+// NewBrave creates a Brave search provider.
+func NewBrave(apiKey string) *BraveProvider {
+	return &BraveProvider{APIKey: apiKey, BaseURL: "https://api.search.brave.com"}
+}
+
+// Name returns the provider name.
+func (p *BraveProvider) Name() string { return "brave" }
+
+// Search runs a Brave web search.
+func (p *BraveProvider) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	if p.APIKey == "" {
+		return nil, ErrMissingSearchKey
+	}
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("count", strconv.Itoa(maxResults(opts)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/res/v1/web/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", p.APIKey)
+
+	var resp struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	if err := doSearchRequest(p.Client, req, p.Name(), &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(resp.Web.Results))
+	for _, r := range resp.Web.Results {
+		_, snippet := Extract(r.Description) // Brave highlights matches with <strong>
+		results = append(results, SearchResult{Title: r.Title, URL: r.URL, Snippet: snippet})
+	}
+	return results, nil
+}
+
+// SerpAPIProvider searches Google through SerpAPI.
+type SerpAPIProvider struct {
+	APIKey  string
+	Engine  string // Defaults to "google"
+	BaseURL string // Defaults to https://serpapi.com
+	Client  *http.Client
+}
+
+// NewSerpAPI creates a SerpAPI search provider.
+func NewSerpAPI(apiKey string) *SerpAPIProvider {
+	return &SerpAPIProvider{APIKey: apiKey, Engine: "google", BaseURL: "https://serpapi.com"}
+}
+
+// Name returns the provider name.
+func (p *SerpAPIProvider) Name() string { return "serpapi" }
+
+// Search runs a SerpAPI search and returns the organic results.
+func (p *SerpAPIProvider) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	if p.APIKey == "" {
+		return nil, ErrMissingSearchKey
+	}
+	engine := p.Engine
+	if engine == "" {
+		engine = "google"
+	}
+	params := url.Values{}
+	params.Set("engine", engine)
+	params.Set("q", query)
+	params.Set("num", strconv.Itoa(maxResults(opts)))
+	params.Set("api_key", p.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/search.json?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		OrganicResults []struct {
+			Title   string `json:"title"`
+			Link    string `json:"link"`
+			Snippet string `json:"snippet"`
+		} `json:"organic_results"`
+	}
+	if err := doSearchRequest(p.Client, req, p.Name(), &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(resp.OrganicResults))
+	for _, r := range resp.OrganicResults {
+		results = append(results, SearchResult{Title: r.Title, URL: r.Link, Snippet: r.Snippet})
+	}
+	return results, nil
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearchProviders_NormalizeResults(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tvly-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["query"] != "golang" || body["max_results"] != float64(3) {
+			t.Errorf("unexpected tavily request: %v", body)
+		}
+		w.Write([]byte(`{"results":[{"title":"Go","url":"https://go.dev","content":"The Go language","score":0.9}]}`))
+	})
+	mux.HandleFunc("/res/v1/web/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Subscription-Token") != "brave-key" || r.URL.Query().Get("count") != "3" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"web":{"results":[{"title":"Go","url":"https://go.dev","description":"The <strong>Go</strong> language"}]}}`))
+	})
+	mux.HandleFunc("/search.json", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("api_key") != "serp-key" || q.Get("engine") != "google" || q.Get("num") != "3" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"organic_results":[{"title":"Go","link":"https://go.dev","snippet":"The Go language"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tavily := NewTavily("tvly-key")
+	tavily.BaseURL = server.URL
+	brave := NewBrave("brave-key")
+	brave.BaseURL = server.URL
+	serp := NewSerpAPI("serp-key")
+	serp.BaseURL = server.URL
+
+	for _, provider := range []SearchProvider{tavily, brave, serp} {
+		results, err := provider.Search(context.Background(), "golang", SearchOptions{MaxResults: 3})
+		if err != nil {
+			t.Fatalf("%s: Search() error = %v", provider.Name(), err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("%s: expected 1 result, got %d", provider.Name(), len(results))
+		}
+		got := results[0]
+		if got.Title != "Go" || got.URL != "https://go.dev" || got.Snippet != "The Go language" {
+			t.Errorf("%s: unexpected result %+v", provider.Name(), got)
+		}
+	}
+}
+
+func TestSearchProviders_MissingKey(t *testing.T) {
+	for _, provider := range []SearchProvider{NewTavily(""), NewBrave(""), NewSerpAPI("")} {
+		if _, err := provider.Search(context.Background(), "q", SearchOptions{}); !errors.Is(err, ErrMissingSearchKey) {
+			t.Errorf("%s: expected ErrMissingSearchKey, got %v", provider.Name(), err)
+		}
+	}
+}
+
+type stubSearch struct {
+	opts SearchOptions
+}
+
+func (s *stubSearch) Name() string { return "stub" }
+
+func (s *stubSearch) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	s.opts = opts
+	results := make([]SearchResult, 10)
+	for i := range results {
+		results[i] = SearchResult{Title: query}
+	}
+	return results, nil
+}
+
+func TestWebSearchTool(t *testing.T) {
+	provider := &stubSearch{}
+	tool := WebSearch(provider)
+
+	result, err := tool.Execute(context.Background(), `{"query":"agents","max_results":null}`)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if provider.opts.MaxResults != DefaultMaxResults {
+		t.Errorf("expected default max results, got %d", provider.opts.MaxResults)
+	}
+	results := result.(map[string]any)["results"].([]SearchResult)
+	if len(results) != DefaultMaxResults {
+		t.Errorf("expected results trimmed to %d, got %d", DefaultMaxResults, len(results))
+	}
+
+	if _, err := tool.Execute(context.Background(), `{"query":"agents","max_results":100}`); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if provider.opts.MaxResults != maxSearchResults {
+		t.Errorf("expected max results capped at %d, got %d", maxSearchResults, provider.opts.MaxResults)
+	}
+}
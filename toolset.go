@@ -0,0 +1,146 @@
+package agentkit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Toolset groups tools that are attached to and detached from an agent
+// together, such as everything an admin role may use or the tools of one
+// integration. Runs can be limited to some toolsets with WithEnabledTools,
+// so one agent definition serves different users with different
+// capabilities.
+type Toolset struct {
+	name  string
+	tools []Tool
+}
+
+// NewToolset creates a toolset named name holding tools.
+func NewToolset(name string, tools ...Tool) *Toolset {
+	return &Toolset{name: name, tools: slices.Clone(tools)}
+}
+
+// Name returns the toolset's name.
+func (ts *Toolset) Name() string {
+	return ts.name
+}
+
+// Tools returns the tools in the toolset.
+func (ts *Toolset) Tools() []Tool {
+	return slices.Clone(ts.tools)
+}
+
+// ToolNames returns the names of the tools in the toolset.
+func (ts *Toolset) ToolNames() []string {
+	names := make([]string, len(ts.tools))
+	for i, tool := range ts.tools {
+		names[i] = tool.name
+	}
+	return names
+}
+
+// AddToolset attaches the tools in ts to the agent. It fails with
+// ErrDuplicateTool, attaching nothing, when a toolset with the same name is
+// attached or one of its tools is already registered. It is safe to call
+// while runs are in progress; iterations that start afterwards offer the
+// new tools.
+func (a *Agent) AddToolset(ts *Toolset) error {
+	defer a.lockTools()()
+	if _, ok := a.toolsets[ts.name]; ok {
+		return fmt.Errorf("%w: toolset %s is already attached", ErrDuplicateTool, ts.name)
+	}
+	seen := make(map[string]bool, len(ts.tools))
+	for _, tool := range ts.tools {
+		if _, ok := a.tools[tool.name]; ok || seen[tool.name] {
+			return fmt.Errorf("%w: %s (toolset %s)", ErrDuplicateTool, tool.name, ts.name)
+		}
+		seen[tool.name] = true
+	}
+	for _, tool := range ts.tools {
+		tool.toolset = ts.name
+		a.tools[tool.name] = tool
+	}
+	if a.toolsets == nil {
+		a.toolsets = make(map[string]*Toolset)
+	}
+	a.toolsets[ts.name] = ts
+	return nil
+}
+
+// RemoveToolset detaches the toolset named name and its tools, reporting
+// whether it was attached. Calls the model makes to its tools afterwards are
+// answered like calls to unknown tools.
+func (a *Agent) RemoveToolset(name string) bool {
+	defer a.lockTools()()
+	if _, ok := a.toolsets[name]; !ok {
+		return false
+	}
+	for toolName, tool := range a.tools {
+		if tool.toolset == name {
+			delete(a.tools, toolName)
+		}
+	}
+	delete(a.toolsets, name)
+	return true
+}
+
+// Toolsets returns the names of the attached toolsets, sorted.
+func (a *Agent) Toolsets() []string {
+	defer a.readTools()()
+	names := make([]string, 0, len(a.toolsets))
+	for name := range a.toolsets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lockTools locks the tool map for writing and returns the unlock function.
+func (a *Agent) lockTools() func() {
+	if a.toolsMu == nil {
+		return func() {}
+	}
+	a.toolsMu.Lock()
+	return a.toolsMu.Unlock
+}
+
+// readTools locks the tool map for reading and returns the unlock function.
+func (a *Agent) readTools() func() {
+	if a.toolsMu == nil {
+		return func() {}
+	}
+	a.toolsMu.RLock()
+	return a.toolsMu.RUnlock
+}
+
+// lookupTool returns the registered tool named name.
+func (a *Agent) lookupTool(name string) (Tool, bool) {
+	defer a.readTools()()
+	tool, ok := a.tools[name]
+	return tool, ok
+}
+
+const enabledToolsKey contextKey = "agentkit_enabled_tools"
+
+// WithEnabledTools limits runs using ctx to the named tools and toolsets,
+// e.g. the toolsets a user's role may use. Other tools are not offered, and
+// calls to them are answered like calls to unknown tools. Calling it again
+// narrows the selection further: a tool must be named by every call.
+func WithEnabledTools(ctx context.Context, names ...string) context.Context {
+	filters, _ := ctx.Value(enabledToolsKey).([][]string)
+	return context.WithValue(ctx, enabledToolsKey, append(slices.Clip(filters), slices.Clone(names)))
+}
+
+// runAllowsTool reports whether the WithEnabledTools selections of ctx
+// include tool, by its name or its toolset's.
+func runAllowsTool(ctx context.Context, tool *Tool) bool {
+	filters, _ := ctx.Value(enabledToolsKey).([][]string)
+	for _, names := range filters {
+		if !slices.Contains(names, tool.name) && (tool.toolset == "" || !slices.Contains(names, tool.toolset)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func offeredTools(req providers.CompletionRequest) []string {
+	var names []string
+	for _, def := range req.Tools {
+		names = append(names, def.Name)
+	}
+	return names
+}
+
+func TestToolset_AttachDetach(t *testing.T) {
+	agent, err := New(Config{Provider: mockprovider.New(), Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("search").Build())
+	admin := NewToolset("admin", NewTool("ban_user").Build(), NewTool("delete_post").Build())
+
+	if err := agent.AddToolset(admin); err != nil {
+		t.Fatalf("AddToolset() error = %v", err)
+	}
+	if got := offeredTools(agent.buildCompletionRequest(context.Background(), nil)); !slices.Equal(got, []string{"ban_user", "delete_post", "search"}) {
+		t.Errorf("offered tools = %v", got)
+	}
+	if err := agent.AddToolset(admin); !errors.Is(err, ErrDuplicateTool) {
+		t.Errorf("attaching twice: error = %v, want ErrDuplicateTool", err)
+	}
+	if err := agent.AddToolset(NewToolset("other", NewTool("x").Build(), NewTool("search").Build())); !errors.Is(err, ErrDuplicateTool) {
+		t.Errorf("conflicting tool: error = %v, want ErrDuplicateTool", err)
+	}
+	if _, ok := agent.lookupTool("x"); ok {
+		t.Error("failed AddToolset attached some of its tools")
+	}
+	if got := agent.Toolsets(); !slices.Equal(got, []string{"admin"}) {
+		t.Errorf("Toolsets() = %v, want [admin]", got)
+	}
+
+	if !agent.RemoveToolset("admin") || agent.RemoveToolset("admin") {
+		t.Error("RemoveToolset should report only the first removal")
+	}
+	if got := offeredTools(agent.buildCompletionRequest(context.Background(), nil)); !slices.Equal(got, []string{"search"}) {
+		t.Errorf("offered tools after removal = %v, want [search]", got)
+	}
+}
+
+func TestToolset_WithEnabledTools(t *testing.T) {
+	executed := false
+	provider := &recordingProvider{Provider: mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "ban_user", Arguments: map[string]any{}}}).
+		WithResponse("done", nil)}
+	agent, err := New(Config{Provider: provider, Model: "test-model", StreamResponses: false})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("search").Build())
+	agent.AddTool(NewTool("summarize").Build())
+	if err := agent.AddToolset(NewToolset("admin", NewTool("ban_user").
+		WithHandler(func(context.Context, map[string]any) (any, error) {
+			executed = true
+			return "banned", nil
+		}).
+		Build())); err != nil {
+		t.Fatalf("AddToolset() error = %v", err)
+	}
+
+	ctx := WithEnabledTools(context.Background(), "search", "summarize")
+	events := collectEvents(agent.Run(ctx, "Ban them"), time.Second)
+	if got := offeredTools(provider.requests[0]); !slices.Equal(got, []string{"search", "summarize"}) {
+		t.Errorf("offered tools = %v, want [search summarize]", got)
+	}
+	if executed {
+		t.Error("tool outside the enabled set was executed")
+	}
+	if findEvent(events, EventTypeToolUnknown) == nil {
+		t.Error("call to a disabled tool was not answered as unknown")
+	}
+
+	narrowed := WithEnabledTools(WithEnabledTools(context.Background(), "admin", "search"), "admin")
+	if got := offeredTools(agent.buildCompletionRequest(narrowed, nil)); !slices.Equal(got, []string{"ban_user"}) {
+		t.Errorf("offered tools for toolset selection = %v, want [ban_user]", got)
+	}
+}
+
+func TestToolset_ConcurrentChanges(t *testing.T) {
+	agent, err := New(Config{Provider: mockprovider.New(), Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = agent.AddToolset(NewToolset("dynamic", NewTool("dynamic_tool").Build()))
+				agent.RemoveToolset("dynamic")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				agent.buildCompletionRequest(context.Background(), nil)
+				agent.lookupTool("dynamic_tool")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -4,6 +4,9 @@ package agentkit
 import (
 	"context"
 	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestTraceIDPropagation(t *testing.T) {
@@ -36,3 +39,68 @@ func TestTraceIDPropagation(t *testing.T) {
 		t.Fatal("expected events to be emitted")
 	}
 }
+
+// otelTracer starts OpenTelemetry spans, like the Langfuse tracer.
+type otelTracer struct {
+	NoOpTracer
+	tracer trace.Tracer
+}
+
+func (o *otelTracer) StartTrace(ctx context.Context, name string, opts ...TraceOption) (context.Context, func()) {
+	ctx, span := o.tracer.Start(ctx, name)
+	return ctx, func() { span.End() }
+}
+
+func (o *otelTracer) StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, func()) {
+	ctx, span := o.tracer.Start(ctx, name)
+	return ctx, func() { span.End() }
+}
+
+func TestTraceIDFromOpenTelemetrySpan(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	agent, err := New(Config{
+		Model:           "gpt-4o",
+		LLMProvider:     NewMockLLM().WithFinalResponse("done"),
+		StreamResponses: false,
+		Tracer:          &otelTracer{tracer: tp.Tracer("test")},
+	})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	traceID := ""
+	for event := range agent.Run(context.Background(), "hello") {
+		if event.TraceID == "" || event.SpanID == "" {
+			t.Fatalf("event %s without trace/span ID", event.Type)
+		}
+		if traceID == "" {
+			traceID = event.TraceID
+		}
+		if event.TraceID != traceID {
+			t.Fatalf("trace ID changed from %s to %s", traceID, event.TraceID)
+		}
+	}
+	if len(traceID) != 32 {
+		t.Fatalf("trace ID = %q, want an OTel trace ID", traceID)
+	}
+}
+
+// idTracer reports fixed IDs through TraceIDProvider.
+type idTracer struct{ NoOpTracer }
+
+func (*idTracer) TraceIDs(ctx context.Context) (string, string) { return "lf-trace", "lf-observation" }
+
+func TestTraceIDFromTracer(t *testing.T) {
+	ctx := WithTracer(context.Background(), &idTracer{})
+	if id, _ := GetTraceID(ctx); id != "lf-trace" {
+		t.Errorf("GetTraceID() = %q", id)
+	}
+	if id, _ := GetSpanID(ctx); id != "lf-observation" {
+		t.Errorf("GetSpanID() = %q", id)
+	}
+	if id, _ := GetTraceID(WithTraceID(ctx, "explicit")); id != "explicit" {
+		t.Errorf("GetTraceID() with explicit ID = %q", id)
+	}
+}
@@ -0,0 +1,59 @@
+package agentkit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// traceUsage accumulates token usage and cost across every generation of a
+// trace, including those of nested agents started by handoffs,
+// collaborations and agent tools, which share the root run's accumulator.
+type traceUsage struct {
+	mu          sync.Mutex
+	usage       providers.TokenUsage
+	cost        float64
+	generations int
+}
+
+// withTraceUsage returns ctx carrying the trace's usage accumulator and
+// whether this run created it, i.e. is the root of the trace.
+func withTraceUsage(ctx context.Context) (context.Context, *traceUsage, bool) {
+	if totals, ok := ctx.Value(traceUsageKey).(*traceUsage); ok {
+		return ctx, totals, false
+	}
+	totals := &traceUsage{}
+	return context.WithValue(ctx, traceUsageKey, totals), totals, true
+}
+
+// addTraceUsage records one generation of model on the trace in ctx.
+func addTraceUsage(ctx context.Context, model string, usage providers.TokenUsage) {
+	totals, ok := ctx.Value(traceUsageKey).(*traceUsage)
+	if !ok {
+		return
+	}
+	var cost float64
+	if info := CalculateCost(model, usage.PromptTokens, usage.CompletionTokens); info != nil {
+		cost = info.TotalCost
+	}
+	totals.mu.Lock()
+	defer totals.mu.Unlock()
+	totals.usage = addUsage(totals.usage, usage)
+	totals.cost += cost
+	totals.generations++
+}
+
+// attributes returns the totals as trace attributes.
+func (u *traceUsage) attributes() map[string]any {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return map[string]any{
+		"total_prompt_tokens":     u.usage.PromptTokens,
+		"total_completion_tokens": u.usage.CompletionTokens,
+		"total_reasoning_tokens":  u.usage.ReasoningTokens,
+		"total_tokens":            u.usage.TotalTokens,
+		"total_cost":              u.cost,
+		"generations":             u.generations,
+	}
+}
@@ -0,0 +1,54 @@
+package agentkit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestTraceUsage_RollsUpNestedAgents(t *testing.T) {
+	RegisterModelCost("usage-test-model", ModelCostConfig{InputCostPer1MTokens: 1_000_000, OutputCostPer1MTokens: 2_000_000})
+
+	tracer := &traceAttributeTracer{}
+	billing, err := New(
+		WithProvider(mockprovider.New().WithResponse("refunded", nil)),
+		WithModel("usage-test-model"),
+		WithName("billing"),
+		WithTracing(tracer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triage, err := New(
+		WithProvider(mockprovider.New().
+			WithResponse("", []providers.ToolCall{{ID: "call-1", Name: "billing", Arguments: map[string]any{"task": "refund"}}}).
+			WithResponse("done", nil)),
+		WithModel("usage-test-model"),
+		WithName("triage"),
+		WithTracing(tracer),
+		WithTool(billing.AsHandoffTool("billing", "Handles refunds")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collectEvents(triage.Run(context.Background(), "refund please"), 2*time.Second)
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	if tracer.calls != 1 {
+		t.Fatalf("SetTraceAttributes calls = %d, want 1 (root run only)", tracer.calls)
+	}
+	got := tracer.attributes
+	// Three generations of 10 prompt and 20 completion tokens each.
+	if got["generations"] != 3 || got["total_prompt_tokens"] != 30 || got["total_completion_tokens"] != 60 || got["total_tokens"] != 90 {
+		t.Errorf("trace attributes = %v", got)
+	}
+	if cost, _ := got["total_cost"].(float64); math.Abs(cost-150) > 1e-9 {
+		t.Errorf("total_cost = %v, want 150", got["total_cost"])
+	}
+}
@@ -36,6 +36,13 @@ type Tracer interface {
 	Flush(ctx context.Context) error
 }
 
+// TraceIDProvider is implemented by tracers that can identify the trace and
+// observation active in a context, so events link to them. Tracers built on
+// OpenTelemetry don't need it: the OTel span context is used by default.
+type TraceIDProvider interface {
+	TraceIDs(ctx context.Context) (traceID, spanID string)
+}
+
 // TraceOption configures trace creation
 type TraceOption func(*TraceConfig)
 
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/tracing/otlp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -51,6 +52,34 @@ type LangfuseConfig struct {
 	Enabled bool
 }
 
+// authHeader returns the Basic auth header for the API keys.
+func (cfg LangfuseConfig) authHeader() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(cfg.PublicKey+":"+cfg.SecretKey))
+}
+
+// OTLP returns the endpoint, auth and service details of cfg for
+// otlp.NewLogHandler and otlp.NewMetrics, so logs and metrics are
+// configured once with traces. Langfuse itself ingests traces only; set
+// BaseURL to an OpenTelemetry collector that forwards traces to Langfuse
+// and logs and metrics elsewhere, or change the returned Endpoint.
+func (cfg LangfuseConfig) OTLP() otlp.Config {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = "https://cloud.langfuse.com"
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "agentkit-app"
+	}
+	return otlp.Config{
+		Endpoint:       strings.TrimSuffix(baseURL, "/") + "/api/public/otel",
+		Headers:        map[string]string{"Authorization": cfg.authHeader()},
+		ServiceName:    serviceName,
+		ServiceVersion: cfg.ServiceVersion,
+		Environment:    cfg.Environment,
+	}
+}
+
 // NewLangfuseTracer creates a new Langfuse tracer instance
 func NewLangfuseTracer(cfg LangfuseConfig) (*LangfuseTracer, error) {
 	if !cfg.Enabled {
@@ -71,9 +100,7 @@ func NewLangfuseTracer(cfg LangfuseConfig) (*LangfuseTracer, error) {
 		cfg.ServiceName = "agentkit-app"
 	}
 
-	// Create Basic Auth header
-	authString := cfg.PublicKey + ":" + cfg.SecretKey
-	authHeader := "Basic " + base64.StdEncoding.EncodeToString([]byte(authString))
+	authHeader := cfg.authHeader()
 
 	// Configure OTLP HTTP exporter for Langfuse
 	// Extract host from BaseURL (remove scheme)
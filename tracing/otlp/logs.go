@@ -0,0 +1,200 @@
+package otlp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// logBatchSize is how many records trigger an export before the interval.
+const logBatchSize = 512
+
+// maxQueuedLogs bounds the records waiting for export; more are dropped.
+const maxQueuedLogs = 16 * logBatchSize
+
+// LogHandler is a slog.Handler exporting records as OTLP logs. Records
+// logged within a span carry its trace and span IDs, so backends link them
+// to the trace.
+type LogHandler struct {
+	logs   *logQueue
+	level  slog.Leveler
+	attrs  []*commonpb.KeyValue
+	prefix string // Open groups, as "group."
+}
+
+// logQueue holds records for export; it is shared by a handler and those
+// derived with WithAttrs and WithGroup.
+type logQueue struct {
+	exporter *exporter
+	loop     *loop
+	mu       sync.Mutex
+	records  []*logspb.LogRecord
+	dropped  atomic.Int64
+}
+
+// NewLogHandler returns a handler exporting records at level or above
+// (nil means slog.LevelInfo) to cfg.Endpoint. Call Shutdown before exit to
+// send the last batch.
+func NewLogHandler(cfg Config, level slog.Leveler) (*LogHandler, error) {
+	exporter, err := newExporter(cfg, "logs")
+	if err != nil {
+		return nil, err
+	}
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	q := &logQueue{exporter: exporter}
+	q.loop = startLoop(exporter.cfg.Interval, q.flush)
+	return &LogHandler{logs: q, level: level}, nil
+}
+
+// Enabled implements slog.Handler.
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler. It queues the record and never blocks
+// on the network.
+func (h *LogHandler) Handle(ctx context.Context, r slog.Record) error {
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(r.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       severity(r.Level),
+		SeverityText:         r.Level.String(),
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: r.Message}},
+		Attributes:           append(make([]*commonpb.KeyValue, 0, len(h.attrs)+r.NumAttrs()), h.attrs...),
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		record.Attributes = appendAttr(record.Attributes, h.prefix, attr)
+		return true
+	})
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		traceID, spanID := span.TraceID(), span.SpanID()
+		record.TraceId = traceID[:]
+		record.SpanId = spanID[:]
+		record.Flags = uint32(span.TraceFlags())
+	}
+	h.logs.add(record)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.attrs = append([]*commonpb.KeyValue(nil), h.attrs...)
+	for _, attr := range attrs {
+		derived.attrs = appendAttr(derived.attrs, h.prefix, attr)
+	}
+	return &derived
+}
+
+// WithGroup implements slog.Handler. Attributes in groups are exported
+// with dotted keys, e.g. "request.id".
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	derived := *h
+	derived.prefix = h.prefix + name + "."
+	return &derived
+}
+
+// Dropped returns how many records were discarded because the queue was
+// full.
+func (h *LogHandler) Dropped() int64 {
+	return h.logs.dropped.Load()
+}
+
+// Flush exports the queued records now.
+func (h *LogHandler) Flush(ctx context.Context) error {
+	return h.logs.flush(ctx)
+}
+
+// Shutdown stops the background export and sends the queued records.
+func (h *LogHandler) Shutdown(ctx context.Context) error {
+	return h.logs.loop.shutdown(ctx)
+}
+
+func (q *logQueue) add(record *logspb.LogRecord) {
+	q.mu.Lock()
+	if len(q.records) >= maxQueuedLogs {
+		q.mu.Unlock()
+		q.dropped.Add(1)
+		return
+	}
+	q.records = append(q.records, record)
+	full := len(q.records) >= logBatchSize
+	q.mu.Unlock()
+	if full {
+		q.loop.trigger()
+	}
+}
+
+func (q *logQueue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	records := q.records
+	q.records = nil
+	q.mu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+	return q.exporter.export(ctx, &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			Resource: q.exporter.resource,
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope:      &commonpb.InstrumentationScope{Name: scopeName},
+				LogRecords: records,
+			}},
+		}},
+	})
+}
+
+// appendAttr appends attr, with groups flattened into dotted keys.
+func appendAttr(attrs []*commonpb.KeyValue, prefix string, attr slog.Attr) []*commonpb.KeyValue {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range attr.Value.Group() {
+			attrs = appendAttr(attrs, prefix, member)
+		}
+		return attrs
+	}
+	return append(attrs, &commonpb.KeyValue{Key: prefix + attr.Key, Value: anyValue(attr.Value)})
+}
+
+// severity maps slog levels to OTLP severity numbers; levels between the
+// standard ones keep their offset, e.g. slog.LevelInfo+2 is INFO3.
+func severity(level slog.Level) logspb.SeverityNumber {
+	n := logspb.SeverityNumber_SEVERITY_NUMBER_INFO + logspb.SeverityNumber(level-slog.LevelInfo)
+	return min(max(n, logspb.SeverityNumber_SEVERITY_NUMBER_TRACE), logspb.SeverityNumber_SEVERITY_NUMBER_FATAL4)
+}
+
+// anyValue converts a slog value other than a group.
+func anyValue(v slog.Value) *commonpb.AnyValue {
+	switch v.Kind() {
+	case slog.KindString:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.String()}}
+	case slog.KindInt64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.Int64()}}
+	case slog.KindUint64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v.Uint64())}}
+	case slog.KindFloat64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.Float64()}}
+	case slog.KindBool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.Bool()}}
+	}
+	// Durations, times, errors and anything else as text.
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.String()}}
+}
@@ -0,0 +1,267 @@
+package otlp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/providers"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+// Bucket bounds follow the OpenTelemetry GenAI semantic conventions.
+var (
+	durationBounds = []float64{0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92}
+	tokenBounds    = []float64{1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864}
+)
+
+// Metrics is an agentkit middleware recording runs, tool calls and model
+// calls, exported as cumulative OTLP metrics every Interval:
+//
+//   - agentkit.runs and agentkit.run.duration, by agent and status
+//   - agentkit.tool.calls and agentkit.tool.duration, by agent, tool and status
+//   - gen_ai.client.operation.duration and gen_ai.client.token.usage, by model
+//   - agentkit.runs.active, the agentkit.ActiveRuns gauge
+//
+// Status is "ok" or "error". Add it with Agent.Use; one Metrics can serve
+// several agents.
+type Metrics struct {
+	exporter *exporter
+	loop     *loop
+	start    time.Time
+
+	mu          sync.Mutex
+	instruments []*instrument
+
+	runs, runDuration, toolCalls, toolDuration, llmDuration, tokens *instrument
+}
+
+// NewMetrics returns a Metrics exporting to cfg.Endpoint. Call Shutdown
+// before exit to send the last values.
+func NewMetrics(cfg Config) (*Metrics, error) {
+	exporter, err := newExporter(cfg, "metrics")
+	if err != nil {
+		return nil, err
+	}
+	m := &Metrics{exporter: exporter, start: time.Now()}
+	m.runs = m.instrument("agentkit.runs", "{run}", "Agent runs completed", nil)
+	m.runDuration = m.instrument("agentkit.run.duration", "s", "Duration of agent runs", durationBounds)
+	m.toolCalls = m.instrument("agentkit.tool.calls", "{call}", "Tool calls completed", nil)
+	m.toolDuration = m.instrument("agentkit.tool.duration", "s", "Duration of tool calls", durationBounds)
+	m.llmDuration = m.instrument("gen_ai.client.operation.duration", "s", "Duration of model calls", durationBounds)
+	m.tokens = m.instrument("gen_ai.client.token.usage", "{token}", "Tokens used per model call", tokenBounds)
+	m.loop = startLoop(exporter.cfg.Interval, m.Flush)
+	return m, nil
+}
+
+type (
+	runStartKey  struct{}
+	toolStartKey struct{}
+	llmCallKey   struct{}
+)
+
+type llmCall struct {
+	start time.Time
+	model string
+}
+
+// OnAgentStart implements agentkit.Middleware.
+func (m *Metrics) OnAgentStart(ctx context.Context, input string) context.Context {
+	return context.WithValue(ctx, runStartKey{}, time.Now())
+}
+
+// OnAgentComplete implements agentkit.Middleware.
+func (m *Metrics) OnAgentComplete(ctx context.Context, output string, err error) {
+	attrs := []*commonpb.KeyValue{stringAttr("agent", agentName(ctx)), stringAttr("status", status(err))}
+	m.runs.add(attrs, 1)
+	if start, ok := ctx.Value(runStartKey{}).(time.Time); ok {
+		m.runDuration.add(attrs, time.Since(start).Seconds())
+	}
+}
+
+// OnToolStart implements agentkit.Middleware.
+func (m *Metrics) OnToolStart(ctx context.Context, tool string, args any) context.Context {
+	return context.WithValue(ctx, toolStartKey{}, time.Now())
+}
+
+// OnToolComplete implements agentkit.Middleware.
+func (m *Metrics) OnToolComplete(ctx context.Context, tool string, result any, err error) {
+	attrs := []*commonpb.KeyValue{stringAttr("agent", agentName(ctx)), stringAttr("tool", tool), stringAttr("status", status(err))}
+	m.toolCalls.add(attrs, 1)
+	if start, ok := ctx.Value(toolStartKey{}).(time.Time); ok {
+		m.toolDuration.add(attrs, time.Since(start).Seconds())
+	}
+}
+
+// OnLLMCall implements agentkit.Middleware.
+func (m *Metrics) OnLLMCall(ctx context.Context, req any) context.Context {
+	call := llmCall{start: time.Now()}
+	if req, ok := req.(providers.CompletionRequest); ok {
+		call.model = req.Model
+	}
+	return context.WithValue(ctx, llmCallKey{}, call)
+}
+
+// OnLLMResponse implements agentkit.Middleware.
+func (m *Metrics) OnLLMResponse(ctx context.Context, resp any, err error) {
+	call, ok := ctx.Value(llmCallKey{}).(llmCall)
+	if !ok {
+		return
+	}
+	model := stringAttr("gen_ai.request.model", call.model)
+	m.llmDuration.add([]*commonpb.KeyValue{model, stringAttr("agent", agentName(ctx)), stringAttr("status", status(err))},
+		time.Since(call.start).Seconds())
+	if resp, ok := resp.(*providers.CompletionResponse); ok && resp != nil {
+		m.tokens.add([]*commonpb.KeyValue{model, stringAttr("gen_ai.token.type", "input")}, float64(resp.Usage.PromptTokens))
+		m.tokens.add([]*commonpb.KeyValue{model, stringAttr("gen_ai.token.type", "output")}, float64(resp.Usage.CompletionTokens))
+	}
+}
+
+// Flush exports the current values now.
+func (m *Metrics) Flush(ctx context.Context) error {
+	now := uint64(time.Now().UnixNano())
+	start := uint64(m.start.UnixNano())
+	metrics := []*metricspb.Metric{{
+		Name:        "agentkit.runs.active",
+		Unit:        "{run}",
+		Description: "Runs in progress",
+		Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+			TimeUnixNano: now,
+			Value:        &metricspb.NumberDataPoint_AsInt{AsInt: int64(agentkit.ActiveRuns())},
+		}}}},
+	}}
+	m.mu.Lock()
+	for _, inst := range m.instruments {
+		if metric := inst.metric(start, now); metric != nil {
+			metrics = append(metrics, metric)
+		}
+	}
+	m.mu.Unlock()
+
+	return m.exporter.export(ctx, &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: m.exporter.resource,
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: scopeName},
+				Metrics: metrics,
+			}},
+		}},
+	})
+}
+
+// Shutdown stops the background export and sends the final values.
+func (m *Metrics) Shutdown(ctx context.Context) error {
+	return m.loop.shutdown(ctx)
+}
+
+// instrument is a counter, or a histogram when it has bounds, with one
+// series per attribute set.
+type instrument struct {
+	m      *Metrics
+	name   string
+	unit   string
+	desc   string
+	bounds []float64
+	series map[string]*series
+}
+
+type series struct {
+	attrs    []*commonpb.KeyValue
+	count    uint64
+	sum      float64
+	min, max float64
+	buckets  []uint64
+}
+
+func (m *Metrics) instrument(name, unit, desc string, bounds []float64) *instrument {
+	inst := &instrument{m: m, name: name, unit: unit, desc: desc, bounds: bounds, series: make(map[string]*series)}
+	m.instruments = append(m.instruments, inst)
+	return inst
+}
+
+func (i *instrument) add(attrs []*commonpb.KeyValue, value float64) {
+	var key strings.Builder
+	for _, attr := range attrs {
+		key.WriteString(attr.Key + "=" + attr.Value.GetStringValue() + "\x00")
+	}
+	i.m.mu.Lock()
+	defer i.m.mu.Unlock()
+	s, ok := i.series[key.String()]
+	if !ok {
+		s = &series{attrs: attrs, min: value, max: value}
+		if i.bounds != nil {
+			s.buckets = make([]uint64, len(i.bounds)+1)
+		}
+		i.series[key.String()] = s
+	}
+	s.count++
+	s.sum += value
+	s.min, s.max = min(s.min, value), max(s.max, value)
+	if s.buckets != nil {
+		idx, _ := slices.BinarySearch(i.bounds, value) // Buckets are (bound[i-1], bound[i]]
+		s.buckets[idx]++
+	}
+}
+
+// metric returns the instrument's values, or nil before the first one.
+func (i *instrument) metric(start, now uint64) *metricspb.Metric {
+	if len(i.series) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(i.series))
+	for key := range i.series {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	metric := &metricspb.Metric{Name: i.name, Unit: i.unit, Description: i.desc}
+	if i.bounds == nil {
+		sum := &metricspb.Sum{AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE, IsMonotonic: true}
+		for _, key := range keys {
+			s := i.series[key]
+			sum.DataPoints = append(sum.DataPoints, &metricspb.NumberDataPoint{
+				Attributes:        s.attrs,
+				StartTimeUnixNano: start,
+				TimeUnixNano:      now,
+				Value:             &metricspb.NumberDataPoint_AsInt{AsInt: int64(s.sum)},
+			})
+		}
+		metric.Data = &metricspb.Metric_Sum{Sum: sum}
+		return metric
+	}
+	histogram := &metricspb.Histogram{AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE}
+	for _, key := range keys {
+		s := i.series[key]
+		sum, lo, hi := s.sum, s.min, s.max // Copied: the series keeps changing once unlocked
+		histogram.DataPoints = append(histogram.DataPoints, &metricspb.HistogramDataPoint{
+			Attributes:        s.attrs,
+			StartTimeUnixNano: start,
+			TimeUnixNano:      now,
+			Count:             s.count,
+			Sum:               &sum,
+			Min:               &lo,
+			Max:               &hi,
+			BucketCounts:      slices.Clone(s.buckets),
+			ExplicitBounds:    i.bounds,
+		})
+	}
+	metric.Data = &metricspb.Metric_Histogram{Histogram: histogram}
+	return metric
+}
+
+func agentName(ctx context.Context) string {
+	name, _ := agentkit.GetAgentName(ctx)
+	return name
+}
+
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,179 @@
+// Package otlp exports agentkit logs and metrics over OTLP/HTTP, next to
+// the traces sent by tracing/langfuse, from one endpoint and auth config:
+//
+//	cfg := langfuseConfig.OTLP() // Or otlp.Config{Endpoint: "http://collector:4318"}
+//	logs, err := otlp.NewLogHandler(cfg, slog.LevelInfo)
+//	metrics, err := otlp.NewMetrics(cfg)
+//
+//	agent, err := agentkit.New(agentkit.Config{
+//		Tracer:  tracer,
+//		Logging: &agentkit.LoggingConfig{Handler: logs},
+//	})
+//	agent.Use(metrics)
+//
+// Logs go to <Endpoint>/v1/logs and metrics to <Endpoint>/v1/metrics, as
+// protobuf. Both are batched in the background and sent every Interval;
+// Shutdown sends what is left. Failed exports are reported to the
+// OpenTelemetry error handler (otel.Handle).
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const scopeName = "github.com/darkostanimirovic/agentkit"
+
+// DefaultInterval is how often logs and metrics are exported by default.
+const DefaultInterval = 10 * time.Second
+
+// Config is the OTLP/HTTP endpoint, auth and resource shared by logs and
+// metrics.
+type Config struct {
+	// Endpoint is the base URL of the receiver, e.g. "http://localhost:4318".
+	Endpoint string
+	// Headers are sent with every export, e.g. Authorization.
+	Headers map[string]string
+	// ServiceName identifies your application (default "agentkit-app").
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	// Interval is how often batches are exported (default DefaultInterval).
+	Interval time.Duration
+	// Timeout bounds each export (default 10s).
+	Timeout time.Duration
+	// HTTPClient sends the exports (default http.DefaultClient).
+	HTTPClient *http.Client
+}
+
+// exporter posts OTLP requests for one signal.
+type exporter struct {
+	cfg      Config
+	url      string
+	resource *resourcepb.Resource
+}
+
+func newExporter(cfg Config, signal string) (*exporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("otlp: Endpoint is required")
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "agentkit-app"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	resource := &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttr("service.name", cfg.ServiceName)}}
+	if cfg.ServiceVersion != "" {
+		resource.Attributes = append(resource.Attributes, stringAttr("service.version", cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		resource.Attributes = append(resource.Attributes, stringAttr("deployment.environment", cfg.Environment))
+	}
+	return &exporter{
+		cfg:      cfg,
+		url:      strings.TrimSuffix(cfg.Endpoint, "/") + "/v1/" + signal,
+		resource: resource,
+	}, nil
+}
+
+// export posts msg as protobuf.
+func (e *exporter) export(ctx context.Context, msg proto.Message) error {
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("otlp: encode: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("otlp: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp: export to %s: %w", e.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("otlp: export to %s: %s: %s", e.url, resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// loop calls flush every Interval and when kicked, until stopped. The
+// final flush is left to Shutdown.
+type loop struct {
+	flush   func(context.Context) error
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	stopped sync.Once
+}
+
+func startLoop(interval time.Duration, flush func(context.Context) error) *loop {
+	l := &loop{flush: flush, kick: make(chan struct{}, 1), stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-l.kick:
+			case <-l.stop:
+				return
+			}
+			if err := l.flush(context.Background()); err != nil {
+				otel.Handle(err)
+			}
+		}
+	}()
+	return l
+}
+
+// trigger asks for a flush without waiting for it.
+func (l *loop) trigger() {
+	select {
+	case l.kick <- struct{}{}:
+	default:
+	}
+}
+
+// shutdown stops the loop and flushes once more.
+func (l *loop) shutdown(ctx context.Context) error {
+	l.stopped.Do(func() { close(l.stop) })
+	select {
+	case <-l.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return l.flush(ctx)
+}
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
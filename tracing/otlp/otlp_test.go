@@ -0,0 +1,195 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/providers"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver records the OTLP requests it gets.
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests map[string][][]byte
+	headers  http.Header
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{requests: map[string][][]byte{}}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests[req.URL.Path] = append(r.requests[req.URL.Path], body)
+		r.headers = req.Header.Clone()
+		r.mu.Unlock()
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) config() Config {
+	return Config{Endpoint: r.URL + "/otel", Headers: map[string]string{"Authorization": "Bearer secret"}, ServiceName: "checkout"}
+}
+
+func (r *receiver) last(t *testing.T, path string, msg proto.Message) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bodies := r.requests[path]
+	if len(bodies) == 0 {
+		t.Fatalf("nothing sent to %s (got %v)", path, r.requests)
+	}
+	if err := proto.Unmarshal(bodies[len(bodies)-1], msg); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+	if got := r.headers.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func attrs(kvs []*commonpb.KeyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		switch v := kv.Value.Value.(type) {
+		case *commonpb.AnyValue_StringValue:
+			m[kv.Key] = v.StringValue
+		case *commonpb.AnyValue_IntValue:
+			m[kv.Key] = slog.Int64Value(v.IntValue).String()
+		default:
+			m[kv.Key] = kv.Value.String()
+		}
+	}
+	return m
+}
+
+func TestLogHandler(t *testing.T) {
+	r := newReceiver(t)
+	handler, err := NewLogHandler(r.config(), slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("NewLogHandler() error = %v", err)
+	}
+	logger := slog.New(handler).With("agent", "support").WithGroup("tool")
+
+	traceID := trace.TraceID{1, 2, 3}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: trace.SpanID{4, 5}, TraceFlags: trace.FlagsSampled,
+	}))
+	logger.DebugContext(ctx, "not exported")
+	logger.WarnContext(ctx, "tool failed", "name", "lookup", "attempts", 3)
+	if err := handler.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	var req collogspb.ExportLogsServiceRequest
+	r.last(t, "/otel/v1/logs", &req)
+	if got := attrs(req.ResourceLogs[0].Resource.Attributes)["service.name"]; got != "checkout" {
+		t.Errorf("service.name = %q", got)
+	}
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 1 {
+		t.Fatalf("records = %v", records)
+	}
+	record := records[0]
+	if record.Body.GetStringValue() != "tool failed" || record.SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_WARN {
+		t.Errorf("record = %v", record)
+	}
+	want := map[string]string{"agent": "support", "tool.name": "lookup", "tool.attempts": "3"}
+	if got := attrs(record.Attributes); len(got) != len(want) || got["agent"] != "support" || got["tool.name"] != "lookup" || got["tool.attempts"] != "3" {
+		t.Errorf("attributes = %v, want %v", got, want)
+	}
+	if trace.TraceID(record.TraceId) != traceID {
+		t.Errorf("trace ID = %x", record.TraceId)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	r := newReceiver(t)
+	metrics, err := NewMetrics(r.config())
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+	ctx := agentkit.WithAgentName(context.Background(), "support")
+
+	runCtx := metrics.OnAgentStart(ctx, "hi")
+	callCtx := metrics.OnLLMCall(runCtx, providers.CompletionRequest{Model: "gpt-test"})
+	metrics.OnLLMResponse(callCtx, &providers.CompletionResponse{Usage: providers.TokenUsage{PromptTokens: 100, CompletionTokens: 20}}, nil)
+	for _, err := range []error{nil, errors.New("boom"), nil} {
+		metrics.OnToolComplete(metrics.OnToolStart(runCtx, "lookup", nil), "lookup", nil, err)
+	}
+	metrics.OnAgentComplete(runCtx, "done", nil)
+	if err := metrics.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	var req colmetricspb.ExportMetricsServiceRequest
+	r.last(t, "/otel/v1/metrics", &req)
+	byName := map[string]*metricspb.Metric{}
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		byName[m.Name] = m
+	}
+
+	toolCalls := map[string]int64{}
+	for _, dp := range byName["agentkit.tool.calls"].GetSum().GetDataPoints() {
+		a := attrs(dp.Attributes)
+		if a["agent"] != "support" || a["tool"] != "lookup" {
+			t.Errorf("tool call attributes = %v", a)
+		}
+		toolCalls[a["status"]] = dp.GetAsInt()
+	}
+	if toolCalls["ok"] != 2 || toolCalls["error"] != 1 {
+		t.Errorf("tool calls = %v", toolCalls)
+	}
+	if runs := byName["agentkit.run.duration"].GetHistogram().GetDataPoints(); len(runs) != 1 || runs[0].Count != 1 {
+		t.Errorf("run durations = %v", runs)
+	}
+	tokens := map[string]float64{}
+	for _, dp := range byName["gen_ai.client.token.usage"].GetHistogram().GetDataPoints() {
+		a := attrs(dp.Attributes)
+		if a["gen_ai.request.model"] != "gpt-test" {
+			t.Errorf("token attributes = %v", a)
+		}
+		tokens[a["gen_ai.token.type"]] = dp.GetSum()
+	}
+	if tokens["input"] != 100 || tokens["output"] != 20 {
+		t.Errorf("tokens = %v", tokens)
+	}
+	if byName["agentkit.runs.active"].GetGauge() == nil {
+		t.Error("no agentkit.runs.active gauge")
+	}
+}
+
+func TestExportError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "logs not supported", http.StatusNotFound)
+	}))
+	defer server.Close()
+	handler, err := NewLogHandler(Config{Endpoint: server.URL}, nil)
+	if err != nil {
+		t.Fatalf("NewLogHandler() error = %v", err)
+	}
+	slog.New(handler).Info("hello")
+	err = handler.Shutdown(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found: logs not supported") {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+
+	if _, err := NewMetrics(Config{}); err == nil {
+		t.Error("NewMetrics() without Endpoint succeeded")
+	}
+}
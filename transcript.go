@@ -0,0 +1,211 @@
+package agentkit
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// transcript is a format-neutral document: a title, key facts and
+// sections of labeled entries. Collaboration and handoff results build one
+// and render it as Markdown or HTML.
+type transcript struct {
+	Title    string
+	Facts    []transcriptFact
+	Sections []transcriptSection
+}
+
+type transcriptFact struct {
+	Label, Value string
+}
+
+type transcriptSection struct {
+	Heading string
+	Note    string
+	Entries []transcriptEntry
+}
+
+type transcriptEntry struct {
+	Label string
+	Note  string
+	Text  string
+}
+
+// Markdown renders the collaboration as a transcript: participants, each
+// round's contributions and synthesis, and the final decision. It is meant
+// for attaching to tickets and design docs.
+func (r *CollaborationResult) Markdown() string {
+	return r.transcript().markdown()
+}
+
+// HTML renders the collaboration transcript as a standalone HTML document.
+func (r *CollaborationResult) HTML() string {
+	return r.transcript().html()
+}
+
+func (r *CollaborationResult) transcript() transcript {
+	topic, _ := r.Metadata["topic"].(string)
+	doc := transcript{Title: "Collaboration"}
+	if topic != "" {
+		doc.Title += ": " + topic
+	}
+	doc.Facts = []transcriptFact{
+		{"Participants", strings.Join(r.Participants, ", ")},
+		{"Rounds", fmt.Sprint(len(r.Rounds))},
+	}
+	if r.Summary != "" {
+		doc.Facts = append(doc.Facts, transcriptFact{"Summary", r.Summary})
+	}
+
+	for _, round := range r.Rounds {
+		section := transcriptSection{Heading: fmt.Sprintf("Round %d", round.Number)}
+		var notes []string
+		if round.Focus != "" && round.Focus != topic {
+			notes = append(notes, "Focus: "+round.Focus)
+		}
+		if len(round.Skipped) > 0 {
+			notes = append(notes, "Skipped: "+strings.Join(round.Skipped, ", "))
+		}
+		section.Note = strings.Join(notes, ". ")
+		for _, contrib := range round.Contributions {
+			entry := transcriptEntry{Label: contrib.Agent, Text: contrib.Content}
+			if len(contrib.Expertise) > 0 {
+				entry.Note = fmt.Sprintf("expertise: %s; weight %.1f", strings.Join(contrib.Expertise, ", "), contrib.Weight)
+			}
+			section.Entries = append(section.Entries, entry)
+		}
+		if round.Synthesis != "" {
+			section.Entries = append(section.Entries, transcriptEntry{Label: "Synthesis", Text: round.Synthesis})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	doc.Sections = append(doc.Sections, transcriptSection{
+		Heading: "Decision",
+		Entries: []transcriptEntry{{Text: r.FinalResponse}},
+	})
+	return doc
+}
+
+// Markdown renders the handoff as a transcript: who delegated what to whom,
+// the delegated agent's trace (when the handoff ran with full context) and
+// its response.
+func (r *HandoffResult) Markdown() string {
+	return r.transcript().markdown()
+}
+
+// HTML renders the handoff transcript as a standalone HTML document.
+func (r *HandoffResult) HTML() string {
+	return r.transcript().html()
+}
+
+func (r *HandoffResult) transcript() transcript {
+	from, _ := r.Metadata["from"].(string)
+	to, _ := r.Metadata["to"].(string)
+	task, _ := r.Metadata["task"].(string)
+
+	doc := transcript{Title: "Handoff"}
+	if from != "" && to != "" {
+		doc.Title += fmt.Sprintf(": %s → %s", from, to)
+	}
+	if task != "" {
+		doc.Facts = append(doc.Facts, transcriptFact{"Task", task})
+	}
+	if r.Summary != "" {
+		doc.Facts = append(doc.Facts, transcriptFact{"Summary", r.Summary})
+	}
+
+	if len(r.Trace) > 0 {
+		section := transcriptSection{Heading: "Trace"}
+		for i, item := range r.Trace {
+			section.Entries = append(section.Entries, transcriptEntry{
+				Label: fmt.Sprintf("%d. %s", i+1, strings.ReplaceAll(item.Type, "_", " ")),
+				Text:  item.Content,
+			})
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+	doc.Sections = append(doc.Sections, transcriptSection{
+		Heading: "Response",
+		Entries: []transcriptEntry{{Text: r.Response}},
+	})
+	return doc
+}
+
+func (t transcript) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", t.Title)
+	for _, fact := range t.Facts {
+		fmt.Fprintf(&b, "- **%s:** %s\n", fact.Label, fact.Value)
+	}
+	for _, section := range t.Sections {
+		fmt.Fprintf(&b, "\n## %s\n", section.Heading)
+		if section.Note != "" {
+			fmt.Fprintf(&b, "\n_%s_\n", section.Note)
+		}
+		for _, entry := range section.Entries {
+			if entry.Label == "" {
+				fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(entry.Text))
+				continue
+			}
+			fmt.Fprintf(&b, "\n**%s**", entry.Label)
+			if entry.Note != "" {
+				fmt.Fprintf(&b, " _(%s)_", entry.Note)
+			}
+			b.WriteString("\n\n")
+			for _, line := range strings.Split(strings.TrimSpace(entry.Text), "\n") {
+				fmt.Fprintf(&b, "> %s\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
+var transcriptHTML = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 50rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #1f2328; }
+dt { font-weight: 600; }
+dd { margin: 0 0 .5rem 0; }
+.note { color: #59636e; font-style: italic; }
+.entry { border-left: 3px solid #d1d9e0; margin: 1rem 0; padding-left: 1rem; }
+.text { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Facts}}
+<dl>
+{{- range .Facts}}
+<dt>{{.Label}}</dt><dd>{{.Value}}</dd>
+{{- end}}
+</dl>
+{{- end}}
+{{- range .Sections}}
+<h2>{{.Heading}}</h2>
+{{- if .Note}}
+<p class="note">{{.Note}}</p>
+{{- end}}
+{{- range .Entries}}
+{{- if .Label}}
+<div class="entry"><strong>{{.Label}}</strong>{{if .Note}} <span class="note">({{.Note}})</span>{{end}}
+<div class="text">{{.Text}}</div></div>
+{{- else}}
+<div class="text">{{.Text}}</div>
+{{- end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+func (t transcript) html() string {
+	var b strings.Builder
+	if err := transcriptHTML.Execute(&b, t); err != nil {
+		return fmt.Sprintf("<!-- transcript rendering failed: %s -->", template.HTMLEscapeString(err.Error()))
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package agentkit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCollaborationResult_Markdown(t *testing.T) {
+	result := &CollaborationResult{
+		FinalResponse: "Use Postgres full-text search.",
+		Participants:  []string{"db", "ui"},
+		Summary:       "Collaboration completed in 1 round(s)",
+		Metadata:      map[string]any{"topic": "Search"},
+		Rounds: []CollaborationRound{{
+			Number:        1,
+			Focus:         "Indexing",
+			Skipped:       []string{"ui"},
+			Contributions: []CollaborationContribution{{Agent: "db", Content: "Add a GIN index.\nReindex weekly.", Expertise: []string{"postgres"}, Weight: 2}},
+			Synthesis:     "Index first.",
+		}},
+	}
+
+	want := `# Collaboration: Search
+
+- **Participants:** db, ui
+- **Rounds:** 1
+- **Summary:** Collaboration completed in 1 round(s)
+
+## Round 1
+
+_Focus: Indexing. Skipped: ui_
+
+**db** _(expertise: postgres; weight 2.0)_
+
+> Add a GIN index.
+> Reindex weekly.
+
+**Synthesis**
+
+> Index first.
+
+## Decision
+
+Use Postgres full-text search.
+`
+	if got := result.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHandoffResult_HTML(t *testing.T) {
+	result := &HandoffResult{
+		Response: "Found <3> issues.",
+		Trace:    []HandoffTraceItem{{Type: "tool_call", Content: "scan()"}},
+		Metadata: map[string]any{"from": "lead", "to": "auditor", "task": "Audit auth"},
+	}
+
+	html := result.HTML()
+	for _, want := range []string{
+		"<title>Handoff: lead → auditor</title>",
+		"<dt>Task</dt><dd>Audit auth</dd>",
+		"<strong>1. tool call</strong>",
+		"Found &lt;3&gt; issues.",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML() missing %q:\n%s", want, html)
+		}
+	}
+	if md := result.Markdown(); !strings.Contains(md, "**1. tool call**\n\n> scan()") {
+		t.Errorf("Markdown() = %s", md)
+	}
+}
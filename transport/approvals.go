@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// ErrApprovalNotPending is returned when resolving a call that is not
+// waiting for approval.
+var ErrApprovalNotPending = errors.New("transport: no pending approval for call")
+
+// Approvals parks tool approvals until a client decides them in a separate
+// request, so approval flows work across stateless transports:
+//
+//	approvals := transport.NewApprovals()
+//	agent, _ := agentkit.New(agentkit.Config{
+//		Approval: &agentkit.ApprovalConfig{AllTools: true, Handler: approvals.Handler},
+//	})
+//
+// The approval_required event carries the call ID the client passes to Resolve.
+type Approvals struct {
+	mu      sync.Mutex
+	pending map[string]chan bool
+}
+
+// NewApprovals creates an empty approval broker.
+func NewApprovals() *Approvals {
+	return &Approvals{pending: make(map[string]chan bool)}
+}
+
+// Handler is an agentkit.ApprovalHandler that waits for Resolve or for the
+// run's context to end.
+func (a *Approvals) Handler(ctx context.Context, req agentkit.ApprovalRequest) (bool, error) {
+	decision := make(chan bool, 1)
+	a.mu.Lock()
+	a.pending[req.CallID] = decision
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		delete(a.pending, req.CallID)
+		a.mu.Unlock()
+	}()
+
+	select {
+	case approved := <-decision:
+		return approved, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// Resolve approves or denies a pending call.
+func (a *Approvals) Resolve(callID string, approved bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	decision, ok := a.pending[callID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrApprovalNotPending, callID)
+	}
+	delete(a.pending, callID)
+	decision <- approved
+	return nil
+}
+
+// Pending returns the IDs of calls waiting for a decision.
+func (a *Approvals) Pending() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ids := make([]string, 0, len(a.pending))
+	for id := range a.pending {
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,227 @@
+// Package compact is a binary event encoding for bandwidth-constrained
+// clients such as mobile apps on cellular networks.
+//
+// Events are MessagePack arrays
+//
+//	[type, timestamp_ms, data, trace_id, span_id]
+//
+// where type and the top-level data keys are replaced by their index in a
+// versioned Schema when known (unknown ones stay strings), so the repeated
+// field names that dominate JSON events cost one byte each. The schema is
+// generated from the agentkit event definitions into schema.json, which
+// clients can vendor or fetch from SchemaHandler.
+//
+// Transports negotiate the encoding with the Accept header; see Accepts.
+package compact
+
+//go:generate go run ./internal/gen
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// ContentType is the media type of compact-encoded bodies.
+const ContentType = "application/msgpack"
+
+// SchemaVersionHeader carries the schema version of compact responses, so
+// clients can detect a schema newer than the one they ship with.
+const SchemaVersionHeader = "X-Agentkit-Schema-Version"
+
+// ErrInvalidEvent is returned when decoded data is not a compact event.
+var ErrInvalidEvent = errors.New("compact: invalid event")
+
+//go:embed schema.json
+var schemaJSON []byte
+
+// Schema maps event types and data keys to wire indices. Entries are only
+// ever appended, so clients with an older schema still decode the indices
+// they know.
+type Schema struct {
+	Version int                  `json:"version"`
+	Types   []agentkit.EventType `json:"types"`
+	Keys    []string             `json:"keys"`
+}
+
+var defaultSchema = sync.OnceValue(func() *Schema {
+	var s Schema
+	if err := json.Unmarshal(schemaJSON, &s); err != nil {
+		panic(fmt.Sprintf("compact: invalid embedded schema: %v", err))
+	}
+	return &s
+})
+
+// DefaultSchema returns the schema generated from the agentkit event types.
+func DefaultSchema() *Schema {
+	return defaultSchema()
+}
+
+// SchemaHandler serves the default schema as JSON.
+func SchemaHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(SchemaVersionHeader, strconv.Itoa(DefaultSchema().Version))
+		_, _ = w.Write(schemaJSON)
+	})
+}
+
+// Codec encodes and decodes events with a schema.
+type Codec struct {
+	schema *Schema
+	types  map[agentkit.EventType]int
+	keys   map[string]int
+}
+
+// NewCodec creates a codec for schema, or the default schema when nil.
+func NewCodec(schema *Schema) *Codec {
+	if schema == nil {
+		schema = DefaultSchema()
+	}
+	c := &Codec{
+		schema: schema,
+		types:  make(map[agentkit.EventType]int, len(schema.Types)),
+		keys:   make(map[string]int, len(schema.Keys)),
+	}
+	for i, t := range schema.Types {
+		c.types[t] = i
+	}
+	for i, k := range schema.Keys {
+		c.keys[k] = i
+	}
+	return c
+}
+
+// Schema returns the codec's schema.
+func (c *Codec) Schema() *Schema {
+	return c.schema
+}
+
+// Encode returns the MessagePack encoding of event.
+func (c *Codec) Encode(event agentkit.Event) ([]byte, error) {
+	return Marshal(c.Compact(event))
+}
+
+// Compact returns event in its compact array form, for embedding in larger
+// values passed to Marshal.
+func (c *Codec) Compact(event agentkit.Event) any {
+	var eventType any = string(event.Type)
+	if i, ok := c.types[event.Type]; ok {
+		eventType = i
+	}
+	var data any
+	if event.Data != nil {
+		compacted := make(pairs, 0, len(event.Data))
+		for key, value := range event.Data {
+			var k any = key
+			if i, ok := c.keys[key]; ok {
+				k = i
+			}
+			compacted = append(compacted, pair{Key: k, Value: value})
+		}
+		data = compacted
+	}
+	var timestamp any
+	if !event.Timestamp.IsZero() {
+		timestamp = event.Timestamp.UnixMilli()
+	}
+	return []any{eventType, timestamp, data, optional(event.TraceID), optional(event.SpanID)}
+}
+
+// Decode parses an event produced by Encode.
+func (c *Codec) Decode(data []byte) (agentkit.Event, error) {
+	v, err := Unmarshal(data)
+	if err != nil {
+		return agentkit.Event{}, err
+	}
+	return c.Expand(v)
+}
+
+// Expand converts a decoded compact array back into an event.
+func (c *Codec) Expand(v any) (agentkit.Event, error) {
+	fields, ok := v.([]any)
+	if !ok || len(fields) < 3 {
+		return agentkit.Event{}, ErrInvalidEvent
+	}
+	var event agentkit.Event
+	switch t := fields[0].(type) {
+	case string:
+		event.Type = agentkit.EventType(t)
+	case int64:
+		if t < 0 || t >= int64(len(c.schema.Types)) {
+			return event, fmt.Errorf("%w: unknown type index %d", ErrInvalidEvent, t)
+		}
+		event.Type = c.schema.Types[t]
+	default:
+		return event, ErrInvalidEvent
+	}
+	if ms, ok := fields[1].(int64); ok {
+		event.Timestamp = time.UnixMilli(ms)
+	}
+
+	switch data := fields[2].(type) {
+	case nil:
+	case map[string]any:
+		event.Data = data
+	case map[any]any:
+		event.Data = make(map[string]any, len(data))
+		for key, value := range data {
+			switch k := key.(type) {
+			case string:
+				event.Data[k] = value
+			case int64:
+				if k < 0 || k >= int64(len(c.schema.Keys)) {
+					return event, fmt.Errorf("%w: unknown key index %d", ErrInvalidEvent, k)
+				}
+				event.Data[c.schema.Keys[k]] = value
+			default:
+				return event, fmt.Errorf("%w: data key of type %T", ErrInvalidEvent, key)
+			}
+		}
+	default:
+		return event, ErrInvalidEvent
+	}
+
+	if len(fields) > 3 {
+		event.TraceID, _ = fields[3].(string)
+	}
+	if len(fields) > 4 {
+		event.SpanID, _ = fields[4].(string)
+	}
+	return event, nil
+}
+
+func optional(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Accepts reports whether an Accept header asks for the compact encoding,
+// i.e. lists application/msgpack (or application/x-msgpack) with a non-zero
+// quality.
+func Accepts(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || (mediaType != ContentType && mediaType != "application/x-msgpack") {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if quality, err := strconv.ParseFloat(q, 64); err == nil && quality == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package compact
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := NewCodec(nil)
+	event := agentkit.Event{
+		Type:      agentkit.EventTypeActionResult,
+		Data:      map[string]any{"description": "done", "result": "ok", "custom_key": int64(3)},
+		Timestamp: time.UnixMilli(1_760_000_000_123),
+		TraceID:   "trace",
+	}
+	data, err := codec.Encode(event)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	got, err := codec.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Data, event.Data) || got.Type != event.Type || !got.Timestamp.Equal(event.Timestamp) || got.TraceID != "trace" || got.SpanID != "" {
+		t.Fatalf("round trip = %+v, want %+v", got, event)
+	}
+
+	asJSON, _ := json.Marshal(event)
+	if len(data)*2 > len(asJSON) {
+		t.Errorf("compact size %d is not under half of JSON size %d", len(data), len(asJSON))
+	}
+}
+
+func TestCodec_UnknownTypesStayStrings(t *testing.T) {
+	codec := NewCodec(&Schema{Version: 1, Types: []agentkit.EventType{"a"}})
+	data, err := codec.Encode(agentkit.NewEvent("custom.event", map[string]any{"k": "v"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := codec.Decode(data)
+	if err != nil || event.Type != "custom.event" || event.Data["k"] != "v" {
+		t.Fatalf("Decode() = %+v, %v", event, err)
+	}
+
+	// Indices from a newer schema are rejected rather than misread.
+	newer, _ := NewCodec(&Schema{Version: 2, Types: []agentkit.EventType{"a", "b"}}).Encode(agentkit.NewEvent("b", nil))
+	if _, err := codec.Decode(newer); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("Decode(newer) error = %v, want ErrInvalidEvent", err)
+	}
+}
+
+func TestDefaultSchema_CoversEventTypes(t *testing.T) {
+	schema := DefaultSchema()
+	if schema.Version < 1 {
+		t.Fatalf("version = %d", schema.Version)
+	}
+	for _, eventType := range []agentkit.EventType{
+		agentkit.EventTypeThinkingChunk, agentkit.EventTypeFinalOutput, agentkit.EventTypeActionDetected,
+		agentkit.EventTypeApprovalRequired, agentkit.EventTypeCostUpdate, agentkit.EventTypeError,
+	} {
+		found := false
+		for _, known := range schema.Types {
+			found = found || known == eventType
+		}
+		if !found {
+			t.Errorf("schema is missing %q; run go generate", eventType)
+		}
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	tests := map[string]bool{
+		"":                                       false,
+		"application/json":                       false,
+		"application/msgpack":                    true,
+		"text/event-stream, application/msgpack": true,
+		"application/x-msgpack;q=0.5":            true,
+		"application/msgpack;q=0":                false,
+	}
+	for accept, want := range tests {
+		if got := Accepts(accept); got != want {
+			t.Errorf("Accepts(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Command gen updates schema.json from the event types and event data keys
+// declared in the agentkit package's event.go.
+//
+// Indices on the wire are positions in the schema lists, so existing entries
+// are never reordered or removed: new ones are appended and the version is
+// bumped. Run it with go generate from the compact package.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+)
+
+type schema struct {
+	Version int      `json:"version"`
+	Types   []string `json:"types"`
+	Keys    []string `json:"keys"`
+}
+
+func main() {
+	const source, target = "../../event.go", "schema.json"
+
+	var current schema
+	if data, err := os.ReadFile(target); err == nil {
+		if err := json.Unmarshal(data, &current); err != nil {
+			log.Fatalf("gen: parse %s: %v", target, err)
+		}
+	} else if !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), source, nil, 0)
+	if err != nil {
+		log.Fatalf("gen: parse %s: %v", source, err)
+	}
+	types, keys := collect(file)
+
+	next := current
+	next.Types = appendMissing(slices.Clone(current.Types), types)
+	next.Keys = appendMissing(slices.Clone(current.Keys), keys)
+	if len(next.Types) == len(current.Types) && len(next.Keys) == len(current.Keys) && current.Version > 0 {
+		return
+	}
+	next.Version++
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(next); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(target, buf.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collect returns the EventType constant values and the string keys of
+// map[string]any literals, in source order.
+func collect(file *ast.File) (types, keys []string) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			if isIdent(n.Type, "EventType") {
+				for _, value := range n.Values {
+					if s, ok := stringLit(value); ok {
+						types = append(types, s)
+					}
+				}
+			}
+		case *ast.CompositeLit:
+			if m, ok := n.Type.(*ast.MapType); ok && isIdent(m.Key, "string") && isIdent(m.Value, "any") {
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if s, ok := stringLit(kv.Key); ok {
+							keys = append(keys, s)
+						}
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			// data["key"] = value
+			for _, lhs := range n.Lhs {
+				if index, ok := lhs.(*ast.IndexExpr); ok && isIdent(index.X, "data") {
+					if s, ok := stringLit(index.Index); ok {
+						keys = append(keys, s)
+					}
+				}
+			}
+		}
+		return true
+	})
+	return types, keys
+}
+
+func appendMissing(list, values []string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
@@ -0,0 +1,103 @@
+package compact
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	values := []any{
+		nil, true, false,
+		int64(0), int64(127), int64(128), int64(-1), int64(-32), int64(-33), int64(-200),
+		int64(70000), int64(-70000), int64(math.MaxInt64), int64(math.MinInt64),
+		uint64(math.MaxUint64), 1.5, -0.25,
+		"", "short", strings.Repeat("x", 40), strings.Repeat("y", 300), strings.Repeat("z", 70000),
+		[]byte{1, 2, 3},
+		[]any{int64(1), "two", []any{nil}},
+		make([]any, 20),
+		map[string]any{"a": int64(1), "b": map[string]any{"c": "d"}},
+	}
+	for _, v := range values {
+		data, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(%v) error = %v", v, err)
+		}
+		got, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(Marshal(%v)) error = %v", v, err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("round trip of %#v = %#v", v, got)
+		}
+	}
+}
+
+func TestMarshal_ConvertsGoTypes(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	type payload struct {
+		Name string `json:"name"`
+	}
+	data, err := Marshal(map[string]any{
+		"int":    7,
+		"float":  float32(0.5),
+		"time":   ts,
+		"err":    errors.New("boom"),
+		"struct": payload{Name: "x"},
+		"list":   []string{"a"},
+	})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := map[string]any{
+		"int":    int64(7),
+		"float":  0.5,
+		"time":   ts.Format(time.RFC3339Nano),
+		"err":    "boom",
+		"struct": map[string]any{"name": "x"},
+		"list":   []any{"a"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	for _, data := range [][]byte{
+		{},
+		{0xa5, 'a'},
+		{0xdd, 0xff, 0xff, 0xff, 0xff},
+		{0xc1},
+		{0x81, 0x90, 0x01},
+		{0x01, 0x02},
+	} {
+		if _, err := Unmarshal(data); err == nil {
+			t.Errorf("Unmarshal(% x) succeeded", data)
+		}
+	}
+	if _, err := Unmarshal([]byte{0xa3, 'a'}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("error = %v, want ErrTruncated", err)
+	}
+}
+
+func TestMarshal_MixedKeys(t *testing.T) {
+	data, err := Marshal(pairs{{Key: 1, Value: "a"}, {Key: "b", Value: "c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte{0x82, 0x01}) {
+		t.Fatalf("encoding = % x", data)
+	}
+	got, _ := Unmarshal(data)
+	if want := (map[any]any{int64(1): "a", "b": "c"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v", got)
+	}
+}
@@ -0,0 +1,232 @@
+// Package eventschema publishes the agentkit event wire format as JSON
+// Schema and protobuf definitions, so frontends in other languages, such as
+// TypeScript SSE clients, can generate typed bindings.
+//
+// Both files are generated from the agentkit event constructors and
+// versioned: every change bumps the version, and changes must be
+// backward-compatible (see Compatible). Event types and data keys are only
+// ever added, keys keep their type and required keys stay required, and
+// protobuf field numbers never change. Clients can vendor the files or
+// fetch them from SchemaHandler and ProtoHandler.
+package eventschema
+
+//go:generate go run ./internal/gen
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// VersionHeader carries the schema version of SchemaHandler and
+// ProtoHandler responses.
+const VersionHeader = "X-Agentkit-Event-Schema-Version"
+
+// ErrInvalidEvent is returned by Validate for events that do not match
+// the schema.
+var ErrInvalidEvent = errors.New("eventschema: invalid event")
+
+//go:embed event.schema.json
+var schemaJSON []byte
+
+//go:embed event.proto
+var protoFile []byte
+
+var published = sync.OnceValue(func() *document {
+	doc, err := parse(schemaJSON)
+	if err != nil {
+		panic(fmt.Sprintf("eventschema: invalid embedded schema: %v", err))
+	}
+	return doc
+})
+
+var publishedEvents = sync.OnceValue(func() map[string]map[string]any {
+	return published().events()
+})
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of events.
+func JSONSchema() []byte {
+	return slices.Clone(schemaJSON)
+}
+
+// Proto returns the proto3 definitions of events.
+func Proto() []byte {
+	return slices.Clone(protoFile)
+}
+
+// Version returns the schema version.
+func Version() int {
+	return published().Version
+}
+
+// SchemaHandler serves the JSON Schema.
+func SchemaHandler() http.Handler {
+	return serve("application/schema+json", schemaJSON)
+}
+
+// ProtoHandler serves the protobuf definitions.
+func ProtoHandler() http.Handler {
+	return serve("text/plain; charset=utf-8", protoFile)
+}
+
+func serve(contentType string, body []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set(VersionHeader, strconv.Itoa(Version()))
+		_, _ = w.Write(body)
+	})
+}
+
+// Validate checks that event, as encoded to JSON, matches the schema.
+// Events of types the schema does not know only need a valid envelope.
+func Validate(event agentkit.Event) error {
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var value map[string]any
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return err
+	}
+	doc := published()
+	violations := agentkit.ValidateSchema(doc.Envelope, value)
+	if data, ok := publishedEvents()[string(event.Type)]; ok {
+		for _, v := range agentkit.ValidateSchema(data, value["data"]) {
+			v.Path = "$.data" + strings.TrimPrefix(v.Path, "$")
+			violations = append(violations, v)
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	problems := make([]string, len(violations))
+	for i, v := range violations {
+		problems[i] = v.String()
+	}
+	return fmt.Errorf("%w %q: %s", ErrInvalidEvent, event.Type, strings.Join(problems, "; "))
+}
+
+// Compatible reports the changes from the previous to the current JSON
+// Schema that would break clients built against the previous one: a
+// removed event type or data key, a key whose type changed or that is no
+// longer required, a renumbered protobuf field, or a lower version.
+func Compatible(previous, current []byte) error {
+	prev, err := parse(previous)
+	if err != nil {
+		return fmt.Errorf("eventschema: previous schema: %w", err)
+	}
+	cur, err := parse(current)
+	if err != nil {
+		return fmt.Errorf("eventschema: current schema: %w", err)
+	}
+
+	var errs []error
+	if cur.Version < prev.Version {
+		errs = append(errs, fmt.Errorf("version %d is lower than %d", cur.Version, prev.Version))
+	}
+	curEvents := cur.events()
+	for _, eventType := range sortedKeys(prev.events()) {
+		prevData := prev.events()[eventType]
+		curData, ok := curEvents[eventType]
+		if !ok {
+			errs = append(errs, fmt.Errorf("event type %q was removed", eventType))
+			continue
+		}
+		for _, problem := range compareSchemas(prevData, curData, "") {
+			errs = append(errs, fmt.Errorf("%q data%s", eventType, problem))
+		}
+	}
+	for _, key := range sortedKeys(prev.FieldNumbers) {
+		if n, ok := cur.FieldNumbers[key]; !ok || n != prev.FieldNumbers[key] {
+			errs = append(errs, fmt.Errorf("field number of %q changed from %d to %d", key, prev.FieldNumbers[key], n))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// compareSchemas returns what prev allows that cur no longer does, recursing
+// into object properties and array items.
+func compareSchemas(prev, cur map[string]any, path string) []string {
+	var problems []string
+	if !reflect.DeepEqual(prev["type"], cur["type"]) {
+		problems = append(problems, fmt.Sprintf("%s: type changed from %v to %v", path, prev["type"], cur["type"]))
+	}
+	prevProps, _ := prev["properties"].(map[string]any)
+	curProps, _ := cur["properties"].(map[string]any)
+	for _, name := range sortedKeys(prevProps) {
+		curProp, ok := curProps[name].(map[string]any)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s: removed", path, name))
+			continue
+		}
+		prevProp, _ := prevProps[name].(map[string]any)
+		problems = append(problems, compareSchemas(prevProp, curProp, path+"."+name)...)
+	}
+	curRequired, _ := cur["required"].([]any)
+	prevRequired, _ := prev["required"].([]any)
+	for _, name := range prevRequired {
+		if !slices.Contains(curRequired, name) {
+			problems = append(problems, fmt.Sprintf("%s.%v: no longer required", path, name))
+		}
+	}
+	if prevItems, ok := prev["items"].(map[string]any); ok {
+		curItems, _ := cur["items"].(map[string]any)
+		problems = append(problems, compareSchemas(prevItems, curItems, path+"[]")...)
+	}
+	return problems
+}
+
+// document is the part of the JSON Schema Validate and Compatible use.
+type document struct {
+	Version      int                       `json:"x-agentkit-version"`
+	FieldNumbers map[string]int            `json:"x-agentkit-field-numbers"`
+	Defs         map[string]map[string]any `json:"$defs"`
+	Envelope     map[string]any            `json:"-"`
+}
+
+func parse(schema []byte) (*document, error) {
+	var doc document
+	if err := json.Unmarshal(schema, &doc); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(schema, &doc.Envelope); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// events maps event types to their data schemas.
+func (d *document) events() map[string]map[string]any {
+	events := make(map[string]map[string]any)
+	for _, def := range d.Defs {
+		props, _ := def["properties"].(map[string]any)
+		typ, _ := props["type"].(map[string]any)
+		value, ok := typ["const"].(string)
+		if !ok {
+			continue
+		}
+		data, _ := props["data"].(map[string]any)
+		ref, _ := data["$ref"].(string)
+		events[value] = d.Defs[strings.TrimPrefix(ref, "#/$defs/")]
+	}
+	return events
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,156 @@
+package eventschema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/providers"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func TestSchema_CoversEventTypes(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "../../event.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := published().events()
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "EventType" {
+				continue
+			}
+			eventType, _ := strconv.Unquote(value.Values[0].(*ast.BasicLit).Value)
+			if _, ok := events[eventType]; !ok {
+				t.Errorf("schema is missing %q; run go generate", eventType)
+			}
+		}
+	}
+	if !strings.Contains(string(Proto()), "message CostUpdateData {") {
+		t.Error("event.proto has no CostUpdateData message")
+	}
+}
+
+func TestSchema_CompatibleWithPublishedVersions(t *testing.T) {
+	baseline, err := os.ReadFile("testdata/v1.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Compatible(baseline, JSONSchema()); err != nil {
+		t.Errorf("schema breaks clients of version 1:\n%v", err)
+	}
+}
+
+func TestCompatible_DetectsBreakingChanges(t *testing.T) {
+	change := func(edit func(doc map[string]any)) []byte {
+		var doc map[string]any
+		if err := json.Unmarshal(JSONSchema(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		edit(doc)
+		data, _ := json.Marshal(doc)
+		return data
+	}
+	defs := func(doc map[string]any) map[string]any { return doc["$defs"].(map[string]any) }
+	properties := func(doc map[string]any, def string) map[string]any {
+		return defs(doc)[def].(map[string]any)["properties"].(map[string]any)
+	}
+
+	tests := map[string]struct {
+		edit func(doc map[string]any)
+		want string
+	}{
+		"removed type": {func(doc map[string]any) { delete(defs(doc), "ToolUnknownEvent") }, `"tool.unknown" was removed`},
+		"removed key":  {func(doc map[string]any) { delete(properties(doc, "ToolUnknownData"), "available_tools") }, ".available_tools: removed"},
+		"changed type": {func(doc map[string]any) {
+			properties(doc, "CostUpdateData")["cost"] = map[string]any{"type": "string"}
+		}, ".cost: type changed"},
+		"optional key": {func(doc map[string]any) { delete(defs(doc)["ToolUnknownData"].(map[string]any), "required") }, "no longer required"},
+		"renumbered":   {func(doc map[string]any) { doc["x-agentkit-field-numbers"].(map[string]any)["tool_name"] = 999 }, `"tool_name" changed`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Compatible(JSONSchema(), change(tt.edit))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Compatible() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	added := change(func(doc map[string]any) {
+		properties(doc, "ToolUnknownData")["suggestion"] = map[string]any{"type": "string"}
+		doc["x-agentkit-version"] = Version() + 1
+	})
+	if err := Compatible(JSONSchema(), added); err != nil {
+		t.Errorf("adding a key: Compatible() error = %v", err)
+	}
+}
+
+func TestValidate_ConstructorEvents(t *testing.T) {
+	events := []agentkit.Event{
+		agentkit.ToolUnknown("serach", "call_1", []string{"search"}),
+		agentkit.AgentCompleteWithUsage("assistant", "done", providers.TokenUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, 2, 1200),
+		agentkit.CostUpdated(agentkit.CostUpdate{Model: "gpt-4o", Cost: &agentkit.CostInfo{TotalCost: 0.25}}),
+		agentkit.ApprovalRequired(agentkit.ApprovalRequest{ToolName: "deploy", Arguments: map[string]any{"env": "prod"}, CallID: "call_2"}),
+		agentkit.FinalOutputWithAnnotations("", "See the docs.", []providers.Annotation{{Type: "url_citation", URL: "https://example.com"}}),
+		agentkit.Error(errors.New("boom")),
+		agentkit.NewEvent("custom.step", map[string]any{"anything": true}),
+	}
+	for _, event := range events {
+		if err := Validate(event); err != nil {
+			t.Errorf("Validate(%s) error = %v", event.Type, err)
+		}
+	}
+
+	bad := agentkit.ToolUnknown("serach", "call_1", nil)
+	bad.Data["tool_name"] = 42
+	delete(bad.Data, "tool_id")
+	err := Validate(bad)
+	if !errors.Is(err, ErrInvalidEvent) || !strings.Contains(err.Error(), "$.data.tool_name") || !strings.Contains(err.Error(), `"tool_id"`) {
+		t.Errorf("Validate(bad) error = %v", err)
+	}
+}
+
+func TestValidate_AgentRun(t *testing.T) {
+	provider := mockprovider.New().
+		WithResponse("", []providers.ToolCall{{ID: "call_1", Name: "lookup", Arguments: map[string]any{"q": "go"}}}).
+		WithResponse("", []providers.ToolCall{{ID: "call_2", Name: "missing"}}).
+		WithResponse("Go is a language.", nil)
+	agent, err := agentkit.New(agentkit.Config{Provider: provider, Model: "gpt-4o"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent.AddTool(agentkit.NewTool("lookup").
+		WithDescription("Look things up").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return map[string]any{"answer": "a language"}, nil
+		}).
+		Build())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	count := 0
+	for event := range agent.Run(ctx, "What is Go?") {
+		count++
+		if err := Validate(event); err != nil {
+			t.Errorf("Validate(%s) error = %v", event.Type, err)
+		}
+	}
+	if count < 5 {
+		t.Errorf("run emitted %d events", count)
+	}
+}
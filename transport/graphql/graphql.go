@@ -0,0 +1,507 @@
+// Package graphql exposes agent runs to GraphQL frontends without a GraphQL
+// server dependency.
+//
+// Queries and mutations use GraphQL over HTTP (POST {"query", "variables",
+// "operationName"}). The events subscription is served with the graphql-sse
+// protocol in distinct-connections mode: each subscription is a request
+// answered with "next" events followed by "complete".
+//
+//	mutation Start($message: String!) { runAgent(message: $message) { id } }
+//	subscription Events($id: ID!) { events(runId: $id) { seq type data } }
+//	mutation Approve($call: ID!) { approveToolCall(callId: $call, approved: true) }
+//
+// Runs live in a transport.Registry, so a subscription that drops can be
+// restarted with after: <last seq> to replay the events it missed, and tool
+// approvals are resolved through a transport.Approvals broker.
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/transport"
+)
+
+// Schema is the SDL of the operations served by Handler.
+const Schema = `scalar JSON
+scalar Time
+
+type Run {
+  id: ID!
+  done: Boolean!
+}
+
+type Event {
+  seq: Int!
+  type: String!
+  data: JSON
+  timestamp: Time!
+  traceId: String
+  spanId: String
+}
+
+type Query {
+  run(id: ID!): Run
+}
+
+type Mutation {
+  runAgent(message: String!, conversationId: String): Run!
+  approveToolCall(callId: ID!, approved: Boolean!): Boolean!
+  cancelRun(id: ID!): Boolean!
+}
+
+type Subscription {
+  events(runId: ID!, after: Int): Event!
+}
+`
+
+// DefaultHeartbeat is the interval of keep-alive comments on subscriptions.
+const DefaultHeartbeat = 15 * time.Second
+
+// ErrApprovalsNotConfigured is returned by approveToolCall when the handler
+// has no approval broker.
+var ErrApprovalsNotConfigured = errors.New("graphql: approvals not configured")
+
+// Handler serves the agent schema over HTTP.
+type Handler struct {
+	Runner   transport.Runner
+	Registry *transport.Registry
+	// Approvals resolves approveToolCall mutations. Configure the agent's
+	// ApprovalConfig.Handler with Approvals.Handler.
+	Approvals *transport.Approvals
+	// Heartbeat is the interval of keep-alive comments on subscriptions.
+	Heartbeat time.Duration
+}
+
+// NewHandler creates a handler running messages on runner. approvals may be nil.
+func NewHandler(runner transport.Runner, approvals *transport.Approvals) *Handler {
+	return &Handler{
+		Runner:    runner,
+		Registry:  transport.NewRegistry(),
+		Approvals: approvals,
+		Heartbeat: DefaultHeartbeat,
+	}
+}
+
+// Request is a GraphQL over HTTP request.
+type Request struct {
+	Query         string         `json:"query"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	OperationName string         `json:"operationName,omitempty"`
+}
+
+// Response is a GraphQL result.
+type Response struct {
+	Data   map[string]any `json:"data"`
+	Errors []Error        `json:"errors,omitempty"`
+}
+
+// Error is a GraphQL error.
+type Error struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeRequest(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Errors: []Error{{Message: err.Error()}}})
+		return
+	}
+	op, err := parse(req.Query, req.OperationName)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Errors: []Error{{Message: err.Error()}}})
+		return
+	}
+	if err := validate(op); err != nil {
+		writeJSON(w, http.StatusBadRequest, Response{Errors: []Error{{Message: err.Error()}}})
+		return
+	}
+	vars := variables{values: req.Variables, defaults: op.Defaults}
+
+	switch op.Type {
+	case "subscription":
+		h.subscribe(w, r, op, vars)
+	case "mutation":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, Response{Errors: []Error{{Message: "graphql: mutations require POST"}}})
+			return
+		}
+		writeJSON(w, http.StatusOK, h.execute(r, op, vars))
+	default:
+		writeJSON(w, http.StatusOK, h.execute(r, op, vars))
+	}
+}
+
+func decodeRequest(r *http.Request) (Request, error) {
+	var req Request
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("graphql: decode request: %w", err)
+		}
+	} else {
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return req, fmt.Errorf("graphql: decode variables: %w", err)
+			}
+		}
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		return req, errors.New("graphql: query is required")
+	}
+	return req, nil
+}
+
+// execute resolves the root fields of a query or mutation. Mutation fields
+// run in document order.
+func (h *Handler) execute(r *http.Request, op operation, vars variables) Response {
+	resp := Response{Data: map[string]any{}}
+	for _, f := range op.Fields {
+		value, err := h.resolveRoot(r, op.Type, f, vars)
+		if err != nil {
+			resp.Errors = append(resp.Errors, Error{Message: err.Error(), Path: []any{f.key()}})
+		}
+		resp.Data[f.key()] = project(value, f.Selection)
+	}
+	return resp
+}
+
+func (h *Handler) resolveRoot(r *http.Request, opType string, f field, vars variables) (any, error) {
+	args, err := vars.resolve(f.Arguments)
+	if err != nil {
+		return nil, err
+	}
+	if f.Name == "__typename" {
+		return rootTypeName(opType), nil
+	}
+
+	switch opType + "." + f.Name {
+	case "query.run":
+		id, err := stringArg(args, "id", true)
+		if err != nil {
+			return nil, err
+		}
+		run, err := h.Registry.Get(id)
+		if errors.Is(err, transport.ErrRunNotFound) {
+			return nil, nil
+		}
+		return runObject(run), err
+
+	case "mutation.runAgent":
+		message, err := stringArg(args, "message", true)
+		if err != nil {
+			return nil, err
+		}
+		conversationID, err := stringArg(args, "conversationId", false)
+		if err != nil {
+			return nil, err
+		}
+		ctx := r.Context()
+		if conversationID != "" {
+			ctx = agentkit.WithConversation(ctx, conversationID)
+		}
+		run, err := h.Registry.Start(ctx, h.Runner, message)
+		if err != nil {
+			return nil, err
+		}
+		return runObject(run), nil
+
+	case "mutation.approveToolCall":
+		callID, err := stringArg(args, "callId", true)
+		if err != nil {
+			return nil, err
+		}
+		approved, ok := args["approved"].(bool)
+		if !ok {
+			return nil, errors.New("argument \"approved\" must be a Boolean")
+		}
+		if h.Approvals == nil {
+			return nil, ErrApprovalsNotConfigured
+		}
+		if err := h.Approvals.Resolve(callID, approved); err != nil {
+			return nil, err
+		}
+		return true, nil
+
+	case "mutation.cancelRun":
+		id, err := stringArg(args, "id", true)
+		if err != nil {
+			return nil, err
+		}
+		run, err := h.Registry.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		run.Cancel()
+		return true, nil
+	}
+	return nil, fmt.Errorf("cannot query field %q on type %q", f.Name, rootTypeName(opType))
+}
+
+// subscribe streams the events subscription using graphql-sse.
+func (h *Handler) subscribe(w http.ResponseWriter, r *http.Request, op operation, vars variables) {
+	if len(op.Fields) != 1 {
+		writeJSON(w, http.StatusBadRequest, Response{Errors: []Error{{Message: "graphql: subscriptions must select exactly one field"}}})
+		return
+	}
+	f := op.Fields[0]
+	run, after, err := h.subscription(f, vars)
+	if err != nil {
+		writeJSON(w, http.StatusOK, Response{Errors: []Error{{Message: err.Error(), Path: []any{f.key()}}}})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher.Flush()
+
+	heartbeatEvery := h.Heartbeat
+	if heartbeatEvery <= 0 {
+		heartbeatEvery = DefaultHeartbeat
+	}
+	heartbeat := time.NewTicker(heartbeatEvery)
+	defer heartbeat.Stop()
+
+	for {
+		records, missed, done, wait := run.Since(after)
+		if missed > 0 {
+			next := Response{Errors: []Error{{
+				Message: fmt.Sprintf("%d events fell out of the replay window", missed),
+				Path:    []any{f.key()},
+			}}}
+			if err := writeNext(w, transport.Cursor(run.ID, after+missed), next); err != nil {
+				return
+			}
+		}
+		for _, rec := range records {
+			next := Response{Data: map[string]any{f.key(): project(eventObject(rec), f.Selection)}}
+			if err := writeNext(w, transport.Cursor(run.ID, rec.Seq), next); err != nil {
+				return
+			}
+			after = rec.Seq
+		}
+		if done {
+			fmt.Fprint(w, "event: complete\ndata:\n\n")
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-wait:
+		case <-heartbeat.C:
+			fmt.Fprint(w, ":\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *Handler) subscription(f field, vars variables) (*transport.Run, int64, error) {
+	args, err := vars.resolve(f.Arguments)
+	if err != nil {
+		return nil, 0, err
+	}
+	runID, err := stringArg(args, "runId", true)
+	if err != nil {
+		return nil, 0, err
+	}
+	after, err := intArg(args, "after")
+	if err != nil {
+		return nil, 0, err
+	}
+	run, err := h.Registry.Get(runID)
+	return run, after, err
+}
+
+func writeNext(w http.ResponseWriter, id string, next Response) error {
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: next\ndata: %s\n\n", id, data)
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/graphql-response+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func runObject(run *transport.Run) map[string]any {
+	return map[string]any{"__typename": "Run", "id": run.ID, "done": run.Done()}
+}
+
+func eventObject(rec transport.Record) map[string]any {
+	return map[string]any{
+		"__typename": "Event",
+		"seq":        rec.Seq,
+		"type":       string(rec.Event.Type),
+		"data":       rec.Event.Data,
+		"timestamp":  rec.Event.Timestamp,
+		"traceId":    rec.Event.TraceID,
+		"spanId":     rec.Event.SpanID,
+	}
+}
+
+// objectFields lists the selectable fields of each object type.
+var objectFields = map[string]map[string]bool{
+	"Run":   {"__typename": true, "id": true, "done": true},
+	"Event": {"__typename": true, "seq": true, "type": true, "data": true, "timestamp": true, "traceId": true, "spanId": true},
+}
+
+// rootFields maps "<operation>.<field>" to the field's object type, or "" for scalars.
+var rootFields = map[string]string{
+	"query.run":                "Run",
+	"mutation.runAgent":        "Run",
+	"mutation.approveToolCall": "",
+	"mutation.cancelRun":       "",
+	"subscription.events":      "Event",
+}
+
+// validate checks the operation's selections against the schema.
+func validate(op operation) error {
+	for _, f := range op.Fields {
+		if f.Name == "__typename" && op.Type != "subscription" {
+			continue
+		}
+		typename, ok := rootFields[op.Type+"."+f.Name]
+		if !ok {
+			return fmt.Errorf("graphql: cannot query field %q on type %q", f.Name, rootTypeName(op.Type))
+		}
+		if err := validateSelection(f, typename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSelection(f field, typename string) error {
+	if typename == "" {
+		if len(f.Selection) > 0 {
+			return fmt.Errorf("graphql: field %q must not have a selection since it is a scalar", f.Name)
+		}
+		return nil
+	}
+	if len(f.Selection) == 0 {
+		return fmt.Errorf("graphql: field %q of type %q must have a selection of subfields", f.Name, typename)
+	}
+	for _, sub := range f.Selection {
+		if !objectFields[typename][sub.Name] {
+			return fmt.Errorf("graphql: cannot query field %q on type %q", sub.Name, typename)
+		}
+		if err := validateSelection(sub, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rootTypeName(opType string) string {
+	return strings.ToUpper(opType[:1]) + opType[1:]
+}
+
+// project applies a validated selection set to an object.
+func project(value any, selection []field) any {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+	out := make(map[string]any, len(selection))
+	for _, f := range selection {
+		out[f.key()] = obj[f.Name]
+	}
+	return out
+}
+
+// variables resolves variable references in arguments.
+type variables struct {
+	values   map[string]any
+	defaults map[string]any
+}
+
+func (v variables) resolve(args map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(args))
+	for name, value := range args {
+		resolved, err := v.value(value)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = resolved
+	}
+	return out, nil
+}
+
+func (v variables) value(value any) (any, error) {
+	switch value := value.(type) {
+	case variable:
+		if x, ok := v.values[string(value)]; ok {
+			return x, nil
+		}
+		if x, ok := v.defaults[string(value)]; ok {
+			return v.value(x)
+		}
+		return nil, nil
+	case []any:
+		out := make([]any, len(value))
+		for i, item := range value {
+			resolved, err := v.value(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	case map[string]any:
+		return v.resolve(value)
+	}
+	return value, nil
+}
+
+func stringArg(args map[string]any, name string, required bool) (string, error) {
+	switch value := args[name].(type) {
+	case string:
+		if value == "" && required {
+			return "", fmt.Errorf("argument %q is required", name)
+		}
+		return value, nil
+	case nil:
+		if required {
+			return "", fmt.Errorf("argument %q is required", name)
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("argument %q must be a String", name)
+	}
+}
+
+func intArg(args map[string]any, name string) (int64, error) {
+	switch value := args[name].(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return value, nil
+	case float64: // JSON variables
+		if value == float64(int64(value)) {
+			return int64(value), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %q must be an Int", name)
+}
@@ -0,0 +1,163 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/transport"
+)
+
+type chanRunner struct {
+	events  chan agentkit.Event
+	message string
+	ctx     context.Context
+}
+
+func (r *chanRunner) Run(ctx context.Context, message string, _ ...agentkit.RunOption) <-chan agentkit.Event {
+	r.ctx, r.message = ctx, message
+	return r.events
+}
+
+func post(t *testing.T, url string, req Request) Response {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	defer resp.Body.Close()
+	var out Response
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode error = %v", err)
+	}
+	return out
+}
+
+func TestHandler_RunAgentAndSubscribe(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event, 2)}
+	runner.events <- agentkit.NewEvent(agentkit.EventTypeThinkingChunk, map[string]any{"chunk": "hi"})
+	runner.events <- agentkit.NewEvent(agentkit.EventTypeFinalOutput, map[string]any{"response": "done"})
+	close(runner.events)
+	server := httptest.NewServer(NewHandler(runner, nil))
+	defer server.Close()
+
+	started := post(t, server.URL, Request{
+		Query:     `mutation($m: String!) { runAgent(message: $m, conversationId: "conv-1") { id } }`,
+		Variables: map[string]any{"m": "hello"},
+	})
+	if len(started.Errors) > 0 {
+		t.Fatalf("runAgent errors = %+v", started.Errors)
+	}
+	runID := started.Data["runAgent"].(map[string]any)["id"].(string)
+	if runner.message != "hello" {
+		t.Errorf("message = %q", runner.message)
+	}
+	if id, _ := agentkit.GetConversationID(runner.ctx); id != "conv-1" {
+		t.Errorf("conversation ID = %q", id)
+	}
+
+	body, _ := json.Marshal(Request{
+		Query:     `subscription($id: ID!, $after: Int) { e: events(runId: $id, after: $after) { seq type } }`,
+		Variables: map[string]any{"id": runID, "after": 1},
+	})
+	req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("subscribe error = %v", err)
+	}
+	stream, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	want := "id: " + transport.Cursor(runID, 2) + "\nevent: next\ndata: {\"data\":{\"e\":{\"seq\":2,\"type\":\"final_output\"}}}\n\n" +
+		"event: complete\ndata:\n\n"
+	if got := string(stream); got != want {
+		t.Fatalf("stream = %q, want %q", got, want)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		query := post(t, server.URL, Request{Query: `query { run(id: "` + runID + `") { id done __typename } }`})
+		run := query.Data["run"].(map[string]any)
+		if run["done"] == true && run["__typename"] == "Run" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run not done: %+v", run)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHandler_ApproveToolCall(t *testing.T) {
+	approvals := transport.NewApprovals()
+	server := httptest.NewServer(NewHandler(&chanRunner{}, approvals))
+	defer server.Close()
+
+	decided := make(chan bool, 1)
+	go func() {
+		approved, _ := approvals.Handler(context.Background(), agentkit.ApprovalRequest{CallID: "call-1"})
+		decided <- approved
+	}()
+	for len(approvals.Pending()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	resp := post(t, server.URL, Request{Query: `mutation { approveToolCall(callId: "call-1", approved: true) }`})
+	if len(resp.Errors) > 0 || resp.Data["approveToolCall"] != true {
+		t.Fatalf("response = %+v", resp)
+	}
+	if !<-decided {
+		t.Fatal("call was not approved")
+	}
+
+	resp = post(t, server.URL, Request{Query: `mutation { approveToolCall(callId: "call-1", approved: false) }`})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "no pending approval") {
+		t.Fatalf("second approval = %+v", resp)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&chanRunner{}, nil))
+	defer server.Close()
+
+	resp := post(t, server.URL, Request{Query: `{ a: run(id: "missing") { id } b: run(id: "") { id } }`})
+	if resp.Data["a"] != nil || len(resp.Errors) != 1 || resp.Errors[0].Path[0] != "b" {
+		t.Fatalf("response = %+v", resp)
+	}
+
+	resp = post(t, server.URL, Request{Query: `mutation { approveToolCall(callId: "x", approved: true) }`})
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != ErrApprovalsNotConfigured.Error() {
+		t.Fatalf("response = %+v", resp)
+	}
+
+	for query, want := range map[string]string{
+		`{ run(id: "missing") { id bogus } }`:               `"bogus"`,
+		`{ nope }`:                                          `"nope" on type "Query"`,
+		`{ run(id: "missing") }`:                            "must have a selection",
+		`subscription { events(runId: "x") { seq { a } } }`: "must not have a selection",
+	} {
+		resp = post(t, server.URL, Request{Query: query})
+		if resp.Data != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, want) {
+			t.Errorf("%s: response = %+v, want error containing %s", query, resp, want)
+		}
+	}
+
+	get, err := http.Get(server.URL + "?query=" + url.QueryEscape(`mutation { cancelRun(id: "x") }`))
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	get.Body.Close()
+	if get.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("GET mutation status = %d", get.StatusCode)
+	}
+}
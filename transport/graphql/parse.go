@@ -0,0 +1,416 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// operation is a parsed GraphQL operation. Only the subset needed by the
+// agent schema is supported: a single operation with fields, aliases,
+// arguments, variables and nested selections (no fragments or directives).
+type operation struct {
+	Type     string // query, mutation or subscription
+	Name     string
+	Defaults map[string]any
+	Fields   []field
+}
+
+type field struct {
+	Alias     string
+	Name      string
+	Arguments map[string]any
+	Selection []field
+}
+
+// key returns the response key for the field.
+func (f field) key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// variable is an argument value referring to an operation variable.
+type variable string
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenString
+	tokenNumber
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+type parser struct {
+	src    string
+	pos    int
+	tok    token
+	parsed []operation
+}
+
+// parse parses src and returns the operation named name, or the only
+// operation when name is empty.
+func parse(src, name string) (operation, error) {
+	p := &parser{src: src}
+	if err := p.next(); err != nil {
+		return operation{}, err
+	}
+	for p.tok.kind != tokenEOF {
+		op, err := p.operation()
+		if err != nil {
+			return operation{}, err
+		}
+		p.parsed = append(p.parsed, op)
+	}
+	switch {
+	case len(p.parsed) == 0:
+		return operation{}, fmt.Errorf("graphql: document has no operations")
+	case name != "":
+		for _, op := range p.parsed {
+			if op.Name == name {
+				return op, nil
+			}
+		}
+		return operation{}, fmt.Errorf("graphql: unknown operation %q", name)
+	case len(p.parsed) > 1:
+		return operation{}, fmt.Errorf("graphql: operationName is required for documents with several operations")
+	}
+	return p.parsed[0], nil
+}
+
+func (p *parser) operation() (operation, error) {
+	op := operation{Type: "query", Defaults: map[string]any{}}
+	if p.tok.kind == tokenName {
+		switch p.tok.value {
+		case "query", "mutation", "subscription":
+			op.Type = p.tok.value
+		case "fragment":
+			return op, p.errorf("fragments are not supported")
+		default:
+			return op, p.errorf("unexpected %q", p.tok.value)
+		}
+		if err := p.next(); err != nil {
+			return op, err
+		}
+		if p.tok.kind == tokenName {
+			op.Name = p.tok.value
+			if err := p.next(); err != nil {
+				return op, err
+			}
+		}
+		if p.is("(") {
+			if err := p.variableDefinitions(op.Defaults); err != nil {
+				return op, err
+			}
+		}
+	}
+	fields, err := p.selectionSet()
+	op.Fields = fields
+	return op, err
+}
+
+func (p *parser) variableDefinitions(defaults map[string]any) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.is(")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if p.is("=") {
+			if err := p.next(); err != nil {
+				return err
+			}
+			value, err := p.value()
+			if err != nil {
+				return err
+			}
+			defaults[name] = value
+		}
+	}
+	return p.next()
+}
+
+func (p *parser) skipType() error {
+	if p.is("[") {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.is("!") {
+		return p.next()
+	}
+	return nil
+}
+
+func (p *parser) selectionSet() ([]field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []field
+	for !p.is("}") {
+		if p.is("...") {
+			return nil, p.errorf("fragments are not supported")
+		}
+		f, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, p.next()
+}
+
+func (p *parser) field() (field, error) {
+	var f field
+	name, err := p.name()
+	if err != nil {
+		return f, err
+	}
+	if p.is(":") {
+		if err := p.next(); err != nil {
+			return f, err
+		}
+		f.Alias = name
+		if name, err = p.name(); err != nil {
+			return f, err
+		}
+	}
+	f.Name = name
+	if p.is("@") {
+		return f, p.errorf("directives are not supported")
+	}
+	if p.is("(") {
+		if f.Arguments, err = p.arguments(); err != nil {
+			return f, err
+		}
+	}
+	if p.is("{") {
+		f.Selection, err = p.selectionSet()
+	}
+	return f, err
+}
+
+func (p *parser) arguments() (map[string]any, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	args := map[string]any{}
+	for !p.is(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.next()
+}
+
+func (p *parser) value() (any, error) {
+	tok := p.tok
+	switch {
+	case p.is("$"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return variable(name), err
+	case p.is("["):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []any{}
+		for !p.is("]") {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.next()
+	case p.is("{"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		obj := map[string]any{}
+		for !p.is("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if obj[name], err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		return obj, p.next()
+	case tok.kind == tokenString:
+		return tok.value, p.next()
+	case tok.kind == tokenNumber:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if n, err := strconv.ParseInt(tok.value, 10, 64); err == nil {
+			return n, nil
+		}
+		return strconv.ParseFloat(tok.value, 64)
+	case tok.kind == tokenName:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch tok.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return tok.value, nil // enum value
+	}
+	return nil, p.errorf("unexpected %q", tok.value)
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.errorf("expected name, found %q", p.tok.value)
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.errorf("expected %q, found %q", punct, p.tok.value)
+	}
+	return p.next()
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("graphql: syntax error at offset %d: %s", p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+// next advances to the next token, skipping whitespace, commas and comments.
+func (p *parser) next() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		if c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			p.pos++
+			continue
+		}
+		break
+	}
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunct, value: "...", pos: start}
+	case strings.ContainsRune("{}()[]:!$=@|&", rune(c)):
+		p.pos++
+		p.tok = token{kind: tokenPunct, value: string(c), pos: start}
+	case c == '"':
+		s, err := p.string()
+		if err != nil {
+			return err
+		}
+		p.tok = token{kind: tokenString, value: s, pos: start}
+	case c == '-' || (c >= '0' && c <= '9'):
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		p.tok = token{kind: tokenNumber, value: p.src[start:p.pos], pos: start}
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], pos: start}
+	default:
+		return fmt.Errorf("graphql: syntax error at offset %d: unexpected character %q", start, c)
+	}
+	return nil
+}
+
+// string reads a quoted string, including block strings ("""...""").
+func (p *parser) string() (string, error) {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		end := strings.Index(p.src[p.pos+3:], `"""`)
+		if end < 0 {
+			return "", fmt.Errorf("graphql: syntax error at offset %d: unterminated block string", p.pos)
+		}
+		s := p.src[p.pos+3 : p.pos+3+end]
+		p.pos += end + 6
+		return strings.TrimSpace(s), nil
+	}
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			s, err := strconv.Unquote(p.src[start:p.pos])
+			if err != nil {
+				return "", fmt.Errorf("graphql: syntax error at offset %d: invalid string", start)
+			}
+			return s, nil
+		case '\n':
+			return "", fmt.Errorf("graphql: syntax error at offset %d: unterminated string", start)
+		default:
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("graphql: syntax error at offset %d: unterminated string", start)
+}
@@ -0,0 +1,70 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_Operation(t *testing.T) {
+	op, err := parse(`
+		# start a run
+		mutation Start($message: String!, $conv: String = "c1") {
+			run: runAgent(message: $message, conversationId: $conv) { id done }
+		}`, "")
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if op.Type != "mutation" || op.Name != "Start" || op.Defaults["conv"] != "c1" {
+		t.Fatalf("operation = %+v", op)
+	}
+	want := []field{{
+		Alias:     "run",
+		Name:      "runAgent",
+		Arguments: map[string]any{"message": variable("message"), "conversationId": variable("conv")},
+		Selection: []field{{Name: "id"}, {Name: "done"}},
+	}}
+	if !reflect.DeepEqual(op.Fields, want) {
+		t.Fatalf("fields = %+v, want %+v", op.Fields, want)
+	}
+}
+
+func TestParse_Values(t *testing.T) {
+	op, err := parse(`{ f(a: 1, b: -2.5, c: "x\ny", d: true, e: null, g: [1, "2"], h: {k: ENUM}, i: """ block """) }`, "")
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	want := map[string]any{
+		"a": int64(1), "b": -2.5, "c": "x\ny", "d": true, "e": nil,
+		"g": []any{int64(1), "2"}, "h": map[string]any{"k": "ENUM"}, "i": "block",
+	}
+	if op.Type != "query" || !reflect.DeepEqual(op.Fields[0].Arguments, want) {
+		t.Fatalf("arguments = %#v", op.Fields[0].Arguments)
+	}
+}
+
+func TestParse_SelectsOperationByName(t *testing.T) {
+	doc := `query A { run(id: "1") { id } } query B { run(id: "2") { done } }`
+	if _, err := parse(doc, ""); err == nil {
+		t.Fatal("expected error without operationName")
+	}
+	op, err := parse(doc, "B")
+	if err != nil || op.Fields[0].Arguments["id"] != "2" {
+		t.Fatalf("parse(B) = %+v, %v", op, err)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, doc := range []string{
+		``,
+		`{ run(id: "1") { ...RunFields } }`,
+		`fragment F on Run { id }`,
+		`{ run(id: "1) { id } }`,
+		`{ run(id: 1 { id } }`,
+		`{ run @include(if: true) { id } }`,
+	} {
+		if _, err := parse(doc, ""); err == nil || !strings.HasPrefix(err.Error(), "graphql: ") {
+			t.Errorf("parse(%q) error = %v", doc, err)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+// Package mcp serves agentkit tools to Model Context Protocol hosts such as
+// Claude Desktop and IDEs, so tools written for agents can be called from
+// them too. A whole agent can be served as one tool with AddAgent.
+//
+// Server speaks JSON-RPC 2.0 and implements the initialize, ping, tools/list
+// and tools/call methods. Serve it over stdio, the transport local hosts
+// launch servers with:
+//
+//	server := mcp.NewServer("notes", "1.0.0", searchTool, saveTool)
+//	if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// or over HTTP, where Server is an http.Handler answering each POSTed
+// message with a JSON response (the Streamable HTTP transport without
+// server-initiated streams):
+//
+//	http.Handle("/mcp", server)
+//
+// Tool errors are returned to the host as results flagged isError, so the
+// calling model can see and recover from them; unknown tools and malformed
+// requests are JSON-RPC errors.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// ProtocolVersion is the latest MCP revision the server implements. Clients
+// asking for an older supported revision get that one instead.
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the revisions the server can speak, newest first.
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// maxMessageSize bounds one stdio message or HTTP body.
+const maxMessageSize = 16 << 20
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Server exposes agentkit tools over MCP. It is safe for concurrent use;
+// tools can be added while it serves.
+type Server struct {
+	// Name and Version identify the server to hosts.
+	Name    string
+	Version string
+	// Instructions is an optional hint for hosts on how to use the tools.
+	Instructions string
+
+	mu    sync.RWMutex
+	tools map[string]agentkit.Tool
+}
+
+// NewServer creates a server named name serving tools.
+func NewServer(name, version string, tools ...agentkit.Tool) *Server {
+	s := &Server{Name: name, Version: version, tools: make(map[string]agentkit.Tool, len(tools))}
+	for _, tool := range tools {
+		s.AddTool(tool)
+	}
+	return s
+}
+
+// AddTool serves tool, replacing a tool of the same name.
+func (s *Server) AddTool(tool agentkit.Tool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tools == nil {
+		s.tools = make(map[string]agentkit.Tool)
+	}
+	s.tools[tool.Name()] = tool
+}
+
+// AddAgent serves agent as a tool taking an "input" task and returning the
+// agent's final answer (see agentkit.Agent.AsTool).
+func (s *Server) AddAgent(agent *agentkit.Agent, name, description string) {
+	s.AddTool(agent.AsTool(name, description))
+}
+
+func (s *Server) tool(name string) (agentkit.Tool, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tool, ok := s.tools[name]
+	return tool, ok
+}
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes
+// responses to w until r is exhausted or ctx is cancelled. Requests are
+// handled concurrently; notifications/cancelled cancels a running call.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		writeMu  sync.Mutex
+		wg       sync.WaitGroup
+		callsMu  sync.Mutex
+		inFlight = map[string]context.CancelFunc{}
+	)
+	write := func(resp *response) error {
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64<<10), maxMessageSize)
+		for scanner.Scan() {
+			line := slices.Clone(scanner.Bytes())
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case err := <-readErr:
+			wg.Wait()
+			return err
+		case line = <-lines:
+		}
+		if len(line) == 0 {
+			continue
+		}
+		req, errResp := parseRequest(line)
+		if errResp != nil {
+			if err := write(errResp); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.Method == "notifications/cancelled" {
+			var params struct {
+				RequestID json.RawMessage `json:"requestId"`
+			}
+			_ = json.Unmarshal(req.Params, &params)
+			callsMu.Lock()
+			if cancelCall, ok := inFlight[string(params.RequestID)]; ok {
+				cancelCall()
+			}
+			callsMu.Unlock()
+			continue
+		}
+		if req.isNotification() {
+			continue
+		}
+
+		callCtx, cancelCall := context.WithCancel(ctx)
+		key := string(req.ID)
+		callsMu.Lock()
+		inFlight[key] = cancelCall
+		callsMu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp := s.handle(callCtx, req)
+			callsMu.Lock()
+			delete(inFlight, key)
+			callsMu.Unlock()
+			cancelled := callCtx.Err() != nil
+			cancelCall()
+			// Cancelled requests get no response.
+			if !cancelled {
+				_ = write(resp)
+			}
+		}()
+	}
+}
+
+// ServeHTTP implements http.Handler for the Streamable HTTP transport. Each
+// POST carries one JSON-RPC message; requests are answered with a JSON
+// body and notifications with 202 Accepted. The server opens no
+// server-to-client streams, so GET is refused.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, errResp := parseRequest(body)
+	if errResp == nil && req.isNotification() {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	resp := errResp
+	if resp == nil {
+		resp = s.handle(r.Context(), req)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if errResp != nil {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification reports whether req expects no response. Responses the
+// client sends to the server are treated the same way.
+func (req *request) isNotification() bool {
+	return len(req.ID) == 0 || string(req.ID) == "null" || req.Method == ""
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func errorResponse(id json.RawMessage, code int, format string, args ...any) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: fmt.Sprintf(format, args...)}}
+}
+
+// parseRequest decodes one message, or returns the error response for it.
+func parseRequest(data []byte) (*request, *response) {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, errorResponse(nil, codeParseError, "parse error: %v", err)
+	}
+	if req.JSONRPC != "2.0" {
+		return nil, errorResponse(req.ID, codeInvalidRequest, "invalid request: jsonrpc must be \"2.0\"")
+	}
+	return &req, nil
+}
+
+// handle answers one request.
+func (s *Server) handle(ctx context.Context, req *request) *response {
+	var (
+		result any
+		err    *response
+	)
+	switch req.Method {
+	case "initialize":
+		result, err = s.initialize(req)
+	case "ping":
+		result = struct{}{}
+	case "tools/list":
+		result = s.listTools()
+	case "tools/call":
+		result, err = s.callTool(ctx, req)
+	default:
+		return errorResponse(req.ID, codeMethodNotFound, "method not found: %s", req.Method)
+	}
+	if err != nil {
+		return err
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) initialize(req *request) (any, *response) {
+	var params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, errorResponse(req.ID, codeInvalidParams, "invalid params: %v", err)
+		}
+	}
+	version := ProtocolVersion
+	if slices.Contains(supportedVersions, params.ProtocolVersion) {
+		version = params.ProtocolVersion
+	}
+	result := map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]any{"listChanged": false},
+		},
+		"serverInfo": map[string]any{"name": s.Name, "version": s.Version},
+	}
+	if s.Instructions != "" {
+		result["instructions"] = s.Instructions
+	}
+	return result, nil
+}
+
+type toolInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+func (s *Server) listTools() map[string]any {
+	s.mu.RLock()
+	tools := make([]toolInfo, 0, len(s.tools))
+	for _, tool := range s.tools {
+		def := tool.ToToolDefinition()
+		schema := def.Parameters
+		if len(schema) == 0 {
+			schema = map[string]any{"type": "object"}
+		}
+		tools = append(tools, toolInfo{Name: def.Name, Description: def.Description, InputSchema: schema})
+	}
+	s.mu.RUnlock()
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return map[string]any{"tools": tools}
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type callResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+func (s *Server) callTool(ctx context.Context, req *request) (any, *response) {
+	var params struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, errorResponse(req.ID, codeInvalidParams, "invalid params: %v", err)
+	}
+	tool, ok := s.tool(params.Name)
+	if !ok {
+		return nil, errorResponse(req.ID, codeInvalidParams, "unknown tool: %s", params.Name)
+	}
+	args := string(params.Arguments)
+	if args == "" || args == "null" {
+		args = "{}"
+	}
+
+	result, err := tool.Execute(ctx, args)
+	if err != nil {
+		return callResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	text, err := resultText(result)
+	if err != nil {
+		return callResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return callResult{Content: []textContent{{Type: "text", Text: text}}}, nil
+}
+
+// resultText renders a tool result as text content: strings as-is,
+// everything else as JSON.
+func resultText(result any) (string, error) {
+	switch v := result.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("encode tool result: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+func echoTool() agentkit.Tool {
+	return agentkit.NewTool("echo").
+		WithDescription("Echo the text back").
+		WithParameter("text", agentkit.String().Required()).
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return args["text"], nil
+		}).
+		Build()
+}
+
+func failingTool() agentkit.Tool {
+	return agentkit.NewTool("fail").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			return nil, errors.New("disk full")
+		}).
+		Build()
+}
+
+// decodeResponses splits newline-delimited responses.
+func decodeResponses(t *testing.T, out string) map[string]map[string]any {
+	t.Helper()
+	responses := map[string]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var resp map[string]any
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("bad response line %q: %v", line, err)
+		}
+		id, _ := json.Marshal(resp["id"])
+		responses[string(id)] = resp
+	}
+	return responses
+}
+
+func TestServeStdio(t *testing.T) {
+	server := NewServer("test", "1.0.0", echoTool(), failingTool())
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"host","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"fail","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"missing"}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"resources/list"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+
+	if err := server.ServeStdio(context.Background(), strings.NewReader(in), &out); err != nil {
+		t.Fatalf("ServeStdio() error = %v", err)
+	}
+
+	responses := decodeResponses(t, out.String())
+	if len(responses) != 7 {
+		t.Fatalf("got %d responses, want 7 (none for the notification):\n%s", len(responses), out.String())
+	}
+	initResult := responses["1"]["result"].(map[string]any)
+	if initResult["protocolVersion"] != "2025-03-26" || initResult["serverInfo"].(map[string]any)["name"] != "test" {
+		t.Errorf("initialize result = %v", initResult)
+	}
+	tools := responses["2"]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 2 || tools[0].(map[string]any)["name"] != "echo" {
+		t.Fatalf("tools/list = %v", tools)
+	}
+	if schema := tools[0].(map[string]any)["inputSchema"].(map[string]any); schema["type"] != "object" {
+		t.Errorf("echo inputSchema = %v", schema)
+	}
+	if schema := tools[1].(map[string]any)["inputSchema"].(map[string]any); schema["type"] != "object" {
+		t.Errorf("parameterless inputSchema = %v, want an object schema", schema)
+	}
+	echo := responses["3"]["result"].(map[string]any)
+	if text := echo["content"].([]any)[0].(map[string]any)["text"]; text != "hi" || echo["isError"] != nil {
+		t.Errorf("echo result = %v", echo)
+	}
+	failed := responses["4"]["result"].(map[string]any)
+	if failed["isError"] != true || failed["content"].([]any)[0].(map[string]any)["text"] != "disk full" {
+		t.Errorf("failing tool result = %v, want an isError result", failed)
+	}
+	for id, code := range map[string]float64{"5": codeInvalidParams, "6": codeMethodNotFound, "null": codeParseError} {
+		rpcErr, ok := responses[id]["error"].(map[string]any)
+		if !ok || rpcErr["code"] != code {
+			t.Errorf("response %s = %v, want error code %v", id, responses[id], code)
+		}
+	}
+}
+
+func TestServeStdio_CancelledCall(t *testing.T) {
+	started := make(chan struct{})
+	slow := agentkit.NewTool("slow").
+		WithHandler(func(ctx context.Context, args map[string]any) (any, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}).
+		Build()
+	server := NewServer("test", "1.0.0", slow)
+	in, feed := io.Pipe()
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() { done <- server.ServeStdio(context.Background(), in, &out) }()
+
+	io.WriteString(feed, `{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"slow"}}`+"\n")
+	<-started
+	io.WriteString(feed, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"a"}}`+"\n")
+	io.WriteString(feed, `{"jsonrpc":"2.0","id":"b","method":"ping"}`+"\n")
+	feed.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ServeStdio() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeStdio did not return after the cancelled call")
+	}
+	responses := decodeResponses(t, out.String())
+	if _, ok := responses[`"a"`]; ok {
+		t.Errorf("cancelled call got a response: %v", responses[`"a"`])
+	}
+	if _, ok := responses[`"b"`]; !ok {
+		t.Errorf("ping got no response: %s", out.String())
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	server := httptest.NewServer(NewServer("test", "1.0.0", echoTool()))
+	defer server.Close()
+
+	post := func(body string) *http.Response {
+		t.Helper()
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		return resp
+	}
+
+	resp := post(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"text":"over http"}}}`)
+	var decoded struct {
+		Result callResult `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("decode error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || decoded.Result.Content[0].Text != "over http" {
+		t.Errorf("status %d, result %+v", resp.StatusCode, decoded.Result)
+	}
+
+	resp = post(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification status = %d, want 202", resp.StatusCode)
+	}
+
+	getResp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	getResp.Body.Close()
+	if getResp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", getResp.StatusCode)
+	}
+}
+
+func TestAddAgent(t *testing.T) {
+	agent, err := agentkit.New(agentkit.Config{
+		Provider: mockprovider.New().WithResponse("Paris", nil),
+		Model:    "test-model",
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	server := NewServer("test", "1.0.0")
+	server.AddAgent(agent, "geography", "Answers geography questions")
+
+	resp := server.handle(context.Background(), &request{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage("1"),
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name":"geography","arguments":{"input":"Capital of France?"}}`),
+	})
+
+	result, ok := resp.Result.(callResult)
+	if !ok || result.IsError || result.Content[0].Text != "Paris" {
+		t.Errorf("agent call = %+v", resp)
+	}
+}
@@ -0,0 +1,132 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// Versions of the event stream protocol. A version changes when the events
+// a transport sends change shape, so clients built for an older version keep
+// working while newer ones opt in.
+const (
+	// ProtocolV1 streams agent events only. Clients that do not ask for a
+	// version get it.
+	ProtocolV1 = 1
+	// ProtocolV2 opens every stream with an EventTypeStreamOpen event.
+	ProtocolV2 = 2
+
+	// LatestProtocol is the newest version this package speaks.
+	LatestProtocol = ProtocolV2
+)
+
+// Clients request a protocol version with this header or, when they cannot
+// set headers (EventSource), this query parameter. The value is a version
+// or a comma-separated list of the versions the client speaks. Responses
+// carry the negotiated version in the header.
+const (
+	ProtocolHeader = "X-Agentkit-Protocol"
+	ProtocolParam  = "protocol"
+)
+
+// EventTypeStreamOpen is the first event of a ProtocolV2 stream. Its data
+// holds the negotiated protocol, the run_id and the event_schema version
+// of the eventschema package the server was built with.
+const EventTypeStreamOpen agentkit.EventType = "stream.open"
+
+// ErrUnsupportedProtocol is returned when a client asks only for versions
+// the server does not speak.
+var ErrUnsupportedProtocol = errors.New("transport: unsupported protocol version")
+
+// Downgrade rewrites an event for clients of the previous protocol
+// version. Returning false drops the event. Events are shared by every
+// client of a run, so a Downgrade copies Data before changing it.
+type Downgrade func(event agentkit.Event) (agentkit.Event, bool)
+
+// Protocols is the range of protocol versions a transport accepts and how
+// events are adapted to older versions. Events are produced in the Max
+// version's shape; a client of version v gets them rewritten by
+// Downgrades[Max-1], then Downgrades[Max-2] and so on down to
+// Downgrades[v]. The zero value accepts every version this package speaks.
+//
+// To roll out a payload change, start from DefaultProtocols, raise Max and
+// add the Downgrade from the new version to the previous one.
+type Protocols struct {
+	Min, Max   int
+	Downgrades map[int]Downgrade
+}
+
+// DefaultProtocols accepts ProtocolV1 through LatestProtocol.
+func DefaultProtocols() Protocols {
+	return Protocols{
+		Min: ProtocolV1,
+		Max: LatestProtocol,
+		Downgrades: map[int]Downgrade{
+			ProtocolV1: func(event agentkit.Event) (agentkit.Event, bool) {
+				return event, event.Type != EventTypeStreamOpen
+			},
+		},
+	}
+}
+
+func (p Protocols) withDefaults() Protocols {
+	if p.Max == 0 {
+		defaults := DefaultProtocols()
+		defaults.Min = max(p.Min, defaults.Min)
+		for version, downgrade := range p.Downgrades {
+			defaults.Downgrades[version] = downgrade
+		}
+		return defaults
+	}
+	if p.Min == 0 {
+		p.Min = ProtocolV1
+	}
+	return p
+}
+
+// Negotiate picks the newest version the request asks for that p accepts.
+// Requests without a version get Min.
+func (p Protocols) Negotiate(r *http.Request) (int, error) {
+	p = p.withDefaults()
+	requested := r.Header.Get(ProtocolHeader)
+	if q := r.URL.Query().Get(ProtocolParam); q != "" {
+		requested = q
+	}
+	if requested == "" {
+		return p.Min, nil
+	}
+	best := 0
+	for _, field := range strings.Split(requested, ",") {
+		version, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrUnsupportedProtocol, requested)
+		}
+		if version >= p.Min && version <= p.Max {
+			best = max(best, version)
+		}
+	}
+	if best == 0 {
+		return 0, fmt.Errorf("%w: %s (supported: %d-%d)", ErrUnsupportedProtocol, requested, p.Min, p.Max)
+	}
+	return best, nil
+}
+
+// Adapt rewrites event for clients of version. It reports false when the
+// event does not exist in that version.
+func (p Protocols) Adapt(version int, event agentkit.Event) (agentkit.Event, bool) {
+	p = p.withDefaults()
+	for v := p.Max - 1; v >= version; v-- {
+		downgrade, ok := p.Downgrades[v]
+		if !ok {
+			continue
+		}
+		if event, ok = downgrade(event); !ok {
+			return event, false
+		}
+	}
+	return event, true
+}
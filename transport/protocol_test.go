@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"errors"
+	"maps"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+func TestProtocols_Negotiate(t *testing.T) {
+	tests := []struct {
+		header, query string
+		want          int
+		err           bool
+	}{
+		{want: ProtocolV1},
+		{header: "2", want: ProtocolV2},
+		{header: "1, 2, 7", want: ProtocolV2},
+		{query: "1", header: "2", want: ProtocolV1},
+		{header: "7", err: true},
+		{header: "latest", err: true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/agent?protocol="+tt.query, nil)
+		if tt.header != "" {
+			req.Header.Set(ProtocolHeader, tt.header)
+		}
+		got, err := Protocols{}.Negotiate(req)
+		if tt.err {
+			if !errors.Is(err, ErrUnsupportedProtocol) {
+				t.Errorf("Negotiate(%q, %q) error = %v, want ErrUnsupportedProtocol", tt.header, tt.query, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Negotiate(%q, %q) = %d, %v, want %d", tt.header, tt.query, got, err, tt.want)
+		}
+	}
+
+	// Servers can retire old versions.
+	req := httptest.NewRequest("GET", "/agent", nil)
+	if got, _ := (Protocols{Min: ProtocolV2}).Negotiate(req); got != ProtocolV2 {
+		t.Errorf("Negotiate() with Min 2 = %d", got)
+	}
+}
+
+func TestProtocols_AdaptChainsDowngrades(t *testing.T) {
+	protocols := DefaultProtocols()
+	protocols.Max = 3
+	// Version 3 renamed the chunk key; version 2 clients get the old one.
+	protocols.Downgrades[ProtocolV2] = func(event agentkit.Event) (agentkit.Event, bool) {
+		if text, ok := event.Data["text"]; ok {
+			event.Data = maps.Clone(event.Data)
+			delete(event.Data, "text")
+			event.Data["chunk"] = text
+		}
+		return event, true
+	}
+
+	event := agentkit.NewEvent(agentkit.EventTypeResponseChunk, map[string]any{"text": "hi"})
+	for version, want := range map[int]string{3: "text", 2: "chunk", 1: "chunk"} {
+		got, ok := protocols.Adapt(version, event)
+		if _, has := got.Data[want]; !ok || !has {
+			t.Errorf("Adapt(%d) = %v, want key %q", version, got.Data, want)
+		}
+	}
+	if _, ok := event.Data["text"]; !ok {
+		t.Error("Adapt modified the shared event")
+	}
+
+	open := agentkit.NewEvent(EventTypeStreamOpen, nil)
+	if _, ok := protocols.Adapt(ProtocolV1, open); ok {
+		t.Error("stream.open reached a version 1 client")
+	}
+	if _, ok := protocols.Adapt(ProtocolV2, open); !ok {
+		t.Error("stream.open dropped for a version 2 client")
+	}
+}
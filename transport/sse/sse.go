@@ -0,0 +1,336 @@
+// Package sse serves agent runs over Server-Sent Events with resumption,
+// plus a long-polling fallback for clients that cannot hold a stream open.
+//
+// Starting a run:
+//
+//	GET  /agent?message=hello
+//	POST /agent   {"message": "hello", "conversation_id": "c1"}
+//
+// Every frame carries an ID of the form "<run-id>:<seq>". Browsers send the
+// last one back in the Last-Event-ID header when EventSource reconnects, and
+// the handler replays the events buffered since then before streaming live
+// events again. Clients can also resume explicitly with ?last_event_id=.
+//
+// Long polling (?mode=poll, or Accept: application/json) returns the events
+// after the cursor as JSON, waiting up to PollTimeout for new ones.
+//
+// Clients listing application/msgpack in Accept (or passing
+// ?encoding=msgpack, since EventSource cannot set headers) get the compact
+// encoding: poll bodies are MessagePack and stream frames carry base64
+// compact events.
+//
+// The stream protocol version is negotiated with the X-Agentkit-Protocol
+// header or ?protocol= (see transport.Protocols). Clients that do not ask
+// get version 1; version 2 streams open with a stream.open event.
+//
+// Events of agents started by handoffs, collaborations and agent tools are
+// streamed inline with the root agent's. Their data carries agent_name,
+// agent_path ("triage/billing"), agent_depth and parent_call_id (the
+// parent's tool call that started them), which UIs use to nest activity;
+// see agentkit.EventSource.
+package sse
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/transport"
+	"github.com/darkostanimirovic/agentkit/transport/compact"
+	"github.com/darkostanimirovic/agentkit/transport/eventschema"
+)
+
+// Defaults for Handler.
+const (
+	DefaultPollTimeout = 25 * time.Second
+	DefaultHeartbeat   = 15 * time.Second
+	DefaultRetry       = 2 * time.Second
+)
+
+// EventTypeReplayGap is sent when a client resumes from a cursor older than
+// the replay window. Data holds the number of missed events.
+const EventTypeReplayGap agentkit.EventType = "replay.gap"
+
+// RunIDHeader carries the run ID on every response.
+const RunIDHeader = "X-Run-ID"
+
+// Handler serves runs over SSE and long polling.
+type Handler struct {
+	Runner   transport.Runner
+	Registry *transport.Registry
+	// PollTimeout bounds how long a long-poll request waits for new events.
+	PollTimeout time.Duration
+	// Heartbeat is the interval of comment frames keeping idle streams open.
+	Heartbeat time.Duration
+	// Retry is the reconnection delay advertised to EventSource clients.
+	Retry time.Duration
+	// Codec encodes events for clients negotiating the compact encoding
+	// (default: compact.NewCodec(nil)).
+	Codec *compact.Codec
+	// Protocols are the stream protocol versions clients may negotiate
+	// (default: transport.DefaultProtocols()).
+	Protocols transport.Protocols
+}
+
+// NewHandler creates a handler running messages on runner.
+func NewHandler(runner transport.Runner) *Handler {
+	return &Handler{
+		Runner:      runner,
+		Registry:    transport.NewRegistry(),
+		PollTimeout: DefaultPollTimeout,
+		Heartbeat:   DefaultHeartbeat,
+		Retry:       DefaultRetry,
+		Codec:       compact.NewCodec(nil),
+		Protocols:   transport.DefaultProtocols(),
+	}
+}
+
+type startRequest struct {
+	Message        string `json:"message"`
+	ConversationID string `json:"conversation_id"`
+}
+
+// PollResponse is the body of a long-poll response. Cursor is passed back as
+// last_event_id on the next poll.
+type PollResponse struct {
+	RunID  string           `json:"run_id"`
+	Events []agentkit.Event `json:"events"`
+	Missed int64            `json:"missed,omitempty"`
+	Done   bool             `json:"done"`
+	Cursor string           `json:"cursor"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	version, err := h.Protocols.Negotiate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	run, after, err := h.resolve(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, transport.ErrRunNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set(RunIDHeader, run.ID)
+	w.Header().Set(transport.ProtocolHeader, strconv.Itoa(version))
+	w.Header().Add("Vary", "Accept, "+transport.ProtocolHeader)
+	conn := connection{run: run, version: version, protocols: h.Protocols}
+
+	accept := r.Header.Get("Accept")
+	var codec *compact.Codec
+	if compact.Accepts(accept) || r.URL.Query().Get("encoding") == "msgpack" {
+		codec = h.Codec
+		if codec == nil {
+			codec = compact.NewCodec(nil)
+		}
+		w.Header().Set(compact.SchemaVersionHeader, strconv.Itoa(codec.Schema().Version))
+	}
+
+	wantsBody := strings.Contains(accept, "application/json") || compact.Accepts(accept)
+	if r.URL.Query().Get("mode") == "poll" || (wantsBody && !strings.Contains(accept, "text/event-stream")) {
+		h.poll(w, r, conn, after, codec)
+		return
+	}
+	h.stream(w, r, conn, after, codec)
+}
+
+// resolve finds the run to resume or starts a new one.
+func (h *Handler) resolve(r *http.Request) (*transport.Run, int64, error) {
+	cursor := r.Header.Get("Last-Event-ID")
+	if q := r.URL.Query().Get("last_event_id"); q != "" {
+		cursor = q
+	}
+	if cursor != "" {
+		runID, seq, err := transport.ParseCursor(cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		run, err := h.Registry.Get(runID)
+		return run, seq, err
+	}
+
+	req := startRequest{
+		Message:        r.URL.Query().Get("message"),
+		ConversationID: r.URL.Query().Get("conversation_id"),
+	}
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, 0, fmt.Errorf("sse: decode request: %w", err)
+		}
+	}
+	if req.Message == "" {
+		return nil, 0, errors.New("sse: message is required")
+	}
+	ctx := r.Context()
+	if req.ConversationID != "" {
+		ctx = agentkit.WithConversation(ctx, req.ConversationID)
+	}
+	run, err := h.Registry.Start(ctx, h.Runner, req.Message)
+	return run, 0, err
+}
+
+// connection is a client's view of a run in its protocol version.
+type connection struct {
+	run       *transport.Run
+	version   int
+	protocols transport.Protocols
+}
+
+func (c connection) adapt(event agentkit.Event) (agentkit.Event, bool) {
+	return c.protocols.Adapt(c.version, event)
+}
+
+func (h *Handler) stream(w http.ResponseWriter, r *http.Request, conn connection, after int64, codec *compact.Codec) {
+	run := conn.run
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(w, "retry: %d\n\n", orDefault(h.Retry, DefaultRetry).Milliseconds())
+	encoding := "json"
+	if codec != nil {
+		encoding = "msgpack"
+	}
+	open := agentkit.NewEvent(transport.EventTypeStreamOpen, map[string]any{
+		"protocol":     conn.version,
+		"run_id":       run.ID,
+		"event_schema": eventschema.Version(),
+		"encoding":     encoding,
+	})
+	if event, ok := conn.adapt(open); ok {
+		if err := writeFrame(w, transport.Cursor(run.ID, after), event, codec); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(orDefault(h.Heartbeat, DefaultHeartbeat))
+	defer heartbeat.Stop()
+
+	for {
+		records, missed, done, wait := run.Since(after)
+		if missed > 0 {
+			gap := agentkit.NewEvent(EventTypeReplayGap, map[string]any{"missed": missed})
+			if event, ok := conn.adapt(gap); ok {
+				if err := writeFrame(w, transport.Cursor(run.ID, after+missed), event, codec); err != nil {
+					return
+				}
+			}
+		}
+		for _, rec := range records {
+			if event, ok := conn.adapt(rec.Event); ok {
+				if err := writeFrame(w, transport.Cursor(run.ID, rec.Seq), event, codec); err != nil {
+					return
+				}
+			}
+			after = rec.Seq
+		}
+		flusher.Flush()
+		if done {
+			// Since snapshots records and done together, so nothing is left.
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-wait:
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *Handler) poll(w http.ResponseWriter, r *http.Request, conn connection, after int64, codec *compact.Codec) {
+	run := conn.run
+	ctx, cancel := context.WithTimeout(r.Context(), orDefault(h.PollTimeout, DefaultPollTimeout))
+	defer cancel()
+
+	records, missed, done, wait := run.Since(after)
+	if len(records) == 0 && missed == 0 && !done {
+		select {
+		case <-ctx.Done():
+		case <-wait:
+		}
+		records, missed, done, _ = run.Since(after)
+	}
+
+	resp := PollResponse{RunID: run.ID, Events: make([]agentkit.Event, 0, len(records)), Missed: missed, Done: done}
+	cursor := after + missed
+	for _, rec := range records {
+		if event, ok := conn.adapt(rec.Event); ok {
+			resp.Events = append(resp.Events, event)
+		}
+		cursor = rec.Seq
+	}
+	resp.Cursor = transport.Cursor(run.ID, cursor)
+
+	w.Header().Set("Cache-Control", "no-cache")
+	if codec != nil {
+		events := make([]any, len(resp.Events))
+		for i, event := range resp.Events {
+			events[i] = codec.Compact(event)
+		}
+		body, err := compact.Marshal(map[string]any{
+			"run_id": resp.RunID,
+			"events": events,
+			"missed": resp.Missed,
+			"done":   resp.Done,
+			"cursor": resp.Cursor,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", compact.ContentType)
+		_, _ = w.Write(body)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// writeFrame writes one SSE frame, with the event as JSON or, when codec is
+// set, as base64 compact encoding.
+func writeFrame(w http.ResponseWriter, id string, event agentkit.Event, codec *compact.Codec) error {
+	var data []byte
+	var err error
+	if codec != nil {
+		var encoded []byte
+		if encoded, err = codec.Encode(event); err == nil {
+			data = []byte(base64.StdEncoding.EncodeToString(encoded))
+		}
+	} else {
+		data, err = json.Marshal(event)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event.Type, data)
+	return err
+}
+
+func orDefault(d, fallback time.Duration) time.Duration {
+	if d <= 0 {
+		return fallback
+	}
+	return d
+}
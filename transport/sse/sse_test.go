@@ -0,0 +1,271 @@
+package sse
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+	"github.com/darkostanimirovic/agentkit/transport"
+	"github.com/darkostanimirovic/agentkit/transport/compact"
+)
+
+type chanRunner struct {
+	events chan agentkit.Event
+}
+
+func (r *chanRunner) Run(context.Context, string, ...agentkit.RunOption) <-chan agentkit.Event {
+	return r.events
+}
+
+func chunk(s string) agentkit.Event {
+	return agentkit.NewEvent(agentkit.EventTypeThinkingChunk, map[string]any{"chunk": s})
+}
+
+func TestHandler_StreamsAndResumesFromLastEventID(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event, 4)}
+	runner.events <- chunk("one")
+	runner.events <- chunk("two")
+	runner.events <- chunk("three")
+	close(runner.events)
+	server := httptest.NewServer(NewHandler(runner))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?message=hi")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	runID := resp.Header.Get(RunIDHeader)
+	if runID == "" {
+		t.Fatal("missing run ID header")
+	}
+	if !strings.HasPrefix(string(body), "retry: 2000\n\n") {
+		t.Errorf("body does not start with retry: %q", body)
+	}
+	for i, want := range []string{"one", "two", "three"} {
+		if !strings.Contains(string(body), "id: "+transport.Cursor(runID, int64(i+1))+"\nevent: thinking_chunk\n") {
+			t.Errorf("frame %d missing from %q", i+1, body)
+		}
+		if !strings.Contains(string(body), want) {
+			t.Errorf("body missing %q", want)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?message=hi", nil)
+	req.Header.Set("Last-Event-ID", transport.Cursor(runID, 2))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("resume error = %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.Contains(string(body), "one") || strings.Contains(string(body), "two") || !strings.Contains(string(body), "three") {
+		t.Fatalf("resumed body = %q, want only the third event", body)
+	}
+}
+
+func TestHandler_ReportsReplayGap(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event, 4)}
+	for _, s := range []string{"a", "b", "c", "d"} {
+		runner.events <- chunk(s)
+	}
+	close(runner.events)
+	handler := NewHandler(runner)
+	handler.Registry.Window = 2
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?message=hi")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "?last_event_id=" + transport.Cursor(resp.Header.Get(RunIDHeader), 0))
+	if err != nil {
+		t.Fatalf("resume error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "event: replay.gap\n") || !strings.Contains(string(body), `"missed":2`) {
+		t.Fatalf("body = %q, want replay.gap with 2 missed", body)
+	}
+}
+
+func TestHandler_LongPoll(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event)}
+	handler := NewHandler(runner)
+	handler.PollTimeout = 50 * time.Millisecond
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	poll := func(query string) PollResponse {
+		t.Helper()
+		resp, err := http.Get(server.URL + "?mode=poll&" + query)
+		if err != nil {
+			t.Fatalf("poll error = %v", err)
+		}
+		defer resp.Body.Close()
+		var out PollResponse
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("decode error = %v", err)
+		}
+		return out
+	}
+
+	first := poll("message=hi")
+	if first.Done || len(first.Events) != 0 || first.Cursor != transport.Cursor(first.RunID, 0) {
+		t.Fatalf("first poll = %+v, want empty timeout", first)
+	}
+
+	go func() {
+		runner.events <- chunk("one")
+		close(runner.events)
+	}()
+	handler.PollTimeout = 2 * time.Second
+	var events []agentkit.Event
+	cursor := first.Cursor
+	for i := 0; i < 5; i++ {
+		resp := poll("last_event_id=" + cursor)
+		events = append(events, resp.Events...)
+		cursor = resp.Cursor
+		if resp.Done {
+			break
+		}
+	}
+	if len(events) != 1 || events[0].Data["chunk"] != "one" {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestHandler_CompactEncoding(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event, 1)}
+	runner.events <- chunk("one")
+	close(runner.events)
+	server := httptest.NewServer(NewHandler(runner))
+	defer server.Close()
+	codec := compact.NewCodec(nil)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?message=hi", nil)
+	req.Header.Set("Accept", "text/event-stream, application/msgpack")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Header.Get(compact.SchemaVersionHeader) == "" {
+		t.Error("missing schema version header")
+	}
+	_, frame, _ := strings.Cut(string(body), "event: thinking_chunk\ndata: ")
+	frame, _, _ = strings.Cut(frame, "\n")
+	raw, err := base64.StdEncoding.DecodeString(frame)
+	if err != nil {
+		t.Fatalf("frame %q is not base64: %v", frame, err)
+	}
+	if event, err := codec.Decode(raw); err != nil || event.Data["chunk"] != "one" {
+		t.Fatalf("Decode() = %+v, %v", event, err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"?last_event_id="+transport.Cursor(resp.Header.Get(RunIDHeader), 0), nil)
+	req.Header.Set("Accept", compact.ContentType)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("poll error = %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != compact.ContentType {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	decoded, err := compact.Unmarshal(body)
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	poll := decoded.(map[string]any)
+	events := poll["events"].([]any)
+	if poll["done"] != true || len(events) != 1 {
+		t.Fatalf("poll = %+v", poll)
+	}
+	if event, err := codec.Expand(events[0]); err != nil || event.Type != agentkit.EventTypeThinkingChunk {
+		t.Fatalf("Expand() = %+v, %v", event, err)
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&chanRunner{}))
+	defer server.Close()
+
+	for query, want := range map[string]int{
+		"":                         http.StatusBadRequest,
+		"?last_event_id=bad":       http.StatusBadRequest,
+		"?last_event_id=missing:1": http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + query)
+		if err != nil {
+			t.Fatalf("GET %q error = %v", query, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %q status = %d, want %d", query, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestHandler_NegotiatesProtocol(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event, 1)}
+	runner.events <- chunk("one")
+	close(runner.events)
+	server := httptest.NewServer(NewHandler(runner))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?message=hi&protocol=1,2")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	runID := resp.Header.Get(RunIDHeader)
+	if got := resp.Header.Get(transport.ProtocolHeader); got != "2" {
+		t.Errorf("protocol header = %q, want 2", got)
+	}
+	open := "id: " + transport.Cursor(runID, 0) + "\nevent: stream.open\ndata: "
+	i := strings.Index(string(body), open)
+	if i < 0 || i > strings.Index(string(body), "event: thinking_chunk") {
+		t.Fatalf("body does not open with stream.open: %q", body)
+	}
+	var event agentkit.Event
+	line, _, _ := strings.Cut(string(body)[i+len(open):], "\n")
+	if err := json.Unmarshal([]byte(line), &event); err != nil || event.Data["protocol"] != 2.0 || event.Data["run_id"] != runID {
+		t.Errorf("stream.open = %+v, %v", event, err)
+	}
+
+	// Clients that do not ask get version 1, without stream.open.
+	resp, err = http.Get(server.URL + "?last_event_id=" + transport.Cursor(runID, 0))
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.Header.Get(transport.ProtocolHeader) != "1" || strings.Contains(string(body), "stream.open") || !strings.Contains(string(body), "one") {
+		t.Errorf("version 1 stream = %q (protocol %q)", body, resp.Header.Get(transport.ProtocolHeader))
+	}
+
+	resp, err = http.Get(server.URL + "?message=hi&protocol=9")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unsupported protocol status = %d, want 400", resp.StatusCode)
+	}
+}
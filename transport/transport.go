@@ -0,0 +1,205 @@
+// Package transport keeps agent runs alive independently of the client
+// connection that started them, so network transports (SSE, long polling)
+// can let clients disconnect and resume.
+//
+// A Registry starts runs and records their events in a bounded replay
+// window. Each event gets a sequence number; a reconnecting client asks for
+// everything after the last sequence it saw.
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// Defaults for Registry.
+const (
+	DefaultReplayWindow = 512
+	DefaultRetention    = 5 * time.Minute
+)
+
+// Common errors.
+var (
+	ErrRunNotFound   = errors.New("transport: run not found")
+	ErrInvalidCursor = errors.New("transport: invalid cursor")
+)
+
+// Runner starts an agent run. *agentkit.Agent and *agentkit.Shadow satisfy it.
+type Runner interface {
+	Run(ctx context.Context, userMessage string, opts ...agentkit.RunOption) <-chan agentkit.Event
+}
+
+// Record is an event with its position in the run.
+type Record struct {
+	Seq   int64
+	Event agentkit.Event
+}
+
+// Cursor identifies a position in a run as "<run-id>:<seq>". It is used as
+// the SSE event ID, so browsers send it back in Last-Event-ID.
+func Cursor(runID string, seq int64) string {
+	return runID + ":" + strconv.FormatInt(seq, 10)
+}
+
+// ParseCursor splits a cursor into run ID and sequence.
+func ParseCursor(cursor string) (string, int64, error) {
+	i := strings.LastIndexByte(cursor, ':')
+	if i <= 0 {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	}
+	seq, err := strconv.ParseInt(cursor[i+1:], 10, 64)
+	if err != nil || seq < 0 {
+		return "", 0, fmt.Errorf("%w: %q", ErrInvalidCursor, cursor)
+	}
+	return cursor[:i], seq, nil
+}
+
+// Run is an agent run whose events are buffered for replay.
+type Run struct {
+	ID string
+
+	mu      sync.Mutex
+	records []Record // the most recent events, oldest first
+	window  int
+	next    int64
+	done    bool
+	changed chan struct{}
+	cancel  context.CancelFunc
+}
+
+// Since returns the buffered records after seq. Missed counts records after
+// seq that already fell out of the replay window. Wait is closed when new
+// records arrive or the run finishes.
+func (r *Run) Since(seq int64) (records []Record, missed int64, done bool, wait <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	oldest := r.next - int64(len(r.records)) + 1
+	if seq+1 < oldest {
+		missed = oldest - seq - 1
+	}
+	for _, rec := range r.records {
+		if rec.Seq > seq {
+			records = append(records, rec)
+		}
+	}
+	return records, missed, r.done, r.changed
+}
+
+// Done reports whether the run has finished.
+func (r *Run) Done() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.done
+}
+
+// Cancel stops the run.
+func (r *Run) Cancel() {
+	r.cancel()
+}
+
+func (r *Run) append(event agentkit.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	r.records = append(r.records, Record{Seq: r.next, Event: event})
+	if len(r.records) > r.window {
+		r.records = append(r.records[:0:0], r.records[len(r.records)-r.window:]...)
+	}
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (r *Run) finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Registry tracks runs by ID. It is safe for concurrent use.
+type Registry struct {
+	// Window is how many recent events each run keeps for replay.
+	Window int
+	// Retention is how long a finished run stays available for resuming.
+	Retention time.Duration
+
+	mu   sync.Mutex
+	runs map[string]*Run
+}
+
+// NewRegistry creates a registry with the default window and retention.
+func NewRegistry() *Registry {
+	return &Registry{Window: DefaultReplayWindow, Retention: DefaultRetention, runs: make(map[string]*Run)}
+}
+
+// Start runs userMessage on runner. The run is detached from ctx's
+// cancellation (values such as the conversation ID are kept) so it continues
+// while clients reconnect; use Run.Cancel to stop it.
+func (g *Registry) Start(ctx context.Context, runner Runner, userMessage string) (*Run, error) {
+	id, err := newRunID()
+	if err != nil {
+		return nil, err
+	}
+	window := g.Window
+	if window <= 0 {
+		window = DefaultReplayWindow
+	}
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	run := &Run{ID: id, window: window, changed: make(chan struct{}), cancel: cancel}
+
+	g.mu.Lock()
+	if g.runs == nil {
+		g.runs = make(map[string]*Run)
+	}
+	g.runs[id] = run
+	g.mu.Unlock()
+
+	events := runner.Run(runCtx, userMessage)
+	go func() {
+		defer cancel()
+		for event := range events {
+			run.append(event)
+		}
+		run.finish()
+
+		retention := g.Retention
+		if retention <= 0 {
+			retention = DefaultRetention
+		}
+		time.AfterFunc(retention, func() {
+			g.mu.Lock()
+			delete(g.runs, id)
+			g.mu.Unlock()
+		})
+	}()
+	return run, nil
+}
+
+// Get returns the run with the given ID.
+func (g *Registry) Get(id string) (*Run, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	run, ok := g.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	return run, nil
+}
+
+func newRunID() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("transport: generate run id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,108 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit"
+)
+
+// chanRunner replays events sent on its channel as a run.
+type chanRunner struct {
+	events chan agentkit.Event
+	ctx    context.Context
+}
+
+func (r *chanRunner) Run(ctx context.Context, _ string, _ ...agentkit.RunOption) <-chan agentkit.Event {
+	r.ctx = ctx
+	return r.events
+}
+
+func waitDone(t *testing.T, run *Run) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !run.Done() {
+		if time.Now().After(deadline) {
+			t.Fatal("run did not finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	id, seq, err := ParseCursor(Cursor("abc", 42))
+	if err != nil || id != "abc" || seq != 42 {
+		t.Fatalf("ParseCursor() = %q, %d, %v", id, seq, err)
+	}
+	for _, bad := range []string{"", "abc", ":1", "abc:x", "abc:-1"} {
+		if _, _, err := ParseCursor(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("ParseCursor(%q) error = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+}
+
+func TestRegistry_ReplaysWithinWindow(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event, 10)}
+	registry := NewRegistry()
+	registry.Window = 3
+
+	run, err := registry.Start(context.Background(), runner, "hi")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		runner.events <- agentkit.NewEvent(agentkit.EventTypeThinkingChunk, map[string]any{"i": i})
+	}
+	close(runner.events)
+	waitDone(t, run)
+
+	records, missed, done, _ := run.Since(0)
+	if !done || missed != 2 || len(records) != 3 || records[0].Seq != 3 {
+		t.Fatalf("Since(0) = %d records from %v, missed %d, done %v", len(records), records, missed, done)
+	}
+	records, missed, _, _ = run.Since(4)
+	if missed != 0 || len(records) != 1 || records[0].Seq != 5 {
+		t.Fatalf("Since(4) = %v, missed %d", records, missed)
+	}
+
+	if got, err := registry.Get(run.ID); err != nil || got != run {
+		t.Fatalf("Get() = %v, %v", got, err)
+	}
+	if _, err := registry.Get("missing"); !errors.Is(err, ErrRunNotFound) {
+		t.Fatalf("Get(missing) error = %v", err)
+	}
+}
+
+func TestRegistry_RunOutlivesStartContext(t *testing.T) {
+	runner := &chanRunner{events: make(chan agentkit.Event)}
+	ctx, cancel := context.WithCancel(agentkit.WithConversation(context.Background(), "conv-1"))
+	run, err := NewRegistry().Start(ctx, runner, "hi")
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	cancel()
+
+	if runner.ctx.Err() != nil {
+		t.Fatal("run context canceled with the request context")
+	}
+	if id, _ := agentkit.GetConversationID(runner.ctx); id != "conv-1" {
+		t.Fatalf("conversation ID = %q, want conv-1", id)
+	}
+
+	_, _, _, wait := run.Since(0)
+	runner.events <- agentkit.NewEvent(agentkit.EventTypeFinalOutput, nil)
+	select {
+	case <-wait:
+	case <-time.After(2 * time.Second):
+		t.Fatal("wait channel not closed on new event")
+	}
+
+	run.Cancel()
+	if runner.ctx.Err() == nil {
+		t.Fatal("Cancel() did not cancel the run context")
+	}
+	close(runner.events)
+	waitDone(t, run)
+}
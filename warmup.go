@@ -0,0 +1,143 @@
+package agentkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/darkostanimirovic/agentkit/providers"
+)
+
+// warmUpFresh is how long a warm-up counts. net/http keeps idle
+// connections for 90 seconds and prompt caches last a few minutes.
+const warmUpFresh = 30 * time.Second
+
+// warmUpMaxTokens caps the answer to the cache-priming request; 16 is the
+// smallest limit the OpenAI Responses API accepts.
+const warmUpMaxTokens = 16
+
+// WarmUpOptions configures Agent.WarmUp.
+type WarmUpOptions struct {
+	// PrimeCache also sends the static start of a request, the system prompt
+	// and tool definitions, so the provider's prompt cache holds it when the
+	// first real request arrives. It costs one small model call. OpenAI
+	// caches prefixes of 1024 tokens or more.
+	PrimeCache bool
+}
+
+// warmUpState records recent warm-ups, shared by an agent's clones.
+type warmUpState struct {
+	mu     sync.Mutex
+	warmed time.Time
+	primed map[uint64]time.Time // by request prefix
+}
+
+// WarmUp prepares the agent for a fast first response. It opens connections
+// to the agent's providers, fallbacks included, and with PrimeCache primes
+// the prompt cache. Call it at startup or when a user starts typing: calls
+// within 30 seconds of a successful warm-up return at once, so typing
+// signals can call it freely. The system prompt is built from ctx as in a
+// run, so per-user prompts are primed for that user.
+func (a *Agent) WarmUp(ctx context.Context, opts WarmUpOptions) error {
+	state := a.warmUp
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	var errs []error
+	if time.Since(state.warmed) >= warmUpFresh {
+		if err := a.warmConnections(ctx); err != nil {
+			errs = append(errs, err)
+		} else {
+			state.warmed = time.Now()
+		}
+	}
+	if opts.PrimeCache {
+		if err := a.primeCache(ctx, state); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// warmConnections warms every distinct provider that implements
+// providers.Warmer, concurrently.
+func (a *Agent) warmConnections(ctx context.Context) error {
+	candidates := []providers.Provider{a.provider}
+	for _, spec := range a.fallbacks {
+		candidates = append(candidates, spec.Provider)
+	}
+	seen := make(map[providers.Warmer]bool)
+	var warmers []providers.Warmer
+	for _, provider := range candidates {
+		warmer, ok := unwrapProvider(provider).(providers.Warmer)
+		if !ok {
+			continue
+		}
+		// Fallbacks usually share the primary provider; map keys must be
+		// comparable.
+		if reflect.TypeOf(warmer).Comparable() {
+			if seen[warmer] {
+				continue
+			}
+			seen[warmer] = true
+		}
+		warmers = append(warmers, warmer)
+	}
+
+	errs := make([]error, len(warmers))
+	var wg sync.WaitGroup
+	for i, warmer := range warmers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = warmer.WarmUp(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// primeCache sends a minimal request sharing the run's static prefix,
+// unless that prefix was primed recently.
+func (a *Agent) primeCache(ctx context.Context, state *warmUpState) error {
+	req := a.buildCompletionRequest(ctx, []providers.Message{{Role: providers.RoleUser, Content: "Reply with OK."}})
+	req.MaxTokens = warmUpMaxTokens
+	req.Store = false
+
+	key := prefixKey(req)
+	now := time.Now()
+	for k, primed := range state.primed {
+		if now.Sub(primed) >= warmUpFresh {
+			delete(state.primed, k)
+		}
+	}
+	if _, ok := state.primed[key]; ok {
+		return nil
+	}
+
+	callCtx, cancel := a.withLLMTimeout(ctx)
+	if cancel != nil {
+		defer cancel()
+	}
+	if _, err := a.provider.Complete(callCtx, req); err != nil {
+		return err
+	}
+	if state.primed == nil {
+		state.primed = make(map[uint64]time.Time)
+	}
+	state.primed[key] = now
+	a.logger.Debug("prompt cache primed", "model", req.Model)
+	return nil
+}
+
+// prefixKey identifies the cacheable start of req.
+func prefixKey(req providers.CompletionRequest) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(req.Model + "\x00" + req.SystemPrompt + "\x00"))
+	_ = json.NewEncoder(h).Encode(req.Tools)
+	return h.Sum64()
+}
@@ -0,0 +1,84 @@
+package agentkit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	mockprovider "github.com/darkostanimirovic/agentkit/providers/mock"
+)
+
+// warmingProvider counts warm-ups.
+type warmingProvider struct {
+	recordingProvider
+	warmUps atomic.Int32
+	err     error
+}
+
+func (w *warmingProvider) WarmUp(context.Context) error {
+	w.warmUps.Add(1)
+	return w.err
+}
+
+func TestWarmUp_ConnectsAndPrimesOnce(t *testing.T) {
+	provider := &warmingProvider{recordingProvider: recordingProvider{Provider: mockprovider.New().WithResponse("OK", nil).WithResponse("OK", nil)}}
+	agent, err := New(Config{
+		Provider:       provider,
+		Model:          "test-model",
+		SystemPrompt:   func(context.Context) string { return "You are a support agent." },
+		FallbackModels: []ModelSpec{{Model: "backup-model"}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	agent.AddTool(NewTool("lookup").WithDescription("Look up an order").Build())
+
+	for range 3 {
+		if err := agent.WarmUp(context.Background(), WarmUpOptions{PrimeCache: true}); err != nil {
+			t.Fatalf("WarmUp() error = %v", err)
+		}
+	}
+
+	if n := provider.warmUps.Load(); n != 1 {
+		t.Errorf("provider warmed %d times, want 1", n)
+	}
+	if len(provider.requests) != 1 {
+		t.Fatalf("priming requests = %d, want 1", len(provider.requests))
+	}
+	req := provider.requests[0]
+	if req.SystemPrompt != "You are a support agent." || len(req.Tools) != 1 || req.MaxTokens != warmUpMaxTokens {
+		t.Errorf("priming request = %+v", req)
+	}
+
+	// Another prefix is primed separately.
+	agent.AddTool(NewTool("refund").WithDescription("Refund an order").Build())
+	if err := agent.WarmUp(context.Background(), WarmUpOptions{PrimeCache: true}); err != nil {
+		t.Fatalf("WarmUp() error = %v", err)
+	}
+	if len(provider.requests) != 2 {
+		t.Errorf("priming requests = %d, want 2 after the tools changed", len(provider.requests))
+	}
+}
+
+func TestWarmUp_RetriesAfterFailure(t *testing.T) {
+	provider := &warmingProvider{recordingProvider: recordingProvider{Provider: mockprovider.New()}, err: errors.New("invalid api key")}
+	agent, err := New(Config{Provider: provider, Model: "test-model"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := agent.WarmUp(context.Background(), WarmUpOptions{}); err == nil {
+		t.Fatal("WarmUp() succeeded with a failing provider")
+	}
+	provider.err = nil
+	if err := agent.WarmUp(context.Background(), WarmUpOptions{}); err != nil {
+		t.Fatalf("WarmUp() error = %v", err)
+	}
+	if n := provider.warmUps.Load(); n != 2 {
+		t.Errorf("provider warmed %d times, want 2", n)
+	}
+	if len(provider.requests) != 0 {
+		t.Errorf("cache primed without PrimeCache")
+	}
+}